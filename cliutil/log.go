@@ -6,13 +6,23 @@ package cliutil
 import (
 	"fmt"
 
+	"github.com/fatih/color"
 	logging "github.com/ipfs/go-log"
+	logging2 "github.com/ipfs/go-log/v2"
 	"github.com/urfave/cli/v2"
 )
 
 const (
 	// FlagLogLevel is the log level flag.
 	FlagLogLevel = "log-level"
+	// FlagLogFormat is the log format flag.
+	FlagLogFormat = "log-format"
+)
+
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
 )
 
 // SetLogLevelsFromContext sets the log levels for all packages from the CLI context.
@@ -35,6 +45,28 @@ func SetLogLevelsFromContext(c *cli.Context) error {
 	return nil
 }
 
+// SetLogFormat sets the encoding of the log output of all packages. With the
+// JSON format, each line is a JSON object, and fields like a swap's offer ID
+// are separate keys of it. It must be called before the log levels are set,
+// as it resets them.
+func SetLogFormat(format string) error {
+	switch format {
+	case LogFormatText:
+		return nil
+	case LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+
+	cfg := logging2.GetConfig()
+	cfg.Format = logging2.JSONOutput
+	logging2.SetupLogging(cfg)
+
+	// escape codes would end up in the JSON messages
+	color.NoColor = true
+	return nil
+}
+
 // SetLogLevels sets the log levels for all packages.
 func SetLogLevels(level string) {
 	// alphabetically ordered
@@ -7,6 +7,7 @@ package cliutil
 import (
 	"crypto/ecdsa"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime/debug"
@@ -175,3 +176,29 @@ func ExpandBootnodes(nodesCLI []string) []string {
 	}
 	return nodes
 }
+
+// ValidateHost returns an error if the passed value is not an IP address or a
+// valid (RFC 1123) hostname.
+func ValidateHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	if len(host) == 0 || len(host) > 253 {
+		return fmt.Errorf("invalid host %q", host)
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid host %q", host)
+		}
+		for _, c := range label {
+			isAlphaNum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+			if !isAlphaNum && c != '-' {
+				return fmt.Errorf("invalid host %q", host)
+			}
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
@@ -112,3 +113,32 @@ func Test_expandBootnodes_noNodes(t *testing.T) {
 	nodes := ExpandBootnodes(cliNodes)
 	require.Zero(t, len(nodes))
 }
+
+func TestValidateHost(t *testing.T) {
+	validHosts := []string{
+		"127.0.0.1",
+		"::1",
+		"localhost",
+		"swapd",
+		"swapd-1.example.com",
+		"example.com.",
+	}
+	for _, host := range validHosts {
+		require.NoError(t, ValidateHost(host), host)
+	}
+
+	invalidHosts := []string{
+		"",
+		"127.0.0.1:5000",
+		"http://localhost",
+		"-swapd",
+		"swapd-",
+		"swapd..example.com",
+		"swapd_1",
+		"[::1]",
+		strings.Repeat("a", 64),
+	}
+	for _, host := range invalidHosts {
+		require.ErrorContains(t, ValidateHost(host), "invalid host", host)
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 var (
-	errNoDuration = fmt.Errorf("must provide non-zero --duration")
+	errNoDuration = fmt.Errorf("must provide non-zero --duration, --t0-duration or --t1-duration")
 )
 
 func errInvalidFlagValue(flagName string, err error) error {
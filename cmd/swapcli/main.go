@@ -6,14 +6,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/fatih/color"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/skip2/go-qrcode"
 	"github.com/urfave/cli/v2"
@@ -23,27 +29,80 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
 	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/rpc"
 	"github.com/athanorlabs/atomic-swap/rpcclient"
 	"github.com/athanorlabs/atomic-swap/rpcclient/wsclient"
 )
 
 const (
 	defaultDiscoverSearchTimeSecs = 12
-
-	flagSwapdPort      = "swapd-port"
-	flagMinAmount      = "min-amount"
-	flagMaxAmount      = "max-amount"
-	flagPeerID         = "peer-id"
-	flagOfferID        = "offer-id"
-	flagOfferIDs       = "offer-ids"
-	flagExchangeRate   = "exchange-rate"
-	flagProvides       = "provides"
-	flagProvidesAmount = "provides-amount"
-	flagUseRelayer     = "use-relayer"
-	flagSearchTime     = "search-time"
-	flagToken          = "token"
-	flagDetached       = "detached"
+	defaultSwapdHost              = "127.0.0.1"
+	watchReconnectDelay           = 5 * time.Second
+	defaultBalancesWatchInterval  = 10 * time.Second
+
+	// clearScreen is the ANSI escape sequence that moves the cursor to the top
+	// left and clears the screen.
+	clearScreen = "\033[H\033[2J"
+
+	// maxMarketRateDiffPercent is how far a reused exchange rate can be from
+	// the market rate before make prints a warning.
+	maxMarketRateDiffPercent = 10
+
+	flagSwapdPort       = "swapd-port"
+	flagSwapdHost       = "swapd-host"
+	flagTLS             = "tls"
+	flagTLSCACert       = "tls-ca-cert"
+	flagAuthToken       = "auth-token"
+	flagMinAmount       = "min-amount"
+	flagMaxAmount       = "max-amount"
+	flagPeerID          = "peer-id"
+	flagMultiaddr       = "multiaddr"
+	flagOfferID         = "offer-id"
+	flagOfferIDs        = "offer-ids"
+	flagExchangeRate    = "exchange-rate"
+	flagMaxExchangeRate = "max-exchange-rate"
+	flagProvides        = "provides"
+	flagProvidesAmount  = "provides-amount"
+	flagUseRelayer      = "use-relayer"
+	flagSearchTime      = "search-time"
+	flagToken           = "token"
+	flagDetached        = "detached"
+	flagStatus          = "status"
+	flagOlderThan       = "older-than"
+	flagSince           = "since"
+	flagUntil           = "until"
+	flagLimit           = "limit"
+	flagOffset          = "offset"
+	flagReuseLast       = "reuse-last"
+	flagDryRun          = "dry-run"
+	flagExpiresIn       = "expires-in"
+	flagMinTakerRate    = "min-taker-success-rate"
+	flagRejectUnknown   = "reject-unknown-takers"
+	flagPersistent      = "persistent"
+	flagWatch           = "watch"
+	flagInterval        = "interval"
+	flagGasPrice        = "gas-price"
+	flagMarkup          = "markup"
+	flagRepriceInterval = "reprice-interval"
+	flagFloorRate       = "floor-rate"
+	flagMakerSwapdHost  = "maker-swapd-host"
+	flagMakerSwapdPort  = "maker-swapd-port"
+	flagXMRAmount       = "xmr-amount"
+	flagSplit           = "split"
+	flagStrategy        = "strategy"
+	flagWeights         = "weights"
+	flagShowPeerStats   = "show-peer-stats"
+	flagDuration        = "duration"
+	flagT0Duration      = "t0-duration"
+	flagT1Duration      = "t1-duration"
+	flagFile            = "file"
+	flagIdempotencyKey  = "idempotency-key"
+	flagAll             = "all"
+	flagDrain           = "drain"
+	flagTimeout         = "timeout"
+	flagForceRefresh    = "force-refresh"
 )
 
 func cliApp() *cli.App {
@@ -60,6 +119,10 @@ func cliApp() *cli.App {
 				Usage:   "List our daemon's libp2p listening addresses",
 				Action:  runAddresses,
 				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -69,6 +132,98 @@ func cliApp() *cli.App {
 				Usage:   "List peers that are currently connected",
 				Action:  runPeers,
 				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "streams",
+				Usage:  "List the open swap protocol streams with peers",
+				Action: runStreams,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name: "peer-stats",
+				Usage: "Show the number of successful, refunded and aborted swaps that this node\n" +
+					"completed with each peer, to help avoid unreliable counterparties",
+				Action: runPeerStats,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagPeerID,
+						Usage: "Only show the stats of this peer",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name: "block-peer",
+				Usage: "Refuse all interaction with a peer. Blocked peers are left out of discovery\n" +
+					"and query results, and their queries and swaps are rejected",
+				Action: runBlockPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "ID of the peer to block",
+						Required: true,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "unblock-peer",
+				Usage:  "Remove a peer from the blocklist",
+				Action: runUnblockPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagPeerID,
+						Usage:    "ID of the peer to unblock",
+						Required: true,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "eth-sync-status",
+				Usage:  "Show whether the Ethereum node used by swapd is synced",
+				Action: runEthSyncStatus,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "xmr-sync-status",
+				Usage:  "Show how far the Monero wallet and the monerod node it uses are synced",
+				Action: runXMRSyncStatus,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -78,6 +233,10 @@ func cliApp() *cli.App {
 				Usage:   "Show our Monero and Ethereum account balances",
 				Action:  runBalances,
 				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 					&cli.StringSliceFlag{
 						Name:    flagToken,
@@ -85,6 +244,15 @@ func cliApp() *cli.App {
 						EnvVars: []string{"SWAPCLI_TOKENS"},
 						Usage:   "Token address to include in the balance response",
 					},
+					&cli.BoolFlag{
+						Name:  flagWatch,
+						Usage: "Reprint the balances periodically until interrupted",
+					},
+					&cli.DurationFlag{
+						Name:  flagInterval,
+						Usage: "Interval between balance updates with --watch",
+						Value: defaultBalancesWatchInterval,
+					},
 				},
 			},
 			{
@@ -92,6 +260,10 @@ func cliApp() *cli.App {
 				Usage:  "Show our Ethereum address with its QR code",
 				Action: runETHAddress,
 				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -100,6 +272,10 @@ func cliApp() *cli.App {
 				Usage:  "Show our Monero address with its QR code",
 				Action: runXMRAddress,
 				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -120,6 +296,10 @@ func cliApp() *cli.App {
 						Usage: "Duration of time to search for, in seconds",
 						Value: defaultDiscoverSearchTimeSecs,
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -130,10 +310,18 @@ func cliApp() *cli.App {
 				Action:  runQuery,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     flagPeerID,
-						Usage:    "Peer's ID, as provided by discover",
-						Required: true,
+						Name:  flagPeerID,
+						Usage: "Peer's ID, as provided by discover",
+					},
+					&cli.StringFlag{
+						Name: flagMultiaddr,
+						Usage: "Peer's full multiaddr, including its peer ID, to query the peer directly\n" +
+							"without resolving its address via the DHT. Alternative to --" + flagPeerID,
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -154,6 +342,77 @@ func cliApp() *cli.App {
 						Usage: "Duration of time to search for, in seconds",
 						Value: defaultDiscoverSearchTimeSecs,
 					},
+					&cli.StringFlag{
+						Name:  flagMaxExchangeRate,
+						Usage: "Only show offers with an exchange rate of at most this value",
+					},
+					&cli.StringFlag{
+						Name:  flagMinAmount,
+						Usage: "Only show offers that allow swapping at least this amount, in XMR",
+					},
+					&cli.StringFlag{
+						Name:  flagMaxAmount,
+						Usage: "Only show offers that allow swapping at most this amount, in XMR",
+					},
+					&cli.BoolFlag{
+						Name:  flagShowPeerStats,
+						Usage: "Show the outcomes of this node's completed swaps with each peer",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "orderbook",
+				Usage:  "Discover peers that provide Monero and show their offers sorted by exchange rate",
+				Action: runOrderbook,
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  flagSearchTime,
+						Usage: "Duration of time to search for, in seconds",
+						Value: defaultDiscoverSearchTimeSecs,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "relayer-status",
+				Usage:  "Discover relayers with their fees and get the claims relayed by swapd if it is a relayer",
+				Action: runRelayerStatus,
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  flagSearchTime,
+						Usage: "Duration of time to search for, in seconds",
+						Value: defaultDiscoverSearchTimeSecs,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "query-relayers",
+				Usage:  "Discover peers that relay claims for XMR makers and the fees they charge",
+				Action: runQueryRelayers,
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  flagSearchTime,
+						Usage: "Duration of time to search for, in seconds",
+						Value: defaultDiscoverSearchTimeSecs,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -164,19 +423,21 @@ func cliApp() *cli.App {
 				Action:  runMake,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     flagMinAmount,
-						Usage:    "Minimum amount to be swapped, in XMR",
-						Required: true,
+						Name:  flagMinAmount,
+						Usage: "Minimum amount to be swapped, in XMR",
 					},
 					&cli.StringFlag{
-						Name:     flagMaxAmount,
-						Usage:    "Maximum amount to be swapped, in XMR",
-						Required: true,
+						Name:  flagMaxAmount,
+						Usage: "Maximum amount to be swapped, in XMR",
 					},
 					&cli.StringFlag{
-						Name:     flagExchangeRate,
-						Usage:    "Desired exchange rate of XMR:ETH, eg. --exchange-rate=0.1 means 10XMR = 1ETH",
-						Required: true,
+						Name:  flagExchangeRate,
+						Usage: "Desired exchange rate of XMR:ETH, eg. --exchange-rate=0.1 means 10XMR = 1ETH",
+					},
+					&cli.BoolFlag{
+						Name: flagReuseLast,
+						Usage: "Use the amounts and exchange rate of the last offer made for the same asset\n" +
+							"for any of those values not passed. Requires swapd --persist-offer-defaults.",
 					},
 					&cli.BoolFlag{
 						Name:  flagDetached,
@@ -190,6 +451,52 @@ func cliApp() *cli.App {
 						Name:  flagUseRelayer,
 						Usage: "Use the relayer even if the receiving account has enough ETH to claim",
 					},
+					&cli.BoolFlag{
+						Name:  flagDryRun,
+						Usage: "Validate the offer and print its amounts without publishing it",
+					},
+					&cli.DurationFlag{
+						Name:  flagExpiresIn,
+						Usage: "Remove the offer if it has not been taken within this duration, eg. 12h",
+					},
+					&cli.StringFlag{
+						Name: flagMinTakerRate,
+						Usage: "Reject takes from takers whose fraction of successful swaps with\n" +
+							"this node is below this value, eg. --min-taker-success-rate=0.9",
+					},
+					&cli.BoolFlag{
+						Name:  flagRejectUnknown,
+						Usage: "Reject takes from takers that have not completed any swaps with this node",
+					},
+					&cli.BoolFlag{
+						Name: flagPersistent,
+						Usage: "Renew the offer with the same terms after each successful swap,\n" +
+							"with the maximum amount clamped to the remaining XMR balance",
+					},
+					&cli.StringFlag{
+						Name: flagMarkup,
+						Usage: "Set the exchange rate as a percentage above the market rate of the price feed\n" +
+							"instead of passing --exchange-rate, eg. --markup=2 or --markup=-0.5 (ETH offers only)",
+					},
+					&cli.DurationFlag{
+						Name: flagRepriceInterval,
+						Usage: "With --markup, stay running and re-price the offer at this interval while it\n" +
+							"is not taken, replacing it with a new offer if the market rate changed, eg. 10m",
+					},
+					&cli.StringFlag{
+						Name: flagFloorRate,
+						Usage: "With --markup, have swapd keep the offer at the markup over the market rate,\n" +
+							"but never below this exchange rate, even after swapcli exits",
+					},
+					&cli.StringFlag{
+						Name: flagIdempotencyKey,
+						Usage: "A key that makes retrying the command safe: if swapd already made an offer\n" +
+							"for the key, that offer is returned instead of a new one being made",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
@@ -214,132 +521,565 @@ func cliApp() *cli.App {
 						Usage:    "Amount of coin to send in the swap",
 						Required: true,
 					},
+					&cli.StringFlag{
+						Name:  flagMaxExchangeRate,
+						Usage: "Abort the swap before locking any funds if the offer's exchange rate is above this value",
+					},
 					&cli.BoolFlag{
 						Name:  flagDetached,
 						Usage: "Exit immediately instead of subscribing to notifications about the swap's status",
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "ongoing",
-				Usage:  "Get information about ongoing swap(s).",
-				Action: runGetOngoingSwap,
+				Name: "gas-estimate",
+				Usage: "Show the expected ETH gas cost of a swap at the current gas price, for the\n" +
+					"taker's transactions when the swap succeeds or is refunded, and for the maker's claim",
+				Action: runGasEstimate,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  flagOfferID,
-						Usage: "ID of swap to retrieve info for",
+						Name:  flagToken,
+						Usage: "Token address of the ERC20 asset you would provide, ETH if not set",
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "past",
-				Usage:  "Get information about past swap(s)",
-				Action: runGetPastSwap,
+				Name: "estimate",
+				Usage: "Show the amount of XMR you would receive for taking an offer with --provides-amount,\n" +
+					"without initiating a swap",
+				Action: runEstimate,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  flagOfferID,
-						Usage: "ID of swap to retrieve info for",
+						Name:     flagPeerID,
+						Usage:    "Peer's ID, as provided by discover",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     flagOfferID,
+						Usage:    "ID of the offer to estimate",
+						Required: true,
 					},
+					&cli.StringFlag{
+						Name:     flagProvidesAmount,
+						Usage:    "Amount of coin you would send in the swap",
+						Required: true,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "cancel",
-				Usage:  "Cancel a ongoing swap if possible. Depending on the swap stage, this may not be possible.",
-				Action: runCancel,
+				Name: "take-all",
+				Usage: "Initiate swaps with several offers at once. Offers that fail to be taken are\n" +
+					"reported at the end and do not stop the remaining offers from being taken.",
+				Action: runTakeAll,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  flagOfferID,
-						Usage: "ID of swap to retrieve info for",
+						Name:     flagOfferIDs,
+						Usage:    "A comma-separated list of offer IDs to take",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name: flagPeerID,
+						Usage: "Peer ID of the maker of all offers, or a comma-separated list of peer IDs\n" +
+							"with one entry for each offer ID",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     flagProvidesAmount,
+						Usage:    "Amount of coin to send in the swaps, split between them by --" + flagSplit,
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name: flagSplit,
+						Usage: fmt.Sprintf("How to use --%s: %q provides the full amount to each offer,\n"+
+							"%q divides it evenly between the offers", flagProvidesAmount, splitEach, splitEven),
+						Value: splitEach,
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "clear-offers",
-				Usage:  "Clear current offers. If no offer IDs are provided, clears all current offers.",
-				Action: runClearOffers,
+				Name: "take-best",
+				Usage: "Discover offers for XMR and take the one selected by --strategy among those that\n" +
+					"accept --provides-amount. The selected offer and the reason it was selected are printed.",
+				Action: runTakeBest,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  flagOfferIDs,
-						Usage: "A comma-separated list of offer IDs to delete",
+						Name:     flagProvidesAmount,
+						Usage:    "Amount of coin to send in the swap",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  flagToken,
+						Usage: "Use to pass the ethereum ERC20 token address to provide instead of ETH",
+					},
+					&cli.StringFlag{
+						Name:  flagMaxExchangeRate,
+						Usage: "Only consider offers with an exchange rate of at most this value",
 					},
+					&cli.StringFlag{
+						Name: flagStrategy,
+						Usage: fmt.Sprintf("How to select the offer: %q for the lowest exchange rate, %q for the\n"+
+							"highest maximum amount, %q for the maker with the best success rate of swaps\n"+
+							"with this node, %q for the fastest responding maker, or %q for a weighted\n"+
+							"combination of all of them set by --%s",
+							selectBestRate, selectLiquidity, selectReputation, selectLatency, selectWeighted, flagWeights),
+						Value: selectBestRate,
+					},
+					&cli.StringFlag{
+						Name:  flagWeights,
+						Usage: fmt.Sprintf("Weights of the criteria of the %q strategy", selectWeighted),
+						Value: defaultSelectionWeights,
+					},
+					&cli.Uint64Flag{
+						Name:  flagSearchTime,
+						Usage: "Duration of time to search for, in seconds",
+						Value: defaultDiscoverSearchTimeSecs,
+					},
+					&cli.BoolFlag{
+						Name:  flagDetached,
+						Usage: "Exit immediately instead of subscribing to notifications about the swap's status",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "get-offers",
-				Usage:  "Get all current offers.",
-				Action: runGetOffers,
+				Name: "test-swap",
+				Usage: "Run a complete swap with a small amount between two swapd instances to verify an\n" +
+					"installation. The maker daemon makes an offer that the taker daemon (set with --swapd-port\n" +
+					"and --swapd-host) takes. Only allowed in the dev and stagenet environments.",
+				Action: runTestSwap,
 				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagMakerSwapdHost,
+						Usage: "Hostname or IP address of the swap daemon making the test offer",
+						Value: defaultSwapdHost,
+						Action: func(_ *cli.Context, host string) error {
+							if err := cliutil.ValidateHost(host); err != nil {
+								return errInvalidFlagValue(flagMakerSwapdHost, err)
+							}
+							return nil
+						},
+					},
+					&cli.UintFlag{
+						Name:  flagMakerSwapdPort,
+						Usage: "RPC port of the swap daemon making the test offer",
+						Value: common.DefaultSwapdPort + 1,
+					},
+					&cli.StringFlag{
+						Name:  flagXMRAmount,
+						Usage: "Amount of XMR to swap",
+						Value: defaultTestSwapXMRAmount,
+					},
+					&cli.StringFlag{
+						Name:  flagExchangeRate,
+						Usage: "Exchange rate of the test offer, in ETH per XMR",
+						Value: defaultTestSwapExchangeRate,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "get-status",
-				Usage:  "Get the status of a current swap.",
-				Action: runGetStatus,
+				Name:   "ongoing",
+				Usage:  "Get information about ongoing swap(s).",
+				Action: runGetOngoingSwap,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:     flagOfferID,
-						Usage:    "ID of swap to retrieve info for",
-						Required: true,
+						Name:  flagOfferID,
+						Usage: "ID of swap to retrieve info for",
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "set-swap-timeout",
-				Usage:  "Set the duration between swap initiation and t0 and t0 and t1, in seconds",
-				Action: runSetSwapTimeout,
+				Name: "watch",
+				Usage: "Print status updates of all swaps as they happen, or only those of a single swap if\n" +
+					"--offer-id is set. Runs until interrupted or the watched swap completes.",
+				Action: runWatch,
 				Flags: []cli.Flag{
-					&cli.UintFlag{
-						Name:     "duration",
-						Usage:    "Duration of timeout, in seconds",
-						Required: true,
+					&cli.StringFlag{
+						Name:  flagOfferID,
+						Usage: "ID of swap to watch",
 					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "suggested-exchange-rate",
-				Usage:  "Returns the current mainnet exchange rate based on ETH/USD and XMR/USD price feeds.",
-				Action: runSuggestedExchangeRate,
-				Flags:  []cli.Flag{swapdPortFlag},
+				Name:   "past",
+				Usage:  "Get information about past swap(s)",
+				Action: runGetPastSwap,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagOfferID,
+						Usage: "ID of swap to retrieve info for",
+					},
+					&cli.StringFlag{
+						Name:  flagStatus,
+						Usage: "Only show swaps with this status, eg. Refunded",
+					},
+					&cli.StringFlag{
+						Name: flagSince,
+						Usage: "Only show swaps started at or after this time, given as a date (eg. 2023-04-01)," +
+							" an RFC 3339 timestamp, or a duration before now (eg. 720h)",
+					},
+					&cli.StringFlag{
+						Name:  flagUntil,
+						Usage: "Only show swaps started at or before this time, in the same formats as --" + flagSince,
+					},
+					&cli.UintFlag{
+						Name:  flagLimit,
+						Usage: "Maximum number of swaps to show, 0 for no limit",
+					},
+					&cli.UintFlag{
+						Name:  flagOffset,
+						Usage: "Number of the newest matching swaps to skip",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
 			},
 			{
-				Name:   "get-swap-timeout",
-				Usage:  "Get the duration between swap initiation and t0 and t0 and t1, in seconds",
-				Action: runGetSwapTimeout,
+				Name:   "export",
+				Usage:  "Export the full record of a past swap as JSON",
+				Action: runExport,
 				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagOfferID,
+						Usage:    "ID of swap to export",
+						Required: true,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "version",
-				Usage:  "Get the client and server versions",
-				Action: runGetVersions,
+				Name:   "cancel",
+				Usage:  "Cancel a ongoing swap if possible. Depending on the swap stage, this may not be possible.",
+				Action: runCancel,
 				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagOfferID,
+						Usage: "ID of swap to retrieve info for",
+					},
+					&cli.BoolFlag{
+						Name:  flagAll,
+						Usage: "Cancel all ongoing swaps instead of a single one",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:   "shutdown",
-				Usage:  "Shutdown swapd",
-				Action: runShutdown,
+				Name: "cancel-by-status",
+				Usage: "Cancel all ongoing swaps with the given status. Swaps past the point where they\n" +
+					"can be safely cancelled are reported as skipped.",
+				Action: runCancelByStatus,
 				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagStatus,
+						Usage:    "Status of the swaps to cancel, eg. KeysExchanged",
+						Required: true,
+					},
+					&cli.DurationFlag{
+						Name:  flagOlderThan,
+						Usage: "Only cancel swaps whose status has not changed for at least this duration, eg. 1h",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
 					swapdPortFlag,
 				},
 			},
 			{
-				Name:  "recovery",
-				Usage: "Methods that should only be used as a last resort in the case of an unrecoverable swap error.",
-				Subcommands: []*cli.Command{
-					{
-						Name: "get-contract-swap-info",
-						Usage: "Get information about a swap needed to call the contract functions.\n" +
+				Name:   "clear-offers",
+				Usage:  "Clear current offers. If no offer IDs are provided, clears all current offers.",
+				Action: runClearOffers,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  flagOfferIDs,
+						Usage: "A comma-separated list of offer IDs to delete",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "get-offers",
+				Usage:  "Get all current offers.",
+				Action: runGetOffers,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:  "offers",
+				Usage: "Back up, restore and re-advertise our current offers",
+				Subcommands: []*cli.Command{
+					{
+						Name: "export",
+						Usage: "Write our current offers and their settings to a JSON file.\n" +
+							"Only the offers' parameters are exported, no swap secrets.",
+						Action: runExportOffers,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagFile,
+								Usage:    "Path of the file to write the offers to",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "import",
+						Usage: "Re-make the offers in a file written by export, publishing them under new offer IDs.\n" +
+							"Offers that have expired are skipped.",
+						Action: runImportOffers,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagFile,
+								Usage:    "Path of the file to read the offers from",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "readvertise",
+						Usage: "Re-advertise our offers in the DHT now, eg. after reconnecting after being offline,\n" +
+							"if peers no longer discover them.",
+						Action: runReadvertiseOffers,
+						Flags: []cli.Flag{
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+				},
+			},
+			{
+				Name:   "rejected-takes",
+				Usage:  "Get the take requests for our offers that were rejected before a swap was created.",
+				Action: runGetRejectedTakes,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "get-status",
+				Usage:  "Get the status of a current swap.",
+				Action: runGetStatus,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     flagOfferID,
+						Usage:    "ID of swap to retrieve info for",
+						Required: true,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name: "set-swap-timeout",
+				Usage: "Set the duration between swap initiation and t0 and t0 and t1, in seconds.\n" +
+					"The durations are used for the swaps that we initiate on-chain.",
+				Action: runSetSwapTimeout,
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:  flagDuration,
+						Usage: "Duration of both timeouts, in seconds",
+					},
+					&cli.UintFlag{
+						Name:  flagT0Duration,
+						Usage: "Duration between swap initiation and t0, in seconds. Overrides --" + flagDuration,
+					},
+					&cli.UintFlag{
+						Name:  flagT1Duration,
+						Usage: "Duration between t0 and t1, in seconds. Overrides --" + flagDuration,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "suggested-exchange-rate",
+				Usage:  "Returns the current mainnet exchange rate based on ETH/USD and XMR/USD price feeds.",
+				Action: runSuggestedExchangeRate,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  flagForceRefresh,
+						Usage: "Read the price feeds even if swapd has a cached rate that has not expired",
+					},
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "rate-history",
+				Usage:  "Show the exchange rate samples stored by swapd",
+				Action: runRateHistory,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: flagSince,
+						Usage: "Only show samples taken at or after this time, given as a date (eg. 2023-04-01)," +
+							" an RFC 3339 timestamp, or a duration before now (eg. 720h)",
+					},
+					&cli.StringFlag{
+						Name:  flagUntil,
+						Usage: "Only show samples taken at or before this time, in the same formats as --" + flagSince,
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "get-swap-timeout",
+				Usage:  "Get the duration between swap initiation and t0 and t0 and t1, in seconds",
+				Action: runGetSwapTimeout,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "version",
+				Usage:  "Get the client and server versions",
+				Action: runGetVersions,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "health",
+				Usage:  "Get the health of swapd and its dependencies, exits with an error if swapd is unhealthy",
+				Action: runHealth,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "relayer-stats",
+				Usage:  "Get the claims relayed by swapd since it started and the fees earned",
+				Action: runRelayerStats,
+				Flags: []cli.Flag{
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:   "shutdown",
+				Usage:  "Shutdown swapd",
+				Action: runShutdown,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: flagDrain,
+						Usage: "Stop taking new swaps and publishing offers, and wait for the ongoing\n" +
+							"swaps to complete before shutting down",
+					},
+					&cli.DurationFlag{
+						Name:  flagTimeout,
+						Usage: "Maximum time to wait for ongoing swaps with --drain, from 1s to 25m, defaults to swapd's default of 10m",
+					},
+					swapdHostFlag,
+					tlsFlag,
+					tlsCACertFlag,
+					authTokenFlag,
+					swapdPortFlag,
+				},
+			},
+			{
+				Name:  "recovery",
+				Usage: "Methods that should only be used as a last resort in the case of an unrecoverable swap error.",
+				Subcommands: []*cli.Command{
+					{
+						Name: "get-contract-swap-info",
+						Usage: "Get information about a swap needed to call the contract functions.\n" +
 							"Returns the contract address, the swap's struct as represented in the contract,\n" +
 							"and the hash of the swap's struct, which is used as its contract identifier.\n" +
 							"Note: this is only useful if you plan to manually call the contract functions.",
@@ -350,6 +1090,10 @@ func cliApp() *cli.App {
 								Usage:    "ID of swap for which query for",
 								Required: true,
 							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
 							swapdPortFlag,
 						},
 					},
@@ -366,6 +1110,129 @@ func cliApp() *cli.App {
 								Usage:    "ID of swap for which to get the secret for",
 								Required: true,
 							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "verify-dleq",
+						Usage: "Display and verify the counterparty's DLEq proof for a swap.\n" +
+							"The proof shows that the counterparty's secp256k1 public key, used in the contract,\n" +
+							"and their Monero public spend key correspond to the same secret.",
+						Action: runVerifyDLEqProof,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagOfferID,
+								Usage:    "ID of swap for which to verify the DLEq proof",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "force-complete",
+						Usage: "Mark an ongoing swap as completed if it was already claimed on-chain.\n" +
+							"This is only needed if swapd failed to finalize a swap that finished on-chain.\n" +
+							"Only swaps where swapd provides XMR are supported.",
+						Action: runForceComplete,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagOfferID,
+								Usage:    "ID of swap to complete",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "retry-sweep",
+						Usage: "Retry sweeping the XMR of a swap from the swap wallet to the primary wallet.\n" +
+							"This is only needed if the XMR was claimed, but sweeping it failed and the swap\n" +
+							"is stuck with status SweepingXMR.",
+						Action: runRetrySweep,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagOfferID,
+								Usage:    "ID of swap to retry the sweep of",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "verify",
+						Usage: "Compare the stored state of a swap with its state in the contract.\n" +
+							"Reports any divergence, e.g. a swap stored as XMRLocked that was already completed\n" +
+							"on-chain, without acting on it. The swap's contract info must still be stored.",
+						Action: runVerify,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagOfferID,
+								Usage:    "ID of swap to verify",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "sweep-shared-wallet",
+						Usage: "Sweep any XMR left in the shared wallet of a past swap to the primary wallet.\n" +
+							"The shared wallet is recreated from both parties' swap keys in the recovery\n" +
+							"database, so this only works if the counterparty's secret was learned.",
+						Action: runSweepSharedWallet,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagOfferID,
+								Usage:    "ID of swap to sweep the shared wallet of",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
+							swapdPortFlag,
+						},
+					},
+					{
+						Name: "set-gas-price-override",
+						Usage: "Set the gas price of the next contract transaction of an ongoing swap, bypassing\n" +
+							"swapd's maximum gas price. This is for getting a time-critical claim or refund mined\n" +
+							"during a gas price spike, and may cost significantly more than usual.",
+						Action: runSetGasPriceOverride,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     flagOfferID,
+								Usage:    "ID of swap to override the gas price of",
+								Required: true,
+							},
+							&cli.Uint64Flag{
+								Name:     flagGasPrice,
+								Usage:    "Gas price in gwei, must be above the currently suggested gas price",
+								Required: true,
+							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
 							swapdPortFlag,
 						},
 					},
@@ -381,6 +1248,10 @@ func cliApp() *cli.App {
 								Usage:    "ID of swap for which to call claim()",
 								Required: true,
 							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
 							swapdPortFlag,
 						},
 					},
@@ -396,6 +1267,10 @@ func cliApp() *cli.App {
 								Usage:    "ID of swap for which to call refund()",
 								Required: true,
 							},
+							swapdHostFlag,
+							tlsFlag,
+							tlsCACertFlag,
+							authTokenFlag,
 							swapdPortFlag,
 						},
 					},
@@ -413,6 +1288,33 @@ var (
 		Value:   common.DefaultSwapdPort,
 		EnvVars: []string{"SWAPD_PORT"},
 	}
+	swapdHostFlag = &cli.StringFlag{
+		Name:    flagSwapdHost,
+		Usage:   "Hostname or IP address of swap daemon",
+		Value:   defaultSwapdHost,
+		EnvVars: []string{"SWAPD_HOST"},
+		Action: func(_ *cli.Context, host string) error {
+			if err := cliutil.ValidateHost(host); err != nil {
+				return errInvalidFlagValue(flagSwapdHost, err)
+			}
+			return nil
+		},
+	}
+	tlsFlag = &cli.BoolFlag{
+		Name:    flagTLS,
+		Usage:   "Connect to swap daemon using https:// and wss:// (e.g. when behind a TLS reverse proxy)",
+		EnvVars: []string{"SWAPD_TLS"},
+	}
+	tlsCACertFlag = &cli.StringFlag{
+		Name:    flagTLSCACert,
+		Usage:   "Path to a PEM encoded CA certificate to trust when connecting with --" + flagTLS,
+		EnvVars: []string{"SWAPD_TLS_CA_CERT"},
+	}
+	authTokenFlag = &cli.StringFlag{
+		Name:    flagAuthToken,
+		Usage:   "Token to authenticate with when swap daemon was started with --" + flagAuthToken,
+		EnvVars: []string{"SWAPD_AUTH_TOKEN"},
+	}
 )
 
 func main() {
@@ -422,20 +1324,55 @@ func main() {
 	}
 }
 
-func newRRPClient(ctx *cli.Context) *rpcclient.Client {
-	swapdPort := ctx.Uint(flagSwapdPort)
-	endpoint := fmt.Sprintf("http://127.0.0.1:%d", swapdPort)
-	return rpcclient.NewClient(ctx.Context, endpoint)
+func newRRPClient(ctx *cli.Context) (*rpcclient.Client, error) {
+	return newRRPClientForHost(ctx, swapdHostPort(ctx))
+}
+
+// newRRPClientForHost creates a JSON-RPC client for the swapd instance at hostPort,
+// using the TLS settings of the command.
+func newRRPClientForHost(ctx *cli.Context, hostPort string) (*rpcclient.Client, error) {
+	scheme := "http"
+	if ctx.Bool(flagTLS) {
+		scheme = "https"
+	}
+
+	endpoint := fmt.Sprintf("%s://%s", scheme, hostPort)
+	c, err := rpcclient.NewClient(ctx.Context, endpoint, ctx.String(flagTLSCACert))
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetAuthToken(ctx.String(flagAuthToken))
+	return c, nil
 }
 
 func newWSClient(ctx *cli.Context) (wsclient.WsClient, error) {
-	swapdPort := ctx.Uint(flagSwapdPort)
-	endpoint := fmt.Sprintf("ws://127.0.0.1:%d/ws", swapdPort)
-	return wsclient.NewWsClient(ctx.Context, endpoint)
+	return newWSClientForHost(ctx, swapdHostPort(ctx))
+}
+
+// newWSClientForHost creates a websocket client for the swapd instance at hostPort,
+// using the TLS settings of the command.
+func newWSClientForHost(ctx *cli.Context, hostPort string) (wsclient.WsClient, error) {
+	authToken := ctx.String(flagAuthToken)
+	if ctx.Bool(flagTLS) {
+		tlsConf, err := rpcclient.NewTLSConfig(ctx.String(flagTLSCACert))
+		if err != nil {
+			return nil, err
+		}
+		endpoint := fmt.Sprintf("wss://%s/ws", hostPort)
+		return wsclient.NewAuthWsClient(ctx.Context, endpoint, tlsConf, authToken)
+	}
+
+	endpoint := fmt.Sprintf("ws://%s/ws", hostPort)
+	return wsclient.NewAuthWsClient(ctx.Context, endpoint, nil, authToken)
 }
 
 func runAddresses(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.Addresses()
 	if err != nil {
 		return err
@@ -452,7 +1389,11 @@ func runAddresses(ctx *cli.Context) error {
 }
 
 func runPeers(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.Peers()
 	if err != nil {
 		return err
@@ -468,481 +1409,1879 @@ func runPeers(ctx *cli.Context) error {
 	return nil
 }
 
-func runBalances(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-
-	request := &rpctypes.BalancesRequest{}
-	tokens := ctx.StringSlice(flagToken)
-	for _, tokenAddr := range tokens {
-		if !ethcommon.IsHexAddress(tokenAddr) {
-			return fmt.Errorf("invalid token address: %q", tokenAddr)
-		}
-		request.TokenAddrs = append(request.TokenAddrs, ethcommon.HexToAddress(tokenAddr))
+func runStreams(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
 	}
 
-	balances, err := c.Balances(request)
+	resp, err := c.GetProtocolStreams()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Ethereum address: %s\n", balances.EthAddress)
-	fmt.Printf("ETH Balance: %s\n", balances.WeiBalance.AsEtherString())
-	fmt.Println()
-
-	for _, tokenBalance := range balances.TokenBalances {
-		fmt.Printf("Token: %s\n", tokenBalance.TokenInfo.Address)
-		fmt.Printf("Name: %q\n", tokenBalance.TokenInfo.Name)
-		fmt.Printf("Symbol: %q\n", tokenBalance.TokenInfo.Symbol)
-		fmt.Printf("Balance: %s\n", tokenBalance.AsStandard().Text('f'))
-		fmt.Println()
+	fmt.Println("Open swap protocol streams:")
+	for i, s := range resp.Streams {
+		role := "maker"
+		if s.IsTaker {
+			role = "taker"
+		}
+		fmt.Printf("%d: offer=%s peer=%s role=%s open for %s\n",
+			i+1, s.OfferID, s.PeerID, role, time.Duration(s.OpenFor)*time.Second)
+	}
+	if len(resp.Streams) == 0 {
+		fmt.Println("[none]")
+	}
+	if resp.Truncated {
+		fmt.Println("[more streams are open than were returned]")
 	}
-
-	fmt.Printf("Monero address: %s\n", balances.MoneroAddress)
-	fmt.Printf("XMR Balance: %s\n", balances.PiconeroBalance.AsMoneroString())
-	fmt.Printf("Unlocked XMR balance: %s\n",
-		balances.PiconeroUnlockedBalance.AsMoneroString())
-	fmt.Printf("Blocks to unlock: %d\n", balances.BlocksToUnlock)
 	return nil
 }
 
-func runETHAddress(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	balances, err := c.Balances(nil)
+func runPeerStats(ctx *cli.Context) error {
+	var peerID peer.ID
+	if ctx.IsSet(flagPeerID) {
+		var err error
+		peerID, err = peer.Decode(ctx.String(flagPeerID))
+		if err != nil {
+			return errInvalidFlagValue(flagPeerID, err)
+		}
+	}
+
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Ethereum address: %s\n", balances.EthAddress)
-	code, err := qrcode.New(balances.EthAddress.String(), qrcode.Medium)
+
+	resp, err := c.PeerStats(peerID)
 	if err != nil {
 		return err
 	}
-	fmt.Println(code.ToString(false))
-	return nil
-}
 
-func runXMRAddress(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	balances, err := c.Balances(nil)
-	if err != nil {
-		return err
+	fmt.Println("Completed swaps by peer:")
+	for i, s := range resp.Peers {
+		fmt.Printf("%d: peer=%s %s\n", i+1, s.PeerID, formatPeerStats(s))
 	}
-	fmt.Printf("Monero address: %s\n", balances.MoneroAddress)
-	code, err := qrcode.New(balances.MoneroAddress.String(), qrcode.Medium)
-	if err != nil {
-		return err
+	if len(resp.Peers) == 0 {
+		fmt.Println("[none]")
 	}
-	fmt.Println(code.ToString(true))
 	return nil
 }
 
-func runDiscover(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	provides := ctx.String(flagProvides)
-	peerIDs, err := c.Discover(provides, ctx.Uint64(flagSearchTime))
+func runBlockPeer(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
 	if err != nil {
-		return err
+		return errInvalidFlagValue(flagPeerID, err)
 	}
 
-	for i, peerID := range peerIDs {
-		fmt.Printf("Peer %d: %v\n", i, peerID)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
 	}
-	if len(peerIDs) == 0 {
-		fmt.Println("[none]")
+
+	if err = c.BlockPeer(peerID); err != nil {
+		return err
 	}
 
+	fmt.Printf("Blocked peer %s\n", peerID)
 	return nil
 }
 
-func runQuery(ctx *cli.Context) error {
+func runUnblockPeer(ctx *cli.Context) error {
 	peerID, err := peer.Decode(ctx.String(flagPeerID))
 	if err != nil {
 		return errInvalidFlagValue(flagPeerID, err)
 	}
 
-	c := newRRPClient(ctx)
-	res, err := c.Query(peerID)
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	for i, o := range res.Offers {
-		err = printOffer(c, o, i, "")
-		if err != nil {
-			return err
-		}
+	if err = c.UnblockPeer(peerID); err != nil {
+		return err
 	}
+
+	fmt.Printf("Unblocked peer %s\n", peerID)
 	return nil
 }
 
-func runQueryAll(ctx *cli.Context) error {
-	provides, err := providesStrToVal(ctx.String(flagProvides))
+func runXMRSyncStatus(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	searchTime := ctx.Uint64(flagSearchTime)
-
-	c := newRRPClient(ctx)
-	peerOffers, err := c.QueryAll(provides, searchTime)
+	status, err := c.MoneroSyncStatus()
 	if err != nil {
 		return err
 	}
 
-	for i, po := range peerOffers {
-		if i > 0 {
-			fmt.Println("---")
-		}
-		fmt.Printf("Peer %d:\n", i)
-		fmt.Printf("  Peer ID: %v\n", po.PeerID)
-		fmt.Printf("  Offers:\n")
-		for j, o := range po.Offers {
-			err = printOffer(c, o, j, "    ")
-			if err != nil {
-				return err
-			}
-		}
+	fmt.Printf("Wallet height: %d\n", status.WalletHeight)
+	fmt.Printf("Monerod height: %d\n", status.DaemonHeight)
+	fmt.Printf("Target height: %d\n", status.TargetHeight)
+	if status.IsSynced {
+		fmt.Println("The wallet is fully synced")
+	} else {
+		fmt.Printf("The wallet is %d blocks behind\n", status.TargetHeight-status.WalletHeight)
 	}
 
 	return nil
 }
 
-func runMake(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-
-	min, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMinAmount)
+func runEthSyncStatus(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	max, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMaxAmount)
+	status, err := c.EthSyncStatus()
 	if err != nil {
 		return err
 	}
 
-	ethAssetStr := ctx.String(flagToken)
-	ethAsset := types.EthAssetETH
-	if ethAssetStr != "" {
-		ethAsset = types.EthAsset(ethcommon.HexToAddress(ethAssetStr))
+	fmt.Printf("Chain ID: %d\n", status.ChainID)
+	fmt.Printf("Head block: %d\n", status.HeadBlock)
+	if status.IsSynced {
+		fmt.Println("The Ethereum node is synced")
+	} else {
+		fmt.Printf("The Ethereum node is syncing to block %d, %d blocks behind\n",
+			status.HighestBlock, status.HighestBlock-status.HeadBlock)
+	}
+
+	return nil
+}
+
+func runBalances(ctx *cli.Context) error {
+	request := &rpctypes.BalancesRequest{}
+	tokens := ctx.StringSlice(flagToken)
+	for _, tokenAddr := range tokens {
+		if !ethcommon.IsHexAddress(tokenAddr) {
+			return fmt.Errorf("invalid token address: %q", tokenAddr)
+		}
+		request.TokenAddrs = append(request.TokenAddrs, ethcommon.HexToAddress(tokenAddr))
+	}
+
+	if ctx.Bool(flagWatch) {
+		return watchBalances(ctx, request)
 	}
 
-	exchangeRateDec, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagExchangeRate)
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
-	exchangeRate := coins.ToExchangeRate(exchangeRateDec)
 
-	var otherMin, otherMax *apd.Decimal
-	var symbol string
+	balances, err := c.Balances(request)
+	if err != nil {
+		return err
+	}
 
-	if ethAsset.IsETH() {
-		symbol = "ETH"
+	printBalances(balances, nil)
+	return nil
+}
 
-		if otherMin, err = exchangeRate.ToETH(min); err != nil {
-			return err
-		}
+// watchBalances reprints the balances every --interval until interrupted.
+// When the output is a terminal, the screen is cleared between updates.
+func watchBalances(ctx *cli.Context, request *rpctypes.BalancesRequest) error {
+	interval := ctx.Duration(flagInterval)
+	if interval < time.Second {
+		return fmt.Errorf("--%s must be at least one second", flagInterval)
+	}
 
-		if otherMax, err = exchangeRate.ToETH(max); err != nil {
-			return err
-		}
-	} else {
-		tokenInfo, err := c.TokenInfo(ethAsset.Address()) //nolint:govet
-		if err != nil {
-			return err
-		}
+	sigCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx.Context = sigCtx
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 
-		symbol = strconv.Quote(tokenInfo.Symbol)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-		if otherMin, err = exchangeRate.ToERC20Amount(min, tokenInfo); err != nil {
+	var prev *rpctypes.BalancesResponse
+	for {
+		balances, err := c.Balances(request)
+		switch {
+		case sigCtx.Err() != nil:
+			return nil
+		case err != nil && prev == nil:
+			// fail fast if we never got the balances, as it's likely a misconfiguration
 			return err
+		case err != nil:
+			fmt.Printf("%s > Failed to get balances: %s\n", time.Now().Format(common.TimeFmtSecs), err)
+		default:
+			if !color.NoColor {
+				fmt.Print(clearScreen)
+			} else if prev != nil {
+				fmt.Println()
+			}
+			fmt.Printf("%s (every %s, Ctrl-C to exit)\n\n", time.Now().Format(common.TimeFmtSecs), interval)
+			printBalances(balances, prev)
+			prev = balances
 		}
 
-		if otherMax, err = exchangeRate.ToERC20Amount(max, tokenInfo); err != nil {
-			return err
+		select {
+		case <-ticker.C:
+		case <-sigCtx.Done():
+			return nil
 		}
-
 	}
+}
 
-	printOfferSummary := func(offerResp *rpctypes.MakeOfferResponse) {
-		fmt.Println("Published:")
-		fmt.Printf("\tOffer ID:  %s\n", offerResp.OfferID)
-		fmt.Printf("\tPeer ID:   %s\n", offerResp.PeerID)
-		fmt.Printf("\tTaker Min: %s %s\n", otherMin.Text('f'), symbol)
-		fmt.Printf("\tTaker Max: %s %s\n", otherMax.Text('f'), symbol)
+// printBalances prints the balances. If prev is not nil, the ETH and XMR
+// balances that changed since prev are highlighted. Blocks to unlock are
+// highlighted while the XMR balance is not fully unlocked.
+func printBalances(balances *rpctypes.BalancesResponse, prev *rpctypes.BalancesResponse) {
+	changed := color.New(color.Bold, color.FgGreen).SprintFunc()
+	locked := color.New(color.Bold, color.FgYellow).SprintFunc()
+	highlight := func(s string, isChanged bool) string {
+		if isChanged {
+			return changed(s)
+		}
+		return s
 	}
 
-	alwaysUseRelayer := ctx.Bool(flagUseRelayer)
+	ethBalance := balances.WeiBalance.AsEtherString()
+	fmt.Printf("Ethereum address: %s\n", balances.EthAddress)
+	fmt.Printf("ETH Balance: %s\n",
+		highlight(ethBalance, prev != nil && prev.WeiBalance.AsEtherString() != ethBalance))
+	fmt.Println()
 
-	if !ctx.Bool(flagDetached) {
-		wsc, err := newWSClient(ctx) //nolint:govet
-		if err != nil {
-			return err
-		}
-		defer wsc.Close()
+	for _, tokenBalance := range balances.TokenBalances {
+		fmt.Printf("Token: %s\n", tokenBalance.TokenInfo.Address)
+		fmt.Printf("Name: %q\n", tokenBalance.TokenInfo.Name)
+		fmt.Printf("Symbol: %q\n", tokenBalance.TokenInfo.Symbol)
+		fmt.Printf("Balance: %s\n", tokenBalance.AsStandard().Text('f'))
+		fmt.Println()
+	}
 
-		resp, statusCh, err := wsc.MakeOfferAndSubscribe(
-			min,
-			max,
-			exchangeRate,
-			ethAsset,
-			alwaysUseRelayer,
-		)
-		if err != nil {
-			return err
-		}
+	xmrBalance := balances.PiconeroBalance.AsMoneroString()
+	unlockedBalance := balances.PiconeroUnlockedBalance.AsMoneroString()
+	fmt.Printf("Monero address: %s\n", balances.MoneroAddress)
+	fmt.Printf("XMR Balance: %s\n",
+		highlight(xmrBalance, prev != nil && prev.PiconeroBalance.AsMoneroString() != xmrBalance))
+	fmt.Printf("Unlocked XMR balance: %s\n",
+		highlight(unlockedBalance, prev != nil && prev.PiconeroUnlockedBalance.AsMoneroString() != unlockedBalance))
 
-		printOfferSummary(resp)
+	blocksToUnlock := fmt.Sprintf("%d", balances.BlocksToUnlock)
+	if balances.BlocksToUnlock > 0 {
+		blocksToUnlock = locked(blocksToUnlock)
+	}
+	fmt.Printf("Blocks to unlock: %s\n", blocksToUnlock)
+}
 
-		for stage := range statusCh {
-			fmt.Printf("%s > Stage updated: %s\n", time.Now().Format(common.TimeFmtSecs), stage)
-			if !stage.IsOngoing() {
-				return nil
-			}
-		}
+func runETHAddress(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 
-		return nil
+	balances, err := c.Balances(nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Ethereum address: %s\n", balances.EthAddress)
+	code, err := qrcode.New(balances.EthAddress.String(), qrcode.Medium)
+	if err != nil {
+		return err
 	}
+	fmt.Println(code.ToString(false))
+	return nil
+}
 
-	resp, err := c.MakeOffer(min, max, exchangeRate, ethAsset, alwaysUseRelayer)
+func runXMRAddress(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	printOfferSummary(resp)
+	balances, err := c.Balances(nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Monero address: %s\n", balances.MoneroAddress)
+	code, err := qrcode.New(balances.MoneroAddress.String(), qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	fmt.Println(code.ToString(true))
 	return nil
 }
 
-func runTake(ctx *cli.Context) error {
-	peerID, err := peer.Decode(ctx.String(flagPeerID))
+func runDiscover(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
 	if err != nil {
-		return errInvalidFlagValue(flagPeerID, err)
+		return err
 	}
-	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+
+	provides := ctx.String(flagProvides)
+	peerIDs, err := c.Discover(provides, ctx.Uint64(flagSearchTime))
 	if err != nil {
-		return errInvalidFlagValue(flagOfferID, err)
+		return err
 	}
 
-	providesAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagProvidesAmount)
+	for i, peerID := range peerIDs {
+		fmt.Printf("Peer %d: %v\n", i, peerID)
+	}
+	if len(peerIDs) == 0 {
+		fmt.Println("[none]")
+	}
+
+	return nil
+}
+
+func runQuery(ctx *cli.Context) error {
+	if ctx.IsSet(flagPeerID) == ctx.IsSet(flagMultiaddr) {
+		return fmt.Errorf("exactly one of --%s or --%s must be provided", flagPeerID, flagMultiaddr)
+	}
+
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	if !ctx.Bool(flagDetached) {
-		wsc, err := newWSClient(ctx)
+	var res *rpctypes.QueryPeerResponse
+	if ctx.IsSet(flagMultiaddr) {
+		var err error
+		res, err = c.QueryAddr(ctx.String(flagMultiaddr))
 		if err != nil {
 			return err
 		}
-		defer wsc.Close()
+	} else {
+		peerID, err := peer.Decode(ctx.String(flagPeerID))
+		if err != nil {
+			return errInvalidFlagValue(flagPeerID, err)
+		}
 
-		statusCh, err := wsc.TakeOfferAndSubscribe(peerID, offerID, providesAmount)
+		res, err = c.Query(peerID)
 		if err != nil {
 			return err
 		}
+	}
 
-		fmt.Printf("Initiated swap with offer ID %s\n", offerID)
-
-		for stage := range statusCh {
-			fmt.Printf("%s > Stage updated: %s\n", time.Now().Format(common.TimeFmtSecs), stage)
-			if !stage.IsOngoing() {
-				return nil
-			}
+	for i, o := range res.Offers {
+		err = printOffer(c, o, i, "")
+		if err != nil {
+			return err
 		}
-
-		return nil
 	}
+	return nil
+}
 
-	c := newRRPClient(ctx)
-	if err := c.TakeOffer(peerID, offerID, providesAmount); err != nil {
+func runQueryAll(ctx *cli.Context) error {
+	provides, err := providesStrToVal(ctx.String(flagProvides))
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Initiated swap with offer ID %s\n", offerID)
-	return nil
-}
+	searchTime := ctx.Uint64(flagSearchTime)
 
-func runGetOngoingSwap(ctx *cli.Context) error {
-	var offerID *types.Hash
+	filter, err := readOfferFilter(ctx)
+	if err != nil {
+		return err
+	}
 
-	if ctx.IsSet(flagOfferID) {
-		hash, err := types.HexToHash(ctx.String(flagOfferID))
-		if err != nil {
-			return errInvalidFlagValue(flagOfferID, err)
-		}
-		offerID = &hash
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
 	}
 
-	c := newRRPClient(ctx)
-	resp, err := c.GetOngoingSwap(offerID)
+	peerOffers, err := c.QueryAll(provides, searchTime)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Ongoing swaps:")
-	if len(resp.Swaps) == 0 {
-		fmt.Println("[none]")
-		return nil
+	var peerStats map[peer.ID]*rpctypes.PeerStats
+	if ctx.Bool(flagShowPeerStats) {
+		resp, err := c.PeerStats("") //nolint:govet
+		if err != nil {
+			return err
+		}
+
+		peerStats = make(map[peer.ID]*rpctypes.PeerStats, len(resp.Peers))
+		for _, s := range resp.Peers {
+			peerStats[s.PeerID] = s
+		}
 	}
 
-	for i, info := range resp.Swaps {
-		if i > 0 {
-			fmt.Printf("---\n")
+	i := 0
+	for _, po := range peerOffers {
+		var offers []*types.Offer
+		for _, o := range po.Offers {
+			if filter.matches(o) {
+				offers = append(offers, o)
+			}
 		}
 
-		providedCoin, receivedCoin, err := providedAndReceivedSymbols(c, info.Provided, info.EthAsset)
-		if err != nil {
-			return err
+		// peers without any matching offers are not printed
+		if len(offers) == 0 {
+			continue
+		}
+
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Printf("Peer %d:\n", i)
+		fmt.Printf("  Peer ID: %v\n", po.PeerID)
+		if peerStats != nil {
+			stats, has := peerStats[po.PeerID]
+			if !has {
+				stats = &rpctypes.PeerStats{PeerID: po.PeerID}
+			}
+			fmt.Printf("  Swaps with us: %s\n", formatPeerStats(stats))
+		}
+		fmt.Printf("  Offers:\n")
+		for j, o := range offers {
+			err = printOffer(c, o, j, "    ")
+			if err != nil {
+				return err
+			}
+		}
+		i++
+	}
+
+	return nil
+}
+
+func runQueryRelayers(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	relayers, err := c.QueryRelayers(ctx.Uint64(flagSearchTime))
+	if err != nil {
+		return err
+	}
+
+	printRelayers(relayers)
+	return nil
+}
+
+func runRelayerStatus(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.RelayerStatus(ctx.Uint64(flagSearchTime))
+	if err != nil {
+		return err
+	}
+
+	if resp.IsRelayer {
+		fmt.Printf("This node is a relayer with a fee of %s ETH\n", resp.Fee.Text('f'))
+		fmt.Printf("  Claims relayed: %d\n", resp.NumRelayed)
+		fmt.Printf("  Fees earned: %s ETH\n", resp.TotalFees.Text('f'))
+	} else {
+		fmt.Println("This node is not a relayer")
+	}
+
+	printRelayers(resp.Relayers)
+	return nil
+}
+
+func printRelayers(relayers []*rpctypes.RelayerWithFee) {
+	fmt.Println("Relayers:")
+	if len(relayers) == 0 {
+		fmt.Println("[none]")
+		return
+	}
+
+	for i, r := range relayers {
+		fmt.Printf("Relayer %d:\n", i)
+		fmt.Printf("  Peer ID: %s\n", r.PeerID)
+		fmt.Printf("  Fee: %s\n", formatRelayerFee(r.Fee))
+	}
+}
+
+// formatRelayerFee formats the fee of a relayer, which is nil if the relayer
+// did not answer the fee query.
+func formatRelayerFee(fee *apd.Decimal) string {
+	if fee == nil {
+		return fmt.Sprintf("unknown (%s ETH if the relayer predates fee queries)", coins.RelayerFeeETH.Text('f'))
+	}
+	return fmt.Sprintf("%s ETH", fee.Text('f'))
+}
+
+// printRelayerFees prints the fees that discovered relayers charge to claim
+// for us, for offers that always claim using a relayer.
+func printRelayerFees(c *rpcclient.Client) {
+	// relayers are discovered for the same time as when swapd claims
+	const relayerSearchTimeSecs = 3
+
+	relayers, err := c.QueryRelayers(relayerSearchTimeSecs)
+	if err != nil {
+		fmt.Printf("WARNING: unable to query relayer fees: %s\n", err)
+		return
+	}
+
+	if len(relayers) == 0 {
+		fmt.Printf("No relayers found, claims will be relayed by the taker for %s ETH\n",
+			coins.RelayerFeeETH.Text('f'))
+		return
+	}
+
+	fmt.Printf("Claims will be relayed by the cheapest of %d discovered relayers, whose fee is %s\n",
+		len(relayers), formatRelayerFee(relayers[0].Fee))
+}
+
+func runMake(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	ethAssetStr := ctx.String(flagToken)
+	ethAsset := types.EthAssetETH
+	if ethAssetStr != "" {
+		ethAsset = types.EthAsset(ethcommon.HexToAddress(ethAssetStr))
+	}
+
+	var defaults *rpctypes.GetOfferDefaultsResponse
+	if ctx.Bool(flagReuseLast) {
+		var err error
+		defaults, err = c.GetOfferDefaults(ethAsset)
+		if err != nil {
+			return err
+		}
+	} else {
+		defaults = new(rpctypes.GetOfferDefaultsResponse)
+	}
+
+	min, err := readUnsignedDecimalFlagOrDefault(ctx, flagMinAmount, defaults.MinAmount)
+	if err != nil {
+		return err
+	}
+
+	max, err := readUnsignedDecimalFlagOrDefault(ctx, flagMaxAmount, defaults.MaxAmount)
+	if err != nil {
+		return err
+	}
+
+	var exchangeRate, marketRate *coins.ExchangeRate
+	var markup *apd.Decimal
+	var repricing *types.Repricing
+	repriceInterval := ctx.Duration(flagRepriceInterval)
+
+	if ctx.IsSet(flagMarkup) {
+		switch {
+		case ctx.IsSet(flagExchangeRate):
+			return fmt.Errorf("--%s and --%s cannot be used together", flagMarkup, flagExchangeRate)
+		case !ethAsset.IsETH():
+			return fmt.Errorf("--%s is only supported for offers for ETH", flagMarkup)
+		case repriceInterval != 0 && repriceInterval < time.Minute:
+			return fmt.Errorf("--%s must be at least one minute", flagRepriceInterval)
+		case repriceInterval != 0 && ctx.Bool(flagDetached):
+			return fmt.Errorf("--%s cannot be used with --%s", flagRepriceInterval, flagDetached)
+		case repriceInterval != 0 && ctx.Bool(flagPersistent):
+			return fmt.Errorf("--%s cannot be used with --%s", flagRepriceInterval, flagPersistent)
+		case repriceInterval != 0 && ctx.IsSet(flagFloorRate):
+			return fmt.Errorf("--%s cannot be used with --%s", flagRepriceInterval, flagFloorRate)
+		}
+
+		if markup, err = readMarkupFlag(ctx); err != nil {
+			return err
+		}
+
+		if exchangeRate, marketRate, err = markupExchangeRate(c, markup); err != nil {
+			return err
+		}
+
+		if ctx.IsSet(flagFloorRate) {
+			floorRate, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagFloorRate) //nolint:govet
+			if err != nil {
+				return err
+			}
+
+			repricing = &types.Repricing{Spread: markup, FloorRate: coins.ToExchangeRate(floorRate)}
+			if exchangeRate, err = repricing.RepricedRate(marketRate); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, flag := range []string{flagRepriceInterval, flagFloorRate} {
+			if ctx.IsSet(flag) {
+				return fmt.Errorf("--%s requires --%s", flag, flagMarkup)
+			}
+		}
+
+		exchangeRateDec, err := readUnsignedDecimalFlagOrDefault( //nolint:govet
+			ctx,
+			flagExchangeRate,
+			defaults.ExchangeRate.Decimal(),
+		)
+		if err != nil {
+			return err
+		}
+		exchangeRate = coins.ToExchangeRate(exchangeRateDec)
+
+		if ctx.Bool(flagReuseLast) && !ctx.IsSet(flagExchangeRate) && ethAsset.IsETH() {
+			warnIfFarFromMarketRate(c, exchangeRate)
+		}
+	}
+
+	var otherMin, otherMax *apd.Decimal
+	var symbol string
+
+	if ethAsset.IsETH() {
+		symbol = "ETH"
+
+		if otherMin, err = exchangeRate.ToETH(min); err != nil {
+			return err
+		}
+
+		if otherMax, err = exchangeRate.ToETH(max); err != nil {
+			return err
+		}
+	} else {
+		tokenInfo, err := c.TokenInfo(ethAsset.Address()) //nolint:govet
+		if err != nil {
+			return err
+		}
+
+		symbol = strconv.Quote(tokenInfo.Symbol)
+
+		if otherMin, err = exchangeRate.ToERC20Amount(min, tokenInfo); err != nil {
+			return err
+		}
+
+		if otherMax, err = exchangeRate.ToERC20Amount(max, tokenInfo); err != nil {
+			return err
+		}
+
+	}
+
+	alwaysUseRelayer := ctx.Bool(flagUseRelayer)
+
+	expiresIn := ctx.Duration(flagExpiresIn)
+	if expiresIn < 0 || (expiresIn > 0 && expiresIn < time.Second) {
+		return fmt.Errorf("--%s must be at least one second", flagExpiresIn)
+	}
+
+	var minTakerRep *types.MinTakerReputation
+	if ctx.IsSet(flagMinTakerRate) || ctx.Bool(flagRejectUnknown) {
+		minTakerRep = &types.MinTakerReputation{
+			SuccessRate:   new(apd.Decimal),
+			RejectUnknown: ctx.Bool(flagRejectUnknown),
+		}
+		if ctx.IsSet(flagMinTakerRate) {
+			if minTakerRep.SuccessRate, err = cliutil.ReadUnsignedDecimalFlag(ctx, flagMinTakerRate); err != nil {
+				return err
+			}
+		}
+	}
+
+	req := &rpctypes.MakeOfferRequest{
+		MinAmount:          min,
+		MaxAmount:          max,
+		ExchangeRate:       exchangeRate,
+		EthAsset:           ethAsset,
+		UseRelayer:         alwaysUseRelayer,
+		ExpiresIn:          uint64(expiresIn.Seconds()),
+		MinTakerReputation: minTakerRep,
+		Persistent:         ctx.Bool(flagPersistent),
+		Repricing:          repricing,
+		IdempotencyKey:     ctx.String(flagIdempotencyKey),
+	}
+
+	// relayers are only used to claim ETH
+	if alwaysUseRelayer && ethAsset.IsETH() {
+		printRelayerFees(c)
+	}
+
+	printOfferSummary := func(offerResp *rpctypes.MakeOfferResponse) {
+		fmt.Println("Published:")
+		fmt.Printf("\tOffer ID:  %s\n", offerResp.OfferID)
+		fmt.Printf("\tPeer ID:   %s\n", offerResp.PeerID)
+		fmt.Printf("\tTaker Min: %s %s\n", otherMin.Text('f'), symbol)
+		fmt.Printf("\tTaker Max: %s %s\n", otherMax.Text('f'), symbol)
+		if markup != nil {
+			sign := "+"
+			if markup.Negative {
+				sign = ""
+			}
+			fmt.Printf("\tRate:      %s (market rate %s %s%s%%)\n", exchangeRate, marketRate, sign, markup.Text('f'))
+		}
+		if repricing != nil {
+			fmt.Printf("\tFloor:     %s (repriced by swapd as the market rate changes)\n", repricing.FloorRate)
+		}
+		if expiresIn > 0 {
+			fmt.Printf("\tExpires:   %s\n", time.Now().Add(expiresIn).Format(common.TimeFmtSecs))
+		}
+	}
+
+	if ctx.Bool(flagDryRun) {
+		summary, err := c.ValidateOfferRequest(req) //nolint:govet
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Valid offer (not published):")
+		fmt.Printf("\tMaker Min:        %s XMR\n", min.Text('f'))
+		fmt.Printf("\tMaker Max:        %s XMR\n", max.Text('f'))
+		fmt.Printf("\tTaker Min:        %s %s\n", summary.TakerMinAmount.Text('f'), symbol)
+		fmt.Printf("\tTaker Max:        %s %s\n", summary.TakerMaxAmount.Text('f'), symbol)
+		fmt.Printf("\tUnlocked Balance: %s XMR\n", summary.UnlockedBalance.Text('f'))
+		return nil
+	}
+
+	if repriceInterval != 0 {
+		resp, err := c.MakeOfferRequest(req) //nolint:govet
+		if err != nil {
+			return err
+		}
+
+		printOfferSummary(resp)
+		return repriceOffer(ctx, c, req, resp.OfferID, markup, repriceInterval)
+	}
+
+	if !ctx.Bool(flagDetached) {
+		wsc, err := newWSClient(ctx) //nolint:govet
+		if err != nil {
+			return err
+		}
+		defer wsc.Close()
+
+		resp, statusCh, err := wsc.MakeOfferRequestAndSubscribe(req)
+		if err != nil {
+			return err
+		}
+
+		printOfferSummary(resp)
+
+		for stage := range statusCh {
+			fmt.Printf("%s > Stage updated: %s\n", time.Now().Format(common.TimeFmtSecs), stage)
+			if !stage.IsOngoing() {
+				return nil
+			}
+		}
+
+		return nil
+	}
+
+	resp, err := c.MakeOfferRequest(req)
+	if err != nil {
+		return err
+	}
+
+	printOfferSummary(resp)
+	return nil
+}
+
+// markupExchangeRate returns the market rate of the price feed with the markup
+// applied, along with the market rate itself.
+func markupExchangeRate(c *rpcclient.Client, markup *apd.Decimal) (*coins.ExchangeRate, *coins.ExchangeRate, error) {
+	market, err := c.SuggestedExchangeRate(false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the market rate: %w", err)
+	}
+
+	rate, err := applyMarkup(market.ExchangeRate, markup)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rate, market.ExchangeRate, nil
+}
+
+// repriceOffer re-queries the price feed every interval until interrupted and,
+// if the marked up rate changed, replaces the offer with a new offer at the
+// new rate. It returns once the offer is no longer listed, which happens when
+// it was taken, expired or was cleared.
+func repriceOffer(
+	ctx *cli.Context,
+	c *rpcclient.Client,
+	req *rpctypes.MakeOfferRequest,
+	offerID types.Hash,
+	markup *apd.Decimal,
+	interval time.Duration,
+) error {
+	sigCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var expiresAt time.Time
+	if req.ExpiresIn > 0 {
+		expiresAt = time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+	}
+
+	fmt.Printf("Re-pricing the offer every %s (Ctrl-C to stop re-pricing, the offer stays listed)\n", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-sigCtx.Done():
+			return nil
+		}
+
+		now := time.Now().Format(common.TimeFmtSecs)
+
+		offers, err := c.GetOffers()
+		if err != nil {
+			fmt.Printf("%s > Failed to get our offers: %s\n", now, err)
+			continue
+		}
+
+		listed := false
+		for _, o := range offers.Offers {
+			if o.ID == offerID {
+				listed = true
+				break
+			}
+		}
+
+		if !listed {
+			fmt.Printf("%s > Offer %s is no longer listed, stopping re-pricing\n", now, offerID)
+			return nil
+		}
+
+		rate, market, err := markupExchangeRate(c, markup)
+		if err != nil {
+			fmt.Printf("%s > Failed to re-price offer %s: %s\n", now, offerID, err)
+			continue
+		}
+
+		if rate.Cmp(req.ExchangeRate) == 0 {
+			continue
+		}
+
+		if !expiresAt.IsZero() {
+			remaining := time.Until(expiresAt)
+			if remaining < time.Second {
+				fmt.Printf("%s > Offer %s expired, stopping re-pricing\n", now, offerID)
+				return nil
+			}
+			req.ExpiresIn = uint64(remaining.Seconds())
+		}
+
+		if err = c.ClearOffers([]types.Hash{offerID}); err != nil {
+			fmt.Printf("%s > Failed to clear offer %s to re-price it: %s\n", now, offerID, err)
+			continue
+		}
+
+		// the offer may have been taken after we listed our offers, in which
+		// case it must not be made again
+		ongoing, err := c.GetOngoingSwap(&offerID)
+		if err == nil && len(ongoing.Swaps) > 0 {
+			fmt.Printf("%s > Offer %s was taken, stopping re-pricing\n", now, offerID)
+			return nil
+		}
+
+		req.ExchangeRate = rate
+		// the key is for the first offer, the re-priced offers are new offers
+		req.IdempotencyKey = ""
+		resp, err := c.MakeOfferRequest(req)
+		if err != nil {
+			return fmt.Errorf("offer %s was cleared, but making its re-priced offer failed: %w", offerID, err)
+		}
+
+		fmt.Printf("%s > Re-priced offer %s as offer %s at rate %s (market rate %s)\n",
+			now, offerID, resp.OfferID, rate, market)
+		offerID = resp.OfferID
+	}
+}
+
+// warnIfFarFromMarketRate prints a warning if the exchange rate differs from
+// the current market rate by more than maxMarketRateDiffPercent.
+func warnIfFarFromMarketRate(c *rpcclient.Client, exchangeRate *coins.ExchangeRate) {
+	market, err := c.SuggestedExchangeRate(false)
+	if err != nil {
+		fmt.Printf("WARNING: unable to compare the reused exchange rate to the market rate: %s\n", err)
+		return
+	}
+
+	diff, err := exchangeRate.PercentDiff(market.ExchangeRate)
+	if err != nil {
+		fmt.Printf("WARNING: unable to compare the reused exchange rate to the market rate: %s\n", err)
+		return
+	}
+
+	if diff.Cmp(apd.New(maxMarketRateDiffPercent, 0)) > 0 {
+		diffPct, _ := diff.Float64()
+		fmt.Printf("WARNING: reused exchange rate %s differs from the market rate %s by %.1f%%\n",
+			exchangeRate, market.ExchangeRate, diffPct)
+	}
+}
+
+func runTake(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	providesAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	var maxExchangeRate *coins.ExchangeRate
+	if ctx.IsSet(flagMaxExchangeRate) {
+		rate, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMaxExchangeRate) //nolint:govet
+		if err != nil {
+			return err
+		}
+		maxExchangeRate = coins.ToExchangeRate(rate)
+	}
+
+	return takeOffer(ctx, &rpctypes.TakeOfferRequest{
+		PeerID:          peerID,
+		OfferID:         offerID,
+		ProvidesAmount:  providesAmount,
+		MaxExchangeRate: maxExchangeRate,
+	})
+}
+
+// takeOffer takes the offer and, unless --detached is set, prints the swap's
+// status updates until it completes.
+func takeOffer(ctx *cli.Context, req *rpctypes.TakeOfferRequest) error {
+	if !ctx.Bool(flagDetached) {
+		wsc, err := newWSClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer wsc.Close()
+
+		statusCh, err := wsc.TakeOfferRequestAndSubscribe(req)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Initiated swap with offer ID %s\n", req.OfferID)
+
+		for stage := range statusCh {
+			fmt.Printf("%s > Stage updated: %s\n", time.Now().Format(common.TimeFmtSecs), stage)
+			if !stage.IsOngoing() {
+				return nil
+			}
+		}
+
+		return nil
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.TakeOfferRequest(req); err != nil {
+		return err
+	}
+
+	fmt.Printf("Initiated swap with offer ID %s\n", req.OfferID)
+	return nil
+}
+
+func runEstimate(ctx *cli.Context) error {
+	peerID, err := peer.Decode(ctx.String(flagPeerID))
+	if err != nil {
+		return errInvalidFlagValue(flagPeerID, err)
+	}
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	providesAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.EstimateTake(peerID, offerID, providesAmount)
+	if err != nil {
+		return err
+	}
+
+	symbol, err := ethAssetSymbol(c, resp.EthAsset)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Offer %s\n", offerID)
+	fmt.Printf("\tExchange Rate: %s\n", resp.ExchangeRate)
+	fmt.Printf("\tAccepts: %s-%s %s\n",
+		resp.MinProvidesAmount.Text('f'), resp.MaxProvidesAmount.Text('f'), symbol)
+	if resp.Clamped {
+		fmt.Printf("\tProvided: %s %s (clamped from %s %s, taking the offer with %s %s will fail)\n",
+			resp.ProvidesAmount.Text('f'), symbol, providesAmount.Text('f'), symbol, providesAmount.Text('f'), symbol)
+	} else {
+		fmt.Printf("\tProvided: %s %s\n", resp.ProvidesAmount.Text('f'), symbol)
+	}
+	fmt.Printf("\tReceived: %s XMR\n", resp.ReceivedAmount.Text('f'))
+
+	return nil
+}
+
+func runGasEstimate(ctx *cli.Context) error {
+	ethAsset := types.EthAssetETH
+	if ctx.IsSet(flagToken) {
+		tokenAddr := ctx.String(flagToken)
+		if !ethcommon.IsHexAddress(tokenAddr) {
+			return errInvalidFlagValue(flagToken, errors.New("not a valid ethereum address"))
+		}
+		ethAsset = types.EthAsset(ethcommon.HexToAddress(tokenAddr))
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.EstimateGas(ethAsset)
+	if err != nil {
+		return err
+	}
+
+	gasPriceGwei := new(apd.Decimal).Set(resp.GasPrice.Decimal())
+	gasPriceGwei.Exponent -= 9
+	_, _ = gasPriceGwei.Reduce(gasPriceGwei)
+
+	fmt.Printf("Gas price: %s Gwei\n", gasPriceGwei.Text('f'))
+	fmt.Printf("Taker, swap succeeds: %s ETH (%d gas)\n", resp.Success.Cost.Text('f'), resp.Success.Gas)
+	fmt.Printf("Taker, swap refunded: %s ETH (%d gas)\n", resp.Refund.Cost.Text('f'), resp.Refund.Gas)
+	fmt.Printf("Maker, self-claim:    %s ETH (%d gas)\n", resp.SelfClaim.Cost.Text('f'), resp.SelfClaim.Gas)
+	if resp.RelayedClaimFee != nil {
+		fmt.Printf("Maker, relayed claim: %s ETH fee, deducted from the swap amount\n",
+			resp.RelayedClaimFee.Text('f'))
+	} else {
+		fmt.Printf("Maker, relayed claim: not available for ERC20 swaps\n")
+	}
+	fmt.Printf("Estimates use the most gas each transaction has been seen to use.\n")
+
+	return nil
+}
+
+func runGetOngoingSwap(ctx *cli.Context) error {
+	var offerID *types.Hash
+
+	if ctx.IsSet(flagOfferID) {
+		hash, err := types.HexToHash(ctx.String(flagOfferID))
+		if err != nil {
+			return errInvalidFlagValue(flagOfferID, err)
+		}
+		offerID = &hash
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetOngoingSwap(offerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Ongoing swaps:")
+	if len(resp.Swaps) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	for i, info := range resp.Swaps {
+		if i > 0 {
+			fmt.Printf("---\n")
+		}
+
+		providedCoin, receivedCoin, err := providedAndReceivedSymbols(c, info.Provided, info.EthAsset)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ID: %s\n", info.ID)
+		fmt.Printf("Start time: %s\n", info.StartTime.Format(common.TimeFmtSecs))
+		fmt.Printf("Provided: %s %s\n", info.ProvidedAmount.Text('f'), providedCoin)
+		fmt.Printf("Receiving: %s %s\n", info.ExpectedAmount.Text('f'), receivedCoin)
+		fmt.Printf("Exchange Rate: %s ETH/XMR\n", info.ExchangeRate)
+		fmt.Printf("Status: %s\n", info.Status)
+		fmt.Printf("Time status was last updated: %s\n", info.LastStatusUpdateTime.Format(common.TimeFmtSecs))
+		if info.Timeout0 != nil && info.Timeout1 != nil {
+			fmt.Printf("First timeout: %s\n", info.Timeout0.Format(common.TimeFmtSecs))
+			fmt.Printf("Second timeout: %s\n", info.Timeout1.Format(common.TimeFmtSecs))
+		}
+		fmt.Printf("Estimated time to completion: %s\n", info.EstimatedTimeToCompletion)
+	}
+
+	return nil
+}
+
+func runTakeAll(ctx *cli.Context) error {
+	var offerIDs []types.Hash
+	for _, offerIDStr := range strings.Split(ctx.String(flagOfferIDs), ",") {
+		offerID, err := types.HexToHash(strings.TrimSpace(offerIDStr))
+		if err != nil {
+			return errInvalidFlagValue(flagOfferIDs, err)
+		}
+		offerIDs = append(offerIDs, offerID)
+	}
+
+	var peerIDs []peer.ID
+	for _, peerIDStr := range strings.Split(ctx.String(flagPeerID), ",") {
+		peerID, err := peer.Decode(strings.TrimSpace(peerIDStr))
+		if err != nil {
+			return errInvalidFlagValue(flagPeerID, err)
+		}
+		peerIDs = append(peerIDs, peerID)
+	}
+
+	// a single peer ID is used for all offers
+	if len(peerIDs) == 1 {
+		for len(peerIDs) < len(offerIDs) {
+			peerIDs = append(peerIDs, peerIDs[0])
+		}
+	}
+	if len(peerIDs) != len(offerIDs) {
+		return fmt.Errorf("got %d peer IDs for %d offer IDs, pass a single peer ID or one for each offer",
+			len(peerIDs), len(offerIDs))
+	}
+
+	totalAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	split := ctx.String(flagSplit)
+	decimals := uint8(coins.NumEtherDecimals)
+	if split == splitEven {
+		// the shares can't have more decimal places than the offers' assets
+		decimals, err = offersMinDecimals(c, peerIDs, offerIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	providesAmount, err := takeAllAmount(totalAmount, len(offerIDs), split, decimals)
+	if errors.Is(err, errAmountTooSmallToSplit) {
+		return errInvalidFlagValue(flagProvidesAmount, err)
+	}
+	if err != nil {
+		return errInvalidFlagValue(flagSplit, err)
+	}
+
+	takeErrs := make([]error, len(offerIDs))
+	numFailed := 0
+	for i, offerID := range offerIDs {
+		takeErrs[i] = c.TakeOffer(peerIDs[i], offerID, providesAmount)
+		if takeErrs[i] != nil {
+			numFailed++
+		}
+	}
+
+	fmt.Printf("Took %d of %d offers, providing %s each:\n",
+		len(offerIDs)-numFailed, len(offerIDs), providesAmount.Text('f'))
+	for i, offerID := range offerIDs {
+		if takeErrs[i] != nil {
+			fmt.Printf("\t%s: failed: %s\n", offerID, takeErrs[i])
+			continue
+		}
+		fmt.Printf("\t%s: initiated swap\n", offerID)
+	}
+
+	if numFailed == len(offerIDs) {
+		return fmt.Errorf("failed to take any of the %d offers", len(offerIDs))
+	}
+
+	return nil
+}
+
+func runTakeBest(ctx *cli.Context) error {
+	providesAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	ethAsset := types.EthAssetETH
+	if ctx.IsSet(flagToken) {
+		ethAsset = types.EthAsset(ethcommon.HexToAddress(ctx.String(flagToken)))
+	}
+
+	selector, err := newOfferSelector(ctx.String(flagStrategy), ctx.String(flagWeights))
+	if err != nil {
+		return err
+	}
+
+	filter, err := readOfferFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	peerOffers, err := c.QueryAll(coins.ProvidesXMR, ctx.Uint64(flagSearchTime))
+	if err != nil {
+		return err
+	}
+
+	pastSwaps, err := c.GetPastSwaps(new(rpc.GetPastRequest))
+	if err != nil {
+		return err
+	}
+	successRates := makerSuccessRates(pastSwaps.Swaps)
+
+	var candidates []*offerCandidate
+	for _, po := range peerOffers {
+		var latency time.Duration
+		if selector.needsLatency() {
+			start := time.Now()
+			if _, err = c.Query(po.PeerID); err != nil {
+				fmt.Printf("Skipping peer %s, which failed to respond: %s\n", po.PeerID, err)
+				continue
+			}
+			latency = time.Since(start)
+		}
+
+		for _, o := range po.Offers {
+			if o.EthAsset != ethAsset || !filter.matches(o) {
+				continue
+			}
+
+			xmrAmount, err := o.ExchangeRate.ToXMR(providesAmount) //nolint:govet
+			if err != nil {
+				return err
+			}
+			if xmrAmount.Cmp(o.MinAmount) < 0 || xmrAmount.Cmp(o.MaxAmount) > 0 {
+				continue
+			}
+
+			candidates = append(candidates, &offerCandidate{
+				peerID:      po.PeerID,
+				offer:       o,
+				successRate: successRates[po.PeerID],
+				latency:     latency,
+			})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("no offers found that accept %s %s", providesAmount.Text('f'), ethAsset)
+	}
+
+	best, reason := selector.selectOffer(candidates)
+	fmt.Printf("Selected offer %s from peer %s\n", best.offer.ID, best.peerID)
+	fmt.Printf("\tReason: %s\n", reason)
+
+	return takeOffer(ctx, &rpctypes.TakeOfferRequest{
+		PeerID:          best.peerID,
+		OfferID:         best.offer.ID,
+		ProvidesAmount:  providesAmount,
+		MaxExchangeRate: filter.maxExchangeRate,
+	})
+}
+
+func runWatch(ctx *cli.Context) error {
+	var offerID *types.Hash
+
+	if ctx.IsSet(flagOfferID) {
+		hash, err := types.HexToHash(ctx.String(flagOfferID))
+		if err != nil {
+			return errInvalidFlagValue(flagOfferID, err)
+		}
+		offerID = &hash
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx.Context = sigCtx
+
+	for connected := false; ; {
+		watchedSwapDone, err := watchSwaps(ctx, offerID, &connected)
+		if watchedSwapDone || sigCtx.Err() != nil {
+			return nil
+		}
+
+		// fail fast if we never got a connection, as it's likely a misconfiguration
+		if !connected {
+			return err
+		}
+
+		reason := "connection closed"
+		if err != nil {
+			reason = err.Error()
+		}
+		fmt.Printf("%s > Lost connection to swapd (%s), reconnecting in %s\n",
+			time.Now().Format(common.TimeFmtSecs), reason, watchReconnectDelay)
+
+		select {
+		case <-time.After(watchReconnectDelay):
+		case <-sigCtx.Done():
+			return nil
+		}
+	}
+}
+
+// watchSwaps prints status updates until the websocket connection is lost, the context
+// is cancelled, or the swap with the passed offer ID completes. It returns true in the
+// latter case. The connected flag is set once a subscription has been established.
+func watchSwaps(ctx *cli.Context, offerID *types.Hash, connected *bool) (bool, error) {
+	wsc, err := newWSClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	// closing the client unblocks the subscription's reader when interrupted
+	defer wsc.Close()
+
+	printStatus := func(id types.Hash, status types.Status) {
+		fmt.Printf("%s > %s: %s\n", time.Now().Format(common.TimeFmtSecs), id, status)
+	}
+
+	if offerID != nil {
+		statusCh, err := wsc.SubscribeSwapStatus(*offerID)
+		if err != nil {
+			return false, err
+		}
+		*connected = true
+
+		for {
+			select {
+			case status, ok := <-statusCh:
+				if !ok {
+					return false, nil
+				}
+				printStatus(*offerID, status)
+				if !status.IsOngoing() {
+					return true, nil
+				}
+			case <-ctx.Context.Done():
+				return false, nil
+			}
+		}
+	}
+
+	eventCh, err := wsc.SubscribeAllSwapStatus()
+	if err != nil {
+		return false, err
+	}
+	*connected = true
+
+	for {
+		select {
+		case event, ok := <-eventCh:
+			if !ok {
+				return false, nil
+			}
+			printStatus(event.OfferID, event.Status)
+		case <-ctx.Context.Done():
+			return false, nil
+		}
+	}
+}
+
+func runGetPastSwap(ctx *cli.Context) error {
+	req := new(rpc.GetPastRequest)
+
+	if ctx.IsSet(flagOfferID) {
+		hash, err := types.HexToHash(ctx.String(flagOfferID))
+		if err != nil {
+			return errInvalidFlagValue(flagOfferID, err)
+		}
+		req.OfferID = &hash
+	}
+
+	if ctx.IsSet(flagStatus) {
+		status := new(types.Status)
+		if err := status.UnmarshalText([]byte(ctx.String(flagStatus))); err != nil {
+			return errInvalidFlagValue(flagStatus, err)
+		}
+		req.Status = status
+	}
+
+	if ctx.IsSet(flagSince) {
+		since, err := parseTimeFlag(ctx.String(flagSince), time.Now())
+		if err != nil {
+			return errInvalidFlagValue(flagSince, err)
+		}
+		req.Since = &since
+	}
+
+	if ctx.IsSet(flagUntil) {
+		until, err := parseTimeFlag(ctx.String(flagUntil), time.Now())
+		if err != nil {
+			return errInvalidFlagValue(flagUntil, err)
+		}
+		req.Until = &until
+	}
+
+	req.Limit = ctx.Uint(flagLimit)
+	req.Offset = ctx.Uint(flagOffset)
+
+	if req.OfferID != nil && (req.Status != nil || req.Since != nil || req.Until != nil ||
+		req.Limit != 0 || req.Offset != 0) {
+		return fmt.Errorf("--%s cannot be combined with --%s, --%s, --%s, --%s or --%s",
+			flagOfferID, flagStatus, flagSince, flagUntil, flagLimit, flagOffset)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetPastSwaps(req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Past swaps:")
+	if len(resp.Swaps) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	for i, info := range resp.Swaps {
+		if i > 0 {
+			fmt.Printf("---\n")
+		}
+
+		providedCoin, receivedCoin, err := providedAndReceivedSymbols(c, info.Provided, info.EthAsset)
+		if err != nil {
+			return err
+		}
+
+		endTime := "-"
+		if info.EndTime != nil {
+			endTime = info.EndTime.Format(common.TimeFmtSecs)
 		}
 
 		fmt.Printf("ID: %s\n", info.ID)
 		fmt.Printf("Start time: %s\n", info.StartTime.Format(common.TimeFmtSecs))
+		fmt.Printf("End time: %s\n", endTime)
 		fmt.Printf("Provided: %s %s\n", info.ProvidedAmount.Text('f'), providedCoin)
-		fmt.Printf("Receiving: %s %s\n", info.ExpectedAmount.Text('f'), receivedCoin)
+		fmt.Printf("Received: %s %s\n", info.ExpectedAmount.Text('f'), receivedCoin)
 		fmt.Printf("Exchange Rate: %s ETH/XMR\n", info.ExchangeRate)
+		if info.MarketExchangeRate != nil && info.ProfitPercent != nil {
+			profitPct, _ := info.ProfitPercent.Float64()
+			fmt.Printf("Market Exchange Rate at start: %s ETH/XMR\n", info.MarketExchangeRate)
+			fmt.Printf("Profit/loss vs market: %+.2f%%\n", profitPct)
+		}
 		fmt.Printf("Status: %s\n", info.Status)
-		fmt.Printf("Time status was last updated: %s\n", info.LastStatusUpdateTime.Format(common.TimeFmtSecs))
-		if info.Timeout0 != nil && info.Timeout1 != nil {
-			fmt.Printf("First timeout: %s\n", info.Timeout0.Format(common.TimeFmtSecs))
-			fmt.Printf("Second timeout: %s\n", info.Timeout1.Format(common.TimeFmtSecs))
+		printTxHash("New swap tx", info.NewSwapTxHash)
+		printTxHash("Set ready tx", info.SetReadyTxHash)
+		printTxHash("Claim tx", info.ClaimTxHash)
+		printTxHash("Refund tx", info.RefundTxHash)
+		if info.RelayerFee != nil {
+			fmt.Printf("Relayer fee: %s ETH\n", info.RelayerFee.Text('f'))
 		}
-		fmt.Printf("Estimated time to completion: %s\n", info.EstimatedTimeToCompletion)
 	}
 
 	return nil
 }
 
-func runGetPastSwap(ctx *cli.Context) error {
-	var offerID *types.Hash
+func runExport(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
 
-	if ctx.IsSet(flagOfferID) {
-		hash, err := types.HexToHash(ctx.String(flagOfferID))
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Export(offerID)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := vjson.MarshalIndentStruct(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+func runCancel(ctx *cli.Context) error {
+	if ctx.Bool(flagAll) {
+		if ctx.IsSet(flagOfferID) {
+			return fmt.Errorf("flags %q and %q can't be used together", flagAll, flagOfferID)
+		}
+		return runCancelAll(ctx)
+	}
+
+	if !ctx.IsSet(flagOfferID) {
+		return fmt.Errorf("one of the flags %q or %q is required", flagOfferID, flagAll)
+	}
+
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Attempting to exit swap with id %s\n", offerID)
+	resp, err := c.Cancel(offerID)
+	if err != nil {
+		return err
+	}
+
+	switch resp.Reason {
+	case rpc.CancelReasonNoSuchSwap, rpc.CancelReasonPastPointOfNoReturn:
+		return fmt.Errorf("failed to cancel (%s): %s", resp.Reason, resp.Message)
+	case rpc.CancelReasonCompleted:
+		fmt.Printf("Not cancelled, %s\n", resp.Message)
+	default:
+		fmt.Printf("Cancelled successfully (%s), exit status: %s\n", resp.Reason, resp.Status)
+	}
+
+	return nil
+}
+
+func runCancelAll(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Attempting to exit all ongoing swaps\n")
+	resp, err := c.CancelAll()
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Results) == 0 {
+		fmt.Printf("No ongoing swaps\n")
+		return nil
+	}
+
+	offerIDs := make([]types.Hash, 0, len(resp.Results))
+	for offerID := range resp.Results {
+		offerIDs = append(offerIDs, offerID)
+	}
+	sort.Slice(offerIDs, func(i, j int) bool {
+		return offerIDs[i].String() < offerIDs[j].String()
+	})
+
+	numFailed := 0
+	for _, offerID := range offerIDs {
+		r := resp.Results[offerID]
+		switch r.Reason {
+		case rpc.CancelReasonPastPointOfNoReturn, rpc.CancelReasonFailed:
+			numFailed++
+			fmt.Printf("%s: failed to cancel (%s): %s\n", offerID, r.Reason, r.Message)
+		case rpc.CancelReasonCompleted:
+			fmt.Printf("%s: not cancelled, %s\n", offerID, r.Message)
+		default:
+			fmt.Printf("%s: cancelled (%s), exit status: %s\n", offerID, r.Reason, r.Status)
+		}
+	}
+
+	if numFailed > 0 {
+		return fmt.Errorf("failed to cancel %d of %d ongoing swaps", numFailed, len(offerIDs))
+	}
+
+	return nil
+}
+
+func runCancelByStatus(ctx *cli.Context) error {
+	var status types.Status
+	if err := status.UnmarshalText([]byte(ctx.String(flagStatus))); err != nil {
+		return errInvalidFlagValue(flagStatus, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.CancelByStatus(status, ctx.Duration(flagOlderThan))
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Results) == 0 {
+		fmt.Printf("No ongoing swaps matched status %s\n", status)
+		return nil
+	}
+
+	for _, r := range resp.Results {
+		switch {
+		case r.Skipped:
+			fmt.Printf("%s: skipped, swap cannot be safely cancelled at status %s\n", r.OfferID, r.Status)
+		case r.Error != "":
+			fmt.Printf("%s: failed to cancel: %s\n", r.OfferID, r.Error)
+		default:
+			fmt.Printf("%s: cancelled, exit status: %s\n", r.OfferID, r.Status)
+		}
+	}
+
+	return nil
+}
+
+func runClearOffers(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := ctx.String(flagOfferIDs)
+	if ids == "" {
+		err := c.ClearOffers(nil)
 		if err != nil {
-			return errInvalidFlagValue(flagOfferID, err)
+			return err
 		}
-		offerID = &hash
+
+		fmt.Printf("Cleared all offers successfully.\n")
+		return nil
+	}
+
+	var offerIDs []types.Hash
+	for _, offerIDStr := range strings.Split(ids, ",") {
+		id, err := types.HexToHash(strings.TrimSpace(offerIDStr))
+		if err != nil {
+			return errInvalidFlagValue(flagOfferIDs, err)
+		}
+		offerIDs = append(offerIDs, id)
+	}
+	err = c.ClearOffers(offerIDs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Cleared offers successfully: %s\n", ids)
+	return nil
+}
+
+func runGetOffers(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetOffers()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Peer ID (self):", resp.PeerID)
+	fmt.Println("Offers:")
+	for i, offer := range resp.Offers {
+		err = printOffer(c, offer, i, "  ")
+		if err != nil {
+			return err
+		}
+		if expiresAt, ok := resp.Expiries[offer.ID]; ok {
+			fmt.Printf("  Expires: %s\n", expiresAt.Local().Format(common.TimeFmtSecs))
+		}
+	}
+	if len(resp.Offers) == 0 {
+		fmt.Println("[no offers]")
+	}
+
+	return nil
+}
+
+func runReadvertiseOffers(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Readvertise()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Re-advertising %d offers\n", resp.NumOffers)
+	return nil
+}
+
+func runGetRejectedTakes(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetRejectedTakes()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Rejected takes:")
+	if len(resp.RejectedTakes) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	for i, rt := range resp.RejectedTakes {
+		if i > 0 {
+			fmt.Printf("---\n")
+		}
+		fmt.Printf("Time: %s\n", rt.Time.Format(common.TimeFmtSecs))
+		fmt.Printf("Peer ID: %s\n", rt.PeerID)
+		fmt.Printf("Offer ID: %s\n", rt.OfferID)
+		fmt.Printf("Reason: %s\n", rt.Reason)
+	}
+
+	return nil
+}
+
+func runGetStatus(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
-	resp, err := c.GetPastSwap(offerID)
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Past swaps:")
-	if len(resp.Swaps) == 0 {
-		fmt.Println("[none]")
-		return nil
+	resp, err := c.GetStatus(offerID)
+	if err != nil {
+		return err
 	}
 
-	for i, info := range resp.Swaps {
-		if i > 0 {
-			fmt.Printf("---\n")
-		}
+	fmt.Printf("Start time: %s\n", resp.StartTime.Format(common.TimeFmtSecs))
+	fmt.Printf("Status=%s: %s\n", resp.Status, resp.Description)
+	if resp.Timeout0 != nil && resp.Timeout1 != nil {
+		fmt.Printf("First timeout: %s\n", resp.Timeout0.Format(common.TimeFmtSecs))
+		fmt.Printf("Second timeout: %s\n", resp.Timeout1.Format(common.TimeFmtSecs))
+	}
+	if resp.EstimatedTimeToCompletion > 0 {
+		fmt.Printf("Estimated time to completion: %s\n", resp.EstimatedTimeToCompletion)
+	}
+	if resp.ClaimMethod != "" {
+		fmt.Printf("Claim method: %s\n", claimMethodDescription(resp.ClaimMethod))
+	}
+	if resp.RelayerFee != nil {
+		fmt.Printf("Relayer fee: %s ETH\n", resp.RelayerFee.Text('f'))
+	}
+	return nil
+}
 
-		providedCoin, receivedCoin, err := providedAndReceivedSymbols(c, info.Provided, info.EthAsset)
-		if err != nil {
-			return err
-		}
+func runForceComplete(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
 
-		endTime := "-"
-		if info.EndTime != nil {
-			endTime = info.EndTime.Format(common.TimeFmtSecs)
-		}
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("ID: %s\n", info.ID)
-		fmt.Printf("Start time: %s\n", info.StartTime.Format(common.TimeFmtSecs))
-		fmt.Printf("End time: %s\n", endTime)
-		fmt.Printf("Provided: %s %s\n", info.ProvidedAmount.Text('f'), providedCoin)
-		fmt.Printf("Received: %s %s\n", info.ExpectedAmount.Text('f'), receivedCoin)
-		fmt.Printf("Exchange Rate: %s ETH/XMR\n", info.ExchangeRate)
-		fmt.Printf("Status: %s\n", info.Status)
+	if err = c.ForceComplete(offerID); err != nil {
+		return err
 	}
 
+	fmt.Printf("Completed swap %s\n", offerID)
 	return nil
 }
 
-func runCancel(ctx *cli.Context) error {
+func runRetrySweep(ctx *cli.Context) error {
 	offerID, err := types.HexToHash(ctx.String(flagOfferID))
 	if err != nil {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
-	fmt.Printf("Attempting to exit swap with id %s\n", offerID)
-	resp, err := c.Cancel(offerID)
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Cancelled successfully, exit status: %s\n", resp)
+	if err = c.RetrySweep(offerID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Swept XMR and completed swap %s\n", offerID)
 	return nil
 }
 
-func runClearOffers(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-
-	ids := ctx.String(flagOfferIDs)
-	if ids == "" {
-		err := c.ClearOffers(nil)
-		if err != nil {
-			return err
-		}
-
-		fmt.Printf("Cleared all offers successfully.\n")
-		return nil
+func runSweepSharedWallet(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	var offerIDs []types.Hash
-	for _, offerIDStr := range strings.Split(ids, ",") {
-		id, err := types.HexToHash(strings.TrimSpace(offerIDStr))
-		if err != nil {
-			return errInvalidFlagValue(flagOfferIDs, err)
-		}
-		offerIDs = append(offerIDs, id)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
 	}
-	err := c.ClearOffers(offerIDs)
+
+	resp, err := c.SweepSharedWallet(offerID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Cleared offers successfully: %s\n", ids)
+	fmt.Printf("Swept %s XMR (%s XMR fees) from the shared wallet of swap %s to %s\n",
+		resp.Amount.Text('f'), resp.Fee.Text('f'), offerID, resp.Address)
+	for _, txID := range resp.TxIDs {
+		fmt.Printf("Transaction ID: %s\n", txID)
+	}
 	return nil
 }
 
-func runGetOffers(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	resp, err := c.GetOffers()
+func runVerify(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Peer ID (self):", resp.PeerID)
-	fmt.Println("Offers:")
-	for i, offer := range resp.Offers {
-		err = printOffer(c, offer, i, "  ")
-		if err != nil {
-			return err
-		}
+	resp, err := c.Verify(offerID)
+	if err != nil {
+		return err
 	}
-	if len(resp.Offers) == 0 {
-		fmt.Println("[no offers]")
+
+	fmt.Printf("Local status: %s\n", resp.Status)
+	fmt.Printf("Contract stage: %s\n", resp.ContractStage)
+	if len(resp.Mismatches) == 0 {
+		fmt.Println("Swap state is consistent")
+		return nil
 	}
 
+	fmt.Printf("Found %d mismatches:\n", len(resp.Mismatches))
+	for _, mismatch := range resp.Mismatches {
+		fmt.Printf("  - %s\n", mismatch)
+	}
 	return nil
 }
 
-func runGetStatus(ctx *cli.Context) error {
+func runSetGasPriceOverride(ctx *cli.Context) error {
 	offerID, err := types.HexToHash(ctx.String(flagOfferID))
 	if err != nil {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
-	resp, err := c.GetStatus(offerID)
+	gasPrice := ctx.Uint64(flagGasPrice)
+	c, err := newRRPClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Start time: %s\n", resp.StartTime.Format(common.TimeFmtSecs))
-	fmt.Printf("Status=%s: %s\n", resp.Status, resp.Description)
+	if err = c.SetGasPriceOverride(offerID, gasPrice); err != nil {
+		return err
+	}
+
+	fmt.Printf("The next transaction of swap %s will use a gas price of %d gwei\n", offerID, gasPrice)
 	return nil
 }
 
@@ -952,7 +3291,11 @@ func runClaim(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.Claim(offerID)
 	if err != nil {
 		return err
@@ -968,7 +3311,11 @@ func runRefund(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.Refund(offerID)
 	if err != nil {
 		return err
@@ -979,35 +3326,63 @@ func runRefund(ctx *cli.Context) error {
 }
 
 func runSetSwapTimeout(ctx *cli.Context) error {
-	duration := ctx.Uint("duration")
-	if duration == 0 {
+	// a duration that is not given is left unchanged by swapd
+	t0Duration := ctx.Uint(flagDuration)
+	t1Duration := t0Duration
+	if ctx.IsSet(flagT0Duration) {
+		t0Duration = ctx.Uint(flagT0Duration)
+	}
+	if ctx.IsSet(flagT1Duration) {
+		t1Duration = ctx.Uint(flagT1Duration)
+	}
+
+	if t0Duration == 0 && t1Duration == 0 {
 		return errNoDuration
 	}
 
-	c := newRRPClient(ctx)
-	err := c.SetSwapTimeout(uint64(duration))
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.SetSwapTimeout(uint64(t0Duration), uint64(t1Duration))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.GetSwapTimeout()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Set timeout duration to %d seconds\n", duration)
+	fmt.Printf("Set timeout durations to %d seconds until t0 and %d seconds from t0 to t1\n",
+		resp.T0Duration, resp.T1Duration)
 	return nil
 }
 
 func runGetSwapTimeout(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.GetSwapTimeout()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Swap timeout duration: %d seconds\n", resp.Timeout)
+	fmt.Printf("Duration until t0: %d seconds\n", resp.T0Duration)
+	fmt.Printf("Duration from t0 to t1: %d seconds\n", resp.T1Duration)
 	return nil
 }
 
 func runSuggestedExchangeRate(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	resp, err := c.SuggestedExchangeRate()
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.SuggestedExchangeRate(ctx.Bool(flagForceRefresh))
 	if err != nil {
 		return err
 	}
@@ -1015,6 +3390,99 @@ func runSuggestedExchangeRate(ctx *cli.Context) error {
 	fmt.Printf("Exchange rate: %s\n", resp.ExchangeRate)
 	fmt.Printf("XMR/USD Price: %-13s (%s)\n", resp.XMRPrice, resp.XMRUpdatedAt)
 	fmt.Printf("ETH/USD Price: %-13s (%s)\n", resp.ETHPrice, resp.ETHUpdatedAt)
+	if resp.Provider != "" {
+		fmt.Printf("Provider: %s\n", resp.Provider)
+	}
+
+	return nil
+}
+
+func runRateHistory(ctx *cli.Context) error {
+	req := new(rpc.RateHistoryRequest)
+
+	if ctx.IsSet(flagSince) {
+		since, err := parseTimeFlag(ctx.String(flagSince), time.Now())
+		if err != nil {
+			return errInvalidFlagValue(flagSince, err)
+		}
+		req.Since = &since
+	}
+
+	if ctx.IsSet(flagUntil) {
+		until, err := parseTimeFlag(ctx.String(flagUntil), time.Now())
+		if err != nil {
+			return errInvalidFlagValue(flagUntil, err)
+		}
+		req.Until = &until
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.RateHistory(req)
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Samples) == 0 {
+		fmt.Println("[none]")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tEXCHANGE RATE\tXMR/USD\tETH/USD")
+	for _, sample := range resp.Samples {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			sample.Time.Format(common.TimeFmtSecs),
+			sample.ExchangeRate,
+			sample.XMRPrice.Text('f'),
+			sample.ETHPrice.Text('f'),
+		)
+	}
+
+	return w.Flush()
+}
+
+func runOrderbook(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Orderbook(ctx.Uint64(flagSearchTime))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Peers: %d discovered, %d failed to respond\n", resp.NumPeers, resp.NumPeersFailed)
+	if len(resp.Offers) == 0 {
+		fmt.Println("[no offers]")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ASSET\tEXCHANGE RATE\tMIN XMR\tMAX XMR\tOFFER ID\tPEER ID")
+	for _, e := range resp.Offers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Offer.EthAsset,
+			e.Offer.ExchangeRate,
+			e.Offer.MinAmount.Text('f'),
+			e.Offer.MaxAmount.Text('f'),
+			e.Offer.ID,
+			e.PeerID,
+		)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for _, s := range resp.Summaries {
+		fmt.Printf("%s: %d offers, best ask %s (peer %s), worst ask %s\n",
+			s.EthAsset, s.NumOffers, s.BestAsk.Offer.ExchangeRate, s.BestAsk.PeerID, s.WorstAsk.Offer.ExchangeRate)
+	}
 
 	return nil
 }
@@ -1022,7 +3490,11 @@ func runSuggestedExchangeRate(ctx *cli.Context) error {
 func runGetVersions(ctx *cli.Context) error {
 	fmt.Printf("swapcli: %s\n", cliutil.GetVersion())
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.Version()
 	if err != nil {
 		return err
@@ -1032,16 +3504,105 @@ func runGetVersions(ctx *cli.Context) error {
 	fmt.Printf("p2p version: %s\n", resp.P2PVersion)
 	fmt.Printf("env: %s\n", resp.Env)
 	fmt.Printf("swap creator address: %s\n", resp.SwapCreatorAddr)
+	fmt.Printf("forwarder address: %s\n", resp.Forwarder.ForwarderAddr)
+	if resp.Forwarder.IsValid {
+		fmt.Printf("forwarder verified: true\n")
+	} else {
+		fmt.Printf("forwarder verified: false (%s)\n", resp.Forwarder.InvalidReason)
+	}
+
+	return nil
+}
+
+func runHealth(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Health()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Monero wallet reachable: %t\n", resp.MoneroWalletReachable)
+	fmt.Printf("Ethereum synced: %t\n", resp.EthereumSynced)
+	fmt.Printf("Connected peers: %d\n", resp.NumPeers)
+	fmt.Printf("Ongoing swaps: %d\n", resp.NumOngoingSwaps)
+	if resp.GasBalance != nil {
+		fmt.Printf("ETH balance low: %t\n", resp.GasBalance.IsLow)
+	}
+	if resp.WsConnections != nil {
+		fmt.Printf("Websocket connections: %d (max %d)\n", resp.WsConnections.Count, resp.WsConnections.Max)
+	}
+
+	if !resp.IsHealthy {
+		return fmt.Errorf("swapd is unhealthy: %s", strings.Join(resp.Errors, "; "))
+	}
+
+	return nil
+}
+
+func runRelayerStats(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.RelayerStats()
+	if err != nil {
+		return err
+	}
+
+	if resp.IsRelayer {
+		fmt.Printf("Relaying claims for everyone for a fee of %s ETH\n", resp.Fee.Text('f'))
+	} else {
+		fmt.Printf("Only relaying claims for swap counterparties\n")
+	}
+	fmt.Printf("Claims relayed: %d\n", resp.NumRelayed)
+	fmt.Printf("Claims that failed to relay: %d\n", resp.NumFailed)
+	fmt.Printf("Fees earned: %s ETH\n", resp.TotalFees.Text('f'))
+	fmt.Printf("Gas cost: %s ETH\n", resp.TotalGasCost.Text('f'))
+	fmt.Printf("Earnings: %s ETH\n", resp.Earnings.Text('f'))
 
 	return nil
 }
 
 func runShutdown(ctx *cli.Context) error {
-	c := newRRPClient(ctx)
-	err := c.Shutdown()
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !ctx.Bool(flagDrain) {
+		if ctx.IsSet(flagTimeout) {
+			return fmt.Errorf("flag %q can only be used with %q", flagTimeout, flagDrain)
+		}
+		return c.Shutdown()
+	}
+
+	timeout := ctx.Duration(flagTimeout)
+	if timeout < 0 {
+		return errInvalidFlagValue(flagTimeout, errors.New("must not be negative"))
+	}
+	if timeout > 0 && timeout < time.Second {
+		return errInvalidFlagValue(flagTimeout, errors.New("must be at least 1s"))
+	}
+	if timeout > rpc.MaxDrainTimeout {
+		return errInvalidFlagValue(flagTimeout, fmt.Errorf("must not be more than %s", rpc.MaxDrainTimeout))
+	}
+
+	fmt.Printf("Waiting for ongoing swaps to complete before shutting down\n")
+	resp, err := c.DrainAndShutdown(timeout)
 	if err != nil {
 		return err
 	}
+
+	if resp.NumOngoingSwaps > 0 {
+		fmt.Printf("Timed out with %d ongoing swap(s), they will resume when swapd is restarted\n",
+			resp.NumOngoingSwaps)
+	}
+	fmt.Printf("Shut down swapd\n")
 	return nil
 }
 
@@ -1051,7 +3612,11 @@ func runGetContractSwapInfo(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.GetContractSwapInfo(offerID)
 	if err != nil {
 		return err
@@ -1079,7 +3644,11 @@ func runGetSwapSecret(ctx *cli.Context) error {
 		return errInvalidFlagValue(flagOfferID, err)
 	}
 
-	c := newRRPClient(ctx)
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := c.GetSwapSecret(offerID)
 	if err != nil {
 		return err
@@ -1089,6 +3658,32 @@ func runGetSwapSecret(ctx *cli.Context) error {
 	return nil
 }
 
+func runVerifyDLEqProof(ctx *cli.Context) error {
+	offerID, err := types.HexToHash(ctx.String(flagOfferID))
+	if err != nil {
+		return errInvalidFlagValue(flagOfferID, err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.VerifyDLEqProof(offerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Counterparty secp256k1 public key: %s\n", resp.Secp256k1PublicKey)
+	fmt.Printf("Counterparty public spend key: %s\n", resp.PublicSpendKey)
+	if !resp.Verified {
+		return fmt.Errorf("DLEq proof verification failed: %s", resp.Error)
+	}
+
+	fmt.Println("DLEq proof verified")
+	return nil
+}
+
 func providesStrToVal(providesStr string) (coins.ProvidesCoin, error) {
 	var provides coins.ProvidesCoin
 
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/rpcclient"
 )
 
 func (s *swapCLITestSuite) Test_runGetVersions() {
@@ -28,3 +30,14 @@ func (s *swapCLITestSuite) Test_runBalances() {
 	err := cliApp().RunContext(context.Background(), args)
 	require.NoError(s.T(), err)
 }
+
+func (s *swapCLITestSuite) Test_checkTestSwapEnv_sameDaemon() {
+	// different host names that reach the same daemon
+	maker, err := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://localhost:%d", s.conf.RPCPort), "")
+	require.NoError(s.T(), err)
+	taker, err := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", s.conf.RPCPort), "")
+	require.NoError(s.T(), err)
+
+	err = checkTestSwapEnv(maker, taker)
+	require.ErrorIs(s.T(), err, errTestSwapSameDaemon)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// exportedOfferToRequest returns the request that re-makes an exported offer
+// with the same settings, or nil if the offer has expired.
+func exportedOfferToRequest(exported *rpc.ExportedOffer, now time.Time) *rpctypes.MakeOfferRequest {
+	o, extra := exported.Offer, exported.Extra
+	if extra.IsExpired(now) {
+		return nil
+	}
+
+	req := &rpctypes.MakeOfferRequest{
+		MinAmount:          o.MinAmount,
+		MaxAmount:          o.MaxAmount,
+		ExchangeRate:       o.ExchangeRate,
+		EthAsset:           o.EthAsset,
+		UseRelayer:         extra.UseRelayer,
+		MinTakerReputation: extra.MinTakerReputation,
+		Persistent:         extra.Persistent,
+		Repricing:          extra.Repricing,
+	}
+
+	if extra.ExpiresAt != nil {
+		// round up, so the new offer does not expire before the original
+		req.ExpiresIn = uint64(math.Ceil(extra.ExpiresAt.Sub(now).Seconds()))
+	}
+
+	return req
+}
+
+func runExportOffers(ctx *cli.Context) error {
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.ExportOffers()
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := vjson.MarshalIndentStruct(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Clean(ctx.String(flagFile)), jsonData, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d offers to %s\n", len(resp.Offers), ctx.String(flagFile))
+	return nil
+}
+
+func runImportOffers(ctx *cli.Context) error {
+	jsonData, err := os.ReadFile(filepath.Clean(ctx.String(flagFile)))
+	if err != nil {
+		return err
+	}
+
+	exported := new(rpc.ExportOffersResponse)
+	if err = vjson.UnmarshalStruct(jsonData, exported); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", ctx.String(flagFile), err)
+	}
+
+	c, err := newRRPClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, e := range exported.Offers {
+		req := exportedOfferToRequest(e, now)
+		if req == nil {
+			fmt.Printf("Skipped offer %s, which has expired\n", e.Offer.ID)
+			continue
+		}
+
+		resp, err := c.MakeOfferRequest(req) //nolint:govet
+		if err != nil {
+			return fmt.Errorf("failed to re-make offer %s: %w", e.Offer.ID, err)
+		}
+
+		fmt.Printf("Re-made offer %s as %s\n", e.Offer.ID, resp.OfferID)
+	}
+
+	return nil
+}
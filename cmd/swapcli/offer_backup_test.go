@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+func TestExportedOfferToRequest(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(90*time.Second + 500*time.Millisecond)
+	minTakerRep := &types.MinTakerReputation{SuccessRate: apd.New(9, -1)}
+
+	exported := &rpc.ExportedOffer{
+		Offer: types.NewOffer(
+			coins.ProvidesXMR,
+			apd.New(1, 0),
+			apd.New(2, 0),
+			coins.ToExchangeRate(apd.New(5, -2)),
+			types.EthAssetETH,
+		),
+		Extra: &types.OfferExtra{
+			UseRelayer:         true,
+			ExpiresAt:          &expiresAt,
+			MinTakerReputation: minTakerRep,
+			Persistent:         true,
+		},
+	}
+
+	req := exportedOfferToRequest(exported, now)
+	require.NotNil(t, req)
+	require.Equal(t, exported.Offer.MinAmount, req.MinAmount)
+	require.Equal(t, exported.Offer.MaxAmount, req.MaxAmount)
+	require.Equal(t, exported.Offer.ExchangeRate, req.ExchangeRate)
+	require.Equal(t, types.EthAssetETH, req.EthAsset)
+	require.True(t, req.UseRelayer)
+	require.Equal(t, uint64(91), req.ExpiresIn)
+	require.Equal(t, minTakerRep, req.MinTakerReputation)
+	require.True(t, req.Persistent)
+	require.Nil(t, req.Repricing)
+
+	// expired offers are not re-made
+	require.Nil(t, exportedOfferToRequest(exported, expiresAt))
+}
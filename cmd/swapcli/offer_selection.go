@@ -0,0 +1,350 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// Strategies for selecting the offer that take-best takes
+const (
+	selectBestRate   = "best-rate"
+	selectLiquidity  = "liquidity"
+	selectReputation = "reputation"
+	selectLatency    = "latency"
+	selectWeighted   = "weighted"
+)
+
+// defaultSelectionWeights are the weights of the weighted strategy when
+// --weights is not set.
+const defaultSelectionWeights = "rate=4,liquidity=2,reputation=3,latency=1"
+
+// offerCandidate is an offer that take-best can take, along with the
+// information about its maker that the selection strategies use.
+type offerCandidate struct {
+	peerID peer.ID
+	offer  *types.Offer
+	// successRate is the fraction of our completed swaps with the maker that
+	// succeeded, or nil if we have not completed any swaps with the maker.
+	successRate *apd.Decimal
+	// latency is the time it took to query the maker's offers, or zero if it
+	// was not measured.
+	latency time.Duration
+}
+
+// offerSelector chooses the offer to take among several candidates.
+type offerSelector interface {
+	// selectOffer returns the best of the candidates, which must not be empty,
+	// and the reason it was selected.
+	selectOffer(candidates []*offerCandidate) (*offerCandidate, string)
+	// needsLatency returns true if the selector uses the candidates' latency,
+	// which has to be measured by querying each maker.
+	needsLatency() bool
+}
+
+// newOfferSelector returns the selector of the named strategy. The weights are
+// only used by the weighted strategy.
+func newOfferSelector(strategy string, weights string) (offerSelector, error) {
+	switch strategy {
+	case selectBestRate:
+		return &compareSelector{better: betterRate, reason: rateReason}, nil
+	case selectLiquidity:
+		return &compareSelector{better: betterLiquidity, reason: liquidityReason}, nil
+	case selectReputation:
+		return &compareSelector{better: betterReputation, reason: reputationReason}, nil
+	case selectLatency:
+		return &compareSelector{better: betterLatency, reason: latencyReason, usesLatency: true}, nil
+	case selectWeighted:
+		w, err := parseSelectionWeights(weights)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	default:
+		return nil, fmt.Errorf("unknown offer selection strategy %q", strategy)
+	}
+}
+
+// compareSelector selects the candidate that is better than all others by a
+// single criterion. Ties are broken by the exchange rate, then by offer ID, so
+// the selection does not depend on the order of the candidates.
+type compareSelector struct {
+	// better returns -1 if a is better than b, 1 if b is better, and 0 if
+	// they are equal by the selector's criterion.
+	better      func(a, b *offerCandidate) int
+	reason      func(best *offerCandidate, numCandidates int) string
+	usesLatency bool
+}
+
+func (s *compareSelector) selectOffer(candidates []*offerCandidate) (*offerCandidate, string) {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if s.less(c, best) {
+			best = c
+		}
+	}
+	return best, s.reason(best, len(candidates))
+}
+
+func (s *compareSelector) less(a, b *offerCandidate) bool {
+	if cmp := s.better(a, b); cmp != 0 {
+		return cmp < 0
+	}
+	return breakTie(a, b)
+}
+
+// breakTie returns true if a is preferred over b when they are equally good by
+// a strategy's criteria, preferring the lower exchange rate, then the lower
+// offer ID.
+func breakTie(a, b *offerCandidate) bool {
+	if cmp := betterRate(a, b); cmp != 0 {
+		return cmp < 0
+	}
+	return a.offer.ID.String() < b.offer.ID.String()
+}
+
+func (s *compareSelector) needsLatency() bool {
+	return s.usesLatency
+}
+
+// betterRate prefers the lower exchange rate, as a lower rate means fewer ETH
+// for each XMR.
+func betterRate(a, b *offerCandidate) int {
+	return a.offer.ExchangeRate.Cmp(b.offer.ExchangeRate)
+}
+
+func rateReason(best *offerCandidate, numCandidates int) string {
+	return fmt.Sprintf("lowest exchange rate (%s) of %d matching offers", best.offer.ExchangeRate, numCandidates)
+}
+
+// betterLiquidity prefers the higher maximum amount.
+func betterLiquidity(a, b *offerCandidate) int {
+	return -a.offer.MaxAmount.Cmp(b.offer.MaxAmount)
+}
+
+func liquidityReason(best *offerCandidate, numCandidates int) string {
+	return fmt.Sprintf("highest maximum amount (%s XMR) of %d matching offers",
+		best.offer.MaxAmount.Text('f'), numCandidates)
+}
+
+// betterReputation prefers the higher success rate of our swaps with the
+// maker. Makers that we have not swapped with rank below all others.
+func betterReputation(a, b *offerCandidate) int {
+	switch {
+	case a.successRate == nil && b.successRate == nil:
+		return 0
+	case a.successRate == nil:
+		return 1
+	case b.successRate == nil:
+		return -1
+	default:
+		return -a.successRate.Cmp(b.successRate)
+	}
+}
+
+func reputationReason(best *offerCandidate, numCandidates int) string {
+	if best.successRate == nil {
+		return fmt.Sprintf("none of the makers of the %d matching offers have completed swaps with us, "+
+			"fell back to the lowest exchange rate (%s)", numCandidates, best.offer.ExchangeRate)
+	}
+	rate, _ := best.successRate.Float64()
+	return fmt.Sprintf("highest success rate of swaps with us (%.0f%%) of the makers of %d matching offers",
+		rate*100, numCandidates)
+}
+
+// betterLatency prefers the lower latency. Unmeasured latencies rank below
+// all others.
+func betterLatency(a, b *offerCandidate) int {
+	switch {
+	case a.latency == b.latency:
+		return 0
+	case a.latency == 0:
+		return 1
+	case b.latency == 0:
+		return -1
+	case a.latency < b.latency:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func latencyReason(best *offerCandidate, numCandidates int) string {
+	return fmt.Sprintf("lowest latency (%s) of the makers of %d matching offers",
+		best.latency.Round(time.Millisecond), numCandidates)
+}
+
+// weightedSelector scores each candidate by each criterion, normalized to the
+// range [0, 1] among the candidates, and selects the candidate with the
+// highest weighted sum of its scores.
+type weightedSelector struct {
+	rate       float64
+	liquidity  float64
+	reputation float64
+	latency    float64
+}
+
+// parseSelectionWeights parses weights given as comma-separated name=value
+// pairs, eg. "rate=4,liquidity=1". Criteria that are not listed have a weight
+// of zero.
+func parseSelectionWeights(weights string) (*weightedSelector, error) {
+	w := new(weightedSelector)
+	for _, pair := range strings.Split(weights, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid weight %q, expected name=value", pair)
+		}
+
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid value %q for weight %q", value, name)
+		}
+
+		switch name {
+		case "rate":
+			w.rate = f
+		case "liquidity":
+			w.liquidity = f
+		case "reputation":
+			w.reputation = f
+		case "latency":
+			w.latency = f
+		default:
+			return nil, fmt.Errorf("unknown weight %q", name)
+		}
+	}
+
+	if w.rate+w.liquidity+w.reputation+w.latency == 0 {
+		return nil, fmt.Errorf("at least one weight must be positive")
+	}
+
+	return w, nil
+}
+
+func (w *weightedSelector) needsLatency() bool {
+	return w.latency > 0
+}
+
+func (w *weightedSelector) selectOffer(candidates []*offerCandidate) (*offerCandidate, string) {
+	rates := make([]float64, len(candidates))
+	amounts := make([]float64, len(candidates))
+	latencies := make([]float64, len(candidates))
+	for i, c := range candidates {
+		rates[i], _ = c.offer.ExchangeRate.Decimal().Float64()
+		amounts[i], _ = c.offer.MaxAmount.Float64()
+		latencies[i] = float64(c.latency)
+	}
+
+	rateScores := normalize(rates, true)
+	amountScores := normalize(amounts, false)
+	latencyScores := normalize(latencies, true)
+	reputationScores := make([]float64, len(candidates))
+
+	scores := make([]float64, len(candidates))
+	for i, c := range candidates {
+		// makers we have not swapped with and unmeasured latencies score zero
+		if c.successRate != nil {
+			reputationScores[i], _ = c.successRate.Float64()
+		}
+		if c.latency == 0 {
+			latencyScores[i] = 0
+		}
+
+		scores[i] = w.rate*rateScores[i] +
+			w.liquidity*amountScores[i] +
+			w.reputation*reputationScores[i] +
+			w.latency*latencyScores[i]
+	}
+
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return breakTie(candidates[a], candidates[b])
+	})
+
+	best := order[0]
+	reason := fmt.Sprintf("highest weighted score (%.2f) of %d matching offers, with scores "+
+		"rate=%.2f, liquidity=%.2f, reputation=%.2f, latency=%.2f",
+		scores[best], len(candidates), rateScores[best], amountScores[best], reputationScores[best],
+		latencyScores[best])
+	return candidates[best], reason
+}
+
+// normalize maps the values linearly to the range [0, 1], where 1 is given to
+// the lowest value if lowerIsBetter is true, and to the highest value
+// otherwise. If all values are equal, they all map to 1.
+func normalize(values []float64, lowerIsBetter bool) []float64 {
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	normalized := make([]float64, len(values))
+	for i, v := range values {
+		switch {
+		case max == min:
+			normalized[i] = 1
+		case lowerIsBetter:
+			normalized[i] = (max - v) / (max - min)
+		default:
+			normalized[i] = (v - min) / (max - min)
+		}
+	}
+
+	return normalized
+}
+
+// makerSuccessRates returns the fraction of our completed swaps as the taker
+// with each maker that succeeded.
+func makerSuccessRates(swaps []*rpc.PastSwap) map[peer.ID]*apd.Decimal {
+	type counts struct{ succeeded, total int64 }
+	byPeer := make(map[peer.ID]*counts)
+	for _, s := range swaps {
+		if s.Provided != coins.ProvidesETH {
+			continue
+		}
+
+		c, has := byPeer[s.PeerID]
+		if !has {
+			c = new(counts)
+			byPeer[s.PeerID] = c
+		}
+
+		c.total++
+		if s.Status == types.CompletedSuccess {
+			c.succeeded++
+		}
+	}
+
+	rates := make(map[peer.ID]*apd.Decimal, len(byPeer))
+	for peerID, c := range byPeer {
+		rate := new(apd.Decimal)
+		_, _ = coins.DecimalCtx().Quo(rate, apd.New(c.succeeded, 0), apd.New(c.total, 0))
+		rates[peerID] = rate
+	}
+
+	return rates
+}
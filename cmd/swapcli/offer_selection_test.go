@@ -0,0 +1,144 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// testOfferCandidates returns candidates that are each the best by exactly one
+// criterion:
+//   - cheap has the lowest exchange rate
+//   - deep has the highest maximum amount
+//   - trusted has the maker with the best success rate
+//   - fast has the maker with the lowest latency
+func testOfferCandidates() (cheap, deep, trusted, fast *offerCandidate, all []*offerCandidate) {
+	newCandidate := func(
+		peerID string,
+		rate string,
+		max string,
+		successRate string,
+		latency time.Duration,
+	) *offerCandidate {
+		c := &offerCandidate{
+			peerID: peer.ID(peerID),
+			offer: types.NewOffer(
+				coins.ProvidesXMR,
+				coins.StrToDecimal("0.1"),
+				coins.StrToDecimal(max),
+				coins.StrToExchangeRate(rate),
+				types.EthAssetETH,
+			),
+			latency: latency,
+		}
+		if successRate != "" {
+			c.successRate = coins.StrToDecimal(successRate)
+		}
+		return c
+	}
+
+	cheap = newCandidate("cheap", "0.05", "1", "0.5", 300*time.Millisecond)
+	deep = newCandidate("deep", "0.06", "20", "", 200*time.Millisecond)
+	trusted = newCandidate("trusted", "0.07", "2", "1", 400*time.Millisecond)
+	fast = newCandidate("fast", "0.08", "3", "0.6", 50*time.Millisecond)
+	return cheap, deep, trusted, fast, []*offerCandidate{fast, trusted, deep, cheap}
+}
+
+func TestOfferSelector_singleCriterion(t *testing.T) {
+	cheap, deep, trusted, fast, candidates := testOfferCandidates()
+
+	for strategy, expected := range map[string]*offerCandidate{
+		selectBestRate:   cheap,
+		selectLiquidity:  deep,
+		selectReputation: trusted,
+		selectLatency:    fast,
+	} {
+		selector, err := newOfferSelector(strategy, defaultSelectionWeights)
+		require.NoError(t, err)
+
+		best, reason := selector.selectOffer(candidates)
+		require.Equal(t, expected, best, strategy)
+		require.NotEmpty(t, reason)
+		require.Equal(t, strategy == selectLatency, selector.needsLatency())
+	}
+}
+
+func TestOfferSelector_ties(t *testing.T) {
+	cheap, deep, _, _, _ := testOfferCandidates()
+
+	// without any swap history, the reputation strategy falls back to the rate
+	deep.successRate = nil
+	cheap.successRate = nil
+	selector, err := newOfferSelector(selectReputation, "")
+	require.NoError(t, err)
+	best, reason := selector.selectOffer([]*offerCandidate{deep, cheap})
+	require.Equal(t, cheap, best)
+	require.Contains(t, reason, "fell back to the lowest exchange rate")
+
+	// unmeasured latencies rank last
+	cheap.latency = 0
+	selector, err = newOfferSelector(selectLatency, "")
+	require.NoError(t, err)
+	best, _ = selector.selectOffer([]*offerCandidate{cheap, deep})
+	require.Equal(t, deep, best)
+}
+
+func TestOfferSelector_weighted(t *testing.T) {
+	cheap, deep, trusted, fast, candidates := testOfferCandidates()
+
+	for weights, expected := range map[string]*offerCandidate{
+		"rate=1":                           cheap,
+		"liquidity=1":                      deep,
+		"reputation=1":                     trusted,
+		"latency=1":                        fast,
+		"rate=1,reputation=1":              cheap,
+		"rate=1,reputation=3":              trusted,
+		"rate=0.1,liquidity=1,latency=0.2": deep,
+	} {
+		selector, err := newOfferSelector(selectWeighted, weights)
+		require.NoError(t, err)
+
+		best, reason := selector.selectOffer(candidates)
+		require.Equal(t, expected, best, weights)
+		require.Contains(t, reason, "highest weighted score")
+	}
+
+	selector, err := newOfferSelector(selectWeighted, defaultSelectionWeights)
+	require.NoError(t, err)
+	require.True(t, selector.needsLatency())
+}
+
+func TestOfferSelector_invalid(t *testing.T) {
+	_, err := newOfferSelector("random", "")
+	require.ErrorContains(t, err, "unknown offer selection strategy")
+
+	for _, weights := range []string{"", "rate", "rate=-1", "rate=x", "speed=1", "rate=0,latency=0"} {
+		_, err = newOfferSelector(selectWeighted, weights)
+		require.Error(t, err, weights)
+	}
+}
+
+func Test_makerSuccessRates(t *testing.T) {
+	pastSwap := func(peerID string, provided coins.ProvidesCoin, status types.Status) *rpc.PastSwap {
+		return &rpc.PastSwap{PeerID: peer.ID(peerID), Provided: provided, Status: status}
+	}
+
+	rates := makerSuccessRates([]*rpc.PastSwap{
+		pastSwap("a", coins.ProvidesETH, types.CompletedSuccess),
+		pastSwap("a", coins.ProvidesETH, types.CompletedRefund),
+		pastSwap("a", coins.ProvidesETH, types.CompletedSuccess),
+		pastSwap("a", coins.ProvidesETH, types.CompletedAbort),
+		pastSwap("b", coins.ProvidesETH, types.CompletedSuccess),
+		pastSwap("c", coins.ProvidesXMR, types.CompletedSuccess), // we were the maker
+	})
+
+	require.Len(t, rates, 2)
+	require.Equal(t, "0.5", rates["a"].Text('f'))
+	require.Equal(t, "1", rates["b"].Text('f'))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/athanorlabs/atomic-swap/daemon"
@@ -34,7 +35,9 @@ func TestRunSwapcliWithDaemonTests(t *testing.T) {
 }
 
 func (s *swapCLITestSuite) rpcEndpoint() *rpcclient.Client {
-	return rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", s.conf.RPCPort))
+	c, err := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", s.conf.RPCPort), "")
+	require.NoError(s.T(), err)
+	return c
 }
 
 func (s *swapCLITestSuite) mockDaiAddr() ethcommon.Address {
@@ -0,0 +1,280 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/urfave/cli/v2"
+
+	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/rpcclient"
+)
+
+const (
+	defaultTestSwapXMRAmount    = "0.1"
+	defaultTestSwapExchangeRate = "0.05"
+
+	// testSwapTimeout allows for the confirmation times of stagenet
+	testSwapTimeout = time.Hour
+
+	// testSwapOfferWait is how long we wait for the taker to see the test offer
+	testSwapOfferWait    = 2 * time.Minute
+	testSwapOfferRetryIn = 5 * time.Second
+)
+
+var errTestSwapSameDaemon = errors.New(
+	"test-swap needs two swapd instances, as a daemon cannot take its own offer; " +
+		"set --maker-swapd-port and --maker-swapd-host to the daemon making the offer")
+
+func runTestSwap(ctx *cli.Context) error {
+	xmrAmount, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagXMRAmount)
+	if err != nil {
+		return err
+	}
+
+	exchangeRateDec, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagExchangeRate)
+	if err != nil {
+		return err
+	}
+	exchangeRate := coins.ToExchangeRate(exchangeRateDec)
+
+	ethAmount, err := exchangeRate.ToETH(xmrAmount)
+	if err != nil {
+		return err
+	}
+
+	makerPort := strconv.FormatUint(uint64(ctx.Uint(flagMakerSwapdPort)), 10)
+	makerHostPort := net.JoinHostPort(ctx.String(flagMakerSwapdHost), makerPort)
+	takerHostPort := swapdHostPort(ctx)
+
+	// created before the context below, so the test offer is still removed when
+	// the test swap is interrupted
+	makerCleanup, err := newRRPClientForHost(ctx, makerHostPort)
+	if err != nil {
+		return err
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	timeoutCtx, cancel := context.WithTimeout(sigCtx, testSwapTimeout)
+	defer cancel()
+	ctx.Context = timeoutCtx
+
+	maker, err := newRRPClientForHost(ctx, makerHostPort)
+	if err != nil {
+		return err
+	}
+
+	taker, err := newRRPClientForHost(ctx, takerHostPort)
+	if err != nil {
+		return err
+	}
+
+	if err = checkTestSwapEnv(maker, taker); err != nil {
+		return err
+	}
+	printTestSwapStep("Both daemons are reachable and on a test network")
+
+	makerBalancesBefore, err := maker.Balances(nil)
+	if err != nil {
+		return err
+	}
+	takerBalancesBefore, err := taker.Balances(nil)
+	if err != nil {
+		return err
+	}
+
+	makerWS, err := newWSClientForHost(ctx, makerHostPort)
+	if err != nil {
+		return err
+	}
+	defer makerWS.Close()
+
+	offerResp, makerStatusCh, err := makerWS.MakeOfferAndSubscribe(
+		xmrAmount,
+		xmrAmount,
+		exchangeRate,
+		types.EthAssetETH,
+		false,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to make test offer: %w", err)
+	}
+	printTestSwapStep(fmt.Sprintf("Maker published offer %s for %s XMR", offerResp.OfferID, xmrAmount.Text('f')))
+
+	// The offer is removed by the maker once it is taken, but it would be left
+	// behind if the swap never started.
+	defer func() {
+		if err := makerCleanup.ClearOffers([]types.Hash{offerResp.OfferID}); err != nil { //nolint:govet
+			fmt.Printf("Failed to remove test offer %s: %s\n", offerResp.OfferID, err)
+		}
+	}()
+
+	if err = waitForTestOffer(ctx.Context, taker, offerResp.PeerID, offerResp.OfferID); err != nil {
+		return err
+	}
+	printTestSwapStep("Taker found the test offer")
+
+	takerWS, err := newWSClientForHost(ctx, takerHostPort)
+	if err != nil {
+		return err
+	}
+	defer takerWS.Close()
+
+	takerStatusCh, err := takerWS.TakeOfferAndSubscribe(offerResp.PeerID, offerResp.OfferID, ethAmount)
+	if err != nil {
+		return fmt.Errorf("failed to take test offer: %w", err)
+	}
+	printTestSwapStep(fmt.Sprintf("Taker took offer providing %s ETH", ethAmount.Text('f')))
+
+	makerStatus, takerStatus := types.ExpectingKeys, types.ExpectingKeys
+	for makerStatusCh != nil || takerStatusCh != nil {
+		select {
+		case status, ok := <-makerStatusCh:
+			if !ok {
+				makerStatusCh = nil
+				continue
+			}
+			makerStatus = status
+			printTestSwapStep(fmt.Sprintf("Maker status: %s (%s)", status, status.Description()))
+		case status, ok := <-takerStatusCh:
+			if !ok {
+				takerStatusCh = nil
+				continue
+			}
+			takerStatus = status
+			printTestSwapStep(fmt.Sprintf("Taker status: %s (%s)", status, status.Description()))
+		case <-ctx.Context.Done():
+			return fmt.Errorf("test swap did not complete: %w", ctx.Context.Err())
+		}
+	}
+
+	if makerStatus != types.CompletedSuccess || takerStatus != types.CompletedSuccess {
+		return fmt.Errorf("test swap failed: maker finished with %s, taker finished with %s", makerStatus, takerStatus)
+	}
+	printTestSwapStep("Test swap completed successfully")
+
+	makerBalancesAfter, err := maker.Balances(nil)
+	if err != nil {
+		return err
+	}
+	takerBalancesAfter, err := taker.Balances(nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("Maker balances (before -> after):")
+	fmt.Printf("\tETH: %s -> %s\n",
+		makerBalancesBefore.WeiBalance.AsEtherString(), makerBalancesAfter.WeiBalance.AsEtherString())
+	fmt.Printf("\tXMR: %s -> %s\n",
+		makerBalancesBefore.PiconeroBalance.AsMoneroString(), makerBalancesAfter.PiconeroBalance.AsMoneroString())
+	fmt.Println("Taker balances (before -> after):")
+	fmt.Printf("\tETH: %s -> %s\n",
+		takerBalancesBefore.WeiBalance.AsEtherString(), takerBalancesAfter.WeiBalance.AsEtherString())
+	fmt.Printf("\tXMR: %s -> %s\n",
+		takerBalancesBefore.PiconeroBalance.AsMoneroString(), takerBalancesAfter.PiconeroBalance.AsMoneroString())
+	fmt.Println("Note: received XMR can take several blocks to show in the balance.")
+
+	return nil
+}
+
+// checkTestSwapEnv verifies that both daemons run in the same environment and that
+// it is not mainnet, so a test swap can never move real funds.
+func checkTestSwapEnv(maker, taker *rpcclient.Client) error {
+	makerVersion, err := maker.Version()
+	if err != nil {
+		return fmt.Errorf("failed to reach maker daemon: %w", err)
+	}
+
+	takerVersion, err := taker.Version()
+	if err != nil {
+		return fmt.Errorf("failed to reach taker daemon: %w", err)
+	}
+
+	if makerVersion.Env == common.Mainnet || takerVersion.Env == common.Mainnet {
+		return errors.New("test-swap is not allowed on mainnet")
+	}
+
+	if makerVersion.Env != takerVersion.Env {
+		return fmt.Errorf("maker daemon is on %s, but taker daemon is on %s", makerVersion.Env, takerVersion.Env)
+	}
+
+	// different host:port pairs can still reach the same daemon, so we compare
+	// the identities of the daemons instead
+	makerPeerID, err := daemonPeerID(maker)
+	if err != nil {
+		return fmt.Errorf("failed to get maker daemon's peer ID: %w", err)
+	}
+
+	takerPeerID, err := daemonPeerID(taker)
+	if err != nil {
+		return fmt.Errorf("failed to get taker daemon's peer ID: %w", err)
+	}
+
+	if makerPeerID == takerPeerID {
+		return errTestSwapSameDaemon
+	}
+
+	return nil
+}
+
+// daemonPeerID returns the peer ID of the daemon, which is part of each of its
+// listening addresses.
+func daemonPeerID(c *rpcclient.Client) (peer.ID, error) {
+	resp, err := c.Addresses()
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Addrs) == 0 {
+		return "", errors.New("daemon has no listening addresses")
+	}
+
+	addrInfo, err := peer.AddrInfoFromString(resp.Addrs[0])
+	if err != nil {
+		return "", err
+	}
+
+	return addrInfo.ID, nil
+}
+
+// waitForTestOffer waits until the taker can see the maker's test offer, as it can
+// take a moment for a new offer to be advertised.
+func waitForTestOffer(ctx context.Context, taker *rpcclient.Client, makerID peer.ID, offerID types.Hash) error {
+	waitCtx, cancel := context.WithTimeout(ctx, testSwapOfferWait)
+	defer cancel()
+
+	for {
+		resp, err := taker.Query(makerID)
+		if err == nil {
+			for _, offer := range resp.Offers {
+				if offer.ID == offerID {
+					return nil
+				}
+			}
+		}
+
+		if err = common.SleepWithContext(waitCtx, testSwapOfferRetryIn); err != nil {
+			return fmt.Errorf("taker did not find test offer %s from peer %s", offerID, makerID)
+		}
+	}
+}
+
+func printTestSwapStep(msg string) {
+	fmt.Printf("%s > %s\n", time.Now().Format(common.TimeFmtSecs), msg)
+}
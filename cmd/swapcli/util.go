@@ -1,16 +1,32 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/urfave/cli/v2"
 
+	"github.com/athanorlabs/atomic-swap/cliutil"
 	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/rpcclient"
 )
 
+// swapdHostPort returns the "host:port" of the swap daemon, with the host
+// bracketed if it is an IPv6 address.
+func swapdHostPort(ctx *cli.Context) string {
+	swapdPort := ctx.Uint(flagSwapdPort)
+	return net.JoinHostPort(ctx.String(flagSwapdHost), strconv.FormatUint(uint64(swapdPort), 10))
+}
+
 // _tokenCache should only be directly accessed by lookupToken
 var _tokenCache = make(map[ethcommon.Address]*coins.ERC20TokenInfo)
 
@@ -124,3 +140,258 @@ func printOffer(c *rpcclient.Client, o *types.Offer, index int, indent string) e
 	fmt.Printf("%sTaker Max: %s %s\n", indent, maxTake.Text('f'), receivedCoin)
 	return nil
 }
+
+// readUnsignedDecimalFlagOrDefault returns the value of the flag if it was set,
+// otherwise the default value. If the default value is nil, the flag is
+// required.
+func readUnsignedDecimalFlagOrDefault(
+	ctx *cli.Context,
+	flagName string,
+	defaultValue *apd.Decimal,
+) (*apd.Decimal, error) {
+	if defaultValue != nil && !ctx.IsSet(flagName) {
+		return defaultValue, nil
+	}
+
+	return cliutil.ReadUnsignedDecimalFlag(ctx, flagName)
+}
+
+// offerFilter holds the optional criteria that query-all filters offers by.
+// Criteria that are nil match every offer.
+type offerFilter struct {
+	maxExchangeRate *coins.ExchangeRate
+	minAmount       *apd.Decimal // in XMR
+	maxAmount       *apd.Decimal // in XMR
+}
+
+// matches returns true if the offer's exchange rate is at most the maximum
+// exchange rate, and the offer's amount range overlaps the filter's amount
+// range.
+func (f *offerFilter) matches(o *types.Offer) bool {
+	if f.maxExchangeRate != nil && o.ExchangeRate.Cmp(f.maxExchangeRate) > 0 {
+		return false
+	}
+
+	if f.minAmount != nil && o.MaxAmount.Cmp(f.minAmount) < 0 {
+		return false
+	}
+
+	if f.maxAmount != nil && o.MinAmount.Cmp(f.maxAmount) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// readOfferFilter returns the offer filter set by the query-all flags.
+func readOfferFilter(ctx *cli.Context) (*offerFilter, error) {
+	f := new(offerFilter)
+
+	if ctx.IsSet(flagMaxExchangeRate) {
+		rate, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMaxExchangeRate)
+		if err != nil {
+			return nil, err
+		}
+		f.maxExchangeRate = coins.ToExchangeRate(rate)
+	}
+
+	if ctx.IsSet(flagMinAmount) {
+		min, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMinAmount)
+		if err != nil {
+			return nil, err
+		}
+		f.minAmount = min
+	}
+
+	if ctx.IsSet(flagMaxAmount) {
+		max, err := cliutil.ReadUnsignedDecimalFlag(ctx, flagMaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		f.maxAmount = max
+	}
+
+	if f.minAmount != nil && f.maxAmount != nil && f.minAmount.Cmp(f.maxAmount) > 0 {
+		return nil, fmt.Errorf("value of --%s cannot be greater than --%s", flagMinAmount, flagMaxAmount)
+	}
+
+	return f, nil
+}
+
+// Policies for splitting --provides-amount between the offers of take-all
+const (
+	splitEach = "each"
+	splitEven = "even"
+)
+
+// errAmountTooSmallToSplit is returned by takeAllAmount when the even shares of
+// the amount would be zero.
+var errAmountTooSmallToSplit = errors.New("amount is too small to split")
+
+// takeAllAmount returns the amount to provide to each of numOffers offers. With
+// splitEach, every offer is taken with the full amount. With splitEven, the amount
+// is divided evenly between the offers, rounded down to the given number of
+// decimal places of the offers' asset, so the total provided never exceeds the
+// amount.
+func takeAllAmount(amount *apd.Decimal, numOffers int, split string, decimals uint8) (*apd.Decimal, error) {
+	switch split {
+	case splitEach:
+		return amount, nil
+	case splitEven:
+		dctx := coins.DecimalCtx()
+		dctx.Rounding = apd.RoundDown
+		share := new(apd.Decimal)
+		if _, err := dctx.Quo(share, amount, apd.New(int64(numOffers), 0)); err != nil {
+			return nil, err
+		}
+		if _, err := dctx.Quantize(share, share, -int32(decimals)); err != nil {
+			return nil, err
+		}
+		if share.IsZero() {
+			return nil, fmt.Errorf("%w: %s between %d offers with %d decimal places",
+				errAmountTooSmallToSplit, amount.Text('f'), numOffers, decimals)
+		}
+		_, _ = share.Reduce(share)
+		return share, nil
+	default:
+		return nil, fmt.Errorf("unknown split policy %q, expected %q or %q", split, splitEach, splitEven)
+	}
+}
+
+// offersMinDecimals returns the smallest number of decimal places of the ETH
+// assets of the given offers, querying each maker for its offers.
+func offersMinDecimals(c *rpcclient.Client, peerIDs []peer.ID, offerIDs []types.Hash) (uint8, error) {
+	peerOffers := make(map[peer.ID][]*types.Offer)
+	decimals := uint8(coins.NumEtherDecimals)
+
+	for i, offerID := range offerIDs {
+		offers, ok := peerOffers[peerIDs[i]]
+		if !ok {
+			resp, err := c.Query(peerIDs[i])
+			if err != nil {
+				return 0, err
+			}
+			offers = resp.Offers
+			peerOffers[peerIDs[i]] = offers
+		}
+
+		var offer *types.Offer
+		for _, o := range offers {
+			if o.ID == offerID {
+				offer = o
+				break
+			}
+		}
+		if offer == nil {
+			return 0, fmt.Errorf("offer %s not found on peer %s", offerID, peerIDs[i])
+		}
+
+		if offer.EthAsset.IsETH() {
+			continue
+		}
+
+		token, err := lookupToken(c, offer.EthAsset.Address())
+		if err != nil {
+			return 0, err
+		}
+		if token.NumDecimals < decimals {
+			decimals = token.NumDecimals
+		}
+	}
+
+	return decimals, nil
+}
+
+// parseTimeFlag parses a time flag value given as a date, an RFC 3339 timestamp,
+// or a duration before now.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration %q must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date, RFC 3339 timestamp or duration", value)
+	}
+	return t, nil
+}
+
+// readMarkupFlag reads the --markup flag, a signed percentage that must be
+// greater than -100.
+func readMarkupFlag(ctx *cli.Context) (*apd.Decimal, error) {
+	s := ctx.String(flagMarkup)
+	markup, _, err := new(apd.Decimal).SetString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for flag --%s", s, flagMarkup)
+	}
+
+	if markup.Cmp(apd.New(-100, 0)) <= 0 {
+		return nil, fmt.Errorf("value of flag --%s must be greater than -100", flagMarkup)
+	}
+
+	return markup, nil
+}
+
+// applyMarkup returns the market rate increased by markup percent, rounded up
+// to the maximum number of decimal places of an exchange rate. A negative
+// markup decreases the rate.
+func applyMarkup(market *coins.ExchangeRate, markup *apd.Decimal) (*coins.ExchangeRate, error) {
+	factor := new(apd.Decimal)
+	if _, err := coins.DecimalCtx().Quo(factor, markup, apd.New(100, 0)); err != nil {
+		return nil, err
+	}
+	if _, err := coins.DecimalCtx().Add(factor, factor, apd.New(1, 0)); err != nil {
+		return nil, err
+	}
+
+	rate := new(apd.Decimal)
+	if _, err := coins.DecimalCtx().Mul(rate, market.Decimal(), factor); err != nil {
+		return nil, err
+	}
+	if err := coins.RoundUpToDecimalPlace(rate, rate, coins.MaxExchangeRateDecimals); err != nil {
+		return nil, err
+	}
+
+	if err := coins.ValidatePositive("exchangeRate", coins.MaxExchangeRateDecimals, rate); err != nil {
+		return nil, err
+	}
+
+	return coins.ToExchangeRate(rate), nil
+}
+
+// claimMethodDescription returns a human readable description of the claim
+// method of a swap.
+func claimMethodDescription(method string) string {
+	switch method {
+	case pswap.ClaimMethodSelf:
+		return "self"
+	case pswap.ClaimMethodRelayed:
+		return "relayed"
+	case pswap.ClaimMethodSelfNearTimeout1:
+		return "self, too close to timeout1 to relay"
+	case pswap.ClaimMethodManual:
+		return "needs manual attention, too close to timeout1 to relay and balance too low to claim"
+	default:
+		return method
+	}
+}
+
+// printTxHash prints the hash of one of a swap's transactions, if it is set.
+func printTxHash(label string, txHash *ethcommon.Hash) {
+	if txHash != nil {
+		fmt.Printf("%s: %s\n", label, txHash)
+	}
+}
+
+// formatPeerStats returns the counts of the outcomes of the swaps with a peer
+// as a single line.
+func formatPeerStats(s *rpctypes.PeerStats) string {
+	return fmt.Sprintf("succeeded=%d refunded=%d aborted=%d", s.NumSucceeded, s.NumRefunded, s.NumAborted)
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 
@@ -71,3 +75,108 @@ func (s *swapCLITestSuite) Test_printOffer() {
 	err := printOffer(c, o, 0, "")
 	require.NoError(s.T(), err)
 }
+
+func Test_takeAllAmount(t *testing.T) {
+	amount := coins.StrToDecimal("1")
+
+	each, err := takeAllAmount(amount, 3, splitEach, coins.NumEtherDecimals)
+	require.NoError(t, err)
+	require.Equal(t, "1", each.Text('f'))
+
+	// rounded down to whole wei, so 3 shares do not exceed the amount
+	even, err := takeAllAmount(amount, 3, splitEven, coins.NumEtherDecimals)
+	require.NoError(t, err)
+	require.Equal(t, "0.333333333333333333", even.Text('f'))
+
+	even, err = takeAllAmount(amount, 4, splitEven, coins.NumEtherDecimals)
+	require.NoError(t, err)
+	require.Equal(t, "0.25", even.Text('f'))
+
+	// rounded down to the token's smallest unit
+	even, err = takeAllAmount(amount, 3, splitEven, 6)
+	require.NoError(t, err)
+	require.Equal(t, "0.333333", even.Text('f'))
+
+	_, err = takeAllAmount(apd.New(1, -18), 2, splitEven, coins.NumEtherDecimals) // 1 wei
+	require.ErrorIs(t, err, errAmountTooSmallToSplit)
+
+	_, err = takeAllAmount(apd.New(1, -6), 2, splitEven, 6)
+	require.ErrorIs(t, err, errAmountTooSmallToSplit)
+
+	_, err = takeAllAmount(amount, 2, "random", coins.NumEtherDecimals)
+	require.ErrorContains(t, err, "unknown split policy")
+}
+
+func Test_offerFilter(t *testing.T) {
+	o := types.NewOffer(
+		coins.ProvidesXMR,
+		coins.StrToDecimal("1"),
+		coins.StrToDecimal("2"),
+		coins.StrToExchangeRate("0.05"),
+		types.EthAssetETH,
+	)
+
+	require.True(t, new(offerFilter).matches(o))
+
+	f := &offerFilter{maxExchangeRate: coins.StrToExchangeRate("0.05")}
+	require.True(t, f.matches(o))
+	f.maxExchangeRate = coins.StrToExchangeRate("0.04")
+	require.False(t, f.matches(o))
+
+	// amount ranges overlapping the offer's range of 1 to 2 XMR
+	f = &offerFilter{minAmount: coins.StrToDecimal("2"), maxAmount: coins.StrToDecimal("3")}
+	require.True(t, f.matches(o))
+	f = &offerFilter{minAmount: coins.StrToDecimal("0.5"), maxAmount: coins.StrToDecimal("1")}
+	require.True(t, f.matches(o))
+	f = &offerFilter{minAmount: coins.StrToDecimal("1.2"), maxAmount: coins.StrToDecimal("1.5")}
+	require.True(t, f.matches(o))
+
+	// amount ranges outside of the offer's range
+	f = &offerFilter{minAmount: coins.StrToDecimal("2.1")}
+	require.False(t, f.matches(o))
+	f = &offerFilter{maxAmount: coins.StrToDecimal("0.9")}
+	require.False(t, f.matches(o))
+}
+
+func Test_parseTimeFlag(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseTimeFlag("720h", now)
+	require.NoError(t, err)
+	require.Equal(t, now.AddDate(0, 0, -30), since)
+
+	ts, err := parseTimeFlag("2023-04-01T10:30:00Z", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 4, 1, 10, 30, 0, 0, time.UTC), ts)
+
+	date, err := parseTimeFlag("2023-04-01", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 4, 1, 0, 0, 0, 0, time.Local), date)
+
+	_, err = parseTimeFlag("-1h", now)
+	require.ErrorContains(t, err, "must not be negative")
+
+	_, err = parseTimeFlag("last week", now)
+	require.ErrorContains(t, err, "is not a date")
+}
+
+func Test_applyMarkup(t *testing.T) {
+	market := coins.StrToExchangeRate("0.05")
+
+	rate, err := applyMarkup(market, coins.StrToDecimal("2"))
+	require.NoError(t, err)
+	require.Equal(t, "0.051", rate.String())
+
+	rate, err = applyMarkup(market, coins.StrToDecimal("-10"))
+	require.NoError(t, err)
+	require.Equal(t, "0.045", rate.String())
+
+	rate, err = applyMarkup(market, new(apd.Decimal))
+	require.NoError(t, err)
+	require.Equal(t, "0.05", rate.String())
+
+	// rounded up to the maximum exchange rate decimals
+	rate, err = applyMarkup(coins.StrToExchangeRate("0.123456"), coins.StrToDecimal("1"))
+	require.NoError(t, err)
+	require.Equal(t, "0.124691", rate.String())
+}
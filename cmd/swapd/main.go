@@ -10,8 +10,12 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"math/big"
+	"net/url"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	logging "github.com/ipfs/go-log"
@@ -24,6 +28,10 @@ import (
 	"github.com/athanorlabs/atomic-swap/daemon"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	"github.com/athanorlabs/atomic-swap/rpc"
 )
 
 const (
@@ -36,6 +44,40 @@ const (
 	defaultRPCPort         = common.DefaultSwapdPort
 	defaultXMRTakerRPCPort = defaultRPCPort
 	defaultXMRMakerRPCPort = defaultXMRTakerRPCPort + 1
+
+	// number of unknown or unexpected messages dropped before a swap is aborted
+	defaultMaxDroppedMessages = 10
+
+	// number of attempts to resume a swap's dropped protocol stream
+	defaultMaxStreamResumeAttempts = 3
+
+	// number of take requests a single peer can make per minute
+	defaultMaxTakesPerMinute = 30
+
+	// number of times, and the delay before the first time, that requests to
+	// an unavailable monero-wallet-rpc are retried
+	defaultWalletMaxRetries = 5
+	defaultWalletRetryDelay = time.Second
+
+	// number of concurrent websocket connections to the RPC server
+	defaultMaxWsConnections = 256
+
+	// number of claims relayed at a time, and how long further claim requests
+	// wait for one of them to finish
+	defaultMaxConcurrentRelays = 4
+	defaultRelayQueueTimeout   = 30 * time.Second
+
+	// how long the suggested exchange rate is cached before the price feeds
+	// are read again
+	defaultRateCacheTTL = time.Minute
+
+	// age beyond which the prices of a price provider are considered stale,
+	// a little over the 24 hour heartbeat of Chainlink's XMR/USD feed
+	defaultMaxPriceAge = 25 * time.Hour
+
+	// number of ongoing swaps kept in memory, beyond which they're loaded from
+	// the database on demand
+	defaultMaxOngoingSwapsInMemory = 1000
 )
 
 var (
@@ -51,11 +93,12 @@ var (
 )
 
 const (
-	flagRPCPort    = "rpc-port"
-	flagDataDir    = "data-dir"
-	flagLibp2pKey  = "libp2p-key"
-	flagLibp2pPort = "libp2p-port"
-	flagBootnodes  = "bootnodes"
+	flagRPCPort       = "rpc-port"
+	flagDataDir       = "data-dir"
+	flagNoDataDirLock = "no-data-dir-lock"
+	flagLibp2pKey     = "libp2p-key"
+	flagLibp2pPort    = "libp2p-port"
+	flagBootnodes     = "bootnodes"
 
 	flagEnv                  = "env"
 	flagMoneroDaemonHost     = "monerod-host"
@@ -63,22 +106,63 @@ const (
 	flagMoneroWalletPath     = "wallet-file"
 	flagMoneroWalletPassword = "wallet-password"
 	flagMoneroWalletPort     = "wallet-port"
+	flagWalletMaxRetries     = "wallet-max-retries"
+	flagWalletRetryDelay     = "wallet-retry-delay"
 	flagEthEndpoint          = "eth-endpoint"
+	flagEthFailoverEndpoints = "eth-failover-endpoints"
 	flagEthPrivKey           = "eth-privkey"
 	flagContractAddress      = "contract-address"
 	flagGasPrice             = "gas-price"
+	flagMaxGasPrice          = "max-gas-price"
+	flagClearStuckTxs        = "clear-stuck-txs"
+	flagMaxFeePerGas         = "max-fee-per-gas"
+	flagMaxPriorityFeePerGas = "max-priority-fee-per-gas"
 	flagGasLimit             = "gas-limit"
 	flagUseExternalSigner    = "external-signer"
 	flagRelayer              = "relayer"
-
-	flagDevXMRTaker      = "dev-xmrtaker"
-	flagDevXMRMaker      = "dev-xmrmaker"
-	flagDeploy           = "deploy"
-	flagForwarderAddress = "forwarder-address"
-	flagNoTransferBack   = "no-transfer-back"
-
-	flagLogLevel = cliutil.FlagLogLevel
-	flagProfile  = "profile"
+	flagObserver             = "observer"
+
+	flagDevXMRTaker          = "dev-xmrtaker"
+	flagDevXMRMaker          = "dev-xmrmaker"
+	flagDeploy               = "deploy"
+	flagForwarderAddress     = "forwarder-address"
+	flagNoTransferBack       = "no-transfer-back"
+	flagAutoClearOffers      = "auto-clear-offers"
+	flagPersistOfferDefaults = "persist-offer-defaults"
+	flagEventSocket          = "event-socket"
+	flagWsCompression        = "ws-compression"
+	flagMaxWsConnections     = "max-ws-connections"
+	flagMetrics              = "metrics"
+	flagAuthToken            = "auth-token"
+	flagAllowedOrigins       = "allowed-origins"
+	flagRelayerFee           = "relayer-fee"
+	flagMaxConcurrentRelays  = "max-concurrent-relays"
+	flagRelayQueueTimeout    = "relay-queue-timeout"
+	flagRateSampleInterval   = "rate-sample-interval"
+	flagRateCacheTTL         = "rate-cache-ttl"
+	flagPriceProviders       = "price-providers"
+	flagMaxPriceAge          = "max-price-age"
+	flagMinETHBalance        = "min-eth-balance"
+	flagXMRConfirmations     = "xmr-confirmations"
+	flagEthConfirmations     = "eth-confirmations"
+	flagMaxSwapDuration      = "max-swap-duration"
+	flagRelayClaimBuffer     = "relay-claim-buffer"
+	flagXMRLockDecimals      = "xmr-lock-decimals"
+	flagMaxTakesPerMinute    = "max-takes-per-minute"
+	flagXMRPayoutAddress     = "xmr-payout-address"
+	flagEventChSize          = "event-ch-size"
+	flagLogChSize            = "log-ch-size"
+
+	flagMaxOngoingSwapsInMemory = "max-ongoing-swaps-in-memory"
+
+	flagAbortOnUnknownMessages    = "abort-on-unknown-messages"
+	flagAbortOnUnexpectedMessages = "abort-on-unexpected-messages"
+	flagMaxDroppedMessages        = "max-dropped-messages"
+	flagMaxStreamResumeAttempts   = "max-stream-resume-attempts"
+
+	flagLogLevel  = cliutil.FlagLogLevel
+	flagLogFormat = cliutil.FlagLogFormat
+	flagProfile   = "profile"
 )
 
 func cliApp() *cli.App {
@@ -101,6 +185,11 @@ func cliApp() *cli.App {
 				Usage: "Path to store swap artifacts",
 				Value: "{HOME}/.atomicswap/{ENV}", // For --help only, actual default replaces variables
 			},
+			&cli.BoolFlag{
+				Name: flagNoDataDirLock,
+				Usage: "Don't lock the data directory against use by a second swapd instance. Only\n" +
+					"use this if the data directory is on a filesystem without file lock support.",
+			},
 			&cli.StringFlag{
 				Name:  flagLibp2pKey,
 				Usage: "libp2p private key",
@@ -144,12 +233,28 @@ func cliApp() *cli.App {
 				Usage:  "The port that the internal monero-wallet-rpc instance listens on",
 				Hidden: true, // flag is for integration tests and won't be supported long term
 			},
+			&cli.UintFlag{
+				Name:  flagWalletMaxRetries,
+				Usage: "Number of times a request to monero-wallet-rpc is retried while the wallet is unavailable",
+				Value: defaultWalletMaxRetries,
+			},
+			&cli.DurationFlag{
+				Name:  flagWalletRetryDelay,
+				Usage: "Delay before retrying a request to monero-wallet-rpc, doubled with each further retry",
+				Value: defaultWalletRetryDelay,
+			},
 			&cli.StringFlag{
 				Name:    flagEthEndpoint,
 				Usage:   "Ethereum client endpoint",
 				Aliases: []string{"ethereum-endpoint"},
 				EnvVars: []string{"SWAPD_ETH_ENDPOINT"},
 			},
+			&cli.StringSliceFlag{
+				Name: flagEthFailoverEndpoints,
+				Usage: "Ethereum client endpoints to fail over to, in order, if the endpoint is unreachable," +
+					" comma separated if passing multiple to a single flag. All endpoints must use http or https",
+				EnvVars: []string{"SWAPD_ETH_FAILOVER_ENDPOINTS"},
+			},
 			&cli.StringFlag{
 				Name:    flagEthPrivKey,
 				Usage:   "File containing ethereum private key as hex, new key is generated if missing",
@@ -171,6 +276,25 @@ func cliApp() *cli.App {
 				Name:  flagGasPrice,
 				Usage: "Ethereum gas price to use for transactions (in gwei). If not set, the gas price is set via oracle.",
 			},
+			&cli.UintFlag{
+				Name: flagMaxGasPrice,
+				Usage: "Maximum Ethereum gas price for swap transactions (in gwei). Transactions are not sent " +
+					"while the gas price is above it. If not set, there is no maximum.",
+			},
+			&cli.BoolFlag{
+				Name: flagClearStuckTxs,
+				Usage: "At startup, replace any Ethereum transactions from our account that are pending but " +
+					"not mined with zero-value transfers to ourselves, so new transactions don't queue behind them",
+			},
+			&cli.UintFlag{
+				Name: flagMaxFeePerGas,
+				Usage: "EIP-1559 max fee per gas for swap transactions (in gwei). If this or " +
+					fmt.Sprintf("--%s is set, transactions use EIP-1559 fees.", flagMaxPriorityFeePerGas),
+			},
+			&cli.UintFlag{
+				Name:  flagMaxPriorityFeePerGas,
+				Usage: "EIP-1559 max priority fee per gas for swap transactions (in gwei)",
+			},
 			&cli.UintFlag{
 				Name:  flagGasLimit,
 				Usage: "Ethereum gas limit to use for transactions. If not set, the gas limit is estimated for each transaction.",
@@ -195,12 +319,137 @@ func cliApp() *cli.App {
 				Name:  flagNoTransferBack,
 				Usage: "Leave XMR in generated swap wallet instead of sweeping funds to primary.",
 			},
+			&cli.BoolFlag{
+				Name: flagAutoClearOffers,
+				Usage: "Withdraw offers when the unlocked XMR balance falls below their minimum amount, " +
+					"and restore them when the balance recovers",
+			},
+			&cli.Uint64Flag{
+				Name: flagXMRConfirmations,
+				Usage: "Number of confirmations required on the XMR lock transaction when acting as the maker " +
+					"(0 for the default)",
+			},
+			&cli.Uint64Flag{
+				Name: flagEthConfirmations,
+				Usage: "Number of blocks that must be mined on top of a swap contract log (Ready, Claimed or " +
+					"Refunded) before it is acted on",
+			},
+			&cli.DurationFlag{
+				Name: flagMaxSwapDuration,
+				Usage: "Exit swaps that are still ongoing after this duration, refunding any locked funds " +
+					"once it is safe to do so (0 to disable)",
+			},
+			&cli.DurationFlag{
+				Name: flagRelayClaimBuffer,
+				Usage: "Minimum time left before the second swap timeout to claim using a relayer when acting " +
+					"as the maker, otherwise claim without one (0 to disable)",
+			},
+			&cli.UintFlag{
+				Name: flagXMRLockDecimals,
+				Usage: "Decimal place that XMR amounts locked as the maker are rounded up to, to avoid " +
+					"dust amounts. Rounding never locks less than the agreed amount.",
+				Value: coins.NumMoneroDecimals,
+			},
+			&cli.UintFlag{
+				Name: flagMaxTakesPerMinute,
+				Usage: "Number of take requests a single peer can make per minute when acting as the maker, " +
+					"beyond which its takes are rejected (0 for no limit)",
+				Value: defaultMaxTakesPerMinute,
+			},
+			&cli.StringFlag{
+				Name: flagXMRPayoutAddress,
+				Usage: "Primary address or subaddress of our wallet that XMR is swept to when reclaimed as " +
+					"the maker (default: the primary address)",
+			},
+			&cli.BoolFlag{
+				Name: flagPersistOfferDefaults,
+				Usage: "Remember the amounts and exchange rate of the last offer made for each asset, " +
+					"so they can be reused with swapcli make --reuse-last",
+			},
+			&cli.StringFlag{
+				Name: flagEventSocket,
+				Usage: "Path of a Unix socket on which to emit swap status events, using the same " +
+					"event format as the websocket subscriptions",
+			},
+			&cli.BoolFlag{
+				Name: flagWsCompression,
+				Usage: "Compress websocket messages for clients that support per-message deflate, " +
+					"trading CPU for bandwidth on high-volume subscriptions",
+			},
+			&cli.UintFlag{
+				Name:  flagMaxWsConnections,
+				Usage: "Maximum number of concurrent websocket connections to the RPC server (0 for no limit)",
+				Value: defaultMaxWsConnections,
+			},
+			&cli.BoolFlag{
+				Name:  flagMetrics,
+				Usage: "Serve Prometheus metrics on the /metrics path of the RPC server",
+			},
+			&cli.StringFlag{
+				Name: flagAuthToken,
+				Usage: "Require this token in the Authorization header of all RPC, websocket and metrics " +
+					"requests, as a bearer token or a basic auth password",
+				EnvVars: []string{"SWAPD_AUTH_TOKEN"},
+			},
+			&cli.StringSliceFlag{
+				Name: flagAllowedOrigins,
+				Usage: "Origins of the web pages allowed to make RPC and websocket requests, eg. " +
+					"http://localhost:3000, comma separated if passing multiple to a single flag. " +
+					"All origins are allowed if not set.",
+				EnvVars: []string{"SWAPD_ALLOWED_ORIGINS"},
+			},
+			&cli.BoolFlag{
+				Name:  flagAbortOnUnknownMessages,
+				Usage: "Abort a swap when the peer sends a message of an unknown type, instead of dropping the message",
+			},
+			&cli.BoolFlag{
+				Name: flagAbortOnUnexpectedMessages,
+				Usage: "Abort a swap when the peer sends a message that is not expected at the current stage " +
+					"of the swap, instead of ignoring the message",
+			},
+			&cli.UintFlag{
+				Name:  flagMaxDroppedMessages,
+				Usage: "Number of unknown or unexpected messages from a peer that are dropped before a swap is aborted (0 for no limit)",
+				Value: defaultMaxDroppedMessages,
+			},
+			&cli.UintFlag{
+				Name: flagMaxStreamResumeAttempts,
+				Usage: "Number of attempts to resume a swap's dropped network stream before the swap's " +
+					"funds are locked, instead of exiting the swap (0 to disable)",
+				Value: defaultMaxStreamResumeAttempts,
+			},
+			&cli.UintFlag{
+				Name:  flagEventChSize,
+				Usage: "Buffer size of each swap's event channel",
+				Value: backend.DefaultEventChSize,
+			},
+			&cli.UintFlag{
+				Name:  flagLogChSize,
+				Usage: "Buffer size of the channels on which each swap receives contract logs",
+				Value: backend.DefaultLogChSize,
+			},
+			&cli.UintFlag{
+				Name: flagMaxOngoingSwapsInMemory,
+				Usage: "Maximum number of ongoing swaps that are not being run kept in memory, beyond which " +
+					"the least active ones are read from the database on demand (0 for no limit)",
+				Value: defaultMaxOngoingSwapsInMemory,
+			},
+			&cli.StringFlag{
+				Name:  flagMinETHBalance,
+				Usage: "Warn when the ETH balance available for gas drops below this amount (in ETH)",
+			},
 			&cli.StringFlag{
 				Name:    flagLogLevel,
 				Usage:   "Set log level: one of [error|warn|info|debug]",
 				EnvVars: []string{"SWAPD_LOG_LEVEL"},
 				Value:   "info",
 			},
+			&cli.StringFlag{
+				Name:    flagLogFormat,
+				Usage:   fmt.Sprintf("Set log format: one of [%s|%s]", cliutil.LogFormatText, cliutil.LogFormatJSON),
+				EnvVars: []string{"SWAPD_LOG_FORMAT"},
+				Value:   cliutil.LogFormatText,
+			},
 			&cli.BoolFlag{
 				Name:  flagUseExternalSigner,
 				Usage: "Use external signer, for usage with the swap UI",
@@ -208,11 +457,54 @@ func cliApp() *cli.App {
 			&cli.BoolFlag{
 				Name: flagRelayer,
 				Usage: fmt.Sprintf(
-					"Relay claims for XMR makers and earn %s ETH (minus gas fees) per transaction",
+					"Relay claims for XMR makers and earn the relayer fee, %s ETH by default, "+
+						"(minus gas fees) per transaction",
 					coins.RelayerFeeETH.Text('f'),
 				),
 				Value: false,
 			},
+			&cli.StringFlag{
+				Name:  flagRelayerFee,
+				Usage: fmt.Sprintf("Fee (in ETH) to charge per relayed claim with --%s", flagRelayer),
+				Value: coins.RelayerFeeETH.Text('f'),
+			},
+			&cli.UintFlag{
+				Name:  flagMaxConcurrentRelays,
+				Usage: "Maximum number of claims relayed at a time (0 for no limit)",
+				Value: defaultMaxConcurrentRelays,
+			},
+			&cli.DurationFlag{
+				Name: flagRelayQueueTimeout,
+				Usage: fmt.Sprintf("Time a claim request waits when --%s claims are being relayed, "+
+					"before it is rejected as busy", flagMaxConcurrentRelays),
+				Value: defaultRelayQueueTimeout,
+			},
+			&cli.DurationFlag{
+				Name:  flagRateSampleInterval,
+				Usage: "How often to sample and store the suggested exchange rate for its history (0 to disable)",
+			},
+			&cli.DurationFlag{
+				Name:  flagRateCacheTTL,
+				Usage: "How long the suggested exchange rate is cached before the price feeds are read again (0 to disable)",
+				Value: defaultRateCacheTTL,
+			},
+			&cli.StringSliceFlag{
+				Name: flagPriceProviders,
+				Usage: fmt.Sprintf("Comma separated sources of the suggested exchange rate, tried in order: %q, %q "+
+					"or the http(s) URL of an Ethereum mainnet endpoint to read the Chainlink feeds from",
+					pricefeed.ChainlinkProviderName, pricefeed.CoinGeckoProviderName),
+				Value: cli.NewStringSlice(pricefeed.ChainlinkProviderName),
+			},
+			&cli.DurationFlag{
+				Name:  flagMaxPriceAge,
+				Usage: "Age beyond which a price provider's prices are stale and the next provider is tried (0 for no limit)",
+				Value: defaultMaxPriceAge,
+			},
+			&cli.BoolFlag{
+				Name: flagObserver,
+				Usage: "Run without a Monero wallet or Ethereum key, only discovering and querying peers " +
+					"and serving the suggested exchange rate",
+			},
 			&cli.StringFlag{
 				Name:   flagProfile,
 				Usage:  "BIND_IP:PORT to provide profiling information on",
@@ -240,6 +532,10 @@ func runDaemon(c *cli.Context) error {
 		return fmt.Errorf("unknown command %q", c.Args().First())
 	}
 
+	if err := cliutil.SetLogFormat(c.String(flagLogFormat)); err != nil {
+		return err
+	}
+
 	if err := cliutil.SetLogLevelsFromContext(c); err != nil {
 		return err
 	}
@@ -259,14 +555,39 @@ func runDaemon(c *cli.Context) error {
 		return err
 	}
 
-	mc, err := createMoneroClient(c, envConf)
-	if err != nil {
-		return err
+	// the lock is taken before the Monero wallet is opened, as two instances
+	// using the same wallet files can corrupt them, not just the database
+	if !c.Bool(flagNoDataDirLock) {
+		unlock, err := common.LockDataDir(envConf.DataDir) //nolint:govet
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := unlock(); err != nil {
+				log.Warnf("failed to unlock the data directory: %s", err)
+			}
+		}()
 	}
-	defer mc.Close()
 
-	if err = maybeBackgroundMine(c.Context, devXMRMaker, mc.PrimaryAddress()); err != nil {
-		return err
+	observer := c.Bool(flagObserver)
+	if observer {
+		if err = checkObserverFlags(c); err != nil {
+			return err
+		}
+	}
+
+	// observers have no wallet, so the Monero client stays nil
+	var mc monero.WalletClient
+	if !observer {
+		mc, err = createMoneroClient(c, envConf)
+		if err != nil {
+			return err
+		}
+		defer mc.Close()
+
+		if err = maybeBackgroundMine(c.Context, devXMRMaker, mc.PrimaryAddress()); err != nil {
+			return err
+		}
 	}
 
 	ec, err := createEthClient(c, envConf)
@@ -294,6 +615,17 @@ func runDaemon(c *cli.Context) error {
 
 // getEnvConfig returns the environment specific config, adjusting all values changed by
 // command line options.
+// gweiFlagToWei returns the value of the gwei amount flag in wei, or nil if
+// the flag is not set.
+func gweiFlagToWei(c *cli.Context, flagName string) *coins.WeiAmount {
+	if !c.IsSet(flagName) {
+		return nil
+	}
+
+	wei := new(big.Int).SetUint64(uint64(c.Uint(flagName)))
+	return coins.NewWeiAmount(wei.Mul(wei, big.NewInt(1e9)))
+}
+
 func getEnvConfig(c *cli.Context, devXMRMaker bool, devXMRTaker bool) (*common.Config, error) {
 	if c.IsSet(flagEnv) {
 		if c.String(flagEnv) != common.Development.String() && (devXMRMaker || devXMRTaker) {
@@ -438,6 +770,10 @@ func createMoneroClient(c *cli.Context, envConf *common.Config) (monero.WalletCl
 		MoneroWalletRPCPath: "", // look for it in "./monero-bin/monero-wallet-rpc" and then the user's path
 		WalletPassword:      c.String(flagMoneroWalletPassword),
 		WalletPort:          c.Uint(flagMoneroWalletPort),
+		RetryPolicy: monero.RetryPolicy{
+			MaxRetries: c.Uint(flagWalletMaxRetries),
+			BaseDelay:  c.Duration(flagWalletRetryDelay),
+		},
 	})
 }
 
@@ -456,7 +792,8 @@ func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthCl
 		return nil, errFlagsMutuallyExclusive(flagUseExternalSigner, flagEthPrivKey)
 	}
 
-	if !useExternalSigner {
+	// observers only read from the chain, so they don't need a key
+	if !useExternalSigner && !c.Bool(flagObserver) {
 		ethPrivKeyFile := envConf.EthKeyFileName()
 		if c.IsSet(flagEthPrivKey) {
 			ethPrivKeyFile = c.String(flagEthPrivKey)
@@ -478,7 +815,8 @@ func createEthClient(c *cli.Context, envConf *common.Config) (extethclient.EthCl
 		}
 	}
 
-	extendedEC, err := extethclient.NewEthClient(c.Context, env, ethEndpoint, ethPrivKey)
+	ethEndpoints := append([]string{ethEndpoint}, c.StringSlice(flagEthFailoverEndpoints)...)
+	extendedEC, err := extethclient.NewFailoverEthClient(c.Context, env, ethEndpoints, ethPrivKey)
 	if err != nil {
 		return nil, err
 	}
@@ -505,6 +843,20 @@ func createSwapdConf(
 		}
 	}
 
+	// an empty token would silently leave the RPC server unauthenticated
+	if c.IsSet(flagAuthToken) && c.String(flagAuthToken) == "" {
+		return nil, errFlagValueEmpty(flagAuthToken)
+	}
+
+	var allowedOrigins []string
+	if c.IsSet(flagAllowedOrigins) {
+		var err error
+		allowedOrigins, err = parseAllowedOrigins(c.StringSlice(flagAllowedOrigins))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", flagAllowedOrigins, err)
+		}
+	}
+
 	libp2pPort := c.Uint(flagLibp2pPort)
 	if !c.IsSet(flagLibp2pPort) {
 		switch {
@@ -525,13 +877,106 @@ func createSwapdConf(
 		}
 	}
 
+	var minETHBalance *coins.WeiAmount
+	if c.IsSet(flagMinETHBalance) {
+		minBal, err := cliutil.ReadUnsignedDecimalFlag(c, flagMinETHBalance)
+		if err != nil {
+			return nil, err
+		}
+		minETHBalance = coins.EtherToWei(minBal)
+	}
+
+	var relayerFee *coins.WeiAmount
+	if c.IsSet(flagRelayerFee) {
+		if !c.Bool(flagRelayer) {
+			return nil, fmt.Errorf("--%s requires --%s", flagRelayerFee, flagRelayer)
+		}
+		fee, err := cliutil.ReadUnsignedDecimalFlag(c, flagRelayerFee)
+		if err != nil {
+			return nil, err
+		}
+		relayerFee = coins.EtherToWei(fee)
+	}
+
+	xmrLockDecimals := c.Uint(flagXMRLockDecimals)
+	if xmrLockDecimals == 0 || xmrLockDecimals > coins.NumMoneroDecimals {
+		return nil, fmt.Errorf("--%s must be between 1 and %d", flagXMRLockDecimals, coins.NumMoneroDecimals)
+	}
+
+	if c.Duration(flagRateSampleInterval) < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagRateSampleInterval)
+	}
+
+	var priceProviders []pricefeed.Provider
+	for _, name := range c.StringSlice(flagPriceProviders) {
+		provider, err := pricefeed.NewProvider(name, ec.Raw())
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", flagPriceProviders, err)
+		}
+		priceProviders = append(priceProviders, provider)
+	}
+
+	var xmrPayoutAddr *mcrypto.Address
+	if c.IsSet(flagXMRPayoutAddress) {
+		var err error
+		xmrPayoutAddr, err = mcrypto.NewAddress(c.String(flagXMRPayoutAddress), envConf.Env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", flagXMRPayoutAddress, err)
+		}
+	}
+
+	if c.IsSet(flagGasPrice) && (c.IsSet(flagMaxFeePerGas) || c.IsSet(flagMaxPriorityFeePerGas)) {
+		return nil, fmt.Errorf("--%s cannot be used with --%s or --%s",
+			flagGasPrice, flagMaxFeePerGas, flagMaxPriorityFeePerGas)
+	}
+
 	return &daemon.SwapdConfig{
-		EnvConf:        envConf,
-		Libp2pPort:     uint16(libp2pPort),
-		Libp2pKeyfile:  libp2pKeyFile,
-		RPCPort:        uint16(rpcPort),
-		IsRelayer:      c.Bool(flagRelayer),
-		NoTransferBack: c.Bool(flagNoTransferBack),
+		EnvConf:              envConf,
+		Libp2pPort:           uint16(libp2pPort),
+		Libp2pKeyfile:        libp2pKeyFile,
+		RPCPort:              uint16(rpcPort),
+		IsRelayer:            c.Bool(flagRelayer),
+		IsObserver:           c.Bool(flagObserver),
+		RelayerFee:           relayerFee,
+		MaxConcurrentRelays:  uint32(c.Uint(flagMaxConcurrentRelays)),
+		RelayQueueTimeout:    c.Duration(flagRelayQueueTimeout),
+		RateSampleInterval:   c.Duration(flagRateSampleInterval),
+		RateCacheTTL:         c.Duration(flagRateCacheTTL),
+		PriceProviders:       priceProviders,
+		MaxPriceAge:          c.Duration(flagMaxPriceAge),
+		NoTransferBack:       c.Bool(flagNoTransferBack),
+		AutoClearOffers:      c.Bool(flagAutoClearOffers),
+		MoneroConfirmations:  c.Uint64(flagXMRConfirmations),
+		EthConfirmations:     c.Uint64(flagEthConfirmations),
+		MaxSwapDuration:      c.Duration(flagMaxSwapDuration),
+		RelayClaimBuffer:     c.Duration(flagRelayClaimBuffer),
+		XMRLockDecimals:      uint8(xmrLockDecimals),
+		MaxTakesPerMinute:    c.Uint(flagMaxTakesPerMinute),
+		XMRPayoutAddress:     xmrPayoutAddr,
+		PersistOfferDefaults: c.Bool(flagPersistOfferDefaults),
+		EventSocketPath:      c.String(flagEventSocket),
+		WsCompression:        c.Bool(flagWsCompression),
+		MaxWsConnections:     uint32(c.Uint(flagMaxWsConnections)),
+		EnableMetrics:        c.Bool(flagMetrics),
+		RPCAuthToken:         c.String(flagAuthToken),
+		RPCAllowedOrigins:    allowedOrigins,
+		MinETHBalance:        minETHBalance,
+		MaxGasPrice:          gweiFlagToWei(c, flagMaxGasPrice),
+		ClearStuckTxs:        c.Bool(flagClearStuckTxs),
+		MaxFeePerGas:         gweiFlagToWei(c, flagMaxFeePerGas),
+		MaxPriorityFeePerGas: gweiFlagToWei(c, flagMaxPriorityFeePerGas),
+		EventChSize:          int(c.Uint(flagEventChSize)),
+		LogChSize:            int(c.Uint(flagLogChSize)),
+
+		MaxOngoingSwapsInMemory: int(c.Uint(flagMaxOngoingSwapsInMemory)),
+
+		MessagePolicy: net.MessagePolicy{
+			DropUnknown:      !c.Bool(flagAbortOnUnknownMessages),
+			IgnoreUnexpected: !c.Bool(flagAbortOnUnexpectedMessages),
+			MaxDropped:       c.Uint(flagMaxDroppedMessages),
+		},
+		MaxStreamResumeAttempts: c.Uint(flagMaxStreamResumeAttempts),
+
 		MoneroClient:   mc,
 		EthereumClient: ec,
 	}, nil
@@ -549,6 +994,55 @@ func maybeBackgroundMine(ctx context.Context, devXMRMaker bool, address *mcrypto
 	return nil
 }
 
+// parseAllowedOrigins returns the origins passed with the allowed origins flag,
+// which are comma separated if passing multiple to a single flag. Each origin
+// must be a scheme and host, with an optional port, as sent by browsers in the
+// Origin header, or "*" for all origins.
+func parseAllowedOrigins(flagValues []string) ([]string, error) {
+	var origins []string
+	for _, flagVal := range flagValues {
+		for _, origin := range strings.Split(flagVal, ",") {
+			origin = strings.ToLower(strings.TrimSpace(origin))
+			if origin == "" {
+				continue
+			}
+
+			if origin != rpc.AllOrigins {
+				u, err := url.Parse(origin)
+				if err != nil || u.Scheme == "" || u.Host == "" || u.Scheme+"://"+u.Host != origin {
+					return nil, fmt.Errorf("%q is not an origin, eg. http://localhost:3000", origin)
+				}
+			}
+
+			origins = append(origins, origin)
+		}
+	}
+
+	if len(origins) == 0 {
+		return nil, errors.New("no origins given")
+	}
+
+	return origins, nil
+}
+
+// checkObserverFlags returns an error if a flag that needs a wallet is set
+// together with the observer flag.
+func checkObserverFlags(c *cli.Context) error {
+	for _, flag := range []string{
+		flagRelayer,
+		flagDeploy,
+		flagDevXMRMaker,
+		flagDevXMRTaker,
+		flagUseExternalSigner,
+		flagEthPrivKey,
+	} {
+		if c.IsSet(flag) {
+			return errFlagsMutuallyExclusive(flagObserver, flag)
+		}
+	}
+	return nil
+}
+
 func errFlagsMutuallyExclusive(flag1, flag2 string) error {
 	return fmt.Errorf("flags %q and %q are mutually exclusive", flag1, flag2)
 }
@@ -294,7 +294,8 @@ func TestDaemon_PersistOffers(t *testing.T) {
 	}
 
 	// make an offer
-	client := rpcclient.NewClient(ctx1, rpcEndpoint)
+	client, err := rpcclient.NewClient(ctx1, rpcEndpoint, "")
+	require.NoError(t, err)
 	balance, err := client.Balances(new(rpctypes.BalancesRequest))
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, balance.PiconeroUnlockedBalance.Cmp(coins.MoneroToPiconero(one)), 0)
@@ -330,10 +331,28 @@ func TestDaemon_PersistOffers(t *testing.T) {
 
 	daemon.WaitForSwapdStart(t, rpcPort)
 
-	client = rpcclient.NewClient(ctx2, rpcEndpoint)
+	client, err = rpcclient.NewClient(ctx2, rpcEndpoint, "")
+	require.NoError(t, err)
 	resp, err := client.GetOffers()
 	require.NoError(t, err)
 	require.Equal(t, offerResp.PeerID, resp.PeerID)
 	require.Equal(t, 1, len(resp.Offers))
 	require.Equal(t, offerResp.OfferID, resp.Offers[0].ID)
 }
+
+func TestParseAllowedOrigins(t *testing.T) {
+	origins, err := parseAllowedOrigins([]string{"http://localhost:3000, https://Swap.example.org", "*"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://localhost:3000", "https://swap.example.org", "*"}, origins)
+
+	for _, invalid := range []string{
+		"localhost:3000",
+		"http://localhost:3000/",
+		"https://swap.example.org/app",
+		"https://user@swap.example.org",
+		",",
+	} {
+		_, err = parseAllowedOrigins([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
@@ -95,6 +95,33 @@ func (r *ExchangeRate) ToERC20Amount(xmrAmount *apd.Decimal, token *ERC20TokenIn
 	return NewERC20TokenAmountFromDecimals(erc20Amount, token).AsStandard(), nil
 }
 
+// PercentDiff returns the absolute difference between the exchange rate and the
+// reference rate, expressed as a percentage of the reference rate.
+func (r *ExchangeRate) PercentDiff(reference *ExchangeRate) (*apd.Decimal, error) {
+	diff := new(apd.Decimal)
+	if _, err := decimalCtx.Sub(diff, r.Decimal(), reference.Decimal()); err != nil {
+		return nil, err
+	}
+	diff.Abs(diff)
+
+	if _, err := decimalCtx.Quo(diff, diff, reference.Decimal()); err != nil {
+		return nil, err
+	}
+
+	if _, err := decimalCtx.Mul(diff, diff, apd.New(100, 0)); err != nil {
+		return nil, err
+	}
+
+	diff.Reduce(diff)
+	return diff, nil
+}
+
+// Cmp compares the exchange rate with another exchange rate, returning -1 if it
+// is lower, 0 if they are equal, and 1 if it is higher.
+func (r *ExchangeRate) Cmp(other *ExchangeRate) int {
+	return r.Decimal().Cmp(other.Decimal())
+}
+
 func (r *ExchangeRate) String() string {
 	return r.Decimal().Text('f')
 }
@@ -122,3 +122,25 @@ func TestCalcExchangeRate_fail(t *testing.T) {
 	_, err := CalcExchangeRate(xmrPrice, ethPrice)
 	require.ErrorContains(t, err, "division by zero")
 }
+
+func TestExchangeRate_PercentDiff(t *testing.T) {
+	market := ToExchangeRate(StrToDecimal("0.05"))
+
+	diff, err := ToExchangeRate(StrToDecimal("0.06")).PercentDiff(market)
+	require.NoError(t, err)
+	assert.Equal(t, "20", diff.Text('f'))
+
+	diff, err = ToExchangeRate(StrToDecimal("0.045")).PercentDiff(market)
+	require.NoError(t, err)
+	assert.Equal(t, "10", diff.Text('f'))
+
+	_, err = market.PercentDiff(ToExchangeRate(StrToDecimal("0")))
+	require.ErrorContains(t, err, "division by zero")
+}
+
+func TestExchangeRate_Cmp(t *testing.T) {
+	rate := ToExchangeRate(StrToDecimal("0.05"))
+	assert.Equal(t, -1, rate.Cmp(ToExchangeRate(StrToDecimal("0.06"))))
+	assert.Equal(t, 0, rate.Cmp(ToExchangeRate(StrToDecimal("0.050"))))
+	assert.Equal(t, 1, rate.Cmp(ToExchangeRate(StrToDecimal("0.045"))))
+}
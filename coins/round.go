@@ -20,3 +20,23 @@ func roundToDecimalPlace(result *apd.Decimal, n *apd.Decimal, decimalPlace uint8
 	_, _ = result.Reduce(result)
 	return nil
 }
+
+// RoundUpToDecimalPlace rounds n up to the given decimal place, storing the
+// value in result. The result is the smallest value with at most decimalPlace
+// decimals that is not less than n, so n is unchanged if it has no more
+// decimals than that.
+func RoundUpToDecimalPlace(result *apd.Decimal, n *apd.Decimal, decimalPlace uint8) error {
+	result.Set(n)
+
+	ctx := DecimalCtx()
+	ctx.Rounding = apd.RoundCeiling
+
+	increaseExponent(result, decimalPlace)
+	_, err := ctx.RoundToIntegralValue(result, result)
+	if err != nil {
+		return err
+	}
+	decreaseExponent(result, decimalPlace)
+	_, _ = result.Reduce(result)
+	return nil
+}
@@ -32,3 +32,36 @@ func Test_roundToDecimalPlace(t *testing.T) {
 	assert.Equal(t, "0.0001", res.String())
 	assert.Equal(t, "0.00009", amt.String()) // input value unchanged
 }
+
+func TestRoundUpToDecimalPlace(t *testing.T) {
+	// Any extra precision rounds up
+	amt := StrToDecimal("1.000000000001")
+	err := RoundUpToDecimalPlace(amt, amt, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0001", amt.String())
+
+	// Values below half round up too
+	amt = StrToDecimal("0.123412345678")
+	res := new(apd.Decimal)
+	err = RoundUpToDecimalPlace(res, amt, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1235", res.String())
+	assert.Equal(t, "0.123412345678", amt.String()) // input value unchanged
+
+	// Values with no more decimals than the rounding place are unchanged
+	amt = StrToDecimal("0.1234")
+	err = RoundUpToDecimalPlace(res, amt, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1234", res.String())
+
+	amt = StrToDecimal("7")
+	err = RoundUpToDecimalPlace(res, amt, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "7", res.String())
+
+	// Rounding at the last piconero decimal is a no-op
+	amt = StrToDecimal("0.123456789012")
+	err = RoundUpToDecimalPlace(res, amt, NumMoneroDecimals)
+	require.NoError(t, err)
+	assert.Equal(t, "0.123456789012", res.String())
+}
@@ -4,6 +4,7 @@
 package common
 
 import (
+	"math"
 	"math/big"
 	"os"
 	"path"
@@ -175,6 +176,20 @@ func SwapTimeoutFromEnv(env Environment) time.Duration {
 	}
 }
 
+// SwapTimeoutBoundsFromEnv returns the shortest and longest durations that XMR
+// makers accept for each of the two swap timeout periods: between the swap
+// being initiated on-chain and t0, and between t0 and t1. Outside of
+// development, they are half and four times the default swap timeout.
+// Development has no bounds, as tests often set very short timeouts.
+func SwapTimeoutBoundsFromEnv(env Environment) (min time.Duration, max time.Duration) {
+	if env == Development {
+		return 0, math.MaxInt64
+	}
+
+	timeout := SwapTimeoutFromEnv(env)
+	return timeout / 2, timeout * 4
+}
+
 // DefaultMoneroPortFromEnv returns the default Monerod RPC port for an environment
 // Reference: https://monerodocs.org/interacting/monerod-reference/
 func DefaultMoneroPortFromEnv(env Environment) uint {
@@ -0,0 +1,40 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/gofrs/flock"
+)
+
+// DataDirLockFileName is the name of the file in the data directory that is
+// locked while a swapd instance is using the directory.
+const DataDirLockFileName = "swapd.lock"
+
+// ErrDataDirLocked is returned by LockDataDir when another process holds the
+// lock on the data directory.
+var ErrDataDirLocked = errors.New("data directory is in use by another swapd instance")
+
+// LockDataDir acquires an exclusive lock on the data directory, so that two
+// swapd instances can't use the same database and Monero wallet files. It fails
+// immediately with ErrDataDirLocked if another process holds the lock. The
+// returned function releases the lock. The lock is also released by the OS when
+// the process exits, so a crashed instance doesn't leave the directory locked.
+func LockDataDir(dataDir string) (unlock func() error, err error) {
+	lockFile := path.Join(dataDir, DataDirLockFileName)
+	fileLock := flock.New(lockFile)
+
+	locked, err := fileLock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", lockFile, err)
+	}
+	if !locked {
+		return nil, fmt.Errorf("%w: %s is locked", ErrDataDirLocked, lockFile)
+	}
+
+	return fileLock.Unlock, nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockDataDir(t *testing.T) {
+	dataDir := t.TempDir()
+
+	unlock, err := LockDataDir(dataDir)
+	require.NoError(t, err)
+
+	_, err = LockDataDir(dataDir)
+	require.ErrorIs(t, err, ErrDataDirLocked)
+
+	require.NoError(t, unlock())
+
+	unlock, err = LockDataDir(dataDir)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
@@ -6,6 +6,8 @@
 package rpctypes
 
 import (
+	"time"
+
 	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -17,13 +19,16 @@ import (
 
 // JSON RPC method names that we serve on the localhost server
 const (
-	NetDiscover         = "net_discover"
-	NetQueryPeer        = "net_queryPeer"
-	SubscribeNewPeer    = "net_subscribeNewPeer"
-	SubscribeMakeOffer  = "net_makeOfferAndSubscribe"
-	SubscribeTakeOffer  = "net_takeOfferAndSubscribe"
-	SubscribeSwapStatus = "swap_subscribeStatus"
-	SubscribeSigner     = "signer_subscribe"
+	NetDiscover          = "net_discover"
+	NetQueryPeer         = "net_queryPeer"
+	SubscribeNewPeer     = "net_subscribeNewPeer"
+	SubscribeMakeOffer   = "net_makeOfferAndSubscribe"
+	SubscribeTakeOffer   = "net_takeOfferAndSubscribe"
+	SubscribeOffersTaken = "net_subscribeOffersTaken"
+	SubscribeSwapStatus  = "swap_subscribeStatus"
+	SubscribeAllStatus   = "swap_subscribeAllStatus"
+	SubscribeSigner      = "signer_subscribe"
+	SubscribeBalances    = "personal_subscribeBalances"
 )
 
 // SubscribeSwapStatusRequest ...
@@ -36,6 +41,15 @@ type SubscribeSwapStatusResponse struct {
 	Status types.Status `json:"status" validate:"required"`
 }
 
+// SwapStatusEvent is emitted to event socket consumers and swap_subscribeAllStatus
+// subscribers each time the status of a swap changes. It is the same as
+// SubscribeSwapStatusResponse, with the addition of the swap's offer ID, as events
+// for all swaps are emitted.
+type SwapStatusEvent struct {
+	OfferID types.Hash   `json:"offerID" validate:"required"`
+	Status  types.Status `json:"status" validate:"required"`
+}
+
 // DiscoverRequest ...
 type DiscoverRequest struct {
 	Provides   string `json:"provides"`
@@ -53,6 +67,12 @@ type QueryPeerRequest struct {
 	PeerID peer.ID `json:"peerID" validate:"required"`
 }
 
+// QueryPeerAddrRequest ...
+type QueryPeerAddrRequest struct {
+	// Full multiaddr of the peer to query, including its peer ID
+	Multiaddr string `json:"multiaddr" validate:"required"`
+}
+
 // QueryPeerResponse ...
 type QueryPeerResponse struct {
 	Offers []*types.Offer `json:"offers" validate:"dive,required"`
@@ -72,11 +92,53 @@ type QueryAllResponse struct {
 	PeersWithOffers []*PeerWithOffers `json:"peersWithOffers" validate:"dive,required"`
 }
 
+// QueryRelayersRequest ...
+type QueryRelayersRequest struct {
+	SearchTime uint64 `json:"searchTime"` // in seconds
+}
+
+// RelayerWithFee ...
+type RelayerWithFee struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+	// Fee is the relayer's fee in ETH. It is not set if the relayer did not
+	// answer the query, which relayers that predate fee queries don't, in
+	// which case they charge coins.RelayerFeeETH.
+	Fee *apd.Decimal `json:"fee,omitempty"`
+}
+
+// QueryRelayersResponse ...
+type QueryRelayersResponse struct {
+	Relayers []*RelayerWithFee `json:"relayers" validate:"dive,required"`
+}
+
+// RelayerStatusRequest ...
+type RelayerStatusRequest struct {
+	SearchTime uint64 `json:"searchTime"` // in seconds
+}
+
+// RelayerStatusResponse ...
+type RelayerStatusResponse struct {
+	// Relayers are the discovered relayers, cheapest first, as returned by
+	// net_queryRelayers.
+	Relayers []*RelayerWithFee `json:"relayers" validate:"dive,required"`
+	// IsRelayer is true if our node relays claims for everyone. The remaining
+	// fields are only set if it is.
+	IsRelayer bool `json:"isRelayer"`
+	// Fee is the fee that our node charges per relayed claim, in ETH.
+	Fee *apd.Decimal `json:"fee,omitempty"`
+	// NumRelayed and TotalFees are the number of claims that our node relayed
+	// since it started and the sum of their fees, in ETH.
+	NumRelayed uint64       `json:"numRelayed,omitempty"`
+	TotalFees  *apd.Decimal `json:"totalFees,omitempty"`
+}
+
 // TakeOfferRequest ...
 type TakeOfferRequest struct {
 	PeerID         peer.ID      `json:"peerID" validate:"required"`
 	OfferID        types.Hash   `json:"offerID" validate:"required"`
 	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"` // eth asset amount
+	// MaxExchangeRate, if set, aborts the take if the offer's exchange rate is above it
+	MaxExchangeRate *coins.ExchangeRate `json:"maxExchangeRate,omitempty"`
 }
 
 // MakeOfferRequest ...
@@ -86,6 +148,18 @@ type MakeOfferRequest struct {
 	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
 	EthAsset     types.EthAsset      `json:"ethAsset,omitempty"`
 	UseRelayer   bool                `json:"useRelayer,omitempty"`
+	ExpiresIn    uint64              `json:"expiresIn,omitempty"` // seconds until the offer expires, 0 for never
+	// MinTakerReputation, if set, rejects takes from takers that do not meet it
+	MinTakerReputation *types.MinTakerReputation `json:"minTakerReputation,omitempty"`
+	// Persistent offers are renewed with the same terms after a successful swap
+	Persistent bool `json:"persistent,omitempty"`
+	// Repricing, if set, keeps the offer's exchange rate at a spread from the
+	// market rate, replacing the offer whenever the market rate changes
+	Repricing *types.Repricing `json:"repricing,omitempty"`
+	// IdempotencyKey, if set, makes retries of the request safe: a request
+	// with a key that was recently used returns the offer made for it instead
+	// of making a new offer
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 // MakeOfferResponse ...
@@ -94,6 +168,23 @@ type MakeOfferResponse struct {
 	OfferID types.Hash `json:"offerID" validate:"required"`
 }
 
+// ValidateOfferResponse contains the amounts of an offer that passed the same
+// checks as net_makeOffer, without the offer being published.
+type ValidateOfferResponse = types.OfferSummary
+
+// GetOfferDefaultsRequest ...
+type GetOfferDefaultsRequest struct {
+	EthAsset types.EthAsset `json:"ethAsset,omitempty"`
+}
+
+// GetOfferDefaultsResponse contains the values of the most recent offer made
+// for an asset.
+type GetOfferDefaultsResponse struct {
+	MinAmount    *apd.Decimal        `json:"minAmount" validate:"required"`
+	MaxAmount    *apd.Decimal        `json:"maxAmount" validate:"required"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+}
+
 // SignerRequest initiates the signer_subscribe handler from the front-end
 type SignerRequest struct {
 	OfferID    types.Hash        `json:"offerID" validate:"required"`
@@ -107,6 +198,14 @@ type SignerResponse struct {
 	To      ethcommon.Address `json:"to" validate:"required"`
 	Data    []byte            `json:"data" validate:"required"`
 	Value   *apd.Decimal      `json:"value" validate:"required"` // In ETH (or other ETH asset) not WEI
+	// GasPrice and MaxGasPrice are only set if swapd has a maximum gas price.
+	// GasPrice is the suggested gas price that was checked against the maximum.
+	GasPrice    *coins.WeiAmount `json:"gasPrice,omitempty"`
+	MaxGasPrice *coins.WeiAmount `json:"maxGasPrice,omitempty"`
+	// MaxFeePerGas and MaxPriorityFeePerGas are only set if swapd is
+	// configured to use EIP-1559 fees.
+	MaxFeePerGas         *coins.WeiAmount `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *coins.WeiAmount `json:"maxPriorityFeePerGas,omitempty"`
 }
 
 // SignerTxSigned is a response from the front-end saying the given tx has been submitted successfully
@@ -118,6 +217,9 @@ type SignerTxSigned struct {
 // TokenInfoRequest is used to request lookup of the token's metadata.
 type TokenInfoRequest struct {
 	TokenAddr ethcommon.Address `json:"tokenAddr" validate:"required"`
+	// Refresh re-reads the token's metadata from the chain, replacing the
+	// cached metadata
+	Refresh bool `json:"refresh,omitempty"`
 }
 
 // TokenInfoResponse contains the metadata for the requested token
@@ -150,3 +252,50 @@ type AddressesResponse struct {
 type PeersResponse struct {
 	Addrs []string `json:"addresses" validate:"dive,required"`
 }
+
+// ProtocolStream is an open swap protocol stream with a peer.
+type ProtocolStream struct {
+	PeerID  peer.ID    `json:"peerID" validate:"required"`
+	OfferID types.Hash `json:"offerID" validate:"required"`
+	// IsTaker is true if we opened the stream to take the peer's offer
+	IsTaker  bool      `json:"isTaker"`
+	OpenedAt time.Time `json:"openedAt" validate:"required"`
+	// OpenFor is how long the stream has been open, in seconds
+	OpenFor uint64 `json:"openFor"`
+}
+
+// GetProtocolStreamsResponse ...
+type GetProtocolStreamsResponse struct {
+	Streams []*ProtocolStream `json:"streams" validate:"dive,required"`
+	// Truncated is true if there were more open streams than returned
+	Truncated bool `json:"truncated"`
+}
+
+// PeerStatsRequest ...
+type PeerStatsRequest struct {
+	// PeerID selects the peer to return the stats of, or all peers if not set
+	PeerID peer.ID `json:"peerID,omitempty"`
+}
+
+// PeerStats holds the counts of the outcomes of our completed swaps with a peer.
+type PeerStats struct {
+	PeerID       peer.ID `json:"peerID" validate:"required"`
+	NumSucceeded uint64  `json:"numSucceeded"`
+	NumRefunded  uint64  `json:"numRefunded"`
+	NumAborted   uint64  `json:"numAborted"`
+}
+
+// PeerStatsResponse ...
+type PeerStatsResponse struct {
+	Peers []*PeerStats `json:"peers" validate:"dive,required"`
+}
+
+// ReadvertiseResponse ...
+type ReadvertiseResponse struct {
+	NumOffers int `json:"numOffers"`
+}
+
+// BlockPeerRequest is used for both net_blockPeer and net_unblockPeer.
+type BlockPeerRequest struct {
+	PeerID peer.ID `json:"peerID" validate:"required"`
+}
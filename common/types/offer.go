@@ -9,9 +9,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -125,7 +127,9 @@ func (o *Offer) IsSet() bool {
 		o.ExchangeRate != nil
 }
 
-func (o *Offer) validate() error {
+// Validate checks that the offer's fields are set, that the amounts are within
+// the allowed range and that the offer ID matches the other fields.
+func (o *Offer) Validate() error {
 	if IsHashZero(o.ID) {
 		return errOfferIDNotSet
 	}
@@ -176,8 +180,116 @@ func (o *Offer) validate() error {
 
 // OfferExtra represents extra data that is passed when an offer is made.
 type OfferExtra struct {
-	StatusCh   chan Status `json:"-"`
-	UseRelayer bool        `json:"useRelayer,omitempty"`
+	StatusCh           chan Status         `json:"-"`
+	UseRelayer         bool                `json:"useRelayer,omitempty"`
+	ExpiresAt          *time.Time          `json:"expiresAt,omitempty"`          // nil if the offer does not expire
+	MinTakerReputation *MinTakerReputation `json:"minTakerReputation,omitempty"` // nil if any taker is accepted
+	// Persistent offers are replaced by a new offer with the same terms after
+	// a successful swap, instead of being deleted
+	Persistent bool `json:"persistent,omitempty"`
+	// Repricing, if set, keeps the offer's exchange rate at a spread from the
+	// market rate
+	Repricing *Repricing `json:"repricing,omitempty"`
+}
+
+// IsExpired returns true if the offer has an expiry time that is not after now.
+func (e *OfferExtra) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// MinTakerReputation is the reputation a taker must have for their take of an
+// offer to be accepted. A taker's reputation is the fraction of their completed
+// swaps with us that succeeded.
+type MinTakerReputation struct {
+	SuccessRate *apd.Decimal `json:"successRate" validate:"required"`
+	// RejectUnknown rejects takers that have not completed any swaps with us.
+	// Otherwise, they are treated as neutral and their takes are accepted.
+	RejectUnknown bool `json:"rejectUnknown,omitempty"`
+}
+
+// Validate returns an error if the success rate is not between 0 and 1.
+func (r *MinTakerReputation) Validate() error {
+	if r.SuccessRate == nil {
+		return errors.New("taker success rate must be set")
+	}
+
+	if r.SuccessRate.Sign() < 0 || r.SuccessRate.Cmp(apd.New(1, 0)) > 0 {
+		return fmt.Errorf("taker success rate of %s is not between 0 and 1", r.SuccessRate.Text('f'))
+	}
+
+	return nil
+}
+
+// Repricing configures an offer whose exchange rate tracks the market rate.
+// The offer is periodically replaced by an offer with an exchange rate of
+// Spread percent above the market rate, but never below FloorRate.
+type Repricing struct {
+	Spread    *apd.Decimal        `json:"spread" validate:"required"` // can be negative
+	FloorRate *coins.ExchangeRate `json:"floorRate" validate:"required"`
+}
+
+// Validate returns an error if the spread is not above -100 percent or the
+// floor rate is not set.
+func (r *Repricing) Validate() error {
+	if r.Spread == nil {
+		return errors.New("repricing spread must be set")
+	}
+
+	if r.Spread.Cmp(apd.New(-100, 0)) <= 0 {
+		return fmt.Errorf("repricing spread of %s%% is not above -100%%", r.Spread.Text('f'))
+	}
+
+	if r.FloorRate == nil {
+		return errors.New("repricing floor rate must be set")
+	}
+
+	return nil
+}
+
+// RepricedRate returns the exchange rate of a repriced offer given the market
+// rate: the market rate with the spread applied, rounded up to the maximum
+// number of exchange rate decimals, or the floor rate if that is higher.
+func (r *Repricing) RepricedRate(market *coins.ExchangeRate) (*coins.ExchangeRate, error) {
+	factor := new(apd.Decimal)
+	if _, err := coins.DecimalCtx().Quo(factor, r.Spread, apd.New(100, 0)); err != nil {
+		return nil, err
+	}
+	if _, err := coins.DecimalCtx().Add(factor, factor, apd.New(1, 0)); err != nil {
+		return nil, err
+	}
+
+	rate := new(apd.Decimal)
+	if _, err := coins.DecimalCtx().Mul(rate, market.Decimal(), factor); err != nil {
+		return nil, err
+	}
+	if err := coins.RoundUpToDecimalPlace(rate, rate, coins.MaxExchangeRateDecimals); err != nil {
+		return nil, err
+	}
+
+	if rate.Cmp(r.FloorRate.Decimal()) < 0 {
+		rate.Set(r.FloorRate.Decimal())
+	}
+
+	return coins.ToExchangeRate(rate), nil
+}
+
+// OfferSummary contains the amounts of an offer that was validated, but not
+// published.
+type OfferSummary struct {
+	TakerMinAmount  *apd.Decimal `json:"takerMinAmount" validate:"required"`  // ETH asset amount
+	TakerMaxAmount  *apd.Decimal `json:"takerMaxAmount" validate:"required"`  // ETH asset amount
+	UnlockedBalance *apd.Decimal `json:"unlockedBalance" validate:"required"` // XMR amount
+}
+
+// OfferTakenEvent is emitted when a taker's take of one of our offers is
+// accepted and the swap starts.
+type OfferTakenEvent struct {
+	OfferID        Hash         `json:"offerID" validate:"required"`
+	PeerID         peer.ID      `json:"peerID" validate:"required"` // the taker
+	EthAsset       EthAsset     `json:"ethAsset"`
+	ProvidedAmount *apd.Decimal `json:"providedAmount" validate:"required"` // in ETH or the ERC20 token
+	XMRAmount      *apd.Decimal `json:"xmrAmount" validate:"required"`      // the XMR we lock
+	Time           time.Time    `json:"time" validate:"required"`
 }
 
 // UnmarshalOffer deserializes a JSON offer, checking the version for compatibility before
@@ -211,7 +323,7 @@ func UnmarshalOffer(jsonData []byte) (*Offer, error) {
 
 // MarshalJSON provides JSON marshalling for the Offer type
 func (o *Offer) MarshalJSON() ([]byte, error) {
-	if err := o.validate(); err != nil {
+	if err := o.Validate(); err != nil {
 		return nil, err
 	}
 	// Do standard JSON marshal without recursion
@@ -226,5 +338,5 @@ func (o *Offer) UnmarshalJSON(data []byte) error {
 	if err := vjson.UnmarshalStruct(data, (*_Offer)(o)); err != nil {
 		return err
 	}
-	return o.validate()
+	return o.Validate()
 }
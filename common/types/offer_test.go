@@ -250,3 +250,32 @@ func TestUnmarshalOffer_VersionTooNew(t *testing.T) {
 	_, err := UnmarshalOffer([]byte(offerJSON))
 	require.ErrorContains(t, err, fmt.Sprintf("offer version %q not supported", unsupportedVersion))
 }
+
+func TestRepricing_RepricedRate(t *testing.T) {
+	r := &Repricing{
+		Spread:    coins.StrToDecimal("1.5"),
+		FloorRate: coins.StrToExchangeRate("0.05"),
+	}
+	require.NoError(t, r.Validate())
+
+	rate, err := r.RepricedRate(coins.StrToExchangeRate("0.06"))
+	require.NoError(t, err)
+	require.Equal(t, "0.0609", rate.String())
+
+	// rounded up to the maximum exchange rate decimals
+	rate, err = r.RepricedRate(coins.StrToExchangeRate("0.123457"))
+	require.NoError(t, err)
+	require.Equal(t, "0.125309", rate.String())
+
+	// never below the floor, which is not modified
+	rate, err = r.RepricedRate(coins.StrToExchangeRate("0.04"))
+	require.NoError(t, err)
+	require.Equal(t, "0.05", rate.String())
+	rate.Decimal().SetInt64(1)
+	require.Equal(t, "0.05", r.FloorRate.String())
+
+	r.Spread = coins.StrToDecimal("-100")
+	require.ErrorContains(t, r.Validate(), "is not above -100%")
+	r.Spread = nil
+	require.ErrorContains(t, r.Validate(), "spread must be set")
+}
@@ -150,13 +150,76 @@ func (s Status) Description() string {
 	}
 }
 
+// statusTransitions maps each known status to the statuses that a swap can move
+// to next, from the point of view of either the maker or the taker. Terminal
+// statuses map to an empty list.
+var statusTransitions = map[Status][]Status{
+	// the taker moves to ETHLocked once it locks its ETH, the maker
+	// moves to KeysExchanged once a taker accepts its offer
+	ExpectingKeys: {KeysExchanged, ETHLocked, CompletedAbort},
+	// the maker moves to XMRLocked once it locks its XMR
+	KeysExchanged: {XMRLocked, CompletedAbort},
+	// the taker's ETH is locked, so it can only exit by refunding, or by
+	// claiming the XMR if the maker claimed the ETH after timeout0
+	ETHLocked: {ContractReady, SweepingXMR, CompletedSuccess, CompletedRefund},
+	// the maker claims the ETH, or reclaims and sweeps its XMR if the taker
+	// refunds
+	XMRLocked: {SweepingXMR, CompletedSuccess, CompletedRefund},
+	// the taker claims and sweeps the XMR, or refunds after timeout1
+	ContractReady: {SweepingXMR, CompletedSuccess, CompletedRefund},
+	SweepingXMR:   {CompletedSuccess, CompletedRefund},
+
+	CompletedSuccess: {},
+	CompletedRefund:  {},
+	CompletedAbort:   {},
+}
+
+// ValidNextStatuses returns the statuses that a swap with this status can move
+// to next. It returns nil for terminal and unknown statuses.
+func (s Status) ValidNextStatuses() []Status {
+	next := statusTransitions[s]
+	if len(next) == 0 {
+		return nil
+	}
+
+	// copy, so callers can't modify the transition table
+	return append([]Status(nil), next...)
+}
+
+// IsValidTransition returns true if a swap with this status can move to the
+// next status.
+func (s Status) IsValidTransition(next Status) bool {
+	for _, status := range statusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal returns true if the status means the swap has completed, in which
+// case its status can no longer change.
+func (s Status) IsTerminal() bool {
+	next, ok := statusTransitions[s]
+	return ok && len(next) == 0
+}
+
 // IsOngoing returns true if the status means the swap has not completed
 func (s Status) IsOngoing() bool {
+	if s == UnknownStatus {
+		panic("swap should not have UnknownStatus")
+	}
+	return len(statusTransitions[s]) > 0
+}
+
+// IsCancellable returns true if a swap with this status can be safely exited
+// without forfeiting funds. This is the case before any value is locked, or
+// when the ETH has been locked but the contract has not been set to ready, as
+// the ETH-taker can still refund.
+func (s Status) IsCancellable() bool {
 	switch s {
-	case ExpectingKeys, KeysExchanged, ETHLocked, XMRLocked, ContractReady, SweepingXMR:
+	case ExpectingKeys, KeysExchanged, ETHLocked:
 		return true
-	case UnknownStatus:
-		panic("swap should not have UnknownStatus")
 	default:
 		return false
 	}
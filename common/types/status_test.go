@@ -46,3 +46,63 @@ func TestUnmarshalStatus_fail(t *testing.T) {
 	_, err = json.Marshal(s)
 	require.ErrorContains(t, err, `unknown status 255`)
 }
+
+func TestStatus_IsCancellable(t *testing.T) {
+	require.True(t, ExpectingKeys.IsCancellable())
+	require.True(t, KeysExchanged.IsCancellable())
+	require.True(t, ETHLocked.IsCancellable())
+	require.False(t, XMRLocked.IsCancellable())
+	require.False(t, ContractReady.IsCancellable())
+	require.False(t, SweepingXMR.IsCancellable())
+	require.False(t, CompletedSuccess.IsCancellable())
+	require.False(t, CompletedRefund.IsCancellable())
+	require.False(t, CompletedAbort.IsCancellable())
+}
+
+func TestStatus_ValidNextStatuses(t *testing.T) {
+	require.Equal(t, []Status{XMRLocked, CompletedAbort}, KeysExchanged.ValidNextStatuses())
+	require.Nil(t, CompletedSuccess.ValidNextStatuses())
+	require.Nil(t, UnknownStatus.ValidNextStatuses())
+
+	// the returned slice is a copy of the transition table
+	next := KeysExchanged.ValidNextStatuses()
+	next[0] = CompletedSuccess
+	require.Equal(t, XMRLocked, KeysExchanged.ValidNextStatuses()[0])
+}
+
+func TestStatus_transitions(t *testing.T) {
+	// the maker's successful path, and the path where the taker refunds
+	maker := []Status{KeysExchanged, XMRLocked, CompletedSuccess}
+	makerRefund := []Status{KeysExchanged, XMRLocked, SweepingXMR, CompletedRefund}
+	// the taker's successful path, and the path where it refunds
+	taker := []Status{ExpectingKeys, ETHLocked, ContractReady, SweepingXMR, CompletedSuccess}
+	takerRefund := []Status{ExpectingKeys, ETHLocked, CompletedRefund}
+	abort := []Status{ExpectingKeys, KeysExchanged, CompletedAbort}
+
+	for _, path := range [][]Status{maker, makerRefund, taker, takerRefund, abort} {
+		for i := 1; i < len(path); i++ {
+			require.True(t, path[i-1].IsValidTransition(path[i]), "%s -> %s", path[i-1], path[i])
+		}
+	}
+
+	// statuses can't move backwards, and funds can't be locked after an abort
+	require.False(t, XMRLocked.IsValidTransition(KeysExchanged))
+	require.False(t, ContractReady.IsValidTransition(ETHLocked))
+	require.False(t, ETHLocked.IsValidTransition(CompletedAbort))
+	require.False(t, XMRLocked.IsValidTransition(CompletedAbort))
+	require.False(t, CompletedAbort.IsValidTransition(ETHLocked))
+}
+
+func TestStatus_IsTerminal(t *testing.T) {
+	for s := ExpectingKeys; s <= CompletedAbort; s++ {
+		// every known status is either terminal or ongoing
+		require.NotEqual(t, s.IsTerminal(), s.IsOngoing(), s)
+		require.Equal(t, s.IsTerminal(), len(s.ValidNextStatuses()) == 0, s)
+	}
+
+	require.True(t, CompletedSuccess.IsTerminal())
+	require.True(t, CompletedRefund.IsTerminal())
+	require.True(t, CompletedAbort.IsTerminal())
+	require.False(t, SweepingXMR.IsTerminal())
+	require.False(t, UnknownStatus.IsTerminal())
+}
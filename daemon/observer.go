@@ -0,0 +1,84 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// runObserver runs swapd in observer mode, blocking until it is shut down.
+// There is no wallet, database or swap backend, so the node can discover and
+// query peers and serve the suggested exchange rate, but not make or take
+// offers.
+func runObserver(ctx context.Context, conf *SwapdConfig) (err error) {
+	// The host doesn't answer queries or swap requests, as a bootnode, but
+	// unlike a bootnode, its RPC server lets us discover and query peers.
+	host, err := net.NewHost(&net.Config{
+		Ctx:            ctx,
+		DataDir:        conf.EnvConf.DataDir,
+		Port:           conf.Libp2pPort,
+		KeyFile:        conf.Libp2pKeyfile,
+		Bootnodes:      conf.EnvConf.Bootnodes,
+		ProtocolID:     fmt.Sprintf("%s/%d", net.ProtocolID, conf.EthereumClient.ChainID().Int64()),
+		ListenIP:       hostListenIP(conf.EnvConf.Env),
+		IsBootnodeOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if hostErr := host.Stop(); hostErr != nil {
+			err = multierror.Append(err, fmt.Errorf("error shutting down peer-to-peer services: %w", hostErr))
+		}
+	}()
+
+	if err = host.Start(); err != nil {
+		return err
+	}
+
+	rpcServer, err := rpc.NewServer(&rpc.Config{
+		Ctx:     ctx,
+		Address: fmt.Sprintf("127.0.0.1:%d", conf.RPCPort),
+		Net:     host,
+		Namespaces: map[string]struct{}{
+			rpc.DaemonNamespace: {},
+			rpc.NetNamespace:    {},
+			rpc.SwapNamespace:   {},
+		},
+		WsCompression:    conf.WsCompression,
+		MaxWsConnections: conf.MaxWsConnections,
+		EnableMetrics:    conf.EnableMetrics,
+		AuthToken:        conf.RPCAuthToken,
+		AllowedOrigins:   conf.RPCAllowedOrigins,
+		RateCacheTTL:     conf.RateCacheTTL,
+		PriceProviders:   conf.PriceProviders,
+		MaxPriceAge:      conf.MaxPriceAge,
+		IsObserver:       true,
+		ETHClient:        conf.EthereumClient,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("starting swapd in observer mode with data-dir %s", conf.EnvConf.DataDir)
+	err = rpcServer.Start()
+
+	if errors.Is(err, http.ErrServerClosed) {
+		// Remove the error for a clean program exit, as ErrServerClosed only
+		// happens when the server is told to shut down
+		err = nil
+	}
+
+	// err can get set in defer blocks, so return err or use an empty
+	// return statement below (not nil)
+	return err
+}
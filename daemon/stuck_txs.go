@@ -0,0 +1,50 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+)
+
+// checkStuckTxs detects transactions from our account that were sent before
+// swapd was restarted and are still not mined, which leave a nonce gap that
+// any new transaction queues behind. If clear is set, they are replaced by
+// zero-value transfers to ourselves, otherwise they are only logged.
+func checkStuckTxs(ctx context.Context, ec extethclient.EthClient, clear bool, maxGasPrice *coins.WeiAmount) error {
+	if !ec.HasPrivateKey() {
+		// the external signer manages its own transactions
+		return nil
+	}
+
+	latest, pending, err := extethclient.PendingNonces(ctx, ec)
+	if err != nil {
+		return fmt.Errorf("failed to check for stuck transactions: %w", err)
+	}
+
+	if pending <= latest {
+		return nil
+	}
+
+	if !clear {
+		log.Warnf("found %d pending transactions from %s that are not mined (nonces %d to %d), "+
+			"new transactions will not be mined until they are; restart with --clear-stuck-txs to replace them",
+			pending-latest, ec.Address(), latest, pending-1)
+		return nil
+	}
+
+	log.Warnf("replacing %d pending transactions from %s that are not mined (nonces %d to %d)",
+		pending-latest, ec.Address(), latest, pending-1)
+
+	var maxPrice *big.Int
+	if maxGasPrice != nil {
+		maxPrice = maxGasPrice.BigInt()
+	}
+
+	return extethclient.ClearStuckTxs(ctx, ec, maxPrice)
+}
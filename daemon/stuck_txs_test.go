@@ -0,0 +1,27 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/tests"
+)
+
+func TestCheckStuckTxs_noneStuck(t *testing.T) {
+	ctx := context.Background()
+	ec := extethclient.CreateTestClient(t, tests.GetTestKeyByIndex(t, 0))
+
+	// ganache mines transactions as they are sent, so there is no nonce gap
+	latest, pending, err := extethclient.PendingNonces(ctx, ec)
+	require.NoError(t, err)
+	require.Equal(t, latest, pending)
+
+	require.NoError(t, checkStuckTxs(ctx, ec, false, nil))
+	require.NoError(t, checkStuckTxs(ctx, ec, true, nil))
+}
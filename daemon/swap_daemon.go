@@ -11,17 +11,21 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/ChainSafe/chaindb"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/hashicorp/go-multierror"
 	logging "github.com/ipfs/go-log"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker"
@@ -41,6 +45,134 @@ type SwapdConfig struct {
 	RPCPort        uint16
 	IsRelayer      bool
 	NoTransferBack bool
+
+	// IsObserver runs swapd without a Monero wallet or an Ethereum key. Only
+	// network queries and the suggested exchange rate are served, and the
+	// MoneroClient is not used.
+	IsObserver bool
+
+	// RelayerFee, if set, is the fee that we charge for relaying claims when
+	// IsRelayer is set, instead of coins.RelayerFeeWei.
+	RelayerFee *coins.WeiAmount
+
+	// MaxConcurrentRelays, if non-zero, is the number of claims that we relay
+	// at a time. Further claim requests wait up to RelayQueueTimeout before
+	// being rejected.
+	MaxConcurrentRelays uint32
+	RelayQueueTimeout   time.Duration
+
+	// RateSampleInterval, if non-zero, is how often the suggested exchange
+	// rate is sampled and stored, for swap_rateHistory.
+	RateSampleInterval time.Duration
+
+	// RateCacheTTL is how long the suggested exchange rate is cached. If zero,
+	// the price feeds are read on every request.
+	RateCacheTTL time.Duration
+
+	// PriceProviders are the sources of the suggested exchange rate, in the
+	// order they are tried. Providers with prices older than MaxPriceAge are
+	// skipped, unless it is zero.
+	PriceProviders []pricefeed.Provider
+	MaxPriceAge    time.Duration
+
+	// AutoClearOffers withdraws offers that our unlocked XMR balance can no
+	// longer cover and restores them once the balance recovers.
+	AutoClearOffers bool
+
+	// MoneroConfirmations, if non-zero, overrides the number of confirmations
+	// the maker requires on its XMR lock transaction.
+	MoneroConfirmations uint64
+
+	// EthConfirmations is the number of blocks that must be mined on top of a
+	// swap contract log, like a Claimed log, before it is acted on.
+	EthConfirmations uint64
+
+	// MaxSwapDuration, if non-zero, is how long a swap can be ongoing before
+	// it is exited with a lifecycle timeout. Exiting never abandons locked
+	// funds; it refunds them or waits until they can be recovered.
+	MaxSwapDuration time.Duration
+
+	// RelayClaimBuffer is the minimum time that must be left before t1 for the
+	// maker to claim using a relayer. With less time left, it claims without a
+	// relayer, or flags the swap for manual attention if its balance is too low.
+	RelayClaimBuffer time.Duration
+
+	// XMRLockDecimals is the decimal place that the maker rounds up the XMR
+	// amounts it locks to. If zero, amounts are not rounded.
+	XMRLockDecimals uint8
+
+	// MaxTakesPerMinute, if non-zero, is the number of take requests that a
+	// single peer can make per minute before its takes are rejected.
+	MaxTakesPerMinute uint
+
+	// XMRPayoutAddress, if set, is the address of our wallet that the maker
+	// sweeps reclaimed XMR to, instead of the primary address.
+	XMRPayoutAddress *mcrypto.Address
+
+	// PersistOfferDefaults stores the amounts and exchange rate of the most
+	// recent offer made for each asset, so they can be reused by later offers.
+	PersistOfferDefaults bool
+
+	// EventSocketPath, if set, is the path of a Unix socket on which swap
+	// status events are emitted to local consumers.
+	EventSocketPath string
+
+	// WsCompression enables per-message deflate compression of websocket
+	// messages for clients that negotiate it.
+	WsCompression bool
+
+	// MaxWsConnections, if non-zero, limits the number of concurrent websocket
+	// connections to the RPC server.
+	MaxWsConnections uint32
+
+	// EnableMetrics serves Prometheus metrics on the /metrics path of the RPC
+	// server.
+	EnableMetrics bool
+
+	// RPCAuthToken, if set, is the token that requests to the RPC server must
+	// present in their Authorization header.
+	RPCAuthToken string
+
+	// RPCAllowedOrigins, if set, are the origins of the web pages that can
+	// make requests to the RPC server. If empty, all origins are allowed.
+	RPCAllowedOrigins []string
+
+	// MinETHBalance, if set, is the ETH balance below which a low gas
+	// balance warning is logged and reported by the daemon_health method.
+	MinETHBalance *coins.WeiAmount
+
+	// MaxGasPrice, if set, is the gas price above which swap transactions are
+	// not sent.
+	MaxGasPrice *coins.WeiAmount
+
+	// ClearStuckTxs replaces any of our Ethereum transactions that were sent
+	// before a restart and are still not mined, so new transactions aren't
+	// queued behind them. Without it, such transactions are only logged.
+	ClearStuckTxs bool
+
+	// MaxFeePerGas and MaxPriorityFeePerGas, if either is set, make swap
+	// transactions use EIP-1559 fees.
+	MaxFeePerGas         *coins.WeiAmount
+	MaxPriorityFeePerGas *coins.WeiAmount
+
+	// EventChSize and LogChSize, if non-zero, override the buffer sizes of
+	// each swap's event channel and contract log channels.
+	EventChSize int
+	LogChSize   int
+
+	// MaxOngoingSwapsInMemory, if non-zero, is the number of ongoing swaps
+	// that are not being run, like ones that were not resumed, kept in memory.
+	// The least active ones beyond it are only kept in the database and read
+	// when accessed.
+	MaxOngoingSwapsInMemory int
+
+	// MessagePolicy configures how swaps handle unknown and unexpected messages
+	// from the peer. The zero value aborts the swap on any such message.
+	MessagePolicy net.MessagePolicy
+
+	// MaxStreamResumeAttempts is the number of attempts to resume a swap's
+	// protocol stream that dropped before the swap's funds were locked.
+	MaxStreamResumeAttempts uint
 }
 
 // RunSwapDaemon assembles and runs a swapd instance blocking until swapd is
@@ -57,9 +189,19 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		panic("swap creator address not specified")
 	}
 
+	if conf.IsObserver {
+		return runObserver(ctx, conf)
+	}
+
 	ec := conf.EthereumClient
 	chainID := ec.ChainID()
 
+	// this must happen before any ongoing swaps are resumed, as their
+	// transactions would otherwise queue behind the stuck ones
+	if err = checkStuckTxs(ctx, ec, conf.ClearStuckTxs, conf.MaxGasPrice); err != nil {
+		return err
+	}
+
 	// Initialize the database first, so the defer statement that closes it
 	// will get executed last.
 	sdb, err := db.NewDatabase(&chaindb.Config{
@@ -74,25 +216,25 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 		}
 	}()
 
-	sm, err := swap.NewManager(sdb)
+	sm, err := swap.NewManagerWithMaxOngoing(sdb, conf.MaxOngoingSwapsInMemory)
 	if err != nil {
 		return err
 	}
 
-	hostListenIP := "0.0.0.0"
-	if conf.EnvConf.Env == common.Development {
-		hostListenIP = "127.0.0.1"
-	}
-
 	host, err := net.NewHost(&net.Config{
-		Ctx:        ctx,
-		DataDir:    conf.EnvConf.DataDir,
-		Port:       conf.Libp2pPort,
-		KeyFile:    conf.Libp2pKeyfile,
-		Bootnodes:  conf.EnvConf.Bootnodes,
-		ProtocolID: fmt.Sprintf("%s/%d", net.ProtocolID, chainID.Int64()),
-		ListenIP:   hostListenIP,
-		IsRelayer:  conf.IsRelayer,
+		Ctx:           ctx,
+		DataDir:       conf.EnvConf.DataDir,
+		Port:          conf.Libp2pPort,
+		KeyFile:       conf.Libp2pKeyfile,
+		Bootnodes:     conf.EnvConf.Bootnodes,
+		ProtocolID:    fmt.Sprintf("%s/%d", net.ProtocolID, chainID.Int64()),
+		ListenIP:      hostListenIP(conf.EnvConf.Env),
+		IsRelayer:     conf.IsRelayer,
+		RelayerFee:    conf.RelayerFee,
+		MessagePolicy: conf.MessagePolicy,
+
+		MaxStreamResumeAttempts: conf.MaxStreamResumeAttempts,
+		BlocklistDB:             sdb,
 	})
 	if err != nil {
 		return err
@@ -104,14 +246,28 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 	}()
 
 	swapBackend, err := backend.NewBackend(&backend.Config{
-		Ctx:             ctx,
-		MoneroClient:    conf.MoneroClient,
-		EthereumClient:  conf.EthereumClient,
-		Environment:     conf.EnvConf.Env,
-		SwapCreatorAddr: conf.EnvConf.SwapCreatorAddr,
-		SwapManager:     sm,
-		RecoveryDB:      sdb.RecoveryDB(),
-		Net:             host,
+		Ctx:                  ctx,
+		MoneroClient:         conf.MoneroClient,
+		EthereumClient:       conf.EthereumClient,
+		Environment:          conf.EnvConf.Env,
+		SwapCreatorAddr:      conf.EnvConf.SwapCreatorAddr,
+		SwapManager:          sm,
+		RecoveryDB:           sdb.RecoveryDB(),
+		Net:                  host,
+		MinETHBalance:        conf.MinETHBalance,
+		MaxGasPrice:          conf.MaxGasPrice,
+		MaxFeePerGas:         conf.MaxFeePerGas,
+		MaxPriorityFeePerGas: conf.MaxPriorityFeePerGas,
+		EventChSize:          conf.EventChSize,
+		LogChSize:            conf.LogChSize,
+		RelayerFee:           conf.RelayerFee,
+		RelayedSwapsDB:       sdb,
+		MaxConcurrentRelays:  conf.MaxConcurrentRelays,
+		RelayQueueTimeout:    conf.RelayQueueTimeout,
+		RateSampleInterval:   conf.RateSampleInterval,
+		RateSampleDB:         sdb,
+		PriceProviders:       conf.PriceProviders,
+		MaxPriceAge:          conf.MaxPriceAge,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to make backend: %w", err)
@@ -123,19 +279,36 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 	)
 
 	xmrTaker, err := xmrtaker.NewInstance(&xmrtaker.Config{
-		Backend:        swapBackend,
-		DataDir:        conf.EnvConf.DataDir,
-		NoTransferBack: conf.NoTransferBack,
+		Backend:          swapBackend,
+		DataDir:          conf.EnvConf.DataDir,
+		NoTransferBack:   conf.NoTransferBack,
+		MaxSwapDuration:  conf.MaxSwapDuration,
+		EthConfirmations: conf.EthConfirmations,
 	})
 	if err != nil {
 		return err
 	}
 
+	var offerDefaultsDB xmrmaker.OfferDefaultsDB
+	if conf.PersistOfferDefaults {
+		offerDefaultsDB = sdb
+	}
+
 	xmrMaker, err := xmrmaker.NewInstance(&xmrmaker.Config{
-		Backend:  swapBackend,
-		DataDir:  conf.EnvConf.DataDir,
-		Database: sdb,
-		Network:  host,
+		Backend:             swapBackend,
+		DataDir:             conf.EnvConf.DataDir,
+		Database:            sdb,
+		RejectedTakesDB:     sdb,
+		OfferDefaultsDB:     offerDefaultsDB,
+		Network:             host,
+		AutoClearOffers:     conf.AutoClearOffers,
+		MoneroConfirmations: conf.MoneroConfirmations,
+		EthConfirmations:    conf.EthConfirmations,
+		MaxSwapDuration:     conf.MaxSwapDuration,
+		RelayClaimBuffer:    conf.RelayClaimBuffer,
+		XMRLockDecimals:     conf.XMRLockDecimals,
+		MaxTakesPerMinute:   conf.MaxTakesPerMinute,
+		XMRPayoutAddress:    conf.XMRPayoutAddress,
 	})
 	if err != nil {
 		return err
@@ -148,14 +321,25 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 	}
 
 	rpcServer, err := rpc.NewServer(&rpc.Config{
-		Ctx:             ctx,
-		Address:         fmt.Sprintf("127.0.0.1:%d", conf.RPCPort),
-		Net:             host,
-		XMRTaker:        xmrTaker,
-		XMRMaker:        xmrMaker,
-		ProtocolBackend: swapBackend,
-		RecoveryDB:      sdb.RecoveryDB(),
-		Namespaces:      rpc.AllNamespaces(),
+		Ctx:              ctx,
+		Address:          fmt.Sprintf("127.0.0.1:%d", conf.RPCPort),
+		Net:              host,
+		XMRTaker:         xmrTaker,
+		XMRMaker:         xmrMaker,
+		ProtocolBackend:  swapBackend,
+		RecoveryDB:       sdb.RecoveryDB(),
+		TokenDB:          sdb,
+		RateSampleDB:     sdb,
+		RateCacheTTL:     conf.RateCacheTTL,
+		PriceProviders:   conf.PriceProviders,
+		MaxPriceAge:      conf.MaxPriceAge,
+		Namespaces:       rpc.AllNamespaces(),
+		EventSocketPath:  conf.EventSocketPath,
+		WsCompression:    conf.WsCompression,
+		MaxWsConnections: conf.MaxWsConnections,
+		EnableMetrics:    conf.EnableMetrics,
+		AuthToken:        conf.RPCAuthToken,
+		AllowedOrigins:   conf.RPCAllowedOrigins,
 	})
 	if err != nil {
 		return err
@@ -174,3 +358,11 @@ func RunSwapDaemon(ctx context.Context, conf *SwapdConfig) (err error) {
 	// return statement below (not nil)
 	return err
 }
+
+// hostListenIP returns the IP that the peer-to-peer host listens on.
+func hostListenIP(env common.Environment) string {
+	if env == common.Development {
+		return "127.0.0.1"
+	}
+	return "0.0.0.0"
+}
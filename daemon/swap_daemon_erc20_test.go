@@ -47,7 +47,8 @@ func TestRunSwapDaemon_ExchangesXMRForERC20Tokens(t *testing.T) {
 	time.Sleep(250 * time.Millisecond) // offer propagation time
 
 	// Have Alice query all the offer information back
-	aRPC := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", aliceConf.RPCPort))
+	aRPC, err := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", aliceConf.RPCPort), "")
+	require.NoError(t, err)
 	peersWithOffers, err := aRPC.QueryAll(coins.ProvidesXMR, 3)
 	require.NoError(t, err)
 	require.Len(t, peersWithOffers, 1)
@@ -109,7 +110,8 @@ func TestRunSwapDaemon_ExchangesXMRForERC20Tokens(t *testing.T) {
 	//
 	// Check Bob's token balance via RPC method instead of doing it directly
 	//
-	bRPC := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", bobConf.RPCPort))
+	bRPC, err := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", bobConf.RPCPort), "")
+	require.NoError(t, err)
 	balances, err := bRPC.Balances(&rpctypes.BalancesRequest{TokenAddrs: []ethcommon.Address{tokenAddr}})
 	require.NoError(t, err)
 	t.Logf("Balances: %#v", balances)
@@ -480,7 +480,8 @@ func TestRunSwapDaemon_RPC_Version(t *testing.T) {
 	timeout := time.Minute
 	ctx, _ := LaunchDaemons(t, timeout, conf)
 
-	c := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", conf.RPCPort))
+	c, err := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", conf.RPCPort), "")
+	require.NoError(t, err)
 	versionResp, err := c.Version()
 	require.NoError(t, err)
 
@@ -488,6 +489,12 @@ func TestRunSwapDaemon_RPC_Version(t *testing.T) {
 	require.NotEmpty(t, versionResp.SwapdVersion)
 	require.Equal(t, conf.EnvConf.SwapCreatorAddr, versionResp.SwapCreatorAddr)
 	require.Equal(t, protocolVersion, versionResp.P2PVersion)
+	require.True(t, versionResp.Forwarder.IsValid)
+
+	forwarderResp, err := c.Forwarder()
+	require.NoError(t, err)
+	require.Equal(t, versionResp.Forwarder, forwarderResp)
+	require.NotEqual(t, ethcommon.Address{}, forwarderResp.ForwarderAddr)
 }
 
 // Tests the shutdown RPC method
@@ -496,8 +503,9 @@ func TestRunSwapDaemon_RPC_Shutdown(t *testing.T) {
 	timeout := time.Minute
 	ctx, _ := LaunchDaemons(t, timeout, conf)
 
-	c := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", conf.RPCPort))
-	err := c.Shutdown()
+	c, err := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", conf.RPCPort), "")
+	require.NoError(t, err)
+	err = c.Shutdown()
 	require.NoError(t, err)
 
 	err = c.Shutdown()
@@ -44,8 +44,10 @@ func TestAliceStoppedAndRestartedDuringXMRSweep(t *testing.T) {
 	require.NoError(t, err)
 
 	// Use an independent context for these clients that will execute across 2 runs of the daemons
-	bc := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", bobConf.RPCPort))
-	ac := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", aliceConf.RPCPort))
+	bc, err := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", bobConf.RPCPort), "")
+	require.NoError(t, err)
+	ac, err := rpcclient.NewClient(context.Background(), fmt.Sprintf("http://127.0.0.1:%d", aliceConf.RPCPort), "")
+	require.NoError(t, err)
 
 	tokenAddr := GetMockTokens(t, aliceConf.EthereumClient)[MockTether]
 	tokenAsset := types.EthAsset(tokenAddr)
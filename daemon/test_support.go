@@ -97,7 +97,8 @@ func LaunchDaemons(t *testing.T, timeout time.Duration, configs ...*SwapdConfig)
 
 		// Configure remaining daemons to use the first one a bootnode
 		if n == 0 {
-			c := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", conf.RPCPort))
+			c, err := rpcclient.NewClient(ctx, fmt.Sprintf("http://127.0.0.1:%d", conf.RPCPort), "")
+			require.NoError(t, err)
 			addresses, err := c.Addresses()
 			require.NoError(t, err)
 			require.Greater(t, len(addresses.Addrs), 1)
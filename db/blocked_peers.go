@@ -0,0 +1,47 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// PutBlockedPeer adds the peer to the stored blocklist.
+func (db *Database) PutBlockedPeer(peerID peer.ID) error {
+	err := db.blockedPeerTable.Put([]byte(peerID), []byte{})
+	if err != nil {
+		return err
+	}
+
+	return db.blockedPeerTable.Flush()
+}
+
+// DeleteBlockedPeer removes the peer from the stored blocklist.
+func (db *Database) DeleteBlockedPeer(peerID peer.ID) error {
+	err := db.blockedPeerTable.Del([]byte(peerID))
+	if err != nil {
+		return err
+	}
+
+	return db.blockedPeerTable.Flush()
+}
+
+// GetBlockedPeers returns the IDs of all peers on the stored blocklist.
+func (db *Database) GetBlockedPeers() ([]peer.ID, error) {
+	iter := db.blockedPeerTable.NewIterator()
+	defer iter.Release()
+
+	var peerIDs []peer.ID
+	for ; iter.Valid(); iter.Next() {
+		// if the key is not a peer ID, we're not iterating over blocked peers
+		peerID, err := peer.IDFromBytes(iter.Key())
+		if err != nil {
+			break
+		}
+
+		peerIDs = append(peerIDs, peerID)
+	}
+
+	return peerIDs, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_BlockedPeers(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	// put a swap to ensure iteration stops at the end of the table
+	info := newTestSwapInfo(types.Hash{1}, types.ETHLocked, time.Now())
+	require.NoError(t, db.PutSwap(info))
+
+	peerIDs, err := db.GetBlockedPeers()
+	require.NoError(t, err)
+	require.Empty(t, peerIDs)
+
+	otherPeerID, err := peer.Decode("12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2")
+	require.NoError(t, err)
+
+	require.NoError(t, db.PutBlockedPeer(testPeerID))
+	require.NoError(t, db.PutBlockedPeer(otherPeerID))
+	peerIDs, err = db.GetBlockedPeers()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []peer.ID{testPeerID, otherPeerID}, peerIDs)
+
+	require.NoError(t, db.DeleteBlockedPeer(testPeerID))
+	peerIDs, err = db.GetBlockedPeers()
+	require.NoError(t, err)
+	require.Equal(t, []peer.ID{otherPeerID}, peerIDs)
+}
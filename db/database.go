@@ -15,10 +15,21 @@ import (
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
+// Table prefixes must not start with the prefix of another table, as iterating
+// over a table would otherwise visit the other table's entries.
 const (
-	offerPrefix = "offer"
-	swapPrefix  = "swap"
-	idLength    = len(types.Hash{})
+	offerPrefix         = "offer"
+	swapPrefix          = "swap"
+	swapStartTimePrefix = "starttime"
+	rejectedTakePrefix  = "rejtake"
+	offerDefaultsPrefix = "ofdefaults"
+	offerExtraPrefix    = "ofextra"
+	tokenInfoPrefix     = "tokeninfo"
+	peerStatsPrefix     = "peerstats"
+	blockedPeerPrefix   = "blockedpeer"
+	relayedSwapPrefix   = "relayedswap"
+	rateSamplePrefix    = "ratesample"
+	idLength            = len(types.Hash{})
 )
 
 var (
@@ -42,6 +53,70 @@ type Database struct {
 	// only their `Status` field within *swap.Info may be updated.
 	swapTable chaindb.Database
 
+	// swapStartTimeTable is a key-value store where all the keys are prefixed by
+	// swapStartTimePrefix in the underlying database.
+	// it indexes swapTable by start time. the key is the 8-byte big-endian
+	// inverted unix nanosecond start time of the swap followed by the 32-byte
+	// swap ID, and the value is empty. entries are added with the swap.
+	swapStartTimeTable chaindb.Database
+
+	// rejectedTakeTable is a key-value store where all the keys are prefixed by
+	// rejectedTakePrefix in the underlying database.
+	// the key is the 8-byte big-endian unix nanosecond timestamp of the rejection
+	// followed by the 32-byte offer ID, and the value is a JSON-marshalled *RejectedTake.
+	// only the most recent maxRejectedTakes entries are retained.
+	rejectedTakeTable chaindb.Database
+
+	// offerDefaultsTable is a key-value store where all the keys are prefixed by
+	// offerDefaultsPrefix in the underlying database.
+	// the key is the environment name followed by the 20-byte ETH asset address,
+	// and the value is a JSON-marshalled *OfferDefaults holding the values of the
+	// most recent offer made for that asset.
+	offerDefaultsTable chaindb.Database
+
+	// offerExtraTable is a key-value store where all the keys are prefixed by
+	// offerExtraPrefix in the underlying database.
+	// the key is the 32-byte offer ID and the value is a JSON-marshalled
+	// *types.OfferExtra. entries are only stored for offers made with extra
+	// data that needs to survive a restart, and are removed with the offer.
+	offerExtraTable chaindb.Database
+
+	// tokenInfoTable is a key-value store where all the keys are prefixed by
+	// tokenInfoPrefix in the underlying database.
+	// the key is the environment name followed by the 20-byte token address,
+	// and the value is a JSON-marshalled *coins.ERC20TokenInfo holding the
+	// cached metadata of a token that swapd has seen. entries are only replaced
+	// when the metadata is explicitly refreshed.
+	tokenInfoTable chaindb.Database
+
+	// peerStatsTable is a key-value store where all the keys are prefixed by
+	// peerStatsPrefix in the underlying database.
+	// the key is the peer ID and the value is a JSON-marshalled *swap.PeerStats
+	// holding the counts of the outcomes of our completed swaps with the peer.
+	// entries are updated as swaps complete.
+	peerStatsTable chaindb.Database
+
+	// blockedPeerTable is a key-value store where all the keys are prefixed by
+	// blockedPeerPrefix in the underlying database.
+	// the key is the ID of a peer on the blocklist and the value is empty.
+	// entries are added and removed as peers are blocked and unblocked.
+	blockedPeerTable chaindb.Database
+
+	// relayedSwapTable is a key-value store where all the keys are prefixed by
+	// relayedSwapPrefix in the underlying database.
+	// the key is the 32-byte contract swap ID of a swap whose claim we relayed
+	// and the value is empty. entries are added as claims are relayed and are
+	// never deleted.
+	relayedSwapTable chaindb.Database
+
+	// rateSampleTable is a key-value store where all the keys are prefixed by
+	// rateSamplePrefix in the underlying database.
+	// the key is the 8-byte big-endian unix nanosecond time the sample was
+	// taken, and the value is a JSON-marshalled *RateSample holding the price
+	// feed values at that time. entries are added by the rate sampler and are
+	// never deleted.
+	rateSampleTable chaindb.Database
+
 	// recoveryDB contains a db table prefixed by recoveryPrefix.
 	// it contains information about ongoing swaps required to recover funds
 	// in case of a node crash, or any other problem.
@@ -57,11 +132,30 @@ func NewDatabase(cfg *chaindb.Config) (*Database, error) {
 
 	recoveryDB := newRecoveryDB(chaindb.NewTable(db, recoveryPrefix))
 
-	return &Database{
-		offerTable: chaindb.NewTable(db, offerPrefix),
-		swapTable:  chaindb.NewTable(db, swapPrefix),
-		recoveryDB: recoveryDB,
-	}, nil
+	database := &Database{
+		offerTable:         chaindb.NewTable(db, offerPrefix),
+		swapTable:          chaindb.NewTable(db, swapPrefix),
+		swapStartTimeTable: chaindb.NewTable(db, swapStartTimePrefix),
+		rejectedTakeTable:  chaindb.NewTable(db, rejectedTakePrefix),
+		offerDefaultsTable: chaindb.NewTable(db, offerDefaultsPrefix),
+		offerExtraTable:    chaindb.NewTable(db, offerExtraPrefix),
+		tokenInfoTable:     chaindb.NewTable(db, tokenInfoPrefix),
+		peerStatsTable:     chaindb.NewTable(db, peerStatsPrefix),
+		blockedPeerTable:   chaindb.NewTable(db, blockedPeerPrefix),
+		relayedSwapTable:   chaindb.NewTable(db, relayedSwapPrefix),
+		rateSampleTable:    chaindb.NewTable(db, rateSamplePrefix),
+		recoveryDB:         recoveryDB,
+	}
+
+	if err = database.indexSwapStartTimes(); err != nil {
+		return nil, err
+	}
+
+	if err = database.indexPeerStats(); err != nil {
+		return nil, err
+	}
+
+	return database, nil
 }
 
 // Close flushes and closes the database.
@@ -76,6 +170,51 @@ func (db *Database) Close() error {
 		return err
 	}
 
+	err = db.swapStartTimeTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.rejectedTakeTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.offerDefaultsTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.offerExtraTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.tokenInfoTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.peerStatsTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.blockedPeerTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.relayedSwapTable.Close()
+	if err != nil {
+		return err
+	}
+
+	err = db.rateSampleTable.Close()
+	if err != nil {
+		return err
+	}
+
 	return db.recoveryDB.close()
 }
 
@@ -100,8 +239,13 @@ func (db *Database) PutOffer(offer *types.Offer) error {
 	return db.offerTable.Flush()
 }
 
-// DeleteOffer deletes an offer from the database.
+// DeleteOffer deletes an offer, and any extra data stored with it, from the
+// database.
 func (db *Database) DeleteOffer(id types.Hash) error {
+	if err := db.offerExtraTable.Del(id[:]); err != nil {
+		return err
+	}
+
 	return db.offerTable.Del(id[:])
 }
 
@@ -124,6 +268,9 @@ func (db *Database) purgeInvalidOffer(id []byte, encodedOffer string, reasonErr
 	if err := db.offerTable.Del(id[:]); err != nil {
 		return err
 	}
+	if err := db.offerExtraTable.Del(id[:]); err != nil {
+		return err
+	}
 	swapEncoded, err := db.swapTable.Get(id[:])
 	if err != nil {
 		if errors.Is(chaindb.ErrKeyNotFound, err) {
@@ -184,6 +331,22 @@ func (db *Database) ClearAllOffers() error {
 		iter.Next()
 	}
 
+	return db.clearAllOfferExtras()
+}
+
+// clearAllOfferExtras clears the extra data of all offers from the database.
+func (db *Database) clearAllOfferExtras() error {
+	iter := db.offerExtraTable.NewIterator()
+	defer iter.Release()
+
+	for iter.Valid() {
+		err := db.offerExtraTable.Del(iter.Key())
+		if err != nil {
+			return err
+		}
+		iter.Next()
+	}
+
 	return nil
 }
 
@@ -201,6 +364,16 @@ func (db *Database) PutSwap(s *swap.Info) error {
 		return err
 	}
 
+	err = db.swapStartTimeTable.Put(getSwapStartTimeKey(s), []byte{})
+	if err != nil {
+		return err
+	}
+
+	err = db.swapStartTimeTable.Flush()
+	if err != nil {
+		return err
+	}
+
 	return db.swapTable.Flush()
 }
 
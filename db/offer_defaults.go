@@ -0,0 +1,55 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// getOfferDefaultsKey returns the key of the offer defaults for the given
+// asset. The environment is included so that values stored by, for example, a
+// stagenet swapd are never reused on mainnet if the data directory is shared.
+func getOfferDefaultsKey(env common.Environment, asset types.EthAsset) []byte {
+	addr := asset.Address()
+	return append([]byte(env.String()), addr[:]...)
+}
+
+// PutOfferDefaults stores the values of the most recent offer made for the
+// given asset in the given environment, replacing any previous values.
+func (db *Database) PutOfferDefaults(
+	env common.Environment,
+	asset types.EthAsset,
+	defaults *OfferDefaults,
+) error {
+	val, err := vjson.MarshalStruct(defaults)
+	if err != nil {
+		return err
+	}
+
+	err = db.offerDefaultsTable.Put(getOfferDefaultsKey(env, asset), val)
+	if err != nil {
+		return err
+	}
+
+	return db.offerDefaultsTable.Flush()
+}
+
+// GetOfferDefaults returns the stored offer defaults for the given asset in the
+// given environment. It returns the error chaindb.ErrKeyNotFound if no offer
+// has been made for the asset.
+func (db *Database) GetOfferDefaults(env common.Environment, asset types.EthAsset) (*OfferDefaults, error) {
+	val, err := db.offerDefaultsTable.Get(getOfferDefaultsKey(env, asset))
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := new(OfferDefaults)
+	if err = vjson.UnmarshalStruct(val, defaults); err != nil {
+		return nil, err
+	}
+
+	return defaults, nil
+}
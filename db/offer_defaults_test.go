@@ -0,0 +1,103 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_OfferDefaults(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.GetOfferDefaults(common.Mainnet, types.EthAssetETH)
+	require.True(t, errors.Is(err, chaindb.ErrKeyNotFound))
+
+	ethDefaults := &OfferDefaults{
+		MinAmount:    coins.StrToDecimal("0.1"),
+		MaxAmount:    coins.StrToDecimal("2"),
+		ExchangeRate: coins.ToExchangeRate(coins.StrToDecimal("0.06")),
+	}
+	require.NoError(t, db.PutOfferDefaults(common.Mainnet, types.EthAssetETH, ethDefaults))
+
+	token := types.EthAsset(ethcommon.Address{0x1})
+	tokenDefaults := &OfferDefaults{
+		MinAmount:    coins.StrToDecimal("1"),
+		MaxAmount:    coins.StrToDecimal("3"),
+		ExchangeRate: coins.ToExchangeRate(coins.StrToDecimal("150")),
+	}
+	require.NoError(t, db.PutOfferDefaults(common.Mainnet, token, tokenDefaults))
+
+	res, err := db.GetOfferDefaults(common.Mainnet, types.EthAssetETH)
+	require.NoError(t, err)
+	require.Equal(t, ethDefaults.MinAmount.String(), res.MinAmount.String())
+	require.Equal(t, ethDefaults.MaxAmount.String(), res.MaxAmount.String())
+	require.Equal(t, ethDefaults.ExchangeRate.String(), res.ExchangeRate.String())
+
+	res, err = db.GetOfferDefaults(common.Mainnet, token)
+	require.NoError(t, err)
+	require.Equal(t, tokenDefaults.ExchangeRate.String(), res.ExchangeRate.String())
+
+	// values are namespaced by environment
+	_, err = db.GetOfferDefaults(common.Stagenet, types.EthAssetETH)
+	require.True(t, errors.Is(err, chaindb.ErrKeyNotFound))
+
+	// later offers replace the stored values
+	ethDefaults.ExchangeRate = coins.ToExchangeRate(coins.StrToDecimal("0.07"))
+	require.NoError(t, db.PutOfferDefaults(common.Mainnet, types.EthAssetETH, ethDefaults))
+	res, err = db.GetOfferDefaults(common.Mainnet, types.EthAssetETH)
+	require.NoError(t, err)
+	require.Equal(t, "0.07", res.ExchangeRate.String())
+}
+
+func TestDatabase_OfferDefaults_notOffers(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	one := coins.StrToDecimal("1")
+	defaults := &OfferDefaults{
+		MinAmount:    one,
+		MaxAmount:    one,
+		ExchangeRate: coins.ToExchangeRate(one),
+	}
+	require.NoError(t, db.PutOfferDefaults(common.Mainnet, types.EthAssetETH, defaults))
+
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	require.NoError(t, db.PutOffer(offer))
+
+	// the defaults are neither returned as offers nor purged as invalid offers
+	offers, err := db.GetAllOffers()
+	require.NoError(t, err)
+	require.Len(t, offers, 1)
+	require.Equal(t, offer.ID, offers[0].ID)
+
+	_, err = db.GetOfferDefaults(common.Mainnet, types.EthAssetETH)
+	require.NoError(t, err)
+
+	// clearing the offers keeps the defaults
+	require.NoError(t, db.ClearAllOffers())
+	offers, err = db.GetAllOffers()
+	require.NoError(t, err)
+	require.Empty(t, offers)
+
+	_, err = db.GetOfferDefaults(common.Mainnet, types.EthAssetETH)
+	require.NoError(t, err)
+}
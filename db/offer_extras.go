@@ -0,0 +1,56 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// PutOfferExtra stores the extra data of the offer with the given ID, so that
+// it can be restored along with the offer when swapd restarts.
+func (db *Database) PutOfferExtra(id types.Hash, extra *types.OfferExtra) error {
+	val, err := vjson.MarshalStruct(extra)
+	if err != nil {
+		return err
+	}
+
+	err = db.offerExtraTable.Put(id[:], val)
+	if err != nil {
+		return err
+	}
+
+	return db.offerExtraTable.Flush()
+}
+
+// GetAllOfferExtras returns the stored extra data of all offers, keyed by the
+// offer ID. Offers that were made without any extra data worth storing have
+// no entry.
+func (db *Database) GetAllOfferExtras() (map[types.Hash]*types.OfferExtra, error) {
+	iter := db.offerExtraTable.NewIterator()
+	defer iter.Release()
+
+	extras := make(map[types.Hash]*types.OfferExtra)
+	for iter.Valid() {
+		key := iter.Key()
+
+		// if the key/offerID becomes longer than 32, we're not iterating over offer extras
+		if len(key) > idLength {
+			break
+		}
+
+		extra := new(types.OfferExtra)
+		if err := vjson.UnmarshalStruct(iter.Value(), extra); err != nil {
+			// The offer itself is still restored, just without its extra data
+			log.Warnf("ignoring invalid extra data of offer with ID=0x%X: %s", key, err)
+		} else {
+			var id types.Hash
+			copy(id[:], key)
+			extras[id] = extra
+		}
+		iter.Next()
+	}
+
+	return extras, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"errors"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// getPeerStats returns the stored stats of the peer, or empty stats if none
+// are stored.
+func (db *Database) getPeerStats(peerID peer.ID) (*swap.PeerStats, error) {
+	stats := new(swap.PeerStats)
+
+	val, err := db.peerStatsTable.Get([]byte(peerID))
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = vjson.UnmarshalStruct(val, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (db *Database) putPeerStats(peerID peer.ID, stats *swap.PeerStats) error {
+	val, err := vjson.MarshalStruct(stats)
+	if err != nil {
+		return err
+	}
+
+	return db.peerStatsTable.Put([]byte(peerID), val)
+}
+
+// IncrementPeerStats counts a swap with the peer that completed with the given
+// status.
+func (db *Database) IncrementPeerStats(peerID peer.ID, status types.Status) error {
+	stats, err := db.getPeerStats(peerID)
+	if err != nil {
+		return err
+	}
+
+	stats.Add(status)
+	if err = db.putPeerStats(peerID, stats); err != nil {
+		return err
+	}
+
+	return db.peerStatsTable.Flush()
+}
+
+// GetAllPeerStats returns the stored stats of all peers that we completed
+// swaps with.
+func (db *Database) GetAllPeerStats() (map[peer.ID]*swap.PeerStats, error) {
+	iter := db.peerStatsTable.NewIterator()
+	defer iter.Release()
+
+	allStats := make(map[peer.ID]*swap.PeerStats)
+	for ; iter.Valid(); iter.Next() {
+		// if the key is not a peer ID, we're not iterating over peer stats
+		peerID, err := peer.IDFromBytes(iter.Key())
+		if err != nil {
+			break
+		}
+
+		stats := new(swap.PeerStats)
+		if err = vjson.UnmarshalStruct(iter.Value(), stats); err != nil {
+			log.Warnf("skipping invalid stats of peer %s: %s", peerID, err)
+			continue
+		}
+
+		allStats[peerID] = stats
+	}
+
+	return allStats, nil
+}
+
+// indexPeerStats counts the outcomes of the completed swaps by peer, if no
+// peer stats are stored yet, for example after upgrading from a version of
+// swapd that did not keep them.
+func (db *Database) indexPeerStats() error {
+	allStats, err := db.GetAllPeerStats()
+	if err != nil {
+		return err
+	}
+	if len(allStats) > 0 {
+		return nil
+	}
+
+	swaps, err := db.GetAllSwaps()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range swaps {
+		if s.Status.IsOngoing() || s.PeerID == "" {
+			continue
+		}
+
+		stats, has := allStats[s.PeerID]
+		if !has {
+			stats = new(swap.PeerStats)
+			allStats[s.PeerID] = stats
+		}
+		stats.Add(s.Status)
+	}
+
+	for peerID, stats := range allStats {
+		if err = db.putPeerStats(peerID, stats); err != nil {
+			return err
+		}
+	}
+
+	return db.peerStatsTable.Flush()
+}
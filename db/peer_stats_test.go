@@ -0,0 +1,86 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestDatabase_PeerStats(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	// put a swap to ensure iteration stops at the end of the table
+	info := newTestSwapInfo(types.Hash{1}, types.ETHLocked, time.Now())
+	require.NoError(t, db.PutSwap(info))
+
+	allStats, err := db.GetAllPeerStats()
+	require.NoError(t, err)
+	require.Empty(t, allStats)
+
+	otherPeerID, err := peer.Decode("12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2")
+	require.NoError(t, err)
+
+	for _, status := range []types.Status{
+		types.CompletedSuccess,
+		types.CompletedSuccess,
+		types.CompletedRefund,
+		types.CompletedAbort,
+	} {
+		require.NoError(t, db.IncrementPeerStats(testPeerID, status))
+	}
+	require.NoError(t, db.IncrementPeerStats(otherPeerID, types.CompletedAbort))
+
+	allStats, err = db.GetAllPeerStats()
+	require.NoError(t, err)
+	require.Equal(t, map[peer.ID]*swap.PeerStats{
+		testPeerID:  {NumSucceeded: 2, NumRefunded: 1, NumAborted: 1},
+		otherPeerID: {NumAborted: 1},
+	}, allStats)
+}
+
+func TestDatabase_indexPeerStats(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	now := time.Now()
+	for i, status := range []types.Status{
+		types.CompletedSuccess,
+		types.CompletedRefund,
+		types.CompletedSuccess,
+		types.ETHLocked, // ongoing swaps are not counted
+	} {
+		require.NoError(t, db.PutSwap(newTestSwapInfo(types.Hash{byte(i + 1)}, status, now)))
+	}
+
+	require.NoError(t, db.indexPeerStats())
+
+	allStats, err := db.GetAllPeerStats()
+	require.NoError(t, err)
+	require.Equal(t, map[peer.ID]*swap.PeerStats{
+		testPeerID: {NumSucceeded: 2, NumRefunded: 1},
+	}, allStats)
+
+	// once stats are stored, the swaps are not counted again
+	require.NoError(t, db.indexPeerStats())
+	allStats, err = db.GetAllPeerStats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), allStats[testPeerID].NumSucceeded)
+}
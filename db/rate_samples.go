@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+func getRateSampleKey(t time.Time) []byte {
+	key := make([]byte, timestampLength)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// PutRateSample stores a sample of the exchange rate in the database.
+func (db *Database) PutRateSample(sample *RateSample) error {
+	val, err := vjson.MarshalStruct(sample)
+	if err != nil {
+		return err
+	}
+
+	err = db.rateSampleTable.Put(getRateSampleKey(sample.Time), val)
+	if err != nil {
+		return err
+	}
+
+	return db.rateSampleTable.Flush()
+}
+
+// GetRateSamples returns the stored exchange rate samples taken between since
+// and until, inclusive, ordered from oldest to newest. A zero since or until
+// leaves that end of the range open.
+func (db *Database) GetRateSamples(since time.Time, until time.Time) ([]*RateSample, error) {
+	iter := db.rateSampleTable.NewIterator()
+	defer iter.Release()
+
+	var samples []*RateSample
+	for iter.Valid() {
+		key := iter.Key()
+
+		// if the key is not the expected length, we're not iterating over rate samples
+		if len(key) != timestampLength {
+			break
+		}
+
+		sampleTime := time.Unix(0, int64(binary.BigEndian.Uint64(key)))
+		if !until.IsZero() && sampleTime.After(until) {
+			// keys are ordered by time, so the remaining samples are all later
+			break
+		}
+
+		if since.IsZero() || !sampleTime.Before(since) {
+			sample := new(RateSample)
+			if err := vjson.UnmarshalStruct(iter.Value(), sample); err != nil {
+				log.Warnf("skipping invalid rate sample entry with key=0x%X: %s", key, err)
+			} else {
+				samples = append(samples, sample)
+			}
+		}
+
+		iter.Next()
+	}
+
+	return samples, nil
+}
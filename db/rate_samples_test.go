@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_RateSamples(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	// put a relayed swap to ensure iteration stops at the end of the table
+	require.NoError(t, db.PutRelayedSwap(types.Hash{1}))
+
+	samples, err := db.GetRateSamples(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, samples)
+
+	start := time.Now().Round(0)
+	for i := 0; i < 4; i++ {
+		err = db.PutRateSample(&RateSample{
+			Time:         start.Add(time.Duration(i) * time.Hour),
+			XMRPrice:     coins.StrToDecimal("150"),
+			ETHPrice:     coins.StrToDecimal("2000"),
+			ExchangeRate: coins.ToExchangeRate(coins.StrToDecimal("0.075")),
+		})
+		require.NoError(t, err)
+	}
+
+	samples, err = db.GetRateSamples(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, samples, 4)
+	// oldest entries are returned first
+	require.True(t, samples[0].Time.Equal(start))
+	require.Equal(t, "0.075", samples[0].ExchangeRate.String())
+
+	// both ends of the range are inclusive
+	samples, err = db.GetRateSamples(start.Add(time.Hour), start.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	require.True(t, samples[0].Time.Equal(start.Add(time.Hour)))
+	require.True(t, samples[1].Time.Equal(start.Add(2*time.Hour)))
+
+	samples, err = db.GetRateSamples(start.Add(3*time.Hour+time.Second), time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, samples)
+}
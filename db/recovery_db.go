@@ -7,6 +7,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
 
 	"github.com/ChainSafe/chaindb"
 )
@@ -18,6 +19,7 @@ const (
 	counterpartySwapPrivateKeyPrefix = "cspriv"
 	relayerInfoPrefix                = "relayer"
 	counterpartySwapKeysPrefix       = "cskeys"
+	counterpartyDLEqProofPrefix      = "csdleq"
 )
 
 // RecoveryDB contains information about ongoing swaps required for recovery
@@ -218,6 +220,50 @@ func (db *RecoveryDB) GetCounterpartySwapKeys(id types.Hash) (*mcrypto.PublicKey
 	return info.PublicSpendKey, info.PrivateViewKey, nil
 }
 
+// CounterpartyDLEqProof is the DLEq proof sent by the swap counterparty, along
+// with the keys that it proves correspond.
+type CounterpartyDLEqProof struct {
+	Proof              []byte               `json:"proof" validate:"required"`
+	Secp256k1PublicKey *secp256k1.PublicKey `json:"secp256k1PublicKey" validate:"required"`
+	PublicSpendKey     *mcrypto.PublicKey   `json:"publicSpendKey" validate:"required"`
+}
+
+// PutCounterpartyDLEqProof is used to store the counterparty's DLEq proof, so
+// that it can be verified again later.
+func (db *RecoveryDB) PutCounterpartyDLEqProof(id types.Hash, proof *CounterpartyDLEqProof) error {
+	val, err := vjson.MarshalStruct(proof)
+	if err != nil {
+		return err
+	}
+
+	key := getRecoveryDBKey(id, counterpartyDLEqProofPrefix)
+	log.Debugf("PutCounterpartyDLEqProof %s", key)
+	err = db.db.Put(key, val)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("flushing db")
+	return db.db.Flush()
+}
+
+// GetCounterpartyDLEqProof returns the counterparty's DLEq proof.
+func (db *RecoveryDB) GetCounterpartyDLEqProof(id types.Hash) (*CounterpartyDLEqProof, error) {
+	key := getRecoveryDBKey(id, counterpartyDLEqProofPrefix)
+	value, err := db.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof CounterpartyDLEqProof
+	err = vjson.UnmarshalStruct(value, &proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proof, nil
+}
+
 // DeleteSwap deletes all recovery info from the db for the given swap.
 // TODO: this is currently unimplemented
 func (db *RecoveryDB) DeleteSwap(id types.Hash) error {
@@ -232,6 +278,7 @@ func (db *RecoveryDB) deleteSwap(id types.Hash) error {
 		getRecoveryDBKey(id, swapPrivateKeyPrefix),
 		getRecoveryDBKey(id, counterpartySwapPrivateKeyPrefix),
 		getRecoveryDBKey(id, counterpartySwapKeysPrefix),
+		getRecoveryDBKey(id, counterpartyDLEqProofPrefix),
 	}
 
 	for _, key := range keys {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/ChainSafe/chaindb"
 	"github.com/stretchr/testify/require"
@@ -15,6 +16,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
 
@@ -141,6 +143,34 @@ func TestRecoveryDB_CounterpartySwapKeys(t *testing.T) {
 	require.Equal(t, kp.ViewKey().String(), resVk.String())
 }
 
+func TestRecoveryDB_CounterpartyDLEqProof(t *testing.T) {
+	rdb := newTestRecoveryDB(t)
+	offerID := types.Hash{5, 6, 7, 8}
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	ethKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	proof := &CounterpartyDLEqProof{
+		Proof:              []byte{1, 2, 3, 4},
+		Secp256k1PublicKey: secp256k1.NewPublicKeyFromBigInt(ethKey.X, ethKey.Y),
+		PublicSpendKey:     kp.SpendKey().Public(),
+	}
+
+	err = rdb.PutCounterpartyDLEqProof(offerID, proof)
+	require.NoError(t, err)
+
+	res, err := rdb.GetCounterpartyDLEqProof(offerID)
+	require.NoError(t, err)
+	require.Equal(t, proof.Proof, res.Proof)
+	require.Equal(t, proof.Secp256k1PublicKey.String(), res.Secp256k1PublicKey.String())
+	require.Equal(t, proof.PublicSpendKey.String(), res.PublicSpendKey.String())
+
+	_, err = rdb.GetCounterpartyDLEqProof(types.Hash{0x1})
+	require.EqualError(t, chaindb.ErrKeyNotFound, err.Error())
+}
+
 func TestRecoveryDB_DeleteSwap(t *testing.T) {
 	rdb := newTestRecoveryDB(t)
 	offerID := types.Hash{5, 6, 7, 8}
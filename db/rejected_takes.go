@@ -0,0 +1,115 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"encoding/binary"
+
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+const (
+	// maxRejectedTakes is the maximum number of rejected take entries that are
+	// kept in the database. When exceeded, the oldest entries are pruned.
+	maxRejectedTakes = 1000
+
+	timestampLength       = 8
+	rejectedTakeKeyLength = timestampLength + idLength
+)
+
+func getRejectedTakeKey(rt *RejectedTake) []byte {
+	key := make([]byte, timestampLength, rejectedTakeKeyLength)
+	binary.BigEndian.PutUint64(key, uint64(rt.Time.UnixNano()))
+	return append(key, rt.OfferID[:]...)
+}
+
+// PutRejectedTake stores a rejected take request in the database, pruning the
+// oldest entries if there are more than maxRejectedTakes stored.
+func (db *Database) PutRejectedTake(rt *RejectedTake) error {
+	val, err := vjson.MarshalStruct(rt)
+	if err != nil {
+		return err
+	}
+
+	err = db.rejectedTakeTable.Put(getRejectedTakeKey(rt), val)
+	if err != nil {
+		return err
+	}
+
+	if err = db.pruneRejectedTakes(maxRejectedTakes); err != nil {
+		return err
+	}
+
+	return db.rejectedTakeTable.Flush()
+}
+
+// rejectedTakeKeys returns the keys of all stored rejected takes, ordered from
+// oldest to newest.
+func (db *Database) rejectedTakeKeys() [][]byte {
+	iter := db.rejectedTakeTable.NewIterator()
+	defer iter.Release()
+
+	var keys [][]byte
+	for iter.Valid() {
+		key := iter.Key()
+
+		// if the key is not the expected length, we're not iterating over rejected takes
+		if len(key) != rejectedTakeKeyLength {
+			break
+		}
+
+		keys = append(keys, append([]byte{}, key...))
+		iter.Next()
+	}
+
+	return keys
+}
+
+// pruneRejectedTakes deletes the oldest rejected takes until at most maxEntries remain.
+func (db *Database) pruneRejectedTakes(maxEntries int) error {
+	keys := db.rejectedTakeKeys()
+	if len(keys) <= maxEntries {
+		return nil
+	}
+
+	for _, key := range keys[:len(keys)-maxEntries] {
+		if err := db.rejectedTakeTable.Del(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRejectedTakes returns all stored rejected takes, ordered from newest to oldest.
+func (db *Database) GetRejectedTakes() ([]*RejectedTake, error) {
+	iter := db.rejectedTakeTable.NewIterator()
+	defer iter.Release()
+
+	var rejectedTakes []*RejectedTake
+	for iter.Valid() {
+		key := iter.Key()
+
+		// if the key is not the expected length, we're not iterating over rejected takes
+		if len(key) != rejectedTakeKeyLength {
+			break
+		}
+
+		rt := new(RejectedTake)
+		if err := vjson.UnmarshalStruct(iter.Value(), rt); err != nil {
+			log.Warnf("skipping invalid rejected take entry with key=0x%X: %s", key, err)
+		} else {
+			rejectedTakes = append(rejectedTakes, rt)
+		}
+
+		iter.Next()
+	}
+
+	// keys are ordered by timestamp, so reverse the entries to have the newest first
+	for i, j := 0, len(rejectedTakes)-1; i < j; i, j = i+1, j-1 {
+		rejectedTakes[i], rejectedTakes[j] = rejectedTakes[j], rejectedTakes[i]
+	}
+
+	return rejectedTakes, nil
+}
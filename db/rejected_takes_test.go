@@ -0,0 +1,64 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_RejectedTakes(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	// put a swap and offer to ensure iteration stops at the end of the table
+	one := coins.StrToDecimal("1")
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	require.NoError(t, db.PutOffer(offer))
+
+	rejectedTakes, err := db.GetRejectedTakes()
+	require.NoError(t, err)
+	require.Empty(t, rejectedTakes)
+
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		err = db.PutRejectedTake(&RejectedTake{
+			PeerID:  testPeerID,
+			OfferID: types.Hash{byte(i + 1)},
+			Reason:  "balance too low",
+			Time:    now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	rejectedTakes, err = db.GetRejectedTakes()
+	require.NoError(t, err)
+	require.Len(t, rejectedTakes, 4)
+	// newest entries are returned first
+	require.Equal(t, types.Hash{4}, rejectedTakes[0].OfferID)
+	require.Equal(t, types.Hash{1}, rejectedTakes[3].OfferID)
+	require.Equal(t, testPeerID, rejectedTakes[0].PeerID)
+
+	require.NoError(t, db.pruneRejectedTakes(2))
+	rejectedTakes, err = db.GetRejectedTakes()
+	require.NoError(t, err)
+	require.Len(t, rejectedTakes, 2)
+	require.Equal(t, types.Hash{4}, rejectedTakes[0].OfferID)
+	require.Equal(t, types.Hash{3}, rejectedTakes[1].OfferID)
+
+	// offers are unaffected by the rejected take entries
+	offers, err := db.GetAllOffers()
+	require.NoError(t, err)
+	require.Len(t, offers, 1)
+}
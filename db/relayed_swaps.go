@@ -0,0 +1,25 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// PutRelayedSwap records that we relayed a claim of the swap with the given
+// contract swap ID.
+func (db *Database) PutRelayedSwap(swapID types.Hash) error {
+	err := db.relayedSwapTable.Put(swapID[:], []byte{})
+	if err != nil {
+		return err
+	}
+
+	return db.relayedSwapTable.Flush()
+}
+
+// HasRelayedSwap returns true if we relayed a claim of the swap with the given
+// contract swap ID.
+func (db *Database) HasRelayedSwap(swapID types.Hash) (bool, error) {
+	return db.relayedSwapTable.Has(swapID[:])
+}
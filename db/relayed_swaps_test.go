@@ -0,0 +1,36 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestDatabase_RelayedSwaps(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	swapID := types.Hash{1}
+	relayed, err := db.HasRelayedSwap(swapID)
+	require.NoError(t, err)
+	require.False(t, relayed)
+
+	require.NoError(t, db.PutRelayedSwap(swapID))
+	relayed, err = db.HasRelayedSwap(swapID)
+	require.NoError(t, err)
+	require.True(t, relayed)
+
+	relayed, err = db.HasRelayedSwap(types.Hash{2})
+	require.NoError(t, err)
+	require.False(t, relayed)
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/ChainSafe/chaindb"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+const swapStartTimeKeyLength = timestampLength + idLength
+
+// getSwapStartTimeKey returns the swapStartTimeTable key of the given swap. The
+// timestamp is inverted, so that iterating over the table in key order returns
+// the newest swaps first.
+func getSwapStartTimeKey(s *swap.Info) []byte {
+	var nanos uint64
+	if s.StartTime.Unix() > 0 {
+		nanos = uint64(s.StartTime.UnixNano())
+	}
+
+	key := make([]byte, timestampLength, swapStartTimeKeyLength)
+	binary.BigEndian.PutUint64(key, math.MaxUint64-nanos)
+	return append(key, s.OfferID[:]...)
+}
+
+// indexSwapStartTimes adds any swaps missing from the swapStartTimeTable, for
+// example swaps that were stored by a version of swapd without the index.
+func (db *Database) indexSwapStartTimes() error {
+	swaps, err := db.GetAllSwaps()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range swaps {
+		key := getSwapStartTimeKey(s)
+		has, err := db.swapStartTimeTable.Has(key)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+
+		if err = db.swapStartTimeTable.Put(key, []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return db.swapStartTimeTable.Flush()
+}
+
+// GetSwapsByStartTime returns the swaps passing the given filter, ordered from
+// newest to oldest start time. The first offset matching swaps are skipped, and
+// at most limit swaps are returned, or all of them if limit is zero. Only the
+// swaps up to the end of the requested page are read from the database. If the
+// filter is nil, all swaps match.
+func (db *Database) GetSwapsByStartTime(filter swap.PastSwapFilter, offset, limit uint) ([]*swap.Info, error) {
+	iter := db.swapStartTimeTable.NewIterator()
+	defer iter.Release()
+
+	var swaps []*swap.Info
+	for ; iter.Valid(); iter.Next() {
+		if limit != 0 && uint(len(swaps)) == limit {
+			break
+		}
+
+		key := iter.Key()
+
+		// if the key is not the expected length, we're not iterating over the index
+		if len(key) != swapStartTimeKeyLength {
+			break
+		}
+
+		var id types.Hash
+		copy(id[:], key[timestampLength:])
+		s, err := db.GetSwap(id)
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			// the swap was removed as an invalid entry by GetAllSwaps
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if filter != nil && !filter(s) {
+			continue
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		swaps = append(swaps, s)
+	}
+
+	return swaps, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func newTestSwapInfo(id types.Hash, status types.Status, startTime time.Time) *swap.Info {
+	return &swap.Info{
+		Version:              swap.CurInfoVersion,
+		PeerID:               testPeerID,
+		OfferID:              id,
+		Provides:             coins.ProvidesXMR,
+		ProvidedAmount:       coins.StrToDecimal("1.5"),
+		ExpectedAmount:       coins.StrToDecimal("0.15"),
+		ExchangeRate:         coins.ToExchangeRate(coins.StrToDecimal("0.1")),
+		EthAsset:             types.EthAssetETH,
+		Status:               status,
+		LastStatusUpdateTime: startTime,
+		MoneroStartHeight:    12345,
+		StartTime:            startTime,
+	}
+}
+
+func swapIDs(swaps []*swap.Info) []types.Hash {
+	ids := make([]types.Hash, len(swaps))
+	for i, s := range swaps {
+		ids[i] = s.OfferID
+	}
+	return ids
+}
+
+func TestDatabase_GetSwapsByStartTime(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	// put an offer to ensure iteration stops at the end of the table
+	one := coins.StrToDecimal("1")
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	require.NoError(t, db.PutOffer(offer))
+
+	now := time.Now()
+	statuses := []types.Status{
+		types.CompletedSuccess,
+		types.CompletedRefund,
+		types.CompletedSuccess,
+		types.CompletedRefund,
+	}
+	for i, status := range statuses {
+		err = db.PutSwap(newTestSwapInfo(types.Hash{byte(i + 1)}, status, now.Add(time.Duration(i)*time.Minute)))
+		require.NoError(t, err)
+	}
+
+	// updating a swap does not add another index entry
+	err = db.PutSwap(newTestSwapInfo(types.Hash{1}, types.CompletedSuccess, now))
+	require.NoError(t, err)
+
+	// newest swaps are returned first
+	swaps, err := db.GetSwapsByStartTime(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []types.Hash{{4}, {3}, {2}, {1}}, swapIDs(swaps))
+
+	swaps, err = db.GetSwapsByStartTime(nil, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []types.Hash{{3}, {2}}, swapIDs(swaps))
+
+	swaps, err = db.GetSwapsByStartTime(nil, 4, 2)
+	require.NoError(t, err)
+	require.Empty(t, swaps)
+
+	// the offset and limit apply to the filtered swaps
+	refunded := func(info *swap.Info) bool {
+		return info.Status == types.CompletedRefund
+	}
+	swaps, err = db.GetSwapsByStartTime(refunded, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, []types.Hash{{2}}, swapIDs(swaps))
+}
+
+func TestDatabase_indexSwapStartTimes(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	// write the swap without an index entry, like an older swapd version would
+	info := newTestSwapInfo(types.Hash{1}, types.CompletedSuccess, time.Now())
+	val, err := vjson.MarshalStruct(info)
+	require.NoError(t, err)
+	require.NoError(t, db.swapTable.Put(info.OfferID[:], val))
+
+	swaps, err := db.GetSwapsByStartTime(nil, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, swaps)
+
+	require.NoError(t, db.indexSwapStartTimes())
+	swaps, err = db.GetSwapsByStartTime(nil, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []types.Hash{{1}}, swapIDs(swaps))
+}
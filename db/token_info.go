@@ -0,0 +1,84 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"bytes"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+)
+
+// getTokenInfoKey returns the key of the cached metadata of the given token.
+// As with offer defaults, the environment is included so that tokens seen on
+// one network are never listed on another if the data directory is shared.
+func getTokenInfoKey(env common.Environment, addr ethcommon.Address) []byte {
+	return append([]byte(env.String()), addr[:]...)
+}
+
+// PutTokenInfo caches the metadata of a token in the given environment,
+// replacing any previously cached metadata of the token.
+func (db *Database) PutTokenInfo(env common.Environment, token *coins.ERC20TokenInfo) error {
+	val, err := vjson.MarshalStruct(token)
+	if err != nil {
+		return err
+	}
+
+	err = db.tokenInfoTable.Put(getTokenInfoKey(env, token.Address), val)
+	if err != nil {
+		return err
+	}
+
+	return db.tokenInfoTable.Flush()
+}
+
+// GetTokenInfo returns the cached metadata of the given token in the given
+// environment. It returns the error chaindb.ErrKeyNotFound if the token's
+// metadata is not cached.
+func (db *Database) GetTokenInfo(env common.Environment, addr ethcommon.Address) (*coins.ERC20TokenInfo, error) {
+	val, err := db.tokenInfoTable.Get(getTokenInfoKey(env, addr))
+	if err != nil {
+		return nil, err
+	}
+
+	token := new(coins.ERC20TokenInfo)
+	if err = vjson.UnmarshalStruct(val, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// GetAllTokenInfo returns the cached metadata of all tokens in the given
+// environment, ordered by token address.
+func (db *Database) GetAllTokenInfo(env common.Environment) ([]*coins.ERC20TokenInfo, error) {
+	envPrefix := []byte(env.String())
+	keyLength := len(envPrefix) + ethcommon.AddressLength
+
+	iter := db.tokenInfoTable.NewIterator()
+	defer iter.Release()
+
+	var tokens []*coins.ERC20TokenInfo
+	for ; iter.Valid(); iter.Next() {
+		key := iter.Key()
+
+		// skip the tokens of other environments
+		if len(key) != keyLength || !bytes.HasPrefix(key, envPrefix) {
+			continue
+		}
+
+		token := new(coins.ERC20TokenInfo)
+		if err := vjson.UnmarshalStruct(iter.Value(), token); err != nil {
+			log.Warnf("skipping invalid token info entry with key=0x%X: %s", key, err)
+			continue
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestDatabase_TokenInfo(t *testing.T) {
+	db, err := NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.GetTokenInfo(common.Mainnet, ethcommon.Address{0x1})
+	require.True(t, errors.Is(err, chaindb.ErrKeyNotFound))
+
+	tokens, err := db.GetAllTokenInfo(common.Mainnet)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	usdt := coins.NewERC20TokenInfo(ethcommon.Address{0x2}, 6, "Tether USD", "USDT")
+	dai := coins.NewERC20TokenInfo(ethcommon.Address{0x1}, 18, "Dai Stablecoin", "DAI")
+	require.NoError(t, db.PutTokenInfo(common.Mainnet, usdt))
+	require.NoError(t, db.PutTokenInfo(common.Mainnet, dai))
+
+	res, err := db.GetTokenInfo(common.Mainnet, usdt.Address)
+	require.NoError(t, err)
+	require.Equal(t, usdt, res)
+
+	// tokens are ordered by address
+	tokens, err = db.GetAllTokenInfo(common.Mainnet)
+	require.NoError(t, err)
+	require.Equal(t, []*coins.ERC20TokenInfo{dai, usdt}, tokens)
+
+	// tokens are namespaced by environment
+	tokens, err = db.GetAllTokenInfo(common.Stagenet)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	// refreshed metadata replaces the cached metadata
+	usdt.Name = "Tether"
+	require.NoError(t, db.PutTokenInfo(common.Mainnet, usdt))
+	res, err = db.GetTokenInfo(common.Mainnet, usdt.Address)
+	require.NoError(t, err)
+	require.Equal(t, "Tether", res.Name)
+}
@@ -5,11 +5,15 @@ package db
 
 import (
 	"math/big"
+	"time"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 // EthereumSwapInfo represents information required on the Ethereum side in case of recovery
@@ -29,3 +33,31 @@ type EthereumSwapInfo struct {
 	// SwapCreatorAddr is the address of the contract on which the swap was created.
 	SwapCreatorAddr ethcommon.Address `json:"swapCreatorAddr" validate:"required"`
 }
+
+// RejectedTake is a record of an incoming take request that the XMR maker
+// rejected before a swap was created, eg. because the offer no longer existed,
+// the requested amount was out of range or our balance was too low.
+type RejectedTake struct {
+	PeerID  peer.ID    `json:"peerID" validate:"required"`
+	OfferID types.Hash `json:"offerID" validate:"required"`
+	Reason  string     `json:"reason" validate:"required"`
+	Time    time.Time  `json:"time" validate:"required"`
+}
+
+// RateSample is a snapshot of the price feeds behind the suggested exchange
+// rate, taken periodically so the rate can be charted over time.
+type RateSample struct {
+	Time         time.Time           `json:"time" validate:"required"`
+	XMRPrice     *apd.Decimal        `json:"xmrPrice" validate:"required"`
+	ETHPrice     *apd.Decimal        `json:"ethPrice" validate:"required"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	Provider     string              `json:"provider,omitempty"` // the price provider that the prices came from
+}
+
+// OfferDefaults holds the values of the most recent offer made for an ETH
+// asset, so that they can be reused when making a later offer.
+type OfferDefaults struct {
+	MinAmount    *apd.Decimal        `json:"minAmount" validate:"required"`
+	MaxAmount    *apd.Decimal        `json:"maxAmount" validate:"required"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+}
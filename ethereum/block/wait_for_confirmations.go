@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package block
+
+import (
+	"context"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+// WaitForConfirmations waits until the transaction was mined into a block with at
+// least the given number of blocks mined on top of it, so that it is not undone
+// by a reorg. The transaction is looked up again on every check, so a reorg that
+// moves it into a different block only prolongs the wait. If confirmations is
+// zero, it returns immediately.
+func WaitForConfirmations(
+	ctx context.Context,
+	ec *ethclient.Client,
+	txHash ethcommon.Hash,
+	confirmations uint64,
+) error {
+	if confirmations == 0 {
+		return nil
+	}
+
+	for {
+		receipt, err := WaitForReceipt(ctx, ec, txHash)
+		if err != nil {
+			return err
+		}
+
+		head, err := ec.BlockNumber(ctx)
+		if err != nil {
+			log.Warnf("failed to get latest block number: %s", err)
+		} else if Depth(head, receipt.BlockNumber.Uint64()) >= confirmations {
+			return nil
+		}
+
+		log.Debugf("waiting for %d confirmations of transaction: txHash=%s", confirmations, txHash)
+		if err = common.SleepWithContext(ctx, receiptSleepDuration); err != nil {
+			return err
+		}
+	}
+}
+
+// Depth returns the number of blocks that were mined on top of the block with
+// the given number, given the current head.
+func Depth(head uint64, blockNum uint64) uint64 {
+	if head < blockNum {
+		// the endpoint that returned the head is behind the one that
+		// returned the block
+		return 0
+	}
+
+	return head - blockNum
+}
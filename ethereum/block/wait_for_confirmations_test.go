@@ -0,0 +1,25 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package block
+
+import (
+	"context"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForConfirmations_noConfirmations(t *testing.T) {
+	// the client is not used when no confirmations are required
+	err := WaitForConfirmations(context.Background(), nil, ethcommon.Hash{}, 0)
+	require.NoError(t, err)
+}
+
+func TestDepth(t *testing.T) {
+	require.Equal(t, uint64(0), Depth(10, 10))
+	require.Equal(t, uint64(3), Depth(13, 10))
+	// the head is from an endpoint that is behind
+	require.Equal(t, uint64(0), Depth(9, 10))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -33,6 +34,10 @@ var forwarderAddrIndices = []int{203, 1124}
 
 var (
 	errInvalidSwapCreatorContract = errors.New("given contract address does not contain correct SwapCreator code")
+
+	// ErrInvalidForwarderContract is returned by VerifyTrustedForwarder when the
+	// SwapCreator's trusted forwarder does not contain the expected forwarder code.
+	ErrInvalidForwarderContract = errors.New("trusted forwarder does not contain correct forwarder code")
 )
 
 // CheckSwapCreatorContractCode checks that the bytecode at the given address matches the
@@ -122,3 +127,34 @@ func CheckForwarderContractCode(
 
 	return gsnforwarder.CheckForwarderContractCode(ctx, ec, contractAddr)
 }
+
+// VerifyTrustedForwarder reads the trusted forwarder address of the SwapCreator
+// contract at the given address and checks that it has the expected forwarder
+// bytecode. If the check fails, the forwarder address is returned along with an
+// error wrapping ErrInvalidForwarderContract.
+func VerifyTrustedForwarder(
+	ctx context.Context,
+	ec *ethclient.Client,
+	swapCreatorAddr ethcommon.Address,
+) (ethcommon.Address, error) {
+	swapCreator, err := NewSwapCreator(swapCreatorAddr, ec)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	forwarderAddr, err := swapCreator.TrustedForwarder(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return ethcommon.Address{}, fmt.Errorf("failed to get trusted forwarder: %w", err)
+	}
+
+	if (forwarderAddr == ethcommon.Address{}) {
+		return forwarderAddr, fmt.Errorf("%w: SwapCreator has no trusted forwarder", ErrInvalidForwarderContract)
+	}
+
+	err = CheckForwarderContractCode(ctx, ec, forwarderAddr)
+	if err != nil {
+		return forwarderAddr, fmt.Errorf("%w: %s", ErrInvalidForwarderContract, err)
+	}
+
+	return forwarderAddr, nil
+}
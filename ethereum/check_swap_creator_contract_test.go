@@ -100,6 +100,30 @@ func TestCheckSwapCreatorContractCode_fail(t *testing.T) {
 	require.ErrorIs(t, err, errInvalidSwapCreatorContract)
 }
 
+func TestVerifyTrustedForwarder(t *testing.T) {
+	ec, _ := tests.NewEthClient(t)
+	pk := tests.GetMakerTestKey(t)
+
+	forwarderAddr := deployForwarder(t, ec, pk)
+	swapCreatorAddr, _ := deploySwapCreatorWithForwarder(t, ec, pk, forwarderAddr)
+	parsedTFAddr, err := VerifyTrustedForwarder(context.Background(), ec, swapCreatorAddr)
+	require.NoError(t, err)
+	require.Equal(t, forwarderAddr, parsedTFAddr)
+}
+
+// Tests that we fail when the SwapCreator's trusted forwarder is not a forwarder
+func TestVerifyTrustedForwarder_fail(t *testing.T) {
+	ec, _ := tests.NewEthClient(t)
+	pk := tests.GetMakerTestKey(t)
+
+	// use another SwapCreator contract as the trusted forwarder
+	notForwarderAddr, _ := deploySwapCreatorWithForwarder(t, ec, pk, deployForwarder(t, ec, pk))
+	swapCreatorAddr, _ := deploySwapCreatorWithForwarder(t, ec, pk, notForwarderAddr)
+	parsedTFAddr, err := VerifyTrustedForwarder(context.Background(), ec, swapCreatorAddr)
+	require.ErrorIs(t, err, ErrInvalidForwarderContract)
+	require.Equal(t, notForwarderAddr, parsedTFAddr)
+}
+
 func TestSepoliaContract(t *testing.T) {
 	ctx := context.Background()
 	ec := tests.NewEthSepoliaClient(t)
@@ -8,8 +8,10 @@ package extethclient
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"sync"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	logging "github.com/ipfs/go-log"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -54,6 +57,8 @@ type EthClient interface {
 	WaitForReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
 	WaitForTimestamp(ctx context.Context, ts time.Time) error
 	LatestBlockTimestamp(ctx context.Context) (time.Time, error)
+	IsSynced(ctx context.Context) (bool, error)
+	SyncStatus(ctx context.Context) (*SyncStatus, error)
 
 	Close()
 	Raw() *ethclient.Client
@@ -61,6 +66,7 @@ type EthClient interface {
 
 type ethClient struct {
 	endpoint   string
+	failover   *failoverTransport // nil unless there are multiple endpoints
 	ec         *ethclient.Client
 	ethPrivKey *ecdsa.PrivateKey
 	ethAddress ethcommon.Address
@@ -78,7 +84,34 @@ func NewEthClient(
 	endpoint string,
 	privKey *ecdsa.PrivateKey,
 ) (EthClient, error) {
-	ec, err := ethclient.Dial(endpoint)
+	return NewFailoverEthClient(ctx, env, []string{endpoint}, privKey)
+}
+
+// NewFailoverEthClient is the same as NewEthClient, but requests are sent to
+// the first of the endpoints until it becomes unreachable or responds with
+// server errors, at which point they fail over to the next endpoint. This also
+// applies to requests made with the raw go-ethereum client. Failing over
+// between multiple endpoints requires them to use http or https.
+func NewFailoverEthClient(
+	ctx context.Context,
+	env common.Environment,
+	endpoints []string,
+	privKey *ecdsa.PrivateKey,
+) (EthClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no ethereum endpoints given")
+	}
+
+	var (
+		ec       *ethclient.Client
+		failover *failoverTransport
+		err      error
+	)
+	if len(endpoints) == 1 {
+		ec, err = ethclient.Dial(endpoints[0])
+	} else {
+		ec, failover, err = dialFailover(ctx, env, endpoints)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +131,8 @@ func NewEthClient(
 	}
 
 	return &ethClient{
-		endpoint:   endpoint,
+		endpoint:   endpoints[0],
+		failover:   failover,
 		ec:         ec,
 		ethPrivKey: privKey,
 		ethAddress: addr,
@@ -106,6 +140,45 @@ func NewEthClient(
 	}, nil
 }
 
+// dialFailover returns a client that fails over between the endpoints. Each
+// endpoint that is reachable must be on the environment's chain. Unreachable
+// endpoints are only used once they become reachable.
+func dialFailover(
+	ctx context.Context,
+	env common.Environment,
+	endpoints []string,
+) (*ethclient.Client, *failoverTransport, error) {
+	failover, err := newFailoverTransport(endpoints)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, endpoint := range endpoints {
+		ec, err := ethclient.DialContext(ctx, endpoint) //nolint:govet
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chainID, err := ec.ChainID(ctx)
+		ec.Close()
+		if err != nil {
+			log.Warnf("could not check chain ID of ethereum endpoint %s: %s", endpoint, err)
+			continue
+		}
+
+		if err = validateChainID(env, chainID); err != nil {
+			return nil, nil, fmt.Errorf("ethereum endpoint %s: %w", endpoint, err)
+		}
+	}
+
+	rpcClient, err := rpc.DialHTTPWithClient(endpoints[0], &http.Client{Transport: failover})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ethclient.NewClient(rpcClient), failover, nil
+}
+
 func (c *ethClient) Address() ethcommon.Address {
 	return c.ethAddress
 }
@@ -127,6 +200,9 @@ func (c *ethClient) HasPrivateKey() bool {
 
 // Endpoint returns the endpoint URL that we are connected to
 func (c *ethClient) Endpoint() string {
+	if c.failover != nil {
+		return c.failover.endpoint()
+	}
 	return c.endpoint
 }
 
@@ -244,6 +320,20 @@ func (c *ethClient) TxOpts(ctx context.Context) (*bind.TransactOpts, error) {
 	txOpts.GasPrice = c.gasPrice
 	txOpts.GasLimit = c.gasLimit
 
+	if c.failover != nil {
+		// after failing over, the endpoint may not have seen our transactions
+		// that were sent through another endpoint yet
+		nonce, err := c.ec.PendingNonceAt(ctx, c.ethAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		if minNonce := c.failover.minNonce(); nonce < minNonce {
+			nonce = minNonce
+		}
+		txOpts.Nonce = new(big.Int).SetUint64(nonce)
+	}
+
 	return txOpts, nil
 }
 
@@ -278,6 +368,47 @@ func (c *ethClient) LatestBlockTimestamp(ctx context.Context) (time.Time, error)
 	return time.Unix(int64(hdr.Time), 0), nil
 }
 
+// IsSynced returns whether the Ethereum node is done syncing with the chain.
+func (c *ethClient) IsSynced(ctx context.Context) (bool, error) {
+	progress, err := c.ec.SyncProgress(ctx)
+	if err != nil {
+		return false, err
+	}
+	// the node reports no sync progress when it is not syncing
+	return progress == nil, nil
+}
+
+// SyncStatus holds how far the Ethereum node is synced.
+type SyncStatus struct {
+	// HeadBlock is the number of the latest block that the node has.
+	HeadBlock uint64
+	// HighestBlock is the number of the highest block of the chain that the
+	// node is syncing to. It is equal to HeadBlock once the node is synced.
+	HighestBlock uint64
+	IsSynced     bool
+}
+
+// SyncStatus returns the node's head block number and, if it is syncing, the
+// block number that it is syncing to.
+func (c *ethClient) SyncStatus(ctx context.Context) (*SyncStatus, error) {
+	progress, err := c.ec.SyncProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := c.ec.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the node reports no sync progress when it is not syncing
+	if progress == nil {
+		return &SyncStatus{HeadBlock: head, HighestBlock: head, IsSynced: true}, nil
+	}
+
+	return &SyncStatus{HeadBlock: head, HighestBlock: progress.HighestBlock}, nil
+}
+
 func (c *ethClient) Lock() {
 	c.mu.Lock()
 }
@@ -0,0 +1,197 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const sendRawTransactionMethod = "eth_sendRawTransaction"
+
+var errNoHTTPEndpoint = errors.New("endpoints must use http or https to fail over between them")
+
+// failoverTransport is an http.RoundTripper that sends each JSON-RPC request to
+// the current endpoint. If the request cannot be sent, or the endpoint responds
+// with a server error, the request is sent to the next endpoint, which becomes
+// the current endpoint if it succeeds.
+//
+// As each endpoint may not have seen the transactions that we sent through the
+// others yet, the transport also tracks the nonce following our last
+// transaction that an endpoint accepted.
+type failoverTransport struct {
+	base      http.RoundTripper
+	endpoints []*url.URL
+
+	mu        sync.Mutex
+	current   int
+	nextNonce uint64
+}
+
+func newFailoverTransport(endpoints []string) (*failoverTransport, error) {
+	t := &failoverTransport{base: http.DefaultTransport}
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("%w: %s", errNoHTTPEndpoint, endpoint)
+		}
+
+		t.endpoints = append(t.endpoints, u)
+	}
+
+	return t, nil
+}
+
+// endpoint returns the URL of the current endpoint.
+func (t *failoverTransport) endpoint() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endpoints[t.current].String()
+}
+
+// minNonce returns the nonce following our last transaction that any of the
+// endpoints accepted.
+func (t *failoverTransport) minNonce() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.nextNonce
+}
+
+// RoundTrip sends the request to the current endpoint, then to each of the
+// other endpoints in turn until one succeeds.
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	start := t.current
+	t.mu.Unlock()
+
+	var lastErr error
+	for i := 0; i < len(t.endpoints); i++ {
+		idx := (start + i) % len(t.endpoints)
+		resp, err := t.send(req, body, t.endpoints[idx])
+		if err == nil {
+			t.setCurrent(start, idx)
+			return t.recordNonce(body, resp)
+		}
+
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		log.Warnf("ethereum endpoint %s failed: %s", t.endpoints[idx].Redacted(), err)
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// send sends the request body to the endpoint. Server errors are returned as
+// errors, so that the request is sent to the next endpoint.
+func (t *failoverTransport) send(req *http.Request, body []byte, endpoint *url.URL) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL = endpoint
+	r.Host = endpoint.Host
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// setCurrent makes the endpoint at idx the current endpoint, unless another
+// request already changed the current endpoint since this request started.
+func (t *failoverTransport) setCurrent(start int, idx int) {
+	if idx == start {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != start {
+		return
+	}
+
+	t.current = idx
+	log.Infof("failed over to ethereum endpoint %s", t.endpoints[idx].Redacted())
+}
+
+type jsonrpcRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type jsonrpcResponse struct {
+	Error *json.RawMessage `json:"error"`
+}
+
+// recordNonce updates the tracked nonce if the request was a transaction that
+// the endpoint accepted. The response is returned with its body intact.
+func (t *failoverTransport) recordNonce(reqBody []byte, resp *http.Response) (*http.Response, error) {
+	var req jsonrpcRequest
+	if err := json.Unmarshal(reqBody, &req); err != nil || req.Method != sendRawTransactionMethod {
+		// batch requests are not used to send transactions
+		return resp, nil
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	var rpcResp jsonrpcResponse
+	if err = json.Unmarshal(respBody, &rpcResp); err != nil || rpcResp.Error != nil || len(req.Params) != 1 {
+		return resp, nil
+	}
+
+	var rawTx hexutil.Bytes
+	if err = json.Unmarshal(req.Params[0], &rawTx); err != nil {
+		return resp, nil
+	}
+
+	tx := new(ethtypes.Transaction)
+	if err = tx.UnmarshalBinary(rawTx); err != nil {
+		return resp, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tx.Nonce()+1 > t.nextNonce {
+		t.nextNonce = tx.Nonce() + 1
+	}
+
+	return resp, nil
+}
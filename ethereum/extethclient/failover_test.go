@@ -0,0 +1,106 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEndpoint returns an endpoint that responds to each request with the
+// given status and body, and a counter of the requests it received.
+func newTestEndpoint(t *testing.T, status int, body string) (string, *atomic.Int32) {
+	calls := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, calls
+}
+
+func postJSONRPC(t *testing.T, transport *failoverTransport, body string) string {
+	client := &http.Client{Transport: transport}
+	resp, err := client.Post("http://unused", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(respBody)
+}
+
+func TestFailoverTransport(t *testing.T) {
+	const result = `{"jsonrpc":"2.0","id":1,"result":"0x1"}`
+	down, downCalls := newTestEndpoint(t, http.StatusServiceUnavailable, "")
+	up, upCalls := newTestEndpoint(t, http.StatusOK, result)
+
+	transport, err := newFailoverTransport([]string{down, up})
+	require.NoError(t, err)
+
+	req := `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`
+	require.Equal(t, result, postJSONRPC(t, transport, req))
+	require.Equal(t, up, transport.endpoint())
+
+	// later requests go straight to the endpoint that succeeded
+	require.Equal(t, result, postJSONRPC(t, transport, req))
+	require.Equal(t, int32(1), downCalls.Load())
+	require.Equal(t, int32(2), upCalls.Load())
+}
+
+func TestFailoverTransport_allDown(t *testing.T) {
+	down1, _ := newTestEndpoint(t, http.StatusBadGateway, "")
+	down2, _ := newTestEndpoint(t, http.StatusTooManyRequests, "")
+
+	transport, err := newFailoverTransport([]string{down1, down2})
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: transport}
+	_, err = client.Post("http://unused", "application/json", strings.NewReader("{}"))
+	require.ErrorContains(t, err, "429")
+}
+
+func TestFailoverTransport_recordsNonce(t *testing.T) {
+	pk, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	tx := ethtypes.NewTransaction(7, [20]byte{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	signedTx, err := ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(big.NewInt(1)), pk)
+	require.NoError(t, err)
+	rawTx, err := signedTx.MarshalBinary()
+	require.NoError(t, err)
+	req := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["%s"]}`,
+		hexutil.Encode(rawTx))
+
+	// a rejected transaction is not recorded
+	rejecting, _ := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000}}`)
+	transport, err := newFailoverTransport([]string{rejecting})
+	require.NoError(t, err)
+	postJSONRPC(t, transport, req)
+	require.Equal(t, uint64(0), transport.minNonce())
+
+	accepting, _ := newTestEndpoint(t, http.StatusOK, fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":"%s"}`,
+		signedTx.Hash()))
+	transport, err = newFailoverTransport([]string{accepting})
+	require.NoError(t, err)
+	postJSONRPC(t, transport, req)
+	require.Equal(t, uint64(8), transport.minNonce())
+}
+
+func TestNewFailoverTransport_notHTTP(t *testing.T) {
+	_, err := newFailoverTransport([]string{"http://localhost:8545", "ws://localhost:8546"})
+	require.ErrorIs(t, err, errNoHTTPEndpoint)
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package extethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// maxReplacementAttempts is the number of times the gas price of a
+	// replacement transaction is doubled before giving up on clearing a nonce.
+	maxReplacementAttempts = 4
+
+	// replacementUnderpricedErr is the error substring returned by nodes when a
+	// replacement transaction's gas price isn't high enough.
+	replacementUnderpricedErr = "underpriced"
+
+	// nonceTooLowErr is the error substring returned by nodes when a
+	// transaction with the nonce was already mined.
+	nonceTooLowErr = "nonce too low"
+)
+
+var errReplacementAboveMaxGasPrice = errors.New("gas price needed to replace stuck transaction is above the maximum")
+
+// PendingNonces returns our account's nonce at the latest block and its
+// pending nonce. If the pending nonce is greater, transactions that we sent
+// with the nonces in between have not been mined yet.
+func PendingNonces(ctx context.Context, ec EthClient) (latest uint64, pending uint64, err error) {
+	latest, err = ec.Raw().NonceAt(ctx, ec.Address(), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pending, err = ec.Raw().PendingNonceAt(ctx, ec.Address())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return latest, pending, nil
+}
+
+// ClearStuckTxs replaces each of our transactions that has not been mined with
+// a zero-value transfer to ourselves, so that the nonces they hold are freed
+// up for new transactions. The replacements start at twice the suggested gas
+// price, which is doubled each time a node rejects a replacement as
+// underpriced. If maxGasPrice is not nil, a replacement is never sent above it.
+// ClearStuckTxs returns once all replacements have been mined.
+func ClearStuckTxs(ctx context.Context, ec EthClient, maxGasPrice *big.Int) error {
+	if !ec.HasPrivateKey() {
+		return errors.New("cannot clear stuck transactions when using an external signer")
+	}
+
+	ec.Lock()
+	defer ec.Unlock()
+
+	latest, pending, err := PendingNonces(ctx, ec)
+	if err != nil {
+		return err
+	}
+
+	var txs []*ethtypes.Transaction
+	for nonce := latest; nonce < pending; nonce++ {
+		tx, err := replaceNonce(ctx, ec, nonce, maxGasPrice) //nolint:govet
+		if err != nil {
+			return fmt.Errorf("failed to replace stuck transaction with nonce %d: %w", nonce, err)
+		}
+		if tx != nil {
+			txs = append(txs, tx)
+		}
+	}
+
+	for _, tx := range txs {
+		if _, err = ec.WaitForReceipt(ctx, tx.Hash()); err != nil {
+			return fmt.Errorf("failed to get receipt of replacement transaction %s: %w", tx.Hash(), err)
+		}
+		log.Infof("cleared stuck transaction with nonce %d, replaced by %s", tx.Nonce(), tx.Hash())
+	}
+
+	return nil
+}
+
+// replaceNonce sends a zero-value transfer to ourselves with the given nonce.
+// If the stuck transaction was mined in the meantime, no transfer is sent and
+// nil is returned. The caller must hold the client's lock.
+func replaceNonce(ctx context.Context, ec EthClient, nonce uint64, maxGasPrice *big.Int) (*ethtypes.Transaction, error) {
+	const transferGas = 21000
+
+	suggested, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := new(big.Int).Mul(suggested, big.NewInt(2))
+	for attempt := 1; ; attempt++ {
+		if maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+			return nil, fmt.Errorf("%w: %s wei, maximum %s wei", errReplacementAboveMaxGasPrice, gasPrice, maxGasPrice)
+		}
+
+		tx := ethtypes.NewTransaction(nonce, ec.Address(), new(big.Int), transferGas, gasPrice, nil)
+		signedTx, err := ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(ec.ChainID()), ec.PrivateKey())
+		if err != nil {
+			return nil, err
+		}
+
+		err = ec.Raw().SendTransaction(ctx, signedTx)
+		if err == nil {
+			log.Infof("sent replacement transaction %s for stuck nonce %d with gas price %s wei",
+				signedTx.Hash(), nonce, gasPrice)
+			return signedTx, nil
+		}
+
+		if strings.Contains(err.Error(), nonceTooLowErr) {
+			log.Infof("stuck transaction with nonce %d was mined", nonce)
+			return nil, nil
+		}
+
+		if !strings.Contains(err.Error(), replacementUnderpricedErr) || attempt == maxReplacementAttempts {
+			return nil, err
+		}
+
+		gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(2))
+	}
+}
@@ -168,6 +168,11 @@ func testNewSwap(t *testing.T, asset types.EthAsset, erc20Contract *TestERC20) {
 
 	// validate our off-net calculation of the SwapID
 	require.Equal(t, types.Hash(swapID).Hex(), swap.SwapID().Hex())
+
+	// the contract stores the swap's stage under the same ID
+	stage, err := swapCreator.Swaps(nil, swap.SwapID())
+	require.NoError(t, err)
+	require.Equal(t, StagePending, stage)
 }
 
 func TestSwapCreator_NewSwap(t *testing.T) {
@@ -238,10 +238,14 @@ func GetTimeoutsFromLog(log *ethtypes.Log) (*big.Int, *big.Int, error) {
 }
 
 // GenerateNewSwapNonce generates a random nonce value for use with NewSwap
-// transactions.
+// transactions. The nonce is a uniformly random uint256, so the chance of two swaps
+// ever sharing a nonce is negligible, and no record of past nonces is kept. Even
+// then, the swap ID also commits to the per-swap keys, and the contract rejects a
+// swap whose ID already exists with SwapAlreadyExists.
 func GenerateNewSwapNonce() *big.Int {
-	u256PlusOne := new(big.Int).Lsh(big.NewInt(1), 256)
-	maxU256 := new(big.Int).Sub(u256PlusOne, big.NewInt(1))
-	n, _ := rand.Int(rand.Reader, maxU256)
-	return n
+	var n [32]byte
+	if _, err := rand.Read(n[:]); err != nil {
+		panic(err)
+	}
+	return new(big.Int).SetBytes(n[:])
 }
@@ -21,3 +21,21 @@ func TestStage_StageToString(t *testing.T) {
 		require.Equal(t, expectedValues[s], StageToString(s))
 	}
 }
+
+func TestGenerateNewSwapNonce_Unique(t *testing.T) {
+	const numNonces = 10000
+	seen := make(map[string]struct{}, numNonces)
+
+	for i := 0; i < numNonces; i++ {
+		nonce := GenerateNewSwapNonce()
+		require.LessOrEqual(t, nonce.BitLen(), 256)
+		// fails with probability 2^-128 for a uniform uint256, but catches a
+		// generator that only fills part of the nonce
+		require.Greater(t, nonce.BitLen(), 128)
+
+		key := nonce.String()
+		_, has := seen[key]
+		require.False(t, has, "duplicate nonce %s", key)
+		seen[key] = struct{}{}
+	}
+}
@@ -7,40 +7,62 @@ package watcher
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
 	eth "github.com/ethereum/go-ethereum"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	logging "github.com/ipfs/go-log"
 )
 
 const (
 	checkForBlocksTimeout = time.Second
+
+	// maxRetryBackoff caps the time waited before retrying after a failed
+	// request, which doubles with each consecutive failure.
+	maxRetryBackoff = time.Minute
 )
 
 var (
 	log = logging.Logger("ethereum/watcher")
 )
 
+// Client is the subset of the ethereum client's methods used by the
+// EventFilter.
+type Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+	FilterLogs(ctx context.Context, q eth.FilterQuery) ([]ethtypes.Log, error)
+}
+
 // EventFilter filters the chain for specific events (logs).
-// When it finds a desired log, it puts it into its outbound channel.
+// When it finds a desired log, it puts it into its outbound channel. If the
+// channel is full, the filter waits until there is room or it is stopped, so
+// logs are never dropped and a stopped filter never blocks.
+// Failed requests to the ethereum client are retried with exponential backoff
+// for as long as the filter runs, as swaps with locked funds depend on seeing
+// their logs. The filter only gives up, sending the error on its error channel,
+// if the client was closed.
 type EventFilter struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
-	ec          *ethclient.Client
+	ec          Client
 	topic       ethcommon.Hash
 	filterQuery eth.FilterQuery
 	logCh       chan<- ethtypes.Log
+	errCh       chan error
+
+	// overridden by tests
+	pollInterval    time.Duration
+	maxRetryBackoff time.Duration
 }
 
 // NewEventFilter returns a new *EventFilter.
 func NewEventFilter(
 	ctx context.Context,
-	ec *ethclient.Client,
+	ec Client,
 	contract ethcommon.Address,
 	fromBlock *big.Int,
 	topic ethcommon.Hash,
@@ -53,68 +75,122 @@ func NewEventFilter(
 
 	ctx, cancel := context.WithCancel(ctx)
 	return &EventFilter{
-		ctx:         ctx,
-		cancel:      cancel,
-		ec:          ec,
-		topic:       topic,
-		filterQuery: filterQuery,
-		logCh:       logCh,
+		ctx:             ctx,
+		cancel:          cancel,
+		ec:              ec,
+		topic:           topic,
+		filterQuery:     filterQuery,
+		logCh:           logCh,
+		errCh:           make(chan error, 1),
+		pollInterval:    checkForBlocksTimeout,
+		maxRetryBackoff: maxRetryBackoff,
 	}
 }
 
 // Start starts the EventFilter. It watches the chain for logs.
 func (f *EventFilter) Start() error {
-	go func() {
-		for {
-			select {
-			case <-f.ctx.Done():
-				return
-			case <-time.After(checkForBlocksTimeout):
-			}
-
-			currHeader, err := f.ec.HeaderByNumber(f.ctx, nil)
-			if err != nil {
-				log.Errorf("failed to get header in event watcher: %s", err)
-				if errors.Is(err, ethrpc.ErrClientQuit) {
-					return // non-recoverable error
-				}
-				continue
-			}
-
-			if currHeader.Number.Cmp(f.filterQuery.FromBlock) <= 0 {
-				// no new blocks, don't do anything
-				continue
-			}
-
-			// let's see if we have logs
-			logs, err := f.ec.FilterLogs(f.ctx, f.filterQuery)
-			if err != nil {
-				log.Errorf("failed to filter logs for topic %s: %s", f.topic, err)
-				continue
-			}
-
-			// If you think we are missing log events, uncomment to debug:
-			// log.Debugf("filtered for logs from block %s to block %s",
-			// 	f.filterQuery.FromBlock, currHeader.Number)
-
-			for _, l := range logs {
-				if l.Topics[0] != f.topic {
-					continue
-				}
-
-				if l.Removed {
-					log.Debugf("found removed log: tx hash %s", l.TxHash)
-					continue
-				}
-
-				log.Debugf("watcher for topic %s found log in block %d", f.topic, l.BlockNumber)
-				f.logCh <- l
-			}
-
-			f.filterQuery.FromBlock = currHeader.Number
+	go f.run()
+	return nil
+}
+
+// Err returns the channel on which the filter sends the error that made it
+// give up, which only happens if the ethereum client was closed. No error is
+// sent if the filter is stopped or its context is cancelled.
+func (f *EventFilter) Err() <-chan error {
+	return f.errCh
+}
+
+func (f *EventFilter) run() {
+	wait := f.pollInterval
+	failures := 0
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-time.After(wait):
 		}
-	}()
 
+		err := f.checkForLogs()
+		if f.ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			failures = 0
+			wait = f.pollInterval
+			continue
+		}
+
+		if errors.Is(err, ethrpc.ErrClientQuit) {
+			// non-recoverable error
+			log.Errorf("event watcher for topic %s failed: %s", f.topic, err)
+			f.errCh <- fmt.Errorf("event watcher for topic %s failed: %w", f.topic, err)
+			return
+		}
+
+		failures++
+		wait = f.retryBackoff(failures)
+		log.Warnf("event watcher for topic %s failed (attempt %d), retrying in %s: %s",
+			f.topic, failures, wait, err)
+	}
+}
+
+// retryBackoff returns the time to wait after the given number of consecutive
+// failures, which doubles with each failure up to maxRetryBackoff.
+func (f *EventFilter) retryBackoff(failures int) time.Duration {
+	wait := f.pollInterval
+	for i := 1; i < failures && wait < f.maxRetryBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > f.maxRetryBackoff {
+		return f.maxRetryBackoff
+	}
+	return wait
+}
+
+// checkForLogs sends the logs of the filter's topic in the blocks since the
+// last check to the outbound channel.
+func (f *EventFilter) checkForLogs() error {
+	currHeader, err := f.ec.HeaderByNumber(f.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get header: %w", err)
+	}
+
+	if currHeader.Number.Cmp(f.filterQuery.FromBlock) <= 0 {
+		// no new blocks, don't do anything
+		return nil
+	}
+
+	// let's see if we have logs
+	logs, err := f.ec.FilterLogs(f.ctx, f.filterQuery)
+	if err != nil {
+		return fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	// If you think we are missing log events, uncomment to debug:
+	// log.Debugf("filtered for logs from block %s to block %s",
+	// 	f.filterQuery.FromBlock, currHeader.Number)
+
+	for _, l := range logs {
+		if l.Topics[0] != f.topic {
+			continue
+		}
+
+		if l.Removed {
+			log.Debugf("found removed log: tx hash %s", l.TxHash)
+			continue
+		}
+
+		log.Debugf("watcher for topic %s found log in block %d", f.topic, l.BlockNumber)
+		select {
+		case f.logCh <- l:
+		case <-f.ctx.Done():
+			return f.ctx.Err()
+		}
+	}
+
+	f.filterQuery.FromBlock = currHeader.Number
 	return nil
 }
 
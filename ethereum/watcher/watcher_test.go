@@ -0,0 +1,127 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+var testTopic = ethcommon.Hash{0x1}
+
+// mockClient fails its first numFailures requests for the latest header with
+// err, or a connection refused error if err is not set.
+type mockClient struct {
+	mu          sync.Mutex
+	numFailures int
+	err         error
+	calls       int
+	logs        []ethtypes.Log
+}
+
+func (c *mockClient) HeaderByNumber(_ context.Context, _ *big.Int) (*ethtypes.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.calls <= c.numFailures {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, errors.New("connection refused")
+	}
+
+	return &ethtypes.Header{Number: big.NewInt(10)}, nil
+}
+
+func (c *mockClient) FilterLogs(_ context.Context, _ eth.FilterQuery) ([]ethtypes.Log, error) {
+	return c.logs, nil
+}
+
+func (c *mockClient) numCalls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func newTestEventFilter(t *testing.T, ec Client, logCh chan<- ethtypes.Log) *EventFilter {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	f := NewEventFilter(ctx, ec, ethcommon.Address{}, big.NewInt(1), testTopic, logCh)
+	f.pollInterval = time.Millisecond
+	f.maxRetryBackoff = 4 * time.Millisecond
+	return f
+}
+
+func TestEventFilter_retries(t *testing.T) {
+	ec := &mockClient{
+		numFailures: 3,
+		logs:        []ethtypes.Log{{Topics: []ethcommon.Hash{testTopic}, BlockNumber: 5}},
+	}
+	logCh := make(chan ethtypes.Log)
+	f := newTestEventFilter(t, ec, logCh)
+	require.NoError(t, f.Start())
+
+	select {
+	case l := <-logCh:
+		require.Equal(t, uint64(5), l.BlockNumber)
+	case err := <-f.Err():
+		t.Fatalf("event filter failed: %s", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for log")
+	}
+
+	f.Stop()
+}
+
+func TestEventFilter_neverGivesUp(t *testing.T) {
+	ec := &mockClient{numFailures: 100}
+	f := newTestEventFilter(t, ec, make(chan ethtypes.Log))
+	require.NoError(t, f.Start())
+
+	// the filter keeps retrying, however many requests fail
+	require.Eventually(t, func() bool { return ec.numCalls() > 20 }, 5*time.Second, time.Millisecond)
+	select {
+	case err := <-f.Err():
+		t.Fatalf("event filter gave up: %s", err)
+	default:
+	}
+
+	f.Stop()
+}
+
+func TestEventFilter_clientQuit(t *testing.T) {
+	ec := &mockClient{numFailures: 100, err: ethrpc.ErrClientQuit}
+	f := newTestEventFilter(t, ec, make(chan ethtypes.Log))
+	require.NoError(t, f.Start())
+
+	select {
+	case err := <-f.Err():
+		require.ErrorIs(t, err, ethrpc.ErrClientQuit)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event filter to give up")
+	}
+
+	require.Equal(t, 1, ec.numCalls())
+}
+
+func TestEventFilter_retryBackoff(t *testing.T) {
+	f := &EventFilter{pollInterval: time.Second, maxRetryBackoff: 5 * time.Second}
+	require.Equal(t, time.Second, f.retryBackoff(1))
+	require.Equal(t, 2*time.Second, f.retryBackoff(2))
+	require.Equal(t, 4*time.Second, f.retryBackoff(3))
+	require.Equal(t, 5*time.Second, f.retryBackoff(4))
+	require.Equal(t, 5*time.Second, f.retryBackoff(20))
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"context"
+	"errors"
+	"io"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how requests to monero-wallet-rpc are retried while
+// the wallet RPC server is unavailable, for example because it is restarting.
+// The zero value doesn't retry.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried after its first
+	// attempt.
+	MaxRetries uint
+	// BaseDelay is the delay before the first retry. It doubles with each
+	// further retry.
+	BaseDelay time.Duration
+}
+
+// maxRetryDelay caps the delay between retries, however many there are.
+const maxRetryDelay = time.Minute
+
+// isWalletUnreachableError returns true if the request was not delivered to
+// monero-wallet-rpc, as no connection could be made, so it is safe to retry
+// any request, including transfers.
+func isWalletUnreachableError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isWalletConnectionError returns true if the connection to monero-wallet-rpc
+// failed before or during the request. Requests that don't change the wallet
+// can be retried after such an error.
+func isWalletConnectionError(err error) bool {
+	return isWalletUnreachableError(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// withRetry calls fn until it succeeds, returns an error that isn't retryable,
+// or the retries of the client's policy are exhausted. The last error is
+// returned.
+func (c *walletClient) withRetry(
+	ctx context.Context,
+	name string,
+	retryable func(error) bool,
+	fn func() error,
+) error {
+	delay := c.retryPolicy.BaseDelay
+	for attempt := uint(0); ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.retryPolicy.MaxRetries || !retryable(err) {
+			return err
+		}
+
+		log.Warnf("monero-wallet-rpc %s request failed, retry %d of %d in %s: %s",
+			name, attempt+1, c.retryPolicy.MaxRetries, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
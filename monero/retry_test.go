@@ -0,0 +1,81 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package monero
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gorilla/rpc/v2/json2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestWalletUnreachableErrors(t *testing.T) {
+	port, err := common.GetFreeTCPPort()
+	require.NoError(t, err)
+
+	// nothing listens on the port, so the connection is refused
+	_, dialErr := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.Error(t, dialErr)
+	require.True(t, isWalletUnreachableError(dialErr))
+	require.True(t, isWalletConnectionError(dialErr))
+
+	rpcErr := &json2.Error{Code: walletErrNoDaemonConnection, Message: "no connection to daemon"}
+	require.False(t, isWalletUnreachableError(rpcErr))
+	require.False(t, isWalletConnectionError(rpcErr))
+}
+
+func TestWalletClient_withRetry(t *testing.T) {
+	c := &walletClient{
+		retryPolicy: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	}
+	retryable := errors.New("retryable")
+	isRetryable := func(err error) bool { return errors.Is(err, retryable) }
+
+	// succeeds on the last retry
+	attempts := 0
+	err := c.withRetry(context.Background(), "test", isRetryable, func() error {
+		attempts++
+		if attempts < 3 {
+			return retryable
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	// retries are exhausted
+	attempts = 0
+	err = c.withRetry(context.Background(), "test", isRetryable, func() error {
+		attempts++
+		return retryable
+	})
+	require.ErrorIs(t, err, retryable)
+	require.Equal(t, 3, attempts)
+
+	// other errors are not retried
+	attempts = 0
+	err = c.withRetry(context.Background(), "test", isRetryable, func() error {
+		attempts++
+		return errors.New("other")
+	})
+	require.EqualError(t, err, "other")
+	require.Equal(t, 1, attempts)
+
+	// the zero policy doesn't retry
+	c.retryPolicy = RetryPolicy{}
+	attempts = 0
+	err = c.withRetry(context.Background(), "test", isRetryable, func() error {
+		attempts++
+		return retryable
+	})
+	require.ErrorIs(t, err, retryable)
+	require.Equal(t, 1, attempts)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/MarinX/monerorpc"
 	monerodaemon "github.com/MarinX/monerorpc/daemon"
 	"github.com/MarinX/monerorpc/wallet"
+	"github.com/gorilla/rpc/v2/json2"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -40,6 +41,22 @@ const (
 	SweepToSelfConfirmations = 2
 )
 
+// Error codes of monero-wallet-rpc (see wallet_rpc_server_error_codes.h) with
+// which the wallet rejects a transfer before creating a transaction, for
+// reasons that may go away if the transfer is retried later.
+const (
+	walletErrDaemonIsBusy           = -3
+	walletErrNotEnoughUnlockedMoney = -37
+	walletErrNoDaemonConnection     = -38
+)
+
+// ErrTransferNotSent is wrapped by errors from Transfer when the wallet
+// rejected the transfer with an error code that shows that no transaction was
+// sent, and the same transfer may succeed later. Other errors, such as a
+// timeout of the transfer request, leave it unknown whether a transaction was
+// sent.
+var ErrTransferNotSent = errors.New("transfer not sent")
+
 // WalletClient represents a monero-wallet-rpc client.
 type WalletClient interface {
 	GetAccounts() (*wallet.GetAccountsResponse, error)
@@ -53,6 +70,12 @@ type WalletClient interface {
 		amount *coins.PiconeroAmount,
 		numConfirmations uint64,
 	) (*wallet.Transfer, error)
+	FindTransferTo(
+		ctx context.Context,
+		to *mcrypto.Address,
+		accountIdx uint64,
+		numConfirmations uint64,
+	) (*wallet.Transfer, error)
 	SweepAll(
 		ctx context.Context,
 		to *mcrypto.Address,
@@ -62,6 +85,7 @@ type WalletClient interface {
 	CreateWalletConf(walletNamePrefix string) *WalletClientConf
 	WalletName() string
 	GetHeight() (uint64, error)
+	GetSyncStatus() (*SyncStatus, error)
 	Endpoint() string // URL on which the wallet is accepting RPC requests
 	Close()           // Close closes the client itself, including any open wallet
 	CloseAndRemoveWallet()
@@ -76,6 +100,7 @@ type WalletClientConf struct {
 	MonerodNodes        []*common.MoneroNode // Optional, defaulted from environment if nil
 	MoneroWalletRPCPath string               // optional, path to monero-rpc-binary
 	LogPath             string               // optional, default is dir(WalletFilePath)/../monero-wallet-rpc.log
+	RetryPolicy         RetryPolicy          // optional, zero value doesn't retry unavailable wallet RPC requests
 }
 
 // Fill fills in the optional configuration values (Port, MonerodNodes, MoneroWalletRPCPath,
@@ -134,6 +159,8 @@ type walletClient struct {
 	walletAddr *mcrypto.Address
 	conf       *WalletClientConf
 	rpcProcess *os.Process // monero-wallet-rpc process that we create
+
+	retryPolicy RetryPolicy
 }
 
 // NewWalletClient returns a WalletClient for a newly created monero-wallet-rpc process.
@@ -168,6 +195,7 @@ func NewWalletClient(conf *WalletClientConf) (WalletClient, error) {
 
 	c := NewThinWalletClient(validatedNode.Host, validatedNode.Port, conf.WalletPort).(*walletClient)
 	c.rpcProcess = proc
+	c.retryPolicy = conf.RetryPolicy
 
 	walletName := path.Base(conf.WalletFilePath)
 	if isNewWallet {
@@ -218,16 +246,27 @@ func (c *walletClient) WalletName() string {
 }
 
 func (c *walletClient) GetAccounts() (*wallet.GetAccountsResponse, error) {
-	return c.wRPC.GetAccounts(&wallet.GetAccountsRequest{})
+	var resp *wallet.GetAccountsResponse
+	err := c.withRetry(context.Background(), "get_accounts", isWalletConnectionError, func() (err error) {
+		resp, err = c.wRPC.GetAccounts(&wallet.GetAccountsRequest{})
+		return err
+	})
+	return resp, err
 }
 
 func (c *walletClient) GetBalance(idx uint64) (*wallet.GetBalanceResponse, error) {
 	if err := c.refresh(); err != nil {
 		return nil, err
 	}
-	return c.wRPC.GetBalance(&wallet.GetBalanceRequest{
-		AccountIndex: idx,
+
+	var resp *wallet.GetBalanceResponse
+	err := c.withRetry(context.Background(), "get_balance", isWalletConnectionError, func() (err error) {
+		resp, err = c.wRPC.GetBalance(&wallet.GetBalanceRequest{
+			AccountIndex: idx,
+		})
+		return err
 	})
+	return resp, err
 }
 
 // waitForReceipt waits for the passed monero transaction ID to receive numConfirmations
@@ -245,9 +284,13 @@ func (c *walletClient) waitForReceipt(req *waitForReceiptRequest) (*wallet.Trans
 
 	for {
 		// Wallet is already refreshed here, due to GetHeight above and WaitForBlocks below
-		transferResp, err := c.wRPC.GetTransferByTxid(&wallet.GetTransferByTxidRequest{
-			TxID:         req.TxID,
-			AccountIndex: req.AccountIdx,
+		var transferResp *wallet.GetTransferByTxidResponse
+		err = c.withRetry(req.Ctx, "get_transfer_by_txid", isWalletConnectionError, func() (err error) {
+			transferResp, err = c.wRPC.GetTransferByTxid(&wallet.GetTransferByTxidRequest{
+				TxID:         req.TxID,
+				AccountIndex: req.AccountIdx,
+			})
+			return err
 		})
 		if err != nil {
 			return nil, err
@@ -286,16 +329,25 @@ func (c *walletClient) Transfer(
 	}
 	amountStr := amount.AsMoneroString()
 	log.Infof("Transferring %s XMR to %s", amountStr, to)
-	reqResp, err := c.wRPC.Transfer(&wallet.TransferRequest{
-		Destinations: []wallet.Destination{{
-			Amount:  amt,
-			Address: to.String(),
-		}},
-		AccountIndex: accountIdx,
+	// a transfer is only retried if it didn't reach the wallet, as we can't
+	// otherwise know whether a transaction was sent
+	var reqResp *wallet.TransferResponse
+	err = c.withRetry(ctx, "transfer", isWalletUnreachableError, func() (err error) {
+		reqResp, err = c.wRPC.Transfer(&wallet.TransferRequest{
+			Destinations: []wallet.Destination{{
+				Amount:  amt,
+				Address: to.String(),
+			}},
+			AccountIndex: accountIdx,
+		})
+		return err
 	})
 	if err != nil {
 		log.Warnf("Transfer of %s XMR failed: %s", amountStr, err)
-		return nil, fmt.Errorf("transfer failed: %w", err)
+		if isTransferNotSentError(err) {
+			return nil, fmt.Errorf("%w: %w", ErrTransferNotSent, err)
+		}
+		return nil, err
 	}
 	log.Infof("Transfer of %s XMR initiated, TXID=%s", amountStr, reqResp.TxHash)
 	transfer, err := c.waitForReceipt(&waitForReceiptRequest{
@@ -315,6 +367,64 @@ func (c *walletClient) Transfer(
 	return transfer, nil
 }
 
+// isTransferNotSentError returns true if the error from a transfer request is a
+// wallet error showing that no transaction was sent.
+func isTransferNotSentError(err error) bool {
+	var rpcErr *json2.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+
+	switch rpcErr.Code {
+	case walletErrDaemonIsBusy, walletErrNotEnoughUnlockedMoney, walletErrNoDaemonConnection:
+		return true
+	default:
+		return false
+	}
+}
+
+// FindTransferTo looks for an outgoing transfer to the given address in the
+// wallet's confirmed and pending transfers. If one is found, it waits for the
+// transfer to have the requested number of confirmations before returning it.
+// Nil is returned if the wallet has no transfer to the address.
+func (c *walletClient) FindTransferTo(
+	ctx context.Context,
+	to *mcrypto.Address,
+	accountIdx uint64,
+	numConfirmations uint64,
+) (*wallet.Transfer, error) {
+	var resp *wallet.GetTransfersResponse
+	err := c.withRetry(ctx, "get_transfers", isWalletConnectionError, func() (err error) {
+		resp, err = c.wRPC.GetTransfers(&wallet.GetTransfersRequest{
+			Out:          true,
+			Pending:      true,
+			AccountIndex: accountIdx,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range append(resp.Out, resp.Pending...) {
+		for _, dest := range transfer.Destinations {
+			if dest.Address != to.String() {
+				continue
+			}
+
+			log.Infof("Found transfer TXID=%s to %s", transfer.TxID, to)
+			return c.waitForReceipt(&waitForReceiptRequest{
+				Ctx:              ctx,
+				TxID:             transfer.TxID,
+				NumConfirmations: numConfirmations,
+				AccountIdx:       accountIdx,
+			})
+		}
+	}
+
+	return nil, nil
+}
+
 func (c *walletClient) SweepAll(
 	ctx context.Context,
 	to *mcrypto.Address,
@@ -385,6 +495,7 @@ func (c *walletClient) CreateWalletConf(walletNamePrefix string) *WalletClientCo
 		MonerodNodes:        c.conf.MonerodNodes,
 		MoneroWalletRPCPath: c.conf.MoneroWalletRPCPath,
 		LogPath:             c.conf.LogPath,
+		RetryPolicy:         c.conf.RetryPolicy,
 	}
 	return conf
 }
@@ -420,6 +531,7 @@ func createWalletFromKeys(
 
 	c := NewThinWalletClient(monerodNode.Host, monerodNode.Port, conf.WalletPort).(*walletClient)
 	c.rpcProcess = proc
+	c.retryPolicy = conf.RetryPolicy
 	c.conf = conf
 	err = c.generateFromKeys(
 		privateSpendKey, // nil for a view-only wallet
@@ -533,14 +645,21 @@ func (c *walletClient) generateFromKeys(
 }
 
 func (c *walletClient) GetAddress(idx uint64) (*wallet.GetAddressResponse, error) {
-	return c.wRPC.GetAddress(&wallet.GetAddressRequest{
-		AccountIndex: idx,
+	var resp *wallet.GetAddressResponse
+	err := c.withRetry(context.Background(), "get_address", isWalletConnectionError, func() (err error) {
+		resp, err = c.wRPC.GetAddress(&wallet.GetAddressRequest{
+			AccountIndex: idx,
+		})
+		return err
 	})
+	return resp, err
 }
 
 func (c *walletClient) refresh() error {
-	_, err := c.wRPC.Refresh(&wallet.RefreshRequest{})
-	return err
+	return c.withRetry(context.Background(), "refresh", isWalletConnectionError, func() error {
+		_, err := c.wRPC.Refresh(&wallet.RefreshRequest{})
+		return err
+	})
 }
 
 func (c *walletClient) CreateWallet(filename, password string) error {
@@ -559,12 +678,44 @@ func (c *walletClient) PrimaryAddress() *mcrypto.Address {
 	return c.walletAddr
 }
 
+// WalletOwnsAddress returns true if the address is the primary address of the
+// client's wallet, or a subaddress of any of the wallet's accounts.
+func WalletOwnsAddress(c WalletClient, addr *mcrypto.Address) (bool, error) {
+	if addr.Equal(c.PrimaryAddress()) {
+		return true, nil
+	}
+
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return false, err
+	}
+
+	for _, account := range accounts.SubaddressAccounts {
+		resp, err := c.GetAddress(account.AccountIndex) //nolint:govet
+		if err != nil {
+			return false, err
+		}
+
+		for _, a := range resp.Addresses {
+			if a.Address == addr.String() {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 func (c *walletClient) GetHeight() (uint64, error) {
 	if err := c.refresh(); err != nil {
 		return 0, err
 	}
 
-	res, err := c.wRPC.GetHeight()
+	var res *wallet.GetHeightResponse
+	err := c.withRetry(context.Background(), "get_height", isWalletConnectionError, func() (err error) {
+		res, err = c.wRPC.GetHeight()
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -572,6 +723,46 @@ func (c *walletClient) GetHeight() (uint64, error) {
 	return res.Height, nil
 }
 
+// SyncStatus holds the heights of the wallet and of the monerod node that it
+// uses.
+type SyncStatus struct {
+	WalletHeight uint64
+	DaemonHeight uint64
+	// TargetHeight is the height of the chain that monerod is syncing to. It
+	// is equal to DaemonHeight once monerod is synced.
+	TargetHeight uint64
+	// IsSynced is true if monerod is synced and the wallet has caught up
+	// with it.
+	IsSynced bool
+}
+
+// GetSyncStatus returns the wallet's height after refreshing it and monerod's
+// height and target height.
+func (c *walletClient) GetSyncStatus() (*SyncStatus, error) {
+	info, err := c.dRPC.GetInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monerod info: %w", err)
+	}
+
+	walletHeight, err := c.GetHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// monerod reports a target height of zero when it is not syncing
+	targetHeight := info.TargetHeight
+	if targetHeight < info.Height {
+		targetHeight = info.Height
+	}
+
+	return &SyncStatus{
+		WalletHeight: walletHeight,
+		DaemonHeight: info.Height,
+		TargetHeight: targetHeight,
+		IsSynced:     info.Synchronized && walletHeight >= info.Height,
+	}, nil
+}
+
 // getChainHeight gets the blockchain height directly from the monero daemon instead
 // of the wallet height.
 func (c *walletClient) getChainHeight() (uint64, error) {
@@ -5,11 +5,13 @@ package monero
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path"
 	"testing"
 	"time"
 
+	"github.com/gorilla/rpc/v2/json2"
 	logging "github.com/ipfs/go-log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -63,6 +65,16 @@ func TestClient_Transfer(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, transfer.Confirmations, uint64(MinSpendConfirmations))
 	t.Logf("Bob's TX was mined at height %d with %d confirmations", transfer.Height, transfer.Confirmations)
+
+	// The wallet finds the sent transfer by its destination
+	found, err := cXMRMaker.FindTransferTo(ctx, abAddress, 0, MinSpendConfirmations)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	require.Equal(t, transfer.TxID, found.TxID)
+	found, err = cXMRMaker.FindTransferTo(ctx, cXMRMaker.PrimaryAddress(), 0, MinSpendConfirmations)
+	require.NoError(t, err)
+	require.Nil(t, found)
+
 	cXMRMaker.Close() // Done with bob, make sure no one uses him again
 	cXMRMaker = nil
 
@@ -136,6 +148,16 @@ func TestClient_Transfer(t *testing.T) {
 	require.Equal(t, balanceAlice.Balance, sweepAmount)
 }
 
+func Test_isTransferNotSentError(t *testing.T) {
+	notEnoughUnlocked := &json2.Error{Code: walletErrNotEnoughUnlockedMoney, Message: "not enough unlocked money"}
+	require.True(t, isTransferNotSentError(notEnoughUnlocked))
+	require.True(t, isTransferNotSentError(fmt.Errorf("wrapped: %w", notEnoughUnlocked)))
+
+	// the wallet may have sent the transfer if we don't know how the request ended
+	require.False(t, isTransferNotSentError(context.DeadlineExceeded))
+	require.False(t, isTransferNotSentError(&json2.Error{Code: json2.E_SERVER, Message: "unknown"}))
+}
+
 func Test_walletClient_SweepAll_nothingToSweepReturnsError(t *testing.T) {
 	emptyWallet := CreateWalletClient(t)
 	takerWallet := CreateWalletClient(t)
@@ -181,6 +203,19 @@ func TestClient_GetAccounts(t *testing.T) {
 	require.Equal(t, 1, len(resp.SubaddressAccounts))
 }
 
+func TestWalletOwnsAddress(t *testing.T) {
+	c := CreateWalletClient(t)
+	owned, err := WalletOwnsAddress(c, c.PrimaryAddress())
+	require.NoError(t, err)
+	require.True(t, owned)
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	owned, err = WalletOwnsAddress(c, kp.PublicKeyPair().Address(common.Development))
+	require.NoError(t, err)
+	require.False(t, owned)
+}
+
 func TestClient_GetHeight(t *testing.T) {
 	c, err := NewWalletClient(&WalletClientConf{
 		Env:                 common.Development,
@@ -196,6 +231,11 @@ func TestClient_GetHeight(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, chainHeight, walletHeight)
 	require.LessOrEqual(t, chainHeight-walletHeight, uint64(2))
+
+	status, err := c.GetSyncStatus()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, status.WalletHeight, walletHeight)
+	require.GreaterOrEqual(t, status.TargetHeight, status.DaemonHeight)
 }
 
 func TestCallGenerateFromKeys(t *testing.T) {
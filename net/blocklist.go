@@ -0,0 +1,115 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// BlocklistDB persists the blocked peers across restarts. It is implemented by
+// *db.Database.
+type BlocklistDB interface {
+	PutBlockedPeer(peerID peer.ID) error
+	DeleteBlockedPeer(peerID peer.ID) error
+	GetBlockedPeers() ([]peer.ID, error)
+}
+
+// blocklist holds the peers that we refuse to interact with. If db is nil, the
+// blocklist is only kept in memory.
+type blocklist struct {
+	mu    sync.RWMutex
+	db    BlocklistDB
+	peers map[peer.ID]struct{}
+}
+
+func newBlocklist(db BlocklistDB) (*blocklist, error) {
+	b := &blocklist{
+		db:    db,
+		peers: make(map[peer.ID]struct{}),
+	}
+
+	if db == nil {
+		return b, nil
+	}
+
+	peerIDs, err := db.GetBlockedPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, peerID := range peerIDs {
+		b.peers[peerID] = struct{}{}
+	}
+
+	return b, nil
+}
+
+func (b *blocklist) add(peerID peer.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.db != nil {
+		if err := b.db.PutBlockedPeer(peerID); err != nil {
+			return err
+		}
+	}
+
+	b.peers[peerID] = struct{}{}
+	return nil
+}
+
+func (b *blocklist) remove(peerID peer.ID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.db != nil {
+		if err := b.db.DeleteBlockedPeer(peerID); err != nil {
+			return err
+		}
+	}
+
+	delete(b.peers, peerID)
+	return nil
+}
+
+func (b *blocklist) has(peerID peer.ID) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, has := b.peers[peerID]
+	return has
+}
+
+// BlockPeer adds the peer to the blocklist. Incoming query and swap streams
+// from blocked peers are rejected, and blocked peers are left out of discovery
+// results and cannot be queried or swapped with. Ongoing swaps with the peer
+// are not affected.
+func (h *Host) BlockPeer(peerID peer.ID) error {
+	if peerID == h.PeerID() {
+		return errCannotBlockSelf
+	}
+
+	if err := h.blocklist.add(peerID); err != nil {
+		return err
+	}
+
+	log.Infof("blocked peer %s", peerID)
+	return nil
+}
+
+// UnblockPeer removes the peer from the blocklist.
+func (h *Host) UnblockPeer(peerID peer.ID) error {
+	if err := h.blocklist.remove(peerID); err != nil {
+		return err
+	}
+
+	log.Infof("unblocked peer %s", peerID)
+	return nil
+}
+
+// IsBlocked returns true if the peer is on the blocklist.
+func (h *Host) IsBlocked(peerID peer.ID) bool {
+	return h.blocklist.has(peerID)
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type mockBlocklistDB struct {
+	peers map[peer.ID]struct{}
+}
+
+func (db *mockBlocklistDB) PutBlockedPeer(peerID peer.ID) error {
+	db.peers[peerID] = struct{}{}
+	return nil
+}
+
+func (db *mockBlocklistDB) DeleteBlockedPeer(peerID peer.ID) error {
+	delete(db.peers, peerID)
+	return nil
+}
+
+func (db *mockBlocklistDB) GetBlockedPeers() ([]peer.ID, error) {
+	var peerIDs []peer.ID
+	for peerID := range db.peers {
+		peerIDs = append(peerIDs, peerID)
+	}
+	return peerIDs, nil
+}
+
+func TestHost_BlockPeer(t *testing.T) {
+	ha := newHost(t, basicTestConfig(t))
+	require.NoError(t, ha.Start())
+	hb := newHost(t, basicTestConfig(t))
+	require.NoError(t, hb.Start())
+
+	require.NoError(t, ha.h.Connect(ha.ctx, hb.h.AddrInfo()))
+
+	// blocked peers cannot be queried or swapped with
+	require.NoError(t, ha.BlockPeer(hb.PeerID()))
+	_, err := ha.Query(hb.PeerID())
+	require.ErrorIs(t, err, errPeerBlocked)
+	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), new(mockSwapState))
+	require.ErrorIs(t, err, errPeerBlocked)
+	require.NoError(t, ha.UnblockPeer(hb.PeerID()))
+
+	// queries from blocked peers are rejected
+	require.NoError(t, hb.BlockPeer(ha.PeerID()))
+	_, err = ha.Query(hb.PeerID())
+	require.Error(t, err)
+	require.NoError(t, hb.UnblockPeer(ha.PeerID()))
+
+	resp, err := ha.Query(hb.PeerID())
+	require.NoError(t, err)
+	require.Equal(t, []*types.Offer{}, resp.Offers)
+
+	require.ErrorIs(t, ha.BlockPeer(ha.PeerID()), errCannotBlockSelf)
+}
+
+func TestHost_BlockPeer_persisted(t *testing.T) {
+	db := &mockBlocklistDB{peers: make(map[peer.ID]struct{})}
+	cfg := basicTestConfig(t)
+	cfg.BlocklistDB = db
+	ha := newHost(t, cfg)
+	require.NoError(t, ha.Start())
+	hb := newHost(t, basicTestConfig(t))
+	require.NoError(t, hb.Start())
+
+	require.NoError(t, ha.BlockPeer(hb.PeerID()))
+	require.Contains(t, db.peers, hb.PeerID())
+
+	// a new host loads the blocklist from the database
+	cfg = basicTestConfig(t)
+	cfg.BlocklistDB = db
+	hc := newHost(t, cfg)
+	require.NoError(t, hc.Start())
+	require.True(t, hc.IsBlocked(hb.PeerID()))
+
+	require.NoError(t, hc.UnblockPeer(hb.PeerID()))
+	require.False(t, hc.IsBlocked(hb.PeerID()))
+	require.Empty(t, db.peers)
+}
@@ -12,4 +12,7 @@ var (
 	errNilHandler            = errors.New("handler is nil")
 	errNoOngoingSwap         = errors.New("no swap currently happening")
 	errSwapAlreadyInProgress = errors.New("already have ongoing swap")
+	errStreamReadTimeout     = errors.New("timed out reading from stream")
+	errPeerBlocked           = errors.New("peer is blocked")
+	errCannotBlockSelf       = errors.New("cannot block our own peer ID")
 )
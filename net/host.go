@@ -10,6 +10,7 @@ import (
 	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	p2pnet "github.com/athanorlabs/go-p2p-net"
@@ -32,6 +33,10 @@ const (
 	maxMessageSize      = 1 << 17
 	maxRelayMessageSize = 2048
 	connectionTimeout   = time.Second * 5
+
+	// streamResumeInterval is how long the taker waits before each attempt
+	// to resume a dropped swap protocol stream.
+	streamResumeInterval = time.Second * 5
 )
 
 var log = logging.Logger("net")
@@ -62,12 +67,29 @@ type Host struct {
 	h         P2pHost
 	isRelayer bool
 
+	// relayerFee is the fee that we charge for relaying claims, which is
+	// returned to peers that query it
+	relayerFee *coins.WeiAmount
+
 	// set to true if the node is a bootnode-only node
 	isBootnode bool
 
+	messagePolicy MessagePolicy
+
+	// dropped swap protocol streams are resumed with up to maxResumeAttempts
+	// attempts, resumeInterval apart
+	maxResumeAttempts uint
+	resumeInterval    time.Duration
+
 	makerHandler MakerHandler
 	relayHandler RelayHandler
 
+	// set by StopOffers, after which our offers are no longer advertised or
+	// returned to queries
+	offersStopped atomic.Bool
+
+	blocklist *blocklist
+
 	// swap instance info
 	swapMu sync.RWMutex
 	swaps  map[types.Hash]*swap
@@ -84,6 +106,20 @@ type Config struct {
 	ListenIP       string
 	IsRelayer      bool
 	IsBootnodeOnly bool
+	MessagePolicy  MessagePolicy
+
+	// RelayerFee is the fee that we charge for relaying claims, if IsRelayer
+	// is set. If nil, coins.RelayerFeeWei is used.
+	RelayerFee *coins.WeiAmount
+
+	// MaxStreamResumeAttempts is the number of attempts to resume a swap
+	// protocol stream that dropped before the swap's funds were locked. If
+	// zero, the swap exits as soon as its stream drops.
+	MaxStreamResumeAttempts uint
+
+	// BlocklistDB persists the blocked peers. If nil, blocked peers are
+	// forgotten on restart.
+	BlocklistDB BlocklistDB
 }
 
 // NewHost returns a new Host.
@@ -94,15 +130,29 @@ func NewHost(cfg *Config) (*Host, error) {
 		return nil, errBootnodeCannotRelay
 	}
 
+	peerBlocklist, err := newBlocklist(cfg.BlocklistDB)
+	if err != nil {
+		return nil, err
+	}
+
+	relayerFee := cfg.RelayerFee
+	if relayerFee == nil {
+		relayerFee = coins.NewWeiAmount(coins.RelayerFeeWei)
+	}
+
 	h := &Host{
-		ctx:        cfg.Ctx,
-		h:          nil, // set below
-		isRelayer:  cfg.IsRelayer,
-		isBootnode: cfg.IsBootnodeOnly,
-		swaps:      make(map[types.Hash]*swap),
+		ctx:               cfg.Ctx,
+		h:                 nil, // set below
+		isRelayer:         cfg.IsRelayer,
+		relayerFee:        relayerFee,
+		isBootnode:        cfg.IsBootnodeOnly,
+		messagePolicy:     cfg.MessagePolicy,
+		maxResumeAttempts: cfg.MaxStreamResumeAttempts,
+		resumeInterval:    streamResumeInterval,
+		blocklist:         peerBlocklist,
+		swaps:             make(map[types.Hash]*swap),
 	}
 
-	var err error
 	h.h, err = p2pnet.NewHost(&p2pnet.Config{
 		Ctx:                      cfg.Ctx,
 		DataDir:                  cfg.DataDir,
@@ -124,7 +174,7 @@ func NewHost(cfg *Config) (*Host, error) {
 func (h *Host) advertisedNamespaces() []string {
 	provides := []string{""}
 
-	if !h.isBootnode && len(h.makerHandler.GetOffers()) > 0 {
+	if !h.isBootnode && len(h.getOffers()) > 0 {
 		provides = append(provides, string(coins.ProvidesXMR))
 	}
 
@@ -135,6 +185,22 @@ func (h *Host) advertisedNamespaces() []string {
 	return provides
 }
 
+// getOffers returns the offers that we publish to other peers.
+func (h *Host) getOffers() []*types.Offer {
+	if h.offersStopped.Load() {
+		return nil
+	}
+	return h.makerHandler.GetOffers()
+}
+
+// StopOffers stops advertising our offers and returning them to peers that
+// query us, as done when swapd is draining its swaps before shutting down. It
+// can't be undone.
+func (h *Host) StopOffers() {
+	h.offersStopped.Store(true)
+	h.h.Advertise()
+}
+
 // SetHandlers sets the maker and taker instances used by the host, and configures
 // the stream handlers.
 func (h *Host) SetHandlers(makerHandler MakerHandler, relayHandler RelayHandler) {
@@ -143,7 +209,9 @@ func (h *Host) SetHandlers(makerHandler MakerHandler, relayHandler RelayHandler)
 
 	h.h.SetStreamHandler(queryProtocolID, h.handleQueryStream)
 	h.h.SetStreamHandler(relayProtocolID, h.handleRelayStream)
+	h.h.SetStreamHandler(relayerFeeProtocolID, h.handleRelayerFeeStream)
 	h.h.SetStreamHandler(swapID, h.handleProtocolStream)
+	h.h.SetStreamHandler(swapResumeID, h.handleResumeStream)
 }
 
 // Start starts the bootstrap and discovery process.
@@ -167,32 +235,67 @@ func (h *Host) Stop() error {
 
 // SendSwapMessage sends a message to the peer who we're currently doing a swap with.
 func (h *Host) SendSwapMessage(msg Message, id types.Hash) error {
-	h.swapMu.RLock()
-	defer h.swapMu.RUnlock()
-
+	h.swapMu.Lock()
 	swap, has := h.swaps[id]
 	if !has {
+		h.swapMu.Unlock()
 		return errNoOngoingSwap
 	}
 
-	return p2pnet.WriteStreamMessage(swap.stream, msg, swap.stream.Conn().RemotePeer())
+	// our ETH is locked once we notify the maker, so the stream must not be
+	// resumed from here on, even if sending the message fails
+	if msg.Type() == message.NotifyETHLockedType {
+		swap.ethLockedNotified = true
+	}
+	stream := swap.stream
+	h.swapMu.Unlock()
+
+	// the lock is not held while writing, so that a slow peer does not
+	// block our other swaps
+	return p2pnet.WriteStreamMessage(stream, msg, stream.Conn().RemotePeer())
 }
 
 // CloseProtocolStream closes the current swap protocol stream.
 func (h *Host) CloseProtocolStream(offerID types.Hash) {
-	h.swapMu.RLock()
+	h.swapMu.Lock()
 	swap, has := h.swaps[offerID]
-	h.swapMu.RUnlock()
 	if !has {
+		h.swapMu.Unlock()
 		return
 	}
 
+	swap.closed = true
+	if swap.resumeCh != nil {
+		// stop waiting for the stream to be resumed
+		swap.resumeCh <- nil
+		swap.resumeCh = nil
+	}
+	h.swapMu.Unlock()
+
 	log.Debugf("closing stream: peer=%s protocol=%s",
 		swap.stream.Conn().RemotePeer(), swap.stream.Protocol(),
 	)
 	_ = swap.stream.Close()
 }
 
+// ProtocolStreams returns the open swap protocol streams.
+func (h *Host) ProtocolStreams() []*ProtocolStreamInfo {
+	h.swapMu.RLock()
+	defer h.swapMu.RUnlock()
+
+	streams := make([]*ProtocolStreamInfo, 0, len(h.swaps))
+	for offerID, swap := range h.swaps {
+		streams = append(streams, &ProtocolStreamInfo{
+			PeerID:   swap.stream.Conn().RemotePeer(),
+			OfferID:  offerID,
+			IsTaker:  swap.isTaker,
+			OpenedAt: swap.openedAt,
+		})
+	}
+
+	return streams
+}
+
 // Advertise advertises the namespaces now instead of waiting for the next periodic
 // update. We use it when a new advertised namespace is added.
 func (h *Host) Advertise() {
@@ -200,9 +303,21 @@ func (h *Host) Advertise() {
 }
 
 // Discover searches the DHT for peers that advertise that they provide the given coin..
-// It searches for up to `searchTime` duration of time.
+// It searches for up to `searchTime` duration of time. Blocked peers are left out.
 func (h *Host) Discover(provides string, searchTime time.Duration) ([]peer.ID, error) {
-	return h.h.Discover(provides, searchTime)
+	peerIDs, err := h.h.Discover(provides, searchTime)
+	if err != nil {
+		return nil, err
+	}
+
+	unblocked := make([]peer.ID, 0, len(peerIDs))
+	for _, peerID := range peerIDs {
+		if !h.blocklist.has(peerID) {
+			unblocked = append(unblocked, peerID)
+		}
+	}
+
+	return unblocked, nil
 }
 
 // AddrInfo returns the host's AddrInfo.
@@ -234,6 +349,34 @@ func readStreamMessage(stream libp2pnetwork.Stream, maxMessageSize uint32) (comm
 	return message.DecodeMessage(msgBytes)
 }
 
+// readStreamMessageWithTimeout is the same as readStreamMessage, but closes the
+// stream and returns errStreamReadTimeout if no message is read before the
+// timeout.
+func readStreamMessageWithTimeout(
+	stream libp2pnetwork.Stream,
+	maxMessageSize uint32,
+	timeout time.Duration,
+) (common.Message, error) {
+	type result struct {
+		msg common.Message
+		err error
+	}
+
+	resultCh := make(chan result, 1)
+	go func() {
+		msg, err := readStreamMessage(stream, maxMessageSize)
+		resultCh <- result{msg: msg, err: err}
+	}()
+
+	select {
+	case res := <-resultCh:
+		return res.msg, res.err
+	case <-time.After(timeout):
+		_ = stream.Close()
+		return nil, errStreamReadTimeout
+	}
+}
+
 // nextStreamMessage returns a channel that will receive the next message from the stream.
 // if there is an error reading from the stream, the channel will be closed, thus
 // the received value will be nil.
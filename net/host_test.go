@@ -30,6 +30,12 @@ var (
 type mockMakerHandler struct {
 	t  *testing.T
 	id types.Hash
+
+	// handleErr is returned by the swap states created by the handler for all
+	// messages after the initial one
+	handleErr error
+	// exitCh, if set, is closed when a swap state created by the handler exits
+	exitCh chan struct{}
 }
 
 func (h *mockMakerHandler) GetOffers() []*types.Offer {
@@ -41,9 +47,9 @@ func (h *mockMakerHandler) HandleInitiateMessage(
 	msg *message.SendKeysMessage,
 ) (s SwapState, resp Message, err error) {
 	if (h.id != types.Hash{}) {
-		return &mockSwapState{h.id}, createSendKeysMessage(h.t), nil
+		return &mockSwapState{offerID: h.id, handleErr: h.handleErr, exitCh: h.exitCh}, createSendKeysMessage(h.t), nil
 	}
-	return &mockSwapState{}, msg, nil
+	return &mockSwapState{handleErr: h.handleErr, exitCh: h.exitCh}, msg, nil
 }
 
 type mockRelayHandler struct {
@@ -57,7 +63,13 @@ func (h *mockRelayHandler) HandleRelayClaimRequest(_ peer.ID, _ *RelayClaimReque
 }
 
 type mockSwapState struct {
-	offerID types.Hash
+	offerID   types.Hash
+	handleErr error
+
+	// if set, handled messages are passed to handledCh, which must be
+	// buffered, and exitCh is closed when the swap exits
+	handledCh chan Message
+	exitCh    chan struct{}
 }
 
 func (s *mockSwapState) OfferID() types.Hash {
@@ -68,11 +80,17 @@ func (s *mockSwapState) OfferID() types.Hash {
 	return testID
 }
 
-func (s *mockSwapState) HandleProtocolMessage(_ Message) error {
-	return nil
+func (s *mockSwapState) HandleProtocolMessage(msg Message) error {
+	if s.handledCh != nil {
+		s.handledCh <- msg
+	}
+	return s.handleErr
 }
 
 func (s *mockSwapState) Exit() error {
+	if s.exitCh != nil {
+		close(s.exitCh)
+	}
 	return nil
 }
 
@@ -35,6 +35,10 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 		return errSwapAlreadyInProgress
 	}
 
+	if h.blocklist.has(who.ID) {
+		return errPeerBlocked
+	}
+
 	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
 	defer cancel()
 
@@ -63,6 +67,7 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 		swapState: s,
 		stream:    stream,
 		isTaker:   true,
+		openedAt:  time.Now(),
 	}
 
 	go h.receiveInitiateResponse(stream, s)
@@ -70,30 +75,41 @@ func (h *Host) Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s com
 }
 
 func (h *Host) receiveInitiateResponse(stream libp2pnetwork.Stream, s SwapState) {
-	defer h.handleProtocolStreamClose(stream, s)
-
 	const initiateResponseTimeout = time.Minute
 
-	select {
-	case msg := <-nextStreamMessage(stream, maxMessageSize):
-		if msg == nil {
-			log.Errorf("failed to read initial SendKeysMessage response")
-			return
+	var msg common.Message
+	for {
+		var err error
+		msg, err = readStreamMessageWithTimeout(stream, maxMessageSize, initiateResponseTimeout)
+		if err == nil {
+			break
 		}
 
-		log.Debugf("received protocol=%s message from peer=%s type=%s",
-			stream.Protocol(), stream.Conn().RemotePeer(), message.TypeToString(msg.Type()))
-
-		err := s.HandleProtocolMessage(msg)
-		if err != nil {
-			log.Warnf("failed to handle protocol message: err=%s", err)
+		log.Errorf("failed to read initial SendKeysMessage response: %s", err)
+		newStream := h.resumeProtocolStream(s.OfferID(), err)
+		if newStream == nil {
+			h.handleProtocolStreamClose(stream, s)
 			return
 		}
-	case <-time.After(initiateResponseTimeout):
-		log.Errorf("timed out waiting for SendKeysMessage response")
+		stream = newStream
+	}
+
+	log.Debugf("received protocol=%s message from peer=%s type=%s",
+		stream.Protocol(), stream.Conn().RemotePeer(), message.TypeToString(msg.Type()))
+
+	err := s.HandleProtocolMessage(msg)
+	if err != nil {
+		log.Warnf("failed to handle protocol message: err=%s", err)
+		h.handleProtocolStreamClose(stream, s)
 		return
 	}
 
+	h.swapMu.Lock()
+	if swap, has := h.swaps[s.OfferID()]; has {
+		swap.keysReceived = true
+	}
+	h.swapMu.Unlock()
+
 	h.handleProtocolStreamInner(stream, s)
 }
 
@@ -104,6 +120,12 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 		return
 	}
 
+	if h.blocklist.has(stream.Conn().RemotePeer()) {
+		log.Debugf("rejected swap stream from blocked peer %s", stream.Conn().RemotePeer())
+		_ = stream.Close()
+		return
+	}
+
 	msg, err := readStreamMessage(stream, maxMessageSize)
 	if err != nil {
 		if errors.Is(err, io.EOF) {
@@ -145,9 +167,11 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 
 	h.swapMu.Lock()
 	h.swaps[s.OfferID()] = &swap{
-		swapState: s,
-		stream:    stream,
-		isTaker:   false,
+		swapState:    s,
+		stream:       stream,
+		isTaker:      false,
+		openedAt:     time.Now(),
+		keysResponse: resp,
 	}
 	h.swapMu.Unlock()
 
@@ -156,27 +180,67 @@ func (h *Host) handleProtocolStream(stream libp2pnetwork.Stream) {
 
 // handleProtocolStreamInner is called to handle a protocol stream, in both ingoing and outgoing cases.
 func (h *Host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapState) {
-	defer h.handleProtocolStreamClose(stream, s)
+	filter := &streamMessageFilter{policy: h.messagePolicy}
+
+	for {
+		err := h.readProtocolMessages(stream, s, filter)
+		newStream := h.resumeProtocolStream(s.OfferID(), err)
+		if newStream == nil {
+			break
+		}
+		stream = newStream
+	}
+
+	h.handleProtocolStreamClose(stream, s)
+}
+
+// readProtocolMessages passes the messages read from the stream to the swap
+// state. It returns the error that ended reading from the stream, or nil if
+// the swap state failed to handle a message.
+func (h *Host) readProtocolMessages(
+	stream libp2pnetwork.Stream,
+	s SwapState,
+	filter *streamMessageFilter,
+) error {
+	remotePeer := stream.Conn().RemotePeer()
 
 	for {
 		msg, err := readStreamMessage(stream, maxMessageSize)
 		if err != nil {
+			if filter.shouldDrop(err) {
+				log.Warnf("dropped message from peer=%s: %s", remotePeer, err)
+				continue
+			}
+
 			if errors.Is(err, io.EOF) {
 				log.Debug("Peer closed stream with us, protocol exited")
 			} else {
 				log.Debugf("Failed to read message from peer, id=%s protocol=%s: %s",
 					stream.ID(), stream.Protocol(), err)
 			}
-			return
+			return err
 		}
 
 		log.Debugf("received protocol=%s message from peer=%s type=%s",
-			stream.Protocol(), stream.Conn().RemotePeer(), message.TypeToString(msg.Type()))
+			stream.Protocol(), remotePeer, message.TypeToString(msg.Type()))
+
+		if msg.Type() == message.NotifyETHLockedType {
+			h.swapMu.Lock()
+			if swap, has := h.swaps[s.OfferID()]; has {
+				swap.ethLockedNotified = true
+			}
+			h.swapMu.Unlock()
+		}
 
 		err = s.HandleProtocolMessage(msg)
 		if err != nil {
+			if filter.shouldDrop(err) {
+				log.Warnf("ignored %s message from peer=%s: %s", message.TypeToString(msg.Type()), remotePeer, err)
+				continue
+			}
+
 			log.Warnf("failed to handle protocol message: %s", err)
-			return
+			return nil
 		}
 	}
 }
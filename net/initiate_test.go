@@ -55,6 +55,17 @@ func TestHost_Initiate(t *testing.T) {
 	hb.swapMu.RLock()
 	require.NotNil(t, hb.swaps[testID])
 	hb.swapMu.RUnlock()
+
+	streams := ha.ProtocolStreams()
+	require.Len(t, streams, 1)
+	require.Equal(t, hb.h.PeerID(), streams[0].PeerID)
+	require.Equal(t, testID, streams[0].OfferID)
+	require.True(t, streams[0].IsTaker)
+
+	streams = hb.ProtocolStreams()
+	require.Len(t, streams, 1)
+	require.Equal(t, ha.h.PeerID(), streams[0].PeerID)
+	require.False(t, streams[0].IsTaker)
 }
 
 func TestHost_ConcurrentSwaps(t *testing.T) {
@@ -87,7 +98,7 @@ func TestHost_ConcurrentSwaps(t *testing.T) {
 
 	hb.makerHandler.(*mockMakerHandler).id = testID2
 
-	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), &mockSwapState{testID2})
+	err = ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), &mockSwapState{offerID: testID2})
 	require.NoError(t, err)
 	time.Sleep(time.Millisecond * 1500)
 
@@ -28,6 +28,20 @@ const (
 	RelayClaimResponseType
 	SendKeysType
 	NotifyETHLockedType
+	ResumeSwapType
+	RelayerFeeResponseType
+)
+
+var (
+	// ErrUnknownMessageType is wrapped by the error returned from DecodeMessage when
+	// the message's type identifier is not one we know, which is expected when a peer
+	// runs a newer version of the protocol.
+	ErrUnknownMessageType = errors.New("unknown message type")
+
+	// ErrUnexpectedMessage is wrapped by errors that a swap returns from handling a
+	// known message that it does not expect at its current point in the protocol.
+	// The swap's state is not changed by such a message.
+	ErrUnexpectedMessage = errors.New("unexpected message")
 )
 
 // TypeToString converts a message type into a string.
@@ -39,10 +53,14 @@ func TypeToString(t byte) string {
 		return "SendKeysMessage"
 	case NotifyETHLockedType:
 		return "NotifyETHLocked"
+	case ResumeSwapType:
+		return "ResumeSwapMessage"
 	case RelayClaimRequestType:
 		return "RelayClaimRequestType"
 	case RelayClaimResponseType:
 		return "RelayClaimResponse"
+	case RelayerFeeResponseType:
+		return "RelayerFeeResponse"
 	default:
 		return fmt.Sprintf("Unknown(%d)", t)
 	}
@@ -66,12 +84,16 @@ func DecodeMessage(b []byte) (common.Message, error) {
 		msg = new(RelayClaimRequest)
 	case RelayClaimResponseType:
 		msg = new(RelayClaimResponse)
+	case RelayerFeeResponseType:
+		msg = new(RelayerFeeResponse)
 	case SendKeysType:
 		msg = new(SendKeysMessage)
 	case NotifyETHLockedType:
 		msg = new(NotifyETHLocked)
+	case ResumeSwapType:
+		msg = new(ResumeSwapMessage)
 	default:
-		return nil, fmt.Errorf("invalid message type=%d", msgType)
+		return nil, fmt.Errorf("%w: type=%d", ErrUnknownMessageType, msgType)
 	}
 
 	if err := vjson.UnmarshalStruct(msgJSON, msg); err != nil {
@@ -186,3 +208,37 @@ func (m *NotifyETHLocked) Encode() ([]byte, error) {
 func (m *NotifyETHLocked) Type() byte {
 	return NotifyETHLockedType
 }
+
+// ResumeSwapMessage is sent by the XMR taker as the first message of a new
+// protocol stream, to resume a swap whose previous stream dropped.
+type ResumeSwapMessage struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+	// KeysReceived is false if the taker did not receive the maker's
+	// SendKeysMessage before the stream dropped, in which case the maker sends
+	// it again on the new stream.
+	KeysReceived bool `json:"keysReceived"`
+}
+
+// String ...
+func (m *ResumeSwapMessage) String() string {
+	return fmt.Sprintf("ResumeSwapMessage OfferID=%s KeysReceived=%t",
+		m.OfferID,
+		m.KeysReceived,
+	)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *ResumeSwapMessage) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{ResumeSwapType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *ResumeSwapMessage) Type() byte {
+	return ResumeSwapType
+}
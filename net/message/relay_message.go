@@ -5,9 +5,11 @@ package message
 
 import (
 	"fmt"
+	"math/big"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
@@ -23,6 +25,9 @@ type RelayClaimRequest struct {
 	Swap            *contracts.SwapCreatorSwap `json:"swap" validate:"required"`
 	Secret          []byte                     `json:"secret" validate:"required,len=32"`
 	Signature       []byte                     `json:"signature" validate:"required,len=65"`
+	// Fee is the relayer fee that the claim was signed with. Peers that predate
+	// relayer fee queries don't set it, and sign with coins.RelayerFeeWei.
+	Fee *coins.WeiAmount `json:"fee,omitempty"`
 }
 
 // RelayClaimResponse implements common.Message for our p2p relay claim responses
@@ -30,6 +35,14 @@ type RelayClaimResponse struct {
 	TxHash ethcommon.Hash `json:"transactionHash" validate:"required"`
 }
 
+// FeeWei returns the relayer fee, in wei, that the claim was signed with.
+func (m *RelayClaimRequest) FeeWei() *big.Int {
+	if m.Fee == nil {
+		return coins.RelayerFeeWei
+	}
+	return m.Fee.BigInt()
+}
+
 // String converts the RelayClaimRequest to a string usable for debugging purposes
 func (m *RelayClaimRequest) String() string {
 	return fmt.Sprintf("RelayClaimResponse=%#v", m)
@@ -71,3 +84,30 @@ func (m *RelayClaimResponse) Encode() ([]byte, error) {
 func (m *RelayClaimResponse) Type() byte {
 	return RelayClaimResponseType
 }
+
+// RelayerFeeResponse implements common.Message for our p2p responses to
+// queries of a relayer's fee.
+type RelayerFeeResponse struct {
+	Fee *coins.WeiAmount `json:"fee" validate:"required"`
+}
+
+// String converts the RelayerFeeResponse to a string usable for debugging purposes
+func (m *RelayerFeeResponse) String() string {
+	return fmt.Sprintf("RelayerFeeResponse Fee=%s", m.Fee)
+}
+
+// Encode implements the Encode() method of the common.Message interface which
+// prepends a message type byte before the message's JSON encoding.
+func (m *RelayerFeeResponse) Encode() ([]byte, error) {
+	b, err := vjson.MarshalStruct(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{RelayerFeeResponseType}, b...), nil
+}
+
+// Type implements the Type() method of the common.Message interface
+func (m *RelayerFeeResponse) Type() byte {
+	return RelayerFeeResponseType
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"errors"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+// MessagePolicy configures how a swap stream handles messages that cannot be
+// processed after the swap has started. The zero value aborts the swap on the
+// first such message.
+type MessagePolicy struct {
+	// DropUnknown drops messages of an unknown type, which a peer running a newer
+	// version of the protocol may send, instead of aborting the swap.
+	DropUnknown bool
+
+	// IgnoreUnexpected drops known messages that the swap does not expect at its
+	// current point in the protocol, instead of aborting the swap.
+	IgnoreUnexpected bool
+
+	// MaxDropped is the number of messages that can be dropped on a swap stream
+	// before the peer is considered to be misbehaving and the swap is aborted.
+	// Zero means there is no limit.
+	MaxDropped uint
+}
+
+// streamMessageFilter applies a MessagePolicy to the messages of a single swap
+// stream.
+type streamMessageFilter struct {
+	policy  MessagePolicy
+	dropped uint
+}
+
+// shouldDrop returns true if the message that caused err should be dropped and
+// the stream kept open, or false if the swap should be aborted.
+func (f *streamMessageFilter) shouldDrop(err error) bool {
+	switch {
+	case errors.Is(err, message.ErrUnknownMessageType):
+		if !f.policy.DropUnknown {
+			return false
+		}
+	case errors.Is(err, message.ErrUnexpectedMessage):
+		if !f.policy.IgnoreUnexpected {
+			return false
+		}
+	default:
+		return false
+	}
+
+	f.dropped++
+	if f.policy.MaxDropped != 0 && f.dropped > f.policy.MaxDropped {
+		log.Warnf("peer exceeded the limit of %d dropped messages, aborting swap", f.policy.MaxDropped)
+		return false
+	}
+
+	return true
+}
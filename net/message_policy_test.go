@@ -0,0 +1,143 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
+)
+
+// unknownMessage has a type identifier that DecodeMessage does not know, like a
+// message sent by a peer running a newer version of the protocol.
+type unknownMessage struct{}
+
+const unknownMessageType byte = 0xff
+
+func (*unknownMessage) String() string {
+	return "unknownMessage"
+}
+
+func (*unknownMessage) Encode() ([]byte, error) {
+	return append([]byte{unknownMessageType}, []byte(`{}`)...), nil
+}
+
+func (*unknownMessage) Type() byte {
+	return unknownMessageType
+}
+
+var errTestUnexpected = fmt.Errorf("%w: out of order", message.ErrUnexpectedMessage)
+
+func TestDecodeMessage_unknownType(t *testing.T) {
+	b, err := new(unknownMessage).Encode()
+	require.NoError(t, err)
+
+	_, err = message.DecodeMessage(b)
+	require.ErrorIs(t, err, message.ErrUnknownMessageType)
+}
+
+func TestStreamMessageFilter_zeroPolicy(t *testing.T) {
+	f := &streamMessageFilter{}
+	require.False(t, f.shouldDrop(message.ErrUnknownMessageType))
+	require.False(t, f.shouldDrop(errTestUnexpected))
+	require.False(t, f.shouldDrop(io.EOF))
+}
+
+func TestStreamMessageFilter_dropUnknown(t *testing.T) {
+	f := &streamMessageFilter{policy: MessagePolicy{DropUnknown: true}}
+	require.True(t, f.shouldDrop(fmt.Errorf("%w: type=255", message.ErrUnknownMessageType)))
+	require.False(t, f.shouldDrop(errTestUnexpected))
+	require.False(t, f.shouldDrop(io.EOF))
+}
+
+func TestStreamMessageFilter_ignoreUnexpected(t *testing.T) {
+	f := &streamMessageFilter{policy: MessagePolicy{IgnoreUnexpected: true}}
+	require.True(t, f.shouldDrop(errTestUnexpected))
+	require.False(t, f.shouldDrop(message.ErrUnknownMessageType))
+	require.False(t, f.shouldDrop(io.EOF))
+}
+
+func TestStreamMessageFilter_maxDropped(t *testing.T) {
+	f := &streamMessageFilter{
+		policy: MessagePolicy{
+			DropUnknown:      true,
+			IgnoreUnexpected: true,
+			MaxDropped:       2,
+		},
+	}
+
+	// both kinds of dropped messages count towards the limit
+	require.True(t, f.shouldDrop(message.ErrUnknownMessageType))
+	require.True(t, f.shouldDrop(errTestUnexpected))
+	require.False(t, f.shouldDrop(message.ErrUnknownMessageType))
+}
+
+// startSwapWithPolicy starts a swap between two new hosts, where the host
+// receiving the swap uses the passed message policy and its swap state returns
+// handleErr for every protocol message after the initial one.
+func startSwapWithPolicy(t *testing.T, policy MessagePolicy, handleErr error) (*Host, *Host) {
+	ha := newHost(t, basicTestConfig(t))
+	require.NoError(t, ha.Start())
+
+	hbCfg := basicTestConfig(t)
+	hbCfg.MessagePolicy = policy
+	hb := newHost(t, hbCfg)
+	hb.makerHandler.(*mockMakerHandler).handleErr = handleErr
+	require.NoError(t, hb.Start())
+
+	require.NoError(t, ha.h.Connect(ha.ctx, hb.h.AddrInfo()))
+	require.NoError(t, ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), new(mockSwapState)))
+	time.Sleep(time.Millisecond * 500)
+	require.True(t, hasSwap(hb))
+
+	return ha, hb
+}
+
+func hasSwap(h *Host) bool {
+	h.swapMu.RLock()
+	defer h.swapMu.RUnlock()
+	return h.swaps[testID] != nil
+}
+
+func TestHost_UnknownMessage_abortsByDefault(t *testing.T) {
+	ha, hb := startSwapWithPolicy(t, MessagePolicy{}, nil)
+
+	require.NoError(t, ha.SendSwapMessage(new(unknownMessage), testID))
+	time.Sleep(time.Millisecond * 500)
+	require.False(t, hasSwap(hb))
+}
+
+func TestHost_UnknownMessage_dropped(t *testing.T) {
+	ha, hb := startSwapWithPolicy(t, MessagePolicy{DropUnknown: true}, nil)
+
+	require.NoError(t, ha.SendSwapMessage(new(unknownMessage), testID))
+	time.Sleep(time.Millisecond * 500)
+	require.True(t, hasSwap(hb))
+}
+
+func TestHost_UnexpectedMessage_abortsByDefault(t *testing.T) {
+	ha, hb := startSwapWithPolicy(t, MessagePolicy{}, errTestUnexpected)
+
+	require.NoError(t, ha.SendSwapMessage(createSendKeysMessage(t), testID))
+	time.Sleep(time.Millisecond * 500)
+	require.False(t, hasSwap(hb))
+}
+
+func TestHost_UnexpectedMessage_ignored(t *testing.T) {
+	ha, hb := startSwapWithPolicy(t, MessagePolicy{IgnoreUnexpected: true, MaxDropped: 1}, errTestUnexpected)
+
+	require.NoError(t, ha.SendSwapMessage(createSendKeysMessage(t), testID))
+	time.Sleep(time.Millisecond * 500)
+	require.True(t, hasSwap(hb))
+
+	// the second message exceeds the limit of dropped messages
+	require.NoError(t, ha.SendSwapMessage(createSendKeysMessage(t), testID))
+	time.Sleep(time.Millisecond * 500)
+	require.False(t, hasSwap(hb))
+}
@@ -23,8 +23,13 @@ const (
 func (h *Host) handleQueryStream(stream libp2pnetwork.Stream) {
 	defer func() { _ = stream.Close() }()
 
+	if h.blocklist.has(stream.Conn().RemotePeer()) {
+		log.Debugf("rejected query from blocked peer %s", stream.Conn().RemotePeer())
+		return
+	}
+
 	resp := &QueryResponse{
-		Offers: h.makerHandler.GetOffers(),
+		Offers: h.getOffers(),
 	}
 
 	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
@@ -34,14 +39,25 @@ func (h *Host) handleQueryStream(stream libp2pnetwork.Stream) {
 
 // Query queries the given peer for its offers.
 func (h *Host) Query(who peer.ID) (*QueryResponse, error) {
+	return h.QueryAddrInfo(peer.AddrInfo{ID: who})
+}
+
+// QueryAddrInfo queries the given peer for its offers, connecting to it using
+// the addresses in the AddrInfo. Unlike Query, the peer's addresses don't need
+// to be known by the DHT beforehand.
+func (h *Host) QueryAddrInfo(who peer.AddrInfo) (*QueryResponse, error) {
+	if h.blocklist.has(who.ID) {
+		return nil, errPeerBlocked
+	}
+
 	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
 	defer cancel()
 
-	if err := h.h.Connect(ctx, peer.AddrInfo{ID: who}); err != nil {
+	if err := h.h.Connect(ctx, who); err != nil {
 		return nil, err
 	}
 
-	stream, err := h.h.NewStream(ctx, who, queryProtocolID)
+	stream, err := h.h.NewStream(ctx, who.ID, queryProtocolID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
 	}
@@ -13,11 +13,13 @@ import (
 	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 const (
-	relayProtocolID = "/relay/0"
+	relayProtocolID      = "/relay/0"
+	relayerFeeProtocolID = "/relay/fee/0"
 
 	// RelayerProvidesStr is the DHT namespace advertised by nodes willing to relay
 	// claims for arbitrary XMR makers.
@@ -31,6 +33,15 @@ func (h *Host) DiscoverRelayers() ([]peer.ID, error) {
 	return h.Discover(RelayerProvidesStr, defaultDiscoverTime)
 }
 
+// RelayerFee returns the fee that we charge for relaying claims, or nil if we
+// are not relaying claims for everyone.
+func (h *Host) RelayerFee() *coins.WeiAmount {
+	if !h.isRelayer {
+		return nil
+	}
+	return h.relayerFee
+}
+
 func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 	defer func() { _ = stream.Close() }()
 
@@ -68,7 +79,7 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 
 	resp, err := h.relayHandler.HandleRelayClaimRequest(curPeer, req)
 	if err != nil {
-		log.Debugf("did not handle relay request: %s", err)
+		log.Debugf("did not handle relay request from peer %s: %s", curPeer, err)
 		return
 	}
 
@@ -79,6 +90,66 @@ func (h *Host) handleRelayStream(stream libp2pnetwork.Stream) {
 	}
 }
 
+func (h *Host) handleRelayerFeeStream(stream libp2pnetwork.Stream) {
+	defer func() { _ = stream.Close() }()
+
+	// only nodes relaying for everyone have a fee to return
+	if !h.isRelayer {
+		return
+	}
+
+	resp := &RelayerFeeResponse{
+		Fee: h.relayerFee,
+	}
+
+	if err := p2pnet.WriteStreamMessage(stream, resp, stream.Conn().RemotePeer()); err != nil {
+		log.Warnf("failed to send RelayerFeeResponse message to peer: err=%s", err)
+	}
+}
+
+// QueryRelayerFee queries a relayer for the fee that it charges to relay a
+// claim.
+func (h *Host) QueryRelayerFee(relayerID peer.ID) (*coins.WeiAmount, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
+	defer cancel()
+
+	if err := h.h.Connect(ctx, peer.AddrInfo{ID: relayerID}); err != nil {
+		return nil, err
+	}
+
+	stream, err := h.h.NewStream(ctx, relayerID, relayerFeeProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() { _ = stream.Close() }()
+	log.Debugf("opened relayer fee stream: %s", stream.Conn())
+
+	return receiveRelayerFeeResponse(stream)
+}
+
+func receiveRelayerFeeResponse(stream libp2pnetwork.Stream) (*coins.WeiAmount, error) {
+	const relayerFeeResponseTimeout = time.Second * 15
+
+	select {
+	case msg := <-nextStreamMessage(stream, maxRelayMessageSize):
+		if msg == nil {
+			return nil, errors.New("failed to read RelayerFeeResponse")
+		}
+
+		resp, ok := msg.(*RelayerFeeResponse)
+		if !ok {
+			return nil, fmt.Errorf("expected %s message but received %s",
+				message.TypeToString(message.RelayerFeeResponseType),
+				message.TypeToString(msg.Type()))
+		}
+
+		return resp.Fee, nil
+	case <-time.After(relayerFeeResponseTimeout):
+		return nil, errors.New("timed out waiting for RelayerFeeResponse")
+	}
+}
+
 // SubmitClaimToRelayer sends a request to relay a swap claim to a peer.
 func (h *Host) SubmitClaimToRelayer(relayerID peer.ID, request *RelayClaimRequest) (*RelayClaimResponse, error) {
 	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
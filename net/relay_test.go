@@ -11,6 +11,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/net/message"
@@ -54,6 +55,18 @@ func TestHost_DiscoverRelayers(t *testing.T) {
 	require.Len(t, peerIDs, 0) // ha is not a relayer and not discovered
 }
 
+func TestHost_QueryRelayerFee(t *testing.T) {
+	ha, hb := twoHostRelayerSetup(t)
+
+	fee, err := ha.QueryRelayerFee(hb.PeerID())
+	require.NoError(t, err)
+	require.Equal(t, coins.RelayerFeeWei.String(), fee.BigInt().String())
+
+	// ha is not a relayer, so it doesn't return a fee
+	_, err = hb.QueryRelayerFee(ha.PeerID())
+	require.Error(t, err)
+}
+
 func createTestClaimRequest() *message.RelayClaimRequest {
 	secret := [32]byte{0x1}
 	sig := [65]byte{0x1}
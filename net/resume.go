@@ -0,0 +1,226 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	p2pnet "github.com/athanorlabs/go-p2p-net"
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	swapResumeID = "/swap-resume/0"
+)
+
+// resumeProtocolStream is called when reading from a swap's protocol stream
+// failed with the given error. If the stream dropped before the swap's funds
+// were locked, the taker re-dials the maker and the maker waits for the taker
+// to do so. It returns the new stream, or nil if the stream was not resumed,
+// in which case the swap exits as before.
+func (h *Host) resumeProtocolStream(offerID types.Hash, readErr error) libp2pnetwork.Stream {
+	// EOF means the peer closed the stream, so it did not drop
+	if h.maxResumeAttempts == 0 || readErr == nil ||
+		errors.Is(readErr, io.EOF) || errors.Is(readErr, errStreamReadTimeout) {
+		return nil
+	}
+
+	h.swapMu.RLock()
+	swap, has := h.swaps[offerID]
+	if !has || swap.closed || swap.ethLockedNotified {
+		h.swapMu.RUnlock()
+		return nil
+	}
+	isTaker := swap.isTaker
+	keysReceived := swap.keysReceived
+	remotePeer := swap.stream.Conn().RemotePeer()
+	h.swapMu.RUnlock()
+
+	log.Infof("protocol stream of swap %s with peer=%s dropped, attempting to resume it: %s",
+		offerID, remotePeer, readErr)
+
+	var stream libp2pnetwork.Stream
+	if isTaker {
+		stream = h.redialProtocolStream(offerID, remotePeer, keysReceived)
+	} else {
+		stream = h.waitForResumedStream(offerID)
+	}
+
+	if stream == nil {
+		log.Warnf("failed to resume protocol stream of swap %s", offerID)
+		return nil
+	}
+
+	log.Infof("resumed protocol stream of swap %s with peer=%s", offerID, remotePeer)
+	return stream
+}
+
+// redialProtocolStream is called by the taker to open a new protocol stream
+// with the maker. It returns nil if all attempts failed or the swap exited.
+func (h *Host) redialProtocolStream(offerID types.Hash, who peer.ID, keysReceived bool) libp2pnetwork.Stream {
+	for attempt := uint(1); attempt <= h.maxResumeAttempts; attempt++ {
+		select {
+		case <-h.ctx.Done():
+			return nil
+		case <-time.After(h.resumeInterval):
+		}
+
+		stream, err := h.openResumeStream(offerID, who, keysReceived)
+		if err != nil {
+			log.Warnf("failed to resume protocol stream of swap %s (attempt %d/%d): %s",
+				offerID, attempt, h.maxResumeAttempts, err)
+			continue
+		}
+
+		h.swapMu.Lock()
+		swap, has := h.swaps[offerID]
+		if !has || swap.closed {
+			h.swapMu.Unlock()
+			_ = stream.Close()
+			return nil
+		}
+		swap.stream = stream
+		h.swapMu.Unlock()
+
+		return stream
+	}
+
+	return nil
+}
+
+func (h *Host) openResumeStream(offerID types.Hash, who peer.ID, keysReceived bool) (libp2pnetwork.Stream, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, connectionTimeout)
+	defer cancel()
+
+	if h.h.Connectedness(who) != libp2pnetwork.Connected {
+		err := h.h.Connect(ctx, peer.AddrInfo{ID: who})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := h.h.NewStream(ctx, who, protocol.ID(swapResumeID))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &ResumeSwapMessage{
+		OfferID:      offerID,
+		KeysReceived: keysReceived,
+	}
+	if err := p2pnet.WriteStreamMessage(stream, msg, who); err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// waitForResumedStream is called by the maker to wait for the taker to resume
+// the swap's protocol stream. It returns nil if the taker does not do so
+// before all of its attempts could have been made, or if the swap exited.
+func (h *Host) waitForResumedStream(offerID types.Hash) libp2pnetwork.Stream {
+	resumeCh := make(chan libp2pnetwork.Stream, 1)
+
+	h.swapMu.Lock()
+	swap, has := h.swaps[offerID]
+	if !has || swap.closed {
+		h.swapMu.Unlock()
+		return nil
+	}
+	swap.resumeCh = resumeCh
+	h.swapMu.Unlock()
+
+	defer func() {
+		h.swapMu.Lock()
+		defer h.swapMu.Unlock()
+		if swap.resumeCh == resumeCh {
+			swap.resumeCh = nil
+		}
+
+		// a stream may have been resumed just as we stopped waiting
+		select {
+		case stream := <-resumeCh:
+			if stream != nil {
+				_ = stream.Close()
+			}
+		default:
+		}
+	}()
+
+	timeout := time.Duration(h.maxResumeAttempts) * (h.resumeInterval + connectionTimeout)
+	select {
+	case stream := <-resumeCh:
+		return stream
+	case <-time.After(timeout):
+		return nil
+	case <-h.ctx.Done():
+		return nil
+	}
+}
+
+// handleResumeStream is called when there is an incoming stream from a taker
+// resuming the dropped protocol stream of a swap.
+func (h *Host) handleResumeStream(stream libp2pnetwork.Stream) {
+	msg, err := readStreamMessage(stream, maxMessageSize)
+	if err != nil {
+		log.Debugf("Failed to read message from peer, stream-id=%s: %s", stream.ID(), err)
+		_ = stream.Close()
+		return
+	}
+
+	remotePeer := stream.Conn().RemotePeer()
+
+	rm, ok := msg.(*ResumeSwapMessage)
+	if !ok {
+		log.Warnf("failed to handle resume stream from peer=%s: message was not ResumeSwapMessage", remotePeer)
+		_ = stream.Close()
+		return
+	}
+
+	h.swapMu.Lock()
+	swap, has := h.swaps[rm.OfferID]
+	if !has || swap.isTaker || swap.resumeCh == nil || swap.stream.Conn().RemotePeer() != remotePeer {
+		h.swapMu.Unlock()
+		log.Warnf("rejected request from peer=%s to resume the protocol stream of swap %s",
+			remotePeer, rm.OfferID)
+		_ = stream.Close()
+		return
+	}
+	resumeCh := swap.resumeCh
+	keysResponse := swap.keysResponse
+	h.swapMu.Unlock()
+
+	// the lock is not held while writing, so that a slow peer does not
+	// block our other swaps
+	if !rm.KeysReceived {
+		if err := p2pnet.WriteStreamMessage(stream, keysResponse, remotePeer); err != nil {
+			log.Warnf("failed to send response to peer: %s", err)
+			_ = stream.Close()
+			return
+		}
+	}
+
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	// we may have stopped waiting, or accepted another resumed stream, while
+	// the keys were being sent
+	if swap.resumeCh != resumeCh {
+		log.Warnf("swap %s is no longer waiting for its protocol stream to be resumed", rm.OfferID)
+		_ = stream.Close()
+		return
+	}
+
+	swap.stream = stream
+	swap.resumeCh <- stream
+	swap.resumeCh = nil
+}
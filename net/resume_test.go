@@ -0,0 +1,140 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package net
+
+import (
+	"testing"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p/core/network"
+	"github.com/stretchr/testify/require"
+)
+
+const resumeTestTimeout = 5 * time.Second
+
+// newResumeTestHosts returns a connected taker (ha) and maker (hb). The swap
+// states created by the maker close makerExitCh, if set, when they exit.
+func newResumeTestHosts(t *testing.T, maxResumeAttempts uint, makerExitCh chan struct{}) (*Host, *Host) {
+	cfgA := basicTestConfig(t)
+	cfgA.MaxStreamResumeAttempts = maxResumeAttempts
+	ha := newHost(t, cfgA)
+	ha.resumeInterval = time.Millisecond * 100
+	require.NoError(t, ha.Start())
+
+	cfgB := basicTestConfig(t)
+	cfgB.MaxStreamResumeAttempts = maxResumeAttempts
+	hb := newHost(t, cfgB)
+	hb.SetHandlers(&mockMakerHandler{t: t, exitCh: makerExitCh}, &mockRelayHandler{t: t})
+	hb.resumeInterval = time.Millisecond * 100
+	require.NoError(t, hb.Start())
+
+	err := ha.h.Connect(ha.ctx, hb.h.AddrInfo())
+	require.NoError(t, err)
+
+	return ha, hb
+}
+
+// initiateResumeTestSwap initiates a swap from ha with hb, returning once the
+// taker handled the maker's response.
+func initiateResumeTestSwap(t *testing.T, ha *Host, hb *Host, takerState *mockSwapState) {
+	err := ha.Initiate(hb.h.AddrInfo(), createSendKeysMessage(t), takerState)
+	require.NoError(t, err)
+	waitForHandledMessage(t, takerState)
+}
+
+func waitForHandledMessage(t *testing.T, s *mockSwapState) {
+	select {
+	case <-s.handledCh:
+	case <-time.After(resumeTestTimeout):
+		t.Fatal("timed out waiting for a protocol message")
+	}
+}
+
+func waitForExit(t *testing.T, exitCh <-chan struct{}) {
+	select {
+	case <-exitCh:
+	case <-time.After(resumeTestTimeout):
+		t.Fatal("timed out waiting for the swap to exit")
+	}
+}
+
+// protocolStream returns the current protocol stream of the test swap, or nil
+// if the swap exited.
+func protocolStream(h *Host) libp2pnetwork.Stream {
+	h.swapMu.RLock()
+	defer h.swapMu.RUnlock()
+	if swap, has := h.swaps[testID]; has {
+		return swap.stream
+	}
+	return nil
+}
+
+func TestHost_ResumeProtocolStream(t *testing.T) {
+	ha, hb := newResumeTestHosts(t, 3, nil)
+
+	// signals once the maker accepted a resumed stream
+	resumedCh := make(chan struct{})
+	hb.h.SetStreamHandler(swapResumeID, func(stream libp2pnetwork.Stream) {
+		hb.handleResumeStream(stream)
+		if protocolStream(hb) == stream {
+			close(resumedCh)
+		}
+	})
+
+	takerState := &mockSwapState{handledCh: make(chan Message, 1)}
+	initiateResumeTestSwap(t, ha, hb, takerState)
+
+	// drop the stream after the keys were exchanged, but before the taker
+	// notified the maker that its ETH is locked
+	droppedStream := protocolStream(ha)
+	require.NoError(t, droppedStream.Reset())
+
+	select {
+	case <-resumedCh:
+	case <-time.After(resumeTestTimeout):
+		t.Fatal("timed out waiting for the stream to be resumed")
+	}
+
+	// the resumed stream is used for the rest of the swap, in both directions
+	err := hb.SendSwapMessage(createSendKeysMessage(t), testID)
+	require.NoError(t, err)
+	waitForHandledMessage(t, takerState)
+
+	resumedStream := protocolStream(ha)
+	require.NotEqual(t, droppedStream.ID(), resumedStream.ID())
+	require.Equal(t, ha.h.PeerID(), protocolStream(hb).Conn().RemotePeer())
+
+	err = ha.SendSwapMessage(createSendKeysMessage(t), testID)
+	require.NoError(t, err)
+}
+
+func TestHost_ResumeProtocolStream_disabled(t *testing.T) {
+	makerExitCh := make(chan struct{})
+	ha, hb := newResumeTestHosts(t, 0, makerExitCh)
+
+	takerState := &mockSwapState{handledCh: make(chan Message, 1), exitCh: make(chan struct{})}
+	initiateResumeTestSwap(t, ha, hb, takerState)
+
+	require.NoError(t, protocolStream(ha).Reset())
+
+	// neither side resumes the stream
+	waitForExit(t, takerState.exitCh)
+	waitForExit(t, makerExitCh)
+}
+
+func TestHost_ResumeProtocolStream_afterETHLocked(t *testing.T) {
+	ha, hb := newResumeTestHosts(t, 3, nil)
+
+	takerState := &mockSwapState{handledCh: make(chan Message, 1), exitCh: make(chan struct{})}
+	initiateResumeTestSwap(t, ha, hb, takerState)
+
+	ha.swapMu.Lock()
+	ha.swaps[testID].ethLockedNotified = true
+	droppedStream := ha.swaps[testID].stream
+	ha.swapMu.Unlock()
+	require.NoError(t, droppedStream.Reset())
+
+	// the taker does not resume the stream once its ETH is locked
+	waitForExit(t, takerState.exitCh)
+}
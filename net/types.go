@@ -4,6 +4,8 @@
 package net
 
 import (
+	"time"
+
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/common"
@@ -21,8 +23,10 @@ type (
 	Message            = common.Message
 	QueryResponse      = message.QueryResponse
 	SendKeysMessage    = message.SendKeysMessage
+	ResumeSwapMessage  = message.ResumeSwapMessage
 	RelayClaimRequest  = message.RelayClaimRequest
 	RelayClaimResponse = message.RelayClaimResponse
+	RelayerFeeResponse = message.RelayerFeeResponse
 )
 
 // MakerHandler handles swap initiation messages and offer queries. It is
@@ -42,5 +46,30 @@ type swap struct {
 	swapState SwapState
 	stream    libp2pnetwork.Stream
 	// isTaker is true if we initiated the swap (created the outbound stream)
-	isTaker bool
+	isTaker  bool
+	openedAt time.Time
+
+	// keysResponse is the maker's response to the taker's SendKeysMessage. It
+	// is kept so that it can be sent again on a resumed stream.
+	keysResponse Message
+	// keysReceived is set once the taker received the maker's keys.
+	keysReceived bool
+	// ethLockedNotified is set once the taker sent, or the maker received, the
+	// NotifyETHLocked message. Dropped streams are only resumed before this
+	// point, as the swap's funds are not locked yet.
+	ethLockedNotified bool
+	// closed is set once we closed the stream, so that it is not resumed.
+	closed bool
+	// resumeCh is set while the maker waits for the taker to resume the
+	// dropped stream.
+	resumeCh chan libp2pnetwork.Stream
+}
+
+// ProtocolStreamInfo describes an open swap protocol stream.
+type ProtocolStreamInfo struct {
+	PeerID  peer.ID
+	OfferID types.Hash
+	// IsTaker is true if we opened the stream
+	IsTaker  bool
+	OpenedAt time.Time
 }
@@ -0,0 +1,101 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+)
+
+const (
+	coinGeckoPriceURL = "https://api.coingecko.com/api/v3/simple/price" +
+		"?ids=ethereum,monero&vs_currencies=usd&include_last_updated_at=true"
+
+	coinGeckoETHID = "ethereum"
+	coinGeckoXMRID = "monero"
+
+	coinGeckoRequestTimeout = 10 * time.Second
+)
+
+// coinGeckoPrice is the price of a coin in the response of CoinGecko's simple
+// price API.
+type coinGeckoPrice struct {
+	USD           json.Number `json:"usd"`
+	LastUpdatedAt int64       `json:"last_updated_at"`
+}
+
+// coinGeckoProvider reads prices from CoinGecko's public API.
+type coinGeckoProvider struct {
+	url    string
+	client *http.Client
+}
+
+// NewCoinGeckoProvider returns a provider that reads prices from CoinGecko's
+// public API.
+func NewCoinGeckoProvider() Provider {
+	return &coinGeckoProvider{
+		url:    coinGeckoPriceURL,
+		client: &http.Client{Timeout: coinGeckoRequestTimeout},
+	}
+}
+
+func (p *coinGeckoProvider) Name() string {
+	return CoinGeckoProviderName
+}
+
+func (p *coinGeckoProvider) GetETHUSDPrice(ctx context.Context) (*PriceFeed, error) {
+	return p.getPrice(ctx, coinGeckoETHID, "ETH / USD")
+}
+
+func (p *coinGeckoProvider) GetXMRUSDPrice(ctx context.Context) (*PriceFeed, error) {
+	return p.getPrice(ctx, coinGeckoXMRID, "XMR / USD")
+}
+
+func (p *coinGeckoProvider) getPrice(ctx context.Context, coinID string, description string) (*PriceFeed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coingecko returned status %s", resp.Status)
+	}
+
+	prices := make(map[string]*coinGeckoPrice)
+	if err = json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return nil, fmt.Errorf("failed to decode coingecko prices: %w", err)
+	}
+
+	coinPrice, ok := prices[coinID]
+	if !ok || coinPrice == nil {
+		return nil, fmt.Errorf("coingecko did not return the %s price", coinID)
+	}
+
+	price, _, err := apd.NewFromString(coinPrice.USD.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid coingecko %s price %q: %w", coinID, coinPrice.USD, err)
+	}
+	if price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid coingecko %s price %s", coinID, price)
+	}
+
+	updatedAt := time.Unix(coinPrice.LastUpdatedAt, 0)
+	log.Debugf("%s: $%s (%s)", description, price, updatedAt)
+	return &PriceFeed{
+		Description: description,
+		Price:       price,
+		UpdatedAt:   updatedAt,
+	}, nil
+}
@@ -36,6 +36,7 @@ const (
 
 var (
 	errUnsupportedNetwork = errors.New("unsupported network")
+	errNoPriceProviders   = errors.New("no price providers")
 	log                   = logging.Logger("pricefeed")
 )
 
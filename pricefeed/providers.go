@@ -0,0 +1,148 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// Provider names that can be passed to NewProvider, besides the URL of an
+// Ethereum endpoint.
+const (
+	ChainlinkProviderName = "chainlink"
+	CoinGeckoProviderName = "coingecko"
+)
+
+// Provider is a source of the ETH/USD and XMR/USD prices.
+type Provider interface {
+	// Name identifies the provider that a rate came from.
+	Name() string
+	GetETHUSDPrice(ctx context.Context) (*PriceFeed, error)
+	GetXMRUSDPrice(ctx context.Context) (*PriceFeed, error)
+}
+
+// chainlinkProvider reads the Chainlink price feeds with an Ethereum client.
+type chainlinkProvider struct {
+	name string
+	ec   *ethclient.Client
+}
+
+// NewChainlinkProvider returns a provider that reads the Chainlink price feeds
+// with the given client, like GetETHUSDPrice and GetXMRUSDPrice.
+func NewChainlinkProvider(ec *ethclient.Client) Provider {
+	return &chainlinkProvider{name: ChainlinkProviderName, ec: ec}
+}
+
+func (p *chainlinkProvider) Name() string {
+	return p.name
+}
+
+func (p *chainlinkProvider) GetETHUSDPrice(ctx context.Context) (*PriceFeed, error) {
+	return GetETHUSDPrice(ctx, p.ec)
+}
+
+func (p *chainlinkProvider) GetXMRUSDPrice(ctx context.Context) (*PriceFeed, error) {
+	return GetXMRUSDPrice(ctx, p.ec)
+}
+
+// NewProvider returns the provider with the given name. The chainlink provider
+// reads the Chainlink price feeds with the given client. The URL of an
+// Ethereum endpoint gives a provider that reads the Chainlink price feeds from
+// that endpoint instead.
+func NewProvider(name string, ec *ethclient.Client) (Provider, error) {
+	switch name {
+	case ChainlinkProviderName:
+		return NewChainlinkProvider(ec), nil
+	case CoinGeckoProviderName:
+		return NewCoinGeckoProvider(), nil
+	}
+
+	endpoint, err := url.Parse(name)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("unknown price provider %q, expected %q, %q or an http(s) Ethereum endpoint",
+			name, ChainlinkProviderName, CoinGeckoProviderName)
+	}
+
+	endpointEC, err := ethclient.Dial(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chainlinkProvider{
+		name: fmt.Sprintf("%s (%s)", ChainlinkProviderName, endpoint.Host),
+		ec:   endpointEC,
+	}, nil
+}
+
+// Rate is the XMR/ETH exchange rate calculated from the prices of a provider.
+type Rate struct {
+	Provider     string
+	ETHFeed      *PriceFeed
+	XMRFeed      *PriceFeed
+	ExchangeRate *coins.ExchangeRate
+}
+
+// GetExchangeRate returns the exchange rate calculated from the prices of the
+// first provider, in order, that returns both prices without error. If
+// maxAge is non-zero, providers with a price updated longer than maxAge ago
+// are skipped as stale.
+func GetExchangeRate(ctx context.Context, providers []Provider, maxAge time.Duration) (*Rate, error) {
+	var errs *multierror.Error
+	for _, provider := range providers {
+		rate, err := getProviderExchangeRate(ctx, provider, maxAge)
+		if err == nil {
+			return rate, nil
+		}
+
+		log.Warnf("price provider %s failed: %s", provider.Name(), err)
+		errs = multierror.Append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	if errs == nil {
+		return nil, errNoPriceProviders
+	}
+
+	return nil, fmt.Errorf("all price providers failed: %w", errs.ErrorOrNil())
+}
+
+func getProviderExchangeRate(ctx context.Context, provider Provider, maxAge time.Duration) (*Rate, error) {
+	xmrFeed, err := provider.GetXMRUSDPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ethFeed, err := provider.GetETHUSDPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxAge != 0 {
+		for _, feed := range []*PriceFeed{xmrFeed, ethFeed} {
+			if age := time.Since(feed.UpdatedAt); age > maxAge {
+				return nil, fmt.Errorf("%s price is stale, last updated %s ago",
+					feed.Description, age.Round(time.Second))
+			}
+		}
+	}
+
+	exchangeRate, err := coins.CalcExchangeRate(xmrFeed.Price, ethFeed.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rate{
+		Provider:     provider.Name(),
+		ETHFeed:      ethFeed,
+		XMRFeed:      xmrFeed,
+		ExchangeRate: exchangeRate,
+	}, nil
+}
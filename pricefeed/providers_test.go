@@ -0,0 +1,100 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	name      string
+	err       error
+	updatedAt time.Time
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) GetETHUSDPrice(_ context.Context) (*PriceFeed, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &PriceFeed{Description: "ETH / USD", Price: apd.New(2000, 0), UpdatedAt: p.updatedAt}, nil
+}
+
+func (p *fakeProvider) GetXMRUSDPrice(_ context.Context) (*PriceFeed, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &PriceFeed{Description: "XMR / USD", Price: apd.New(150, 0), UpdatedAt: p.updatedAt}, nil
+}
+
+func TestGetExchangeRate_fallback(t *testing.T) {
+	ctx := context.Background()
+	down := &fakeProvider{name: "down", err: errors.New("unavailable")}
+	stale := &fakeProvider{name: "stale", updatedAt: time.Now().Add(-2 * time.Hour)}
+	fresh := &fakeProvider{name: "fresh", updatedAt: time.Now()}
+
+	rate, err := GetExchangeRate(ctx, []Provider{down, stale, fresh}, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "fresh", rate.Provider)
+	require.Equal(t, "0.075", rate.ExchangeRate.String())
+
+	// without a maximum age, stale prices are used
+	rate, err = GetExchangeRate(ctx, []Provider{down, stale, fresh}, 0)
+	require.NoError(t, err)
+	require.Equal(t, "stale", rate.Provider)
+
+	_, err = GetExchangeRate(ctx, []Provider{down, stale}, time.Hour)
+	require.ErrorContains(t, err, "all price providers failed")
+	require.ErrorContains(t, err, "down: unavailable")
+	require.ErrorContains(t, err, "stale: XMR / USD price is stale")
+
+	_, err = GetExchangeRate(ctx, nil, time.Hour)
+	require.ErrorIs(t, err, errNoPriceProviders)
+}
+
+func TestCoinGeckoProvider(t *testing.T) {
+	updatedAt := time.Now().Add(-time.Minute).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"ethereum":{"usd":1834.27,"last_updated_at":%d},"monero":{"usd":160.5,"last_updated_at":%d}}`,
+			updatedAt, updatedAt)
+	}))
+	defer server.Close()
+
+	p := NewCoinGeckoProvider().(*coinGeckoProvider)
+	p.url = server.URL
+
+	feed, err := p.GetETHUSDPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1834.27", feed.Price.String())
+	require.Equal(t, updatedAt, feed.UpdatedAt.Unix())
+
+	feed, err = p.GetXMRUSDPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "160.5", feed.Price.String())
+}
+
+func TestNewProvider(t *testing.T) {
+	p, err := NewProvider(CoinGeckoProviderName, nil)
+	require.NoError(t, err)
+	require.Equal(t, CoinGeckoProviderName, p.Name())
+
+	p, err = NewProvider("https://eth.example.com", nil)
+	require.NoError(t, err)
+	require.Equal(t, "chainlink (eth.example.com)", p.Name())
+
+	_, err = NewProvider("binance", nil)
+	require.ErrorContains(t, err, `unknown price provider "binance"`)
+}
@@ -9,12 +9,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
@@ -23,16 +25,27 @@ import (
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
+const (
+	// DefaultEventChSize is the default buffer size of a swap's event channel.
+	DefaultEventChSize = 1
+
+	// DefaultLogChSize is the default buffer size of the channels on which a
+	// swap's contract event watchers deliver logs.
+	DefaultLogChSize = 16
+)
+
 // NetSender consists of Host methods invoked by the Maker/Taker
 type NetSender interface {
 	SendSwapMessage(common.Message, types.Hash) error
 	CloseProtocolStream(id types.Hash)
 	DiscoverRelayers() ([]peer.ID, error)                                                          // Only used by Maker
+	QueryRelayerFee(peer.ID) (*coins.WeiAmount, error)                                             // Only used by Maker
 	SubmitClaimToRelayer(peer.ID, *message.RelayClaimRequest) (*message.RelayClaimResponse, error) // Only used by Taker
 }
 
@@ -48,6 +61,8 @@ type RecoveryDB interface {
 	GetSwapRelayerInfo(id types.Hash) (*types.OfferExtra, error)
 	PutCounterpartySwapKeys(id types.Hash, sk *mcrypto.PublicKey, vk *mcrypto.PrivateViewKey) error
 	GetCounterpartySwapKeys(id types.Hash) (*mcrypto.PublicKey, *mcrypto.PrivateViewKey, error)
+	PutCounterpartyDLEqProof(id types.Hash, proof *db.CounterpartyDLEqProof) error
+	GetCounterpartyDLEqProof(id types.Hash) (*db.CounterpartyDLEqProof, error)
 	DeleteSwap(id types.Hash) error
 }
 
@@ -73,11 +88,15 @@ type Backend interface {
 	SwapManager() swap.Manager
 	SwapCreator() *contracts.SwapCreator
 	SwapCreatorAddr() ethcommon.Address
-	SwapTimeout() time.Duration
+	SwapTimeout() (t0Duration time.Duration, t1Duration time.Duration)
+	EventChSize() int
+	LogChSize() int
 	XMRDepositAddress(offerID *types.Hash) *mcrypto.Address
+	GasBalanceStatus() *GasBalanceStatus
+	RelayLimiterStatus() *relayer.LimiterStatus
 
 	// setters
-	SetSwapTimeout(timeout time.Duration)
+	SetSwapTimeout(t0Duration time.Duration, t1Duration time.Duration)
 	SetXMRDepositAddress(*mcrypto.Address, types.Hash)
 	ClearXMRDepositAddress(types.Hash)
 }
@@ -103,10 +122,32 @@ type backend struct {
 	// swap contract
 	swapCreator     *contracts.SwapCreator
 	swapCreatorAddr ethcommon.Address
-	swapTimeout     time.Duration
+	t0Duration      time.Duration
+	t1Duration      time.Duration
+
+	// gas price settings of swap transactions
+	gasConfig txsender.GasConfig
+
+	// per-swap channel buffer sizes
+	eventChSize int
+	logChSize   int
+
+	// the fee that we require to relay claims for makers that are not our
+	// swap counterparty
+	relayerFee *big.Int
+
+	// the swaps whose claims we relayed, nil if they aren't persisted
+	relayedSwapsDB relayer.RelayedSwapsDB
+
+	// bounds the number of claims that we relay concurrently, nil if there
+	// is no limit
+	relayLimiter *relayer.Limiter
 
 	// network interface
 	NetSender
+
+	// set if a minimum gas balance is configured
+	gasMonitor *gasBalanceMonitor
 }
 
 // Config is the config for the Backend
@@ -119,6 +160,58 @@ type Config struct {
 	SwapManager     swap.Manager
 	RecoveryDB      RecoveryDB
 	Net             NetSender
+
+	// MinETHBalance, if set, is the ETH balance below which a warning is
+	// logged and reported, so that the operator tops up before swaps start
+	// failing for lack of gas.
+	MinETHBalance *coins.WeiAmount
+
+	// MaxGasPrice, if set, is the gas price above which swap transactions are
+	// not sent. Instead, an error is returned, so that the swap can be retried
+	// once gas prices are lower.
+	MaxGasPrice *coins.WeiAmount
+
+	// MaxFeePerGas and MaxPriorityFeePerGas, if either is set, make swap
+	// transactions use EIP-1559 fees instead of a legacy gas price.
+	MaxFeePerGas         *coins.WeiAmount
+	MaxPriorityFeePerGas *coins.WeiAmount
+
+	// EventChSize is the buffer size of each swap's event channel. If zero,
+	// DefaultEventChSize is used.
+	EventChSize int
+
+	// LogChSize is the buffer size of the channels on which each swap's
+	// contract event watchers deliver logs. If zero, DefaultLogChSize is used.
+	// A watcher whose channel is full waits for the swap to read from it, so
+	// no logs are dropped, and stops waiting once the swap exits.
+	LogChSize int
+
+	// RelayerFee is the minimum fee that we require to relay a claim for a
+	// maker that is not our swap counterparty. If nil, coins.RelayerFeeWei is
+	// used.
+	RelayerFee *coins.WeiAmount
+
+	// RelayedSwapsDB, if set, persists the swaps whose claims we relayed, so
+	// that repeated claim requests are rejected without querying the chain.
+	RelayedSwapsDB relayer.RelayedSwapsDB
+
+	// MaxConcurrentRelays, if non-zero, is the number of claims that we relay
+	// at a time. Further claim requests wait up to RelayQueueTimeout for one
+	// of them to finish, and are then rejected with relayer.ErrRelayerBusy.
+	MaxConcurrentRelays uint32
+	RelayQueueTimeout   time.Duration
+
+	// RateSampleInterval, if non-zero, is how often the suggested exchange
+	// rate is sampled and stored in RateSampleDB.
+	RateSampleInterval time.Duration
+	RateSampleDB       RateSampleDB
+
+	// PriceProviders are the sources of the sampled exchange rate, in the
+	// order they are tried, skipping those with prices older than MaxPriceAge
+	// if it is non-zero. If there are no providers, the Chainlink price feeds
+	// are read with the EthereumClient.
+	PriceProviders []pricefeed.Provider
+	MaxPriceAge    time.Duration
 }
 
 // NewBackend returns a new Backend
@@ -127,12 +220,21 @@ func NewBackend(cfg *Config) (Backend, error) {
 		return nil, errNilSwapContractOrAddress
 	}
 
+	if cfg.EventChSize < 0 || cfg.LogChSize < 0 {
+		return nil, errNegativeChSize
+	}
+
+	if cfg.MaxFeePerGas != nil && cfg.MaxPriorityFeePerGas != nil &&
+		cfg.MaxPriorityFeePerGas.Cmp(cfg.MaxFeePerGas) > 0 {
+		return nil, errPriorityFeeAboveMaxFee
+	}
+
 	swapCreator, err := contracts.NewSwapCreator(cfg.SwapCreatorAddr, cfg.EthereumClient.Raw())
 	if err != nil {
 		return nil, err
 	}
 
-	return &backend{
+	b := &backend{
 		ctx:                   cfg.Ctx,
 		env:                   cfg.Environment,
 		moneroWallet:          cfg.MoneroClient,
@@ -140,11 +242,53 @@ func NewBackend(cfg *Config) (Backend, error) {
 		swapCreator:           swapCreator,
 		swapCreatorAddr:       cfg.SwapCreatorAddr,
 		swapManager:           cfg.SwapManager,
-		swapTimeout:           common.SwapTimeoutFromEnv(cfg.Environment),
+		t0Duration:            common.SwapTimeoutFromEnv(cfg.Environment),
+		t1Duration:            common.SwapTimeoutFromEnv(cfg.Environment),
 		NetSender:             cfg.Net,
 		perSwapXMRDepositAddr: make(map[types.Hash]*mcrypto.Address),
 		recoveryDB:            cfg.RecoveryDB,
-	}, nil
+		eventChSize:           cfg.EventChSize,
+		logChSize:             cfg.LogChSize,
+		relayerFee:            coins.RelayerFeeWei,
+		relayedSwapsDB:        cfg.RelayedSwapsDB,
+		relayLimiter:          relayer.NewLimiter(cfg.MaxConcurrentRelays, cfg.RelayQueueTimeout),
+	}
+
+	if cfg.RelayerFee != nil {
+		b.relayerFee = cfg.RelayerFee.BigInt()
+	}
+
+	if cfg.MaxGasPrice != nil {
+		b.gasConfig.MaxGasPrice = cfg.MaxGasPrice.BigInt()
+	}
+	if cfg.MaxFeePerGas != nil {
+		b.gasConfig.MaxFeePerGas = cfg.MaxFeePerGas.BigInt()
+	}
+	if cfg.MaxPriorityFeePerGas != nil {
+		b.gasConfig.MaxPriorityFeePerGas = cfg.MaxPriorityFeePerGas.BigInt()
+	}
+
+	if b.eventChSize == 0 {
+		b.eventChSize = DefaultEventChSize
+	}
+	if b.logChSize == 0 {
+		b.logChSize = DefaultLogChSize
+	}
+
+	if cfg.MinETHBalance != nil {
+		b.gasMonitor = &gasBalanceMonitor{threshold: cfg.MinETHBalance}
+		go b.runGasBalanceMonitor()
+	}
+
+	if cfg.RateSampleInterval > 0 && cfg.RateSampleDB != nil {
+		providers := cfg.PriceProviders
+		if len(providers) == 0 {
+			providers = []pricefeed.Provider{pricefeed.NewChainlinkProvider(cfg.EthereumClient.Raw())}
+		}
+		go b.runRateSampler(cfg.RateSampleInterval, cfg.RateSampleDB, providers, cfg.MaxPriceAge)
+	}
+
+	return b, nil
 }
 
 func (b *backend) XMRClient() monero.WalletClient {
@@ -157,10 +301,11 @@ func (b *backend) ETHClient() extethclient.EthClient {
 
 func (b *backend) NewTxSender(asset ethcommon.Address, erc20Contract *contracts.IERC20) (txsender.Sender, error) {
 	if !b.ethClient.HasPrivateKey() {
-		return txsender.NewExternalSender(b.ctx, b.env, b.ethClient.Raw(), b.swapCreatorAddr, asset)
+		return txsender.NewExternalSender(b.env, b.ethClient.Raw(), b.swapCreatorAddr, asset, b.gasConfig)
 	}
 
-	return txsender.NewSenderWithPrivateKey(b.ctx, b.ETHClient(), b.swapCreatorAddr, b.swapCreator, erc20Contract), nil
+	return txsender.NewSenderWithPrivateKey(b.ETHClient(), b.swapCreatorAddr, b.swapCreator, erc20Contract,
+		b.gasConfig), nil
 }
 
 func (b *backend) RecoveryDB() RecoveryDB {
@@ -187,14 +332,28 @@ func (b *backend) SwapManager() swap.Manager {
 	return b.swapManager
 }
 
-func (b *backend) SwapTimeout() time.Duration {
-	return b.swapTimeout
+// SwapTimeout returns the duration between the swap being initiated on-chain
+// and the timeout t0, and the duration between t0 and t1.
+func (b *backend) SwapTimeout() (time.Duration, time.Duration) {
+	return b.t0Duration, b.t1Duration
+}
+
+// EventChSize returns the buffer size of each swap's event channel.
+func (b *backend) EventChSize() int {
+	return b.eventChSize
+}
+
+// LogChSize returns the buffer size of the channels on which each swap's
+// contract event watchers deliver logs.
+func (b *backend) LogChSize() int {
+	return b.logChSize
 }
 
 // SetSwapTimeout sets the duration between the swap being initiated on-chain and the timeout t0,
 // and the duration between t0 and t1.
-func (b *backend) SetSwapTimeout(timeout time.Duration) {
-	b.swapTimeout = timeout
+func (b *backend) SetSwapTimeout(t0Duration time.Duration, t1Duration time.Duration) {
+	b.t0Duration = t0Duration
+	b.t1Duration = t1Duration
 }
 
 func (b *backend) NewSwapCreator(addr ethcommon.Address) (*contracts.SwapCreator, error) {
@@ -236,6 +395,12 @@ func (b *backend) ClearXMRDepositAddress(offerID types.Hash) {
 	delete(b.perSwapXMRDepositAddr, offerID)
 }
 
+// RelayLimiterStatus returns the number of claims being relayed and waiting to
+// be relayed. It returns nil if the number of concurrent relays is unlimited.
+func (b *backend) RelayLimiterStatus() *relayer.LimiterStatus {
+	return b.relayLimiter.Status()
+}
+
 // HandleRelayClaimRequest validates and sends the transaction for a relay claim request
 func (b *backend) HandleRelayClaimRequest(
 	remotePeer peer.ID,
@@ -278,10 +443,25 @@ func (b *backend) HandleRelayClaimRequest(
 		}
 	}
 
+	// As the taker, we relay our counterparty's claim for the default fee,
+	// even if we charge more to relay for everyone.
+	minFee := b.relayerFee
+	if request.OfferID != nil {
+		minFee = coins.RelayerFeeWei
+	}
+
+	release, err := b.relayLimiter.Acquire(b.Ctx())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	return relayer.ValidateAndSendTransaction(
 		b.Ctx(),
 		request,
 		b.ETHClient(),
 		b.SwapCreatorAddr(),
+		minFee,
+		b.relayedSwapsDB,
 	)
 }
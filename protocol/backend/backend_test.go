@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/tests"
 
@@ -50,3 +51,12 @@ func TestWaitForReceipt(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, tx.Hash(), receipt.TxHash)
 }
+
+func TestNewBackend_priorityFeeAboveMaxFee(t *testing.T) {
+	_, err := NewBackend(&Config{
+		SwapCreatorAddr:      ethcommon.Address{0x1},
+		MaxFeePerGas:         coins.NewWeiAmount(big.NewInt(10)),
+		MaxPriorityFeePerGas: coins.NewWeiAmount(big.NewInt(11)),
+	})
+	require.ErrorIs(t, err, errPriorityFeeAboveMaxFee)
+}
@@ -9,4 +9,6 @@ import (
 
 var (
 	errNilSwapContractOrAddress = errors.New("must provide swap contract and address")
+	errNegativeChSize           = errors.New("channel buffer sizes cannot be negative")
+	errPriorityFeeAboveMaxFee   = errors.New("max priority fee per gas cannot be above the max fee per gas")
 )
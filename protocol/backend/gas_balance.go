@@ -0,0 +1,99 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package backend
+
+import (
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+// gasBalanceCheckInterval is how often the ETH balance is compared to the
+// minimum gas balance.
+const gasBalanceCheckInterval = time.Minute
+
+var log = logging.Logger("backend")
+
+// GasBalanceStatus is the result of the most recent comparison of our ETH
+// balance to the configured minimum gas balance.
+type GasBalanceStatus struct {
+	Balance   *coins.WeiAmount
+	Threshold *coins.WeiAmount
+	IsLow     bool
+	CheckedAt time.Time
+}
+
+// gasBalanceMonitor periodically checks that we have enough ETH to pay for
+// gas, warning the operator when the balance drops below the threshold.
+type gasBalanceMonitor struct {
+	threshold *coins.WeiAmount
+
+	mu     sync.RWMutex
+	status *GasBalanceStatus
+}
+
+// update records the latest balance, logging when the balance drops below or
+// recovers above the threshold.
+func (m *gasBalanceMonitor) update(balance *coins.WeiAmount) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	isLow := balance.Cmp(m.threshold) < 0
+	wasLow := m.status != nil && m.status.IsLow
+
+	switch {
+	case isLow && !wasLow:
+		log.Warnf("ETH balance %s ETH is below the minimum gas balance of %s ETH, swaps may fail for lack of gas",
+			balance.AsEtherString(), m.threshold.AsEtherString())
+	case !isLow && wasLow:
+		log.Infof("ETH balance %s ETH is no longer below the minimum gas balance of %s ETH",
+			balance.AsEtherString(), m.threshold.AsEtherString())
+	}
+
+	m.status = &GasBalanceStatus{
+		Balance:   balance,
+		Threshold: m.threshold,
+		IsLow:     isLow,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (m *gasBalanceMonitor) getStatus() *GasBalanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+func (b *backend) runGasBalanceMonitor() {
+	ticker := time.NewTicker(gasBalanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		balance, err := b.ethClient.Balance(b.ctx)
+		if err != nil {
+			log.Warnf("failed to get ETH balance for gas balance check: %s", err)
+		} else {
+			b.gasMonitor.update(balance)
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GasBalanceStatus returns the result of the most recent check of our ETH
+// balance against the minimum gas balance. It returns nil if no minimum is
+// configured or the balance has not been checked yet.
+func (b *backend) GasBalanceStatus() *GasBalanceStatus {
+	if b.gasMonitor == nil {
+		return nil
+	}
+	return b.gasMonitor.getStatus()
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+)
+
+func TestGasBalanceMonitor_update(t *testing.T) {
+	m := &gasBalanceMonitor{threshold: coins.EtherToWei(coins.StrToDecimal("0.1"))}
+	require.Nil(t, m.getStatus())
+
+	m.update(coins.EtherToWei(coins.StrToDecimal("0.5")))
+	status := m.getStatus()
+	require.False(t, status.IsLow)
+	require.Equal(t, "0.5", status.Balance.AsEtherString())
+	require.Equal(t, "0.1", status.Threshold.AsEtherString())
+
+	m.update(coins.EtherToWei(coins.StrToDecimal("0.09")))
+	require.True(t, m.getStatus().IsLow)
+
+	// a balance equal to the threshold is not low
+	m.update(coins.EtherToWei(coins.StrToDecimal("0.1")))
+	require.False(t, m.getStatus().IsLow)
+}
@@ -67,6 +67,21 @@ func (mr *MockRecoveryDBMockRecorder) GetContractSwapInfo(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractSwapInfo", reflect.TypeOf((*MockRecoveryDB)(nil).GetContractSwapInfo), arg0)
 }
 
+// GetCounterpartyDLEqProof mocks base method.
+func (m *MockRecoveryDB) GetCounterpartyDLEqProof(arg0 common.Hash) (*db.CounterpartyDLEqProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCounterpartyDLEqProof", arg0)
+	ret0, _ := ret[0].(*db.CounterpartyDLEqProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCounterpartyDLEqProof indicates an expected call of GetCounterpartyDLEqProof.
+func (mr *MockRecoveryDBMockRecorder) GetCounterpartyDLEqProof(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCounterpartyDLEqProof", reflect.TypeOf((*MockRecoveryDB)(nil).GetCounterpartyDLEqProof), arg0)
+}
+
 // GetCounterpartySwapKeys mocks base method.
 func (m *MockRecoveryDB) GetCounterpartySwapKeys(arg0 common.Hash) (*mcrypto.PublicKey, *mcrypto.PrivateViewKey, error) {
 	m.ctrl.T.Helper()
@@ -142,6 +157,20 @@ func (mr *MockRecoveryDBMockRecorder) PutContractSwapInfo(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutContractSwapInfo", reflect.TypeOf((*MockRecoveryDB)(nil).PutContractSwapInfo), arg0, arg1)
 }
 
+// PutCounterpartyDLEqProof mocks base method.
+func (m *MockRecoveryDB) PutCounterpartyDLEqProof(arg0 common.Hash, arg1 *db.CounterpartyDLEqProof) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutCounterpartyDLEqProof", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutCounterpartyDLEqProof indicates an expected call of PutCounterpartyDLEqProof.
+func (mr *MockRecoveryDBMockRecorder) PutCounterpartyDLEqProof(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutCounterpartyDLEqProof", reflect.TypeOf((*MockRecoveryDB)(nil).PutCounterpartyDLEqProof), arg0, arg1)
+}
+
 // PutCounterpartySwapKeys mocks base method.
 func (m *MockRecoveryDB) PutCounterpartySwapKeys(arg0 common.Hash, arg1 *mcrypto.PublicKey, arg2 *mcrypto.PrivateViewKey) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package backend
+
+import (
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+)
+
+// RateSampleDB persists the exchange rate samples taken by the rate sampler.
+type RateSampleDB interface {
+	PutRateSample(sample *db.RateSample) error
+}
+
+// runRateSampler periodically reads the exchange rate from the first
+// available price provider and stores it, so that the rate's history can be
+// charted.
+func (b *backend) runRateSampler(
+	interval time.Duration,
+	sampleDB RateSampleDB,
+	providers []pricefeed.Provider,
+	maxPriceAge time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.sampleRate(sampleDB, providers, maxPriceAge); err != nil {
+			log.Warnf("failed to sample the exchange rate: %s", err)
+		}
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *backend) sampleRate(
+	sampleDB RateSampleDB,
+	providers []pricefeed.Provider,
+	maxPriceAge time.Duration,
+) error {
+	rate, err := pricefeed.GetExchangeRate(b.ctx, providers, maxPriceAge)
+	if err != nil {
+		return err
+	}
+
+	return sampleDB.PutRateSample(&db.RateSample{
+		Time:         time.Now(),
+		XMRPrice:     rate.XMRFeed.Price,
+		ETHPrice:     rate.ETHFeed.Price,
+		ExchangeRate: rate.ExchangeRate,
+		Provider:     rate.Provider,
+	})
+}
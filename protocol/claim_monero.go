@@ -40,7 +40,9 @@ func GetClaimKeypair(
 }
 
 // ClaimMonero claims the XMR located in the wallet controlled by the private keypair `kpAB`.
-// If noTransferBack is unset, it sweeps the XMR to `depositAddr`.
+// If noTransferBack is unset, it sweeps the XMR to `depositAddr`. If the swap's status
+// is already SweepingXMR, the call is a retry of a sweep that failed or was interrupted,
+// and nothing is swept if the wallet's funds are already gone.
 func ClaimMonero(
 	ctx context.Context,
 	env common.Environment,
@@ -65,7 +67,7 @@ func ClaimMonero(
 	}
 	defer abWalletCli.CloseAndRemoveWallet()
 
-	log.Infof("monero claimed in account %s; transferring to primary account %s",
+	log.Infof("monero claimed in account %s; transferring to deposit address %s",
 		address, depositAddr)
 
 	err = depositAddr.ValidateEnv(env)
@@ -78,11 +80,25 @@ func ClaimMonero(
 		return err
 	}
 
-	err = setSweepStatus(info, sm)
-	if err != nil {
-		return err
+	if info.Status == types.SweepingXMR {
+		swept, err := alreadySwept(abWalletCli) //nolint:govet
+		if err != nil {
+			return err
+		}
+
+		if swept {
+			log.Infof("XMR of swap %s was already swept out of account %s", info.OfferID, address)
+			return nil
+		}
+
+		log.Infof("retrying sweep of XMR of swap %s", info.OfferID)
+	} else {
+		err = setSweepStatus(info, sm)
+		if err != nil {
+			return err
+		}
+		log.Debugf("set swap's status to SweepingXMR; swap ID %s", info.OfferID)
 	}
-	log.Debugf("set swap's status to SweepingXMR; swap ID %s", info.OfferID)
 
 	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, monero.SweepToSelfConfirmations)
 	if err != nil {
@@ -91,7 +107,7 @@ func ClaimMonero(
 
 	log.Debugf("got %d sweep receipts", len(transfers))
 	for _, transfer := range transfers {
-		log.Infof("transferred %s XMR to primary wallet (%s XMR lost to fees)",
+		log.Infof("transferred %s XMR to deposit address (%s XMR lost to fees)",
 			coins.FmtPiconeroAsXMR(transfer.Amount),
 			coins.FmtPiconeroAsXMR(transfer.Fee),
 		)
@@ -100,6 +116,17 @@ func ClaimMonero(
 	return nil
 }
 
+// alreadySwept returns true if the swap wallet no longer holds any funds, which
+// is the case once a sweep out of it was broadcast.
+func alreadySwept(abWalletCli monero.WalletClient) (bool, error) {
+	balance, err := abWalletCli.GetBalance(0)
+	if err != nil {
+		return false, fmt.Errorf("failed to get swap wallet balance: %w", err)
+	}
+
+	return balance.Balance == 0, nil
+}
+
 // setSweepStatus sets the swap's status as `SweepingXMR` and writes it to the db.
 func setSweepStatus(info *swap.Info, sm SwapManager) error {
 	info.SetStatus(types.SweepingXMR)
@@ -0,0 +1,99 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+const (
+	// goroutineExitTimeout is how long a completed swap's goroutines have to
+	// exit before a leak is reported.
+	goroutineExitTimeout = 30 * time.Second
+
+	goroutineExitPollInterval = 50 * time.Millisecond
+)
+
+// SwapGoroutines tracks the long-running goroutines started for a single swap,
+// so that we can verify that all of them exit once the swap completes. A node
+// doing thousands of swaps would otherwise slowly leak any goroutine that is
+// not stopped by the swap's exit path.
+type SwapGoroutines struct {
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// NewSwapGoroutines returns a new *SwapGoroutines.
+func NewSwapGoroutines() *SwapGoroutines {
+	return &SwapGoroutines{
+		running: make(map[string]int),
+	}
+}
+
+// Go runs fn in a new goroutine that is tracked under the given name until fn
+// returns.
+func (g *SwapGoroutines) Go(name string, fn func()) {
+	g.mu.Lock()
+	g.running[name]++
+	g.mu.Unlock()
+
+	go func() {
+		defer func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			g.running[name]--
+			if g.running[name] == 0 {
+				delete(g.running, name)
+			}
+		}()
+
+		fn()
+	}()
+}
+
+// Running returns the sorted names of the tracked goroutines that have not
+// returned.
+func (g *SwapGoroutines) Running() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, 0, len(g.running))
+	for name := range g.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WaitForExit waits up to the given timeout for all tracked goroutines to
+// return. It returns the names of the goroutines still running at the timeout.
+func (g *SwapGoroutines) WaitForExit(timeout time.Duration) []string {
+	deadline := time.Now().Add(timeout)
+	for {
+		running := g.Running()
+		if len(running) == 0 || time.Now().After(deadline) {
+			return running
+		}
+		time.Sleep(goroutineExitPollInterval)
+	}
+}
+
+// VerifyExited should be called after the swap's context is cancelled. It
+// logs a warning naming any of the swap's goroutines that fail to exit. As the
+// swap's exit path runs inside one of the tracked goroutines, the check happens
+// in the background.
+func (g *SwapGoroutines) VerifyExited(offerID types.Hash) {
+	go func() {
+		running := g.WaitForExit(goroutineExitTimeout)
+		if len(running) > 0 {
+			log.Warnf("goroutines of completed swap %s did not exit: %v", offerID, running)
+			return
+		}
+		log.Debugf("all goroutines of swap %s exited", offerID)
+	}()
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapGoroutines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := NewSwapGoroutines()
+
+	for i := 0; i < 2; i++ {
+		g.Go("watcher", func() { <-ctx.Done() })
+	}
+	g.Go("handler", func() { <-ctx.Done() })
+
+	stuckCh := make(chan struct{})
+	g.Go("stuck", func() { <-stuckCh })
+
+	require.Equal(t, []string{"handler", "stuck", "watcher"}, g.Running())
+
+	cancel()
+	require.Equal(t, []string{"stuck"}, g.WaitForExit(200*time.Millisecond))
+
+	close(stuckCh)
+	require.Empty(t, g.WaitForExit(time.Second))
+}
@@ -0,0 +1,25 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"time"
+)
+
+// WaitForSwapDeadline blocks until maxDuration has passed since the swap's
+// start time. It returns true once the deadline is reached, or false if ctx is
+// cancelled first. A deadline already in the past is reached immediately, which
+// happens when a swap restored from the database has outlived its duration.
+func WaitForSwapDeadline(ctx context.Context, startTime time.Time, maxDuration time.Duration) bool {
+	timer := time.NewTimer(time.Until(startTime.Add(maxDuration)))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForSwapDeadline(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	require.True(t, WaitForSwapDeadline(ctx, start, 100*time.Millisecond))
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestWaitForSwapDeadline_pastDeadline(t *testing.T) {
+	ctx := context.Background()
+	require.True(t, WaitForSwapDeadline(ctx, time.Now().Add(-time.Hour), time.Minute))
+}
+
+func TestWaitForSwapDeadline_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, WaitForSwapDeadline(ctx, time.Now(), time.Hour))
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+)
+
+// marketRateTimeout bounds how long taking the market exchange rate snapshot
+// can delay the start of a swap. The snapshot is taken while the swap is being
+// set up (with the XMR maker's swap lock held), so we would rather go without
+// it than stall other swaps on a slow price feed.
+const marketRateTimeout = 2 * time.Second
+
+// MarketExchangeRate returns the current XMR/ETH market exchange rate from the
+// same price feeds used to suggest exchange rates. The rate is only recorded
+// for reference, so nil is returned if the price feeds are unavailable or don't
+// respond within marketRateTimeout, or if the swap's asset is not ETH, as there
+// are no token price feeds.
+func MarketExchangeRate(b backend.Backend, asset types.EthAsset) *coins.ExchangeRate {
+	if asset != types.EthAssetETH {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(b.Ctx(), marketRateTimeout)
+	defer cancel()
+
+	ec := b.ETHClient().Raw()
+
+	xmrFeed, err := pricefeed.GetXMRUSDPrice(ctx, ec)
+	if err != nil {
+		log.Warnf("failed to get XMR price for market exchange rate: %s", err)
+		return nil
+	}
+
+	ethFeed, err := pricefeed.GetETHUSDPrice(ctx, ec)
+	if err != nil {
+		log.Warnf("failed to get ETH price for market exchange rate: %s", err)
+		return nil
+	}
+
+	exchangeRate, err := coins.CalcExchangeRate(xmrFeed.Price, ethFeed.Price)
+	if err != nil {
+		log.Warnf("failed to calculate market exchange rate: %s", err)
+		return nil
+	}
+
+	return exchangeRate
+}
@@ -4,6 +4,8 @@
 package swap
 
 import (
+	"github.com/libp2p/go-libp2p/core/peer"
+
 	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
@@ -13,4 +15,7 @@ type Database interface {
 	HasSwap(id types.Hash) (bool, error)
 	GetSwap(id types.Hash) (*Info, error)
 	GetAllSwaps() ([]*Info, error)
+	GetSwapsByStartTime(filter PastSwapFilter, offset, limit uint) ([]*Info, error)
+	IncrementPeerStats(peerID peer.ID, status types.Status) error
+	GetAllPeerStats() (map[peer.ID]*PeerStats, error)
 }
@@ -13,31 +13,62 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 
 	"github.com/ChainSafe/chaindb"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
-var errNoSwapWithID = errors.New("unable to find swap with given ID")
+var (
+	errNoSwapWithID = errors.New("unable to find swap with given ID")
+
+	// ErrNewSwapsBlocked is returned by AddSwap for a new ongoing swap after
+	// BlockNewSwaps was called.
+	ErrNewSwapsBlocked = errors.New("new swaps are blocked as swapd is shutting down")
+)
+
+// StatusListener is called with a copy of a swap's *Info each time the swap is
+// added to the Manager, written to the database or completed. It is called
+// synchronously from the swap's state machine, so it must not block.
+type StatusListener func(info *Info)
+
+// PastSwapFilter returns true if a past swap should be included in the results
+// of GetPastIDs.
+type PastSwapFilter func(info *Info) bool
 
 // Manager tracks current and past swaps.
 type Manager interface {
 	AddSwap(info *Info) error
 	WriteSwapToDB(info *Info) error
-	GetPastIDs() ([]types.Hash, error)
+	GetPastIDs(filter PastSwapFilter) ([]types.Hash, error)
+	GetPastSwaps(filter PastSwapFilter, offset, limit uint) ([]*Info, error)
 	GetPastSwap(types.Hash) (*Info, error)
 	GetOngoingSwap(types.Hash) (Info, error)
 	GetOngoingSwaps() ([]*Info, error)
 	CompleteOngoingSwap(info *Info) error
 	HasOngoingSwap(types.Hash) bool
+	AddStatusListener(listener StatusListener) (remove func())
+	GetAllPeerStats() (map[peer.ID]*PeerStats, error)
+	BlockNewSwaps()
 }
 
 // manager implements Manager.
 // Note that ongoing swaps are fully populated, but past swaps
 // are only stored in memory if they've completed during
 // this swapd run, or if they've recently been retrieved.
+//
+// If the number of ongoing swaps exceeds maxOngoingInMemory, the least active
+// ones, by status update time, that are not being run are kept on disk only and
+// read when accessed. A swap that is being run is always kept in memory, as its
+// swap state holds on to its *Info anyway.
 type manager struct {
 	db Database
 	sync.RWMutex
-	ongoing map[types.Hash]*Info
-	past    map[types.Hash]*Info
+	ongoing            map[types.Hash]*Info
+	live               map[types.Hash]struct{}          // in-memory ongoing swaps being run by a swap state
+	overflow           map[types.Hash]chan types.Status // disk-only ongoing swaps, with their status channel
+	maxOngoingInMemory int                              // zero for no limit
+	past               map[types.Hash]*Info
+	listeners          map[uint64]StatusListener
+	nextListenerID     uint64
+	newSwapsBlocked    bool
 }
 
 var _ Manager = (*manager)(nil)
@@ -46,26 +77,103 @@ var _ Manager = (*manager)(nil)
 // It loads all ongoing swaps into memory on construction.
 // Completed swaps are not loaded into memory.
 func NewManager(db Database) (Manager, error) {
-	ongoing := make(map[types.Hash]*Info)
+	return NewManagerWithMaxOngoing(db, 0)
+}
 
+// NewManagerWithMaxOngoing returns a new Manager that uses the given database
+// and keeps at most maxOngoingInMemory ongoing swaps that are not being run in
+// memory. Ongoing swaps beyond that, such as ones that were not resumed, are
+// only kept in the database and are read on demand, with the most recently
+// updated swaps kept in memory. If maxOngoingInMemory is zero, all ongoing
+// swaps are kept in memory.
+func NewManagerWithMaxOngoing(db Database, maxOngoingInMemory int) (Manager, error) {
 	stored, err := db.GetAllSwaps()
 	if err != nil {
 		return nil, err
 	}
 
+	m := &manager{
+		db:                 db,
+		ongoing:            make(map[types.Hash]*Info),
+		live:               make(map[types.Hash]struct{}),
+		overflow:           make(map[types.Hash]chan types.Status),
+		maxOngoingInMemory: maxOngoingInMemory,
+		past:               make(map[types.Hash]*Info),
+		listeners:          make(map[uint64]StatusListener),
+	}
+
 	for _, s := range stored {
 		if !s.Status.IsOngoing() {
 			continue
 		}
 
-		ongoing[s.OfferID] = s
+		// the swaps are not run until they're resumed
+		m.addOngoing(s, false)
+	}
+
+	m.updateOngoingSwapsMetric()
+	return m, nil
+}
+
+// addOngoing adds the swap to the in-memory ongoing swaps. If live is true, the
+// swap is being run by a swap state, which updates the same *Info. If the limit
+// is exceeded, the least active in-memory swap that is not live is moved to
+// disk-only; moving a live swap would not free any memory. The swap must
+// already be written to the database. The caller must hold the manager's lock.
+func (m *manager) addOngoing(info *Info, live bool) {
+	delete(m.overflow, info.OfferID)
+	m.ongoing[info.OfferID] = info
+	if live {
+		m.live[info.OfferID] = struct{}{}
+	}
+
+	if m.maxOngoingInMemory == 0 || len(m.ongoing) <= m.maxOngoingInMemory {
+		return
+	}
+
+	var leastActive *Info
+	for id, s := range m.ongoing {
+		if _, isLive := m.live[id]; isLive {
+			continue
+		}
+		if leastActive == nil || s.LastStatusUpdateTime.Before(leastActive.LastStatusUpdateTime) {
+			leastActive = s
+		}
+	}
+
+	// all of the in-memory swaps are being run
+	if leastActive == nil {
+		return
+	}
+
+	delete(m.ongoing, leastActive.OfferID)
+	m.overflow[leastActive.OfferID] = leastActive.statusCh
+}
+
+// readOverflowSwaps reads the given disk-only ongoing swaps from the database,
+// setting their status channels. Swaps that completed or were deleted since
+// the IDs were collected are skipped. The caller must not hold the manager's
+// lock, so that other users of the manager are not blocked by the reads.
+func (m *manager) readOverflowSwaps(overflow map[types.Hash]chan types.Status) ([]*Info, error) {
+	swaps := make([]*Info, 0, len(overflow))
+	for id, statusCh := range overflow {
+		s, err := m.getSwapFromDB(id)
+		if errors.Is(err, errNoSwapWithID) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !s.Status.IsOngoing() {
+			continue
+		}
+
+		s.statusCh = statusCh
+		swaps = append(swaps, s)
 	}
 
-	return &manager{
-		db:      db,
-		ongoing: ongoing,
-		past:    make(map[types.Hash]*Info),
-	}, nil
+	return swaps, nil
 }
 
 // AddSwap adds the given swap *Info to the Manager.
@@ -73,28 +181,86 @@ func (m *manager) AddSwap(info *Info) error {
 	m.Lock()
 	defer m.Unlock()
 
+	if m.newSwapsBlocked && info.Status.IsOngoing() {
+		return ErrNewSwapsBlocked
+	}
+
+	// the swap is written to the database before it's added, as an ongoing
+	// swap can be evicted from memory to disk-only when it's added
+	if err := m.db.PutSwap(info); err != nil {
+		return err
+	}
+
 	switch info.Status.IsOngoing() {
 	case true:
-		m.ongoing[info.OfferID] = info
+		m.addOngoing(info, true)
+		m.updateOngoingSwapsMetric()
 	default:
 		m.past[info.OfferID] = info
+		completedSwaps.WithLabelValues(info.Status.String()).Inc()
+		observeSwapDuration(info)
 	}
 
-	return m.db.PutSwap(info)
+	m.notifyListeners(info)
+	return nil
 }
 
-// WriteSwapToDB writes the swap to the database.
+// WriteSwapToDB writes the swap to the database. An ongoing swap is being run
+// by the caller, so the given *Info is kept in memory from here on, even if the
+// swap was disk-only.
 func (m *manager) WriteSwapToDB(info *Info) error {
-	return m.db.PutSwap(info)
+	if err := m.db.PutSwap(info); err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	if m.hasOngoing(info.OfferID) && info.Status.IsOngoing() {
+		m.addOngoing(info, true)
+	}
+
+	m.notifyListeners(info)
+	return nil
 }
 
-// GetPastIDs returns all past swap IDs.
-func (m *manager) GetPastIDs() ([]types.Hash, error) {
+// AddStatusListener registers a listener that is notified of swap updates. The
+// returned function unregisters the listener. It must not be called from within
+// the listener.
+func (m *manager) AddStatusListener(listener StatusListener) (remove func()) {
+	m.Lock()
+	defer m.Unlock()
+
+	id := m.nextListenerID
+	m.nextListenerID++
+	m.listeners[id] = listener
+
+	return func() {
+		m.Lock()
+		defer m.Unlock()
+		delete(m.listeners, id)
+	}
+}
+
+// notifyListeners passes a copy of the swap info to each listener. The caller
+// must hold the manager's lock.
+func (m *manager) notifyListeners(info *Info) {
+	for _, listener := range m.listeners {
+		infoCopy := new(Info)
+		*infoCopy = *info
+		listener(infoCopy)
+	}
+}
+
+// GetPastIDs returns the IDs of the past swaps that pass the given filter. If
+// the filter is nil, all past swap IDs are returned.
+func (m *manager) GetPastIDs(filter PastSwapFilter) ([]types.Hash, error) {
 	m.RLock()
 	defer m.RUnlock()
 	ids := make(map[types.Hash]struct{})
-	for id := range m.past {
-		ids[id] = struct{}{}
+	for id, s := range m.past {
+		if filter == nil || filter(s) {
+			ids[id] = struct{}{}
+		}
 	}
 
 	// TODO: do we want to cache all past swaps since we're already fetching them?
@@ -108,7 +274,14 @@ func (m *manager) GetPastIDs() ([]types.Hash, error) {
 			continue
 		}
 
-		ids[s.OfferID] = struct{}{}
+		// the cached swap was already filtered above
+		if _, has := m.past[s.OfferID]; has {
+			continue
+		}
+
+		if filter == nil || filter(s) {
+			ids[s.OfferID] = struct{}{}
+		}
 	}
 
 	idArr := make([]types.Hash, len(ids))
@@ -121,6 +294,34 @@ func (m *manager) GetPastIDs() ([]types.Hash, error) {
 	return idArr, nil
 }
 
+// GetPastSwaps returns a page of the past swaps that pass the given filter,
+// ordered from newest to oldest start time. The first offset matching swaps
+// are skipped and at most limit swaps are returned, or all of them if limit is
+// zero. If the filter is nil, all past swaps match.
+func (m *manager) GetPastSwaps(filter PastSwapFilter, offset, limit uint) ([]*Info, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	swaps, err := m.db.GetSwapsByStartTime(func(info *Info) bool {
+		if info.Status.IsOngoing() {
+			return false
+		}
+		return filter == nil || filter(info)
+	}, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// prefer the cached swaps, which are the same swaps as the ones in the db
+	for i, s := range swaps {
+		if cached, has := m.past[s.OfferID]; has {
+			swaps[i] = cached
+		}
+	}
+
+	return swaps, nil
+}
+
 // GetPastSwap returns a swap's *Info given its ID.
 func (m *manager) GetPastSwap(id types.Hash) (*Info, error) {
 	m.RLock()
@@ -140,39 +341,65 @@ func (m *manager) GetPastSwap(id types.Hash) (*Info, error) {
 	return s, nil
 }
 
-// GetOngoingSwap returns the ongoing swap's *Info, if there is one.
+// GetOngoingSwap returns the ongoing swap's *Info, if there is one. A
+// disk-only ongoing swap is read from the database, but is not loaded into
+// memory.
 func (m *manager) GetOngoingSwap(id types.Hash) (Info, error) {
 	m.RLock()
-	defer m.RUnlock()
 	s, has := m.ongoing[id]
+	if has {
+		info := *s
+		m.RUnlock()
+		return info, nil
+	}
+	statusCh, has := m.overflow[id]
+	m.RUnlock()
+
 	if !has {
 		return Info{}, errNoSwapWithID
 	}
 
-	return *s, nil
+	swaps, err := m.readOverflowSwaps(map[types.Hash]chan types.Status{id: statusCh})
+	if err != nil {
+		return Info{}, err
+	}
+	if len(swaps) == 0 {
+		return Info{}, errNoSwapWithID
+	}
+
+	return *swaps[0], nil
 }
 
-// GetOngoingSwaps returns all ongoing swaps.
+// GetOngoingSwaps returns all ongoing swaps. Disk-only ongoing swaps are read
+// from the database, but are not loaded into memory.
 func (m *manager) GetOngoingSwaps() ([]*Info, error) {
 	m.RLock()
-	defer m.RUnlock()
-	swaps := make([]*Info, len(m.ongoing))
-	i := 0
+	swaps := make([]*Info, 0, len(m.ongoing)+len(m.overflow))
 	for _, s := range m.ongoing {
 		sCopy := new(Info)
 		*sCopy = *s
-		swaps[i] = sCopy
-		i++
+		swaps = append(swaps, sCopy)
 	}
-	return swaps, nil
+
+	overflow := make(map[types.Hash]chan types.Status, len(m.overflow))
+	for id, statusCh := range m.overflow {
+		overflow[id] = statusCh
+	}
+	m.RUnlock()
+
+	overflowSwaps, err := m.readOverflowSwaps(overflow)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(swaps, overflowSwaps...), nil
 }
 
 // CompleteOngoingSwap marks the current ongoing swap as completed.
 func (m *manager) CompleteOngoingSwap(info *Info) error {
 	m.Lock()
 	defer m.Unlock()
-	_, has := m.ongoing[info.OfferID]
-	if !has {
+	if !m.hasOngoing(info.OfferID) {
 		return errNoSwapWithID
 	}
 
@@ -181,16 +408,53 @@ func (m *manager) CompleteOngoingSwap(info *Info) error {
 
 	m.past[info.OfferID] = info
 	delete(m.ongoing, info.OfferID)
+	delete(m.live, info.OfferID)
+	delete(m.overflow, info.OfferID)
+	m.updateOngoingSwapsMetric()
+	completedSwaps.WithLabelValues(info.Status.String()).Inc()
+	observeSwapDuration(info)
 
 	// re-write to db, as status has changed
-	return m.db.PutSwap(info)
+	if err := m.db.PutSwap(info); err != nil {
+		return err
+	}
+
+	if err := m.db.IncrementPeerStats(info.PeerID, info.Status); err != nil {
+		return err
+	}
+
+	m.notifyListeners(info)
+	return nil
+}
+
+// BlockNewSwaps makes AddSwap reject any new ongoing swap, so that swapd can
+// wait for its current swaps to complete before shutting down. Swaps that are
+// already ongoing are unaffected. It can't be undone.
+func (m *manager) BlockNewSwaps() {
+	m.Lock()
+	defer m.Unlock()
+	m.newSwapsBlocked = true
+}
+
+// GetAllPeerStats returns the outcomes of our completed swaps, by peer.
+func (m *manager) GetAllPeerStats() (map[peer.ID]*PeerStats, error) {
+	return m.db.GetAllPeerStats()
 }
 
 // HasOngoingSwap returns true if the given ID is an ongoing swap.
 func (m *manager) HasOngoingSwap(id types.Hash) bool {
 	m.RLock()
 	defer m.RUnlock()
+	return m.hasOngoing(id)
+}
+
+// hasOngoing returns true if the given ID is an in-memory or disk-only ongoing
+// swap. The caller must hold the manager's lock.
+func (m *manager) hasOngoing(id types.Hash) bool {
 	_, has := m.ongoing[id]
+	if !has {
+		_, has = m.overflow[id]
+	}
 	return has
 }
 
@@ -5,6 +5,7 @@ package swap
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
 
@@ -104,12 +105,13 @@ func TestManager_AddSwap_Ongoing(t *testing.T) {
 	require.NotNil(t, m.ongoing)
 
 	db.EXPECT().PutSwap(info)
+	db.EXPECT().IncrementPeerStats(info.PeerID, info.Status)
 	err = m.CompleteOngoingSwap(info)
 	require.NoError(t, err)
 	require.Equal(t, 0, len(m.ongoing))
 
 	db.EXPECT().GetAllSwaps()
-	ids, err := m.GetPastIDs()
+	ids, err := m.GetPastIDs(nil)
 	require.NoError(t, err)
 	require.Equal(t, []types.Hash{{}}, ids)
 
@@ -117,6 +119,47 @@ func TestManager_AddSwap_Ongoing(t *testing.T) {
 	//require.NoError(t, err)
 }
 
+func TestManager_BlockNewSwaps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+
+	mgr, err := NewManager(db)
+	require.NoError(t, err)
+	newInfo := func(id byte, status types.Status) *Info {
+		return NewInfo(
+			testPeerID,
+			types.Hash{id},
+			coins.ProvidesXMR,
+			apd.New(1, 0),
+			apd.New(10, 0),
+			coins.ToExchangeRate(apd.New(1, -1)), // 0.1
+			types.EthAssetETH,
+			status,
+			100,
+			nil,
+		)
+	}
+
+	ongoing := newInfo(1, types.ExpectingKeys)
+	db.EXPECT().PutSwap(ongoing)
+	require.NoError(t, mgr.AddSwap(ongoing))
+
+	mgr.BlockNewSwaps()
+	err = mgr.AddSwap(newInfo(2, types.ExpectingKeys))
+	require.ErrorIs(t, err, ErrNewSwapsBlocked)
+	require.False(t, mgr.HasOngoingSwap(types.Hash{2}))
+
+	// swaps that were already ongoing can still complete
+	ongoing.Status = types.CompletedSuccess
+	db.EXPECT().PutSwap(ongoing)
+	db.EXPECT().IncrementPeerStats(ongoing.PeerID, ongoing.Status)
+	require.NoError(t, mgr.CompleteOngoingSwap(ongoing))
+	require.False(t, mgr.HasOngoingSwap(types.Hash{1}))
+}
+
 func TestManager_AddSwap_Past(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -163,7 +206,214 @@ func TestManager_AddSwap_Past(t *testing.T) {
 	require.NoError(t, err)
 
 	db.EXPECT().GetAllSwaps()
-	ids, err := m.GetPastIDs()
+	ids, err := m.GetPastIDs(nil)
 	require.NoError(t, err)
 	require.Equal(t, 2, len(ids))
 }
+
+func TestManager_GetPastIDs_filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+
+	m, err := NewManager(db)
+	require.NoError(t, err)
+
+	cached := &Info{
+		OfferID: types.Hash{1},
+		Status:  types.CompletedRefund,
+	}
+
+	db.EXPECT().PutSwap(cached)
+	err = m.AddSwap(cached)
+	require.NoError(t, err)
+
+	stored := []*Info{
+		cached,
+		{OfferID: types.Hash{2}, Status: types.CompletedRefund},
+		{OfferID: types.Hash{3}, Status: types.CompletedSuccess},
+		{OfferID: types.Hash{4}, Status: types.ETHLocked},
+	}
+
+	db.EXPECT().GetAllSwaps().Return(stored, nil)
+	ids, err := m.GetPastIDs(func(info *Info) bool {
+		return info.Status == types.CompletedRefund
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []types.Hash{{1}, {2}}, ids)
+
+	db.EXPECT().GetAllSwaps().Return(stored, nil)
+	ids, err = m.GetPastIDs(nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []types.Hash{{1}, {2}, {3}}, ids)
+}
+
+func TestManager_GetPastSwaps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+
+	m, err := NewManager(db)
+	require.NoError(t, err)
+
+	stored := []*Info{
+		{OfferID: types.Hash{1}, Status: types.CompletedRefund},
+		{OfferID: types.Hash{2}, Status: types.CompletedSuccess},
+		{OfferID: types.Hash{3}, Status: types.ETHLocked},
+	}
+
+	// the offset and limit are passed to the db, with a filter that also excludes ongoing swaps
+	db.EXPECT().GetSwapsByStartTime(gomock.Any(), uint(1), uint(2)).DoAndReturn(
+		func(filter PastSwapFilter, _, _ uint) ([]*Info, error) {
+			var swaps []*Info
+			for _, s := range stored {
+				if filter(s) {
+					swaps = append(swaps, s)
+				}
+			}
+			return swaps, nil
+		},
+	)
+
+	swaps, err := m.GetPastSwaps(func(info *Info) bool {
+		return info.Status == types.CompletedRefund
+	}, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []*Info{stored[0]}, swaps)
+}
+
+func TestManager_StatusListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	db.EXPECT().GetAllSwaps()
+
+	m, err := NewManager(db)
+	require.NoError(t, err)
+
+	var notified []Status
+	remove := m.AddStatusListener(func(info *Info) {
+		notified = append(notified, info.Status)
+	})
+
+	info := NewInfo(
+		testPeerID,
+		types.Hash{},
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(10, 0),
+		coins.ToExchangeRate(apd.New(1, -1)), // 0.1
+		types.EthAssetETH,
+		types.ExpectingKeys,
+		100,
+		nil,
+	)
+
+	db.EXPECT().PutSwap(info).Times(3)
+	require.NoError(t, m.AddSwap(info))
+
+	info.SetStatus(types.KeysExchanged)
+	require.NoError(t, m.WriteSwapToDB(info))
+
+	info.SetStatus(types.CompletedAbort)
+	db.EXPECT().IncrementPeerStats(info.PeerID, types.CompletedAbort)
+	require.NoError(t, m.CompleteOngoingSwap(info))
+
+	require.Equal(t, []Status{types.ExpectingKeys, types.KeysExchanged, types.CompletedAbort}, notified)
+
+	// removed listeners are no longer notified
+	remove()
+	db.EXPECT().PutSwap(info)
+	require.NoError(t, m.AddSwap(info))
+	require.Len(t, notified, 3)
+}
+
+func TestManager_maxOngoingInMemory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+
+	now := time.Now()
+	infos := make([]*Info, 6)
+	for i := range infos {
+		infos[i] = NewInfo(
+			testPeerID,
+			types.Hash{byte(i + 1)},
+			coins.ProvidesXMR,
+			apd.New(1, 0),
+			apd.New(10, 0),
+			coins.ToExchangeRate(apd.New(1, -1)), // 0.1
+			types.EthAssetETH,
+			types.ExpectingKeys,
+			100,
+			make(chan types.Status, statusChSize),
+		)
+		infos[i].LastStatusUpdateTime = now.Add(time.Duration(i) * time.Second)
+	}
+
+	// the first 3 swaps are ongoing swaps from a previous run, which are not
+	// being run until they are resumed
+	db.EXPECT().GetAllSwaps().Return(infos[:3], nil)
+	mgr, err := NewManagerWithMaxOngoing(db, 2)
+	require.NoError(t, err)
+	m := mgr.(*manager)
+
+	// the least recently updated swap was moved to disk-only
+	require.Len(t, m.ongoing, 2)
+	require.Contains(t, m.overflow, infos[0].OfferID)
+	require.True(t, m.HasOngoingSwap(infos[0].OfferID))
+
+	// all ongoing swaps are returned, including the disk-only one
+	stored := *infos[0]
+	db.EXPECT().GetSwap(infos[0].OfferID).Return(&stored, nil)
+	swaps, err := m.GetOngoingSwaps()
+	require.NoError(t, err)
+	require.Len(t, swaps, 3)
+	require.Len(t, m.ongoing, 2)
+
+	// accessing the disk-only swap reads it without loading it into memory
+	stored = *infos[0]
+	db.EXPECT().GetSwap(infos[0].OfferID).Return(&stored, nil)
+	s, err := m.GetOngoingSwap(infos[0].OfferID)
+	require.NoError(t, err)
+	require.Equal(t, infos[0].OfferID, s.OfferID)
+	require.Equal(t, infos[0].StatusCh(), s.StatusCh())
+	require.NotContains(t, m.ongoing, infos[0].OfferID)
+	require.Contains(t, m.overflow, infos[0].OfferID)
+
+	// new swaps are being run, so adding them moves the swaps that are not
+	// being run to disk-only
+	for _, info := range infos[3:5] {
+		db.EXPECT().PutSwap(info)
+		require.NoError(t, m.AddSwap(info))
+	}
+	require.Len(t, m.ongoing, 2)
+	require.Contains(t, m.overflow, infos[1].OfferID)
+	require.Contains(t, m.overflow, infos[2].OfferID)
+
+	// swaps that are being run are never moved to disk-only, as their swap
+	// states hold on to them anyway
+	db.EXPECT().PutSwap(infos[5])
+	require.NoError(t, m.AddSwap(infos[5]))
+	require.Len(t, m.ongoing, 3)
+	require.Len(t, m.overflow, 3)
+
+	// a resumed disk-only swap is kept in memory, using the swap state's *Info
+	resumed := *infos[0]
+	db.EXPECT().PutSwap(&resumed)
+	require.NoError(t, m.WriteSwapToDB(&resumed))
+	require.Same(t, &resumed, m.ongoing[infos[0].OfferID])
+	require.NotContains(t, m.overflow, infos[0].OfferID)
+
+	// a disk-only swap can be completed
+	db.EXPECT().PutSwap(infos[1])
+	db.EXPECT().IncrementPeerStats(infos[1].PeerID, infos[1].Status)
+	require.NoError(t, m.CompleteOngoingSwap(infos[1]))
+	require.False(t, m.HasOngoingSwap(infos[1].OfferID))
+	require.NotContains(t, m.overflow, infos[1].OfferID)
+}
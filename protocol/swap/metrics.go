@@ -0,0 +1,59 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swap
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	completedSwaps = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "swapd",
+			Name:      "swaps_completed_total",
+			Help:      "Number of completed swaps by final status",
+		},
+		[]string{"status"},
+	)
+
+	swapDurations = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "swapd",
+			Name:      "swap_duration_seconds",
+			Help:      "Time from the start to the end of completed swaps, by asset and final status",
+			Buckets:   prometheus.ExponentialBuckets(60, 2, 10), // 1 minute to ~8.5 hours
+		},
+		[]string{"asset", "status"},
+	)
+
+	ongoingSwaps = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "swapd",
+			Name:      "swaps_ongoing",
+			Help:      "Number of ongoing swaps",
+		},
+	)
+)
+
+// MetricsCollectors returns the collectors of the swap metrics, which are
+// updated by the Manager.
+func MetricsCollectors() []prometheus.Collector {
+	return []prometheus.Collector{completedSwaps, swapDurations, ongoingSwaps}
+}
+
+// observeSwapDuration records the duration of a completed swap.
+func observeSwapDuration(info *Info) {
+	if info.EndTime == nil {
+		return
+	}
+
+	swapDurations.WithLabelValues(info.EthAsset.String(), info.Status.String()).
+		Observe(info.EndTime.Sub(info.StartTime).Seconds())
+}
+
+// updateOngoingSwapsMetric sets the ongoing swaps gauge. The caller must hold
+// the manager's lock.
+func (m *manager) updateOngoingSwapsMetric() {
+	ongoingSwaps.Set(float64(len(m.ongoing) + len(m.overflow)))
+}
@@ -7,8 +7,10 @@ package swap
 import (
 	reflect "reflect"
 
+	types "github.com/athanorlabs/atomic-swap/common/types"
 	common "github.com/ethereum/go-ethereum/common"
 	gomock "github.com/golang/mock/gomock"
+	peer "github.com/libp2p/go-libp2p/core/peer"
 )
 
 // MockDatabase is a mock of Database interface.
@@ -34,6 +36,21 @@ func (m *MockDatabase) EXPECT() *MockDatabaseMockRecorder {
 	return m.recorder
 }
 
+// GetAllPeerStats mocks base method.
+func (m *MockDatabase) GetAllPeerStats() (map[peer.ID]*PeerStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllPeerStats")
+	ret0, _ := ret[0].(map[peer.ID]*PeerStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllPeerStats indicates an expected call of GetAllPeerStats.
+func (mr *MockDatabaseMockRecorder) GetAllPeerStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPeerStats", reflect.TypeOf((*MockDatabase)(nil).GetAllPeerStats))
+}
+
 // GetAllSwaps mocks base method.
 func (m *MockDatabase) GetAllSwaps() ([]*Info, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +66,21 @@ func (mr *MockDatabaseMockRecorder) GetAllSwaps() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllSwaps", reflect.TypeOf((*MockDatabase)(nil).GetAllSwaps))
 }
 
+// GetSwapsByStartTime mocks base method.
+func (m *MockDatabase) GetSwapsByStartTime(arg0 PastSwapFilter, arg1, arg2 uint) ([]*Info, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSwapsByStartTime", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*Info)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSwapsByStartTime indicates an expected call of GetSwapsByStartTime.
+func (mr *MockDatabaseMockRecorder) GetSwapsByStartTime(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSwapsByStartTime", reflect.TypeOf((*MockDatabase)(nil).GetSwapsByStartTime), arg0, arg1, arg2)
+}
+
 // GetSwap mocks base method.
 func (m *MockDatabase) GetSwap(arg0 common.Hash) (*Info, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +111,20 @@ func (mr *MockDatabaseMockRecorder) HasSwap(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSwap", reflect.TypeOf((*MockDatabase)(nil).HasSwap), arg0)
 }
 
+// IncrementPeerStats mocks base method.
+func (m *MockDatabase) IncrementPeerStats(arg0 peer.ID, arg1 types.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementPeerStats", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementPeerStats indicates an expected call of IncrementPeerStats.
+func (mr *MockDatabaseMockRecorder) IncrementPeerStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementPeerStats", reflect.TypeOf((*MockDatabase)(nil).IncrementPeerStats), arg0, arg1)
+}
+
 // PutSwap mocks base method.
 func (m *MockDatabase) PutSwap(arg0 *Info) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,29 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package swap
+
+import (
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// PeerStats holds the counts of the outcomes of our completed swaps with a
+// peer, in either role.
+type PeerStats struct {
+	NumSucceeded uint64 `json:"numSucceeded"`
+	NumRefunded  uint64 `json:"numRefunded"`
+	NumAborted   uint64 `json:"numAborted"`
+}
+
+// Add counts a swap that completed with the given status. Ongoing statuses are
+// ignored.
+func (s *PeerStats) Add(status types.Status) {
+	switch status {
+	case types.CompletedSuccess:
+		s.NumSucceeded++
+	case types.CompletedRefund:
+		s.NumRefunded++
+	case types.CompletedAbort:
+		s.NumAborted++
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -27,6 +28,26 @@ var (
 	errInfoVersionMissing = errors.New("required 'version' field missing in swap Info")
 )
 
+// LifecycleTimeoutReason is the failure reason recorded for a swap that was
+// exited because it exceeded the maximum swap duration.
+const LifecycleTimeoutReason = "lifecycle timeout"
+
+// ClaimMethod values record how the XMR maker decided to claim the swap's ETH.
+const (
+	// ClaimMethodSelf is a claim submitted by the maker itself.
+	ClaimMethodSelf = "self"
+	// ClaimMethodRelayed is a claim submitted by a relayer.
+	ClaimMethodRelayed = "relayed"
+	// ClaimMethodSelfNearTimeout1 is a claim submitted by the maker itself
+	// instead of a relayer, as there wasn't enough time left before timeout1 to
+	// relay it.
+	ClaimMethodSelfNearTimeout1 = "self-near-t1"
+	// ClaimMethodManual means there wasn't enough time left before timeout1 to
+	// relay the claim, and the maker's balance was too low to claim itself. The
+	// swap needs manual attention, otherwise it is refunded after timeout1.
+	ClaimMethodManual = "manual"
+)
+
 type (
 	Status = types.Status //nolint:revive
 )
@@ -63,8 +84,27 @@ type Info struct {
 	// (and after Timeout0), the ETH-taker is able to claim, but
 	// after this timeout, the ETH-taker can no longer claim, only
 	// the ETH-maker can refund.
-	Timeout1 *time.Time        `json:"timeout1,omitempty"`
-	statusCh chan types.Status `json:"-"`
+	Timeout1 *time.Time `json:"timeout1,omitempty"`
+	// FailureReason, if set, describes why the swap was exited before it
+	// could complete normally.
+	FailureReason string `json:"failureReason,omitempty"`
+	// ClaimMethod, if set, is one of the ClaimMethod values describing how the
+	// XMR maker claimed, or attempted to claim, the ETH.
+	ClaimMethod string `json:"claimMethod,omitempty"`
+	// RelayerFee, if set, is the fee in ETH that the relayer which claimed
+	// the swap's ETH for the XMR maker was paid.
+	RelayerFee *apd.Decimal `json:"relayerFee,omitempty"`
+	// MarketExchangeRate is a snapshot of the market exchange rate at the
+	// start of the swap, if it was available.
+	MarketExchangeRate *coins.ExchangeRate `json:"marketExchangeRate,omitempty"`
+	// NewSwapTxHash, SetReadyTxHash, ClaimTxHash and RefundTxHash are the
+	// hashes of the swap's transactions on-chain. Each is set once the node
+	// sees the transaction, so they are only all set if the swap got that far.
+	NewSwapTxHash  *ethcommon.Hash   `json:"newSwapTxHash,omitempty"`
+	SetReadyTxHash *ethcommon.Hash   `json:"setReadyTxHash,omitempty"`
+	ClaimTxHash    *ethcommon.Hash   `json:"claimTxHash,omitempty"`
+	RefundTxHash   *ethcommon.Hash   `json:"refundTxHash,omitempty"`
+	statusCh       chan types.Status `json:"-"`
 }
 
 // NewInfo creates a new *Info from the given parameters.
@@ -119,6 +159,39 @@ func (i *Info) IsTaker() bool {
 	return i.Provides == coins.ProvidesETH
 }
 
+// ProfitPercent returns how much better the swap's exchange rate was than the
+// market exchange rate at the start of the swap, as a percentage of the market
+// rate. A negative value means the swap's rate was worse than the market rate.
+// The maker profits from a higher exchange rate, as it receives more ETH per
+// XMR, while the taker profits from a lower one. It returns nil if no market
+// rate was recorded for the swap.
+func (i *Info) ProfitPercent() (*apd.Decimal, error) {
+	if i.MarketExchangeRate == nil {
+		return nil, nil
+	}
+
+	decimalCtx := coins.DecimalCtx()
+	profit := new(apd.Decimal)
+	if _, err := decimalCtx.Sub(profit, i.ExchangeRate.Decimal(), i.MarketExchangeRate.Decimal()); err != nil {
+		return nil, err
+	}
+
+	if i.IsTaker() {
+		profit.Neg(profit)
+	}
+
+	if _, err := decimalCtx.Quo(profit, profit, i.MarketExchangeRate.Decimal()); err != nil {
+		return nil, err
+	}
+
+	if _, err := decimalCtx.Mul(profit, profit, apd.New(100, 0)); err != nil {
+		return nil, err
+	}
+
+	_, _ = profit.Reduce(profit)
+	return profit, nil
+}
+
 // UnmarshalInfo deserializes a JSON Info struct, checking the version for compatibility
 // before attempting to deserialize the whole blob.
 func UnmarshalInfo(jsonData []byte) (*Info, error) {
@@ -72,3 +72,26 @@ func TestUnmarshalInfo_versionTooNew(t *testing.T) {
 	_, err := UnmarshalInfo([]byte(offerJSON))
 	require.ErrorContains(t, err, fmt.Sprintf("info version %q not supported", unsupportedVersion))
 }
+
+func TestInfo_ProfitPercent(t *testing.T) {
+	info := &Info{
+		Provides:     coins.ProvidesXMR,
+		ExchangeRate: coins.StrToExchangeRate("0.11"),
+	}
+
+	profit, err := info.ProfitPercent()
+	require.NoError(t, err)
+	require.Nil(t, profit)
+
+	// the maker receives more ETH per XMR than the market rate
+	info.MarketExchangeRate = coins.StrToExchangeRate("0.1")
+	profit, err = info.ProfitPercent()
+	require.NoError(t, err)
+	require.Equal(t, "10", profit.Text('f'))
+
+	// the taker pays more ETH per XMR than the market rate
+	info.Provides = coins.ProvidesETH
+	profit, err = info.ProfitPercent()
+	require.NoError(t, err)
+	require.Equal(t, "-10", profit.Text('f'))
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MarinX/monerorpc/wallet"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/monero"
+)
+
+// SweepSharedWallet recreates the shared swap wallet controlled by the private
+// keypair `kpAB` and sweeps all of its XMR to `depositAddr`. It is used to
+// recover XMR that was left in the shared wallet of a swap that ended
+// abnormally. The swap wallet is removed once the sweep was sent.
+func SweepSharedWallet(
+	ctx context.Context,
+	env common.Environment,
+	offerID types.Hash,
+	xmrClient monero.WalletClient,
+	kpAB *mcrypto.PrivateKeyPair,
+	restoreHeight uint64,
+	depositAddr *mcrypto.Address,
+) ([]*wallet.Transfer, error) {
+	if err := depositAddr.ValidateEnv(env); err != nil {
+		return nil, err
+	}
+
+	conf := xmrClient.CreateWalletConf(fmt.Sprintf("swap-wallet-sweep-%s", offerID))
+	abWalletCli, err := monero.CreateSpendWalletFromKeys(conf, kpAB, restoreHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer abWalletCli.CloseAndRemoveWallet()
+
+	address := kpAB.PublicKeyPair().Address(env)
+	swept, err := alreadySwept(abWalletCli)
+	if err != nil {
+		return nil, err
+	}
+	if swept {
+		return nil, fmt.Errorf("shared wallet %s of swap %s has no XMR to sweep", address, offerID)
+	}
+
+	log.Infof("sweeping XMR of swap %s from shared wallet %s to %s", offerID, address, depositAddr)
+	transfers, err := abWalletCli.SweepAll(ctx, depositAddr, 0, monero.SweepToSelfConfirmations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sweep shared wallet: %w", err)
+	}
+
+	return transfers, nil
+}
@@ -6,6 +6,7 @@ package txsender
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -24,26 +25,59 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-var (
-	errTransactionTimeout = errors.New("timed out waiting for transaction to be signed")
-	transactionTimeout    = time.Minute * 2 // amount of time user has to sign message
+const (
+	// defaultTransactionTimeout is the amount of time the user has to sign a
+	// transaction, unless the caller's context has a deadline.
+	defaultTransactionTimeout = time.Minute * 2
+	// mainnetTransactionTimeout is the default on mainnet and stagenet, where
+	// the user may need more time to review each transaction.
+	mainnetTransactionTimeout = time.Hour
 )
 
+var errTransactionTimeout = errors.New("timed out waiting for transaction to be signed")
+
 // Transaction represents a transaction to be signed by the front-end
 type Transaction struct {
 	To    ethcommon.Address
 	Data  []byte
 	Value *apd.Decimal // ETH (or ETH asset), not WEI
+
+	// GasPrice and MaxGasPrice are only set if a maximum gas price is
+	// configured. GasPrice is the suggested gas price that was checked against
+	// the maximum, so the front-end can warn if it signs with a higher price.
+	// If the operator set a gas price override, only GasPrice is set, to the
+	// override, which the front-end should sign with.
+	GasPrice    *big.Int
+	MaxGasPrice *big.Int
+
+	// MaxFeePerGas and MaxPriorityFeePerGas are the configured EIP-1559 fees,
+	// if any, for the front-end to use when signing the transaction.
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
 }
 
 // ExternalSender represents a transaction signer and sender that is external to the daemon (ie. a front-end)
 type ExternalSender struct {
-	ctx          context.Context
 	ec           *ethclient.Client
 	abi          *abi.ABI
+	erc20ABI     *abi.ABI
 	contractAddr ethcommon.Address
 	erc20Addr    ethcommon.Address
+	gasConfig    GasConfig
+	override     gasPriceOverride
+	// txTimeout is the amount of time the external sender has to sign each
+	// transaction, if the caller's context has no deadline
+	txTimeout time.Duration
+
+	chansMu sync.Mutex
+	chans   map[types.Hash]*swapChannels
+}
 
+// swapChannels are the channels of the transactions of a single swap, so that
+// a front-end driving several swaps at once can tell their transactions apart.
+type swapChannels struct {
+	// held while a transaction of the swap is being signed, so that the
+	// swap's transactions are sent to be signed in order
 	sync.Mutex
 
 	// outgoing encoded txs to be signed
@@ -52,27 +86,37 @@ type ExternalSender struct {
 	in chan ethcommon.Hash
 }
 
-// NewExternalSender returns a new ExternalSender
+// NewExternalSender returns a new ExternalSender. If the gas config has a
+// maximum gas price, transactions are not sent to be signed while the suggested
+// gas price is above it. Transactions must be signed within a timeout set by the
+// environment, unless the context passed with the transaction has a deadline.
 func NewExternalSender(
-	ctx context.Context,
 	env common.Environment,
 	ec *ethclient.Client,
 	contractAddr ethcommon.Address,
 	erc20Addr ethcommon.Address,
+	gasConfig GasConfig,
 ) (*ExternalSender, error) {
+	txTimeout := defaultTransactionTimeout
 	switch env {
 	case common.Mainnet, common.Stagenet:
-		transactionTimeout = time.Hour
+		txTimeout = mainnetTransactionTimeout
+	}
+
+	erc20ABI, err := contracts.IERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
 	}
 
 	return &ExternalSender{
-		ctx:          ctx,
 		ec:           ec,
 		abi:          contracts.SwapCreatorParsedABI,
+		erc20ABI:     erc20ABI,
 		contractAddr: contractAddr,
 		erc20Addr:    erc20Addr,
-		out:          make(chan *Transaction),
-		in:           make(chan ethcommon.Hash),
+		gasConfig:    gasConfig,
+		txTimeout:    txTimeout,
+		chans:        make(map[types.Hash]*swapChannels),
 	}, nil
 }
 
@@ -84,88 +128,116 @@ func (s *ExternalSender) SetSwapCreatorAddr(addr ethcommon.Address) {
 	s.contractAddr = addr
 }
 
-// OngoingCh returns the channel of outgoing transactions to be signed and submitted
+// channels returns the channels of the swap, creating them if needed.
+func (s *ExternalSender) channels(id types.Hash) *swapChannels {
+	s.chansMu.Lock()
+	defer s.chansMu.Unlock()
+
+	ch, has := s.chans[id]
+	if !has {
+		ch = &swapChannels{
+			out: make(chan *Transaction),
+			in:  make(chan ethcommon.Hash),
+		}
+		s.chans[id] = ch
+	}
+
+	return ch
+}
+
+// OngoingCh returns the channel of the swap's outgoing transactions to be signed and submitted
 func (s *ExternalSender) OngoingCh(id types.Hash) <-chan *Transaction {
-	return s.out
+	return s.channels(id).out
 }
 
-// IncomingCh returns the channel of incoming transaction hashes that have been signed and submitted
+// IncomingCh returns the channel of the swap's incoming transaction hashes that have been signed and submitted
 func (s *ExternalSender) IncomingCh(id types.Hash) chan<- ethcommon.Hash {
-	return s.in
+	return s.channels(id).in
 }
 
-// approve prompts the external sender to sign an ERC20 approve transaction
-//
-//nolint:unused // not used because external sender's NewSwap doesn't support ERC20 tokens
+// approve prompts the external sender to sign an ERC20 approve transaction. The
+// caller must hold the swap channels' lock.
 func (s *ExternalSender) approve(
+	ctx context.Context,
+	ch *swapChannels,
 	spender ethcommon.Address,
 	amount *big.Int,
 ) (*ethtypes.Receipt, error) {
-	input, err := s.abi.Pack("approve", spender, amount)
+	input, err := s.erc20ABI.Pack("approve", spender, amount)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.sendAndReceive(input, s.erc20Addr)
+	return s.signAndWait(ctx, ch, &Transaction{To: s.erc20Addr, Data: input})
 }
 
-// NewSwap prompts the external sender to sign a new_swap transaction
+// NewSwap prompts the external sender to sign a newSwap transaction. For token
+// swaps, the external sender is first prompted to sign an ERC20 approve
+// transaction, so that the SwapCreator contract can transfer the tokens.
 func (s *ExternalSender) NewSwap(
+	ctx context.Context,
+	offerID types.Hash,
 	pubKeyClaim [32]byte,
 	pubKeyRefund [32]byte,
 	claimer ethcommon.Address,
-	timeoutDuration *big.Int,
+	timeoutDuration0 *big.Int,
+	timeoutDuration1 *big.Int,
 	nonce *big.Int,
 	amount coins.EthAssetAmount,
 ) (*ethtypes.Receipt, error) {
-	// TODO: Add ERC20 token support and approve new_swap for the token transfer
-	if amount.IsToken() {
-		return nil, errors.New("external sender does not support ERC20 token swaps")
-	}
-
-	input, err := s.abi.Pack("new_swap", pubKeyClaim, pubKeyRefund, claimer, timeoutDuration,
+	input, err := s.abi.Pack("newSwap", pubKeyClaim, pubKeyRefund, claimer, timeoutDuration0, timeoutDuration1,
 		amount.TokenAddress(), amount.BigInt(), nonce)
 	if err != nil {
 		return nil, err
 	}
 
+	// the approve and newSwap transactions are sent to be signed in order,
+	// without other transactions of the swap in between
+	ch := s.channels(offerID)
+	ch.Lock()
+	defer ch.Unlock()
+
 	tx := &Transaction{
 		To:    s.contractAddr,
 		Data:  input,
 		Value: amount.AsStandard(),
 	}
 
-	s.Lock()
-	defer s.Unlock()
+	if amount.IsToken() {
+		receipt, err := s.approve(ctx, ch, s.contractAddr, amount.BigInt()) //nolint:govet
+		if err != nil {
+			return nil, fmt.Errorf("approve failed, %w", err)
+		}
 
-	s.out <- tx
-	var txHash ethcommon.Hash
-	select {
-	case <-time.After(transactionTimeout):
-		return nil, errTransactionTimeout
-	case txHash = <-s.in:
-	}
+		log.Debugf("approve transaction included %s", common.ReceiptInfo(receipt))
+		log.Infof("%s %s approved for use by SwapCreator's new_swap",
+			amount.AsStandard().Text('f'), amount.StandardSymbol())
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ec, txHash)
-	if err != nil {
-		return nil, err
+		// the tokens are transferred by the contract, not as the tx value
+		tx.Value = new(apd.Decimal)
 	}
 
-	return receipt, nil
+	return s.signAndWait(ctx, ch, tx)
 }
 
 // SetReady prompts the external sender to sign a set_ready transaction
-func (s *ExternalSender) SetReady(swap *contracts.SwapCreatorSwap) (*ethtypes.Receipt, error) {
+func (s *ExternalSender) SetReady(
+	ctx context.Context,
+	offerID types.Hash,
+	swap *contracts.SwapCreatorSwap,
+) (*ethtypes.Receipt, error) {
 	input, err := s.abi.Pack("set_ready", swap)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.sendAndReceive(input, s.contractAddr)
+	return s.sendAndReceive(ctx, offerID, input, s.contractAddr)
 }
 
 // Claim prompts the external sender to sign a claim transaction
 func (s *ExternalSender) Claim(
+	ctx context.Context,
+	offerID types.Hash,
 	swap *contracts.SwapCreatorSwap,
 	secret [32]byte,
 ) (*ethtypes.Receipt, error) {
@@ -174,11 +246,13 @@ func (s *ExternalSender) Claim(
 		return nil, err
 	}
 
-	return s.sendAndReceive(input, s.contractAddr)
+	return s.sendAndReceive(ctx, offerID, input, s.contractAddr)
 }
 
 // Refund prompts the external sender to sign a refund transaction
 func (s *ExternalSender) Refund(
+	ctx context.Context,
+	offerID types.Hash,
 	swap *contracts.SwapCreatorSwap,
 	secret [32]byte,
 ) (*ethtypes.Receipt, error) {
@@ -187,27 +261,101 @@ func (s *ExternalSender) Refund(
 		return nil, err
 	}
 
-	return s.sendAndReceive(input, s.contractAddr)
+	return s.sendAndReceive(ctx, offerID, input, s.contractAddr)
+}
+
+// SetGasPriceOverride sets the gas price of the next transaction sent to be
+// signed, bypassing the maximum gas price. The override is cleared once used.
+func (s *ExternalSender) SetGasPriceOverride(gasPrice *big.Int) {
+	s.override.set(gasPrice)
 }
 
-func (s *ExternalSender) sendAndReceive(input []byte, to ethcommon.Address) (*ethtypes.Receipt, error) {
-	tx := &Transaction{To: to, Data: input}
+// setGasPrice sets the gas price settings of the transaction. If there is a
+// maximum gas price, it returns an error wrapping ErrGasPriceAboveMax if the
+// suggested gas price is above it. A gas price override, if set, is used
+// instead.
+func (s *ExternalSender) setGasPrice(ctx context.Context, tx *Transaction) error {
+	if gasPrice := s.override.take(); gasPrice != nil {
+		tx.GasPrice = gasPrice
+		return nil
+	}
+
+	tx.MaxFeePerGas = s.gasConfig.MaxFeePerGas
+	tx.MaxPriorityFeePerGas = s.gasConfig.MaxPriorityFeePerGas
 
-	s.Lock()
-	defer s.Unlock()
+	if s.gasConfig.MaxGasPrice == nil {
+		return nil
+	}
+
+	gasPrice, err := checkGasPrice(ctx, s.ec, s.gasConfig.MaxGasPrice)
+	if err != nil {
+		return err
+	}
+
+	tx.GasPrice = gasPrice
+	tx.MaxGasPrice = s.gasConfig.MaxGasPrice
+	return nil
+}
+
+func (s *ExternalSender) sendAndReceive(
+	ctx context.Context,
+	offerID types.Hash,
+	input []byte,
+	to ethcommon.Address,
+) (*ethtypes.Receipt, error) {
+	ch := s.channels(offerID)
+	ch.Lock()
+	defer ch.Unlock()
+
+	return s.signAndWait(ctx, ch, &Transaction{To: to, Data: input})
+}
+
+// signAndWait sends the transaction to be signed and submitted by the external
+// sender, and waits for it to be included. The transaction must be signed
+// before the context's deadline or, if it has none, within the sender's
+// timeout. The caller must hold the swap channels' lock.
+func (s *ExternalSender) signAndWait(
+	ctx context.Context,
+	ch *swapChannels,
+	tx *Transaction,
+) (*ethtypes.Receipt, error) {
+	if err := s.setGasPrice(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	signCtx := ctx
+	if _, has := ctx.Deadline(); !has {
+		var cancel context.CancelFunc
+		signCtx, cancel = context.WithTimeout(ctx, s.txTimeout)
+		defer cancel()
+	}
 
-	s.out <- tx
 	var txHash ethcommon.Hash
 	select {
-	case <-time.After(transactionTimeout):
-		return nil, errTransactionTimeout
-	case txHash = <-s.in:
+	case <-signCtx.Done():
+		return nil, signTimeoutErr(signCtx)
+	case ch.out <- tx:
+	}
+
+	select {
+	case <-signCtx.Done():
+		return nil, signTimeoutErr(signCtx)
+	case txHash = <-ch.in:
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ec, txHash)
+	receipt, err := block.WaitForReceipt(ctx, s.ec, txHash)
 	if err != nil {
 		return nil, err
 	}
 
 	return receipt, nil
 }
+
+// signTimeoutErr returns errTransactionTimeout if the signing context's
+// deadline passed, or the context's error if it was cancelled.
+func signTimeoutErr(signCtx context.Context) error {
+	if errors.Is(signCtx.Err(), context.DeadlineExceeded) {
+		return errTransactionTimeout
+	}
+	return signCtx.Err()
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package txsender
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+func newTestExternalSender(t *testing.T) *ExternalSender {
+	s, err := NewExternalSender(common.Development, nil, ethcommon.Address{0x1}, ethcommon.Address{}, GasConfig{})
+	require.NoError(t, err)
+	return s
+}
+
+func TestExternalSender_perSwapChannels(t *testing.T) {
+	s := newTestExternalSender(t)
+	idA, idB := types.Hash{0x1}, types.Hash{0x2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error)
+	go func() {
+		_, err := s.SetReady(ctx, idB, &contracts.SwapCreatorSwap{})
+		errCh <- err
+	}()
+
+	select {
+	case tx := <-s.OngoingCh(idB):
+		require.Equal(t, ethcommon.Address{0x1}, tx.To)
+	case <-s.OngoingCh(idA):
+		t.Fatal("transaction of swap B was sent on the channel of swap A")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for transaction")
+	}
+
+	// the transaction is never signed, so cancelling aborts the wait
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestExternalSender_signDeadline(t *testing.T) {
+	s := newTestExternalSender(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := s.Refund(ctx, types.Hash{0x1}, &contracts.SwapCreatorSwap{}, [32]byte{})
+	require.ErrorIs(t, err, errTransactionTimeout)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package txsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// ErrGasPriceAboveMax is returned when a transaction is not submitted because
+// the suggested gas price is above the configured maximum gas price.
+var ErrGasPriceAboveMax = errors.New("suggested gas price is above the maximum gas price")
+
+// GasConfig holds the optional gas price settings of swap transactions. The
+// zero value uses the gas price suggested by the Ethereum node with no maximum.
+type GasConfig struct {
+	// MaxGasPrice, if set, is the suggested gas price above which transactions
+	// are not sent.
+	MaxGasPrice *big.Int
+
+	// MaxFeePerGas and MaxPriorityFeePerGas, if either is set, make
+	// transactions use EIP-1559 fees instead of a legacy gas price. If only one
+	// of them is set, the other is derived from the current base fee.
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// IsEIP1559 returns true if transactions use EIP-1559 fees.
+func (c *GasConfig) IsEIP1559() bool {
+	return c.MaxFeePerGas != nil || c.MaxPriorityFeePerGas != nil
+}
+
+// gasPriceSuggester is implemented by both *ethclient.Client and
+// extethclient.EthClient.
+type gasPriceSuggester interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// checkGasPrice returns the suggested gas price, or an error wrapping
+// ErrGasPriceAboveMax if it is above maxGasPrice.
+func checkGasPrice(ctx context.Context, ec gasPriceSuggester, maxGasPrice *big.Int) (*big.Int, error) {
+	gasPrice, err := ec.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if gasPrice.Cmp(maxGasPrice) > 0 {
+		return nil, fmt.Errorf("%w: suggested %s wei, maximum %s wei", ErrGasPriceAboveMax, gasPrice, maxGasPrice)
+	}
+
+	return gasPrice, nil
+}
+
+// gasPriceOverride holds a one-time gas price for the next transaction of a
+// swap, set by the operator to get a time-critical transaction mined during a
+// gas price spike. It bypasses the configured maximum gas price.
+type gasPriceOverride struct {
+	mu       sync.Mutex
+	gasPrice *big.Int
+}
+
+func (o *gasPriceOverride) set(gasPrice *big.Int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.gasPrice = gasPrice
+}
+
+// take returns the override, or nil if none is set, and clears it.
+func (o *gasPriceOverride) take() *big.Int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	gasPrice := o.gasPrice
+	o.gasPrice = nil
+	if gasPrice != nil {
+		log.Warnf("USING GAS PRICE OVERRIDE OF %s WEI FOR THIS TRANSACTION, BYPASSING THE MAXIMUM GAS PRICE", gasPrice)
+	}
+	return gasPrice
+}
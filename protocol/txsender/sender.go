@@ -13,12 +13,14 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	logging "github.com/ipfs/go-log"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
@@ -28,59 +30,124 @@ var (
 	log = logging.Logger("txsender")
 )
 
-// Sender signs and submits transactions to the chain
+// Sender signs and submits transactions to the chain. The context passed with
+// each transaction bounds the time spent signing it and waiting for it to be
+// included, and the offer ID is that of the swap the transaction belongs to.
 type Sender interface {
 	SetSwapCreator(*contracts.SwapCreator)
 	SetSwapCreatorAddr(ethcommon.Address)
 	NewSwap(
+		ctx context.Context,
+		offerID types.Hash,
 		pubKeyClaim [32]byte,
 		pubKeyRefund [32]byte,
 		claimer ethcommon.Address,
-		timeoutDuration *big.Int,
+		timeoutDuration0 *big.Int,
+		timeoutDuration1 *big.Int,
 		nonce *big.Int,
 		amount coins.EthAssetAmount,
 	) (*ethtypes.Receipt, error)
-	SetReady(swap *contracts.SwapCreatorSwap) (*ethtypes.Receipt, error)
-	Claim(swap *contracts.SwapCreatorSwap, secret [32]byte) (*ethtypes.Receipt, error)
-	Refund(swap *contracts.SwapCreatorSwap, secret [32]byte) (*ethtypes.Receipt, error)
+	SetReady(ctx context.Context, offerID types.Hash, swap *contracts.SwapCreatorSwap) (*ethtypes.Receipt, error)
+	Claim(
+		ctx context.Context,
+		offerID types.Hash,
+		swap *contracts.SwapCreatorSwap,
+		secret [32]byte,
+	) (*ethtypes.Receipt, error)
+	Refund(
+		ctx context.Context,
+		offerID types.Hash,
+		swap *contracts.SwapCreatorSwap,
+		secret [32]byte,
+	) (*ethtypes.Receipt, error)
+
+	// SetGasPriceOverride sets the legacy gas price of the next transaction,
+	// bypassing the maximum gas price. The override is cleared once used.
+	SetGasPriceOverride(gasPrice *big.Int)
 }
 
 type privateKeySender struct {
-	ctx             context.Context
 	ethClient       extethclient.EthClient
 	swapCreatorAddr ethcommon.Address
 	swapCreator     *contracts.SwapCreator
 	erc20Contract   *contracts.IERC20
+	gasConfig       GasConfig
+	override        gasPriceOverride
 }
 
-// NewSenderWithPrivateKey returns a new *privateKeySender
+// NewSenderWithPrivateKey returns a new *privateKeySender. If the gas config
+// has a maximum gas price, transactions are not submitted while the suggested
+// gas price is above it.
 func NewSenderWithPrivateKey(
-	ctx context.Context,
 	ethClient extethclient.EthClient,
 	swapCreatorAddr ethcommon.Address,
 	swapCreator *contracts.SwapCreator,
 	erc20Contract *contracts.IERC20,
+	gasConfig GasConfig,
 ) Sender {
 	return &privateKeySender{
-		ctx:             ctx,
 		ethClient:       ethClient,
 		swapCreatorAddr: swapCreatorAddr,
 		swapCreator:     swapCreator,
 		erc20Contract:   erc20Contract,
+		gasConfig:       gasConfig,
 	}
 }
 
+// txOpts returns the options of a new transaction. If there is a maximum gas
+// price, an error wrapping ErrGasPriceAboveMax is returned if the suggested gas
+// price is above it, otherwise a legacy transaction uses the suggested gas
+// price. If EIP-1559 fees are configured, the transaction uses them instead of
+// a gas price. A gas price override, if set, is used instead of all of these.
+func (s *privateKeySender) txOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	txOpts, err := s.ethClient.TxOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if gasPrice := s.override.take(); gasPrice != nil {
+		txOpts.GasPrice = gasPrice
+		return txOpts, nil
+	}
+
+	if s.gasConfig.MaxGasPrice != nil {
+		gasPrice, err := checkGasPrice(ctx, s.ethClient, s.gasConfig.MaxGasPrice) //nolint:govet
+		if err != nil {
+			return nil, err
+		}
+
+		if !s.gasConfig.IsEIP1559() {
+			txOpts.GasPrice = gasPrice
+		}
+	}
+
+	if s.gasConfig.IsEIP1559() {
+		txOpts.GasPrice = nil
+		txOpts.GasFeeCap = s.gasConfig.MaxFeePerGas
+		txOpts.GasTipCap = s.gasConfig.MaxPriorityFeePerGas
+	}
+
+	return txOpts, nil
+}
+
 func (s *privateKeySender) SetSwapCreator(contract *contracts.SwapCreator) {
 	s.swapCreator = contract
 }
 
 func (s *privateKeySender) SetSwapCreatorAddr(_ ethcommon.Address) {}
 
+func (s *privateKeySender) SetGasPriceOverride(gasPrice *big.Int) {
+	s.override.set(gasPrice)
+}
+
 func (s *privateKeySender) NewSwap(
+	ctx context.Context,
+	_ types.Hash,
 	pubKeyClaim [32]byte,
 	pubKeyRefund [32]byte,
 	claimer ethcommon.Address,
-	timeoutDuration *big.Int,
+	timeoutDuration0 *big.Int,
+	timeoutDuration1 *big.Int,
 	nonce *big.Int,
 	amount coins.EthAssetAmount,
 ) (*ethtypes.Receipt, error) {
@@ -94,7 +161,7 @@ func (s *privateKeySender) NewSwap(
 	// lock grab in case there are other simultaneous swaps happening with the
 	// same token.
 	if amount.IsToken() {
-		txOpts, err := s.ethClient.TxOpts(s.ctx)
+		txOpts, err := s.txOpts(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -104,7 +171,7 @@ func (s *privateKeySender) NewSwap(
 			return nil, fmt.Errorf("approve tx creation failed, %w", err)
 		}
 
-		receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+		receipt, err := block.WaitForReceipt(ctx, s.ethClient.Raw(), tx.Hash())
 		if err != nil {
 			return nil, fmt.Errorf("approve failed, %w", err)
 		}
@@ -114,7 +181,7 @@ func (s *privateKeySender) NewSwap(
 			amount.AsStandard().Text('f'), amount.StandardSymbol())
 	}
 
-	txOpts, err := s.ethClient.TxOpts(s.ctx)
+	txOpts, err := s.txOpts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -124,14 +191,14 @@ func (s *privateKeySender) NewSwap(
 		txOpts.Value = value
 	}
 
-	tx, err := s.swapCreator.NewSwap(txOpts, pubKeyClaim, pubKeyRefund, claimer, timeoutDuration, timeoutDuration,
+	tx, err := s.swapCreator.NewSwap(txOpts, pubKeyClaim, pubKeyRefund, claimer, timeoutDuration0, timeoutDuration1,
 		amount.TokenAddress(), value, nonce)
 	if err != nil {
 		err = fmt.Errorf("new_swap tx creation failed, %w", err)
 		return nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, s.ethClient.Raw(), tx.Hash())
 	if err != nil {
 		err = fmt.Errorf("new_swap failed, %w", err)
 		return nil, err
@@ -140,10 +207,14 @@ func (s *privateKeySender) NewSwap(
 	return receipt, nil
 }
 
-func (s *privateKeySender) SetReady(swap *contracts.SwapCreatorSwap) (*ethtypes.Receipt, error) {
+func (s *privateKeySender) SetReady(
+	ctx context.Context,
+	_ types.Hash,
+	swap *contracts.SwapCreatorSwap,
+) (*ethtypes.Receipt, error) {
 	s.ethClient.Lock()
 	defer s.ethClient.Unlock()
-	txOpts, err := s.ethClient.TxOpts(s.ctx)
+	txOpts, err := s.txOpts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +225,7 @@ func (s *privateKeySender) SetReady(swap *contracts.SwapCreatorSwap) (*ethtypes.
 		return nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, s.ethClient.Raw(), tx.Hash())
 	if err != nil {
 		err = fmt.Errorf("set_ready failed, %w", err)
 		return nil, err
@@ -164,12 +235,14 @@ func (s *privateKeySender) SetReady(swap *contracts.SwapCreatorSwap) (*ethtypes.
 }
 
 func (s *privateKeySender) Claim(
+	ctx context.Context,
+	_ types.Hash,
 	swap *contracts.SwapCreatorSwap,
 	secret [32]byte,
 ) (*ethtypes.Receipt, error) {
 	s.ethClient.Lock()
 	defer s.ethClient.Unlock()
-	txOpts, err := s.ethClient.TxOpts(s.ctx)
+	txOpts, err := s.txOpts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +253,7 @@ func (s *privateKeySender) Claim(
 		return nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, s.ethClient.Raw(), tx.Hash())
 	if err != nil {
 		err = fmt.Errorf("claim failed, %w", err)
 		return nil, err
@@ -190,12 +263,14 @@ func (s *privateKeySender) Claim(
 }
 
 func (s *privateKeySender) Refund(
+	ctx context.Context,
+	_ types.Hash,
 	swap *contracts.SwapCreatorSwap,
 	secret [32]byte,
 ) (*ethtypes.Receipt, error) {
 	s.ethClient.Lock()
 	defer s.ethClient.Unlock()
-	txOpts, err := s.ethClient.TxOpts(s.ctx)
+	txOpts, err := s.txOpts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +281,7 @@ func (s *privateKeySender) Refund(
 		return nil, err
 	}
 
-	receipt, err := block.WaitForReceipt(s.ctx, s.ethClient.Raw(), tx.Hash())
+	receipt, err := block.WaitForReceipt(ctx, s.ethClient.Raw(), tx.Hash())
 	if err != nil {
 		err = fmt.Errorf("refund failed, %w", err)
 		return nil, err
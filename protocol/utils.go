@@ -9,8 +9,11 @@ import (
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
 
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	logging "github.com/ipfs/go-log"
+	"go.uber.org/zap"
 )
 
 const etherSymbol = "ETH"
@@ -29,6 +32,21 @@ func AssetSymbol(b backend.Backend, asset types.EthAsset) (string, error) {
 	return etherSymbol, nil
 }
 
+// SwapCorrelationID returns a short ID for a swap, the first 8 hex digits of
+// its offer ID, to tell its log lines apart from those of concurrent swaps.
+func SwapCorrelationID(offerID types.Hash) string {
+	return offerID.Hex()[2:10]
+}
+
+// SwapLogger returns a logger for the lines of a single swap. Its name is the
+// name of the given logger followed by the swap's correlation ID, and it adds
+// the swap's offer ID and the peer ID of its counterparty as fields to each
+// line.
+func SwapLogger(logger *logging.ZapEventLogger, info *swap.Info) *zap.SugaredLogger {
+	return logger.Named(SwapCorrelationID(info.OfferID)).
+		With("offerID", info.OfferID.String(), "peerID", info.PeerID.String())
+}
+
 // CheckSwapID checks if the given log is for the given swap ID.
 func CheckSwapID(log *ethtypes.Log, eventNameTopic [32]byte, contractSwapID types.Hash) error {
 	if len(log.Topics) < 2 {
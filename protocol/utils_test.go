@@ -0,0 +1,43 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"testing"
+
+	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestSwapCorrelationID(t *testing.T) {
+	offerID := types.Hash{0x1a, 0x2b, 0x3c, 0x4d, 0x5e}
+	require.Equal(t, "1a2b3c4d", SwapCorrelationID(offerID))
+}
+
+func TestSwapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := &logging.ZapEventLogger{SugaredLogger: *zap.New(core).Named("xmrmaker").Sugar()}
+	info := &swap.Info{
+		OfferID: types.Hash{0x1a, 0x2b, 0x3c, 0x4d, 0x5e},
+		PeerID:  peer.ID("peer"),
+	}
+
+	SwapLogger(logger, info).Infof("locked %d XMR", 1)
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	require.Equal(t, "xmrmaker.1a2b3c4d", entries[0].LoggerName)
+	require.Equal(t, "locked 1 XMR", entries[0].Message)
+	require.Equal(t, map[string]interface{}{
+		"offerID": info.OfferID.String(),
+		"peerID":  info.PeerID.String(),
+	}, entries[0].ContextMap())
+}
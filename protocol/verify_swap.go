@@ -0,0 +1,194 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+var (
+	claimedTopic  = common.GetTopic(common.ClaimedEventSignature)
+	refundedTopic = common.GetTopic(common.RefundedEventSignature)
+)
+
+// VerifySwap compares the stored state of a swap with the state of its swap in
+// the contract and with the contract's Claimed and Refunded logs for it. It
+// returns the swap's stage in the contract and a description of each
+// divergence found; if there are none, the stored state is consistent with
+// the chain. Unlike the checks done when a swap is resumed, nothing is acted
+// on.
+func VerifySwap(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	info *swap.Info,
+	ethSwapInfo *db.EthereumSwapInfo,
+) (byte, []string, error) {
+	var mismatches []string
+	addMismatch := func(format string, args ...interface{}) {
+		mismatches = append(mismatches, fmt.Sprintf(format, args...))
+	}
+
+	if ethSwapInfo.Swap.SwapID() != ethSwapInfo.SwapID {
+		addMismatch("stored contract swap ID %s is not the hash of the stored swap", ethSwapInfo.SwapID)
+	}
+
+	if info.Timeout0 != nil && info.Timeout0.Unix() != ethSwapInfo.Swap.Timeout0.Int64() {
+		addMismatch("local timeout0 is %d, but the contract swap's is %d",
+			info.Timeout0.Unix(), ethSwapInfo.Swap.Timeout0)
+	}
+
+	if info.Timeout1 != nil && info.Timeout1.Unix() != ethSwapInfo.Swap.Timeout1.Int64() {
+		addMismatch("local timeout1 is %d, but the contract swap's is %d",
+			info.Timeout1.Unix(), ethSwapInfo.Swap.Timeout1)
+	}
+
+	contract, err := contracts.NewSwapCreator(ethSwapInfo.SwapCreatorAddr, ec.Raw())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	stage, err := contract.Swaps(ec.CallOpts(ctx), ethSwapInfo.SwapID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if stage == contracts.StageInvalid {
+		addMismatch("contract %s has no swap with ID %s", ethSwapInfo.SwapCreatorAddr, ethSwapInfo.SwapID)
+		return stage, mismatches, nil
+	}
+
+	claimLog, refundLog, err := findOutcomeLogs(ctx, ec, ethSwapInfo)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	completed := stage == contracts.StageCompleted
+	if completed && claimLog == nil && refundLog == nil {
+		addMismatch("contract swap is completed, but no Claimed or Refunded log was found")
+	}
+	if !completed && (claimLog != nil || refundLog != nil) {
+		addMismatch("found a Claimed or Refunded log, but the contract stage is %s", contracts.StageToString(stage))
+	}
+
+	claimed := completed && claimLog != nil
+	refunded := completed && refundLog != nil
+	if !statusMatchesStage(info.Status, stage, claimed, refunded) {
+		outcome := ""
+		switch {
+		case claimed:
+			outcome = " (claimed)"
+		case refunded:
+			outcome = " (refunded)"
+		}
+
+		addMismatch("local status is %s, but the contract stage is %s%s",
+			info.Status, contracts.StageToString(stage), outcome)
+	}
+
+	if msg := checkTxHash("claim", info.ClaimTxHash, claimLog); msg != "" {
+		mismatches = append(mismatches, msg)
+	}
+
+	if msg := checkTxHash("refund", info.RefundTxHash, refundLog); msg != "" {
+		mismatches = append(mismatches, msg)
+	}
+
+	return stage, mismatches, nil
+}
+
+// statusMatchesStage returns true if a swap with the given local status can
+// have the given stage in the contract.
+func statusMatchesStage(status types.Status, stage byte, claimed, refunded bool) bool {
+	switch status {
+	case types.ExpectingKeys, types.KeysExchanged, types.ETHLocked:
+		return stage == contracts.StagePending
+	case types.XMRLocked:
+		// the counterparty may have set the contract to ready since
+		return stage == contracts.StagePending || stage == contracts.StageReady
+	case types.ContractReady:
+		return stage == contracts.StageReady
+	case types.SweepingXMR:
+		// the taker sweeps after a claim, the maker after a refund
+		return claimed || refunded
+	case types.CompletedSuccess:
+		return claimed
+	case types.CompletedRefund:
+		return refunded
+	case types.CompletedAbort:
+		// the ETH may still be waiting to be refunded
+		return stage == contracts.StagePending || refunded
+	default:
+		return false
+	}
+}
+
+// checkTxHash returns a description of the divergence between a recorded
+// transaction hash and the log of the transaction, if there is one.
+func checkTxHash(name string, recorded *ethcommon.Hash, l *ethtypes.Log) string {
+	switch {
+	case recorded == nil:
+		return ""
+	case l == nil:
+		return fmt.Sprintf("local %s transaction is %s, but no log of it was found", name, recorded)
+	case *recorded != l.TxHash:
+		return fmt.Sprintf("local %s transaction is %s, but the log is from %s", name, recorded, l.TxHash)
+	default:
+		return ""
+	}
+}
+
+// findOutcomeLogs returns the contract's Claimed and Refunded logs for the
+// swap, if any, ignoring logs that were removed by a reorg.
+func findOutcomeLogs(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	ethSwapInfo *db.EthereumSwapInfo,
+) (*ethtypes.Log, *ethtypes.Log, error) {
+	logs, err := ec.Raw().FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: ethSwapInfo.StartNumber,
+		Addresses: []ethcommon.Address{ethSwapInfo.SwapCreatorAddr},
+		Topics:    [][]ethcommon.Hash{{claimedTopic, refundedTopic}},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+
+	var claimLog, refundLog *ethtypes.Log
+	for i := range logs {
+		l := &logs[i]
+		if l.Removed || len(l.Topics) == 0 {
+			continue
+		}
+
+		topic := l.Topics[0]
+		err = CheckSwapID(l, topic, ethSwapInfo.SwapID)
+		if errors.Is(err, ErrLogNotForUs) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if topic == claimedTopic {
+			claimLog = l
+		} else {
+			refundLog = l
+		}
+	}
+
+	return claimLog, refundLog, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package protocol
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+)
+
+func TestStatusMatchesStage(t *testing.T) {
+	require.True(t, statusMatchesStage(types.XMRLocked, contracts.StagePending, false, false))
+	require.True(t, statusMatchesStage(types.XMRLocked, contracts.StageReady, false, false))
+	require.False(t, statusMatchesStage(types.XMRLocked, contracts.StageCompleted, true, false))
+
+	require.True(t, statusMatchesStage(types.CompletedSuccess, contracts.StageCompleted, true, false))
+	require.False(t, statusMatchesStage(types.CompletedSuccess, contracts.StageCompleted, false, true))
+	require.True(t, statusMatchesStage(types.CompletedRefund, contracts.StageCompleted, false, true))
+	require.True(t, statusMatchesStage(types.SweepingXMR, contracts.StageCompleted, false, true))
+
+	require.True(t, statusMatchesStage(types.CompletedAbort, contracts.StagePending, false, false))
+	require.False(t, statusMatchesStage(types.CompletedAbort, contracts.StageCompleted, true, false))
+}
+
+func TestCheckTxHash(t *testing.T) {
+	txHash := ethcommon.Hash{0x1}
+	require.Empty(t, checkTxHash("claim", nil, nil))
+	require.Empty(t, checkTxHash("claim", &txHash, &ethtypes.Log{TxHash: txHash}))
+	require.Contains(t, checkTxHash("claim", &txHash, nil), "no log")
+	require.Contains(t, checkTxHash("claim", &txHash, &ethtypes.Log{TxHash: ethcommon.Hash{0x2}}), "the log is from")
+}
@@ -4,14 +4,101 @@
 package xmrmaker
 
 import (
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 )
 
-// MakeOffer makes a new swap offer.
+// MakeOffer makes a new swap offer. If expiresAt is not nil, the offer is
+// removed once that time is reached. If minTakerRep is not nil, takes from
+// takers that do not meet it are rejected. If persistent is set, the offer is
+// replaced by a new offer with the same terms after each successful swap. If
+// repricing is not nil, the offer is periodically replaced by an offer at the
+// market rate with the repricing spread applied.
 func (inst *Instance) MakeOffer(
 	o *types.Offer,
 	useRelayer bool,
+	expiresAt *time.Time,
+	minTakerRep *types.MinTakerReputation,
+	persistent bool,
+	repricing *types.Repricing,
 ) (*types.OfferExtra, error) {
+	if err := inst.validateOffer(o, useRelayer, expiresAt, minTakerRep, repricing); err != nil {
+		return nil, err
+	}
+
+	extra, err := inst.offerManager.AddOffer(o, useRelayer, expiresAt, minTakerRep, persistent, repricing)
+	if err != nil {
+		return nil, err
+	}
+
+	inst.storeOfferDefaults(o)
+	inst.net.Advertise()
+	log.Infof("created new offer: %v", o)
+	inst.startRepricing(o.ID, repricing)
+	return extra, nil
+}
+
+// ValidateOffer runs the same checks as MakeOffer, but instead of adding and
+// advertising the offer, it returns a summary of the offer's amounts.
+func (inst *Instance) ValidateOffer(
+	o *types.Offer,
+	useRelayer bool,
+	expiresAt *time.Time,
+	minTakerRep *types.MinTakerReputation,
+	repricing *types.Repricing,
+) (*types.OfferSummary, error) {
+	// MakeOffer relies on the offer fields being validated when the offer is
+	// written to the database, which does not happen here.
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := inst.validateOffer(o, useRelayer, expiresAt, minTakerRep, repricing); err != nil {
+		return nil, err
+	}
+
+	balance, err := inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &types.OfferSummary{
+		UnlockedBalance: coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero(),
+	}
+
+	if o.EthAsset.IsETH() {
+		if summary.TakerMinAmount, err = o.ExchangeRate.ToETH(o.MinAmount); err != nil {
+			return nil, err
+		}
+		if summary.TakerMaxAmount, err = o.ExchangeRate.ToETH(o.MaxAmount); err != nil {
+			return nil, err
+		}
+		return summary, nil
+	}
+
+	tokenInfo, err := inst.backend.ETHClient().ERC20Info(inst.backend.Ctx(), o.EthAsset.Address())
+	if err != nil {
+		return nil, err
+	}
+	if summary.TakerMinAmount, err = o.ExchangeRate.ToERC20Amount(o.MinAmount, tokenInfo); err != nil {
+		return nil, err
+	}
+	if summary.TakerMaxAmount, err = o.ExchangeRate.ToERC20Amount(o.MaxAmount, tokenInfo); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (inst *Instance) validateOffer(
+	o *types.Offer,
+	useRelayer bool,
+	expiresAt *time.Time,
+	minTakerRep *types.MinTakerReputation,
+	repricing *types.Repricing,
+) error {
 	err := validateMinBalance(
 		inst.backend.Ctx(),
 		inst.backend.XMRClient(),
@@ -20,21 +107,36 @@ func (inst *Instance) MakeOffer(
 		o.EthAsset,
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if useRelayer && o.EthAsset.IsToken() {
-		return nil, errRelayingWithNonEthAsset
+		return errRelayingWithNonEthAsset
 	}
 
-	extra, err := inst.offerManager.AddOffer(o, useRelayer)
-	if err != nil {
-		return nil, err
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errOfferExpiryNotInFuture
 	}
 
-	inst.net.Advertise()
-	log.Infof("created new offer: %v", o)
-	return extra, nil
+	if minTakerRep != nil {
+		if err := minTakerRep.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if repricing != nil {
+		if o.EthAsset.IsToken() {
+			return errRepricingWithNonEthAsset
+		}
+		if err := repricing.Validate(); err != nil {
+			return err
+		}
+		if o.ExchangeRate.Cmp(repricing.FloorRate) < 0 {
+			return errExchangeRateBelowFloor
+		}
+	}
+
+	return nil
 }
 
 // GetOffers returns all current offers.
@@ -42,6 +144,26 @@ func (inst *Instance) GetOffers() []*types.Offer {
 	return inst.offerManager.GetOffers()
 }
 
+// OfferExpiry returns the time at which the current offer with the given ID
+// expires, or nil if the offer does not expire or does not exist.
+func (inst *Instance) OfferExpiry(id types.Hash) *time.Time {
+	_, extra, err := inst.offerManager.GetOffer(id)
+	if err != nil {
+		return nil
+	}
+	return extra.ExpiresAt
+}
+
+// OfferExtra returns the settings that the current offer with the given ID was
+// made with, or nil if the offer does not exist.
+func (inst *Instance) OfferExtra(id types.Hash) *types.OfferExtra {
+	_, extra, err := inst.offerManager.GetOffer(id)
+	if err != nil {
+		return nil
+	}
+	return extra
+}
+
 // ClearOffers clears all offers.
 func (inst *Instance) ClearOffers(offerIDs []types.Hash) error {
 	if len(offerIDs) == 0 {
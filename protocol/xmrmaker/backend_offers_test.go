@@ -0,0 +1,58 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestInstance_ValidateOffer(t *testing.T) {
+	inst, _ := newTestInstanceAndDB(t)
+	min := coins.StrToDecimal("0.1")
+	max := coins.StrToDecimal("0.5")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+
+	// the offer database and network mocks fail the test if the offer is
+	// stored or advertised
+	summary, err := inst.ValidateOffer(offer, false, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "0.01", summary.TakerMinAmount.Text('f'))
+	require.Equal(t, "0.05", summary.TakerMaxAmount.Text('f'))
+	require.Greater(t, summary.UnlockedBalance.Cmp(max), 0)
+	require.Empty(t, inst.GetOffers())
+}
+
+func TestInstance_ValidateOffer_minGreaterThanMax(t *testing.T) {
+	inst, _ := newTestInstanceAndDB(t)
+	min := coins.StrToDecimal("0.5")
+	max := coins.StrToDecimal("0.1")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+
+	_, err := inst.ValidateOffer(offer, false, nil, nil, nil)
+	require.ErrorContains(t, err, `"minAmount" must be less than or equal to "maxAmount"`)
+}
+
+func TestInstance_ValidateOffer_extras(t *testing.T) {
+	inst, _ := newTestInstanceAndDB(t)
+	min := coins.StrToDecimal("0.1")
+	max := coins.StrToDecimal("0.5")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+
+	expired := time.Now().Add(-time.Second)
+	_, err := inst.ValidateOffer(offer, false, &expired, nil, nil)
+	require.ErrorIs(t, err, errOfferExpiryNotInFuture)
+
+	minTakerRep := &types.MinTakerReputation{SuccessRate: coins.StrToDecimal("1.5")}
+	_, err = inst.ValidateOffer(offer, false, nil, minTakerRep, nil)
+	require.ErrorContains(t, err, "taker success rate of 1.5 is not between 0 and 1")
+}
@@ -104,9 +104,6 @@ func (s *swapState) checkContract(txHash ethcommon.Hash) error {
 
 // checkAndSetTimeouts checks that the timeouts set by the counterparty when initiating the swap
 // are not too short or too long.
-// we expect the timeout to be of a certain length (1 hour for mainnet/stagenet), and allow a 3 minute
-// variation between now and the expected time until the first timeout t0, to allow for block confirmations.
-// the time between t0 and t1 should always be the exact length we expect.
 func (s *swapState) checkAndSetTimeouts(t0, t1 *big.Int) error {
 	s.setTimeouts(t0, t1)
 
@@ -116,14 +113,25 @@ func (s *swapState) checkAndSetTimeouts(t0, t1 *big.Int) error {
 		return nil
 	}
 
-	expectedTimeout := common.SwapTimeoutFromEnv(s.Backend.Env())
-	allowableTimeDiff := expectedTimeout / 20
+	return checkTimeouts(s.Backend.Env(), time.Now(), s.t0, s.t1)
+}
 
-	if s.t1.Sub(s.t0) != expectedTimeout {
+// checkTimeouts checks that the durations of both swap timeout periods are
+// within the bounds of the environment. The counterparty chooses each duration
+// separately. The period until t0 started when the swap was initiated on-chain,
+// shortly before now, so it is allowed to be shorter than the minimum by a small
+// margin, to allow for block confirmations.
+func checkTimeouts(env common.Environment, now time.Time, t0 time.Time, t1 time.Time) error {
+	minDuration, maxDuration := common.SwapTimeoutBoundsFromEnv(env)
+	allowableTimeDiff := common.SwapTimeoutFromEnv(env) / 20
+
+	t1Duration := t1.Sub(t0)
+	if t1Duration < minDuration || t1Duration > maxDuration {
 		return errInvalidT1
 	}
 
-	if time.Now().Add(expectedTimeout).Sub(s.t0).Abs() > allowableTimeDiff {
+	t0Duration := t0.Sub(now)
+	if t0Duration < minDuration-allowableTimeDiff || t0Duration > maxDuration {
 		return errInvalidT0
 	}
 
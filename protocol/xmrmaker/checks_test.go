@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestCheckTimeouts(t *testing.T) {
+	now := time.Now()
+
+	type testCase struct {
+		t0Duration time.Duration
+		t1Duration time.Duration
+		expected   error
+	}
+
+	for _, tc := range []testCase{
+		{t0Duration: time.Hour, t1Duration: time.Hour},
+		// asymmetric durations, both within the bounds
+		{t0Duration: 40 * time.Minute, t1Duration: 2 * time.Hour},
+		{t0Duration: 4 * time.Hour, t1Duration: 30 * time.Minute},
+		// some time passed since the swap was initiated on-chain
+		{t0Duration: 28 * time.Minute, t1Duration: time.Hour},
+		{t0Duration: 20 * time.Minute, t1Duration: time.Hour, expected: errInvalidT0},
+		{t0Duration: 5 * time.Hour, t1Duration: time.Hour, expected: errInvalidT0},
+		{t0Duration: time.Hour, t1Duration: 20 * time.Minute, expected: errInvalidT1},
+		{t0Duration: time.Hour, t1Duration: 5 * time.Hour, expected: errInvalidT1},
+	} {
+		t0 := now.Add(tc.t0Duration)
+		t1 := t0.Add(tc.t1Duration)
+		err := checkTimeouts(common.Mainnet, now, t0, t1)
+		if tc.expected == nil {
+			require.NoError(t, err, "t0Duration=%s t1Duration=%s", tc.t0Duration, tc.t1Duration)
+		} else {
+			require.ErrorIs(t, err, tc.expected, "t0Duration=%s t1Duration=%s", tc.t0Duration, tc.t1Duration)
+		}
+	}
+
+	// there are no bounds in development
+	err := checkTimeouts(common.Development, now, now.Add(time.Second), now.Add(48*time.Hour))
+	require.NoError(t, err)
+}
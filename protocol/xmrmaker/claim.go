@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -15,6 +16,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -22,6 +24,8 @@ import (
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/protocol/txsender"
 	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
@@ -33,13 +37,13 @@ func (s *swapState) claimFunds() (*ethtypes.Receipt, error) {
 	}
 
 	if types.EthAsset(s.contractSwap.Asset) == types.EthAssetETH {
-		log.Infof("balance before claim: %s ETH", weiBalance.AsEtherString())
+		s.logger.Infof("balance before claim: %s ETH", weiBalance.AsEtherString())
 	} else {
 		balance, err := s.ETHClient().ERC20Balance(s.ctx, s.contractSwap.Asset) //nolint:govet
 		if err != nil {
 			return nil, err
 		}
-		log.Infof("balance before claim: %s %s", balance.AsStandardString(), balance.StandardSymbol())
+		s.logger.Infof("balance before claim: %s %s", balance.AsStandardString(), balance.StandardSymbol())
 	}
 
 	hasBalanceToClaim, err := checkForMinClaimBalance(s.ctx, s.ETHClient())
@@ -47,24 +51,49 @@ func (s *swapState) claimFunds() (*ethtypes.Receipt, error) {
 		return nil, err
 	}
 
+	// relayer fee was set or we had insufficient funds to claim without a relayer
+	useRelayer := s.offerExtra.UseRelayer || !hasBalanceToClaim
+	claimMethod := pswap.ClaimMethodSelf
+	if useRelayer {
+		claimMethod = pswap.ClaimMethodRelayed
+	}
+
+	if useRelayer && !hasTimeToRelayClaim(time.Now(), s.t1, s.relayClaimBuffer) {
+		if !hasBalanceToClaim {
+			s.logger.Errorf("swap %s needs manual attention: less than %s left before t1 (%s) to relay the claim",
+				s.OfferID(), s.relayClaimBuffer, s.t1.Format(common.TimeFmtSecs))
+			s.setClaimMethod(pswap.ClaimMethodManual)
+			return nil, errRelayClaimTooLate
+		}
+
+		s.logger.Warnf("less than %s left before t1 (%s) to relay the claim, claiming without a relayer",
+			s.relayClaimBuffer, s.t1.Format(common.TimeFmtSecs))
+		useRelayer = false
+		claimMethod = pswap.ClaimMethodSelfNearTimeout1
+	}
+	s.setClaimMethod(claimMethod)
+
 	var receipt *ethtypes.Receipt
 
 	// call swap.Swap.Claim() w/ b.privkeys.sk, revealing XMRMaker's secret spend key
-	if s.offerExtra.UseRelayer || !hasBalanceToClaim {
-		// relayer fee was set or we had insufficient funds to claim without a relayer
+	if useRelayer {
 		receipt, err = s.claimWithRelay()
 		if err != nil {
 			return nil, fmt.Errorf("failed to claim using relayers: %w", err)
 		}
-		log.Infof("claim transaction was relayed: %s", common.ReceiptInfo(receipt))
+		s.logger.Infof("claim transaction was relayed: %s", common.ReceiptInfo(receipt))
 	} else {
 		// claim and wait for tx to be included
 		sc := s.getSecret()
-		receipt, err = s.sender.Claim(s.contractSwap, sc)
+		receipt, err = s.sender.Claim(s.ctx, s.OfferID(), s.contractSwap, sc)
+		if errors.Is(err, txsender.ErrGasPriceAboveMax) {
+			return nil, fmt.Errorf("cannot claim yet, the claim must succeed before %s: %w",
+				s.t1.Format(common.TimeFmtSecs), err)
+		}
 		if err != nil {
 			return nil, err
 		}
-		log.Infof("claim transaction %s", common.ReceiptInfo(receipt))
+		s.logger.Infof("claim transaction %s", common.ReceiptInfo(receipt))
 	}
 	if err != nil {
 		return nil, err
@@ -75,19 +104,34 @@ func (s *swapState) claimFunds() (*ethtypes.Receipt, error) {
 		if err != nil {
 			return nil, err
 		}
-		log.Infof("balance after claim: %s ETH", balance.AsEtherString())
+		s.logger.Infof("balance after claim: %s ETH", balance.AsEtherString())
 	} else {
 		balance, err := s.ETHClient().ERC20Balance(s.ctx, s.contractSwap.Asset)
 		if err != nil {
 			return nil, err
 		}
 
-		log.Infof("balance after claim: %s %s", balance.AsStandardString(), balance.StandardSymbol())
+		s.logger.Infof("balance after claim: %s %s", balance.AsStandardString(), balance.StandardSymbol())
 	}
 
 	return receipt, nil
 }
 
+// hasTimeToRelayClaim returns true if, at the given time, at least the relay
+// claim buffer is left before t1 to account for the relaying latency.
+func hasTimeToRelayClaim(now time.Time, t1 time.Time, buffer time.Duration) bool {
+	return now.Add(buffer).Before(t1)
+}
+
+// setClaimMethod records how we are claiming the ETH in the swap's info, so
+// the decision is visible in the swap's status.
+func (s *swapState) setClaimMethod(method string) {
+	s.info.ClaimMethod = method
+	if err := s.SwapManager().WriteSwapToDB(s.info); err != nil {
+		s.logger.Warnf("failed to write claim method of swap %s to db: %s", s.OfferID(), err)
+	}
+}
+
 // checkForMinClaimBalance check if we have enough balance to call claim.
 // return true if we do, false otherwise.
 func checkForMinClaimBalance(ctx context.Context, ec extethclient.EthClient) (bool, error) {
@@ -123,11 +167,16 @@ func checkForMinClaimBalance(ctx context.Context, ec extethclient.EthClient) (bo
 }
 
 // relayClaimWithXMRTaker relays the claim to the swap's XMR taker, who should
-// process the claim even if they are not relaying claims for everyone.
-func (s *swapState) relayClaimWithXMRTaker(request *message.RelayClaimRequest) (*ethtypes.Receipt, error) {
+// process the claim even if they are not relaying claims for everyone. The
+// XMR taker relays for the default fee.
+func (s *swapState) relayClaimWithXMRTaker() (*ethtypes.Receipt, error) {
+	request, err := s.createRelayClaimRequest(nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// only requests to the XMR taker set the offerID field
 	request.OfferID = &s.offer.ID
-	defer func() { request.OfferID = nil }()
 
 	response, err := s.Backend.SubmitClaimToRelayer(s.info.PeerID, request)
 	if err != nil {
@@ -146,14 +195,56 @@ func (s *swapState) relayClaimWithXMRTaker(request *message.RelayClaimRequest) (
 		return nil, fmt.Errorf("failed to get receipt of relayer's tx: %s", err)
 	}
 
-	log.Infof("relayer's claim via counterparty included and validated %s", common.ReceiptInfo(receipt))
+	s.logger.Infof("relayer's claim via counterparty included and validated %s", common.ReceiptInfo(receipt))
+	s.setRelayerFee(request.FeeWei())
 	return receipt, nil
 }
 
+// relayerWithFee is a relayer advertised in the DHT and the fee it charges.
+type relayerWithFee struct {
+	peerID peer.ID
+	fee    *coins.WeiAmount
+}
+
+// relayersByFee queries the fee of each relayer that is not our swap
+// counterparty and returns the relayers whose fee is less than the swap
+// value, cheapest first. Relayers that don't answer the query may predate fee
+// queries, so they are included with the default fee.
+func (s *swapState) relayersByFee(relayers []peer.ID) []*relayerWithFee {
+	var byFee []*relayerWithFee
+	for _, relayerPeerID := range relayers {
+		if relayerPeerID == s.info.PeerID {
+			s.logger.Debugf("skipping DHT-advertised relayer that is our swap counterparty")
+			continue
+		}
+
+		fee, err := s.Backend.QueryRelayerFee(relayerPeerID)
+		if err != nil {
+			s.logger.Debugf("failed to query fee of relayer %s, assuming the default fee: %s", relayerPeerID, err)
+			fee = coins.NewWeiAmount(coins.RelayerFeeWei)
+		}
+
+		if fee.BigInt().Cmp(s.contractSwap.Value) >= 0 {
+			s.logger.Debugf("skipping relayer %s, whose fee of %s ETH is not less than the swap value",
+				relayerPeerID, fee.AsEtherString())
+			continue
+		}
+
+		byFee = append(byFee, &relayerWithFee{peerID: relayerPeerID, fee: fee})
+	}
+
+	// stable, so that relayers with equal fees keep the DHT's order
+	sort.SliceStable(byFee, func(i, j int) bool {
+		return byFee[i].fee.Cmp(byFee[j].fee) < 0
+	})
+
+	return byFee
+}
+
 // claimWithAdvertisedRelayers relays the claim to nodes that advertise
-// themselves as relayers in the DHT until the claim succeeds, all relayers have
-// been tried, or the context is cancelled.
-func (s *swapState) claimWithAdvertisedRelayers(request *message.RelayClaimRequest) (*ethtypes.Receipt, error) {
+// themselves as relayers in the DHT, cheapest first, until the claim
+// succeeds, all relayers have been tried, or the context is cancelled.
+func (s *swapState) claimWithAdvertisedRelayers() (*ethtypes.Receipt, error) {
 	relayers, err := s.Backend.DiscoverRelayers()
 	if err != nil {
 		return nil, err
@@ -162,17 +253,18 @@ func (s *swapState) claimWithAdvertisedRelayers(request *message.RelayClaimReque
 	if len(relayers) == 0 {
 		return nil, errors.New("no relayers found to submit claim to")
 	}
-	log.Debugf("Found %d relayers to submit claim to", len(relayers))
-	for _, relayerPeerID := range relayers {
-		if relayerPeerID == s.info.PeerID {
-			log.Debugf("skipping DHT-advertised relayer that is our swap counterparty")
-			continue
+	s.logger.Debugf("Found %d relayers to submit claim to", len(relayers))
+	for _, candidate := range s.relayersByFee(relayers) {
+		request, err := s.createRelayClaimRequest(candidate.fee)
+		if err != nil {
+			return nil, err
 		}
 
-		log.Debugf("submitting claim to relayer with peer ID %s", relayerPeerID)
-		resp, err := s.Backend.SubmitClaimToRelayer(relayerPeerID, request)
+		s.logger.Debugf("submitting claim to relayer with peer ID %s and fee %s ETH",
+			candidate.peerID, candidate.fee.AsEtherString())
+		resp, err := s.Backend.SubmitClaimToRelayer(candidate.peerID, request)
 		if err != nil {
-			log.Warnf("failed to submit tx to relayer: %s", err)
+			s.logger.Warnf("failed to submit tx to relayer: %s", err)
 			continue
 		}
 
@@ -185,25 +277,21 @@ func (s *swapState) claimWithAdvertisedRelayers(request *message.RelayClaimReque
 			s.getSecret(),
 		)
 		if err != nil {
-			log.Warnf("failed to get receipt of relayer's tx: %s", err)
+			s.logger.Warnf("failed to get receipt of relayer's tx: %s", err)
 			continue
 		}
 
-		log.Infof("DHT relayer's claim included and validated %s", common.ReceiptInfo(receipt))
-
+		s.logger.Infof("DHT relayer's claim included and validated %s", common.ReceiptInfo(receipt))
+		s.setRelayerFee(request.FeeWei())
 		return receipt, nil
 	}
 
 	return nil, errors.New("failed to relay claim with any non-counterparty relayer")
 }
 
-// claimWithRelay first tries to relay sequentially with all relayers
-// advertising in the DHT that are not the XMR taker and, if that fails, falls
-// back to the XMR taker who, if using our software, will act as a relayer of
-// last resort for their own swap, even if they are not performing relay
-// operations more generally. Note that the receipt returned is for a
-// transaction created by the remote relayer, not by us.
-func (s *swapState) claimWithRelay() (*ethtypes.Receipt, error) {
+// createRelayClaimRequest returns a claim request for a relayer, signed with
+// the given relayer fee, or with the default fee if it is nil.
+func (s *swapState) createRelayClaimRequest(fee *coins.WeiAmount) (*message.RelayClaimRequest, error) {
 	forwarderAddr, err := s.SwapCreator().TrustedForwarder(&bind.CallOpts{Context: s.ctx})
 	if err != nil {
 		return nil, err
@@ -211,7 +299,7 @@ func (s *swapState) claimWithRelay() (*ethtypes.Receipt, error) {
 
 	secret := s.getSecret()
 
-	request, err := relayer.CreateRelayClaimRequest(
+	return relayer.CreateRelayClaimRequest(
 		s.ctx,
 		s.ETHClient().PrivateKey(),
 		s.ETHClient().Raw(),
@@ -219,16 +307,31 @@ func (s *swapState) claimWithRelay() (*ethtypes.Receipt, error) {
 		forwarderAddr,
 		s.contractSwap,
 		&secret,
+		fee,
 	)
-	if err != nil {
-		return nil, err
+}
+
+// setRelayerFee records the fee paid to the relayer that claimed the swap's
+// ETH.
+func (s *swapState) setRelayerFee(feeWei *big.Int) {
+	s.info.RelayerFee = coins.NewWeiAmount(feeWei).AsEther()
+	if err := s.SwapManager().WriteSwapToDB(s.info); err != nil {
+		s.logger.Warnf("failed to write relayer fee of swap %s to db: %s", s.OfferID(), err)
 	}
+}
 
-	receipt, err := s.claimWithAdvertisedRelayers(request)
+// claimWithRelay first tries to relay with the relayers advertising in the
+// DHT that are not the XMR taker, cheapest first, and, if that fails, falls
+// back to the XMR taker who, if using our software, will act as a relayer of
+// last resort for their own swap, even if they are not performing relay
+// operations more generally. Note that the receipt returned is for a
+// transaction created by the remote relayer, not by us.
+func (s *swapState) claimWithRelay() (*ethtypes.Receipt, error) {
+	receipt, err := s.claimWithAdvertisedRelayers()
 	if err != nil {
-		log.Warnf("failed to relay with DHT-advertised relayers: %s", err)
-		log.Infof("falling back to swap counterparty as relayer")
-		return s.relayClaimWithXMRTaker(request)
+		s.logger.Warnf("failed to relay with DHT-advertised relayers: %s", err)
+		s.logger.Infof("falling back to swap counterparty as relayer")
+		return s.relayClaimWithXMRTaker()
 	}
 	return receipt, nil
 }
@@ -8,11 +8,13 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 var (
 	// various instance and swap errors
-	errUnexpectedMessageType         = errors.New("unexpected message type")
+	errUnexpectedMessageType         = fmt.Errorf("%w type", message.ErrUnexpectedMessage)
 	errMissingKeys                   = errors.New("did not receive XMRTaker's public spend or view key")
 	errMissingAddress                = errors.New("got empty contract address")
 	errNilSwapState                  = errors.New("swap state is nil")
@@ -22,7 +24,7 @@ var (
 	errSwapIDMismatch                = errors.New("hash of swap struct does not match swap ID")
 	errLockTxReverted                = errors.New("other party failed to lock ETH asset (transaction reverted)")
 	errInvalidETHLockedTransaction   = errors.New("eth locked tx was not to correct contract address")
-	errInvalidT0                     = errors.New("invalid t0 value; asset was locked too far in the past")
+	errInvalidT0                     = errors.New("invalid t0 value; asset locked too long ago or t0 too far away")
 	errInvalidT1                     = errors.New("invalid swap timeout set by counterparty")
 	errRelayedTransactionTimeout     = errors.New("relayed transaction was not included within one minute")
 	errClaimedLogInvalidContractAddr = errors.New("log was not emitted by correct contract")
@@ -31,12 +33,27 @@ var (
 	errClaimedLogWrongSwapID         = errors.New("log did not have the correct swap ID as its second topic")
 	errClaimedLogWrongSecret         = errors.New("log did not have the correct secret as its third topic")
 	errRelayingWithNonEthAsset       = errors.New("relayers with ERC20 token swaps are not currently supported")
+	errOfferExpiryNotInFuture        = errors.New("offer expiry time must be in the future")
+	errRepricingWithNonEthAsset      = errors.New("repricing is only supported for offers for ETH")
+	errExchangeRateBelowFloor        = errors.New("offer exchange rate is below the repricing floor rate")
+	errRelayClaimTooLate             = errors.New("too close to t1 to relay claim and balance too low to claim ourselves")
+	errExitWithXMRLocked             = errors.New("cannot exit swap while our XMR is locked, waiting for refund or claim")
+	errForceCompleteNotMaker         = errors.New("can only force-complete swaps where we provide XMR")
+	errSwapNotClaimed                = errors.New("swap was not claimed on-chain, refusing to complete it")
+	errNotSweeping                   = errors.New("can only retry the sweep of swaps with status SweepingXMR")
+	errNoOngoingSwap                 = errors.New("no ongoing swap with given offer ID")
+	errPayoutAddressNotOwned         = errors.New("XMR payout address is not an address of our wallet")
+	errContractNotPending            = errors.New("contract swap is not pending")
+	errTooLateToLockXMR              = errors.New("too close to t0 to lock XMR")
+	errClaimNotFinal                 = errors.New("claim is not yet buried by enough blocks to be final")
 
 	// protocol initiation errors
-	errSwapDoesNotExist          = errors.New("contract swap ID does not exist")
-	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
-	errOfferIDNotSet             = errors.New("offer ID was not set")
-	errInvalidStageForRecovery   = errors.New("cannot create ongoing swap state if stage is not XMRLocked")
+	errSwapDoesNotExist        = errors.New("contract swap ID does not exist")
+	errOfferIDNotSet           = errors.New("offer ID was not set")
+	errOfferAlreadyTaken       = errors.New("offer already taken")
+	errOfferExpired            = errors.New("offer has expired")
+	errUnknownTaker            = errors.New("offer requires takers to have completed swaps with the maker")
+	errInvalidStageForRecovery = errors.New("cannot create ongoing swap state if stage is not KeysExchanged or XMRLocked")
 )
 
 type errBalanceTooLow struct {
@@ -51,6 +68,26 @@ func (e errBalanceTooLow) Error() string {
 	)
 }
 
+type errTakeRateLimited struct {
+	limit uint
+}
+
+func (e errTakeRateLimited) Error() string {
+	return fmt.Sprintf("too many take requests from peer, limit is %d per minute", e.limit)
+}
+
+type errTakerReputationTooLow struct {
+	successRate    *apd.Decimal
+	minSuccessRate *apd.Decimal
+}
+
+func (e errTakerReputationTooLow) Error() string {
+	return fmt.Sprintf("taker's swap success rate of %s is below the offer minimum of %s",
+		e.successRate.Text('f'),
+		e.minSuccessRate.Text('f'),
+	)
+}
+
 type errAmountProvidedTooLow struct {
 	providedAmount *apd.Decimal
 	minAmount      *apd.Decimal
@@ -6,6 +6,8 @@ package xmrmaker
 import (
 	"fmt"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
@@ -98,7 +100,7 @@ type Event interface {
 }
 
 // EventETHLocked is the first expected event. It represents ETH being locked
-// on-chain.
+// on-chain. The message is nil if the swap was resumed after a restart.
 type EventETHLocked struct {
 	message *message.NotifyETHLocked
 	errCh   chan error
@@ -117,9 +119,11 @@ func newEventETHLocked(msg *message.NotifyETHLocked) *EventETHLocked {
 }
 
 // EventContractReady is the second expected event. It represents the contract being
-// ready for us to claim the ETH.
+// ready for us to claim the ETH. The transaction hash is only set if the
+// contract was set to ready, and not if t0 was reached.
 type EventContractReady struct {
-	errCh chan error
+	txHash *ethcommon.Hash
+	errCh  chan error
 }
 
 // Type ...
@@ -127,17 +131,19 @@ func (*EventContractReady) Type() EventType {
 	return EventContractReadyType
 }
 
-func newEventContractReady() *EventContractReady {
+func newEventContractReady(txHash *ethcommon.Hash) *EventContractReady {
 	return &EventContractReady{
-		errCh: make(chan error),
+		txHash: txHash,
+		errCh:  make(chan error),
 	}
 }
 
 // EventETHRefunded is an optional event. It represents the ETH being refunded back
 // to the counterparty, and thus we also must refund.
 type EventETHRefunded struct {
-	sk    *mcrypto.PrivateSpendKey
-	errCh chan error
+	sk     *mcrypto.PrivateSpendKey
+	txHash ethcommon.Hash
+	errCh  chan error
 }
 
 // Type ...
@@ -145,10 +151,11 @@ func (*EventETHRefunded) Type() EventType {
 	return EventETHRefundedType
 }
 
-func newEventETHRefunded(sk *mcrypto.PrivateSpendKey) *EventETHRefunded {
+func newEventETHRefunded(sk *mcrypto.PrivateSpendKey, txHash ethcommon.Hash) *EventETHRefunded {
 	return &EventETHRefunded{
-		sk:    sk,
-		errCh: make(chan error),
+		sk:     sk,
+		txHash: txHash,
+		errCh:  make(chan error),
 	}
 }
 
@@ -156,7 +163,12 @@ func newEventETHRefunded(sk *mcrypto.PrivateSpendKey) *EventETHRefunded {
 // for example if the remote peer closes their connection with us before sending all
 // required messages, or we decide to cancel the swap.
 type EventExit struct {
-	errCh chan error
+	// reason, if set, is recorded as the swap's failure reason
+	reason string
+	// onlyIfUnlocked skips the exit if our XMR is already locked, as the swap
+	// must then be kept so that it is resumed when swapd restarts
+	onlyIfUnlocked bool
+	errCh          chan error
 }
 
 // Type ...
@@ -185,22 +197,28 @@ func (s *swapState) handleEvent(event Event) {
 	// events are only used once, so their error channel can be closed after handling.
 	switch e := event.(type) {
 	case *EventETHLocked:
-		log.Infof("EventETHLocked")
+		s.logger.Infof("EventETHLocked")
 		defer close(e.errCh)
 
 		if s.nextExpectedEvent != EventETHLockedType {
-			e.errCh <- fmt.Errorf("nextExpectedEvent was %s, not %s", s.nextExpectedEvent, e.Type())
+			e.errCh <- fmt.Errorf("%w: nextExpectedEvent was %s, not %s",
+				message.ErrUnexpectedMessage, s.nextExpectedEvent, e.Type())
 			return
 		}
 
-		err := s.handleNotifyETHLocked(e.message)
+		var err error
+		if e.message == nil {
+			err = s.handleResumedETHLocked()
+		} else {
+			err = s.handleNotifyETHLocked(e.message)
+		}
 		if err != nil {
 			e.errCh <- fmt.Errorf("failed to handle EventETHLocked: %w", err)
 		}
 
 		// nextExpectedEvent was set in s.lockFunds()
 	case *EventContractReady:
-		log.Infof("EventContractReady")
+		s.logger.Infof("EventContractReady")
 		defer close(e.errCh)
 
 		if s.nextExpectedEvent != EventContractReadyType {
@@ -208,7 +226,7 @@ func (s *swapState) handleEvent(event Event) {
 			return
 		}
 
-		err := s.handleEventContractReady()
+		err := s.handleEventContractReady(e)
 		if err != nil {
 			e.errCh <- fmt.Errorf("failed to handle EventContractReady: %w", err)
 			return
@@ -216,10 +234,10 @@ func (s *swapState) handleEvent(event Event) {
 
 		err = s.exit()
 		if err != nil {
-			log.Warnf("failed to exit swap: %s", err)
+			s.logger.Warnf("failed to exit swap: %s", err)
 		}
 	case *EventETHRefunded:
-		log.Infof("EventETHRefunded")
+		s.logger.Infof("EventETHRefunded")
 		defer close(e.errCh)
 
 		err := s.handleEventETHRefunded(e)
@@ -230,13 +248,22 @@ func (s *swapState) handleEvent(event Event) {
 
 		err = s.exit()
 		if err != nil {
-			log.Warnf("failed to exit swap: %s", err)
+			s.logger.Warnf("failed to exit swap: %s", err)
 		}
 	case *EventExit:
 		// this can happen at any stage.
-		log.Infof("EventExit")
+		s.logger.Infof("EventExit")
 		defer close(e.errCh)
 
+		if e.onlyIfUnlocked && s.nextExpectedEvent != EventETHLockedType {
+			s.logger.Warnf("not exiting swap with XMR locked, it will be resumed when swapd restarts")
+			return
+		}
+
+		if e.reason != "" {
+			s.exitReason = e.reason
+		}
+
 		err := s.exit()
 		if err != nil {
 			e.errCh <- fmt.Errorf("failed to handle EventExit: %w", err)
@@ -246,15 +273,19 @@ func (s *swapState) handleEvent(event Event) {
 	}
 }
 
-func (s *swapState) handleEventContractReady() error {
-	log.Debug("contract ready, attempting to claim funds...")
+func (s *swapState) handleEventContractReady(e *EventContractReady) error {
+	s.logger.Debug("contract ready, attempting to claim funds...")
+	if e.txHash != nil {
+		s.setTxHash(&s.info.SetReadyTxHash, *e.txHash)
+	}
+
 	close(s.readyCh)
 	s.readyWatcher.Stop()
 
 	// contract ready, let's claim our ether
 	receipt, err := s.claimFunds()
 	if err != nil {
-		log.Warnf("failed to claim funds from contract, attempting to safely exit: %s", err)
+		s.logger.Warnf("failed to claim funds from contract, attempting to safely exit: %s", err)
 
 		// TODO: retry claim, depending on error (#162)
 		if err2 := s.exit(); err2 != nil {
@@ -264,12 +295,15 @@ func (s *swapState) handleEventContractReady() error {
 		return fmt.Errorf("failed to claim: %w", err)
 	}
 
-	log.Debugf("funds claimed, tx: %s", receipt.TxHash)
+	s.logger.Debugf("funds claimed, tx: %s", receipt.TxHash)
+	s.setTxHash(&s.info.ClaimTxHash, receipt.TxHash)
 	s.clearNextExpectedEvent(types.CompletedSuccess)
 	return nil
 }
 
 func (s *swapState) handleEventETHRefunded(e *EventETHRefunded) error {
+	s.setTxHash(&s.info.RefundTxHash, e.txHash)
+
 	// generate monero wallet, regaining control over locked funds
 	err := s.reclaimMonero(e.sk)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	"github.com/athanorlabs/atomic-swap/tests"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,6 +44,9 @@ func TestSwapState_handleEvent_EventContractReady(t *testing.T) {
 	}
 
 	require.Equal(t, types.CompletedSuccess, s.info.Status)
+	readyTxHash := tx.Hash()
+	require.Equal(t, &readyTxHash, s.info.SetReadyTxHash)
+	require.NotNil(t, s.info.ClaimTxHash)
 }
 
 func TestSwapState_handleEvent_EventETHRefunded(t *testing.T) {
@@ -73,9 +77,25 @@ func TestSwapState_handleEvent_EventETHRefunded(t *testing.T) {
 	sk, err := mcrypto.NewPrivateSpendKey(common.Reverse(secret[:]))
 	require.NoError(t, err)
 
-	event := newEventETHRefunded(sk)
+	refundTxHash := ethcommon.Hash{0x1}
+	event := newEventETHRefunded(sk, refundTxHash)
 	s.handleEvent(event)
 	err = <-event.errCh
 	require.NoError(t, err)
 	require.Equal(t, types.CompletedRefund, s.info.Status)
+	require.Equal(t, &refundTxHash, s.info.RefundTxHash)
+}
+
+func TestEventType_getStatus_validTransitions(t *testing.T) {
+	// the maker's statuses set by setNextExpectedEvent, in order, starting
+	// once keys are exchanged
+	status := types.KeysExchanged
+	require.Equal(t, status, EventETHLockedType.getStatus())
+
+	for _, event := range []EventType{EventContractReadyType} {
+		next := event.getStatus()
+		require.True(t, status.IsValidTransition(next), "%s -> %s", status, next)
+		require.Equal(t, event, nextExpectedEventFromStatus(next))
+		status = next
+	}
 }
@@ -0,0 +1,64 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"fmt"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// ForceCompleteSwap moves an ongoing swap whose ETH we already claimed
+// on-chain to the past swaps, for the case where the swap was never finalized
+// locally and is stuck as ongoing. The swap's on-chain state is checked first,
+// and a swap that was not claimed is not completed.
+func (inst *Instance) ForceCompleteSwap(offerID types.Hash) error {
+	info, err := inst.backend.SwapManager().GetOngoingSwap(offerID)
+	if err != nil {
+		return err
+	}
+
+	if info.Provides != coins.ProvidesXMR {
+		return errForceCompleteNotMaker
+	}
+
+	ethSwapInfo, err := inst.backend.RecoveryDB().GetContractSwapInfo(offerID)
+	if err != nil {
+		return fmt.Errorf("failed to get contract info for swap %s: %w", offerID, err)
+	}
+
+	claimed, err := checkIfAlreadyClaimed(inst.backend, ethSwapInfo, inst.ethConfirmations)
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		return errSwapNotClaimed
+	}
+
+	inst.swapMu.Lock()
+	s := inst.swapStates[offerID]
+	inst.swapMu.Unlock()
+
+	if s == nil {
+		if err = completeSwap(&info, inst.backend, inst.offerManager); err != nil {
+			return err
+		}
+
+		inst.takerReputations.record(&info)
+		return nil
+	}
+
+	// Stop the swap's goroutines, so it doesn't act on the completed swap. Once
+	// we completed the swap, the swap state can no longer exit, as it only
+	// closes its done channel after moving the swap to the past swaps itself.
+	s.cancel()
+	if err = completeSwap(s.info, inst.backend, inst.offerManager); err != nil {
+		return err
+	}
+
+	close(s.done)
+	return nil
+}
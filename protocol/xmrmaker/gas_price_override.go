@@ -0,0 +1,31 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"math/big"
+
+	"github.com/fatih/color"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// SetGasPriceOverride sets the gas price of the next contract transaction of
+// an ongoing swap, bypassing the maximum gas price, so that a time-critical
+// claim or refund can be mined during a gas price spike. The override is
+// cleared once it was used.
+func (inst *Instance) SetGasPriceOverride(offerID types.Hash, gasPrice *big.Int) error {
+	inst.swapMu.Lock()
+	s, has := inst.swapStates[offerID]
+	inst.swapMu.Unlock()
+	if !has {
+		return errNoOngoingSwap
+	}
+
+	s.sender.SetGasPriceOverride(gasPrice)
+	log.Warn(color.New(color.Bold).Sprintf(
+		"**gas price override of %s wei set for the next transaction of swap %s, bypassing the maximum gas price**",
+		gasPrice, offerID))
+	return nil
+}
@@ -4,8 +4,10 @@
 package xmrmaker
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/MarinX/monerorpc/wallet"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
+	"github.com/athanorlabs/atomic-swap/monero"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
@@ -25,6 +29,10 @@ var (
 	log = logging.Logger("xmrmaker")
 )
 
+// claimFinalityRetryInterval is how often we check if the Claimed log of an
+// ongoing swap is buried by enough blocks to be final.
+const claimFinalityRetryInterval = 12 * time.Second
+
 // Host contains required network functionality.
 type Host interface {
 	Advertise()
@@ -42,16 +50,78 @@ type Instance struct {
 
 	swapMu     sync.Mutex // synchronises access to swapStates
 	swapStates map[types.Hash]*swapState
+
+	rejectedTakesDB RejectedTakesDB
+	rejectedTakeCh  chan *db.RejectedTake
+
+	offerTakenListeners offerTakenListeners
+
+	offerDefaultsDB OfferDefaultsDB
+
+	takerReputations *takerReputations
+	takeRateLimiter  *takeRateLimiter
+	autoClearOffers  bool
+	repricers        *offerRepricers
+
+	moneroConfirmations uint64
+	ethConfirmations    uint64
+	maxSwapDuration     time.Duration
+	relayClaimBuffer    time.Duration
+	xmrLockDecimals     uint8
+	xmrPayoutAddr       *mcrypto.Address
 }
 
 // Config contains the configuration values for a new XMRMaker instance.
 type Config struct {
 	Backend                    backend.Backend
 	Database                   offers.Database
+	RejectedTakesDB            RejectedTakesDB // optional
+	OfferDefaultsDB            OfferDefaultsDB // optional
 	DataDir                    string
 	WalletFile, WalletPassword string
 	ExternalSender             bool
 	Network                    Host
+
+	// AutoClearOffers withdraws offers whose minimum amount exceeds our unlocked
+	// XMR balance, putting them back on the market when the balance recovers.
+	AutoClearOffers bool
+
+	// MoneroConfirmations is the number of confirmations required on the XMR
+	// lock transaction before the swap proceeds. If zero,
+	// monero.MinSpendConfirmations is used.
+	MoneroConfirmations uint64
+
+	// EthConfirmations is the number of blocks that must be mined on top of
+	// the block containing a Claimed, Ready or Refunded log before it is acted
+	// on. If zero, the log is final as soon as it is included.
+	EthConfirmations uint64
+
+	// MaxSwapDuration, if non-zero, is how long a swap can be ongoing before
+	// it is exited. If our XMR is locked, the swap still waits to be refunded
+	// or for the contract to become ready.
+	MaxSwapDuration time.Duration
+
+	// RelayClaimBuffer is the minimum time that must be left before t1 to claim
+	// using a relayer, to allow for the relaying latency. With less time left,
+	// we claim without a relayer if our balance allows it.
+	RelayClaimBuffer time.Duration
+
+	// XMRLockDecimals is the decimal place that the XMR amount we lock is
+	// rounded up to, so that swaps don't leave dust amounts. The amount is
+	// rounded up, as the taker rejects a swap where we provide less than the
+	// agreed amount. If zero, coins.NumMoneroDecimals is used, so amounts are
+	// not rounded.
+	XMRLockDecimals uint8
+
+	// MaxTakesPerMinute is the number of take requests that a single peer can
+	// make per minute. Take requests beyond it are rejected. If zero, takes
+	// are not rate limited.
+	MaxTakesPerMinute uint
+
+	// XMRPayoutAddress is the address that XMR is swept to when we reclaim it
+	// from a swap wallet. It must be the primary address or a subaddress of
+	// our wallet. If nil, the primary address is used.
+	XMRPayoutAddress *mcrypto.Address
 }
 
 // NewInstance returns a new *xmrmaker.Instance.
@@ -62,9 +132,20 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		return nil, err
 	}
 
-	if om.NumOffers() > 0 {
-		// this is blocking if the network service hasn't started yet
-		go cfg.Network.Advertise()
+	moneroConfirmations := cfg.MoneroConfirmations
+	if moneroConfirmations == 0 {
+		moneroConfirmations = monero.MinSpendConfirmations
+	}
+
+	xmrLockDecimals := cfg.XMRLockDecimals
+	if xmrLockDecimals == 0 || xmrLockDecimals > coins.NumMoneroDecimals {
+		xmrLockDecimals = coins.NumMoneroDecimals
+	}
+
+	if cfg.XMRPayoutAddress != nil {
+		if err = validatePayoutAddress(cfg.Backend, cfg.XMRPayoutAddress); err != nil {
+			return nil, err
+		}
 	}
 
 	inst := &Instance{
@@ -73,6 +154,46 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		offerManager: om,
 		swapStates:   make(map[types.Hash]*swapState),
 		net:          cfg.Network,
+
+		rejectedTakesDB: cfg.RejectedTakesDB,
+		rejectedTakeCh:  make(chan *db.RejectedTake, rejectedTakeChSize),
+
+		offerDefaultsDB: cfg.OfferDefaultsDB,
+
+		takerReputations: newTakerReputations(cfg.Backend.SwapManager()),
+		takeRateLimiter:  newTakeRateLimiter(cfg.MaxTakesPerMinute),
+		autoClearOffers:  cfg.AutoClearOffers,
+		repricers:        newOfferRepricers(),
+
+		moneroConfirmations: moneroConfirmations,
+		ethConfirmations:    cfg.EthConfirmations,
+		maxSwapDuration:     cfg.MaxSwapDuration,
+		relayClaimBuffer:    cfg.RelayClaimBuffer,
+		xmrLockDecimals:     xmrLockDecimals,
+		xmrPayoutAddr:       cfg.XMRPayoutAddress,
+	}
+
+	if inst.rejectedTakesDB != nil {
+		go inst.runRejectedTakesWriter()
+	}
+
+	if cfg.AutoClearOffers {
+		// withdraw any offers we can't fund before they are advertised
+		if err = inst.suspendUnfundedOffers(); err != nil {
+			log.Warnf("failed to check balance against offers: %s", err)
+		}
+		go inst.runOfferBalanceMonitor()
+	}
+
+	if om.NumOffers() > 0 {
+		// this is blocking if the network service hasn't started yet
+		go cfg.Network.Advertise()
+	}
+
+	go inst.runOfferExpiryMonitor()
+
+	for id, repricing := range om.RepricingOffers() {
+		inst.startRepricing(id, repricing)
 	}
 
 	err = inst.checkForOngoingSwaps()
@@ -94,6 +215,23 @@ func (inst *Instance) checkForOngoingSwaps() error {
 			continue
 		}
 
+		if s.Status == types.KeysExchanged && inst.hasContractSwapInfo(s.OfferID) {
+			// the counterparty locked their ETH asset, but we had not started
+			// locking our XMR, so the swap can be resumed or safely aborted
+			err = inst.createOngoingSwap(s)
+			if err == nil {
+				continue
+			}
+
+			log.Warnf("failed to resume swap %s before locking XMR, aborting: %s", s.OfferID, err)
+			err = inst.abortOngoingSwap(s)
+			if err != nil {
+				return fmt.Errorf("failed to abort ongoing swap: %w", err)
+			}
+
+			continue
+		}
+
 		if s.Status == types.KeysExchanged || s.Status == types.ExpectingKeys {
 			log.Infof("found ongoing swap %s in DB, aborting since no funds were locked", s.OfferID)
 
@@ -108,20 +246,27 @@ func (inst *Instance) checkForOngoingSwaps() error {
 		}
 
 		if s.Status == types.SweepingXMR {
+			// the sweep may have failed or been interrupted, so we only mark
+			// the swap as completed once the XMR is out of the swap wallet
 			log.Infof(
-				"found ongoing swap %s in DB where XMR was being swept back to the primary account, marking as completed",
+				"found ongoing swap %s in DB where XMR was being swept back to the primary account, retrying sweep",
 				s.OfferID,
 			)
-			s.Status = types.CompletedRefund
-			err = inst.backend.SwapManager().CompleteOngoingSwap(s)
+			err = inst.retrySweep(s)
 			if err != nil {
-				return fmt.Errorf("failed to mark swap as completed: %w", err)
+				log.Errorf("failed to retry sweep of swap %s, retry with `swapcli recovery retry-sweep`: %s",
+					s.OfferID, err)
 			}
 
 			continue
 		}
 
 		err = inst.createOngoingSwap(s)
+		if errors.Is(err, errClaimNotFinal) {
+			log.Infof("ongoing swap %s was claimed, waiting for the claim to be final: %s", s.OfferID, err)
+			go inst.retryOngoingSwap(s)
+			continue
+		}
 		if err != nil {
 			log.Errorf("%s", err)
 			continue
@@ -131,6 +276,28 @@ func (inst *Instance) checkForOngoingSwaps() error {
 	return nil
 }
 
+// retryOngoingSwap retries restarting an ongoing swap whose Claimed log is not
+// yet buried by enough blocks, until the claim is final and the swap gets
+// completed, or until the claim was undone by a reorg and the swap is resumed.
+func (inst *Instance) retryOngoingSwap(s *swap.Info) {
+	for {
+		if err := common.SleepWithContext(inst.backend.Ctx(), claimFinalityRetryInterval); err != nil {
+			return
+		}
+
+		err := inst.createOngoingSwap(s)
+		if errors.Is(err, errClaimNotFinal) {
+			log.Debugf("claim of ongoing swap %s is not final yet: %s", s.OfferID, err)
+			continue
+		}
+		if err != nil {
+			log.Errorf("%s", err)
+		}
+
+		return
+	}
+}
+
 func (inst *Instance) abortOngoingSwap(s *swap.Info) error {
 	// set status to aborted, delete info from recovery db
 	s.Status = types.CompletedAbort
@@ -142,6 +309,14 @@ func (inst *Instance) abortOngoingSwap(s *swap.Info) error {
 	return inst.backend.RecoveryDB().DeleteSwap(s.OfferID)
 }
 
+// hasContractSwapInfo returns true if the contract swap info of the swap is in
+// the recovery database, which is only stored once the counterparty locked
+// their ETH asset.
+func (inst *Instance) hasContractSwapInfo(offerID types.Hash) bool {
+	_, err := inst.backend.RecoveryDB().GetContractSwapInfo(offerID)
+	return err == nil
+}
+
 func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 	log.Infof("found ongoing swap %s in DB, restarting swap", s.OfferID)
 
@@ -152,7 +327,7 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		return inst.completeSwap(s, skA)
 	}
 
-	offer, _, err := inst.offerManager.GetOffer(s.OfferID)
+	offer, offerExtra, err := inst.offerManager.GetOffer(s.OfferID)
 	if err != nil {
 		return fmt.Errorf("failed to get offer for ongoing swap, offer ID %s: %s", s.OfferID, err)
 	}
@@ -180,6 +355,7 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		// then no relayer was set for this swap.
 		relayerInfo = &types.OfferExtra{}
 	}
+	relayerInfo.Persistent = offerExtra.Persistent
 
 	ss, err := newSwapStateFromOngoing(
 		inst.backend,
@@ -189,17 +365,34 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		ethSwapInfo,
 		s,
 		kp,
+		inst.ethConfirmations,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create new swap state for ongoing swap, offer id %s: %w", s.OfferID, err)
 	}
 
+	ss.relayClaimBuffer = inst.relayClaimBuffer
+	ss.xmrPayoutAddr = inst.payoutAddress()
+	ss.moneroConfirmations = inst.moneroConfirmations
+
 	inst.swapMu.Lock()
 	inst.swapStates[s.OfferID] = ss
 	inst.swapMu.Unlock()
+	ss.startLifecycleTimeout(inst.maxSwapDuration)
+
+	if s.Status == types.KeysExchanged {
+		ss.goroutines.Go("resumeLockFunds", ss.resumeLockFunds)
+	}
 
 	go func() {
 		<-ss.done
+		inst.takerReputations.record(ss.info)
+		if ss.info.Status == types.CompletedSuccess && ss.offerExtra.Persistent {
+			inst.renewPersistentOffer(offer, ss.offerExtra)
+		} else if ss.info.Status != types.CompletedSuccess {
+			// the offer was re-added
+			inst.startRepricing(offer.ID, ss.offerExtra.Repricing)
+		}
 		inst.swapMu.Lock()
 		defer inst.swapMu.Unlock()
 		delete(inst.swapStates, offer.ID)
@@ -215,8 +408,8 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 // us finding the counterparty's secret and claiming the XMR.
 //
 // Note: this will use the current value of `noTransferBack` (verses whatever value was
-// set when the swap was started). It will also only only recover to the primary wallet
-// address, not whatever address was used when the swap was started.
+// set when the swap was started). It will also recover to the currently configured
+// payout address, not whatever address was used when the swap was started.
 func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) error {
 	// fetch our swap private spend key
 	skB, err := inst.backend.RecoveryDB().GetSwapPrivateKey(s.OfferID)
@@ -248,8 +441,8 @@ func (inst *Instance) completeSwap(s *swap.Info, skA *mcrypto.PrivateSpendKey) e
 		s,
 		inst.backend.XMRClient(),
 		kpAB,
-		inst.backend.XMRClient().PrimaryAddress(),
-		false, // always sweep back to our primary address
+		inst.payoutAddress(),
+		false, // always sweep back to our payout address
 		inst.backend.SwapManager(),
 	)
 	if err != nil {
@@ -43,6 +43,10 @@ var (
 type mockNet struct {
 	msgMu sync.Mutex     // lock needed, as SendSwapMessage is called async from timeout handlers
 	msg   common.Message // last value passed to SendSwapMessage
+
+	// relayerFees holds the fees returned by QueryRelayerFee, which fails for
+	// relayers that are not in the map
+	relayerFees map[peer.ID]*coins.WeiAmount
 }
 
 func (n *mockNet) LastSentMessage() common.Message {
@@ -62,6 +66,14 @@ func (n *mockNet) DiscoverRelayers() ([]peer.ID, error) {
 	return nil, nil
 }
 
+func (n *mockNet) QueryRelayerFee(relayerID peer.ID) (*coins.WeiAmount, error) {
+	fee, ok := n.relayerFees[relayerID]
+	if !ok {
+		return nil, errors.New("relayer did not return a fee")
+	}
+	return fee, nil
+}
+
 func (n *mockNet) SubmitClaimToRelayer(_ peer.ID, _ *message.RelayClaimRequest) (*message.RelayClaimResponse, error) {
 	return new(message.RelayClaimResponse), nil
 }
@@ -74,6 +86,7 @@ func newSwapManager(t *testing.T) pswap.Manager {
 	db := pswap.NewMockDatabase(ctrl)
 	db.EXPECT().GetAllSwaps()
 	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
+	db.EXPECT().IncrementPeerStats(gomock.Any(), gomock.Any()).AnyTimes()
 
 	sm, err := pswap.NewManager(db)
 	require.NoError(t, err)
@@ -108,6 +121,7 @@ func newBackendAndNet(t *testing.T) (backend.Backend, *mockNet) {
 	rdb.EXPECT().PutCounterpartySwapPrivateKey(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().PutSwapRelayerInfo(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().PutCounterpartySwapKeys(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	rdb.EXPECT().PutCounterpartyDLEqProof(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().DeleteSwap(gomock.Any()).Return(nil).AnyTimes()
 
 	extendedEC, err := extethclient.NewEthClient(ctx, env, common.DefaultEthEndpoint, pk)
@@ -137,6 +151,7 @@ func newTestInstanceAndDBAndNet(t *testing.T) (*Instance, *offers.MockDatabase,
 	defer ctrl.Finish()
 	db := offers.NewMockDatabase(ctrl)
 	db.EXPECT().GetAllOffers()
+	db.EXPECT().GetAllOfferExtras()
 	db.EXPECT().DeleteOffer(gomock.Any()).Return(nil).AnyTimes()
 
 	host := NewMockP2pHost(ctrl)
@@ -184,7 +199,7 @@ func TestInstance_createOngoingSwap(t *testing.T) {
 	offer := types.NewOffer(coins.ProvidesXMR, one, one, rate, types.EthAssetETH)
 
 	offerDB.EXPECT().PutOffer(offer).Return(nil)
-	_, err = inst.offerManager.AddOffer(offer, false)
+	_, err = inst.offerManager.AddOffer(offer, false, nil, nil, false, nil)
 	require.NoError(t, err)
 
 	s := &pswap.Info{
@@ -224,6 +239,106 @@ func TestInstance_createOngoingSwap(t *testing.T) {
 	close(inst.swapStates[s.OfferID].done)
 }
 
+// newOngoingETHLockedSwap adds an ongoing swap to the instance's recovery DB
+// mock in which the taker locked ETH, with the given claim key, but we have not
+// locked XMR. It returns the swap's info and its status channel.
+func newOngoingETHLockedSwap(
+	t *testing.T,
+	inst *Instance,
+	offerDB *offers.MockDatabase,
+	ours *pcommon.KeysAndProof,
+	claimKey types.Hash,
+) (*pswap.Info, chan types.Status) {
+	rdb := inst.backend.RecoveryDB().(*backend.MockRecoveryDB)
+
+	theirs, err := pcommon.GenerateKeysAndProof()
+	require.NoError(t, err)
+
+	expectedAmount := coins.StrToDecimal("0.01")
+	ec := inst.backend.ETHClient()
+	contractSwap, contractSwapID, _ := newTestSwap(
+		t, ec, inst.backend.SwapCreator(), claimKey, theirs.Secp256k1PublicKey.Keccak256(),
+		coins.EtherToWei(expectedAmount).BigInt(), time.Minute*10,
+	)
+
+	one := apd.New(1, 0)
+	rate := coins.ToExchangeRate(apd.New(1, 0))
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, rate, types.EthAssetETH)
+
+	offerDB.EXPECT().PutOffer(offer).Return(nil)
+	_, err = inst.offerManager.AddOffer(offer, false, nil, nil, false, nil)
+	require.NoError(t, err)
+
+	statusCh := make(chan types.Status, 7)
+	info := pswap.NewInfo(
+		testPeerID,
+		offer.ID,
+		coins.ProvidesXMR,
+		coins.StrToDecimal("0.05"),
+		expectedAmount,
+		rate,
+		types.EthAssetETH,
+		types.KeysExchanged,
+		0,
+		statusCh,
+	)
+
+	rdb.EXPECT().GetSwapRelayerInfo(info.OfferID).Return(nil, errors.New("some error"))
+	rdb.EXPECT().GetCounterpartySwapPrivateKey(info.OfferID).Return(nil, errors.New("some error"))
+	rdb.EXPECT().GetContractSwapInfo(info.OfferID).Return(&db.EthereumSwapInfo{
+		StartNumber:     big.NewInt(1),
+		SwapCreatorAddr: inst.backend.SwapCreatorAddr(),
+		SwapID:          contractSwapID,
+		Swap:            contractSwap,
+	}, nil)
+	rdb.EXPECT().GetSwapPrivateKey(info.OfferID).Return(ours.PrivateKeyPair.SpendKey(), nil)
+	rdb.EXPECT().GetCounterpartySwapKeys(info.OfferID).Return(
+		theirs.PublicKeyPair.SpendKey(), theirs.PrivateKeyPair.ViewKey(), nil,
+	)
+	rdb.EXPECT().GetCounterpartyDLEqProof(info.OfferID).Return(&db.CounterpartyDLEqProof{
+		Proof:              theirs.DLEqProof.Proof(),
+		Secp256k1PublicKey: theirs.Secp256k1PublicKey,
+		PublicSpendKey:     theirs.PublicKeyPair.SpendKey(),
+	}, nil)
+	offerDB.EXPECT().GetOffer(info.OfferID).Return(offer, nil)
+
+	return info, statusCh
+}
+
+func TestInstance_createOngoingSwap_ethLocked(t *testing.T) {
+	inst, offerDB := newTestInstanceAndDB(t)
+
+	ours, err := pcommon.GenerateKeysAndProof()
+	require.NoError(t, err)
+	info, statusCh := newOngoingETHLockedSwap(t, inst, offerDB, ours, ours.Secp256k1PublicKey.Keccak256())
+
+	err = inst.createOngoingSwap(info)
+	require.NoError(t, err)
+
+	// the contract is checked again and our XMR is locked
+	select {
+	case status := <-statusCh:
+		require.Equal(t, types.XMRLocked, status)
+	case <-time.After(time.Minute):
+		t.Fatal("timed out waiting for XMR to be locked")
+	}
+}
+
+func TestInstance_createOngoingSwap_ethLockedBadContract(t *testing.T) {
+	inst, offerDB := newTestInstanceAndDB(t)
+
+	ours, err := pcommon.GenerateKeysAndProof()
+	require.NoError(t, err)
+	info, _ := newOngoingETHLockedSwap(t, inst, offerDB, ours, dummySwapKey)
+
+	err = inst.createOngoingSwap(info)
+	require.ErrorContains(t, err, "contract claim key is not expected")
+
+	inst.swapMu.Lock()
+	defer inst.swapMu.Unlock()
+	require.NotContains(t, inst.swapStates, info.OfferID)
+}
+
 func TestInstance_CompleteSwap(t *testing.T) {
 	monero.TestBackgroundMineBlocks(t)
 
@@ -73,6 +73,10 @@ func (s *swapState) setNextExpectedEvent(event EventType) error {
 		panic("status corresponding to event cannot be UnknownStatus")
 	}
 
+	if !s.info.Status.IsValidTransition(status) {
+		s.logger.Warnf("unexpected status transition from %s to %s", s.info.Status, status)
+	}
+
 	s.info.SetStatus(status)
 	err := s.Backend.SwapManager().WriteSwapToDB(s.info)
 	if err != nil {
@@ -91,7 +95,7 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) error {
 		return errNilContractSwapID
 	}
 
-	log.Infof("got NotifyETHLocked; address=%s contract swap ID=%s", msg.Address, msg.ContractSwapID)
+	s.logger.Infof("got NotifyETHLocked; address=%s contract swap ID=%s", msg.Address, msg.ContractSwapID)
 
 	// validate that swap ID == keccak256(swap struct)
 	if msg.ContractSwap.SwapID() != msg.ContractSwapID {
@@ -130,11 +134,12 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) error {
 		return err
 	}
 
-	log.Infof("stored ContractSwapInfo: id=%s", s.OfferID())
+	s.logger.Infof("stored ContractSwapInfo: id=%s", s.OfferID())
 
 	if err = s.checkContract(msg.TxHash); err != nil {
 		return err
 	}
+	s.setTxHash(&s.info.NewSwapTxHash, msg.TxHash)
 
 	err = s.checkAndSetTimeouts(msg.ContractSwap.Timeout0, msg.ContractSwap.Timeout1)
 	if err != nil {
@@ -143,15 +148,16 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) error {
 
 	err = s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
 	if err != nil {
+		s.exitReason = lockFundsFailedReason
 		return fmt.Errorf("failed to lock funds: %w", err)
 	}
 
-	go s.runT0ExpirationHandler()
+	s.goroutines.Go("runT0ExpirationHandler", s.runT0ExpirationHandler)
 	return nil
 }
 
 func (s *swapState) runT0ExpirationHandler() {
-	log.Debugf("time until t0 (%s): %vs",
+	s.logger.Debugf("time until t0 (%s): %vs",
 		s.t0.Format(common.TimeFmtSecs),
 		time.Until(s.t0).Seconds(),
 	)
@@ -171,27 +177,27 @@ func (s *swapState) runT0ExpirationHandler() {
 	case <-s.ctx.Done():
 		return
 	case <-s.readyCh:
-		log.Debugf("returning from runT0ExpirationHandler as contract was set to ready")
+		s.logger.Debugf("returning from runT0ExpirationHandler as contract was set to ready")
 		return
 	case err := <-waitCh:
 		if err != nil {
 			// TODO: Do we propagate this error? If we retry, the logic should probably be inside
 			// WaitForTimestamp. (#162)
-			log.Errorf("Failure waiting for T0 timeout: err=%s", err)
+			s.logger.Errorf("Failure waiting for T0 timeout: err=%s", err)
 			return
 		}
-		log.Debugf("reached t0, time to claim")
+		s.logger.Debugf("reached t0, time to claim")
 		s.handleT0Expired()
 	}
 }
 
 func (s *swapState) handleT0Expired() {
-	event := newEventContractReady()
+	event := newEventContractReady(nil)
 	s.eventCh <- event
 	err := <-event.errCh
 	if err != nil {
 		// TODO: this is quite bad, how should this be handled? (#162)
-		log.Errorf("failed to handle t0 expiration: %s", err)
+		s.logger.Errorf("failed to handle t0 expiration: %s", err)
 	}
 }
 
@@ -206,5 +212,15 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) error {
 		return err
 	}
 
+	// store the proof, so that it can be verified again when diagnosing the swap
+	err = s.RecoveryDB().PutCounterpartyDLEqProof(s.OfferID(), &db.CounterpartyDLEqProof{
+		Proof:              msg.DLEqProof,
+		Secp256k1PublicKey: msg.Secp256k1PublicKey,
+		PublicSpendKey:     msg.PublicSpendKey,
+	})
+	if err != nil {
+		return err
+	}
+
 	return s.setXMRTakerKeys(msg.PublicSpendKey, msg.PrivateViewKey, verifyResult.Secp256k1PublicKey)
 }
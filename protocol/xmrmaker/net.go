@@ -4,6 +4,9 @@
 package xmrmaker
 
 import (
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -21,6 +24,23 @@ func (inst *Instance) Provides() coins.ProvidesCoin {
 	return coins.ProvidesXMR
 }
 
+// xmrLockAmount returns the XMR amount to lock for the amount agreed with the
+// taker, rounded up to the configured decimal place. It is never less than the
+// agreed amount, and equal to it if the agreed amount has no more decimals.
+func (inst *Instance) xmrLockAmount(agreedAmount *apd.Decimal) (*apd.Decimal, error) {
+	lockAmount := new(apd.Decimal)
+	if err := coins.RoundUpToDecimalPlace(lockAmount, agreedAmount, inst.xmrLockDecimals); err != nil {
+		return nil, err
+	}
+
+	if lockAmount.Cmp(agreedAmount) != 0 {
+		log.Debugf("rounded XMR amount to lock from %s to %s",
+			agreedAmount.Text('f'), lockAmount.Text('f'))
+	}
+
+	return lockAmount, nil
+}
+
 func (inst *Instance) initiate(
 	takerPeerID peer.ID,
 	offer *types.Offer,
@@ -28,8 +48,10 @@ func (inst *Instance) initiate(
 	providesAmount *coins.PiconeroAmount,
 	desiredAmount coins.EthAssetAmount,
 ) (*swapState, error) {
+	// we hold swapMu, so once a take of the offer has been accepted, any other
+	// take of the same offer is rejected here
 	if inst.swapStates[offer.ID] != nil {
-		return nil, errProtocolAlreadyInProgress
+		return nil, errOfferAlreadyTaken
 	}
 
 	balance, err := inst.backend.XMRClient().GetBalance(0)
@@ -61,13 +83,24 @@ func (inst *Instance) initiate(
 		inst.offerManager,
 		providesAmount,
 		desiredAmount,
+		inst.moneroConfirmations,
 	)
 	if err != nil {
 		return nil, err
 	}
+	s.relayClaimBuffer = inst.relayClaimBuffer
+	s.xmrPayoutAddr = inst.payoutAddress()
+	s.ethConfirmations = inst.ethConfirmations
 
 	go func() {
 		<-s.done
+		inst.takerReputations.record(s.info)
+		if s.info.Status == types.CompletedSuccess && offerExtra.Persistent {
+			inst.renewPersistentOffer(offer, offerExtra)
+		} else if s.info.Status != types.CompletedSuccess {
+			// the offer was re-added
+			inst.startRepricing(offer.ID, offerExtra.Repricing)
+		}
 		inst.swapMu.Lock()
 		defer inst.swapMu.Unlock()
 		delete(inst.swapStates, offer.ID)
@@ -86,6 +119,7 @@ func (inst *Instance) initiate(
 		s.info.ProvidedAmount),
 	)
 	inst.swapStates[offer.ID] = s
+	s.startLifecycleTimeout(inst.maxSwapDuration)
 	return s, nil
 }
 
@@ -94,9 +128,31 @@ func (inst *Instance) HandleInitiateMessage(
 	takerPeerID peer.ID,
 	msg *message.SendKeysMessage,
 ) (net.SwapState, common.Message, error) {
+	// checked before taking swapMu, so that rejecting a peer that exceeds the
+	// limit doesn't hold up takes from other peers
+	if !inst.takeRateLimiter.allow(takerPeerID, time.Now()) {
+		err := errTakeRateLimited{inst.takeRateLimiter.limit}
+		log.Warnf("rejected take of offer %s from peer %s: %s", msg.OfferID, takerPeerID, err)
+		inst.recordRejectedTake(takerPeerID, msg.OfferID, err)
+		return nil, nil, err
+	}
+
 	inst.swapMu.Lock()
 	defer inst.swapMu.Unlock()
 
+	state, resp, err := inst.handleInitiateMessage(takerPeerID, msg)
+	if err != nil {
+		inst.recordRejectedTake(takerPeerID, msg.OfferID, err)
+		return nil, nil, err
+	}
+
+	return state, resp, nil
+}
+
+func (inst *Instance) handleInitiateMessage(
+	takerPeerID peer.ID,
+	msg *message.SendKeysMessage,
+) (*swapState, common.Message, error) {
 	str := color.New(color.Bold).Sprintf("**incoming take of offer %s with provided amount %s**",
 		msg.OfferID,
 		msg.ProvidedAmount,
@@ -120,6 +176,16 @@ func (inst *Instance) HandleInitiateMessage(
 		return nil, nil, err
 	}
 
+	// the offer may have expired since it was last purged
+	if offerExtra.IsExpired(time.Now()) {
+		return nil, nil, errOfferExpired
+	}
+
+	err = inst.takerReputations.check(takerPeerID, offerExtra.MinTakerReputation)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	providedAmount, err := offer.ExchangeRate.ToXMR(msg.ProvidedAmount)
 	if err != nil {
 		return nil, nil, err
@@ -133,6 +199,11 @@ func (inst *Instance) HandleInitiateMessage(
 		return nil, nil, errAmountProvidedTooHigh{msg.ProvidedAmount, offer.MaxAmount}
 	}
 
+	providedAmount, err = inst.xmrLockAmount(providedAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	providedPiconero := coins.MoneroToPiconero(providedAmount)
 
 	// check decimals if ERC20
@@ -153,9 +224,22 @@ func (inst *Instance) HandleInitiateMessage(
 	}
 
 	if err = state.handleSendKeysMessage(msg); err != nil {
+		// abort the swap, which puts the offer back up for other takers
+		if exitErr := state.Exit(); exitErr != nil {
+			log.Warnf("failed to exit swap after rejecting take of offer %s: %s", msg.OfferID, exitErr)
+		}
 		return nil, nil, err
 	}
 
+	inst.notifyOfferTaken(&types.OfferTakenEvent{
+		OfferID:        offer.ID,
+		PeerID:         takerPeerID,
+		EthAsset:       offer.EthAsset,
+		ProvidedAmount: msg.ProvidedAmount,
+		XMRAmount:      providedAmount,
+		Time:           time.Now(),
+	})
+
 	resp := state.SendKeysMessage()
 	return state, resp, nil
 }
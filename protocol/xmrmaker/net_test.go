@@ -4,8 +4,12 @@
 package xmrmaker
 
 import (
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -24,16 +28,185 @@ func TestXMRMaker_HandleInitiateMessage(t *testing.T) {
 
 	b.net.(*MockP2pHost).EXPECT().Advertise()
 
-	_, err := b.MakeOffer(offer, false)
+	_, err := b.MakeOffer(offer, false, nil, nil, false, nil)
 	require.NoError(t, err)
 
+	var taken []*types.OfferTakenEvent
+	remove := b.AddOfferTakenListener(func(event *types.OfferTakenEvent) {
+		taken = append(taken, event)
+	})
+	defer remove()
+
 	msg, _ := newTestXMRTakerSendKeysMessage(t)
 	msg.OfferID = offer.ID
 	msg.ProvidedAmount, err = offer.ExchangeRate.ToETH(offer.MinAmount)
 	require.NoError(t, err)
 
-	_, resp, err := b.HandleInitiateMessage("", msg)
+	_, resp, err := b.HandleInitiateMessage("taker", msg)
 	require.NoError(t, err)
 	require.Equal(t, message.SendKeysType, resp.Type())
 	require.NotNil(t, b.swapStates[offer.ID])
+
+	require.Len(t, taken, 1)
+	require.Equal(t, offer.ID, taken[0].OfferID)
+	require.Equal(t, peer.ID("taker"), taken[0].PeerID)
+	require.Equal(t, types.EthAssetETH, taken[0].EthAsset)
+	require.Equal(t, msg.ProvidedAmount, taken[0].ProvidedAmount)
+	require.Zero(t, taken[0].XMRAmount.Cmp(offer.MinAmount))
+}
+
+func TestXMRMaker_HandleInitiateMessage_roundsLockAmount(t *testing.T) {
+	b, db := newTestInstanceAndDB(t)
+	b.xmrLockDecimals = 4
+
+	min := coins.StrToDecimal("0.001")
+	max := coins.StrToDecimal("0.002")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.3"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+	db.EXPECT().DeleteOffer(offer.ID)
+
+	b.net.(*MockP2pHost).EXPECT().Advertise()
+
+	_, err := b.MakeOffer(offer, false, nil, nil, false, nil)
+	require.NoError(t, err)
+
+	// 0.0005 ETH at a rate of 0.3 is 0.001666666667 XMR
+	msg, _ := newTestXMRTakerSendKeysMessage(t)
+	msg.OfferID = offer.ID
+	msg.ProvidedAmount = coins.StrToDecimal("0.0005")
+
+	_, resp, err := b.HandleInitiateMessage("", msg)
+	require.NoError(t, err)
+
+	agreedAmount, err := offer.ExchangeRate.ToXMR(msg.ProvidedAmount)
+	require.NoError(t, err)
+	lockAmount := resp.(*message.SendKeysMessage).ProvidedAmount
+	require.Equal(t, "0.0017", lockAmount.Text('f'))
+	require.Positive(t, lockAmount.Cmp(agreedAmount))
+	require.Zero(t, lockAmount.Cmp(b.swapStates[offer.ID].info.ProvidedAmount))
+}
+
+func TestInstance_xmrLockAmount(t *testing.T) {
+	inst := &Instance{xmrLockDecimals: 4}
+
+	testCases := []struct {
+		agreed   string
+		expected string
+	}{
+		{"0.001666666667", "0.0017"},
+		{"0.000000000001", "0.0001"},
+		{"1.0001", "1.0001"}, // already at the rounding place
+		{"1.00010000001", "1.0002"},
+		{"2", "2"},
+	}
+
+	for _, tc := range testCases {
+		agreed := coins.StrToDecimal(tc.agreed)
+		lockAmount, err := inst.xmrLockAmount(agreed)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, lockAmount.Text('f'))
+		require.GreaterOrEqual(t, lockAmount.Cmp(agreed), 0)
+	}
+
+	// with no rounding, the agreed amount is locked precisely
+	inst.xmrLockDecimals = coins.NumMoneroDecimals
+	agreed := coins.StrToDecimal("0.123456789012")
+	lockAmount, err := inst.xmrLockAmount(agreed)
+	require.NoError(t, err)
+	require.Zero(t, lockAmount.Cmp(agreed))
+}
+
+func TestXMRMaker_HandleInitiateMessage_expiredOffer(t *testing.T) {
+	b, db := newTestInstanceAndDB(t)
+	min := coins.StrToDecimal("0.001")
+	max := coins.StrToDecimal("0.002")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+	db.EXPECT().PutOfferExtra(offer.ID, gomock.Any())
+
+	// the remaining offers are advertised again when the offer is purged
+	b.net.(*MockP2pHost).EXPECT().Advertise().AnyTimes()
+
+	expiresAt := time.Now().Add(time.Minute)
+	_, err := b.MakeOffer(offer, false, &expiresAt, nil, false, nil)
+	require.NoError(t, err)
+
+	// purge the offer as if its expiry time was reached, instead of waiting
+	// for the expiry monitor
+	b.purgeExpiredOffers(expiresAt)
+	require.Empty(t, b.GetOffers())
+
+	msg, _ := newTestXMRTakerSendKeysMessage(t)
+	msg.OfferID = offer.ID
+	msg.ProvidedAmount, err = offer.ExchangeRate.ToETH(offer.MinAmount)
+	require.NoError(t, err)
+
+	_, _, err = b.HandleInitiateMessage("", msg)
+	require.ErrorContains(t, err, "offer with given ID does not exist")
+	require.Nil(t, b.swapStates[offer.ID])
+}
+
+func TestXMRMaker_HandleInitiateMessage_concurrentTakes(t *testing.T) {
+	b, db := newTestInstanceAndDB(t)
+	min := coins.StrToDecimal("0.001")
+	max := coins.StrToDecimal("0.002")
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	offer := types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+
+	b.net.(*MockP2pHost).EXPECT().Advertise()
+
+	_, err := b.MakeOffer(offer, false, nil, nil, false, nil)
+	require.NoError(t, err)
+
+	const numTakers = 2
+	msgs := make([]*message.SendKeysMessage, numTakers)
+	for i := range msgs {
+		msgs[i], _ = newTestXMRTakerSendKeysMessage(t)
+		msgs[i].OfferID = offer.ID
+		msgs[i].ProvidedAmount, err = offer.ExchangeRate.ToETH(offer.MinAmount)
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, numTakers)
+	for i := range msgs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = b.HandleInitiateMessage("", msgs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// exactly one take succeeds, the other is rejected as the offer was
+	// removed when it was taken
+	var numSucceeded int
+	for _, err := range errs {
+		if err == nil {
+			numSucceeded++
+			continue
+		}
+		require.ErrorContains(t, err, "offer with given ID does not exist")
+	}
+	require.Equal(t, 1, numSucceeded)
+	require.NotNil(t, b.swapStates[offer.ID])
+}
+
+func TestXMRMaker_HandleInitiateMessage_rateLimited(t *testing.T) {
+	b, _ := newTestInstanceAndDB(t)
+	b.takeRateLimiter = newTakeRateLimiter(1)
+
+	msg, _ := newTestXMRTakerSendKeysMessage(t)
+	msg.OfferID = types.Hash{1}
+	msg.ProvidedAmount = coins.StrToDecimal("0.0001")
+
+	// the first take is rejected as there's no such offer, but still counts
+	_, _, err := b.HandleInitiateMessage("", msg)
+	require.ErrorContains(t, err, "offer with given ID does not exist")
+
+	_, _, err = b.HandleInitiateMessage("", msg)
+	require.ErrorIs(t, err, errTakeRateLimited{1})
 }
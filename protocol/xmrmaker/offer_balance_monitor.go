@@ -0,0 +1,94 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// offerBalanceCheckInterval is how often the unlocked XMR balance is compared
+// against our offers when automatic clearing of offers is enabled.
+const offerBalanceCheckInterval = time.Minute
+
+// runOfferBalanceMonitor periodically checks our unlocked XMR balance,
+// withdrawing any offers whose minimum amount we can no longer cover and
+// restoring them once the balance recovers.
+func (inst *Instance) runOfferBalanceMonitor() {
+	ctx := inst.backend.Ctx()
+	ticker := time.NewTicker(offerBalanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := inst.checkOfferBalances(); err != nil {
+				log.Warnf("failed to check balance against offers: %s", err)
+			}
+		}
+	}
+}
+
+// suspendUnfundedOffers withdraws the offers whose minimum amount we can't
+// cover with our unlocked XMR balance, without restoring any offers. It is run
+// at startup, before our offers are advertised.
+func (inst *Instance) suspendUnfundedOffers() error {
+	unlockedBal, err := inst.unlockedXMRBalance()
+	if err != nil {
+		return err
+	}
+
+	inst.suspendOffersAbove(unlockedBal)
+	return nil
+}
+
+func (inst *Instance) unlockedXMRBalance() (*apd.Decimal, error) {
+	balance, err := inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return coins.NewPiconeroAmount(balance.UnlockedBalance).AsMonero(), nil
+}
+
+func (inst *Instance) suspendOffersAbove(unlockedBal *apd.Decimal) {
+	// the unlocked balance needs to be strictly greater than the amount provided,
+	// as it also needs to cover chain fees.
+	suspended := inst.offerManager.SuspendOffers(func(o *types.Offer) bool {
+		return unlockedBal.Cmp(o.MinAmount) <= 0
+	})
+	for _, o := range suspended {
+		log.Infof("auto-cleared offer %s, unlocked balance of %s XMR is below offer minimum of %s XMR",
+			o.ID, unlockedBal.Text('f'), o.MinAmount.Text('f'))
+	}
+}
+
+func (inst *Instance) checkOfferBalances() error {
+	unlockedBal, err := inst.unlockedXMRBalance()
+	if err != nil {
+		return err
+	}
+
+	inst.suspendOffersAbove(unlockedBal)
+
+	restored := inst.offerManager.RestoreOffers(func(o *types.Offer) bool {
+		return unlockedBal.Cmp(o.MinAmount) > 0
+	})
+	for _, o := range restored {
+		log.Infof("restored offer %s, unlocked balance of %s XMR covers offer minimum of %s XMR",
+			o.ID, unlockedBal.Text('f'), o.MinAmount.Text('f'))
+	}
+
+	if len(restored) > 0 {
+		inst.net.Advertise()
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"path"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
+)
+
+func newTestOffer(min string) *types.Offer {
+	amount := coins.StrToDecimal(min)
+	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
+	return types.NewOffer(coins.ProvidesXMR, amount, amount, rate, types.EthAssetETH)
+}
+
+func TestNewInstance_suspendsUnfundedOffers(t *testing.T) {
+	b, _ := newBackendAndNet(t)
+	unfunded := newTestOffer("1000000")
+
+	ctrl := gomock.NewController(t)
+	db := offers.NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers().Return([]*types.Offer{unfunded}, nil)
+	db.EXPECT().GetAllOfferExtras()
+
+	// the host mock fails the test if the unfunded offer is advertised
+	inst, err := NewInstance(&Config{
+		Backend:         b,
+		DataDir:         path.Join(t.TempDir(), "xmrmaker"),
+		WalletFile:      testWallet,
+		Database:        db,
+		Network:         NewMockP2pHost(ctrl),
+		AutoClearOffers: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, inst.offerManager.NumOffers())
+	require.Equal(t, 1, inst.offerManager.NumSuspendedOffers())
+}
+
+func TestInstance_checkOfferBalances(t *testing.T) {
+	inst, db := newTestInstanceAndDB(t)
+	funded := newTestOffer("0.1")
+	unfunded := newTestOffer("1000000")
+	for _, o := range []*types.Offer{funded, unfunded} {
+		db.EXPECT().PutOffer(o)
+		_, err := inst.offerManager.AddOffer(o, false, nil, nil, false, nil)
+		require.NoError(t, err)
+	}
+
+	// the unfunded offer is withdrawn, without advertising the remaining offer
+	require.NoError(t, inst.checkOfferBalances())
+	require.Equal(t, []*types.Offer{funded}, inst.GetOffers())
+	require.Equal(t, 1, inst.offerManager.NumSuspendedOffers())
+
+	// a withdrawn offer that the balance covers is restored and advertised
+	inst.offerManager.SuspendOffers(func(*types.Offer) bool { return true })
+	require.Empty(t, inst.GetOffers())
+	inst.net.(*MockP2pHost).EXPECT().Advertise()
+	require.NoError(t, inst.checkOfferBalances())
+	require.Equal(t, []*types.Offer{funded}, inst.GetOffers())
+	require.Equal(t, 1, inst.offerManager.NumSuspendedOffers())
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"errors"
+
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+)
+
+var errOfferDefaultsDisabled = errors.New("persistence of offer defaults is not enabled")
+
+// OfferDefaultsDB contains the db functions used to persist the values of the
+// most recent offer made for each ETH asset.
+type OfferDefaultsDB interface {
+	PutOfferDefaults(env common.Environment, asset types.EthAsset, defaults *db.OfferDefaults) error
+	GetOfferDefaults(env common.Environment, asset types.EthAsset) (*db.OfferDefaults, error)
+}
+
+// storeOfferDefaults saves the offer's amounts and exchange rate as the
+// defaults for its asset. Failures are logged, as they should not prevent the
+// offer from being made.
+func (inst *Instance) storeOfferDefaults(o *types.Offer) {
+	if inst.offerDefaultsDB == nil {
+		return
+	}
+
+	defaults := &db.OfferDefaults{
+		MinAmount:    o.MinAmount,
+		MaxAmount:    o.MaxAmount,
+		ExchangeRate: o.ExchangeRate,
+	}
+
+	err := inst.offerDefaultsDB.PutOfferDefaults(inst.backend.Env(), o.EthAsset, defaults)
+	if err != nil {
+		log.Warnf("failed to store offer defaults for asset %s: %s", o.EthAsset, err)
+	}
+}
+
+// GetOfferDefaults returns the values of the most recent offer made for the
+// given asset in the current environment.
+func (inst *Instance) GetOfferDefaults(asset types.EthAsset) (*db.OfferDefaults, error) {
+	if inst.offerDefaultsDB == nil {
+		return nil, errOfferDefaultsDisabled
+	}
+
+	return inst.offerDefaultsDB.GetOfferDefaults(inst.backend.Env(), asset)
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"time"
+)
+
+// offerExpiryCheckInterval is how often offers are checked for expiry. Expired
+// offers are already hidden from peers and rejected when taken, so the interval
+// only determines how quickly they are deleted and stop being advertised.
+const offerExpiryCheckInterval = 10 * time.Second
+
+// runOfferExpiryMonitor periodically deletes expired offers, advertising the
+// remaining offers if any were deleted.
+func (inst *Instance) runOfferExpiryMonitor() {
+	ctx := inst.backend.Ctx()
+	ticker := time.NewTicker(offerExpiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			inst.purgeExpiredOffers(time.Now())
+		}
+	}
+}
+
+func (inst *Instance) purgeExpiredOffers(now time.Time) {
+	purged, err := inst.offerManager.PurgeExpiredOffers(now)
+	if err != nil {
+		log.Warnf("failed to purge expired offers: %s", err)
+	}
+
+	for _, o := range purged {
+		log.Infof("removed expired offer %s", o.ID)
+	}
+
+	if len(purged) > 0 {
+		inst.net.Advertise()
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+// offerRepriceInterval is how often the exchange rate of offers with repricing
+// is compared to the market rate. The price feeds are only updated when the
+// price moves by a set deviation or after a heartbeat of an hour or more, so
+// checking more often would rarely change the rate.
+const offerRepriceInterval = 5 * time.Minute
+
+// offerRepricers tracks the offers that have a repricing goroutine running,
+// keyed by the offer's current ID, so that no offer is repriced by two
+// goroutines.
+type offerRepricers struct {
+	mu      sync.Mutex
+	running map[types.Hash]struct{}
+}
+
+func newOfferRepricers() *offerRepricers {
+	return &offerRepricers{
+		running: make(map[types.Hash]struct{}),
+	}
+}
+
+// add returns false if the offer is already being repriced, otherwise it marks
+// the offer as being repriced.
+func (r *offerRepricers) add(id types.Hash) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, has := r.running[id]; has {
+		return false
+	}
+
+	r.running[id] = struct{}{}
+	return true
+}
+
+// rekey moves the entry of a repriced offer to the ID of its replacement.
+func (r *offerRepricers) rekey(oldID types.Hash, newID types.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, oldID)
+	r.running[newID] = struct{}{}
+}
+
+func (r *offerRepricers) remove(id types.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, id)
+}
+
+// startRepricing starts the repricing goroutine of the offer, if the offer has
+// repricing set and is not already being repriced.
+func (inst *Instance) startRepricing(id types.Hash, repricing *types.Repricing) {
+	if repricing == nil || !inst.repricers.add(id) {
+		return
+	}
+
+	go inst.runOfferRepricer(id, repricing)
+}
+
+// runOfferRepricer periodically replaces the offer with an offer at the
+// repriced market rate, until the offer is taken or deleted.
+func (inst *Instance) runOfferRepricer(id types.Hash, repricing *types.Repricing) {
+	ctx := inst.backend.Ctx()
+	ticker := time.NewTicker(offerRepriceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			inst.repricers.remove(id)
+			return
+		case <-ticker.C:
+		}
+
+		offer, _, has := inst.offerManager.LookupOffer(id)
+		if !has {
+			log.Debugf("stopped repricing offer %s, as it was taken or deleted", id)
+			inst.repricers.remove(id)
+			return
+		}
+
+		market := pcommon.MarketExchangeRate(inst.backend, offer.EthAsset)
+		if market == nil {
+			continue
+		}
+
+		repriced, err := inst.repriceOffer(offer, repricing, market)
+		if err != nil {
+			log.Warnf("failed to reprice offer %s: %s", id, err)
+			continue
+		}
+
+		if repriced != nil {
+			inst.repricers.rekey(id, repriced.ID)
+			id = repriced.ID
+		}
+	}
+}
+
+// repriceOffer replaces the offer with an offer at the repriced market rate and
+// advertises it. It returns the new offer, or nil if the offer's exchange rate
+// is already the repriced rate.
+func (inst *Instance) repriceOffer(
+	offer *types.Offer,
+	repricing *types.Repricing,
+	market *coins.ExchangeRate,
+) (*types.Offer, error) {
+	rate, err := repricing.RepricedRate(market)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate.Cmp(offer.ExchangeRate) == 0 {
+		return nil, nil
+	}
+
+	repriced, err := inst.offerManager.RepriceOffer(offer.ID, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate.Cmp(repricing.FloorRate) == 0 {
+		log.Infof("repriced offer %s as offer %s at the floor rate of %s (market rate %s)",
+			offer.ID, repriced.ID, rate, market)
+	} else {
+		log.Infof("repriced offer %s as offer %s at rate %s (market rate %s)",
+			offer.ID, repriced.ID, rate, market)
+	}
+
+	inst.net.Advertise()
+	return repriced, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestInstance_repriceOffer(t *testing.T) {
+	inst, db := newTestInstanceAndDB(t)
+	repricing := &types.Repricing{
+		Spread:    coins.StrToDecimal("2"),
+		FloorRate: coins.StrToExchangeRate("0.09"),
+	}
+
+	offer := newTestOffer("0.1") // exchange rate of 0.1
+	db.EXPECT().PutOffer(offer)
+	db.EXPECT().PutOfferExtra(offer.ID, gomock.Any())
+	_, err := inst.offerManager.AddOffer(offer, false, nil, nil, false, repricing)
+	require.NoError(t, err)
+
+	// the market moved up, so the offer is replaced at 2% above it
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().PutOfferExtra(gomock.Any(), gomock.Any())
+	inst.net.(*MockP2pHost).EXPECT().Advertise()
+	repriced, err := inst.repriceOffer(offer, repricing, coins.StrToExchangeRate("0.1"))
+	require.NoError(t, err)
+	require.Equal(t, "0.102", repriced.ExchangeRate.String())
+	require.Len(t, inst.GetOffers(), 1)
+	require.Equal(t, repriced.ID, inst.GetOffers()[0].ID)
+
+	// no change at the same market rate
+	unchanged, err := inst.repriceOffer(repriced, repricing, coins.StrToExchangeRate("0.1"))
+	require.NoError(t, err)
+	require.Nil(t, unchanged)
+
+	// the market crashed, so the offer is priced at the floor
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().PutOfferExtra(gomock.Any(), gomock.Any())
+	inst.net.(*MockP2pHost).EXPECT().Advertise()
+	floored, err := inst.repriceOffer(repriced, repricing, coins.StrToExchangeRate("0.05"))
+	require.NoError(t, err)
+	require.Equal(t, "0.09", floored.ExchangeRate.String())
+}
+
+func TestInstance_ValidateOffer_repricing(t *testing.T) {
+	inst, _ := newTestInstanceAndDB(t)
+	offer := newTestOffer("0.1") // exchange rate of 0.1
+
+	repricing := &types.Repricing{
+		Spread:    coins.StrToDecimal("-1"),
+		FloorRate: coins.StrToExchangeRate("0.2"),
+	}
+	_, err := inst.ValidateOffer(offer, false, nil, nil, repricing)
+	require.ErrorIs(t, err, errExchangeRateBelowFloor)
+
+	repricing.Spread = coins.StrToDecimal("-100")
+	_, err = inst.ValidateOffer(offer, false, nil, nil, repricing)
+	require.ErrorContains(t, err, "is not above -100%")
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"sync"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// offerTakenListeners are the listeners that are notified each time a take of
+// one of our offers is accepted. The zero value has no listeners.
+type offerTakenListeners struct {
+	mu        sync.Mutex
+	nextID    uint64
+	listeners map[uint64]func(*types.OfferTakenEvent)
+}
+
+// AddOfferTakenListener registers a listener that is notified each time a
+// take of one of our offers is accepted. The listener is called while the take
+// is being handled, so it must not block. The returned function unregisters
+// the listener. It must not be called from within the listener.
+func (inst *Instance) AddOfferTakenListener(listener func(*types.OfferTakenEvent)) (remove func()) {
+	l := &inst.offerTakenListeners
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listeners == nil {
+		l.listeners = make(map[uint64]func(*types.OfferTakenEvent))
+	}
+
+	id := l.nextID
+	l.nextID++
+	l.listeners[id] = listener
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.listeners, id)
+	}
+}
+
+// notifyOfferTaken passes a copy of the event to each listener.
+func (inst *Instance) notifyOfferTaken(event *types.OfferTakenEvent) {
+	l := &inst.offerTakenListeners
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, listener := range l.listeners {
+		eventCopy := new(types.OfferTakenEvent)
+		*eventCopy = *event
+		listener(eventCopy)
+	}
+}
@@ -14,4 +14,6 @@ type Database interface {
 	GetOffer(id types.Hash) (*types.Offer, error)
 	GetAllOffers() ([]*types.Offer, error)
 	ClearAllOffers() error
+	PutOfferExtra(id types.Hash, extra *types.OfferExtra) error
+	GetAllOfferExtras() (map[types.Hash]*types.OfferExtra, error)
 }
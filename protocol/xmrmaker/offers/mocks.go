@@ -64,6 +64,21 @@ func (mr *MockDatabaseMockRecorder) DeleteOffer(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOffer", reflect.TypeOf((*MockDatabase)(nil).DeleteOffer), arg0)
 }
 
+// GetAllOfferExtras mocks base method.
+func (m *MockDatabase) GetAllOfferExtras() (map[types.Hash]*types.OfferExtra, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllOfferExtras")
+	ret0, _ := ret[0].(map[types.Hash]*types.OfferExtra)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllOfferExtras indicates an expected call of GetAllOfferExtras.
+func (mr *MockDatabaseMockRecorder) GetAllOfferExtras() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOfferExtras", reflect.TypeOf((*MockDatabase)(nil).GetAllOfferExtras))
+}
+
 // GetAllOffers mocks base method.
 func (m *MockDatabase) GetAllOffers() ([]*types.Offer, error) {
 	m.ctrl.T.Helper()
@@ -107,3 +122,17 @@ func (mr *MockDatabaseMockRecorder) PutOffer(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOffer", reflect.TypeOf((*MockDatabase)(nil).PutOffer), arg0)
 }
+
+// PutOfferExtra mocks base method.
+func (m *MockDatabase) PutOfferExtra(arg0 types.Hash, arg1 *types.OfferExtra) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutOfferExtra", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutOfferExtra indicates an expected call of PutOfferExtra.
+func (mr *MockDatabaseMockRecorder) PutOfferExtra(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutOfferExtra", reflect.TypeOf((*MockDatabase)(nil).PutOfferExtra), arg0, arg1)
+}
@@ -7,9 +7,11 @@ package offers
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/ChainSafe/chaindb"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 
 	logging "github.com/ipfs/go-log"
@@ -25,8 +27,13 @@ var (
 
 // Manager synchronises access to the offers map.
 type Manager struct {
-	mu      sync.RWMutex // synchronises access to the offers map
-	offers  map[types.Hash]*offerWithExtra
+	mu     sync.RWMutex // synchronises access to the offers and suspended maps
+	offers map[types.Hash]*offerWithExtra
+
+	// suspended contains offers that are temporarily withdrawn from the market,
+	// but which remain in the database so they can be restored later.
+	suspended map[types.Hash]*offerWithExtra
+
 	dataDir string
 	db      Database
 }
@@ -46,12 +53,19 @@ func NewManager(dataDir string, db Database) (*Manager, error) {
 		return nil, err
 	}
 
+	savedExtras, err := db.GetAllOfferExtras()
+	if err != nil {
+		return nil, err
+	}
+
 	offers := make(map[types.Hash]*offerWithExtra)
 
 	for _, offer := range savedOffers {
-		extra := &types.OfferExtra{
-			StatusCh: make(chan types.Status, statusChSize),
+		extra, has := savedExtras[offer.ID]
+		if !has {
+			extra = new(types.OfferExtra)
 		}
+		extra.StatusCh = make(chan types.Status, statusChSize)
 
 		offers[offer.ID] = &offerWithExtra{
 			offer: offer,
@@ -62,9 +76,10 @@ func NewManager(dataDir string, db Database) (*Manager, error) {
 	}
 
 	return &Manager{
-		offers:  offers,
-		dataDir: dataDir,
-		db:      db,
+		offers:    offers,
+		suspended: make(map[types.Hash]*offerWithExtra),
+		dataDir:   dataDir,
+		db:        db,
 	}, nil
 }
 
@@ -82,10 +97,18 @@ func (m *Manager) GetOffer(id types.Hash) (*types.Offer, *types.OfferExtra, erro
 	return offer.offer, offer.extra, nil
 }
 
-// AddOffer adds a new offer to the manager and returns its OffersExtra data
+// AddOffer adds a new offer to the manager and returns its OffersExtra data. If
+// expiresAt is not nil, the offer is no longer available from that time on. If
+// minTakerRep is not nil, takes are only accepted from takers meeting it. If
+// persistent is set, the offer is renewed after a successful swap. If repricing
+// is not nil, the offer's exchange rate tracks the market rate.
 func (m *Manager) AddOffer(
 	offer *types.Offer,
 	useRelayer bool,
+	expiresAt *time.Time,
+	minTakerRep *types.MinTakerReputation,
+	persistent bool,
+	repricing *types.Repricing,
 ) (*types.OfferExtra, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -102,8 +125,19 @@ func (m *Manager) AddOffer(
 	}
 
 	extra := &types.OfferExtra{
-		StatusCh:   make(chan types.Status, statusChSize),
-		UseRelayer: useRelayer,
+		StatusCh:           make(chan types.Status, statusChSize),
+		UseRelayer:         useRelayer,
+		ExpiresAt:          expiresAt,
+		MinTakerReputation: minTakerRep,
+		Persistent:         persistent,
+		Repricing:          repricing,
+	}
+
+	if needsPersisting(extra) {
+		err = m.db.PutOfferExtra(id, extra)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	m.offers[id] = &offerWithExtra{
@@ -114,6 +148,95 @@ func (m *Manager) AddOffer(
 	return extra, nil
 }
 
+// needsPersisting returns true if the extra data differs from that of an offer
+// restored from the database without any, so it must be stored with the offer.
+func needsPersisting(extra *types.OfferExtra) bool {
+	return extra.UseRelayer ||
+		extra.ExpiresAt != nil ||
+		extra.MinTakerReputation != nil ||
+		extra.Persistent ||
+		extra.Repricing != nil
+}
+
+// LookupOffer returns the offer with the given ID and its extra data, whether
+// it is current or suspended. It returns false if the offer was taken or
+// deleted.
+func (m *Manager) LookupOffer(id types.Hash) (*types.Offer, *types.OfferExtra, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	o, has := m.offers[id]
+	if !has {
+		o, has = m.suspended[id]
+	}
+	if !has {
+		return nil, nil, false
+	}
+
+	return o.offer, o.extra, true
+}
+
+// RepricingOffers returns the repricing configuration of each current or
+// suspended offer that has one, keyed by offer ID.
+func (m *Manager) RepricingOffers() map[types.Hash]*types.Repricing {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	repricing := make(map[types.Hash]*types.Repricing)
+	for _, offers := range []map[types.Hash]*offerWithExtra{m.offers, m.suspended} {
+		for id, o := range offers {
+			if o.extra.Repricing != nil {
+				repricing[id] = o.extra.Repricing
+			}
+		}
+	}
+
+	return repricing
+}
+
+// RepriceOffer replaces the current or suspended offer with the given ID by an
+// offer with the same amounts and extra data at the given exchange rate, and
+// returns the new offer. As the exchange rate is part of an offer's ID, the
+// new offer has a different ID.
+func (m *Manager) RepriceOffer(id types.Hash, exchangeRate *coins.ExchangeRate) (*types.Offer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offers := m.offers
+	o, has := offers[id]
+	if !has {
+		offers = m.suspended
+		o, has = offers[id]
+	}
+	if !has {
+		return nil, errOfferDoesNotExist
+	}
+
+	repriced := types.NewOffer(o.offer.Provides, o.offer.MinAmount, o.offer.MaxAmount, exchangeRate, o.offer.EthAsset)
+	if err := m.db.PutOffer(repriced); err != nil {
+		return nil, err
+	}
+
+	if needsPersisting(o.extra) {
+		if err := m.db.PutOfferExtra(repriced.ID, o.extra); err != nil {
+			return nil, err
+		}
+	}
+
+	err := m.db.DeleteOffer(id)
+	if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil, err
+	}
+
+	delete(offers, id)
+	offers[repriced.ID] = &offerWithExtra{
+		offer: repriced,
+		extra: o.extra,
+	}
+
+	return repriced, nil
+}
+
 // TakeOffer returns any offer with the matching id and removes the offer from the cache,
 // but leaves it in the database (unlike the Clear/DeleteOffer methods.)
 // Nil for both values is returned when the passed offer id is not currently managed.
@@ -130,19 +253,49 @@ func (m *Manager) TakeOffer(id types.Hash) (*types.Offer, *types.OfferExtra, err
 	return offer.offer, offer.extra, nil
 }
 
-// GetOffers returns all current offers. The returned slice is in random order and will not
-// be the same from one invocation to the next.
+// GetOffers returns all current offers that have not expired. The returned slice is in
+// random order and will not be the same from one invocation to the next.
 func (m *Manager) GetOffers() []*types.Offer {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	now := time.Now()
 	offers := make([]*types.Offer, 0, len(m.offers))
 	for _, o := range m.offers {
+		if o.extra.IsExpired(now) {
+			continue
+		}
 		offers = append(offers, o.offer)
 	}
 	return offers
 }
 
+// PurgeExpiredOffers deletes all current and suspended offers that have expired
+// at the passed time, returning the deleted offers.
+func (m *Manager) PurgeExpiredOffers(now time.Time) ([]*types.Offer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged []*types.Offer
+	for _, offers := range []map[types.Hash]*offerWithExtra{m.offers, m.suspended} {
+		for id, o := range offers {
+			if !o.extra.IsExpired(now) {
+				continue
+			}
+
+			err := m.db.DeleteOffer(id)
+			if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+				return purged, err
+			}
+
+			delete(offers, id)
+			purged = append(purged, o.offer)
+		}
+	}
+
+	return purged, nil
+}
+
 // ClearAllOffers clears all offers.
 func (m *Manager) ClearAllOffers() error {
 	m.mu.Lock()
@@ -153,6 +306,7 @@ func (m *Manager) ClearAllOffers() error {
 	}
 
 	m.offers = make(map[types.Hash]*offerWithExtra)
+	m.suspended = make(map[types.Hash]*offerWithExtra)
 	return nil
 }
 
@@ -162,6 +316,7 @@ func (m *Manager) ClearOfferIDs(ids []types.Hash) error {
 	defer m.mu.Unlock()
 	for _, id := range ids {
 		delete(m.offers, id)
+		delete(m.suspended, id)
 		err := m.db.DeleteOffer(id)
 		if err != nil && !errors.Is(chaindb.ErrKeyNotFound, err) {
 			return err
@@ -176,6 +331,7 @@ func (m *Manager) DeleteOffer(id types.Hash) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.offers, id)
+	delete(m.suspended, id)
 	err := m.db.DeleteOffer(id)
 	if err != nil && !errors.Is(chaindb.ErrKeyNotFound, err) {
 		return err
@@ -189,3 +345,52 @@ func (m *Manager) NumOffers() int {
 	defer m.mu.RUnlock()
 	return len(m.offers)
 }
+
+// SuspendOffers withdraws all current offers for which the passed function
+// returns true, returning the suspended offers. Suspended offers are not
+// returned by GetOffers and cannot be taken, but they remain in the database
+// and can be put back on the market with RestoreOffers.
+func (m *Manager) SuspendOffers(shouldSuspend func(*types.Offer) bool) []*types.Offer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var suspended []*types.Offer
+	for id, o := range m.offers {
+		if !shouldSuspend(o.offer) {
+			continue
+		}
+
+		delete(m.offers, id)
+		m.suspended[id] = o
+		suspended = append(suspended, o.offer)
+	}
+
+	return suspended
+}
+
+// RestoreOffers puts suspended offers for which the passed function returns
+// true back on the market, returning the restored offers.
+func (m *Manager) RestoreOffers(shouldRestore func(*types.Offer) bool) []*types.Offer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var restored []*types.Offer
+	for id, o := range m.suspended {
+		if !shouldRestore(o.offer) {
+			continue
+		}
+
+		delete(m.suspended, id)
+		m.offers[id] = o
+		restored = append(restored, o.offer)
+	}
+
+	return restored
+}
+
+// NumSuspendedOffers returns the current number of suspended offers.
+func (m *Manager) NumSuspendedOffers() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.suspended)
+}
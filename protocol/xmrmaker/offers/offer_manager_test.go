@@ -5,6 +5,7 @@ package offers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ChainSafe/chaindb"
 	"github.com/cockroachdb/apd/v3"
@@ -25,6 +26,7 @@ func Test_Manager(t *testing.T) {
 	db := NewMockDatabase(ctrl)
 
 	db.EXPECT().GetAllOffers()
+	db.EXPECT().GetAllOfferExtras()
 	db.EXPECT().ClearAllOffers()
 
 	infoDir := t.TempDir()
@@ -41,7 +43,7 @@ func Test_Manager(t *testing.T) {
 			types.EthAssetETH,
 		)
 		db.EXPECT().PutOffer(offer)
-		offerExtra, err := mgr.AddOffer(offer, false)
+		offerExtra, err := mgr.AddOffer(offer, false, nil, nil, false, nil)
 		require.NoError(t, err)
 		require.NotNil(t, offerExtra)
 	}
@@ -86,7 +88,7 @@ func Test_Manager_NoErrorDeletingOfferNotOnDisk(t *testing.T) {
 		coins.ToExchangeRate(coins.StrToDecimal("0.1")),
 		types.EthAssetETH,
 	)
-	offerExtra, err := mgr.AddOffer(offer, false)
+	offerExtra, err := mgr.AddOffer(offer, false, nil, nil, false, nil)
 	require.NoError(t, err)
 	require.NotNil(t, offerExtra)
 
@@ -121,3 +123,219 @@ func Test_Manager_NoErrorDeletingOfferNotOnDisk(t *testing.T) {
 	err = mgr.DeleteOffer(offer.ID)
 	require.NoError(t, err)
 }
+
+func Test_Manager_SuspendAndRestoreOffers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+	db.EXPECT().GetAllOfferExtras()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	small := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	large := types.NewOffer(coins.ProvidesXMR, apd.New(5, 0), apd.New(5, 0), coins.ToExchangeRate(one), types.EthAssetETH)
+	for _, o := range []*types.Offer{small, large} {
+		db.EXPECT().PutOffer(o)
+		_, err = mgr.AddOffer(o, false, nil, nil, false, nil)
+		require.NoError(t, err)
+	}
+
+	minAbove := func(amt *apd.Decimal) func(*types.Offer) bool {
+		return func(o *types.Offer) bool { return o.MinAmount.Cmp(amt) >= 0 }
+	}
+	minBelow := func(amt *apd.Decimal) func(*types.Offer) bool {
+		return func(o *types.Offer) bool { return o.MinAmount.Cmp(amt) < 0 }
+	}
+
+	suspended := mgr.SuspendOffers(minAbove(apd.New(2, 0)))
+	require.Len(t, suspended, 1)
+	require.Equal(t, large.ID, suspended[0].ID)
+	require.Len(t, mgr.GetOffers(), 1)
+	require.Equal(t, 1, mgr.NumSuspendedOffers())
+
+	// suspended offers cannot be taken
+	_, _, err = mgr.GetOffer(large.ID)
+	require.ErrorIs(t, err, errOfferDoesNotExist)
+
+	// balance has not recovered enough, nothing is restored
+	restored := mgr.RestoreOffers(minBelow(apd.New(3, 0)))
+	require.Empty(t, restored)
+
+	restored = mgr.RestoreOffers(minBelow(apd.New(10, 0)))
+	require.Len(t, restored, 1)
+	require.Equal(t, large.ID, restored[0].ID)
+	require.Len(t, mgr.GetOffers(), 2)
+	require.Equal(t, 0, mgr.NumSuspendedOffers())
+
+	// clearing offers also clears suspended offers
+	mgr.SuspendOffers(minAbove(apd.New(2, 0)))
+	db.EXPECT().ClearAllOffers()
+	require.NoError(t, mgr.ClearAllOffers())
+	require.Equal(t, 0, mgr.NumSuspendedOffers())
+}
+
+func Test_Manager_PurgeExpiredOffers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+	db.EXPECT().GetAllOfferExtras()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	now := time.Now()
+	expiresAt := now.Add(time.Minute)
+
+	expiring := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	db.EXPECT().PutOffer(expiring)
+	db.EXPECT().PutOfferExtra(expiring.ID, gomock.Any())
+	extra, err := mgr.AddOffer(expiring, false, &expiresAt, nil, false, nil)
+	require.NoError(t, err)
+	require.Equal(t, &expiresAt, extra.ExpiresAt)
+
+	permanent := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	db.EXPECT().PutOffer(permanent)
+	_, err = mgr.AddOffer(permanent, false, nil, nil, false, nil)
+	require.NoError(t, err)
+
+	// nothing has expired yet
+	purged, err := mgr.PurgeExpiredOffers(now)
+	require.NoError(t, err)
+	require.Empty(t, purged)
+	require.Len(t, mgr.GetOffers(), 2)
+
+	db.EXPECT().DeleteOffer(expiring.ID)
+	purged, err = mgr.PurgeExpiredOffers(expiresAt)
+	require.NoError(t, err)
+	require.Len(t, purged, 1)
+	require.Equal(t, expiring.ID, purged[0].ID)
+
+	offers := mgr.GetOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, permanent.ID, offers[0].ID)
+	_, _, err = mgr.GetOffer(expiring.ID)
+	require.ErrorIs(t, err, errOfferDoesNotExist)
+}
+
+func Test_Manager_restoresOfferExtra(t *testing.T) {
+	dataDir := t.TempDir()
+	testDB, err := db.NewDatabase(&chaindb.Config{DataDir: dataDir})
+	require.NoError(t, err)
+
+	mgr, err := NewManager(dataDir, testDB)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	expiresAt := time.Now().Add(time.Hour)
+	expiring := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	_, err = mgr.AddOffer(expiring, true, &expiresAt, nil, false, nil)
+	require.NoError(t, err)
+
+	minTakerRep := &types.MinTakerReputation{SuccessRate: coins.StrToDecimal("0.9"), RejectUnknown: true}
+	picky := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	_, err = mgr.AddOffer(picky, false, nil, minTakerRep, false, nil)
+	require.NoError(t, err)
+
+	permanent := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	_, err = mgr.AddOffer(permanent, false, nil, nil, false, nil)
+	require.NoError(t, err)
+
+	// restart with the same database
+	require.NoError(t, testDB.Close())
+	testDB, err = db.NewDatabase(&chaindb.Config{DataDir: dataDir})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, testDB.Close()) }()
+	mgr, err = NewManager(dataDir, testDB)
+	require.NoError(t, err)
+
+	_, extra, err := mgr.GetOffer(expiring.ID)
+	require.NoError(t, err)
+	require.NotNil(t, extra.StatusCh)
+	require.True(t, extra.UseRelayer)
+	require.NotNil(t, extra.ExpiresAt)
+	require.True(t, expiresAt.Equal(*extra.ExpiresAt))
+
+	require.Nil(t, extra.MinTakerReputation)
+
+	_, extra, err = mgr.GetOffer(picky.ID)
+	require.NoError(t, err)
+	require.Nil(t, extra.ExpiresAt)
+	require.NotNil(t, extra.MinTakerReputation)
+	require.Equal(t, "0.9", extra.MinTakerReputation.SuccessRate.Text('f'))
+	require.True(t, extra.MinTakerReputation.RejectUnknown)
+
+	_, extra, err = mgr.GetOffer(permanent.ID)
+	require.NoError(t, err)
+	require.NotNil(t, extra.StatusCh)
+	require.False(t, extra.UseRelayer)
+	require.Nil(t, extra.ExpiresAt)
+	require.Nil(t, extra.MinTakerReputation)
+
+	// the extra data is deleted with the offer
+	require.NoError(t, mgr.DeleteOffer(expiring.ID))
+	require.NoError(t, mgr.DeleteOffer(picky.ID))
+	extras, err := testDB.GetAllOfferExtras()
+	require.NoError(t, err)
+	require.Empty(t, extras)
+}
+
+func Test_Manager_RepriceOffer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db := NewMockDatabase(ctrl)
+	db.EXPECT().GetAllOffers()
+	db.EXPECT().GetAllOfferExtras()
+
+	mgr, err := NewManager(t.TempDir(), db)
+	require.NoError(t, err)
+
+	one := coins.StrToDecimal("1")
+	repricing := &types.Repricing{Spread: coins.StrToDecimal("2"), FloorRate: coins.StrToExchangeRate("0.5")}
+	offer := types.NewOffer(coins.ProvidesXMR, one, one, coins.ToExchangeRate(one), types.EthAssetETH)
+	db.EXPECT().PutOffer(offer)
+	db.EXPECT().PutOfferExtra(offer.ID, gomock.Any())
+	extra, err := mgr.AddOffer(offer, false, nil, nil, false, repricing)
+	require.NoError(t, err)
+	require.Equal(t, map[types.Hash]*types.Repricing{offer.ID: repricing}, mgr.RepricingOffers())
+
+	// the repriced offer replaces the offer and keeps its extra data
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().PutOfferExtra(gomock.Any(), extra)
+	db.EXPECT().DeleteOffer(offer.ID)
+	repriced, err := mgr.RepriceOffer(offer.ID, coins.StrToExchangeRate("1.1"))
+	require.NoError(t, err)
+	require.NotEqual(t, offer.ID, repriced.ID)
+	require.Equal(t, "1.1", repriced.ExchangeRate.String())
+	require.Equal(t, offer.MaxAmount, repriced.MaxAmount)
+
+	_, _, has := mgr.LookupOffer(offer.ID)
+	require.False(t, has)
+	_, repricedExtra, has := mgr.LookupOffer(repriced.ID)
+	require.True(t, has)
+	require.Equal(t, extra, repricedExtra)
+
+	// suspended offers can be repriced and looked up
+	mgr.SuspendOffers(func(*types.Offer) bool { return true })
+	_, _, has = mgr.LookupOffer(repriced.ID)
+	require.True(t, has)
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().PutOfferExtra(gomock.Any(), extra)
+	db.EXPECT().DeleteOffer(repriced.ID)
+	repriced, err = mgr.RepriceOffer(repriced.ID, coins.StrToExchangeRate("1.2"))
+	require.NoError(t, err)
+	require.Empty(t, mgr.GetOffers())
+	require.Equal(t, 1, mgr.NumSuspendedOffers())
+
+	// taken offers can't be repriced
+	mgr.RestoreOffers(func(*types.Offer) bool { return true })
+	_, _, err = mgr.TakeOffer(repriced.ID)
+	require.NoError(t, err)
+	_, err = mgr.RepriceOffer(repriced.ID, coins.StrToExchangeRate("1.3"))
+	require.ErrorIs(t, err, errOfferDoesNotExist)
+	require.Empty(t, mgr.RepricingOffers())
+}
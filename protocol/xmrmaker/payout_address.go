@@ -0,0 +1,38 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"fmt"
+
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+)
+
+// validatePayoutAddress checks that the payout address is for our network and
+// belongs to our wallet, so reclaimed XMR can't be swept to someone else.
+func validatePayoutAddress(b backend.Backend, addr *mcrypto.Address) error {
+	if err := addr.ValidateEnv(b.Env()); err != nil {
+		return fmt.Errorf("invalid XMR payout address: %w", err)
+	}
+
+	owned, err := monero.WalletOwnsAddress(b.XMRClient(), addr)
+	if err != nil {
+		return fmt.Errorf("failed to check XMR payout address: %w", err)
+	}
+	if !owned {
+		return errPayoutAddressNotOwned
+	}
+
+	return nil
+}
+
+// payoutAddress returns the address that XMR is swept to when we reclaim it.
+func (inst *Instance) payoutAddress() *mcrypto.Address {
+	if inst.xmrPayoutAddr != nil {
+		return inst.xmrPayoutAddr
+	}
+	return inst.backend.XMRClient().PrimaryAddress()
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// renewPersistentOffer replaces a persistent offer that was successfully
+// swapped with a new offer with the same terms, so it keeps serving takers.
+// The new offer's maximum amount is clamped to our remaining XMR balance, and
+// no offer is made if the balance no longer covers the minimum amount.
+func (inst *Instance) renewPersistentOffer(taken *types.Offer, extra *types.OfferExtra) {
+	if extra.IsExpired(time.Now()) {
+		log.Infof("not renewing persistent offer %s, as it has expired", taken.ID)
+		return
+	}
+
+	balance, err := inst.backend.XMRClient().GetBalance(0)
+	if err != nil {
+		log.Warnf("failed to get balance to renew persistent offer %s: %s", taken.ID, err)
+		return
+	}
+
+	// The change of our XMR lock transaction is still locked, so the total
+	// balance is used here. If offers are automatically cleared, the renewed
+	// offer is withdrawn until the unlocked balance covers it.
+	bal := coins.NewPiconeroAmount(balance.Balance).AsMonero()
+	if bal.Cmp(taken.MinAmount) <= 0 {
+		log.Infof("not renewing persistent offer %s, balance of %s XMR is below offer minimum of %s XMR",
+			taken.ID, bal.Text('f'), taken.MinAmount.Text('f'))
+		return
+	}
+
+	maxAmount := taken.MaxAmount
+	if bal.Cmp(maxAmount) < 0 {
+		maxAmount = new(apd.Decimal).Set(bal)
+	}
+
+	offer := types.NewOffer(coins.ProvidesXMR, taken.MinAmount, maxAmount, taken.ExchangeRate, taken.EthAsset)
+	_, err = inst.offerManager.AddOffer(
+		offer,
+		extra.UseRelayer,
+		extra.ExpiresAt,
+		extra.MinTakerReputation,
+		true,
+		extra.Repricing,
+	)
+	if err != nil {
+		log.Warnf("failed to renew persistent offer %s: %s", taken.ID, err)
+		return
+	}
+
+	if inst.autoClearOffers {
+		if err = inst.suspendUnfundedOffers(); err != nil {
+			log.Warnf("failed to check balance against offers: %s", err)
+		}
+	}
+
+	inst.net.Advertise()
+	log.Infof("renewed persistent offer %s as offer %s", taken.ID, offer.ID)
+	inst.startRepricing(offer.ID, extra.Repricing)
+}
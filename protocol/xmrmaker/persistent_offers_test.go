@@ -0,0 +1,41 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestInstance_renewPersistentOffer(t *testing.T) {
+	inst, db := newTestInstanceAndDB(t)
+	extra := &types.OfferExtra{Persistent: true}
+
+	// the renewed offer has a new ID and its max is clamped to our balance
+	taken := newTestOffer("0.1")
+	taken.MaxAmount = coins.StrToDecimal("1000000")
+	db.EXPECT().PutOffer(gomock.Any())
+	db.EXPECT().PutOfferExtra(gomock.Any(), gomock.Any())
+	inst.net.(*MockP2pHost).EXPECT().Advertise()
+	inst.renewPersistentOffer(taken, extra)
+
+	offers := inst.GetOffers()
+	require.Len(t, offers, 1)
+	renewed := offers[0]
+	require.NotEqual(t, taken.ID, renewed.ID)
+	require.Equal(t, taken.MinAmount, renewed.MinAmount)
+	require.Equal(t, -1, renewed.MaxAmount.Cmp(taken.MaxAmount))
+	_, renewedExtra, err := inst.offerManager.GetOffer(renewed.ID)
+	require.NoError(t, err)
+	require.True(t, renewedExtra.Persistent)
+
+	// no offer is made once the balance doesn't cover the minimum
+	inst.renewPersistentOffer(newTestOffer("1000000"), extra)
+	require.Len(t, inst.GetOffers(), 1)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+)
+
+// rejectedTakeChSize is the number of rejected takes that can be queued for
+// writing to the database before new entries are dropped.
+const rejectedTakeChSize = 64
+
+// RejectedTakesDB contains the db functions used to persist take requests that
+// were rejected before a swap was created.
+type RejectedTakesDB interface {
+	PutRejectedTake(rt *db.RejectedTake) error
+	GetRejectedTakes() ([]*db.RejectedTake, error)
+}
+
+// recordRejectedTake queues a rejected take to be written to the database. It
+// never blocks; if the queue is full, the entry is dropped.
+func (inst *Instance) recordRejectedTake(takerPeerID peer.ID, offerID types.Hash, reason error) {
+	if inst.rejectedTakesDB == nil {
+		return
+	}
+
+	rt := &db.RejectedTake{
+		PeerID:  takerPeerID,
+		OfferID: offerID,
+		Reason:  reason.Error(),
+		Time:    time.Now(),
+	}
+
+	select {
+	case inst.rejectedTakeCh <- rt:
+	default:
+		log.Warnf("dropping rejected take of offer %s from peer %s, queue is full", offerID, takerPeerID)
+	}
+}
+
+// runRejectedTakesWriter writes queued rejected takes to the database until the
+// backend's context is cancelled.
+func (inst *Instance) runRejectedTakesWriter() {
+	ctx := inst.backend.Ctx()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rt := <-inst.rejectedTakeCh:
+			if err := inst.rejectedTakesDB.PutRejectedTake(rt); err != nil {
+				log.Warnf("failed to store rejected take of offer %s: %s", rt.OfferID, err)
+			}
+		}
+	}
+}
+
+// GetRejectedTakes returns the take requests that were rejected before a swap
+// was created, ordered from newest to oldest.
+func (inst *Instance) GetRejectedTakes() ([]*db.RejectedTake, error) {
+	if inst.rejectedTakesDB == nil {
+		return []*db.RejectedTake{}, nil
+	}
+
+	return inst.rejectedTakesDB.GetRejectedTakes()
+}
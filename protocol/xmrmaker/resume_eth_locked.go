@@ -0,0 +1,173 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"fmt"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+// resumeETHLocked prepares a swap to be resumed in which the counterparty
+// locked their ETH asset, but we had not started locking our XMR when we
+// restarted. The counterparty's keys are loaded from the recovery database and
+// the swap contract is checked again, as it was when we were notified that
+// the ETH asset was locked.
+func (s *swapState) resumeETHLocked() error {
+	sk, vk, err := s.RecoveryDB().GetCounterpartySwapKeys(s.OfferID())
+	if err != nil {
+		return fmt.Errorf("failed to get counterparty's swap keys from db: %w", err)
+	}
+
+	proof, err := s.RecoveryDB().GetCounterpartyDLEqProof(s.OfferID())
+	if err != nil {
+		return fmt.Errorf("failed to get counterparty's DLEq proof from db: %w", err)
+	}
+
+	s.xmrtakerPublicSpendKey = sk
+	s.xmrtakerPrivateViewKey = vk
+	s.xmrtakerSecp256K1PublicKey = proof.Secp256k1PublicKey
+
+	s.secp256k1Pub, err = secp256k1PublicKeyFromSecret(s.getSecret())
+	if err != nil {
+		return err
+	}
+
+	return s.checkStoredContract()
+}
+
+// checkStoredContract repeats the checks of checkContract against the swap
+// stored in the recovery database, as the transaction that created the swap is
+// not stored. It also checks that the swap is still pending and that there is
+// enough time left before t0 for our XMR lock to be confirmed.
+func (s *swapState) checkStoredContract() error {
+	_, err := contracts.CheckSwapCreatorContractCode(s.ctx, s.ETHClient().Raw(), s.swapCreatorAddr)
+	if err != nil {
+		return err
+	}
+
+	if s.contractSwap.SwapID() != s.contractSwapID {
+		return errSwapIDMismatch
+	}
+
+	stage, err := s.swapCreator.Swaps(s.ETHClient().CallOpts(s.ctx), s.contractSwapID)
+	if err != nil {
+		return err
+	}
+
+	if stage != contracts.StagePending {
+		return fmt.Errorf("%w: stage is %s", errContractNotPending, contracts.StageToString(stage))
+	}
+
+	if s.contractSwap.Claimer != s.ETHClient().Address() {
+		return fmt.Errorf("contract claimer is not expected: got %s, expected %s",
+			s.contractSwap.Claimer, s.ETHClient().Address())
+	}
+
+	skOurs := s.secp256k1Pub.Keccak256()
+	if s.contractSwap.PubKeyClaim != skOurs {
+		return fmt.Errorf("contract claim key is not expected: got 0x%x, expected 0x%x",
+			s.contractSwap.PubKeyClaim, skOurs)
+	}
+
+	skTheirs := s.xmrtakerSecp256K1PublicKey.Keccak256()
+	if s.contractSwap.PubKeyRefund != skTheirs {
+		return fmt.Errorf("contract refund key is not expected: got 0x%x, expected 0x%x",
+			s.contractSwap.PubKeyRefund, skTheirs)
+	}
+
+	if types.EthAsset(s.contractSwap.Asset) != s.info.EthAsset {
+		return fmt.Errorf("swap asset is not expected: got %v, expected %v", s.contractSwap.Asset, s.info.EthAsset)
+	}
+
+	expectedAmount, err := pcommon.GetEthAssetAmount(
+		s.ctx,
+		s.ETHClient(),
+		s.info.ExpectedAmount,
+		s.info.EthAsset,
+	)
+	if err != nil {
+		return err
+	}
+
+	if s.contractSwap.Value.Cmp(expectedAmount.BigInt()) != 0 {
+		return fmt.Errorf("swap value is not expected: got %v, expected %v",
+			s.contractSwap.Value,
+			expectedAmount.BigInt(),
+		)
+	}
+
+	// the counterparty only sets the contract to ready once our lock is
+	// confirmed, which has to happen before t0
+	if time.Until(s.t0) < common.SwapTimeoutFromEnv(s.Env())/2 {
+		return errTooLateToLockXMR
+	}
+
+	return nil
+}
+
+// resumeLockFunds locks our XMR in a swap that was prepared by
+// resumeETHLocked. If the lock fails, the swap is exited.
+func (s *swapState) resumeLockFunds() {
+	// the channels are buffered, as we stop waiting for the results if the
+	// swap's context is cancelled first
+	event := &EventETHLocked{errCh: make(chan error, 1)}
+	select {
+	case s.eventCh <- event:
+	case <-s.ctx.Done():
+		return
+	}
+
+	var err error
+	select {
+	case err = <-event.errCh:
+	case <-s.ctx.Done():
+		return
+	}
+
+	if err == nil {
+		return
+	}
+
+	s.logger.Errorf("failed to lock XMR of resumed swap %s, exiting: %s", s.OfferID(), err)
+	exit := &EventExit{errCh: make(chan error, 1)}
+	select {
+	case s.eventCh <- exit:
+	case <-s.ctx.Done():
+	}
+}
+
+// handleResumedETHLocked is the counterpart of handleNotifyETHLocked for a
+// swap resumed after a restart, whose contract was already checked.
+func (s *swapState) handleResumedETHLocked() error {
+	s.logger.Infof("resuming swap %s, locking XMR", s.OfferID())
+
+	err := s.lockFunds(coins.MoneroToPiconero(s.info.ProvidedAmount))
+	if err != nil {
+		s.exitReason = lockFundsFailedReason
+		return fmt.Errorf("failed to lock funds: %w", err)
+	}
+
+	s.goroutines.Go("runT0ExpirationHandler", s.runT0ExpirationHandler)
+	return nil
+}
+
+// secp256k1PublicKeyFromSecret returns the secp256k1 public key of the secret
+// that unlocks the swap's funds in the contract.
+func secp256k1PublicKeyFromSecret(secret [32]byte) (*secp256k1.PublicKey, error) {
+	sk, err := ethcrypto.ToECDSA(secret[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive secp256k1 key from swap secret: %w", err)
+	}
+
+	return secp256k1.NewPublicKeyFromBigInt(sk.X, sk.Y), nil
+}
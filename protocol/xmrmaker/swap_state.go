@@ -12,12 +12,14 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
 	"github.com/cockroachdb/apd/v3"
 	"github.com/ethereum/go-ethereum"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/fatih/color"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"go.uber.org/zap"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
@@ -27,6 +29,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/watcher"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
@@ -38,6 +41,15 @@ import (
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
 )
 
+const (
+	// lockFundsMaxAttempts is the number of times we try to send the XMR lock
+	// transfer before giving up.
+	lockFundsMaxAttempts = 5
+	// lockFundsRetryBackoff is how long we wait before the first retry of the
+	// XMR lock transfer, doubling after each failed attempt.
+	lockFundsRetryBackoff = 2 * time.Second
+)
+
 var (
 	readyTopic    = common.GetTopic(common.ReadyEventSignature)
 	claimedTopic  = common.GetTopic(common.ClaimedEventSignature)
@@ -75,10 +87,21 @@ type swapState struct {
 	xmrtakerSecp256K1PublicKey *secp256k1.PublicKey
 	moneroStartHeight          uint64 // height of the monero blockchain when the swap is started
 
+	// number of confirmations required on our XMR lock transaction
+	moneroConfirmations uint64
+	// number of blocks that must be mined on top of a contract log before we
+	// act on it
+	ethConfirmations uint64
+
+	// logs under the swap's correlation ID, with its offer ID and
+	// counterparty as fields
+	logger *zap.SugaredLogger
+
 	// tracks the state of the swap
 	nextExpectedEvent EventType
 
-	readyWatcher *watcher.EventFilter
+	readyWatcher    *watcher.EventFilter
+	refundedWatcher *watcher.EventFilter
 
 	// channels
 
@@ -93,6 +116,19 @@ type swapState struct {
 	readyCh chan struct{}
 	// signals to the creator xmrmaker instance that it can delete this swap
 	done chan struct{}
+
+	// tracks the per-swap goroutines, which must exit when the swap completes
+	goroutines *pcommon.SwapGoroutines
+
+	// set if the swap is being exited for a reason that should be recorded
+	// when it doesn't complete successfully
+	exitReason string
+
+	// the minimum time that must be left before t1 to relay our claim
+	relayClaimBuffer time.Duration
+
+	// the address our XMR is swept to if we reclaim it
+	xmrPayoutAddr *mcrypto.Address
 }
 
 // newSwapStateFromStart returns a new *swapState for a fresh swap.
@@ -104,6 +140,7 @@ func newSwapStateFromStart(
 	om *offers.Manager,
 	providesAmount *coins.PiconeroAmount,
 	desiredAmount coins.EthAssetAmount,
+	moneroConfirmations uint64,
 ) (*swapState, error) {
 	// at this point, we've received the counterparty's keys,
 	// and will send our own after this function returns.
@@ -124,8 +161,8 @@ func newSwapStateFromStart(
 		return nil, err
 	}
 	// reduce the scan height a little in case there is a block reorg
-	if moneroStartHeight >= monero.MinSpendConfirmations {
-		moneroStartHeight -= monero.MinSpendConfirmations
+	if moneroStartHeight >= moneroConfirmations {
+		moneroStartHeight -= moneroConfirmations
 	}
 
 	ethHeader, err := b.ETHClient().Raw().HeaderByNumber(b.Ctx(), nil)
@@ -145,6 +182,7 @@ func newSwapStateFromStart(
 		moneroStartHeight,
 		offerExtra.StatusCh,
 	)
+	info.MarketExchangeRate = pcommon.MarketExchangeRate(b, offer.EthAsset)
 
 	if err = b.SwapManager().AddSwap(info); err != nil {
 		return nil, err
@@ -163,6 +201,8 @@ func newSwapStateFromStart(
 		return nil, err
 	}
 
+	s.moneroConfirmations = moneroConfirmations
+
 	err = s.generateAndSetKeys()
 	if err != nil {
 		return nil, err
@@ -173,10 +213,13 @@ func newSwapStateFromStart(
 }
 
 // checkIfAlreadyClaimed returns true if the ETH has already been
-// claimed by us, false otherwise.
+// claimed by us, false otherwise. A Claimed log is only treated as final once
+// it is at least ethConfirmations blocks deep, so that it is not undone by a
+// reorg; if the log is not deep enough yet, errClaimNotFinal is returned.
 func checkIfAlreadyClaimed(
 	b backend.Backend,
 	ethSwapInfo *db.EthereumSwapInfo,
+	ethConfirmations uint64,
 ) (bool, error) {
 	// check if swap actually completed and we didn't realize for some reason
 	// this could happen if we restart from an ongoing swap
@@ -217,7 +260,11 @@ func checkIfAlreadyClaimed(
 
 	log.Debugf("filtered for logs from block %s to head", filterQuery.FromBlock)
 
-	var foundClaimed bool
+	head, err := b.ETHClient().Raw().BlockNumber(b.Ctx())
+	if err != nil {
+		return false, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+
 	for _, l := range logs {
 		l := l
 		if l.Topics[0] != claimedTopic {
@@ -235,11 +282,14 @@ func checkIfAlreadyClaimed(
 		}
 
 		log.Infof("found Claimed log in block %d", l.BlockNumber)
-		foundClaimed = true
-		break
+		if depth := block.Depth(head, l.BlockNumber); depth < ethConfirmations {
+			return false, fmt.Errorf("%w: log is %d blocks deep, need %d", errClaimNotFinal, depth, ethConfirmations)
+		}
+
+		return true, nil
 	}
 
-	return foundClaimed, nil
+	return false, nil
 }
 
 // completeSwap marks the swap as completed and deletes it from the db.
@@ -262,7 +312,7 @@ func completeSwap(info *swap.Info, b backend.Backend, om *offers.Manager) error
 	}
 
 	exitLog := color.New(color.Bold).Sprintf("**swap completed successfully: id=%s**", info.OfferID)
-	log.Info(exitLog)
+	pcommon.SwapLogger(log, info).Info(exitLog)
 	return nil
 }
 
@@ -276,8 +326,9 @@ func newSwapStateFromOngoing(
 	ethSwapInfo *db.EthereumSwapInfo,
 	info *pswap.Info,
 	sk *mcrypto.PrivateKeyPair,
+	ethConfirmations uint64,
 ) (*swapState, error) {
-	alreadyClaimed, err := checkIfAlreadyClaimed(b, ethSwapInfo)
+	alreadyClaimed, err := checkIfAlreadyClaimed(b, ethSwapInfo, ethConfirmations)
 	if err != nil {
 		return nil, err
 	}
@@ -293,13 +344,14 @@ func newSwapStateFromOngoing(
 		return nil, errors.New("swap was already completed successfully")
 	}
 
-	// TODO: do we want to support the case where the ETH has been locked,
-	// but we haven't locked yet?
-	if info.Status != types.XMRLocked {
+	// with the status KeysExchanged, the contract swap info is only stored
+	// once the counterparty locked their ETH asset, and we had not started
+	// locking our XMR.
+	if info.Status != types.XMRLocked && info.Status != types.KeysExchanged {
 		return nil, errInvalidStageForRecovery
 	}
 
-	log.Debugf("restarting swap from eth block number %s", ethSwapInfo.StartNumber)
+	pcommon.SwapLogger(log, info).Debugf("restarting swap from eth block number %s", ethSwapInfo.StartNumber)
 	s, err := newSwapState(
 		b, offer, offerExtra, om, ethSwapInfo.StartNumber, info.MoneroStartHeight, info,
 	)
@@ -307,6 +359,8 @@ func newSwapStateFromOngoing(
 		return nil, err
 	}
 
+	s.ethConfirmations = ethConfirmations
+
 	err = s.setContract(ethSwapInfo.SwapCreatorAddr)
 	if err != nil {
 		return nil, err
@@ -317,6 +371,14 @@ func newSwapStateFromOngoing(
 	s.pubkeys = sk.PublicKeyPair()
 	s.contractSwapID = ethSwapInfo.SwapID
 	s.contractSwap = ethSwapInfo.Swap
+
+	if info.Status == types.KeysExchanged {
+		if err = s.resumeETHLocked(); err != nil {
+			s.cancel()
+			return nil, err
+		}
+	}
+
 	return s, nil
 }
 
@@ -349,9 +411,8 @@ func newSwapState(
 	}
 
 	// set up ethereum event watchers
-	const logChSize = 16 // arbitrary, we just don't want the watcher to block on writing
-	logReadyCh := make(chan ethtypes.Log, logChSize)
-	logRefundedCh := make(chan ethtypes.Log, logChSize)
+	logReadyCh := make(chan ethtypes.Log, b.LogChSize())
+	logRefundedCh := make(chan ethtypes.Log, b.LogChSize())
 
 	// Create per swap context that is canceled when the swap completes
 	ctx, cancel := context.WithCancel(b.Ctx())
@@ -388,14 +449,15 @@ func newSwapState(
 
 	// note: if this is recovering an ongoing swap, this will only
 	// be invoked if our status is XMRLocked; ie. we've locked XMR,
-	// but not yet claimed or refunded.
-	//
-	// dleqProof and secp256k1Pub are never set, as they are only used
-	// in the swap steps before XMR is locked.
+	// but not yet claimed or refunded, or if our status is KeysExchanged
+	// and the counterparty locked their ETH asset.
 	//
-	// similarly, xmrtakerPublicKeys and xmrtakerSecp256K1PublicKey are
-	// also never set, as they're only used to check the contract
-	// before we lock XMR.
+	// dleqProof is never set, as it is only sent to the counterparty
+	// before the ETH asset is locked. When resuming with the status
+	// XMRLocked, secp256k1Pub, xmrtakerPublicKeys and
+	// xmrtakerSecp256K1PublicKey are not set either, as they're only used
+	// to check the contract before we lock XMR. See resumeETHLocked for
+	// the KeysExchanged case.
 	s := &swapState{
 		ctx:               ctx,
 		cancel:            cancel,
@@ -408,15 +470,18 @@ func newSwapState(
 		nextExpectedEvent: nextExpectedEventFromStatus(info.Status),
 		logReadyCh:        logReadyCh,
 		logRefundedCh:     logRefundedCh,
-		eventCh:           make(chan Event, 1),
+		eventCh:           make(chan Event, b.EventChSize()),
 		readyCh:           make(chan struct{}),
 		info:              info,
+		logger:            pcommon.SwapLogger(log, info),
 		done:              make(chan struct{}),
 		readyWatcher:      readyWatcher,
+		refundedWatcher:   refundedWatcher,
+		goroutines:        pcommon.NewSwapGoroutines(),
 	}
 
-	go s.runHandleEvents()
-	go s.runContractEventWatcher()
+	s.goroutines.Go("runHandleEvents", s.runHandleEvents)
+	s.goroutines.Go("runContractEventWatcher", s.runContractEventWatcher)
 	return s, nil
 }
 
@@ -453,42 +518,54 @@ func (s *swapState) Exit() error {
 
 // exit is the same as Exit, but assumes the calling code block already holds the swapState lock.
 func (s *swapState) exit() error {
-	log.Debugf("attempting to exit swap: nextExpectedEvent=%v", s.nextExpectedEvent)
+	s.logger.Debugf("attempting to exit swap: nextExpectedEvent=%v", s.nextExpectedEvent)
 
 	defer func() {
 		s.CloseProtocolStream(s.OfferID())
 
+		if s.exitReason != "" && s.info.Status != types.CompletedSuccess {
+			s.info.FailureReason = s.exitReason
+		}
+
 		err := s.SwapManager().CompleteOngoingSwap(s.info)
 		if err != nil {
-			log.Warnf("failed to mark swap %s as completed: %s", s.offer.ID, err)
+			s.logger.Warnf("failed to mark swap %s as completed: %s", s.offer.ID, err)
 			return
 		}
 
-		log.Infof("exit status %s", s.info.Status)
+		s.logger.Infof("exit status %s", s.info.Status)
 
 		if s.info.Status != types.CompletedSuccess && s.offer.IsSet() {
 			// re-add offer, as it wasn't taken successfully
-			_, err = s.offerManager.AddOffer(s.offer, s.offerExtra.UseRelayer)
+			_, err = s.offerManager.AddOffer(
+				s.offer,
+				s.offerExtra.UseRelayer,
+				s.offerExtra.ExpiresAt,
+				s.offerExtra.MinTakerReputation,
+				s.offerExtra.Persistent,
+				s.offerExtra.Repricing,
+			)
 			if err != nil {
-				log.Warnf("failed to re-add offer %s: %s", s.offer.ID, err)
+				s.logger.Warnf("failed to re-add offer %s: %s", s.offer.ID, err)
 			}
 
-			log.Debugf("re-added offer %s", s.offer.ID)
+			s.logger.Debugf("re-added offer %s", s.offer.ID)
 		} else if s.info.Status == types.CompletedSuccess {
 			err = s.offerManager.DeleteOffer(s.offer.ID)
 			if err != nil {
-				log.Warnf("failed to delete offer %s from db: %s", s.offer.ID, err)
+				s.logger.Warnf("failed to delete offer %s from db: %s", s.offer.ID, err)
 			}
 		}
 
 		err = s.Backend.RecoveryDB().DeleteSwap(s.offer.ID)
 		if err != nil {
-			log.Warnf("failed to delete temporary swap info %s from db: %s", s.offer.ID, err)
+			s.logger.Warnf("failed to delete temporary swap info %s from db: %s", s.offer.ID, err)
 		}
 
 		// Stop all per-swap goroutines
 		s.cancel()
 		close(s.done)
+		s.goroutines.VerifyExited(s.OfferID())
 
 		var exitLog string
 		switch s.info.Status {
@@ -500,7 +577,7 @@ func (s *swapState) exit() error {
 			exitLog = color.New(color.Bold).Sprintf("**swap aborted: id=%s**", s.OfferID())
 		}
 
-		log.Info(exitLog)
+		s.logger.Info(exitLog)
 	}()
 
 	switch s.nextExpectedEvent {
@@ -513,36 +590,55 @@ func (s *swapState) exit() error {
 		// this case takes control of the event channel.
 		// the next event will either be EventContractReady or EventETHRefunded.
 
-		log.Infof("waiting for EventETHRefunded or EventContractReady")
-
-		var err error
-		event := <-s.eventCh
-
-		switch e := event.(type) {
-		case *EventETHRefunded:
-			defer close(e.errCh)
-			log.Infof("got EventETHRefunded")
-			err = s.handleEventETHRefunded(e)
-		case *EventContractReady:
-			defer close(e.errCh)
-			log.Infof("got EventContractReady")
-			err = s.handleEventContractReady()
-		}
-		if err != nil {
-			return err
+		s.logger.Infof("waiting for EventETHRefunded or EventContractReady")
+
+		for {
+			event := <-s.eventCh
+
+			switch e := event.(type) {
+			case *EventETHRefunded:
+				s.logger.Infof("got EventETHRefunded")
+				err := s.handleEventETHRefunded(e)
+				close(e.errCh)
+				return err
+			case *EventContractReady:
+				s.logger.Infof("got EventContractReady")
+				err := s.handleEventContractReady(e)
+				close(e.errCh)
+				return err
+			case *EventExit:
+				// we're already exiting. our XMR is locked, so we must not
+				// return until the swap is refunded or we can claim, and the
+				// sender must not think that the swap was exited.
+				e.errCh <- errExitWithXMRLocked
+				close(e.errCh)
+			case *EventETHLocked:
+				s.logger.Warnf("got unexpected event while waiting for Refunded/Ready: %s", event.Type())
+				e.errCh <- fmt.Errorf("%w: nextExpectedEvent was %s, not %s",
+					message.ErrUnexpectedMessage, EventContractReadyType, e.Type())
+				close(e.errCh)
+			default:
+				panic("unhandled event type")
+			}
 		}
-
-		return nil
 	case EventNoneType:
 		// we already completed the swap, do nothing
 		return nil
 	default:
 		s.clearNextExpectedEvent(types.CompletedAbort)
-		log.Errorf("unexpected nextExpectedEvent in Exit: type=%s", s.nextExpectedEvent)
+		s.logger.Errorf("unexpected nextExpectedEvent in Exit: type=%s", s.nextExpectedEvent)
 		return errUnexpectedMessageType
 	}
 }
 
+// payoutAddress returns the address our XMR is swept to if we reclaim it.
+func (s *swapState) payoutAddress() *mcrypto.Address {
+	if s.xmrPayoutAddr != nil {
+		return s.xmrPayoutAddr
+	}
+	return s.XMRClient().PrimaryAddress()
+}
+
 func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) error {
 	// write counterparty swap privkey to disk in case something goes wrong
 	err := s.Backend.RecoveryDB().PutCounterpartySwapPrivateKey(s.OfferID(), skA)
@@ -568,8 +664,8 @@ func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) error {
 		s.info,
 		s.XMRClient(),
 		kpAB,
-		s.XMRClient().PrimaryAddress(),
-		false, // always sweep back to our primary address
+		s.payoutAddress(),
+		false, // always sweep back to our payout address
 		s.Backend.SwapManager(),
 	)
 }
@@ -643,16 +739,16 @@ func (s *swapState) setContract(address ethcommon.Address) error {
 func (s *swapState) lockFunds(amount *coins.PiconeroAmount) error {
 	xmrtakerPublicKeys := mcrypto.NewPublicKeyPair(s.xmrtakerPublicSpendKey, s.xmrtakerPrivateViewKey.Public())
 	swapDestAddr := mcrypto.SumSpendAndViewKeys(xmrtakerPublicKeys, s.pubkeys).Address(s.Env())
-	log.Infof("going to lock XMR funds, amount=%s XMR", amount.AsMoneroString())
+	s.logger.Infof("going to lock XMR funds, amount=%s XMR", amount.AsMoneroString())
 
 	balance, err := s.XMRClient().GetBalance(0)
 	if err != nil {
 		return err
 	}
 
-	log.Debug("total XMR balance: ", coins.FmtPiconeroAsXMR(balance.Balance))
-	log.Info("unlocked XMR balance: ", coins.FmtPiconeroAsXMR(balance.UnlockedBalance))
-	log.Infof("Starting lock of %s XMR in address %s", amount.AsMoneroString(), swapDestAddr)
+	s.logger.Debug("total XMR balance: ", coins.FmtPiconeroAsXMR(balance.Balance))
+	s.logger.Info("unlocked XMR balance: ", coins.FmtPiconeroAsXMR(balance.UnlockedBalance))
+	s.logger.Infof("Starting lock of %s XMR in address %s", amount.AsMoneroString(), swapDestAddr)
 
 	// set next expected event here, otherwise if we restart while `Transfer` is happening,
 	// we won't notice that we already locked the XMR on restart.
@@ -661,12 +757,60 @@ func (s *swapState) lockFunds(amount *coins.PiconeroAmount) error {
 		return fmt.Errorf("failed to set next expected event to EventContractReadyType: %w", err)
 	}
 
-	transfer, err := s.XMRClient().Transfer(s.ctx, swapDestAddr, 0, amount, monero.MinSpendConfirmations)
+	transfer, err := s.transferWithRetry(swapDestAddr, amount)
 	if err != nil {
 		return err
 	}
 
-	log.Infof("Successfully locked XMR funds: txID=%s address=%s block=%d",
+	s.logger.Infof("Successfully locked XMR funds: txID=%s address=%s block=%d",
 		transfer.TxID, swapDestAddr, transfer.Height)
 	return nil
 }
+
+// transferWithRetry transfers the amount to the swap address, retrying with
+// exponential backoff if the wallet rejected the transfer without sending it.
+// After any failure, the wallet's outgoing transfers are checked first, so a
+// transfer that was sent despite the error (eg. if the request timed out) is
+// never sent a second time.
+func (s *swapState) transferWithRetry(
+	to *mcrypto.Address,
+	amount *coins.PiconeroAmount,
+) (*wallet.Transfer, error) {
+	backoff := lockFundsRetryBackoff
+	for attempt := 1; ; attempt++ {
+		transfer, err := s.XMRClient().Transfer(s.ctx, to, 0, amount, s.moneroConfirmations)
+		if err == nil {
+			return transfer, nil
+		}
+
+		sent, findErr := s.XMRClient().FindTransferTo(s.ctx, to, 0, s.moneroConfirmations)
+		if findErr != nil {
+			s.logger.Warnf("Failed to check for a sent XMR lock transfer: %s", findErr)
+			return nil, err
+		}
+		if sent != nil {
+			s.logger.Infof("XMR lock transfer TXID=%s was sent despite the error: %s", sent.TxID, err)
+			return sent, nil
+		}
+
+		if !errors.Is(err, monero.ErrTransferNotSent) || attempt == lockFundsMaxAttempts {
+			return nil, err
+		}
+
+		s.logger.Warnf("Attempt %d of %d to lock XMR failed, retrying in %s: %s",
+			attempt, lockFundsMaxAttempts, backoff, err)
+		if err = common.SleepWithContext(s.ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// setTxHash sets one of the transaction hashes in the swap's info and writes
+// the info to the db, so the hash can be looked up after the swap completes.
+func (s *swapState) setTxHash(field **ethcommon.Hash, txHash ethcommon.Hash) {
+	*field = &txHash
+	if err := s.SwapManager().WriteSwapToDB(s.info); err != nil {
+		s.logger.Warnf("failed to write transaction hash of swap %s to db: %s", s.OfferID(), err)
+	}
+}
@@ -6,21 +6,27 @@ package xmrmaker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
 	"github.com/cockroachdb/apd/v3"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/xmrmaker/offers"
 	"github.com/athanorlabs/atomic-swap/tests"
 
@@ -48,6 +54,7 @@ func newTestSwapStateAndDB(t *testing.T) (*Instance, *swapState, *offers.MockDat
 		xmrmaker.offerManager,
 		coins.MoneroToPiconero(coins.StrToDecimal("0.05")),
 		desiredAmount,
+		monero.MinSpendConfirmations,
 	)
 	require.NoError(t, err)
 	return xmrmaker, swapState, db
@@ -144,6 +151,31 @@ func TestNewSwapState_generateAndSetKeys(t *testing.T) {
 	require.NotNil(t, swapState.dleqProof)
 }
 
+func TestNewSwapStateFromStart_moneroConfirmations(t *testing.T) {
+	xmrmaker, _ := newTestInstanceAndDB(t)
+	require.Equal(t, uint64(monero.MinSpendConfirmations), xmrmaker.moneroConfirmations)
+
+	const confirmations = monero.MinSpendConfirmations * 2
+	height, err := xmrmaker.backend.XMRClient().GetHeight()
+	require.NoError(t, err)
+
+	s, err := newSwapStateFromStart(
+		xmrmaker.backend,
+		testPeerID,
+		types.NewOffer("", new(apd.Decimal), new(apd.Decimal), new(coins.ExchangeRate), types.EthAssetETH),
+		&types.OfferExtra{},
+		xmrmaker.offerManager,
+		coins.MoneroToPiconero(coins.StrToDecimal("0.05")),
+		desiredAmount,
+		confirmations,
+	)
+	require.NoError(t, err)
+	require.Equal(t, uint64(confirmations), s.moneroConfirmations)
+	if height >= confirmations {
+		require.LessOrEqual(t, s.moneroStartHeight, height-confirmations)
+	}
+}
+
 func TestSwapState_ClaimFunds(t *testing.T) {
 	_, swapState := newTestSwapState(t)
 
@@ -163,6 +195,78 @@ func TestSwapState_ClaimFunds(t *testing.T) {
 	require.True(t, swapState.info.Status.IsOngoing())
 }
 
+func TestHasTimeToRelayClaim(t *testing.T) {
+	const buffer = 5 * time.Minute
+	now := time.Now()
+
+	require.True(t, hasTimeToRelayClaim(now, now.Add(buffer+time.Second), buffer))
+	require.False(t, hasTimeToRelayClaim(now, now.Add(buffer), buffer))
+	require.False(t, hasTimeToRelayClaim(now, now.Add(buffer-time.Second), buffer))
+
+	// without a buffer, we can relay up until t1
+	require.True(t, hasTimeToRelayClaim(now, now.Add(time.Second), 0))
+	require.False(t, hasTimeToRelayClaim(now, now, 0))
+}
+
+func TestSwapState_relayersByFee(t *testing.T) {
+	xmrmaker, _, net := newTestInstanceAndDBAndNet(t)
+	swapState, err := newSwapStateFromStart(
+		xmrmaker.backend,
+		testPeerID,
+		types.NewOffer("", new(apd.Decimal), new(apd.Decimal), new(coins.ExchangeRate), types.EthAssetETH),
+		&types.OfferExtra{},
+		xmrmaker.offerManager,
+		coins.MoneroToPiconero(coins.StrToDecimal("0.05")),
+		desiredAmount,
+		monero.MinSpendConfirmations,
+	)
+	require.NoError(t, err)
+	swapState.contractSwap = &contracts.SwapCreatorSwap{Value: big.NewInt(2e16)} // 0.02 ETH
+
+	cheap, legacy, expensive, tooExpensive := peer.ID("cheap"), peer.ID("legacy"),
+		peer.ID("expensive"), peer.ID("too-expensive")
+	net.relayerFees = map[peer.ID]*coins.WeiAmount{
+		testPeerID:   coins.NewWeiAmount(big.NewInt(1e15)),
+		cheap:        coins.NewWeiAmount(big.NewInt(5e15)),
+		expensive:    coins.NewWeiAmount(big.NewInt(1e16)),
+		tooExpensive: coins.NewWeiAmount(big.NewInt(2e16)),
+	}
+
+	relayers := swapState.relayersByFee([]peer.ID{tooExpensive, expensive, testPeerID, legacy, cheap})
+	require.Len(t, relayers, 3)
+
+	// the counterparty and relayers charging the swap value are skipped, and
+	// relayers that don't answer the query are assumed to charge the default
+	require.Equal(t, cheap, relayers[0].peerID)
+	require.Equal(t, legacy, relayers[1].peerID)
+	require.Equal(t, coins.RelayerFeeWei.String(), relayers[1].fee.BigInt().String())
+	require.Equal(t, expensive, relayers[2].peerID)
+}
+
+func TestSwapState_ClaimFunds_relayNearTimeout1(t *testing.T) {
+	_, swapState := newTestSwapState(t)
+	swapState.offerExtra.UseRelayer = true
+
+	claimKey := swapState.secp256k1Pub.Keccak256()
+	newSwap(t, swapState, claimKey,
+		dummySwapKey, big.NewInt(33), defaultTimeoutDuration)
+
+	// t1 is within the buffer, so we claim without a relayer, as we have the
+	// balance to do so
+	swapState.relayClaimBuffer = time.Until(swapState.t1) + time.Minute
+
+	txOpts, err := swapState.ETHClient().TxOpts(swapState.ctx)
+	require.NoError(t, err)
+	tx, err := swapState.SwapCreator().SetReady(txOpts, *swapState.contractSwap)
+	require.NoError(t, err)
+	tests.MineTransaction(t, swapState.ETHClient().Raw(), tx)
+
+	receipt, err := swapState.claimFunds()
+	require.NoError(t, err)
+	require.NotNil(t, receipt)
+	require.Equal(t, pswap.ClaimMethodSelfNearTimeout1, swapState.info.ClaimMethod)
+}
+
 func TestSwapState_handleSendKeysMessage(t *testing.T) {
 	_, s := newTestSwapState(t)
 
@@ -365,6 +469,9 @@ func TestSwapState_Exit_Reclaim(t *testing.T) {
 		coins.FmtPiconeroAsXMR(balance.Balance), balance.BlocksToUnlock)
 	require.Greater(t, balance.Balance, balAfterLock.Balance) // increased by refund (minus some fees)
 	require.Equal(t, types.CompletedRefund, s.info.Status)
+
+	// all the per-swap goroutines must exit once the swap completes
+	require.Empty(t, s.goroutines.WaitForExit(time.Second*10))
 }
 
 func TestSwapState_Exit_Aborted(t *testing.T) {
@@ -375,6 +482,25 @@ func TestSwapState_Exit_Aborted(t *testing.T) {
 	err := s.Exit()
 	require.NoError(t, err)
 	require.Equal(t, types.CompletedAbort, s.info.Status)
+	require.Empty(t, s.goroutines.WaitForExit(time.Second*10))
+}
+
+func TestSwapState_LifecycleTimeout_Aborted(t *testing.T) {
+	_, s, db := newTestSwapStateAndDB(t)
+	db.EXPECT().PutOffer(s.offer)
+
+	s.nextExpectedEvent = EventETHLockedType
+	s.startLifecycleTimeout(time.Millisecond * 100)
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second * 10):
+		t.Fatal("swap did not exit after the lifecycle timeout")
+	}
+
+	require.Equal(t, types.CompletedAbort, s.info.Status)
+	require.Equal(t, pswap.LifecycleTimeoutReason, s.info.FailureReason)
+	require.Empty(t, s.goroutines.WaitForExit(time.Second*10))
 }
 
 func TestSwapState_Exit_Aborted_1(t *testing.T) {
@@ -414,7 +540,7 @@ func TestSwapState_Exit_Refunded(t *testing.T) {
 	rate := coins.ToExchangeRate(coins.StrToDecimal("0.1"))
 	s.offer = types.NewOffer(coins.ProvidesXMR, min, max, rate, types.EthAssetETH)
 	db.EXPECT().PutOffer(s.offer)
-	_, err := b.MakeOffer(s.offer, false)
+	_, err := b.MakeOffer(s.offer, false, nil, nil, false, nil)
 	require.NoError(t, err)
 
 	s.info.SetStatus(types.CompletedRefund)
@@ -427,3 +553,110 @@ func TestSwapState_Exit_Refunded(t *testing.T) {
 	require.NotNil(t, o)
 	require.NotNil(t, oe)
 }
+
+// transferBackend is a backend whose XMR client returns the scripted transfer
+// errors, one per call, succeeding once they run out.
+type transferBackend struct {
+	backend.Backend
+	client *scriptedTransferClient
+}
+
+func (b *transferBackend) XMRClient() monero.WalletClient {
+	return b.client
+}
+
+type scriptedTransferClient struct {
+	monero.WalletClient
+	errs  []error
+	sent  *wallet.Transfer // returned by FindTransferTo
+	calls int
+}
+
+func (c *scriptedTransferClient) Transfer(
+	_ context.Context,
+	_ *mcrypto.Address,
+	_ uint64,
+	_ *coins.PiconeroAmount,
+	_ uint64,
+) (*wallet.Transfer, error) {
+	c.calls++
+	if len(c.errs) == 0 {
+		return &wallet.Transfer{TxID: "test"}, nil
+	}
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return nil, err
+}
+
+func (c *scriptedTransferClient) FindTransferTo(
+	_ context.Context,
+	_ *mcrypto.Address,
+	_ uint64,
+	_ uint64,
+) (*wallet.Transfer, error) {
+	return c.sent, nil
+}
+
+func TestSwapState_transferWithRetry(t *testing.T) {
+	notSentErr := fmt.Errorf("%w: not enough unlocked money", monero.ErrTransferNotSent)
+	receiptErr := errors.New("monero TXID=test receipt failure")
+
+	newSwapState := func(ctx context.Context, errs ...error) (*swapState, *scriptedTransferClient) {
+		client := &scriptedTransferClient{errs: errs}
+		return &swapState{
+			Backend: &transferBackend{client: client},
+			ctx:     ctx,
+			logger:  log.With(),
+		}, client
+	}
+	amount := coins.MoneroToPiconero(coins.StrToDecimal("1"))
+
+	// a transfer that the wallet rejected without sending it is retried
+	s, client := newSwapState(context.Background(), notSentErr)
+	transfer, err := s.transferWithRetry(nil, amount)
+	require.NoError(t, err)
+	require.Equal(t, "test", transfer.TxID)
+	require.Equal(t, 2, client.calls)
+
+	// a failure after the transfer was sent is not retried
+	s, client = newSwapState(context.Background(), receiptErr)
+	_, err = s.transferWithRetry(nil, amount)
+	require.ErrorIs(t, err, receiptErr)
+	require.Equal(t, 1, client.calls)
+
+	// retrying stops when the swap's context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s, client = newSwapState(ctx, notSentErr, notSentErr)
+	_, err = s.transferWithRetry(nil, amount)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, client.calls)
+}
+
+func TestSwapState_transferWithRetry_timeoutButSent(t *testing.T) {
+	timeoutErr := fmt.Errorf("transfer request: %w", context.DeadlineExceeded)
+	client := &scriptedTransferClient{
+		errs: []error{timeoutErr},
+		sent: &wallet.Transfer{TxID: "sent"},
+	}
+	s := &swapState{
+		Backend: &transferBackend{client: client},
+		ctx:     context.Background(),
+		logger:  log.With(),
+	}
+
+	// the request timed out, but the wallet sent the transfer, so we use it
+	// instead of sending another one
+	transfer, err := s.transferWithRetry(nil, coins.MoneroToPiconero(coins.StrToDecimal("1")))
+	require.NoError(t, err)
+	require.Equal(t, "sent", transfer.TxID)
+	require.Equal(t, 1, client.calls)
+
+	// if the wallet has no record of the transfer, the ambiguous error is
+	// returned without retrying, as it may still be sent
+	client = &scriptedTransferClient{errs: []error{timeoutErr}}
+	s.Backend = &transferBackend{client: client}
+	_, err = s.transferWithRetry(nil, coins.MoneroToPiconero(coins.StrToDecimal("1")))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, client.calls)
+}
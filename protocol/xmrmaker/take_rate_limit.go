@@ -0,0 +1,69 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// takeRateWindow is the period over which the take requests of each peer are
+// counted against the rate limit.
+const takeRateWindow = time.Minute
+
+// takeRateLimiter limits how many take requests each peer can make within
+// takeRateWindow, so that a single peer cannot grief us by repeatedly taking
+// our offers. A limit of zero means takes are not rate limited.
+type takeRateLimiter struct {
+	mu        sync.Mutex
+	limit     uint
+	takes     map[peer.ID][]time.Time // oldest first
+	lastPrune time.Time
+}
+
+func newTakeRateLimiter(limit uint) *takeRateLimiter {
+	return &takeRateLimiter{
+		limit: limit,
+		takes: make(map[peer.ID][]time.Time),
+	}
+}
+
+// allow returns true and counts the take request if the peer made fewer than
+// the limit of take requests within takeRateWindow before now.
+func (l *takeRateLimiter) allow(peerID peer.ID, now time.Time) bool {
+	if l.limit == 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-takeRateWindow)
+
+	// forget peers that have not made a take request within the window, so
+	// the map doesn't grow with every peer that ever took an offer
+	if now.Sub(l.lastPrune) > takeRateWindow {
+		for id, times := range l.takes {
+			if !times[len(times)-1].After(cutoff) {
+				delete(l.takes, id)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	times := l.takes[peerID]
+	for len(times) > 0 && !times[0].After(cutoff) {
+		times = times[1:]
+	}
+
+	if uint(len(times)) >= l.limit {
+		l.takes[peerID] = times
+		return false
+	}
+
+	l.takes[peerID] = append(times, now)
+	return true
+}
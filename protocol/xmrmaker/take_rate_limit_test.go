@@ -0,0 +1,41 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeRateLimiter(t *testing.T) {
+	const peerA, peerB = peer.ID("a"), peer.ID("b")
+	l := newTakeRateLimiter(2)
+	now := time.Now()
+
+	require.True(t, l.allow(peerA, now))
+	require.True(t, l.allow(peerA, now.Add(10*time.Second)))
+	require.False(t, l.allow(peerA, now.Add(20*time.Second)))
+
+	// the limit is per peer
+	require.True(t, l.allow(peerB, now.Add(20*time.Second)))
+
+	// once the first take is out of the window, another take is allowed
+	require.True(t, l.allow(peerA, now.Add(takeRateWindow+time.Second)))
+	require.False(t, l.allow(peerA, now.Add(takeRateWindow+2*time.Second)))
+
+	// peers without takes in the window are forgotten
+	l.allow(peerA, now.Add(3*takeRateWindow))
+	require.NotContains(t, l.takes, peerB)
+}
+
+func TestTakeRateLimiter_noLimit(t *testing.T) {
+	l := newTakeRateLimiter(0)
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		require.True(t, l.allow("a", now))
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"sync"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// lockFundsFailedReason is the failure reason recorded for a swap where we
+// failed to lock our XMR. Such swaps don't count against the taker's
+// reputation.
+const lockFundsFailedReason = "failed to lock XMR"
+
+// takerStats holds the counts of a taker's swap outcomes that count towards
+// their reputation.
+type takerStats struct {
+	numSucceeded int64
+	numFailed    int64
+}
+
+// takerReputations caches the reputation of the takers that completed swaps
+// with us. The swap history is only read from the database the first time a
+// reputation is needed, after which the cache is updated as swaps complete, so
+// checking a take does not read the whole history.
+type takerReputations struct {
+	mu      sync.Mutex
+	sm      pswap.Manager
+	loaded  bool
+	counted map[types.Hash]struct{} // swaps already included in stats
+	stats   map[peer.ID]*takerStats
+}
+
+func newTakerReputations(sm pswap.Manager) *takerReputations {
+	return &takerReputations{
+		sm:      sm,
+		counted: make(map[types.Hash]struct{}),
+		stats:   make(map[peer.ID]*takerStats),
+	}
+}
+
+// takerOutcome returns whether the swap counts towards the taker's reputation
+// and, if so, whether it succeeded. Only swaps where we provided XMR count,
+// and only outcomes that the taker is responsible for:
+//   - CompletedSuccess is a success.
+//   - CompletedAbort is a failure, as we only abort while waiting for the
+//     taker to lock their ETH.
+//   - CompletedRefund is a failure, as the taker refunded their ETH instead of
+//     setting the contract to ready.
+//
+// Aborts and refunds that we caused are not counted: those of swaps where we
+// failed to lock our XMR, and refunds after we started claiming, as then we
+// missed timeout1.
+func takerOutcome(info *pswap.Info) (counts bool, succeeded bool) {
+	if info.Provides != coins.ProvidesXMR {
+		return false, false
+	}
+
+	switch info.Status {
+	case types.CompletedSuccess:
+		return true, true
+	case types.CompletedAbort:
+		return info.FailureReason != lockFundsFailedReason, false
+	case types.CompletedRefund:
+		return info.FailureReason != lockFundsFailedReason && info.ClaimMethod == "", false
+	default:
+		return false, false
+	}
+}
+
+// add includes the swap in its taker's stats, unless it was already included.
+// The caller must hold mu.
+func (r *takerReputations) add(info *pswap.Info) {
+	if _, has := r.counted[info.OfferID]; has {
+		return
+	}
+
+	counts, succeeded := takerOutcome(info)
+	if !counts {
+		return
+	}
+
+	r.counted[info.OfferID] = struct{}{}
+	stats, has := r.stats[info.PeerID]
+	if !has {
+		stats = new(takerStats)
+		r.stats[info.PeerID] = stats
+	}
+
+	if succeeded {
+		stats.numSucceeded++
+	} else {
+		stats.numFailed++
+	}
+}
+
+// load reads the swap history from the database, if it was not read yet. The
+// caller must hold mu.
+func (r *takerReputations) load() error {
+	if r.loaded {
+		return nil
+	}
+
+	swaps, err := r.sm.GetPastSwaps(func(info *pswap.Info) bool {
+		return info.Provides == coins.ProvidesXMR
+	}, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range swaps {
+		r.add(info)
+	}
+
+	r.loaded = true
+	return nil
+}
+
+// record updates the taker's reputation with the outcome of a completed swap.
+// Nothing is recorded if the history was not read yet, as the swap will be
+// read along with it.
+func (r *takerReputations) record(info *pswap.Info) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loaded {
+		r.add(info)
+	}
+}
+
+// successRate returns the fraction of the taker's swaps with us that
+// succeeded, or nil if the taker has not completed any swaps with us that
+// count towards their reputation.
+func (r *takerReputations) successRate(takerPeerID peer.ID) (*apd.Decimal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	stats, has := r.stats[takerPeerID]
+	if !has {
+		return nil, nil
+	}
+
+	rate := new(apd.Decimal)
+	numSwaps := apd.New(stats.numSucceeded+stats.numFailed, 0)
+	_, err := coins.DecimalCtx().Quo(rate, apd.New(stats.numSucceeded, 0), numSwaps)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// check returns an error if the taker does not meet the offer's minimum taker
+// reputation. If minRep is nil, any taker is accepted.
+func (r *takerReputations) check(takerPeerID peer.ID, minRep *types.MinTakerReputation) error {
+	if minRep == nil {
+		return nil
+	}
+
+	rate, err := r.successRate(takerPeerID)
+	if err != nil {
+		return err
+	}
+
+	if rate == nil {
+		if minRep.RejectUnknown {
+			return errUnknownTaker
+		}
+
+		log.Debugf("accepting take from peer %s, which has no swap history with us", takerPeerID)
+		return nil
+	}
+
+	if rate.Cmp(minRep.SuccessRate) < 0 {
+		return errTakerReputationTooLow{
+			successRate:    rate,
+			minSuccessRate: minRep.SuccessRate,
+		}
+	}
+
+	return nil
+}
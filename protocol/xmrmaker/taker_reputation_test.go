@@ -0,0 +1,101 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrmaker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// newSwapManagerWithPastSwaps returns a swap manager whose database contains
+// the given past swaps, which can only be read once.
+func newSwapManagerWithPastSwaps(t *testing.T, swaps []*pswap.Info) pswap.Manager {
+	ctrl := gomock.NewController(t)
+	db := pswap.NewMockDatabase(ctrl)
+	db.EXPECT().GetAllSwaps()
+	db.EXPECT().GetSwapsByStartTime(gomock.Any(), uint(0), uint(0)).Times(1).DoAndReturn(
+		func(filter pswap.PastSwapFilter, _, _ uint) ([]*pswap.Info, error) {
+			var matching []*pswap.Info
+			for _, s := range swaps {
+				if filter(s) {
+					matching = append(matching, s)
+				}
+			}
+			return matching, nil
+		},
+	)
+
+	sm, err := pswap.NewManager(db)
+	require.NoError(t, err)
+	return sm
+}
+
+func TestTakerReputations_check(t *testing.T) {
+	otherPeerID, err := peer.Decode("12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2")
+	require.NoError(t, err)
+
+	var numSwaps byte
+	newSwap := func(status types.Status) *pswap.Info {
+		numSwaps++
+		return &pswap.Info{
+			OfferID:  types.Hash{numSwaps},
+			PeerID:   testPeerID,
+			Provides: coins.ProvidesXMR,
+			Status:   status,
+		}
+	}
+
+	// testPeerID succeeded in 3 of 4 swaps, otherPeerID has no history
+	swaps := []*pswap.Info{
+		newSwap(types.CompletedSuccess),
+		newSwap(types.CompletedAbort),
+		newSwap(types.CompletedSuccess),
+		newSwap(types.CompletedSuccess),
+	}
+
+	// swaps where we provided ETH, or which failed because of us, don't count
+	asTaker := newSwap(types.CompletedRefund)
+	asTaker.Provides = coins.ProvidesETH
+	lockFailed := newSwap(types.CompletedRefund)
+	lockFailed.FailureReason = lockFundsFailedReason
+	missedT1 := newSwap(types.CompletedRefund)
+	missedT1.ClaimMethod = pswap.ClaimMethodRelayed
+	swaps = append(swaps, asTaker, lockFailed, missedT1)
+
+	reps := newTakerReputations(newSwapManagerWithPastSwaps(t, swaps))
+
+	rate, err := reps.successRate(testPeerID)
+	require.NoError(t, err)
+	require.Equal(t, "0.75", rate.Text('f'))
+
+	// no requirement accepts any taker
+	require.NoError(t, reps.check(testPeerID, nil))
+
+	minRep := &types.MinTakerReputation{SuccessRate: coins.StrToDecimal("0.75")}
+	require.NoError(t, reps.check(testPeerID, minRep))
+
+	minRep.SuccessRate = coins.StrToDecimal("0.8")
+	err = reps.check(testPeerID, minRep)
+	require.ErrorContains(t, err, "taker's swap success rate of 0.75 is below the offer minimum of 0.8")
+
+	// completed swaps are recorded without reading the history again, and
+	// swaps that were already counted are not counted twice
+	reps.record(newSwap(types.CompletedSuccess))
+	reps.record(swaps[0])
+	rate, err = reps.successRate(testPeerID)
+	require.NoError(t, err)
+	require.Equal(t, "0.8", rate.Text('f'))
+
+	// takers without history are neutral, unless they are rejected explicitly
+	require.NoError(t, reps.check(otherPeerID, minRep))
+	minRep.RejectUnknown = true
+	require.ErrorIs(t, reps.check(otherPeerID, minRep), errUnknownTaker)
+}
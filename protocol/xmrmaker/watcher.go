@@ -7,17 +7,28 @@ import (
 	"errors"
 
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
+// watcherFailedReason is the failure reason recorded for a swap that was
+// exited because its contract event watchers gave up.
+const watcherFailedReason = "ethereum event watcher failed"
+
 func (s *swapState) runContractEventWatcher() {
 	readyEventSent := false
 	for {
 		select {
 		case <-s.ctx.Done():
 			return
+		case err := <-s.readyWatcher.Err():
+			s.handleWatcherFailed(err)
+			return
+		case err := <-s.refundedWatcher.Err():
+			s.handleWatcherFailed(err)
+			return
 		case l := <-s.logReadyCh:
 			if readyEventSent {
 				// we already sent the ready event, ignore any Ready logs
@@ -26,24 +37,45 @@ func (s *swapState) runContractEventWatcher() {
 
 			eventSent, err := s.handleReadyLogs(&l)
 			if err != nil {
-				log.Errorf("failed to handle ready logs: %s", err)
+				s.logger.Errorf("failed to handle ready logs: %s", err)
 			}
 
 			readyEventSent = eventSent
 		case l := <-s.logRefundedCh:
 			eventSent, err := s.handleRefundLogs(&l)
 			if err != nil {
-				log.Errorf("failed to handle refund logs: %s", err)
+				s.logger.Errorf("failed to handle refund logs: %s", err)
 			}
 
 			if eventSent {
-				log.Debugf("EventETHRefunded sent, returning from event watcher")
+				s.logger.Debugf("EventETHRefunded sent, returning from event watcher")
 				return
 			}
 		}
 	}
 }
 
+// handleWatcherFailed is called after one of the swap's contract event
+// watchers gave up, which only happens if the ethereum client was closed. While
+// our XMR is unlocked, the swap is aborted. Once it is locked, the swap is left
+// as it is, so that it is resumed with new watchers when swapd restarts, as
+// exiting would leave no one watching for the taker's refund.
+func (s *swapState) handleWatcherFailed(err error) {
+	s.logger.Errorf("contract event watcher of swap %s failed: %s", s.OfferID(), err)
+
+	event := &EventExit{
+		reason:         watcherFailedReason,
+		onlyIfUnlocked: true,
+		// buffered, as the exit may outlast the swap's context
+		errCh: make(chan error, 1),
+	}
+
+	select {
+	case s.eventCh <- event:
+	case <-s.ctx.Done():
+	}
+}
+
 func (s *swapState) handleReadyLogs(l *ethtypes.Log) (bool, error) {
 	err := pcommon.CheckSwapID(l, readyTopic, s.contractSwapID)
 	if errors.Is(err, pcommon.ErrLogNotForUs) {
@@ -53,13 +85,22 @@ func (s *swapState) handleReadyLogs(l *ethtypes.Log) (bool, error) {
 		return false, err
 	}
 
+	err = block.WaitForConfirmations(s.ctx, s.ETHClient().Raw(), l.TxHash, s.ethConfirmations)
+	if err != nil {
+		return false, err
+	}
+
 	// contract was set to ready, send EventReady
-	event := newEventContractReady()
-	s.eventCh <- event
+	event := newEventContractReady(&l.TxHash)
+	select {
+	case s.eventCh <- event:
+	case <-s.ctx.Done():
+		return false, s.ctx.Err()
+	}
 	go func() {
 		err = <-event.errCh
 		if err != nil {
-			log.Errorf("failed to handle EventReady: %s", err)
+			s.logger.Errorf("failed to handle EventReady: %s", err)
 		}
 	}()
 	return true, nil
@@ -79,8 +120,17 @@ func (s *swapState) handleRefundLogs(ethlog *ethtypes.Log) (bool, error) {
 		return false, err
 	}
 
+	err = block.WaitForConfirmations(s.ctx, s.ETHClient().Raw(), ethlog.TxHash, s.ethConfirmations)
+	if err != nil {
+		return false, err
+	}
+
 	// swap was refunded, send EventRefunded
-	event := newEventETHRefunded(sk)
-	s.eventCh <- event
+	event := newEventETHRefunded(sk, ethlog.TxHash)
+	select {
+	case s.eventCh <- event:
+	case <-s.ctx.Done():
+		return false, s.ctx.Err()
+	}
 	return true, <-event.errCh
 }
@@ -21,32 +21,35 @@ func (s *swapState) tryClaim() error {
 		return nil
 	}
 
-	skA, err := s.filterForClaim()
+	skA, txHash, err := s.filterForClaim()
 	if err != nil {
 		return err
 	}
+	s.setTxHash(&s.info.ClaimTxHash, txHash)
 
 	addr, err := s.claimMonero(skA)
 	if err != nil {
 		return err
 	}
 
-	log.Infof("claimed monero: address=%s", addr)
+	s.logger.Infof("claimed monero: address=%s", addr)
 	s.clearNextExpectedEvent(types.CompletedSuccess)
 	return nil
 }
 
-func (s *swapState) filterForClaim() (*mcrypto.PrivateSpendKey, error) {
+// filterForClaim returns the secret revealed by the counterparty's claim, and
+// the hash of the claim transaction.
+func (s *swapState) filterForClaim() (*mcrypto.PrivateSpendKey, ethcommon.Hash, error) {
 	logs, err := s.ETHClient().Raw().FilterLogs(s.ctx, eth.FilterQuery{
 		Addresses: []ethcommon.Address{s.SwapCreatorAddr()},
 		Topics:    [][]ethcommon.Hash{{claimedTopic}},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to filter logs: %w", err)
+		return nil, ethcommon.Hash{}, fmt.Errorf("failed to filter logs: %w", err)
 	}
 
 	if len(logs) == 0 {
-		return nil, errNoClaimLogsFound
+		return nil, ethcommon.Hash{}, errNoClaimLogsFound
 	}
 
 	var (
@@ -68,15 +71,15 @@ func (s *swapState) filterForClaim() (*mcrypto.PrivateSpendKey, error) {
 	}
 
 	if !found {
-		return nil, errNoClaimLogsFound
+		return nil, ethcommon.Hash{}, errNoClaimLogsFound
 	}
 
 	sa, err := contracts.GetSecretFromLog(&foundLog, claimedTopic)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get secret from log: %w", err)
+		return nil, ethcommon.Hash{}, fmt.Errorf("failed to get secret from log: %w", err)
 	}
 
-	return sa, nil
+	return sa, foundLog.TxHash, nil
 }
 
 func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (*mcrypto.Address, error) {
@@ -116,11 +119,11 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (*mcrypto.Address,
 	}
 
 	close(s.claimedCh)
-	log.Infof("monero claimed and swept to original account %s", depositAddr)
+	s.logger.Infof("monero claimed and swept to original account %s", depositAddr)
 	go func() {
 		err = s.Exit()
 		if err != nil {
-			log.Warnf("failed to exit: %v", err)
+			s.logger.Warnf("failed to exit: %v", err)
 		}
 	}()
 	return kpAB.PublicKeyPair().Address(s.Env()), nil
@@ -8,13 +8,16 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/apd/v3"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
 var (
 	// various instance and swap errors
 	errNoOngoingSwap           = errors.New("no ongoing swap with given offer ID")
 	errSenderIsNotExternal     = errors.New("swap is not using an external transaction sender")
-	errUnexpectedMessageType   = errors.New("unexpected message type")
+	errUnexpectedMessageType   = fmt.Errorf("%w type", message.ErrUnexpectedMessage)
 	errUnexpectedEventType     = errors.New("unexpected event type")
 	errMissingKeys             = errors.New("did not receive XMRMaker's public spend or private view key")
 	errMissingProvidedAmount   = errors.New("did not receive provided amount")
@@ -25,6 +28,7 @@ var (
 	errCounterpartyKeysNotSet  = errors.New("counterparty's keys aren't set")
 	errSwapInstantiationNoLogs = errors.New("expected 1 log, got 0")
 	errSwapCompleted           = errors.New("swap is already completed")
+	errNotSweeping             = errors.New("can only retry the sweep of swaps with status SweepingXMR")
 
 	// initiation errors
 	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
@@ -73,6 +77,15 @@ func (e errAmountProvidedTooHigh) Error() string {
 	)
 }
 
+type errExchangeRateTooHigh struct {
+	offerRate *coins.ExchangeRate
+	maxRate   *coins.ExchangeRate
+}
+
+func (e errExchangeRateTooHigh) Error() string {
+	return fmt.Sprintf("offer exchange rate of %s is over maximum of %s", e.offerRate, e.maxRate)
+}
+
 type errETHBalanceTooLow struct {
 	currentBalanceETH  *apd.Decimal
 	requiredBalanceETH *apd.Decimal
@@ -151,8 +151,9 @@ func newEventXMRLocked() *EventXMRLocked {
 // EventETHClaimed is the third expected event. It represents the ETH being claimed
 // by the counterparty, and thus we can also claim the XMR.
 type EventETHClaimed struct {
-	sk    *mcrypto.PrivateSpendKey
-	errCh chan error
+	sk     *mcrypto.PrivateSpendKey
+	txHash ethcommon.Hash
+	errCh  chan error
 }
 
 // Type ...
@@ -160,10 +161,11 @@ func (*EventETHClaimed) Type() EventType {
 	return EventETHClaimedType
 }
 
-func newEventETHClaimed(sk *mcrypto.PrivateSpendKey) *EventETHClaimed {
+func newEventETHClaimed(sk *mcrypto.PrivateSpendKey, txHash ethcommon.Hash) *EventETHClaimed {
 	return &EventETHClaimed{
-		sk:    sk,
-		errCh: make(chan error),
+		sk:     sk,
+		txHash: txHash,
+		errCh:  make(chan error),
 	}
 }
 
@@ -190,7 +192,9 @@ func newEventShouldRefund() *EventShouldRefund {
 // for example if the remote peer closes their connection with us before sending all
 // required messages, or we decide to cancel the swap.
 type EventExit struct {
-	errCh chan error
+	// reason, if set, is recorded as the swap's failure reason
+	reason string
+	errCh  chan error
 }
 
 // Type ...
@@ -219,11 +223,12 @@ func (s *swapState) handleEvent(event Event) {
 	// events are only used once, so their error channel can be closed after handling.
 	switch e := event.(type) {
 	case *EventKeysReceived:
-		log.Infof("EventKeysReceived")
+		s.logger.Infof("EventKeysReceived")
 		defer close(e.errCh)
 
 		if s.nextExpectedEvent != EventKeysReceivedType {
-			e.errCh <- fmt.Errorf("nextExpectedEvent was %s, not %s", s.nextExpectedEvent, e.Type())
+			e.errCh <- fmt.Errorf("%w: nextExpectedEvent was %s, not %s",
+				message.ErrUnexpectedMessage, s.nextExpectedEvent, e.Type())
 			return
 		}
 
@@ -241,7 +246,7 @@ func (s *swapState) handleEvent(event Event) {
 			return
 		}
 	case *EventXMRLocked:
-		log.Infof("EventXMRLocked")
+		s.logger.Infof("EventXMRLocked")
 		defer close(e.errCh)
 
 		if s.nextExpectedEvent != EventXMRLockedType {
@@ -261,7 +266,7 @@ func (s *swapState) handleEvent(event Event) {
 			return
 		}
 	case *EventETHClaimed:
-		log.Infof("EventETHClaimed")
+		s.logger.Infof("EventETHClaimed")
 		defer close(e.errCh)
 
 		if s.nextExpectedEvent != EventETHClaimedType {
@@ -274,7 +279,7 @@ func (s *swapState) handleEvent(event Event) {
 			e.errCh <- fmt.Errorf("failed to handle %s: %w", e.Type(), err)
 		}
 	case *EventShouldRefund:
-		log.Infof("EventShouldRefund")
+		s.logger.Infof("EventShouldRefund")
 		defer close(e.errCh)
 		defer close(e.txHashCh)
 
@@ -292,13 +297,17 @@ func (s *swapState) handleEvent(event Event) {
 
 		err = s.exit()
 		if err != nil {
-			log.Warnf("failed to exit swap: %s", err)
+			s.logger.Warnf("failed to exit swap: %s", err)
 		}
 	case *EventExit:
 		// this can happen at any stage.
-		log.Infof("EventExit")
+		s.logger.Infof("EventExit")
 		defer close(e.errCh)
 
+		if e.reason != "" {
+			s.exitReason = e.reason
+		}
+
 		err := s.exit()
 		if err != nil {
 			e.errCh <- fmt.Errorf("failed to handle EventExit: %w", err)
@@ -318,6 +327,7 @@ func (s *swapState) handleEventKeysReceived(event *EventKeysReceived) error {
 }
 
 func (s *swapState) handleEventETHClaimed(event *EventETHClaimed) error {
+	s.setTxHash(&s.info.ClaimTxHash, event.txHash)
 	_, err := s.claimMonero(event.sk)
 	if err != nil {
 		return err
@@ -339,11 +349,11 @@ func (s *swapState) handleEventShouldRefund(event *EventShouldRefund) error {
 			return err
 		}
 
-		log.Debugf("failed to refund (okay): err=%s", err)
+		s.logger.Debugf("failed to refund (okay): err=%s", err)
 		return nil
 	}
 
-	log.Infof("got our ETH back: tx hash=%s", receipt.TxHash)
+	s.logger.Infof("got our ETH back: tx hash=%s", receipt.TxHash)
 	event.txHashCh <- receipt.TxHash
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
 
@@ -65,7 +66,7 @@ func lockXMRAndCheckForReadyLog(t *testing.T, s *swapState, xmrAddr *mcrypto.Add
 func TestSwapState_handleEvent_EventETHClaimed(t *testing.T) {
 	s, net := newTestSwapStateAndNet(t)
 	defer s.cancel()
-	s.SetSwapTimeout(time.Minute * 2)
+	s.SetSwapTimeout(time.Minute*2, time.Minute*2)
 
 	// invalid SendKeysMessage should result in an error
 	msg := &message.SendKeysMessage{}
@@ -105,9 +106,23 @@ func TestSwapState_handleEvent_EventETHClaimed(t *testing.T) {
 	require.NoError(t, err)
 
 	// handled the claimed message should result in the monero wallet being created
-	event := newEventETHClaimed(sk)
+	event := newEventETHClaimed(sk, ethcommon.Hash{0x1})
 	s.eventCh <- event
 	err = <-event.errCh
 	require.NoError(t, err)
 	require.Equal(t, types.CompletedSuccess, s.info.Status)
+
+	// all the per-swap goroutines must exit once the swap completes
+	require.Empty(t, s.goroutines.WaitForExit(time.Second*10))
+}
+
+func TestEventType_getStatus_validTransitions(t *testing.T) {
+	// the taker's statuses set by setNextExpectedEvent, in order
+	status := types.ExpectingKeys
+	for _, event := range []EventType{EventXMRLockedType, EventETHClaimedType} {
+		next := event.getStatus()
+		require.True(t, status.IsValidTransition(next), "%s -> %s", status, next)
+		require.Equal(t, event, nextExpectedEventFromStatus(next))
+		status = next
+	}
 }
@@ -6,6 +6,7 @@ package xmrtaker
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	logging "github.com/ipfs/go-log"
 
@@ -35,6 +36,9 @@ type Instance struct {
 	// map of offer IDs -> ongoing swaps
 	swapStates map[types.Hash]*swapState
 	swapMu     sync.RWMutex // lock for above map
+
+	maxSwapDuration  time.Duration
+	ethConfirmations uint64
 }
 
 // Config contains the configuration values for a new XMRTaker instance.
@@ -43,6 +47,15 @@ type Config struct {
 	DataDir        string
 	NoTransferBack bool
 	ExternalSender bool
+
+	// MaxSwapDuration, if non-zero, is how long a swap can be ongoing before
+	// it is exited, refunding our ETH if it was locked.
+	MaxSwapDuration time.Duration
+
+	// EthConfirmations is the number of blocks that must be mined on top of
+	// the block containing a Claimed log before we act on the claim. If zero,
+	// the log is acted on as soon as it is included.
+	EthConfirmations uint64
 }
 
 // NewInstance returns a new instance of XMRTaker.
@@ -53,6 +66,9 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		backend:    cfg.Backend,
 		dataDir:    cfg.DataDir,
 		swapStates: make(map[types.Hash]*swapState),
+
+		maxSwapDuration:  cfg.MaxSwapDuration,
+		ethConfirmations: cfg.EthConfirmations,
 	}
 
 	err := inst.checkForOngoingSwaps()
@@ -85,14 +101,16 @@ func (inst *Instance) checkForOngoingSwaps() error {
 		}
 
 		if s.Status == types.SweepingXMR {
+			// the sweep may have failed or been interrupted, so we only mark
+			// the swap as completed once the XMR is out of the swap wallet
 			log.Infof(
-				"found ongoing swap %s in DB where XMR was being swept back to the primary account, marking as completed",
+				"found ongoing swap %s in DB where XMR was being swept back to the primary account, retrying sweep",
 				s.OfferID,
 			)
-			s.Status = types.CompletedSuccess
-			err = inst.backend.SwapManager().CompleteOngoingSwap(s)
+			err = inst.retrySweep(s)
 			if err != nil {
-				return fmt.Errorf("failed to mark swap as completed: %w", err)
+				log.Errorf("failed to retry sweep of swap %s, retry with `swapcli recovery retry-sweep`: %s",
+					s.OfferID, err)
 			}
 
 			continue
@@ -158,7 +176,9 @@ func (inst *Instance) createOngoingSwap(s *swap.Info) error {
 		return fmt.Errorf("failed to create new swap state for ongoing swap, offer id %s: %w", s.OfferID, err)
 	}
 
+	ss.ethConfirmations = inst.ethConfirmations
 	inst.swapStates[s.OfferID] = ss
+	ss.startLifecycleTimeout(inst.maxSwapDuration)
 
 	go func() {
 		<-ss.done
@@ -210,7 +230,8 @@ func (inst *Instance) completeSwap(s *swap.Info, skB *mcrypto.PrivateSpendKey) e
 		inst.backend.XMRClient(),
 		kpAB,
 		inst.backend.XMRClient().PrimaryAddress(),
-		inst.noTransferBack,
+		// a swap that was already sweeping is always swept to completion
+		inst.noTransferBack && s.Status != types.SweepingXMR,
 		inst.backend.SwapManager(),
 	)
 	if err != nil {
@@ -0,0 +1,57 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrtaker
+
+import (
+	"time"
+
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+	pswap "github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// startLifecycleTimeout exits the swap if it is still ongoing after
+// maxDuration. A zero maxDuration disables the timeout.
+func (s *swapState) startLifecycleTimeout(maxDuration time.Duration) {
+	if maxDuration == 0 {
+		return
+	}
+
+	s.goroutines.Go("runLifecycleTimeout", func() {
+		s.runLifecycleTimeout(maxDuration)
+	})
+}
+
+// runLifecycleTimeout waits until the swap exceeds maxDuration, then exits it
+// the same way as a user cancellation, recording the lifecycle timeout as the
+// failure reason. The exit path only aborts while our ETH is unlocked. Once
+// it is locked, it refunds as soon as the contract allows it, so the timeout
+// never puts our funds at risk.
+func (s *swapState) runLifecycleTimeout(maxDuration time.Duration) {
+	if !pcommon.WaitForSwapDeadline(s.ctx, s.info.StartTime, maxDuration) {
+		return
+	}
+
+	s.logger.Warnf("swap %s exceeded the maximum swap duration of %s, exiting", s.OfferID(), maxDuration)
+
+	event := &EventExit{
+		reason: pswap.LifecycleTimeoutReason,
+		// buffered, as we stop waiting for the result if the swap's context is
+		// cancelled first
+		errCh: make(chan error, 1),
+	}
+
+	select {
+	case s.eventCh <- event:
+	case <-s.ctx.Done():
+		return
+	}
+
+	select {
+	case err := <-event.errCh:
+		if err != nil {
+			s.logger.Warnf("failed to exit swap %s after lifecycle timeout: %s", s.OfferID(), err)
+		}
+	case <-s.ctx.Done():
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/monero"
 	"github.com/athanorlabs/atomic-swap/net/message"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
@@ -60,7 +61,11 @@ func (s *swapState) setNextExpectedEvent(event EventType) error {
 		panic("status corresponding to event cannot be UnknownStatus")
 	}
 
-	log.Debugf("setting status to %s", status)
+	if !s.info.Status.IsValidTransition(status) {
+		s.logger.Warnf("unexpected status transition from %s to %s", s.info.Status, status)
+	}
+
+	s.logger.Debugf("setting status to %s", status)
 	s.info.SetStatus(status)
 	return s.Backend.SwapManager().WriteSwapToDB(s.info)
 }
@@ -93,15 +98,25 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) (common.
 		return nil, err
 	}
 
+	// store the proof, so that it can be verified again when diagnosing the swap
+	err = s.Backend.RecoveryDB().PutCounterpartyDLEqProof(s.info.OfferID, &db.CounterpartyDLEqProof{
+		Proof:              msg.DLEqProof,
+		Secp256k1PublicKey: msg.Secp256k1PublicKey,
+		PublicSpendKey:     msg.PublicSpendKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	s.xmrmakerAddress = msg.EthAddress
-	log.Debugf("got XMRMaker's keys and address: address=%s", s.xmrmakerAddress)
+	s.logger.Debugf("got XMRMaker's keys and address: address=%s", s.xmrmakerAddress)
 
 	symbol, err := pcommon.AssetSymbol(s.Backend, s.info.EthAsset)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof(color.New(color.Bold).Sprintf("receiving %v XMR for %v %s",
+	s.logger.Infof(color.New(color.Bold).Sprintf("receiving %v XMR for %v %s",
 		msg.ProvidedAmount,
 		s.info.ProvidedAmount,
 		symbol,
@@ -111,7 +126,7 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) (common.
 	if err != nil {
 		return nil, fmt.Errorf("failed to set xmrmaker keys: %w", err)
 	}
-	log.Debugf("stored XMR maker's keys, going to lock ETH")
+	s.logger.Debugf("stored XMR maker's keys, going to lock ETH")
 
 	receipt, err := s.lockAsset()
 	if err != nil {
@@ -119,10 +134,10 @@ func (s *swapState) handleSendKeysMessage(msg *message.SendKeysMessage) (common.
 	}
 
 	// start goroutine to check that XMRMaker locks before t_0
-	go s.runT0ExpirationHandler()
+	s.goroutines.Go("runT0ExpirationHandler", s.runT0ExpirationHandler)
 
 	// start goroutine to check for xmr being locked
-	go s.checkForXMRLock()
+	s.goroutines.Go("checkForXMRLock", s.checkForXMRLock)
 
 	out := &message.NotifyETHLocked{
 		Address:        s.SwapCreatorAddr(),
@@ -149,12 +164,12 @@ func (s *swapState) checkForXMRLock() {
 	conf := s.XMRClient().CreateWalletConf("xmrtaker-swap-wallet-verify-funds")
 	abViewCli, err := monero.CreateViewOnlyWalletFromKeys(conf, vk, lockedAddr, s.walletScanHeight)
 	if err != nil {
-		log.Errorf("failed to generate view-only wallet to verify locked XMR: %s", err)
+		s.logger.Errorf("failed to generate view-only wallet to verify locked XMR: %s", err)
 		return
 	}
 	defer abViewCli.CloseAndRemoveWallet()
 
-	log.Debugf("generated view-only wallet to check funds: %s", abViewCli.WalletName())
+	s.logger.Debugf("generated view-only wallet to check funds: %s", abViewCli.WalletName())
 
 	timer := time.NewTicker(checkForXMRLockInterval)
 	for {
@@ -164,11 +179,11 @@ func (s *swapState) checkForXMRLock() {
 		case <-timer.C:
 			balance, err := abViewCli.GetBalance(0)
 			if err != nil {
-				log.Errorf("failed to get balance: %s", err)
+				s.logger.Errorf("failed to get balance: %s", err)
 				continue
 			}
 
-			log.Debugf("checking locked wallet, address=%s balance=%d blocks-to-unlock=%d",
+			s.logger.Debugf("checking locked wallet, address=%s balance=%d blocks-to-unlock=%d",
 				lockedAddr, balance.Balance, balance.BlocksToUnlock)
 
 			if s.expectedPiconeroAmount().CmpU64(balance.UnlockedBalance) <= 0 {
@@ -176,7 +191,7 @@ func (s *swapState) checkForXMRLock() {
 				s.eventCh <- event
 				err := <-event.errCh
 				if err != nil {
-					log.Errorf("eventXMRLocked errored: %s", err)
+					s.logger.Errorf("eventXMRLocked errored: %s", err)
 				}
 
 				return
@@ -186,7 +201,7 @@ func (s *swapState) checkForXMRLock() {
 }
 
 func (s *swapState) runT0ExpirationHandler() {
-	defer log.Debugf("returning from runT0ExpirationHandler")
+	defer s.logger.Debugf("returning from runT0ExpirationHandler")
 
 	// TODO: this variable is so that we definitely refund before t0.
 	// Current algorithm is to trigger the timeout when only 15% of the allotted
@@ -196,12 +211,10 @@ func (s *swapState) runT0ExpirationHandler() {
 	// satisfy the strictly less than requirement. 7s * 15% = 1.05s. 15% remaining
 	// may be reasonable even with large timeouts on production networks, but more
 	// research is needed.
-	t0Delta := s.t1.Sub(s.t0) // time between swap start and T0 is equal to T1-T0
-	deltaBeforeT0ToGiveUp := time.Duration(float64(t0Delta) * 0.15)
-	deltaUntilGiveUp := time.Until(s.t0) - deltaBeforeT0ToGiveUp
+	deltaUntilGiveUp := time.Until(giveUpDeadline(s.t0, s.t0Duration))
 	giveUpAndRefundTimer := time.NewTimer(deltaUntilGiveUp)
 	defer giveUpAndRefundTimer.Stop() // don't wait for the timeout to garbage collect
-	log.Debugf("time until refund: %vs", deltaUntilGiveUp.Seconds())
+	s.logger.Debugf("time until refund: %vs", deltaUntilGiveUp.Seconds())
 
 	select {
 	case <-s.ctx.Done():
@@ -209,17 +222,25 @@ func (s *swapState) runT0ExpirationHandler() {
 	case <-s.xmrLockedCh:
 		return
 	case <-giveUpAndRefundTimer.C:
-		log.Infof("approaching T0, attempting to refund ETH")
+		s.logger.Infof("approaching T0, attempting to refund ETH")
 		event := newEventShouldRefund()
 		s.eventCh <- event
 		err := <-event.errCh
 		if err != nil {
 			// TODO: what should we do here? this would be bad. (#162)
-			log.Errorf("failed to refund: %s", err)
+			s.logger.Errorf("failed to refund: %s", err)
 		}
 	}
 }
 
+// giveUpDeadline returns the time at which we refund if the counterparty has
+// not locked their XMR, which is when 15% of the time between the swap being
+// initiated on-chain and t0 remains.
+func giveUpDeadline(t0 time.Time, t0Duration time.Duration) time.Time {
+	deltaBeforeT0ToGiveUp := time.Duration(float64(t0Duration) * 0.15)
+	return t0.Add(-deltaBeforeT0ToGiveUp)
+}
+
 func (s *swapState) expectedXMRLockAccount() (*mcrypto.Address, *mcrypto.PrivateViewKey) {
 	vk := mcrypto.SumPrivateViewKeys(s.xmrmakerPrivateViewKey, s.privkeys.ViewKey())
 	sk := mcrypto.SumPublicKeys(s.xmrmakerPublicSpendKey, s.pubkeys.SpendKey())
@@ -228,23 +249,23 @@ func (s *swapState) expectedXMRLockAccount() (*mcrypto.Address, *mcrypto.Private
 
 func (s *swapState) handleNotifyXMRLock() error {
 	close(s.xmrLockedCh)
-	log.Info("XMR was locked successfully, setting contract to ready...")
+	s.logger.Info("XMR was locked successfully, setting contract to ready...")
 
 	if err := s.ready(); err != nil {
 		return fmt.Errorf("failed to call Ready: %w", err)
 	}
 
-	go s.runT1ExpirationHandler()
+	s.goroutines.Go("runT1ExpirationHandler", s.runT1ExpirationHandler)
 	return nil
 }
 
 func (s *swapState) runT1ExpirationHandler() {
-	log.Debugf("time until t1 (%s): %vs",
+	s.logger.Debugf("time until t1 (%s): %vs",
 		s.t1.Format(common.TimeFmtSecs),
 		time.Until(s.t1).Seconds(),
 	)
 
-	defer log.Debugf("returning from runT1ExpirationHandler")
+	defer s.logger.Debugf("returning from runT1ExpirationHandler")
 
 	waitCtx, waitCtxCancel := context.WithCancel(context.Background())
 	defer waitCtxCancel() // Unblock WaitForTimestamp if still running when we exit
@@ -264,7 +285,7 @@ func (s *swapState) runT1ExpirationHandler() {
 		if err != nil {
 			// TODO: Do we propagate this error? If we retry, the logic should probably be inside
 			// WaitForTimestamp. (#162)
-			log.Errorf("failure waiting for T1 timeout: %s", err)
+			s.logger.Errorf("failure waiting for T1 timeout: %s", err)
 			return
 		}
 		s.handleT1Expired()
@@ -272,12 +293,12 @@ func (s *swapState) runT1ExpirationHandler() {
 }
 
 func (s *swapState) handleT1Expired() {
-	log.Debugf("handling T1")
+	s.logger.Debugf("handling T1")
 	event := newEventShouldRefund()
 	s.eventCh <- event
 	err := <-event.errCh
 	if err != nil {
 		// TODO: what should we do here? this would be bad. (#162)
-		log.Errorf("failed to refund: %s", err)
+		s.logger.Errorf("failed to refund: %s", err)
 	}
 }
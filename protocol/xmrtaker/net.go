@@ -21,17 +21,23 @@ func (inst *Instance) Provides() coins.ProvidesCoin {
 }
 
 // InitiateProtocol is called when an RPC call is made from the user to initiate a swap.
-// The input units are ether that we will provide.
+// The input units are ether that we will provide. If maxExchangeRate is not nil, the
+// swap is not started if the offer's exchange rate is above it.
 func (inst *Instance) InitiateProtocol(
 	makerPeerID peer.ID,
 	providesAmount *apd.Decimal,
 	offer *types.Offer,
+	maxExchangeRate *coins.ExchangeRate,
 ) (common.SwapState, error) {
 	err := coins.ValidatePositive("providesAmount", coins.NumEtherDecimals, providesAmount)
 	if err != nil {
 		return nil, err
 	}
 
+	if maxExchangeRate != nil && offer.ExchangeRate.Cmp(maxExchangeRate) > 0 {
+		return nil, errExchangeRateTooHigh{offer.ExchangeRate, maxExchangeRate}
+	}
+
 	offerMinETH, err := offer.ExchangeRate.ToETH(offer.MinAmount)
 	if err != nil {
 		return nil, err
@@ -105,6 +111,8 @@ func (inst *Instance) initiate(
 		return nil, err
 	}
 
+	s.ethConfirmations = inst.ethConfirmations
+
 	go func() {
 		<-s.done
 		inst.swapMu.Lock()
@@ -115,5 +123,6 @@ func (inst *Instance) initiate(
 	log.Info(color.New(color.Bold).Sprintf("**initiated swap with offer ID=%s**", s.info.OfferID))
 	log.Info(color.New(color.Bold).Sprint("DO NOT EXIT THIS PROCESS OR THE SWAP MAY BE CANCELLED!"))
 	inst.swapStates[offerID] = s
+	s.startLifecycleTimeout(inst.maxSwapDuration)
 	return s, nil
 }
@@ -40,7 +40,7 @@ func initiate(
 		coins.ToExchangeRate(apd.New(1, 0)),
 		types.EthAssetETH,
 	)
-	s, err := xmrtaker.InitiateProtocol(testPeerID, providesAmount, offer)
+	s, err := xmrtaker.InitiateProtocol(testPeerID, providesAmount, offer, nil)
 	return offer, s, err
 }
 
@@ -74,3 +74,25 @@ func TestXMRTaker_InitiateProtocol(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, nil, s)
 }
+
+func TestXMRTaker_InitiateProtocol_maxExchangeRate(t *testing.T) {
+	a := newTestXMRTaker(t)
+	offer := types.NewOffer(
+		coins.ProvidesETH,
+		new(apd.Decimal),
+		apd.New(1, 0),
+		coins.StrToExchangeRate("0.1"),
+		types.EthAssetETH,
+	)
+
+	// the offer's rate is over the maximum
+	s, err := a.InitiateProtocol(testPeerID, apd.New(1, -2), offer, coins.StrToExchangeRate("0.09"))
+	require.ErrorContains(t, err, "offer exchange rate of 0.1 is over maximum of 0.09")
+	require.Nil(t, s)
+	require.Nil(t, a.swapStates[offer.ID])
+
+	// the offer's rate is exactly the maximum
+	s, err = a.InitiateProtocol(testPeerID, apd.New(1, -2), offer, coins.StrToExchangeRate("0.1"))
+	require.NoError(t, err)
+	require.Equal(t, a.swapStates[offer.ID], s)
+}
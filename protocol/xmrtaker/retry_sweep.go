@@ -0,0 +1,55 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package xmrtaker
+
+import (
+	"fmt"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// RetrySweep retries sweeping the XMR of an ongoing swap out of the swap
+// wallet, for the case where the XMR was claimed but sweeping it to the primary
+// wallet failed, leaving the swap with status SweepingXMR. The swap wallet is
+// recreated from the keys in the recovery database, and the swap is only
+// completed once its XMR was swept.
+func (inst *Instance) RetrySweep(offerID types.Hash) error {
+	info, err := inst.backend.SwapManager().GetOngoingSwap(offerID)
+	if err != nil {
+		return err
+	}
+
+	if info.Status != types.SweepingXMR {
+		return errNotSweeping
+	}
+
+	inst.swapMu.RLock()
+	s := inst.swapStates[offerID]
+	inst.swapMu.RUnlock()
+
+	if s == nil {
+		return inst.retrySweep(&info)
+	}
+
+	// Stop the swap's goroutines, so it doesn't act on the swap while we sweep.
+	s.cancel()
+	if err = inst.retrySweep(s.info); err != nil {
+		return err
+	}
+
+	close(s.done)
+	return nil
+}
+
+// retrySweep sweeps the XMR of a swap with status SweepingXMR using the swap
+// keys stored in the recovery database, and completes the swap.
+func (inst *Instance) retrySweep(info *swap.Info) error {
+	skB, err := inst.backend.RecoveryDB().GetCounterpartySwapPrivateKey(info.OfferID)
+	if err != nil {
+		return fmt.Errorf("failed to get counterparty swap private key: %w", err)
+	}
+
+	return inst.completeSwap(info, skB)
+}
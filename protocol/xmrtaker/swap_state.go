@@ -35,6 +35,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/fatih/color"
+	"go.uber.org/zap"
 )
 
 const revertSwapCompleted = "swap is already completed"
@@ -73,6 +74,16 @@ type swapState struct {
 	contractSwapID [32]byte
 	contractSwap   *contracts.SwapCreatorSwap
 	t0, t1         time.Time
+	// duration between the swap being initiated on-chain and t0
+	t0Duration time.Duration
+
+	// number of blocks that must be mined on top of a Claimed log before we
+	// act on it
+	ethConfirmations uint64
+
+	// logs under the swap's correlation ID, with its offer ID and
+	// counterparty as fields
+	logger *zap.SugaredLogger
 
 	// tracks the state of the swap
 	nextExpectedEvent EventType
@@ -85,13 +96,21 @@ type swapState struct {
 	// the event handler in event.go ensures only one event is being handled at a time
 	eventCh chan Event
 	// channel for `Claimed` logs seen on-chain
-	logClaimedCh chan ethtypes.Log
+	logClaimedCh   chan ethtypes.Log
+	claimedWatcher *watcher.EventFilter
 	// signals the t0 expiration handler to return
 	xmrLockedCh chan struct{}
 	// signals the t1 expiration handler to return
 	claimedCh chan struct{}
 	// signals to the creator xmrmaker instance that it can delete this swap
 	done chan struct{}
+
+	// tracks the per-swap goroutines, which must exit when the swap completes
+	goroutines *pcommon.SwapGoroutines
+
+	// set if the swap is being exited for a reason that should be recorded
+	// when it doesn't complete successfully
+	exitReason string
 }
 
 func newSwapStateFromStart(
@@ -138,6 +157,7 @@ func newSwapStateFromStart(
 		moneroStartNumber,
 		statusCh,
 	)
+	info.MarketExchangeRate = pcommon.MarketExchangeRate(b, ethAsset)
 	if err = b.SwapManager().AddSwap(info); err != nil {
 		return nil, err
 	}
@@ -193,6 +213,9 @@ func newSwapStateFromOngoing(
 	}
 
 	s.setTimeouts(ethSwapInfo.Swap.Timeout0, ethSwapInfo.Swap.Timeout1)
+	// the swap was initiated on-chain a little after it started, so this
+	// overestimates the duration, which only makes us refund a little earlier
+	s.t0Duration = s.t0.Sub(info.StartTime)
 	s.privkeys = sk
 	s.pubkeys = sk.PublicKeyPair()
 	s.contractSwapID = ethSwapInfo.SwapID
@@ -201,7 +224,7 @@ func newSwapStateFromOngoing(
 	s.xmrmakerPrivateViewKey = makerVk
 
 	if info.Status == types.ETHLocked {
-		go s.checkForXMRLock()
+		s.goroutines.Go("checkForXMRLock", s.checkForXMRLock)
 	}
 	return s, nil
 }
@@ -242,8 +265,7 @@ func newSwapState(
 	}
 
 	// set up ethereum event watchers
-	const logChSize = 16
-	logClaimedCh := make(chan ethtypes.Log, logChSize)
+	logClaimedCh := make(chan ethtypes.Log, b.LogChSize())
 
 	ctx, cancel := context.WithCancel(b.Ctx())
 
@@ -291,17 +313,20 @@ func newSwapState(
 		noTransferBack:    noTransferBack,
 		walletScanHeight:  moneroStartNumber,
 		nextExpectedEvent: nextExpectedEventFromStatus(info.Status),
-		eventCh:           make(chan Event),
+		eventCh:           make(chan Event, b.EventChSize()),
 		logClaimedCh:      logClaimedCh,
+		claimedWatcher:    claimedWatcher,
 		xmrLockedCh:       make(chan struct{}),
 		claimedCh:         make(chan struct{}),
 		done:              make(chan struct{}),
 		info:              info,
+		logger:            pcommon.SwapLogger(log, info),
 		providedAmount:    providedAmt,
+		goroutines:        pcommon.NewSwapGoroutines(),
 	}
 
-	go s.runHandleEvents()
-	go s.runContractEventWatcher()
+	s.goroutines.Go("runHandleEvents", s.runHandleEvents)
+	s.goroutines.Go("runContractEventWatcher", s.runContractEventWatcher)
 	return s, nil
 }
 
@@ -343,20 +368,25 @@ func (s *swapState) exit() error {
 	defer func() {
 		s.CloseProtocolStream(s.OfferID())
 
+		if s.exitReason != "" && s.info.Status != types.CompletedSuccess {
+			s.info.FailureReason = s.exitReason
+		}
+
 		err := s.SwapManager().CompleteOngoingSwap(s.info)
 		if err != nil {
-			log.Warnf("failed to mark swap %s as completed: %s", s.info.OfferID, err)
+			s.logger.Warnf("failed to mark swap %s as completed: %s", s.info.OfferID, err)
 			return
 		}
 
 		err = s.Backend.RecoveryDB().DeleteSwap(s.OfferID())
 		if err != nil {
-			log.Warnf("failed to delete temporary swap info %s from db: %s", s.OfferID(), err)
+			s.logger.Warnf("failed to delete temporary swap info %s from db: %s", s.OfferID(), err)
 		}
 
 		// Stop all per-swap goroutines
 		s.cancel()
 		close(s.done)
+		s.goroutines.VerifyExited(s.OfferID())
 
 		var exitLog string
 		switch s.info.Status {
@@ -368,10 +398,10 @@ func (s *swapState) exit() error {
 			exitLog = color.New(color.Bold).Sprintf("**swap aborted: id=%s**", s.OfferID())
 		}
 
-		log.Info(exitLog)
+		s.logger.Info(exitLog)
 	}()
 
-	log.Debugf("attempting to exit swap: nextExpectedEvent=%s", s.nextExpectedEvent)
+	s.logger.Debugf("attempting to exit swap: nextExpectedEvent=%s", s.nextExpectedEvent)
 
 	switch s.nextExpectedEvent {
 	case EventKeysReceivedType:
@@ -389,7 +419,7 @@ func (s *swapState) exit() error {
 		if err != nil {
 			if errors.Is(err, errRefundSwapCompleted) {
 				s.clearNextExpectedEvent(types.CompletedRefund)
-				log.Infof("swap was already refunded")
+				s.logger.Infof("swap was already refunded")
 				return nil
 			}
 
@@ -407,13 +437,13 @@ func (s *swapState) exit() error {
 		}
 
 		s.clearNextExpectedEvent(types.CompletedRefund)
-		log.Infof("refunded ether: txID=%s", receipt.TxHash)
+		s.logger.Infof("refunded ether: txID=%s", receipt.TxHash)
 		return nil
 	case EventNoneType:
 		// the swap completed already, do nothing
 		return nil
 	default:
-		log.Errorf("unexpected nextExpectedEvent: %s", s.nextExpectedEvent)
+		s.logger.Errorf("unexpected nextExpectedEvent: %s", s.nextExpectedEvent)
 		s.clearNextExpectedEvent(types.CompletedAbort)
 		return errUnexpectedEventType
 	}
@@ -443,7 +473,7 @@ func (s *swapState) tryRefund() (*ethtypes.Receipt, error) {
 		return nil, err
 	}
 
-	log.Debugf("tryRefund isReady=%v untilT0=%vs untilT1=%vs",
+	s.logger.Debugf("tryRefund isReady=%v untilT0=%vs untilT1=%vs",
 		isReady, s.t0.Sub(ts).Seconds(), s.t1.Sub(ts).Seconds())
 
 	if ts.Before(s.t0) && !isReady {
@@ -456,7 +486,7 @@ func (s *swapState) tryRefund() (*ethtypes.Receipt, error) {
 		// There is a small, but non-zero chance that our transaction gets placed in a block that is after T0
 		// even though the current block is before T0. In this case, the transaction will be reverted, the
 		// gas fee is lost, but we can wait until T1 and try again.
-		log.Warnf("first refund attempt failed: err=%s", err)
+		s.logger.Warnf("first refund attempt failed: err=%s", err)
 	}
 
 	if ts.After(s.t1) {
@@ -470,7 +500,7 @@ func (s *swapState) tryRefund() (*ethtypes.Receipt, error) {
 	// from s.eventCh for EventShouldRefund or EventETHClaimed.
 	// (since this function is called from inside the event handler routine,
 	// it won't handle those events while this function is executing.)
-	log.Infof("waiting until time %s to refund", s.t1)
+	s.logger.Infof("waiting until time %s to refund", s.t1)
 
 	waitCtx, waitCtxCancel := context.WithCancel(s.ctx)
 	defer waitCtxCancel()
@@ -484,7 +514,7 @@ func (s *swapState) tryRefund() (*ethtypes.Receipt, error) {
 	for {
 		select {
 		case event := <-s.eventCh:
-			log.Debugf("got event %s while waiting for T1", event.Type())
+			s.logger.Debugf("got event %s while waiting for T1", event.Type())
 			switch event.(type) {
 			case *EventShouldRefund:
 				return s.refund()
@@ -563,14 +593,18 @@ func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 	cmtXMRMaker := s.xmrmakerSecp256k1PublicKey.Keccak256()
 	providedAmt := s.providedAmount
 
-	log.Debugf("locking %s %s in contract", providedAmt.AsStandard(), providedAmt.StandardSymbol())
+	s.logger.Debugf("locking %s %s in contract", providedAmt.AsStandard(), providedAmt.StandardSymbol())
 
 	nonce := contracts.GenerateNewSwapNonce()
+	t0Duration, t1Duration := s.SwapTimeout()
 	receipt, err := s.sender.NewSwap(
+		s.ctx,
+		s.OfferID(),
 		cmtXMRMaker,
 		cmtXMRTaker,
 		s.xmrmakerAddress,
-		big.NewInt(int64(s.SwapTimeout().Seconds())),
+		big.NewInt(int64(t0Duration.Seconds())),
+		big.NewInt(int64(t1Duration.Seconds())),
 		nonce,
 		providedAmt,
 	)
@@ -578,8 +612,9 @@ func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 		return nil, fmt.Errorf("failed to instantiate swap on-chain: %w", err)
 	}
 
-	log.Infof("instantiated swap on-chain: amount=%s asset=%s %s",
+	s.logger.Infof("instantiated swap on-chain: amount=%s asset=%s %s",
 		s.providedAmount, s.info.EthAsset, common.ReceiptInfo(receipt))
+	s.setTxHash(&s.info.NewSwapTxHash, receipt.TxHash)
 
 	if len(receipt.Logs) == 0 {
 		return nil, errSwapInstantiationNoLogs
@@ -609,6 +644,7 @@ func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 
 	s.fundsLocked = true
 	s.setTimeouts(t0, t1)
+	s.t0Duration = t0Duration
 
 	s.contractSwap = &contracts.SwapCreatorSwap{
 		Owner:        s.ETHClient().Address(),
@@ -633,7 +669,7 @@ func (s *swapState) lockAsset() (*ethtypes.Receipt, error) {
 		return nil, err
 	}
 
-	log.Infof("locked %s in swap contract, waiting for XMR to be locked", providedAmt.StandardSymbol())
+	s.logger.Infof("locked %s in swap contract, waiting for XMR to be locked", providedAmt.StandardSymbol())
 	return receipt, nil
 }
 
@@ -648,16 +684,16 @@ func (s *swapState) ready() error {
 
 	if stage != contracts.StagePending {
 		if stage == contracts.StageReady {
-			log.Warnf("contract already set to ready, ignoring call to ready()")
+			s.logger.Warnf("contract already set to ready, ignoring call to ready()")
 			return nil
 		}
 
 		if stage == contracts.StageCompleted {
-			log.Infof("contract aleady set to completed, ignoring call to ready() and sending EventExit")
+			s.logger.Infof("contract aleady set to completed, ignoring call to ready() and sending EventExit")
 			go func() {
 				err = s.Exit()
 				if err != nil {
-					log.Errorf("failed to handle EventExit: %s", err)
+					s.logger.Errorf("failed to handle EventExit: %s", err)
 				}
 			}()
 			return nil
@@ -666,7 +702,7 @@ func (s *swapState) ready() error {
 		return fmt.Errorf("cannot set contract to ready when swap stage is %s", contracts.StageToString(stage))
 	}
 
-	receipt, err := s.sender.SetReady(s.contractSwap)
+	receipt, err := s.sender.SetReady(s.ctx, s.OfferID(), s.contractSwap)
 	if err != nil {
 		if strings.Contains(err.Error(), revertSwapCompleted) && !s.info.Status.IsOngoing() {
 			return nil
@@ -674,7 +710,8 @@ func (s *swapState) ready() error {
 		return err
 	}
 
-	log.Infof("contract set to ready %s", common.ReceiptInfo(receipt))
+	s.logger.Infof("contract set to ready %s", common.ReceiptInfo(receipt))
+	s.setTxHash(&s.info.SetReadyTxHash, receipt.TxHash)
 
 	return nil
 }
@@ -685,12 +722,13 @@ func (s *swapState) ready() error {
 func (s *swapState) refund() (*ethtypes.Receipt, error) {
 	sc := s.getSecret()
 
-	log.Infof("attempting to call Refund()...")
-	receipt, err := s.sender.Refund(s.contractSwap, sc)
+	s.logger.Infof("attempting to call Refund()...")
+	receipt, err := s.sender.Refund(s.ctx, s.OfferID(), s.contractSwap, sc)
 	if err != nil {
 		return nil, err
 	}
-	log.Infof("refund succeeded %s", common.ReceiptInfo(receipt))
+	s.logger.Infof("refund succeeded %s", common.ReceiptInfo(receipt))
+	s.setTxHash(&s.info.RefundTxHash, receipt.TxHash)
 
 	s.clearNextExpectedEvent(types.CompletedRefund)
 	return receipt, nil
@@ -701,3 +739,12 @@ func (s *swapState) refund() (*ethtypes.Receipt, error) {
 func generateKeys() (*pcommon.KeysAndProof, error) {
 	return pcommon.GenerateKeysAndProof()
 }
+
+// setTxHash sets one of the transaction hashes in the swap's info and writes
+// the info to the db, so the hash can be looked up after the swap completes.
+func (s *swapState) setTxHash(field **ethcommon.Hash, txHash ethcommon.Hash) {
+	*field = &txHash
+	if err := s.Backend.SwapManager().WriteSwapToDB(s.info); err != nil {
+		s.logger.Warnf("failed to write transaction hash of swap %s to db: %s", s.OfferID(), err)
+	}
+}
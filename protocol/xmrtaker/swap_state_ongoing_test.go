@@ -23,7 +23,7 @@ var fakeAddress = ethcommon.Address{1}
 func setupSwapStateUntilETHLocked(t *testing.T) (*swapState, uint64) {
 	s := newTestSwapState(t)
 	defer s.cancel()
-	s.SetSwapTimeout(time.Minute * 2)
+	s.SetSwapTimeout(time.Minute*2, time.Minute*2)
 
 	rdb := s.Backend.RecoveryDB().(*backend.MockRecoveryDB)
 
@@ -113,7 +113,7 @@ func TestSwapStateOngoing_handleEvent_EventETHClaimed(t *testing.T) {
 	ss.nextExpectedEvent = EventETHClaimedType
 
 	// handled the claimed message should result in the monero wallet being created
-	event := newEventETHClaimed(sk)
+	event := newEventETHClaimed(sk, ethcommon.Hash{0x1})
 	ss.eventCh <- event
 	err = <-event.errCh
 	require.NoError(t, err)
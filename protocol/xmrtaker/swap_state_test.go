@@ -65,6 +65,10 @@ func (n *mockNet) DiscoverRelayers() ([]peer.ID, error) {
 	return nil, nil
 }
 
+func (n *mockNet) QueryRelayerFee(_ peer.ID) (*coins.WeiAmount, error) {
+	return coins.NewWeiAmount(coins.RelayerFeeWei), nil
+}
+
 func (n *mockNet) SubmitClaimToRelayer(_ peer.ID, _ *message.RelayClaimRequest) (*message.RelayClaimResponse, error) {
 	return new(message.RelayClaimResponse), nil
 }
@@ -77,6 +81,7 @@ func newSwapManager(t *testing.T) pswap.Manager {
 	db := pswap.NewMockDatabase(ctrl)
 	db.EXPECT().GetAllSwaps()
 	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
+	db.EXPECT().IncrementPeerStats(gomock.Any(), gomock.Any()).AnyTimes()
 
 	sm, err := pswap.NewManager(db)
 	require.NoError(t, err)
@@ -108,6 +113,7 @@ func newBackendAndNet(t *testing.T) (backend.Backend, *mockNet) {
 	rdb.EXPECT().PutSwapPrivateKey(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().PutCounterpartySwapPrivateKey(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().PutCounterpartySwapKeys(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	rdb.EXPECT().PutCounterpartyDLEqProof(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	rdb.EXPECT().DeleteSwap(gomock.Any()).Return(nil).AnyTimes()
 
 	net := new(mockNet)
@@ -223,7 +229,8 @@ func TestSwapState_HandleProtocolMessage_SendKeysMessage(t *testing.T) {
 
 	resp := net.LastSentMessage()
 	require.NotNil(t, resp)
-	require.Equal(t, s.SwapTimeout(), s.t1.Sub(s.t0))
+	_, t1Duration := s.SwapTimeout()
+	require.Equal(t, t1Duration, s.t1.Sub(s.t0))
 	require.Equal(t, xmrmakerKeysAndProof.PublicKeyPair.SpendKey().String(), s.xmrmakerPublicSpendKey.String())
 	require.Equal(t, xmrmakerKeysAndProof.PrivateKeyPair.ViewKey().String(), s.xmrmakerPrivateViewKey.String())
 }
@@ -233,7 +240,7 @@ func TestSwapState_HandleProtocolMessage_SendKeysMessage(t *testing.T) {
 func TestSwapState_HandleProtocolMessage_SendKeysMessage_Refund(t *testing.T) {
 	s, net := newTestSwapStateAndNet(t)
 	defer s.cancel()
-	s.SetSwapTimeout(time.Second * 15)
+	s.SetSwapTimeout(time.Second*15, time.Second*15)
 
 	msg, xmrmakerKeysAndProof := newTestXMRMakerSendKeysMessage(t)
 
@@ -243,7 +250,8 @@ func TestSwapState_HandleProtocolMessage_SendKeysMessage_Refund(t *testing.T) {
 	resp := net.LastSentMessage()
 	require.NotNil(t, resp)
 	require.Equal(t, message.NotifyETHLockedType, resp.Type())
-	require.Equal(t, s.SwapTimeout(), s.t1.Sub(s.t0))
+	_, t1Duration := s.SwapTimeout()
+	require.Equal(t, t1Duration, s.t1.Sub(s.t0))
 	require.Equal(t, xmrmakerKeysAndProof.PublicKeyPair.SpendKey().String(), s.xmrmakerPublicSpendKey.String())
 	require.Equal(t, xmrmakerKeysAndProof.PrivateKeyPair.ViewKey().String(), s.xmrmakerPrivateViewKey.String())
 
@@ -316,7 +324,7 @@ func TestSwapState_NotifyXMRLock_Refund(t *testing.T) {
 	s := newTestSwapState(t)
 	defer s.cancel()
 	s.nextExpectedEvent = EventXMRLockedType
-	s.SetSwapTimeout(time.Second * 3)
+	s.SetSwapTimeout(time.Second*3, time.Second*3)
 
 	xmrmakerKeysAndProof, err := generateKeys()
 	require.NoError(t, err)
@@ -480,3 +488,13 @@ func TestSwapState_ApproveToken(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "0", allowance.String())
 }
+
+func TestGiveUpDeadline(t *testing.T) {
+	t0 := time.Now().Add(40 * time.Minute)
+
+	// the deadline depends on the duration until t0, not on the duration
+	// between t0 and t1, which can be much longer
+	deadline := giveUpDeadline(t0, 40*time.Minute)
+	require.Equal(t, t0.Add(-6*time.Minute), deadline)
+	require.True(t, deadline.After(time.Now()))
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
@@ -17,14 +18,21 @@ func (s *swapState) runContractEventWatcher() {
 		select {
 		case <-s.ctx.Done():
 			return
+		case err := <-s.claimedWatcher.Err():
+			// The watcher only gives up if the ethereum client was closed.
+			// The swap is left as it is, so that it is resumed with a new
+			// watcher when swapd restarts, as exiting could leave our ETH
+			// refundable without us learning the secret from a claim.
+			s.logger.Errorf("Claimed event watcher failed, the swap will be resumed when swapd restarts: %s", err)
+			return
 		case l := <-s.logClaimedCh:
 			eventSent, err := s.handleClaimedLogs(&l)
 			if err != nil {
-				log.Errorf("failed to handle ready logs: %s", err)
+				s.logger.Errorf("failed to handle ready logs: %s", err)
 			}
 
 			if eventSent {
-				log.Debugf("EventETHClaimed sent, returning from event watcher")
+				s.logger.Debugf("EventETHClaimed sent, returning from event watcher")
 				return
 			}
 		}
@@ -45,8 +53,17 @@ func (s *swapState) handleClaimedLogs(l *ethtypes.Log) (bool, error) {
 		return false, err
 	}
 
+	err = block.WaitForConfirmations(s.ctx, s.ETHClient().Raw(), l.TxHash, s.ethConfirmations)
+	if err != nil {
+		return false, err
+	}
+
 	// contract was set to ready, send EventReady
-	event := newEventETHClaimed(sk)
-	s.eventCh <- event
+	event := newEventETHClaimed(sk, l.TxHash)
+	select {
+	case s.eventCh <- event:
+	case <-s.ctx.Done():
+		return false, s.ctx.Err()
+	}
 	return true, <-event.errCh
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	logging "github.com/ipfs/go-log"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
@@ -24,7 +25,8 @@ const (
 var log = logging.Logger("relayer")
 
 // CreateRelayClaimRequest fills and returns a RelayClaimRequest ready for
-// submission to a relayer.
+// submission to a relayer. The claim is signed with the given relayer fee, or
+// with coins.RelayerFeeWei if the fee is nil.
 func CreateRelayClaimRequest(
 	ctx context.Context,
 	claimerEthKey *ecdsa.PrivateKey,
@@ -33,7 +35,26 @@ func CreateRelayClaimRequest(
 	forwarderAddr ethcommon.Address,
 	swap *contracts.SwapCreatorSwap,
 	secret *[32]byte,
+	fee *coins.WeiAmount,
 ) (*message.RelayClaimRequest, error) {
+	// relayers reject ERC20 swaps, so there's no point in signing a request
+	if err := validateClaimAsset(swap); err != nil {
+		return nil, err
+	}
+
+	// The fee is left unset when it is the default, so that relayers that
+	// predate fee queries can validate the signature.
+	if fee != nil && fee.BigInt().Cmp(coins.RelayerFeeWei) == 0 {
+		fee = nil
+	}
+
+	request := &message.RelayClaimRequest{
+		OfferID:         nil, // set elsewhere if sending to counterparty
+		SwapCreatorAddr: swapCreatorAddr,
+		Swap:            swap,
+		Secret:          secret[:],
+		Fee:             fee,
+	}
 
 	signature, err := createForwarderSignature(
 		ctx,
@@ -43,16 +64,12 @@ func CreateRelayClaimRequest(
 		forwarderAddr,
 		swap,
 		secret,
+		request.FeeWei(),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &message.RelayClaimRequest{
-		OfferID:         nil, // set elsewhere if sending to counterparty
-		SwapCreatorAddr: swapCreatorAddr,
-		Swap:            swap,
-		Secret:          secret[:],
-		Signature:       signature,
-	}, nil
+	request.Signature = signature
+	return request, nil
 }
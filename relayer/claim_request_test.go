@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/tests"
@@ -66,12 +67,30 @@ func TestCreateRelayClaimRequest(t *testing.T) {
 
 	// success path
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret, nil)
 	require.NoError(t, err)
 	require.NotNil(t, req)
+	require.Nil(t, req.Fee)
+
+	// the default fee is left unset for relayers that predate fee queries
+	req, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret,
+		coins.NewWeiAmount(coins.RelayerFeeWei))
+	require.NoError(t, err)
+	require.Nil(t, req.Fee)
+
+	fee := coins.NewWeiAmount(big.NewInt(5e15))
+	req, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret, fee)
+	require.NoError(t, err)
+	require.Equal(t, fee, req.Fee)
+	require.NoError(t, validateClaimSignature(ctx, ec, req))
 
 	// change the ethkey to not match the claimer address to trigger the error path
 	ethKey = tests.GetTakerTestKey(t)
-	_, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	_, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret, nil)
 	require.ErrorContains(t, err, "signing key does not match claimer")
+
+	// ERC20 swaps can't be relayed
+	swap.Asset = ethcommon.Address{0x1}
+	_, err = CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret, nil)
+	require.ErrorContains(t, err, "relaying for ETH Asset")
 }
@@ -16,7 +16,6 @@ import (
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
-	"github.com/athanorlabs/atomic-swap/coins"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 )
 
@@ -28,6 +27,7 @@ func createForwarderSignature(
 	forwarderAddr ethcommon.Address,
 	swap *contracts.SwapCreatorSwap,
 	secret *[32]byte,
+	feeWei *big.Int,
 ) ([]byte, error) {
 
 	if swap.Claimer != ethcrypto.PubkeyToAddress(claimerEthKey.PublicKey) {
@@ -49,6 +49,7 @@ func createForwarderSignature(
 		swapCreatorAddr,
 		swap,
 		secret,
+		feeWei,
 	)
 	if err != nil {
 		return nil, err
@@ -73,9 +74,10 @@ func createForwarderRequest(
 	swapCreatorAddr ethcommon.Address,
 	swap *contracts.SwapCreatorSwap,
 	secret *[32]byte,
+	feeWei *big.Int,
 ) (*gsnforwarder.IForwarderForwardRequest, error) {
 
-	calldata, err := getClaimRelayerTxCalldata(coins.RelayerFeeWei, swap, secret)
+	calldata, err := getClaimRelayerTxCalldata(feeWei, swap, secret)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,101 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRelayerBusy is returned by Limiter.Acquire when no relay slot became free
+// within the limiter's maximum wait.
+var ErrRelayerBusy = errors.New("relayer busy, try again later")
+
+// Limiter bounds the number of claims that are relayed concurrently, as each
+// relay simulates and sends a transaction from our account. Requests beyond
+// the maximum are queued for up to the maximum wait. A nil *Limiter doesn't
+// limit anything.
+type Limiter struct {
+	slots   chan struct{}
+	maxWait time.Duration
+	queued  atomic.Int32
+}
+
+// LimiterStatus holds the number of claims being relayed and waiting for a
+// free relay slot.
+type LimiterStatus struct {
+	Active uint32
+	Queued uint32
+	Max    uint32
+}
+
+// NewLimiter returns a Limiter that relays at most maxConcurrent claims at a
+// time, with requests waiting up to maxWait for a free slot. It returns nil if
+// maxConcurrent is zero.
+func NewLimiter(maxConcurrent uint32, maxWait time.Duration) *Limiter {
+	if maxConcurrent == 0 {
+		return nil
+	}
+
+	return &Limiter{
+		slots:   make(chan struct{}, maxConcurrent),
+		maxWait: maxWait,
+	}
+}
+
+// Acquire waits for a free relay slot and returns the function that frees it.
+// It returns ErrRelayerBusy if no slot became free within the maximum wait.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release = func() {
+		<-l.slots
+		activeRelays.Dec()
+	}
+
+	// don't queue if a slot is free
+	select {
+	case l.slots <- struct{}{}:
+		activeRelays.Inc()
+		return release, nil
+	default:
+	}
+
+	l.queued.Add(1)
+	queuedRelays.Inc()
+	defer func() {
+		l.queued.Add(-1)
+		queuedRelays.Dec()
+	}()
+
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		activeRelays.Inc()
+		return release, nil
+	case <-timer.C:
+		return nil, ErrRelayerBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Status returns the number of claims being relayed and waiting for a slot.
+func (l *Limiter) Status() *LimiterStatus {
+	if l == nil {
+		return nil
+	}
+
+	return &LimiterStatus{
+		Active: uint32(len(l.slots)),
+		Queued: uint32(l.queued.Load()),
+		Max:    uint32(cap(l.slots)),
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := NewLimiter(1, 50*time.Millisecond)
+
+	release, err := l.Acquire(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &LimiterStatus{Active: 1, Queued: 0, Max: 1}, l.Status())
+
+	// the only slot is taken, so the next request is rejected once its wait
+	// is over
+	_, err = l.Acquire(ctx)
+	require.ErrorIs(t, err, ErrRelayerBusy)
+	require.Equal(t, &LimiterStatus{Active: 1, Queued: 0, Max: 1}, l.Status())
+
+	// a queued request gets the slot when it is released
+	acquired := make(chan error)
+	l.maxWait = time.Minute
+	go func() {
+		releaseQueued, err := l.Acquire(ctx) //nolint:govet
+		if err == nil {
+			releaseQueued()
+		}
+		acquired <- err
+	}()
+
+	require.Eventually(t, func() bool { return l.Status().Queued == 1 }, time.Second, 5*time.Millisecond)
+	release()
+	require.NoError(t, <-acquired)
+	require.Equal(t, &LimiterStatus{Active: 0, Queued: 0, Max: 1}, l.Status())
+}
+
+func TestLimiter_unlimited(t *testing.T) {
+	l := NewLimiter(0, time.Second)
+	require.Nil(t, l)
+	require.Nil(t, l.Status())
+
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	relayResultSuccess = "success"
+	relayResultFailure = "failure"
+)
+
+var relayedClaims = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "swapd",
+		Name:      "relayer_claims_total",
+		Help:      "Number of claim requests submitted to us for relaying, by result",
+	},
+	[]string{"result"},
+)
+
+var activeRelays = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "swapd",
+		Name:      "relayer_active_claims",
+		Help:      "Number of claims being relayed",
+	},
+)
+
+var queuedRelays = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "swapd",
+		Name:      "relayer_queued_claims",
+		Help:      "Number of claim requests waiting for a free relay slot",
+	},
+)
+
+// MetricsCollectors returns the collectors of the relayer metrics.
+func MetricsCollectors() []prometheus.Collector {
+	return []prometheus.Collector{relayedClaims, activeRelays, queuedRelays}
+}
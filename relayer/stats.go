@@ -0,0 +1,59 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package relayer
+
+import (
+	"math/big"
+	"sync"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Stats holds the totals of the claim requests submitted to us for relaying
+// since swapd started.
+type Stats struct {
+	NumRelayed uint64
+	NumFailed  uint64
+	// FeesWei is the sum of the relayer fees of the claims that we relayed.
+	FeesWei *big.Int
+	// GasCostWei is the sum of the gas costs of the claims that we relayed.
+	GasCostWei *big.Int
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = Stats{
+		FeesWei:    new(big.Int),
+		GasCostWei: new(big.Int),
+	}
+)
+
+// GetStats returns a copy of the relayer's totals.
+func GetStats() *Stats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	return &Stats{
+		NumRelayed: stats.NumRelayed,
+		NumFailed:  stats.NumFailed,
+		FeesWei:    new(big.Int).Set(stats.FeesWei),
+		GasCostWei: new(big.Int).Set(stats.GasCostWei),
+	}
+}
+
+func recordRelayed(feeWei *big.Int, receipt *ethtypes.Receipt) {
+	gasCost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	stats.NumRelayed++
+	stats.FeesWei.Add(stats.FeesWei, feeWei)
+	stats.GasCostWei.Add(stats.GasCostWei, gasCost)
+}
+
+func recordFailed() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	stats.NumFailed++
+}
@@ -13,25 +13,73 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/ethereum/block"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
-// ValidateAndSendTransaction sends the relayed transaction to the network if it validates successfully.
+// ErrSwapAlreadyRelayed is returned by ValidateAndSendTransaction when we have
+// already relayed a claim of the request's swap.
+var ErrSwapAlreadyRelayed = errors.New("claim of swap was already relayed")
+
+// RelayedSwapsDB persists the IDs of the swaps whose claims we relayed. It is
+// implemented by *db.Database.
+type RelayedSwapsDB interface {
+	PutRelayedSwap(swapID types.Hash) error
+	HasRelayedSwap(swapID types.Hash) (bool, error)
+}
+
+// ValidateAndSendTransaction sends the relayed transaction to the network if it
+// validates successfully. Requests whose relayer fee is less than minFeeWei are
+// rejected. If relayedDB is not nil, requests for swaps that it holds are
+// rejected without querying the chain, and the swaps of relayed claims are
+// added to it.
 func ValidateAndSendTransaction(
 	ctx context.Context,
 	req *message.RelayClaimRequest,
 	ec extethclient.EthClient,
 	ourSFContractAddr ethcommon.Address,
+	minFeeWei *big.Int,
+	relayedDB RelayedSwapsDB,
 ) (*message.RelayClaimResponse, error) {
+	receipt, err := validateAndSendTransaction(ctx, req, ec, ourSFContractAddr, minFeeWei, relayedDB)
+	if err != nil {
+		relayedClaims.WithLabelValues(relayResultFailure).Inc()
+		recordFailed()
+		return nil, err
+	}
+
+	relayedClaims.WithLabelValues(relayResultSuccess).Inc()
+	recordRelayed(req.FeeWei(), receipt)
+	return &message.RelayClaimResponse{TxHash: receipt.TxHash}, nil
+}
 
-	err := validateClaimRequest(ctx, req, ec.Raw(), ourSFContractAddr)
+func validateAndSendTransaction(
+	ctx context.Context,
+	req *message.RelayClaimRequest,
+	ec extethclient.EthClient,
+	ourSFContractAddr ethcommon.Address,
+	minFeeWei *big.Int,
+	relayedDB RelayedSwapsDB,
+) (*ethtypes.Receipt, error) {
+	swapID := req.Swap.SwapID()
+	if relayedDB != nil {
+		relayed, err := relayedDB.HasRelayedSwap(swapID)
+		if err != nil {
+			return nil, err
+		}
+		if relayed {
+			return nil, fmt.Errorf("%w: %s", ErrSwapAlreadyRelayed, swapID)
+		}
+	}
+
+	err := validateClaimRequest(ctx, req, ec.Raw(), ourSFContractAddr, minFeeWei)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +107,7 @@ func ValidateAndSendTransaction(
 	// The size of request.Secret was vetted when it was deserialized
 	secret := (*[32]byte)(req.Secret)
 
-	forwarderReq, err := createForwarderRequest(nonce, req.SwapCreatorAddr, req.Swap, secret)
+	forwarderReq, err := createForwarderRequest(nonce, req.SwapCreatorAddr, req.Swap, secret, req.FeeWei())
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +162,15 @@ func ValidateAndSendTransaction(
 
 	log.Infof("relayed claim %s", common.ReceiptInfo(receipt))
 
-	return &message.RelayClaimResponse{TxHash: tx.Hash()}, nil
+	if relayedDB != nil {
+		// the claim was relayed, so failing to record it must not fail the
+		// request; the simulation still catches a repeated claim
+		if err = relayedDB.PutRelayedSwap(swapID); err != nil {
+			log.Warnf("failed to record relayed swap %s: %s", swapID, err)
+		}
+	}
+
+	return receipt, nil
 }
 
 // checkForMinClaimBalance verifies that we have enough gas to relay a claim and
@@ -177,7 +233,7 @@ func simulateExecute(
 		GasTipCap:  txOpts.GasTipCap,
 		Value:      txOpts.Value,
 		Data:       packed,
-		AccessList: []types.AccessTuple{},
+		AccessList: []ethtypes.AccessTuple{},
 	}
 
 	// Call the "execute" method
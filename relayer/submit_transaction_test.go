@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/dleq"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
@@ -20,6 +21,21 @@ import (
 	"github.com/athanorlabs/atomic-swap/tests"
 )
 
+// mockRelayedSwapsDB is an in-memory RelayedSwapsDB.
+type mockRelayedSwapsDB struct {
+	swapIDs map[types.Hash]struct{}
+}
+
+func (db *mockRelayedSwapsDB) PutRelayedSwap(swapID types.Hash) error {
+	db.swapIDs[swapID] = struct{}{}
+	return nil
+}
+
+func (db *mockRelayedSwapsDB) HasRelayedSwap(swapID types.Hash) (bool, error) {
+	_, has := db.swapIDs[swapID]
+	return has, nil
+}
+
 func Test_ValidateAndSendTransaction(t *testing.T) {
 	sk := tests.GetMakerTestKey(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -93,12 +109,20 @@ func Test_ValidateAndSendTransaction(t *testing.T) {
 	secret := proof.Secret()
 
 	// now let's try to claim
-	req, err := CreateRelayClaimRequest(ctx, sk, ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, sk, ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret, nil)
 	require.NoError(t, err)
 
-	resp, err := ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr)
+	relayedDB := &mockRelayedSwapsDB{swapIDs: make(map[types.Hash]struct{})}
+	statsBefore := GetStats()
+	resp, err := ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr, coins.RelayerFeeWei, relayedDB)
 	require.NoError(t, err)
 
+	stats := GetStats()
+	require.Equal(t, statsBefore.NumRelayed+1, stats.NumRelayed)
+	require.Equal(t, new(big.Int).Add(statsBefore.FeesWei, coins.RelayerFeeWei).String(), stats.FeesWei.String())
+	require.Equal(t, 1, stats.GasCostWei.Cmp(statsBefore.GasCostWei))
+	require.Contains(t, relayedDB.swapIDs, swap.SwapID())
+
 	receipt, err = block.WaitForReceipt(ctx, ec.Raw(), resp.TxHash)
 	require.NoError(t, err)
 	t.Logf("gas cost to call Claim via relayer: %d", receipt.GasUsed)
@@ -112,9 +136,16 @@ func Test_ValidateAndSendTransaction(t *testing.T) {
 
 	// Now lets try to claim a second time and verify that we fail on the simulated
 	// execution.
-	req, err = CreateRelayClaimRequest(ctx, sk, ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err = CreateRelayClaimRequest(ctx, sk, ec.Raw(), swapCreatorAddr, forwarderAddr, swap, &secret, nil)
 	require.NoError(t, err)
 
-	_, err = ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr)
+	_, err = ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr, coins.RelayerFeeWei, nil)
 	require.ErrorContains(t, err, "relayed transaction failed on simulation")
+	require.Equal(t, stats.NumFailed+1, GetStats().NumFailed)
+
+	// With the record of relayed swaps, the repeated claim is rejected before
+	// it is simulated.
+	_, err = ValidateAndSendTransaction(ctx, req, ec, swapCreatorAddr, coins.RelayerFeeWei, relayedDB)
+	require.ErrorIs(t, err, ErrSwapAlreadyRelayed)
+	require.Equal(t, stats.NumFailed+2, GetStats().NumFailed)
 }
@@ -5,11 +5,15 @@ package relayer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 
+	rcommon "github.com/athanorlabs/go-relayer/common"
 	"github.com/athanorlabs/go-relayer/impls/gsnforwarder"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/athanorlabs/atomic-swap/coins"
@@ -18,13 +22,23 @@ import (
 	"github.com/athanorlabs/atomic-swap/net/message"
 )
 
+var errInvalidClaimSignatureValues = errors.New("invalid claim request signature values")
+
 func validateClaimRequest(
 	ctx context.Context,
 	request *message.RelayClaimRequest,
 	ec *ethclient.Client,
 	ourSFContractAddr ethcommon.Address,
+	minFeeWei *big.Int,
 ) error {
-	err := validateClaimValues(ctx, request, ec, ourSFContractAddr)
+	// checked first, as it only needs the request, so that junk requests are
+	// rejected without any chain access
+	err := validateClaimSignatureValues(request)
+	if err != nil {
+		return err
+	}
+
+	err = validateClaimValues(ctx, request, ec, ourSFContractAddr, minFeeWei)
 	if err != nil {
 		return err
 	}
@@ -32,19 +46,61 @@ func validateClaimRequest(
 	return validateClaimSignature(ctx, ec, request)
 }
 
+// validateClaimSignatureValues checks that the claim request's signature is a
+// well-formed secp256k1 signature. The signer can only be recovered once the
+// forwarder's nonce and domain separator were read from the chain, which is
+// done by validateClaimSignature.
+func validateClaimSignatureValues(request *message.RelayClaimRequest) error {
+	const sigLen = 65
+	if len(request.Signature) != sigLen {
+		return fmt.Errorf("invalid claim request signature length %d", len(request.Signature))
+	}
+
+	r := new(big.Int).SetBytes(request.Signature[:32])
+	s := new(big.Int).SetBytes(request.Signature[32:64])
+	v := request.Signature[sigLen-1]
+	if v >= 27 {
+		v -= 27
+	}
+
+	if !ethcrypto.ValidateSignatureValues(v, r, s, true) {
+		return errInvalidClaimSignatureValues
+	}
+
+	return nil
+}
+
+// validateClaimAsset returns an error if the swap is not for ETH, as relayers
+// can't be paid their fee out of an ERC20 swap.
+func validateClaimAsset(swap *contracts.SwapCreatorSwap) error {
+	asset := types.EthAsset(swap.Asset)
+	if asset != types.EthAssetETH {
+		return fmt.Errorf("relaying for ETH Asset %s is not supported", asset)
+	}
+
+	return nil
+}
+
 // validateClaimValues validates the non-signature aspects of the claim request:
-//  1. the claim request's swap creator and forwarder contract bytecode matches ours
-//  2. the swap is for ETH and not an ERC20 token
-//  3. the swap value is strictly greater than the relayer fee
-//  4. TODO: Validate that the swap exists and is in a claimable state?
+//  1. the swap is for ETH and not an ERC20 token
+//  2. the claim request's swap creator and forwarder contract bytecode matches ours
+//  3. the relayer fee is at least minFeeWei
+//  4. the swap value is strictly greater than the relayer fee
+//  5. TODO: Validate that the swap exists and is in a claimable state?
 func validateClaimValues(
 	ctx context.Context,
 	request *message.RelayClaimRequest,
 	ec *ethclient.Client,
 	ourSwapCreatorAddr ethcommon.Address,
+	minFeeWei *big.Int,
 ) error {
 	isTakerRelay := request.OfferID != nil
 
+	// checked first, as it doesn't need the chain
+	if err := validateClaimAsset(request.Swap); err != nil {
+		return err
+	}
+
 	// Validate the deployed SwapCreator contract, if it is not at the same address
 	// as our own. The CheckSwapCreatorContractCode method validates both the
 	// SwapCreator bytecode and the Forwarder bytecode.
@@ -59,22 +115,25 @@ func validateClaimValues(
 		}
 	}
 
-	asset := types.EthAsset(request.Swap.Asset)
-	if asset != types.EthAssetETH {
-		return fmt.Errorf("relaying for ETH Asset %s is not supported", asset)
+	feeWei := request.FeeWei()
+	if feeWei.Cmp(minFeeWei) < 0 {
+		return fmt.Errorf("relayer fee of %s ETH is below our fee of %s ETH",
+			coins.FmtWeiAsETH(feeWei), coins.FmtWeiAsETH(minFeeWei))
 	}
 
 	// The relayer fee must be strictly less than the swap value
-	if coins.RelayerFeeWei.Cmp(request.Swap.Value) >= 0 {
+	if feeWei.Cmp(request.Swap.Value) >= 0 {
 		return fmt.Errorf("swap value of %s ETH is too low to support %s ETH relayer fee",
-			coins.FmtWeiAsETH(request.Swap.Value), coins.FmtWeiAsETH(coins.RelayerFeeWei))
+			coins.FmtWeiAsETH(request.Swap.Value), coins.FmtWeiAsETH(feeWei))
 	}
 
 	return nil
 }
 
 // validateClaimSignature validates the claim signature. It is assumed that the
-// request fields have already been validated.
+// request fields have already been validated. The signer is compared to the
+// swap's claimer as soon as the data needed to recover it was read from the
+// chain, before the forwarder is asked to verify the signature.
 func validateClaimSignature(
 	ctx context.Context,
 	ec *ethclient.Client,
@@ -112,11 +171,17 @@ func validateClaimSignature(
 		request.SwapCreatorAddr,
 		request.Swap,
 		secret,
+		request.FeeWei(),
 	)
 	if err != nil {
 		return err
 	}
 
+	err = validateClaimSigner(forwarderRequest, *domainSeparator, request)
+	if err != nil {
+		return err
+	}
+
 	err = forwarder.Verify(
 		callOpts,
 		*forwarderRequest,
@@ -131,3 +196,44 @@ func validateClaimSignature(
 
 	return nil
 }
+
+// validateClaimSigner recovers the address that signed the forward request
+// from the request's signature and checks that it is the swap's claimer. Unlike
+// the forwarder's verification, it doesn't need the chain, and its error tells
+// a request signed by someone else apart from a malformed signature.
+func validateClaimSigner(
+	forwarderRequest *gsnforwarder.IForwarderForwardRequest,
+	domainSeparator [32]byte,
+	request *message.RelayClaimRequest,
+) error {
+	const sigLen = 65
+	if len(request.Signature) != sigLen {
+		return fmt.Errorf("invalid claim request signature length %d", len(request.Signature))
+	}
+
+	digest, err := rcommon.GetForwardRequestDigestToSign(forwarderRequest, domainSeparator, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get forward request digest: %w", err)
+	}
+
+	// the recovery ID is 27 or 28 in signatures for ecrecover, but 0 or 1 is
+	// expected by SigToPub
+	sig := make([]byte, sigLen)
+	copy(sig, request.Signature)
+	if sig[sigLen-1] >= 27 {
+		sig[sigLen-1] -= 27
+	}
+
+	pubKey, err := ethcrypto.SigToPub(digest[:], sig)
+	if err != nil {
+		return fmt.Errorf("invalid claim request signature: %w", err)
+	}
+
+	signer := ethcrypto.PubkeyToAddress(*pubKey)
+	if signer != request.Swap.Claimer {
+		return fmt.Errorf("claim request signature is from %s, not the swap's claimer %s",
+			signer, request.Swap.Claimer)
+	}
+
+	return nil
+}
@@ -30,6 +30,7 @@ func TestValidateRelayerFee(t *testing.T) {
 	type testCase struct {
 		description string
 		value       *big.Int
+		fee         *coins.WeiAmount
 		expectErr   string
 	}
 
@@ -48,6 +49,23 @@ func TestValidateRelayerFee(t *testing.T) {
 			description: "swap value larger than min fee",
 			value:       new(big.Int).Add(coins.RelayerFeeWei, big.NewInt(1e15)),
 		},
+		{
+			description: "relayer fee below our fee",
+			value:       big.NewInt(1e18),
+			fee:         coins.NewWeiAmount(big.NewInt(8e15)),
+			expectErr:   "relayer fee of 0.008 ETH is below our fee of 0.009 ETH",
+		},
+		{
+			description: "relayer fee above our fee",
+			value:       big.NewInt(1e18),
+			fee:         coins.NewWeiAmount(big.NewInt(1e16)),
+		},
+		{
+			description: "swap value less than higher relayer fee",
+			value:       big.NewInt(1e16),
+			fee:         coins.NewWeiAmount(big.NewInt(1e16)),
+			expectErr:   "swap value of 0.01 ETH is too low to support 0.01 ETH relayer fee",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -67,9 +85,10 @@ func TestValidateRelayerFee(t *testing.T) {
 			SwapCreatorAddr: swapCreatorAddr,
 			Swap:            swap,
 			Secret:          make([]byte, 32),
+			Fee:             tc.fee,
 		}
 
-		err := validateClaimValues(ctx, request, ec, swapCreatorAddr)
+		err := validateClaimValues(ctx, request, ec, swapCreatorAddr, coins.RelayerFeeWei)
 		if tc.expectErr != "" {
 			require.ErrorContains(t, err, tc.expectErr, tc.description)
 		} else {
@@ -93,7 +112,7 @@ func Test_validateClaimValues_takerClaim_contractAddressNotEqualFail(t *testing.
 		Swap:            new(contracts.SwapCreatorSwap), // test fails before we validate this
 	}
 
-	err := validateClaimValues(context.Background(), request, nil, swapCreatorAddrOurs)
+	err := validateClaimValues(context.Background(), request, nil, swapCreatorAddrOurs, coins.RelayerFeeWei)
 	require.ErrorContains(t, err, "taker claim swap creator mismatch")
 }
 
@@ -112,7 +131,7 @@ func Test_validateClaimValues_dhtClaim_contractAddressNotEqual(t *testing.T) {
 		Swap:            new(contracts.SwapCreatorSwap), // test fails before we validate this
 	}
 
-	err := validateClaimValues(context.Background(), request, ec, swapCreatorAddr)
+	err := validateClaimValues(context.Background(), request, ec, swapCreatorAddr, coins.RelayerFeeWei)
 	require.ErrorContains(t, err, "contract address does not contain correct SwapCreator code")
 }
 
@@ -125,17 +144,29 @@ func Test_validateSignature(t *testing.T) {
 	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret, nil)
 	require.NoError(t, err)
 
 	// success path
 	err = validateClaimSignature(ctx, ec, req)
 	require.NoError(t, err)
 
+	// failure path (the claimer is not the signer)
+	otherClaimer := ethcommon.Address{0x1}
+	req.Swap.Claimer = otherClaimer
+	err = validateClaimSignature(ctx, ec, req)
+	require.ErrorContains(t, err, fmt.Sprintf("not the swap's claimer %s", otherClaimer))
+	req.Swap.Claimer = claimer
+
 	// failure path (tamper with an arbitrary byte of the signature)
 	req.Signature[10]++
 	err = validateClaimSignature(ctx, ec, req)
-	require.ErrorContains(t, err, "failed to verify signature")
+	require.ErrorContains(t, err, "claim request signature")
+
+	// failure path (truncated signature)
+	req.Signature = req.Signature[:64]
+	err = validateClaimSignature(ctx, ec, req)
+	require.ErrorContains(t, err, "invalid claim request signature length 64")
 }
 
 func Test_validateClaimRequest(t *testing.T) {
@@ -147,16 +178,34 @@ func Test_validateClaimRequest(t *testing.T) {
 	swapCreatorAddr, forwarderAddr := deployContracts(t, ec, ethKey)
 
 	swap := createTestSwap(claimer)
-	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret)
+	req, err := CreateRelayClaimRequest(ctx, ethKey, ec, swapCreatorAddr, forwarderAddr, swap, &secret, nil)
 	require.NoError(t, err)
 
 	// success path
-	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr)
+	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr, coins.RelayerFeeWei)
 	require.NoError(t, err)
 
 	// test failure path by passing a non-eth asset
 	asset := ethcommon.Address{0x1}
 	req.Swap.Asset = asset
-	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr)
+	err = validateClaimRequest(ctx, req, ec, swapCreatorAddr, coins.RelayerFeeWei)
 	require.ErrorContains(t, err, fmt.Sprintf("relaying for ETH Asset %s is not supported", types.EthAsset(asset)))
 }
+
+func Test_validateClaimRequest_junkSignature(t *testing.T) {
+	swapCreatorAddr := ethcommon.Address{0x1}
+	req := &message.RelayClaimRequest{
+		SwapCreatorAddr: ethcommon.Address{0x2}, // would need the chain to validate
+		Swap:            createTestSwap(ethcommon.Address{0x3}),
+		Secret:          make([]byte, 32),
+		Signature:       make([]byte, 65),
+	}
+
+	// the client is nil, as junk signatures are rejected without chain access
+	err := validateClaimRequest(context.Background(), req, nil, swapCreatorAddr, coins.RelayerFeeWei)
+	require.ErrorIs(t, err, errInvalidClaimSignatureValues)
+
+	req.Signature = make([]byte, 64)
+	err = validateClaimRequest(context.Background(), req, nil, swapCreatorAddr, coins.RelayerFeeWei)
+	require.ErrorContains(t, err, "invalid claim request signature length 64")
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// requireAuthToken returns a handler that only passes requests to next if their
+// Authorization header holds the token, either as a bearer token or as the
+// password of basic authentication with any user name. Other requests are
+// rejected with 401 Unauthorized.
+func requireAuthToken(token string, next http.Handler) http.Handler {
+	// comparing hashes keeps the comparison constant time regardless of the
+	// length of the presented token
+	tokenHash := sha256.Sum256([]byte(token))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := requestAuthToken(r)
+		presentedHash := sha256.Sum256([]byte(presented))
+		if !ok || subtle.ConstantTimeCompare(presentedHash[:], tokenHash[:]) != 1 {
+			log.Debugf("rejected unauthorized %s request to %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="swapd"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestAuthToken returns the token in the request's Authorization header.
+func requestAuthToken(r *http.Request) (string, bool) {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password, true
+	}
+
+	const bearerPrefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(bearerPrefix) || !strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+
+	return auth[len(bearerPrefix):], true
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAuthToken(t *testing.T) {
+	const token = "s3cret"
+	handler := requireAuthToken(token, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(setAuth func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		setAuth(req)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) })
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(func(r *http.Request) { r.SetBasicAuth("anyone", token) })
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	for _, setAuth := range []func(r *http.Request){
+		func(r *http.Request) {},
+		func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") },
+		func(r *http.Request) { r.Header.Set("Authorization", token) },
+		func(r *http.Request) { r.SetBasicAuth(token, "") },
+	} {
+		rec = serve(setAuth)
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		require.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+	}
+}
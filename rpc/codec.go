@@ -14,8 +14,17 @@ import (
 	"github.com/gorilla/rpc/v2/json2"
 )
 
+// observerMethods are the methods outside of the net namespace that are
+// served in observer mode, as they don't need a wallet.
+var observerMethods = map[string]struct{}{
+	"daemon.Shutdown":            {},
+	"swap.SuggestedExchangeRate": {},
+}
+
 // Codec ...
-type Codec struct{}
+type Codec struct {
+	isObserver bool
+}
 
 // NewCodec ...
 func NewCodec() *Codec {
@@ -24,7 +33,7 @@ func NewCodec() *Codec {
 
 // NewRequest ...
 func (c *Codec) NewRequest(req *http.Request) rpc.CodecRequest {
-	outer := &CodecRequest{}
+	outer := &CodecRequest{isObserver: c.isObserver}
 	inner := json2.NewCodec().NewRequest(req)
 	outer.CodecRequest = inner.(*json2.CodecRequest)
 	return outer
@@ -33,6 +42,7 @@ func (c *Codec) NewRequest(req *http.Request) rpc.CodecRequest {
 // CodecRequest ...
 type CodecRequest struct {
 	*json2.CodecRequest
+	isObserver bool
 }
 
 // Method ...
@@ -50,8 +60,15 @@ func (cr *CodecRequest) Method() (string, error) {
 	service, method := parts[0], parts[1]
 	r, n := utf8.DecodeRuneInString(method)
 	if unicode.IsLower(r) {
-		return fmt.Sprintf("%s.%s%s", service, string(unicode.ToUpper(r)), method[n:]), nil
+		method = string(unicode.ToUpper(r)) + method[n:]
+	}
+
+	serviceMethod := fmt.Sprintf("%s.%s", service, method)
+	if cr.isObserver && service != NetNamespace {
+		if _, ok := observerMethods[serviceMethod]; !ok {
+			return "", errObserverMode
+		}
 	}
 
-	return fmt.Sprintf("%s.%s", service, method), nil
+	return serviceMethod, nil
 }
@@ -0,0 +1,43 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCodecRequest(codec *Codec, method string) *CodecRequest {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":"0","method":%q,"params":{}}`, method)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	return codec.NewRequest(req).(*CodecRequest)
+}
+
+func TestCodecRequest_Method(t *testing.T) {
+	method, err := newTestCodecRequest(NewCodec(), "personal_balances").Method()
+	require.NoError(t, err)
+	require.Equal(t, "personal.Balances", method)
+
+	_, err = newTestCodecRequest(NewCodec(), "balances").Method()
+	require.ErrorContains(t, err, "invalid method balances")
+}
+
+func TestCodecRequest_Method_observer(t *testing.T) {
+	codec := &Codec{isObserver: true}
+
+	for _, method := range []string{"net_queryAll", "swap_suggestedExchangeRate", "daemon_shutdown"} {
+		_, err := newTestCodecRequest(codec, method).Method()
+		require.NoError(t, err, method)
+	}
+
+	for _, method := range []string{"personal_balances", "swap_getPast", "database_getContractSwapInfo"} {
+		_, err := newTestCodecRequest(codec, method).Method()
+		require.ErrorIs(t, err, errObserverMode, method)
+	}
+}
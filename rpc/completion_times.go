@@ -0,0 +1,188 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+// CompletionTimeStats summarises the completion times of a set of swaps.
+// Percentiles use the nearest-rank method.
+type CompletionTimeStats struct {
+	NumSwaps uint64        `json:"numSwaps"`
+	Average  time.Duration `json:"average"`
+	P50      time.Duration `json:"p50"`
+	P90      time.Duration `json:"p90"`
+	P99      time.Duration `json:"p99"`
+}
+
+// AssetCompletionTimes holds the completion times of the swaps of one asset,
+// overall and by final status.
+type AssetCompletionTimes struct {
+	All      *CompletionTimeStats                  `json:"all"`
+	ByStatus map[types.Status]*CompletionTimeStats `json:"byStatus"`
+}
+
+// completionTimes caches the durations of our past swaps, grouped by asset and
+// final status. The swap history is only read from the database the first
+// time the durations are needed, after which swaps are added as they complete.
+type completionTimes struct {
+	mu        sync.Mutex
+	sm        SwapManager
+	loaded    bool
+	counted   map[types.Hash]struct{}
+	durations map[types.EthAsset]map[types.Status][]time.Duration // sorted
+
+	// Swaps completed since the last query. They are collected separately,
+	// as the listener is called with the swap manager's lock held, which
+	// must not be taken while holding mu.
+	pendingMu sync.Mutex
+	pending   []*swap.Info
+}
+
+func newCompletionTimes(sm SwapManager) *completionTimes {
+	return &completionTimes{
+		sm:        sm,
+		counted:   make(map[types.Hash]struct{}),
+		durations: make(map[types.EthAsset]map[types.Status][]time.Duration),
+	}
+}
+
+// add inserts the swap's duration, unless it was already included or the swap
+// has not completed. The caller must hold mu.
+func (c *completionTimes) add(info *swap.Info) {
+	if info.Status.IsOngoing() || info.EndTime == nil {
+		return
+	}
+
+	if _, has := c.counted[info.OfferID]; has {
+		return
+	}
+
+	duration := info.EndTime.Sub(info.StartTime)
+	if duration < 0 {
+		return
+	}
+
+	c.counted[info.OfferID] = struct{}{}
+	byStatus, has := c.durations[info.EthAsset]
+	if !has {
+		byStatus = make(map[types.Status][]time.Duration)
+		c.durations[info.EthAsset] = byStatus
+	}
+
+	sorted := byStatus[info.Status]
+	i := sort.Search(len(sorted), func(i int) bool { return sorted[i] >= duration })
+	sorted = append(sorted, 0)
+	copy(sorted[i+1:], sorted[i:])
+	sorted[i] = duration
+	byStatus[info.Status] = sorted
+}
+
+// record queues a completed swap to be added on the next query.
+func (c *completionTimes) record(info *swap.Info) {
+	if info.Status.IsOngoing() {
+		return
+	}
+
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	c.pending = append(c.pending, info)
+}
+
+// load reads the swap history from the database, if it was not read yet, and
+// adds any swaps that completed since. The caller must hold mu.
+func (c *completionTimes) load() error {
+	if !c.loaded {
+		// the listener is added first, so no swap completing while the
+		// history is read is missed
+		c.sm.AddStatusListener(c.record)
+
+		swaps, err := c.sm.GetPastSwaps(nil, 0, 0)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range swaps {
+			c.add(info)
+		}
+
+		c.loaded = true
+	}
+
+	c.pendingMu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.pendingMu.Unlock()
+
+	for _, info := range pending {
+		c.add(info)
+	}
+
+	return nil
+}
+
+// stats returns the completion times of our past swaps by asset.
+func (c *completionTimes) stats() (map[types.EthAsset]*AssetCompletionTimes, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	assets := make(map[types.EthAsset]*AssetCompletionTimes, len(c.durations))
+	for asset, byStatus := range c.durations {
+		var all []time.Duration
+		times := &AssetCompletionTimes{
+			ByStatus: make(map[types.Status]*CompletionTimeStats, len(byStatus)),
+		}
+
+		for status, sorted := range byStatus {
+			times.ByStatus[status] = newCompletionTimeStats(sorted)
+			all = append(all, sorted...)
+		}
+
+		sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+		times.All = newCompletionTimeStats(all)
+		assets[asset] = times
+	}
+
+	return assets, nil
+}
+
+// newCompletionTimeStats returns the stats of the given durations, which must
+// be sorted in ascending order.
+func newCompletionTimeStats(sorted []time.Duration) *CompletionTimeStats {
+	stats := &CompletionTimeStats{NumSwaps: uint64(len(sorted))}
+	if len(sorted) == 0 {
+		return stats
+	}
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	stats.Average = total / time.Duration(len(sorted))
+	stats.P50 = percentile(sorted, 50)
+	stats.P90 = percentile(sorted, 90)
+	stats.P99 = percentile(sorted, 99)
+	return stats
+}
+
+// percentile returns the p-th percentile of the non-empty sorted durations,
+// using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
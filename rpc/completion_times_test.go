@@ -0,0 +1,83 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+type completionTimesSwapManager struct {
+	mockSwapManager
+	past []*swap.Info
+}
+
+func (m *completionTimesSwapManager) GetPastSwaps(_ swap.PastSwapFilter, _, _ uint) ([]*swap.Info, error) {
+	return m.past, nil
+}
+
+func TestSwap_CompletionTimes(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	token := types.EthAsset(ethcommon.Address{0x1})
+	newInfo := func(id byte, asset types.EthAsset, status types.Status, minutes int) *swap.Info {
+		end := start.Add(time.Duration(minutes) * time.Minute)
+		return &swap.Info{
+			OfferID:   types.Hash{id},
+			EthAsset:  asset,
+			Status:    status,
+			StartTime: start,
+			EndTime:   &end,
+		}
+	}
+
+	sm := &completionTimesSwapManager{
+		past: []*swap.Info{
+			newInfo(1, types.EthAssetETH, types.CompletedSuccess, 10),
+			newInfo(2, types.EthAssetETH, types.CompletedSuccess, 20),
+			newInfo(3, types.EthAssetETH, types.CompletedRefund, 60),
+			newInfo(4, token, types.CompletedSuccess, 30),
+		},
+	}
+	ss := NewSwapService(
+		context.Background(),
+		sm,
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(CompletionTimesResponse)
+	require.NoError(t, ss.CompletionTimes(nil, nil, resp))
+	require.Len(t, resp.Assets, 2)
+
+	eth := resp.Assets[types.EthAssetETH]
+	require.Equal(t, &CompletionTimeStats{
+		NumSwaps: 3,
+		Average:  30 * time.Minute,
+		P50:      20 * time.Minute,
+		P90:      60 * time.Minute,
+		P99:      60 * time.Minute,
+	}, eth.All)
+	require.Equal(t, uint64(2), eth.ByStatus[types.CompletedSuccess].NumSwaps)
+	require.Equal(t, 15*time.Minute, eth.ByStatus[types.CompletedSuccess].Average)
+	require.Equal(t, 10*time.Minute, eth.ByStatus[types.CompletedSuccess].P50)
+	require.Equal(t, 30*time.Minute, resp.Assets[token].All.Average)
+
+	// swaps completing after the history was read are added, and swaps
+	// already included are not counted twice
+	require.True(t, sm.notify(newInfo(5, token, types.CompletedSuccess, 50)))
+	require.True(t, sm.notify(newInfo(4, token, types.CompletedSuccess, 30)))
+	require.NoError(t, ss.CompletionTimes(nil, nil, resp))
+	require.Equal(t, uint64(2), resp.Assets[token].All.NumSwaps)
+	require.Equal(t, 40*time.Minute, resp.Assets[token].All.Average)
+}
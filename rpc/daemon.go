@@ -1,46 +1,366 @@
 package rpc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/cockroachdb/apd/v3"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 
 	"github.com/athanorlabs/atomic-swap/cliutil"
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
 	"github.com/athanorlabs/atomic-swap/net"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
+const (
+	// defaultDrainTimeout is how long a draining shutdown waits for ongoing
+	// swaps to complete if the request doesn't set a timeout.
+	defaultDrainTimeout = 10 * time.Minute
+
+	// MaxDrainTimeout is the longest timeout a draining shutdown accepts. It
+	// stays below the 30 minute request timeout of rpcclient, so that swapcli
+	// still gets the response when draining times out.
+	MaxDrainTimeout = 25 * time.Minute
+)
+
+// drainPollInterval is how often the ongoing swaps are checked while draining.
+var drainPollInterval = 5 * time.Second
+
 // DaemonService handles RPC requests for swapd version, administration and (in the future) status requests.
 type DaemonService struct {
 	stopServer func()
 	pb         ProtocolBackend
+	net        Net            // nil if swapd has no peer-to-peer host
+	wsConns    *wsConnCounter // nil if there is no websocket server
 }
 
 // NewDaemonService ...
 func NewDaemonService(stopServer func(), pb ProtocolBackend) *DaemonService {
-	return &DaemonService{stopServer, pb}
+	return &DaemonService{stopServer: stopServer, pb: pb}
+}
+
+// ShutdownRequest ...
+type ShutdownRequest struct {
+	// Drain, if set, stops new swaps and the publishing of our offers, then
+	// waits for the ongoing swaps to complete before shutting down.
+	Drain bool `json:"drain,omitempty"`
+	// Timeout is the maximum number of seconds to wait for ongoing swaps when
+	// draining. If zero, defaultDrainTimeout is used. It must not be more than
+	// MaxDrainTimeout.
+	Timeout uint64 `json:"timeout,omitempty"`
+}
+
+// ShutdownResponse ...
+type ShutdownResponse struct {
+	// NumOngoingSwaps is the number of swaps that were still ongoing when
+	// draining timed out. They are resumed when swapd is restarted.
+	NumOngoingSwaps int `json:"numOngoingSwaps"`
 }
 
-// Shutdown swapd
-func (s *DaemonService) Shutdown(_ *http.Request, _ *any, _ *any) error {
+// Shutdown swapd. If draining is requested, swapd waits for its ongoing swaps
+// to complete, up to the timeout, before shutting down.
+func (s *DaemonService) Shutdown(_ *http.Request, req *ShutdownRequest, resp *ShutdownResponse) error {
+	// bootnodes have no swaps to drain
+	if req.Drain && s.pb != nil {
+		if req.Timeout > uint64(MaxDrainTimeout/time.Second) {
+			return fmt.Errorf("%w, max is %d seconds", errDrainTimeoutTooLong, uint64(MaxDrainTimeout/time.Second))
+		}
+
+		timeout := time.Duration(req.Timeout) * time.Second
+		if timeout == 0 {
+			timeout = defaultDrainTimeout
+		}
+
+		// New swaps are already blocked and our offers are no longer published
+		// when drain fails, which can't be undone, so we still shut down.
+		numOngoing, err := s.drain(timeout)
+		if err != nil {
+			s.stopServer()
+			return fmt.Errorf("shut down without waiting for ongoing swaps: %w", err)
+		}
+		resp.NumOngoingSwaps = numOngoing
+	}
+
 	s.stopServer()
 	return nil
 }
 
+// drain blocks new swaps and stops publishing our offers, then waits until
+// there are no ongoing swaps or the timeout passes. It returns the number of
+// swaps that are still ongoing.
+func (s *DaemonService) drain(timeout time.Duration) (int, error) {
+	sm := s.pb.SwapManager()
+	sm.BlockNewSwaps()
+	if s.net != nil {
+		s.net.StopOffers()
+	}
+
+	ctx, cancel := context.WithTimeout(s.pb.Ctx(), timeout)
+	defer cancel()
+
+	for {
+		ongoing, err := sm.GetOngoingSwaps()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get ongoing swaps: %w", err)
+		}
+
+		if len(ongoing) == 0 {
+			return 0, nil
+		}
+
+		log.Infof("waiting for %d ongoing swap(s) to complete before shutting down", len(ongoing))
+		if err = common.SleepWithContext(ctx, drainPollInterval); err != nil {
+			log.Warnf("shutting down with %d ongoing swap(s), they will resume on restart", len(ongoing))
+			return len(ongoing), nil
+		}
+	}
+}
+
 // VersionResponse ...
 type VersionResponse struct {
 	SwapdVersion    string             `json:"swapdVersion" validate:"required"`
 	P2PVersion      string             `json:"p2pVersion" validate:"required"`
 	Env             common.Environment `json:"env" validate:"required"`
 	SwapCreatorAddr ethcommon.Address  `json:"swapCreatorAddress" validate:"required"`
+	Forwarder       *ForwarderResponse `json:"forwarder" validate:"required"`
 }
 
 // Version returns version & misc info about swapd and its dependencies
 func (s *DaemonService) Version(_ *http.Request, _ *any, resp *VersionResponse) error {
+	// the version info is still returned if the forwarder could not be checked
+	forwarder, err := s.forwarder()
+	if err != nil {
+		forwarder = &ForwarderResponse{
+			InvalidReason: fmt.Sprintf("failed to verify forwarder: %s", err),
+		}
+	}
+
 	resp.SwapdVersion = cliutil.GetVersion()
 	resp.P2PVersion = fmt.Sprintf("%s/%d", net.ProtocolID, s.pb.ETHClient().ChainID())
 	resp.Env = s.pb.Env()
 	resp.SwapCreatorAddr = s.pb.SwapCreatorAddr()
+	resp.Forwarder = forwarder
+	return nil
+}
+
+// ForwarderResponse ...
+type ForwarderResponse struct {
+	ForwarderAddr ethcommon.Address `json:"forwarderAddress"`
+	IsValid       bool              `json:"isValid"`
+	// InvalidReason is only set if IsValid is false
+	InvalidReason string `json:"invalidReason,omitempty"`
+}
+
+// Forwarder returns the address of the trusted forwarder used by the
+// SwapCreator contract for relayed claims, and whether it contains the expected
+// forwarder code.
+func (s *DaemonService) Forwarder(_ *http.Request, _ *any, resp *ForwarderResponse) error {
+	forwarder, err := s.forwarder()
+	if err != nil {
+		return err
+	}
+
+	*resp = *forwarder
+	return nil
+}
+
+func (s *DaemonService) forwarder() (*ForwarderResponse, error) {
+	forwarderAddr, err := contracts.VerifyTrustedForwarder(s.pb.Ctx(), s.pb.ETHClient().Raw(), s.pb.SwapCreatorAddr())
+	if errors.Is(err, contracts.ErrInvalidForwarderContract) {
+		return &ForwarderResponse{
+			ForwarderAddr: forwarderAddr,
+			InvalidReason: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForwarderResponse{
+		ForwarderAddr: forwarderAddr,
+		IsValid:       true,
+	}, nil
+}
+
+// GasBalanceHealth reports whether our ETH balance is below the configured
+// minimum gas balance.
+type GasBalanceHealth struct {
+	WeiBalance    *coins.WeiAmount `json:"weiBalance" validate:"required"`
+	MinWeiBalance *coins.WeiAmount `json:"minWeiBalance" validate:"required"`
+	IsLow         bool             `json:"isLow"`
+	CheckedAt     time.Time        `json:"checkedAt" validate:"required"`
+}
+
+// WsConnectionsHealth contains the number of open websocket connections and
+// the configured maximum, which is zero if there is no limit.
+type WsConnectionsHealth struct {
+	Count uint32 `json:"count"`
+	Max   uint32 `json:"max"`
+}
+
+// EthSyncStatusResponse ...
+type EthSyncStatusResponse struct {
+	// IsSynced is true if the Ethereum node is not syncing
+	IsSynced bool `json:"isSynced"`
+	// HeadBlock is the number of the latest block of the Ethereum node
+	HeadBlock uint64 `json:"headBlock"`
+	// HighestBlock is the number of the block that the node is syncing to,
+	// which is HeadBlock once the node is synced
+	HighestBlock uint64 `json:"highestBlock"`
+	ChainID      uint64 `json:"chainID"`
+}
+
+// EthSyncStatus returns how far the Ethereum node used by swapd is synced.
+// Swaps should not be started while it is syncing, as the contract logs and
+// block headers that swaps watch are not reliable until it's done.
+func (s *DaemonService) EthSyncStatus(_ *http.Request, _ *any, resp *EthSyncStatusResponse) error {
+	ec := s.pb.ETHClient()
+	status, err := ec.SyncStatus(s.pb.Ctx())
+	if err != nil {
+		return fmt.Errorf("failed to get ethereum sync status: %w", err)
+	}
+
+	*resp = EthSyncStatusResponse{
+		IsSynced:     status.IsSynced,
+		HeadBlock:    status.HeadBlock,
+		HighestBlock: status.HighestBlock,
+		ChainID:      ec.ChainID().Uint64(),
+	}
+	return nil
+}
+
+// RelayQueueHealth contains the number of claims being relayed and waiting for
+// a free relay slot, and the maximum number relayed at a time.
+type RelayQueueHealth struct {
+	Active uint32 `json:"active"`
+	Queued uint32 `json:"queued"`
+	Max    uint32 `json:"max"`
+}
+
+// RelayerStatsResponse ...
+type RelayerStatsResponse struct {
+	// IsRelayer is true if we relay claims for everyone, instead of only for
+	// our swap counterparties.
+	IsRelayer bool `json:"isRelayer"`
+	// Fee is the fee that we charge per relayed claim, in ETH. It is only set
+	// if IsRelayer is true.
+	Fee        *apd.Decimal `json:"fee,omitempty"`
+	NumRelayed uint64       `json:"numRelayed"`
+	NumFailed  uint64       `json:"numFailed"`
+	// TotalFees and TotalGasCost are the sums, in ETH, of the fees earned
+	// and the gas paid for the claims that we relayed. Earnings is the
+	// difference, which is negative if the gas cost more than the fees.
+	TotalFees    *apd.Decimal `json:"totalFees" validate:"required"`
+	TotalGasCost *apd.Decimal `json:"totalGasCost" validate:"required"`
+	Earnings     *apd.Decimal `json:"earnings" validate:"required"`
+}
+
+// RelayerStats returns the totals of the claims that we relayed since swapd
+// started, which include the claims relayed for our swap counterparties.
+func (s *DaemonService) RelayerStats(_ *http.Request, _ *any, resp *RelayerStatsResponse) error {
+	if s.net != nil {
+		if fee := s.net.RelayerFee(); fee != nil {
+			resp.IsRelayer = true
+			resp.Fee = fee.AsEther()
+		}
+	}
+
+	stats := relayer.GetStats()
+	resp.NumRelayed = stats.NumRelayed
+	resp.NumFailed = stats.NumFailed
+	resp.TotalFees = coins.NewWeiAmount(stats.FeesWei).AsEther()
+	resp.TotalGasCost = coins.NewWeiAmount(stats.GasCostWei).AsEther()
+
+	resp.Earnings = new(apd.Decimal)
+	_, err := coins.DecimalCtx().Sub(resp.Earnings, resp.TotalFees, resp.TotalGasCost)
+	return err
+}
+
+// HealthResponse ...
+type HealthResponse struct {
+	// IsHealthy is true if all the subsystems below are healthy
+	IsHealthy bool `json:"isHealthy"`
+	// MoneroWalletReachable is true if the monero-wallet-rpc server responded
+	MoneroWalletReachable bool `json:"moneroWalletReachable"`
+	// EthereumSynced is true if the Ethereum node responded and is not syncing
+	EthereumSynced bool `json:"ethereumSynced"`
+	// NumPeers is the number of connected peers, which is not included in
+	// IsHealthy, as a node that just started may not have any peers yet.
+	NumPeers int `json:"numPeers"`
+	// NumOngoingSwaps is the number of swaps currently in progress
+	NumOngoingSwaps int `json:"numOngoingSwaps"`
+	// Errors holds the reason of each subsystem that is not healthy
+	Errors []string `json:"errors,omitempty"`
+
+	// GasBalance is only set if swapd was started with a minimum ETH balance
+	// and the balance has been checked.
+	GasBalance    *GasBalanceHealth    `json:"gasBalance,omitempty"`
+	WsConnections *WsConnectionsHealth `json:"wsConnections,omitempty"`
+	// RelayQueue is only set if the number of concurrent relays is limited
+	RelayQueue *RelayQueueHealth `json:"relayQueue,omitempty"`
+}
+
+// Health returns the health of swapd and its dependencies. A failing
+// dependency is reported in the response instead of as an error, so that
+// monitoring can tell which subsystem is down.
+func (s *DaemonService) Health(_ *http.Request, _ *any, resp *HealthResponse) error {
+	if _, err := s.pb.XMRClient().GetHeight(); err != nil {
+		resp.Errors = append(resp.Errors, fmt.Sprintf("monero wallet unreachable: %s", err))
+	} else {
+		resp.MoneroWalletReachable = true
+	}
+
+	synced, err := s.pb.ETHClient().IsSynced(s.pb.Ctx())
+	switch {
+	case err != nil:
+		resp.Errors = append(resp.Errors, fmt.Sprintf("ethereum client unreachable: %s", err))
+	case !synced:
+		resp.Errors = append(resp.Errors, "ethereum client is syncing")
+	default:
+		resp.EthereumSynced = true
+	}
+
+	if s.net != nil {
+		resp.NumPeers = len(s.net.ConnectedPeers())
+	}
+
+	ongoing, err := s.pb.SwapManager().GetOngoingSwaps()
+	if err != nil {
+		resp.Errors = append(resp.Errors, fmt.Sprintf("failed to get ongoing swaps: %s", err))
+	}
+	resp.NumOngoingSwaps = len(ongoing)
+
+	resp.IsHealthy = len(resp.Errors) == 0
+
+	if status := s.pb.GasBalanceStatus(); status != nil {
+		resp.GasBalance = &GasBalanceHealth{
+			WeiBalance:    status.Balance,
+			MinWeiBalance: status.Threshold,
+			IsLow:         status.IsLow,
+			CheckedAt:     status.CheckedAt,
+		}
+	}
+
+	if s.wsConns != nil {
+		resp.WsConnections = &WsConnectionsHealth{
+			Count: s.wsConns.current(),
+			Max:   s.wsConns.max,
+		}
+	}
+
+	if status := s.pb.RelayLimiterStatus(); status != nil {
+		resp.RelayQueue = &RelayQueueHealth{
+			Active: status.Active,
+			Queued: status.Queued,
+			Max:    status.Max,
+		}
+	}
+
 	return nil
 }
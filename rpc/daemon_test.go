@@ -0,0 +1,138 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+
+	"github.com/stretchr/testify/require"
+)
+
+// drainTestBackend is a mockProtocolBackend with a swap manager that has
+// ongoing swaps.
+type drainTestBackend struct {
+	*mockProtocolBackend
+	sm *cancelTestSwapManager
+}
+
+func (b *drainTestBackend) SwapManager() swap.Manager {
+	return b.sm
+}
+
+func TestDaemon_Shutdown_drain(t *testing.T) {
+	stopped := false
+	pb := newMockProtocolBackend()
+	network := new(mockNet)
+	ds := NewDaemonService(func() { stopped = true }, pb)
+	ds.net = network
+
+	resp := new(ShutdownResponse)
+	require.NoError(t, ds.Shutdown(nil, &ShutdownRequest{Drain: true}, resp))
+	require.Zero(t, resp.NumOngoingSwaps)
+	require.True(t, pb.sm.newSwapsBlocked)
+	require.True(t, network.offersStopped)
+	require.True(t, stopped)
+}
+
+// failingSwapManager is a mockSwapManager that fails to read the ongoing swaps.
+type failingSwapManager struct {
+	mockSwapManager
+}
+
+func (*failingSwapManager) GetOngoingSwaps() ([]*swap.Info, error) {
+	return nil, errors.New("db read failed")
+}
+
+type failingSwapManagerBackend struct {
+	*mockProtocolBackend
+	sm *failingSwapManager
+}
+
+func (b *failingSwapManagerBackend) SwapManager() swap.Manager {
+	return b.sm
+}
+
+func TestDaemon_Shutdown_drainFails(t *testing.T) {
+	stopped := false
+	pb := &failingSwapManagerBackend{
+		mockProtocolBackend: newMockProtocolBackend(),
+		sm:                  new(failingSwapManager),
+	}
+	ds := NewDaemonService(func() { stopped = true }, pb)
+	ds.net = new(mockNet)
+
+	err := ds.Shutdown(nil, &ShutdownRequest{Drain: true}, new(ShutdownResponse))
+	require.ErrorContains(t, err, "db read failed")
+	require.True(t, pb.sm.newSwapsBlocked)
+	require.True(t, stopped)
+}
+
+func TestDaemon_Shutdown_timeoutTooLong(t *testing.T) {
+	stopped := false
+	pb := newMockProtocolBackend()
+	ds := NewDaemonService(func() { stopped = true }, pb)
+	ds.net = new(mockNet)
+
+	req := &ShutdownRequest{Drain: true, Timeout: uint64(MaxDrainTimeout/time.Second) + 1}
+	err := ds.Shutdown(nil, req, new(ShutdownResponse))
+	require.ErrorIs(t, err, errDrainTimeoutTooLong)
+	require.False(t, pb.sm.newSwapsBlocked)
+	require.False(t, stopped)
+}
+
+func TestDaemon_drain_timeout(t *testing.T) {
+	prevInterval := drainPollInterval
+	drainPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { drainPollInterval = prevInterval })
+
+	pb := &drainTestBackend{
+		mockProtocolBackend: newMockProtocolBackend(),
+		sm: &cancelTestSwapManager{
+			ongoing: []*swap.Info{{OfferID: types.Hash{1}, Status: types.XMRLocked}},
+		},
+	}
+	ds := NewDaemonService(func() {}, pb)
+
+	numOngoing, err := ds.drain(50 * time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 1, numOngoing)
+	require.True(t, pb.sm.newSwapsBlocked)
+}
+
+func TestDaemon_RelayerStats(t *testing.T) {
+	ds := NewDaemonService(func() {}, newMockProtocolBackend())
+	ds.net = new(mockNet)
+
+	resp := new(RelayerStatsResponse)
+	require.NoError(t, ds.RelayerStats(nil, nil, resp))
+	require.False(t, resp.IsRelayer)
+	require.Nil(t, resp.Fee)
+	require.Zero(t, resp.NumRelayed)
+	require.Equal(t, "0", resp.Earnings.Text('f'))
+
+	ds.net = &mockNet{relayerFee: coins.NewWeiAmount(coins.RelayerFeeWei)}
+	resp = new(RelayerStatsResponse)
+	require.NoError(t, ds.RelayerStats(nil, nil, resp))
+	require.True(t, resp.IsRelayer)
+	require.Equal(t, "0.009", resp.Fee.Text('f'))
+}
+
+func TestDaemon_EthSyncStatus(t *testing.T) {
+	ds := NewDaemonService(func() {}, newMockProtocolBackend())
+
+	resp := new(EthSyncStatusResponse)
+	require.NoError(t, ds.EthSyncStatus(nil, nil, resp))
+	require.Equal(t, &EthSyncStatusResponse{
+		IsSynced:     true,
+		HeadBlock:    100,
+		HighestBlock: 100,
+		ChainID:      1337,
+	}, resp)
+}
@@ -4,13 +4,16 @@
 package rpc
 
 import (
+	"fmt"
 	"math/big"
 	"net/http"
 
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/crypto/secp256k1"
 	"github.com/athanorlabs/atomic-swap/db"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 )
@@ -20,6 +23,8 @@ type RecoveryDB interface {
 	GetContractSwapInfo(id types.Hash) (*db.EthereumSwapInfo, error)
 	GetSwapPrivateKey(id types.Hash) (*mcrypto.PrivateSpendKey, error)
 	GetCounterpartySwapPrivateKey(id types.Hash) (*mcrypto.PrivateSpendKey, error)
+	GetCounterpartySwapKeys(id types.Hash) (*mcrypto.PublicKey, *mcrypto.PrivateViewKey, error)
+	GetCounterpartyDLEqProof(id types.Hash) (*db.CounterpartyDLEqProof, error)
 }
 
 // DatabaseService ...
@@ -89,3 +94,43 @@ func (s *DatabaseService) GetSwapSecret(
 	resp.Secret = key
 	return nil
 }
+
+// VerifyDLEqProofRequest ...
+type VerifyDLEqProofRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// VerifyDLEqProofResponse ...
+type VerifyDLEqProofResponse struct {
+	Verified           bool                 `json:"verified"`
+	Error              string               `json:"error,omitempty"`
+	Secp256k1PublicKey *secp256k1.PublicKey `json:"secp256k1PublicKey" validate:"required"`
+	PublicSpendKey     *mcrypto.PublicKey   `json:"publicSpendKey" validate:"required"`
+}
+
+// VerifyDLEqProof verifies the counterparty's DLEq proof for the given swap ID
+// from the database again, confirming that the counterparty's secp256k1 public
+// key and Monero public spend key correspond. A failed verification is not an
+// error; the reason is returned in the response instead.
+func (s *DatabaseService) VerifyDLEqProof(
+	_ *http.Request,
+	req *VerifyDLEqProofRequest,
+	resp *VerifyDLEqProofResponse,
+) error {
+	proof, err := s.rdb.GetCounterpartyDLEqProof(req.OfferID)
+	if err != nil {
+		return fmt.Errorf("failed to get DLEq proof of swap %s: %w", req.OfferID, err)
+	}
+
+	resp.Secp256k1PublicKey = proof.Secp256k1PublicKey
+	resp.PublicSpendKey = proof.PublicSpendKey
+
+	_, err = pcommon.VerifyKeysAndProof(proof.Proof, proof.Secp256k1PublicKey, proof.PublicSpendKey)
+	if err != nil {
+		resp.Error = err.Error()
+		return nil
+	}
+
+	resp.Verified = true
+	return nil
+}
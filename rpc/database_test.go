@@ -0,0 +1,86 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
+)
+
+type mockRecoveryDB struct {
+	proofs map[types.Hash]*db.CounterpartyDLEqProof
+}
+
+func (*mockRecoveryDB) GetContractSwapInfo(_ types.Hash) (*db.EthereumSwapInfo, error) {
+	return nil, chaindb.ErrKeyNotFound
+}
+
+func (*mockRecoveryDB) GetSwapPrivateKey(_ types.Hash) (*mcrypto.PrivateSpendKey, error) {
+	return nil, chaindb.ErrKeyNotFound
+}
+
+func (*mockRecoveryDB) GetCounterpartySwapPrivateKey(_ types.Hash) (*mcrypto.PrivateSpendKey, error) {
+	return nil, chaindb.ErrKeyNotFound
+}
+
+func (*mockRecoveryDB) GetCounterpartySwapKeys(_ types.Hash) (*mcrypto.PublicKey, *mcrypto.PrivateViewKey, error) {
+	return nil, nil, chaindb.ErrKeyNotFound
+}
+
+func (m *mockRecoveryDB) GetCounterpartyDLEqProof(id types.Hash) (*db.CounterpartyDLEqProof, error) {
+	proof, has := m.proofs[id]
+	if !has {
+		return nil, chaindb.ErrKeyNotFound
+	}
+	return proof, nil
+}
+
+func TestDatabase_VerifyDLEqProof(t *testing.T) {
+	keysAndProof, err := pcommon.GenerateKeysAndProof()
+	require.NoError(t, err)
+	otherKeys, err := pcommon.GenerateKeysAndProof()
+	require.NoError(t, err)
+
+	validID := types.Hash{0x1}
+	invalidID := types.Hash{0x2}
+	rdb := &mockRecoveryDB{
+		proofs: map[types.Hash]*db.CounterpartyDLEqProof{
+			validID: {
+				Proof:              keysAndProof.DLEqProof.Proof(),
+				Secp256k1PublicKey: keysAndProof.Secp256k1PublicKey,
+				PublicSpendKey:     keysAndProof.PublicKeyPair.SpendKey(),
+			},
+			// the proof does not correspond to the spend key
+			invalidID: {
+				Proof:              keysAndProof.DLEqProof.Proof(),
+				Secp256k1PublicKey: keysAndProof.Secp256k1PublicKey,
+				PublicSpendKey:     otherKeys.PublicKeyPair.SpendKey(),
+			},
+		},
+	}
+	ds := NewDatabaseService(rdb)
+
+	resp := new(VerifyDLEqProofResponse)
+	err = ds.VerifyDLEqProof(nil, &VerifyDLEqProofRequest{OfferID: validID}, resp)
+	require.NoError(t, err)
+	require.True(t, resp.Verified)
+	require.Empty(t, resp.Error)
+	require.Equal(t, keysAndProof.Secp256k1PublicKey, resp.Secp256k1PublicKey)
+
+	resp = new(VerifyDLEqProofResponse)
+	err = ds.VerifyDLEqProof(nil, &VerifyDLEqProofRequest{OfferID: invalidID}, resp)
+	require.NoError(t, err)
+	require.False(t, resp.Verified)
+	require.NotEmpty(t, resp.Error)
+
+	err = ds.VerifyDLEqProof(nil, &VerifyDLEqProofRequest{OfferID: types.Hash{0x3}}, new(VerifyDLEqProofResponse))
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+}
@@ -11,9 +11,31 @@ var (
 	// net_ errors
 	errNoOfferWithID          = errors.New("peer does not have offer with given ID")
 	errUnsupportedForBootnode = errors.New("unsupported for bootnode")
+	errObserverMode           = errors.New("daemon is in observer mode")
+	errIdempotencyKeyReused   = errors.New("idempotency key was already used for an offer with different parameters")
+	errKeyedOfferGone         = errors.New("the offer made for the idempotency key was taken or removed")
+
+	// personal_ errors
+	errNoSwapTimeout          = errors.New("no swap timeout duration was given")
+	errSwapTimeoutOutOfBounds = errors.New("swap timeout duration is out of bounds")
+	errDrainTimeoutTooLong    = errors.New("drain timeout is too long")
+
+	// swap_ errors
+	errSwapOngoing            = errors.New("swap is still ongoing, wait for it to complete before exporting it")
+	errSinceAfterUntil        = errors.New("since must not be after until")
+	errForceCompleteNotMaker  = errors.New("can only force-complete swaps where we provide XMR")
+	errTokenCacheDisabled     = errors.New("token metadata is not cached")
+	errRateHistoryDisabled    = errors.New("exchange rate history is not available")
+	errNoSwapState            = errors.New("failed to find swap state with ID")
+	errGasPriceOverrideTooLow = errors.New("gas price override must be above the suggested gas price")
+	errSweepOngoingSwap       = errors.New("cannot sweep the shared wallet of an ongoing swap")
+	errNoRecoveryDB           = errors.New("recovery database is not available")
+	errNoContractSwapInfo     = errors.New("swap has no contract info, the ETH was not locked or the swap was completed")
 
 	// ws errors
 	errUnimplemented       = errors.New("unimplemented")
 	errInvalidMethod       = errors.New("invalid method")
 	errNamespaceNotEnabled = errors.New("namespace not enabled")
+	errSubscriberTooSlow   = errors.New("subscriber is not keeping up with events")
+	errTooManyWsConns      = errors.New("too many websocket connections")
 )
@@ -0,0 +1,235 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+const (
+	// eventSocketClientBufSize is the number of events that can be queued for
+	// an event socket consumer before it is disconnected for not keeping up.
+	eventSocketClientBufSize = 32
+
+	eventSocketWriteTimeout = 5 * time.Second
+)
+
+// eventSocket emits swap status events to local consumers connected to a Unix
+// domain socket. Each event is written as a single line of JSON, using the same
+// JSON-RPC response envelope as the websocket subscriptions.
+type eventSocket struct {
+	ctx      context.Context
+	listener *net.UnixListener
+
+	mu         sync.Mutex
+	clients    map[*eventSocketClient]struct{}
+	lastStatus map[types.Hash]types.Status
+}
+
+type eventSocketClient struct {
+	conn    net.Conn
+	eventCh chan []byte
+}
+
+// newEventSocket listens on the Unix socket at the given path and registers a
+// listener with the swap manager for the events to emit. The socket file is
+// removed when the context is cancelled.
+func newEventSocket(ctx context.Context, path string, sm swap.Manager) (*eventSocket, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// only the user running swapd can connect
+	if err = os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+
+	es := &eventSocket{
+		ctx:        ctx,
+		listener:   ln,
+		clients:    make(map[*eventSocketClient]struct{}),
+		lastStatus: make(map[types.Hash]types.Status),
+	}
+
+	sm.AddStatusListener(es.publish)
+
+	go es.acceptConnections()
+	go func() {
+		<-ctx.Done()
+		es.close()
+	}()
+
+	log.Infof("Emitting swap events on Unix socket %s", path)
+	return es, nil
+}
+
+// removeStaleSocket removes a socket file left behind by a swapd instance that
+// did not shut down cleanly. Any other type of file at the path is an error.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if fi.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("event socket path %s exists and is not a socket", path)
+	}
+
+	return os.Remove(path)
+}
+
+func (es *eventSocket) acceptConnections() {
+	for {
+		conn, err := es.listener.Accept()
+		if err != nil {
+			if es.ctx.Err() == nil {
+				log.Warnf("event socket stopped accepting connections: %s", err)
+			}
+			return
+		}
+
+		client := &eventSocketClient{
+			conn:    conn,
+			eventCh: make(chan []byte, eventSocketClientBufSize),
+		}
+
+		es.mu.Lock()
+		es.clients[client] = struct{}{}
+		es.mu.Unlock()
+
+		go es.writeEvents(client)
+	}
+}
+
+// writeEvents writes queued events to the client until the client disconnects
+// or the socket is closed.
+func (es *eventSocket) writeEvents(client *eventSocketClient) {
+	defer es.removeClient(client)
+
+	for {
+		select {
+		case <-es.ctx.Done():
+			return
+		case event, ok := <-client.eventCh:
+			if !ok {
+				return
+			}
+
+			_ = client.conn.SetWriteDeadline(time.Now().Add(eventSocketWriteTimeout))
+			if _, err := client.conn.Write(event); err != nil {
+				log.Debugf("event socket consumer disconnected: %s", err)
+				return
+			}
+		}
+	}
+}
+
+func (es *eventSocket) removeClient(client *eventSocketClient) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if _, has := es.clients[client]; has {
+		es.dropClient(client)
+	}
+}
+
+// dropClient disconnects the client and stops its writer goroutine. The caller
+// must hold es.mu.
+func (es *eventSocket) dropClient(client *eventSocketClient) {
+	delete(es.clients, client)
+	_ = client.conn.Close()
+	close(client.eventCh)
+}
+
+// publish queues a status event for all connected consumers. It is called by
+// the swap manager and never blocks; consumers that are not keeping up with
+// events are disconnected.
+func (es *eventSocket) publish(info *swap.Info) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	// the swap manager notifies on every write of the swap, which does not
+	// always coincide with a status change
+	if last, has := es.lastStatus[info.OfferID]; has && last == info.Status {
+		return
+	}
+
+	if info.Status.IsOngoing() {
+		es.lastStatus[info.OfferID] = info.Status
+	} else {
+		delete(es.lastStatus, info.OfferID)
+	}
+
+	event, err := newSwapStatusEvent(info)
+	if err != nil {
+		log.Warnf("failed to encode event for swap %s: %s", info.OfferID, err)
+		return
+	}
+
+	for client := range es.clients {
+		select {
+		case client.eventCh <- event:
+		default:
+			log.Warnf("disconnecting event socket consumer that is not reading events")
+			es.dropClient(client)
+		}
+	}
+}
+
+func newSwapStatusEvent(info *swap.Info) ([]byte, error) {
+	bz, err := vjson.MarshalStruct(&rpctypes.SwapStatusEvent{
+		OfferID: info.OfferID,
+		Status:  info.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := json.Marshal(&rpctypes.Response{
+		Version: rpctypes.DefaultJSONRPCVersion,
+		Result:  bz,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(event, '\n'), nil
+}
+
+// close stops accepting connections, disconnects all consumers and removes the
+// socket file.
+func (es *eventSocket) close() {
+	// closing a listener created with ListenUnix also removes the socket file
+	if err := es.listener.Close(); err != nil {
+		log.Warnf("failed to close event socket: %s", err)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	for client := range es.clients {
+		es.dropClient(client)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestEventSocket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := gomock.NewController(t)
+	db := swap.NewMockDatabase(ctrl)
+	db.EXPECT().GetAllSwaps()
+	db.EXPECT().PutSwap(gomock.Any()).AnyTimes()
+	db.EXPECT().IncrementPeerStats(gomock.Any(), gomock.Any()).AnyTimes()
+
+	sm, err := swap.NewManager(db)
+	require.NoError(t, err)
+
+	// use a short path, as socket paths are limited to ~100 characters
+	dir, err := os.MkdirTemp("", "swapd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := path.Join(dir, "events.sock")
+
+	// a stale socket file from a previous run is replaced
+	staleLn, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	require.NoError(t, err)
+	staleLn.SetUnlinkOnClose(false)
+	require.NoError(t, staleLn.Close())
+
+	es, err := newEventSocket(ctx, socketPath, sm)
+	require.NoError(t, err)
+
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// wait for the connection to be registered
+	require.Eventually(t, func() bool {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		return len(es.clients) == 1
+	}, testTimeout, 10*time.Millisecond)
+
+	offerID := types.Hash{0x1}
+	info := swap.NewInfo(
+		testPeerID,
+		offerID,
+		coins.ProvidesXMR,
+		apd.New(1, 0),
+		apd.New(1, 0),
+		coins.ToExchangeRate(apd.New(1, 0)),
+		types.EthAssetETH,
+		types.ExpectingKeys,
+		1,
+		nil,
+	)
+	require.NoError(t, sm.AddSwap(info))
+	require.NoError(t, sm.WriteSwapToDB(info)) // no status change, no event
+	info.SetStatus(types.KeysExchanged)
+	require.NoError(t, sm.WriteSwapToDB(info))
+	info.SetStatus(types.CompletedAbort)
+	require.NoError(t, sm.CompleteOngoingSwap(info))
+
+	reader := bufio.NewReader(conn)
+	for _, expected := range []types.Status{types.ExpectingKeys, types.KeysExchanged, types.CompletedAbort} {
+		line, err := reader.ReadBytes('\n') //nolint:govet
+		require.NoError(t, err)
+
+		resp := new(rpctypes.Response)
+		require.NoError(t, json.Unmarshal(line, resp))
+		event := new(rpctypes.SwapStatusEvent)
+		require.NoError(t, json.Unmarshal(resp.Result, event))
+		require.Equal(t, offerID, event.OfferID)
+		require.Equal(t, expected, event.Status)
+	}
+
+	// the socket file is removed on shutdown
+	cancel()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socketPath)
+		return os.IsNotExist(err)
+	}, testTimeout, 10*time.Millisecond)
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/relayer"
+)
+
+// newMetricsHandler returns the handler of the /metrics endpoint, which
+// exposes the swap and relayer metrics, along with gauges of the connected
+// peers and our balances that are read when the metrics are scraped.
+func newMetricsHandler(cfg *Config) (http.Handler, error) {
+	reg := prometheus.NewRegistry()
+
+	collectors := append(swap.MetricsCollectors(), relayer.MetricsCollectors()...)
+
+	if cfg.Net != nil {
+		collectors = append(collectors, prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "swapd",
+				Name:      "peers_connected",
+				Help:      "Number of connected peers",
+			},
+			func() float64 {
+				return float64(len(cfg.Net.ConnectedPeers()))
+			},
+		))
+	}
+
+	if pb := cfg.ProtocolBackend; pb != nil {
+		collectors = append(collectors,
+			prometheus.NewGaugeFunc(
+				prometheus.GaugeOpts{
+					Namespace: "swapd",
+					Name:      "balance_eth",
+					Help:      "ETH balance of the swapd account",
+				},
+				func() float64 {
+					bal, err := pb.ETHClient().Balance(cfg.Ctx)
+					if err != nil {
+						log.Debugf("failed to get ETH balance for metrics: %s", err)
+						return 0
+					}
+					return decimalToFloat(bal.AsEther())
+				},
+			),
+			prometheus.NewGaugeFunc(
+				prometheus.GaugeOpts{
+					Namespace: "swapd",
+					Name:      "balance_xmr",
+					Help:      "XMR balance of the primary account of the swapd wallet",
+				},
+				func() float64 {
+					bal, err := pb.XMRClient().GetBalance(0)
+					if err != nil {
+						log.Debugf("failed to get XMR balance for metrics: %s", err)
+						return 0
+					}
+					return decimalToFloat(coins.NewPiconeroAmount(bal.Balance).AsMonero())
+				},
+			),
+		)
+	}
+
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{}), nil
+}
+
+func decimalToFloat(d *apd.Decimal) float64 {
+	f, err := d.Float64()
+	if err != nil {
+		return 0
+	}
+	return f
+}
@@ -5,6 +5,9 @@ package rpc
 
 import (
 	"context"
+	"errors"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/MarinX/monerorpc/wallet"
@@ -18,10 +21,15 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 //
@@ -29,7 +37,21 @@ import (
 //
 
 type mockNet struct {
-	peerID peer.ID
+	peerID        peer.ID
+	advertised    bool
+	offersStopped bool
+
+	// discovered is returned by Discover, and relayerFees by QueryRelayerFee,
+	// which fails for peers that are not in the map
+	discovered  []peer.ID
+	relayerFees map[peer.ID]*coins.WeiAmount
+
+	// relayerFee is our own fee, nil if we are not a relayer
+	relayerFee *coins.WeiAmount
+
+	// offers is returned by Query if set, which then fails for peers that are
+	// not in the map
+	offers map[peer.ID][]*types.Offer
 }
 
 func (*mockNet) Addresses() []ma.Multiaddr {
@@ -51,14 +73,37 @@ func (*mockNet) ConnectedPeers() []string {
 	panic("not implemented")
 }
 
-func (*mockNet) Discover(_ string, _ time.Duration) ([]peer.ID, error) {
-	return nil, nil
+func (m *mockNet) Discover(_ string, _ time.Duration) ([]peer.ID, error) {
+	return m.discovered, nil
 }
 
-func (*mockNet) Query(_ peer.ID) (*message.QueryResponse, error) {
+func (m *mockNet) Query(who peer.ID) (*message.QueryResponse, error) {
+	if m.offers != nil {
+		offers, ok := m.offers[who]
+		if !ok {
+			return nil, errors.New("peer did not respond")
+		}
+		return &message.QueryResponse{Offers: offers}, nil
+	}
 	return &message.QueryResponse{Offers: []*types.Offer{{ID: testSwapID}}}, nil
 }
 
+func (*mockNet) QueryAddrInfo(_ peer.AddrInfo) (*message.QueryResponse, error) {
+	return &message.QueryResponse{Offers: []*types.Offer{{ID: testSwapID}}}, nil
+}
+
+func (m *mockNet) QueryRelayerFee(relayerID peer.ID) (*coins.WeiAmount, error) {
+	fee, ok := m.relayerFees[relayerID]
+	if !ok {
+		return nil, errors.New("relayer did not return a fee")
+	}
+	return fee, nil
+}
+
+func (m *mockNet) RelayerFee() *coins.WeiAmount {
+	return m.relayerFee
+}
+
 func (*mockNet) Initiate(_ peer.AddrInfo, _ common.Message, _ common.SwapStateNet) error {
 	return nil
 }
@@ -67,13 +112,41 @@ func (*mockNet) CloseProtocolStream(_ types.Hash) {
 	panic("not implemented")
 }
 
-type mockSwapManager struct{}
+func (*mockNet) ProtocolStreams() []*net.ProtocolStreamInfo {
+	panic("not implemented")
+}
+
+func (*mockNet) BlockPeer(_ peer.ID) error {
+	return nil
+}
+
+func (m *mockNet) Advertise() {
+	m.advertised = true
+}
+
+func (*mockNet) UnblockPeer(_ peer.ID) error {
+	return nil
+}
+
+func (m *mockNet) StopOffers() {
+	m.offersStopped = true
+}
+
+type mockSwapManager struct {
+	mu              sync.Mutex
+	listener        swap.StatusListener
+	newSwapsBlocked bool
+}
 
 func (*mockSwapManager) WriteSwapToDB(_ *swap.Info) error {
 	return nil
 }
 
-func (*mockSwapManager) GetPastIDs() ([]types.Hash, error) {
+func (*mockSwapManager) GetPastIDs(_ swap.PastSwapFilter) ([]types.Hash, error) {
+	panic("not implemented")
+}
+
+func (*mockSwapManager) GetPastSwaps(_ swap.PastSwapFilter, _, _ uint) ([]*swap.Info, error) {
 	panic("not implemented")
 }
 
@@ -112,8 +185,41 @@ func (*mockSwapManager) CompleteOngoingSwap(_ *swap.Info) error {
 	panic("not implemented")
 }
 
-func (*mockSwapManager) HasOngoingSwap(_ types.Hash) bool {
-	panic("not implemented")
+func (*mockSwapManager) HasOngoingSwap(id types.Hash) bool {
+	return id == testSwapID
+}
+
+func (*mockSwapManager) GetAllPeerStats() (map[peer.ID]*swap.PeerStats, error) {
+	return map[peer.ID]*swap.PeerStats{
+		testPeerID: {NumSucceeded: 3, NumRefunded: 1},
+	}, nil
+}
+
+func (m *mockSwapManager) BlockNewSwaps() {
+	m.newSwapsBlocked = true
+}
+
+func (m *mockSwapManager) AddStatusListener(listener swap.StatusListener) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listener = listener
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.listener = nil
+	}
+}
+
+// notify passes the swap info to the registered status listener, returning false
+// if no listener is registered.
+func (m *mockSwapManager) notify(info *swap.Info) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.listener == nil {
+		return false
+	}
+	m.listener(info)
+	return true
 }
 
 type mockXMRTaker struct{}
@@ -126,7 +232,12 @@ func (*mockXMRTaker) GetOngoingSwapState(_ types.Hash) common.SwapState {
 	return new(mockSwapState)
 }
 
-func (*mockXMRTaker) InitiateProtocol(_ peer.ID, _ *apd.Decimal, _ *types.Offer) (common.SwapState, error) {
+func (*mockXMRTaker) InitiateProtocol(
+	_ peer.ID,
+	_ *apd.Decimal,
+	_ *types.Offer,
+	_ *coins.ExchangeRate,
+) (common.SwapState, error) {
 	return new(mockSwapState), nil
 }
 
@@ -134,7 +245,7 @@ func (*mockXMRTaker) Refund(_ types.Hash) (ethcommon.Hash, error) {
 	panic("not implemented")
 }
 
-func (*mockXMRTaker) SetSwapTimeout(_ time.Duration) {
+func (*mockXMRTaker) SetSwapTimeout(_ time.Duration, _ time.Duration) {
 	panic("not implemented")
 }
 
@@ -142,7 +253,18 @@ func (*mockXMRTaker) ExternalSender(_ types.Hash) (*txsender.ExternalSender, err
 	panic("not implemented")
 }
 
-type mockXMRMaker struct{}
+func (*mockXMRTaker) RetrySweep(_ types.Hash) error {
+	panic("not implemented")
+}
+
+func (*mockXMRTaker) SetGasPriceOverride(_ types.Hash, _ *big.Int) error {
+	return nil
+}
+
+type mockXMRMaker struct {
+	mu                 sync.Mutex
+	offerTakenListener func(*types.OfferTakenEvent)
+}
 
 func (m *mockXMRMaker) Provides() coins.ProvidesCoin {
 	panic("not implemented")
@@ -152,7 +274,14 @@ func (m *mockXMRMaker) GetOngoingSwapState(_ types.Hash) common.SwapState {
 	panic("not implemented")
 }
 
-func (*mockXMRMaker) MakeOffer(_ *types.Offer, _ bool) (*types.OfferExtra, error) {
+func (*mockXMRMaker) MakeOffer(
+	_ *types.Offer,
+	_ bool,
+	_ *time.Time,
+	_ *types.MinTakerReputation,
+	_ bool,
+	_ *types.Repricing,
+) (*types.OfferExtra, error) {
 	offerExtra := &types.OfferExtra{
 		StatusCh: make(chan types.Status, 1),
 	}
@@ -160,18 +289,79 @@ func (*mockXMRMaker) MakeOffer(_ *types.Offer, _ bool) (*types.OfferExtra, error
 	return offerExtra, nil
 }
 
+func (*mockXMRMaker) ValidateOffer(
+	_ *types.Offer,
+	_ bool,
+	_ *time.Time,
+	_ *types.MinTakerReputation,
+	_ *types.Repricing,
+) (*types.OfferSummary, error) {
+	panic("not implemented")
+}
+
 func (*mockXMRMaker) GetOffers() []*types.Offer {
+	return []*types.Offer{}
+}
+
+func (*mockXMRMaker) OfferExpiry(_ types.Hash) *time.Time {
 	panic("not implemented")
 }
 
+func (*mockXMRMaker) OfferExtra(_ types.Hash) *types.OfferExtra {
+	return nil
+}
+
 func (*mockXMRMaker) ClearOffers(_ []types.Hash) error {
 	panic("not implemented")
 }
 
+func (*mockXMRMaker) ForceCompleteSwap(_ types.Hash) error {
+	panic("not implemented")
+}
+
+func (*mockXMRMaker) RetrySweep(_ types.Hash) error {
+	panic("not implemented")
+}
+
+func (*mockXMRMaker) SetGasPriceOverride(_ types.Hash, _ *big.Int) error {
+	return nil
+}
+
 func (*mockXMRMaker) GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error) {
 	panic("not implemented")
 }
 
+func (*mockXMRMaker) GetRejectedTakes() ([]*db.RejectedTake, error) {
+	panic("not implemented")
+}
+
+func (*mockXMRMaker) GetOfferDefaults(_ types.EthAsset) (*db.OfferDefaults, error) {
+	panic("not implemented")
+}
+
+func (m *mockXMRMaker) AddOfferTakenListener(listener func(*types.OfferTakenEvent)) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.offerTakenListener = listener
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.offerTakenListener = nil
+	}
+}
+
+// notifyOfferTaken passes the event to the registered offer taken listener,
+// returning false if no listener is registered.
+func (m *mockXMRMaker) notifyOfferTaken(event *types.OfferTakenEvent) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.offerTakenListener == nil {
+		return false
+	}
+	m.offerTakenListener(event)
+	return true
+}
+
 type mockSwapState struct{}
 
 func (*mockSwapState) HandleProtocolMessage(_ common.Message) error {
@@ -191,12 +381,16 @@ func (*mockSwapState) OfferID() types.Hash {
 }
 
 type mockProtocolBackend struct {
-	sm *mockSwapManager
+	sm         *mockSwapManager
+	env        common.Environment
+	t0Duration time.Duration
+	t1Duration time.Duration
 }
 
 func newMockProtocolBackend() *mockProtocolBackend {
 	return &mockProtocolBackend{
-		sm: new(mockSwapManager),
+		sm:  new(mockSwapManager),
+		env: common.Development,
 	}
 }
 
@@ -204,15 +398,24 @@ func (*mockProtocolBackend) Ctx() context.Context {
 	return context.Background()
 }
 
-func (*mockProtocolBackend) Env() common.Environment {
-	return common.Development
+func (b *mockProtocolBackend) Env() common.Environment {
+	return b.env
 }
 
-func (*mockProtocolBackend) SetSwapTimeout(_ time.Duration) {
+func (b *mockProtocolBackend) SetSwapTimeout(t0Duration time.Duration, t1Duration time.Duration) {
+	b.t0Duration = t0Duration
+	b.t1Duration = t1Duration
+}
+
+func (b *mockProtocolBackend) SwapTimeout() (time.Duration, time.Duration) {
+	return b.t0Duration, b.t1Duration
+}
+
+func (*mockProtocolBackend) EventChSize() int {
 	panic("not implemented")
 }
 
-func (*mockProtocolBackend) SwapTimeout() time.Duration {
+func (*mockProtocolBackend) LogChSize() int {
 	panic("not implemented")
 }
 
@@ -229,7 +432,68 @@ func (*mockProtocolBackend) ClearXMRDepositAddress(types.Hash) {
 }
 
 func (*mockProtocolBackend) ETHClient() extethclient.EthClient {
-	panic("not implemented")
+	return new(mockEthClient)
+}
+
+func (*mockProtocolBackend) XMRClient() monero.WalletClient {
+	return new(mockWalletClient)
+}
+
+// mockEthClient only implements the EthClient methods used by the daemon
+// service, token cache and gas price override, the embedded nil interface
+// panics on any other method.
+type mockEthClient struct {
+	extethclient.EthClient
+}
+
+func (*mockEthClient) IsSynced(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+func (*mockEthClient) SyncStatus(_ context.Context) (*extethclient.SyncStatus, error) {
+	return &extethclient.SyncStatus{HeadBlock: 100, HighestBlock: 100, IsSynced: true}, nil
+}
+
+func (*mockEthClient) ChainID() *big.Int {
+	return big.NewInt(common.GanacheChainID)
+}
+
+func (*mockEthClient) SuggestGasPrice(_ context.Context) (*big.Int, error) {
+	return big.NewInt(10e9), nil // 10 gwei
+}
+
+func (*mockEthClient) Address() ethcommon.Address {
+	return ethcommon.Address{1}
+}
+
+func (*mockEthClient) Balance(_ context.Context) (*coins.WeiAmount, error) {
+	return coins.NewWeiAmount(big.NewInt(1e18)), nil
+}
+
+func (*mockEthClient) ERC20Info(_ context.Context, tokenAddr ethcommon.Address) (*coins.ERC20TokenInfo, error) {
+	return coins.NewERC20TokenInfo(tokenAddr, 18, "Mock Token", "MOCK"), nil
+}
+
+// mockWalletClient only implements the WalletClient methods used by the daemon
+// service, the embedded nil interface panics on any other method.
+type mockWalletClient struct {
+	monero.WalletClient
+}
+
+func (*mockWalletClient) GetHeight() (uint64, error) {
+	return 1, nil
+}
+
+func (*mockWalletClient) GetSyncStatus() (*monero.SyncStatus, error) {
+	return &monero.SyncStatus{WalletHeight: 90, DaemonHeight: 100, TargetHeight: 120}, nil
+}
+
+func (*mockProtocolBackend) GasBalanceStatus() *backend.GasBalanceStatus {
+	return nil
+}
+
+func (*mockProtocolBackend) RelayLimiterStatus() *relayer.LimiterStatus {
+	return nil
 }
 
 func (*mockProtocolBackend) SwapCreatorAddr() ethcommon.Address {
@@ -4,10 +4,13 @@
 package rpc
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
+	"github.com/ChainSafe/chaindb"
 	"github.com/cockroachdb/apd/v3"
 	"github.com/libp2p/go-libp2p/core/peer"
 	ma "github.com/multiformats/go-multiaddr"
@@ -16,10 +19,19 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/net"
 	"github.com/athanorlabs/atomic-swap/net/message"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
-const defaultSearchTime = time.Second * 12
+const (
+	defaultSearchTime = time.Second * 12
+
+	// maxProtocolStreams is the maximum number of streams returned by
+	// net_getProtocolStreams
+	maxProtocolStreams = 1000
+)
 
 // Net contains the network-related functions required by the rpc service.
 type Net interface {
@@ -28,8 +40,16 @@ type Net interface {
 	Addresses() []ma.Multiaddr
 	Discover(provides string, searchTime time.Duration) ([]peer.ID, error)
 	Query(who peer.ID) (*message.QueryResponse, error)
+	QueryAddrInfo(who peer.AddrInfo) (*message.QueryResponse, error)
+	QueryRelayerFee(relayerID peer.ID) (*coins.WeiAmount, error)
+	RelayerFee() *coins.WeiAmount
 	Initiate(who peer.AddrInfo, sendKeysMessage common.Message, s common.SwapStateNet) error
 	CloseProtocolStream(types.Hash)
+	ProtocolStreams() []*net.ProtocolStreamInfo
+	BlockPeer(peerID peer.ID) error
+	UnblockPeer(peerID peer.ID) error
+	Advertise()
+	StopOffers()
 }
 
 // NetService is the RPC service prefixed by net_.
@@ -39,16 +59,20 @@ type NetService struct {
 	xmrmaker   XMRMaker
 	sm         SwapManager
 	isBootnode bool
+	isObserver bool        // there's no wallet, so we can't make, take or look up swaps
+	tokens     *tokenCache // nil if token metadata is not cached
+	offerKeys  *offerKeys
 }
 
 // NewNetService ...
-func NewNetService(net Net, xmrtaker XMRTaker, xmrmaker XMRMaker, sm SwapManager, isBootnode bool) *NetService {
+func NewNetService(network Net, xmrtaker XMRTaker, xmrmaker XMRMaker, sm SwapManager, isBootnode bool) *NetService {
 	return &NetService{
-		net:        net,
+		net:        network,
 		xmrtaker:   xmrtaker,
 		xmrmaker:   xmrmaker,
 		sm:         sm,
 		isBootnode: isBootnode,
+		offerKeys:  newOfferKeys(),
 	}
 }
 
@@ -72,6 +96,108 @@ func (s *NetService) Peers(_ *http.Request, _ *interface{}, resp *rpctypes.Peers
 	return nil
 }
 
+// GetProtocolStreams returns the open swap protocol streams, oldest first. At
+// most maxProtocolStreams streams are returned.
+func (s *NetService) GetProtocolStreams(
+	_ *http.Request,
+	_ *interface{},
+	resp *rpctypes.GetProtocolStreamsResponse,
+) error {
+	streams := s.net.ProtocolStreams()
+	sort.Slice(streams, func(i, j int) bool {
+		return streams[i].OpenedAt.Before(streams[j].OpenedAt)
+	})
+
+	if len(streams) > maxProtocolStreams {
+		streams = streams[:maxProtocolStreams]
+		resp.Truncated = true
+	}
+
+	resp.Streams = make([]*rpctypes.ProtocolStream, 0, len(streams))
+	for _, stream := range streams {
+		resp.Streams = append(resp.Streams, &rpctypes.ProtocolStream{
+			PeerID:   stream.PeerID,
+			OfferID:  stream.OfferID,
+			IsTaker:  stream.IsTaker,
+			OpenedAt: stream.OpenedAt,
+			OpenFor:  uint64(time.Since(stream.OpenedAt).Seconds()),
+		})
+	}
+
+	return nil
+}
+
+// PeerStats returns the outcomes of our completed swaps with the requested
+// peer, or with all peers that we completed swaps with, ordered from the most
+// to the fewest swaps.
+func (s *NetService) PeerStats(
+	_ *http.Request,
+	req *rpctypes.PeerStatsRequest,
+	resp *rpctypes.PeerStatsResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+	if s.isObserver {
+		return errObserverMode
+	}
+
+	allStats, err := s.sm.GetAllPeerStats()
+	if err != nil {
+		return err
+	}
+
+	if req.PeerID != "" {
+		stats, has := allStats[req.PeerID]
+		if !has {
+			stats = new(swap.PeerStats)
+		}
+		allStats = map[peer.ID]*swap.PeerStats{req.PeerID: stats}
+	}
+
+	resp.Peers = make([]*rpctypes.PeerStats, 0, len(allStats))
+	for peerID, stats := range allStats {
+		resp.Peers = append(resp.Peers, &rpctypes.PeerStats{
+			PeerID:       peerID,
+			NumSucceeded: stats.NumSucceeded,
+			NumRefunded:  stats.NumRefunded,
+			NumAborted:   stats.NumAborted,
+		})
+	}
+
+	sort.Slice(resp.Peers, func(i, j int) bool {
+		a, b := resp.Peers[i], resp.Peers[j]
+		numA := a.NumSucceeded + a.NumRefunded + a.NumAborted
+		numB := b.NumSucceeded + b.NumRefunded + b.NumAborted
+		if numA != numB {
+			return numA > numB
+		}
+		return a.PeerID < b.PeerID
+	})
+
+	return nil
+}
+
+// BlockPeer adds the peer to the blocklist. We refuse queries and swaps from
+// blocked peers, and leave them out of discovery and query results. The
+// blocklist is persisted across restarts.
+func (s *NetService) BlockPeer(_ *http.Request, req *rpctypes.BlockPeerRequest, _ *interface{}) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	return s.net.BlockPeer(req.PeerID)
+}
+
+// UnblockPeer removes the peer from the blocklist.
+func (s *NetService) UnblockPeer(_ *http.Request, req *rpctypes.BlockPeerRequest, _ *interface{}) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	return s.net.UnblockPeer(req.PeerID)
+}
+
 // QueryAll discovers peers who provide a certain coin and queries all of them for their current offers.
 func (s *NetService) QueryAll(_ *http.Request, req *rpctypes.QueryAllRequest, resp *rpctypes.QueryAllResponse) error {
 	if s.isBootnode {
@@ -99,6 +225,93 @@ func (s *NetService) QueryAll(_ *http.Request, req *rpctypes.QueryAllRequest, re
 	return nil
 }
 
+// QueryRelayers discovers the peers that relay claims for everyone and queries
+// each of them for its fee. Relayers are returned cheapest first, followed by
+// the relayers that did not return a fee.
+func (s *NetService) QueryRelayers(
+	_ *http.Request,
+	req *rpctypes.QueryRelayersRequest,
+	resp *rpctypes.QueryRelayersResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	relayers, err := s.queryRelayers(req.SearchTime)
+	if err != nil {
+		return err
+	}
+
+	resp.Relayers = relayers
+	return nil
+}
+
+// RelayerStatus returns the discovered relayers with their fees, like
+// QueryRelayers, and if our node relays claims for everyone, its fee and the
+// claims that it relayed since it started.
+func (s *NetService) RelayerStatus(
+	_ *http.Request,
+	req *rpctypes.RelayerStatusRequest,
+	resp *rpctypes.RelayerStatusResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	relayers, err := s.queryRelayers(req.SearchTime)
+	if err != nil {
+		return err
+	}
+	resp.Relayers = relayers
+
+	fee := s.net.RelayerFee()
+	if fee == nil {
+		return nil
+	}
+
+	stats := relayer.GetStats()
+	resp.IsRelayer = true
+	resp.Fee = fee.AsEther()
+	resp.NumRelayed = stats.NumRelayed
+	resp.TotalFees = coins.NewWeiAmount(stats.FeesWei).AsEther()
+	return nil
+}
+
+// queryRelayers discovers relayers and queries each of them for its fee,
+// returning them cheapest first, followed by those that did not return a fee.
+func (s *NetService) queryRelayers(searchTime uint64) ([]*rpctypes.RelayerWithFee, error) {
+	peerIDs, err := s.discover(&rpctypes.DiscoverRequest{
+		Provides:   net.RelayerProvidesStr,
+		SearchTime: searchTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relayers := make([]*rpctypes.RelayerWithFee, len(peerIDs))
+	for i, p := range peerIDs {
+		relayers[i] = &rpctypes.RelayerWithFee{
+			PeerID: p,
+		}
+		fee, err := s.net.QueryRelayerFee(p)
+		if err != nil {
+			log.Debugf("Failed to query fee of relayer %s: %s", p, err)
+			continue
+		}
+		relayers[i].Fee = fee.AsEther()
+	}
+
+	sort.SliceStable(relayers, func(i, j int) bool {
+		a, b := relayers[i].Fee, relayers[j].Fee
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Cmp(b) < 0
+	})
+
+	return relayers, nil
+}
+
 func (s *NetService) discover(req *rpctypes.DiscoverRequest) ([]peer.ID, error) {
 	searchTime, err := time.ParseDuration(fmt.Sprintf("%ds", req.SearchTime))
 	if err != nil {
@@ -150,6 +363,32 @@ func (s *NetService) QueryPeer(
 	return nil
 }
 
+// QueryPeerAddr queries a peer at the given multiaddr for its offers. The
+// multiaddr must include the peer's ID. Unlike QueryPeer, the peer does not
+// need to be discoverable via the DHT.
+func (s *NetService) QueryPeerAddr(
+	_ *http.Request,
+	req *rpctypes.QueryPeerAddrRequest,
+	resp *rpctypes.QueryPeerResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+
+	addrInfo, err := peer.AddrInfoFromString(req.Multiaddr)
+	if err != nil {
+		return fmt.Errorf("invalid multiaddr %q: %w", req.Multiaddr, err)
+	}
+
+	msg, err := s.net.QueryAddrInfo(*addrInfo)
+	if err != nil {
+		return err
+	}
+
+	resp.Offers = msg.Offers
+	return nil
+}
+
 // TakeOffer initiates a swap with the given peer by taking an offer they've made.
 func (s *NetService) TakeOffer(
 	_ *http.Request,
@@ -159,8 +398,11 @@ func (s *NetService) TakeOffer(
 	if s.isBootnode {
 		return errUnsupportedForBootnode
 	}
+	if s.isObserver {
+		return errObserverMode
+	}
 
-	_, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount)
+	_, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount, req.MaxExchangeRate)
 	if err != nil {
 		return err
 	}
@@ -168,30 +410,22 @@ func (s *NetService) TakeOffer(
 	return nil
 }
 
-func (s *NetService) takeOffer(makerPeerID peer.ID, offerID types.Hash, providesAmount *apd.Decimal) (
-	<-chan types.Status,
-	error,
-) {
-	queryResp, err := s.net.Query(makerPeerID)
+func (s *NetService) takeOffer(
+	makerPeerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+	maxExchangeRate *coins.ExchangeRate,
+) (<-chan types.Status, error) {
+	offer, err := queryOffer(s.net, makerPeerID, offerID)
 	if err != nil {
 		return nil, err
 	}
 
-	var offer *types.Offer
-	for _, maybeOffer := range queryResp.Offers {
-		if offerID == maybeOffer.ID {
-			offer = maybeOffer
-			break
-		}
-	}
-	if offer == nil {
-		return nil, errNoOfferWithID
-	}
-
-	swapState, err := s.xmrtaker.InitiateProtocol(makerPeerID, providesAmount, offer)
+	swapState, err := s.xmrtaker.InitiateProtocol(makerPeerID, providesAmount, offer, maxExchangeRate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate protocol: %w", err)
 	}
+	s.tokens.addAsset(offer.EthAsset)
 
 	skm := swapState.SendKeysMessage().(*message.SendKeysMessage)
 	skm.OfferID = offerID
@@ -212,6 +446,23 @@ func (s *NetService) takeOffer(makerPeerID peer.ID, offerID types.Hash, provides
 	return info.StatusCh(), nil
 }
 
+// queryOffer queries the maker for its offers and returns the one with the
+// given ID.
+func queryOffer(n Net, makerPeerID peer.ID, offerID types.Hash) (*types.Offer, error) {
+	queryResp, err := n.Query(makerPeerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, offer := range queryResp.Offers {
+		if offerID == offer.ID {
+			return offer, nil
+		}
+	}
+
+	return nil, errNoOfferWithID
+}
+
 // TakeOfferSyncResponse ...
 type TakeOfferSyncResponse struct {
 	Status types.Status `json:"status" validate:"required"`
@@ -227,8 +478,11 @@ func (s *NetService) TakeOfferSync(
 	if s.isBootnode {
 		return errUnsupportedForBootnode
 	}
+	if s.isObserver {
+		return errObserverMode
+	}
 
-	if _, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount); err != nil {
+	if _, err := s.takeOffer(req.PeerID, req.OfferID, req.ProvidesAmount, req.MaxExchangeRate); err != nil {
 		return err
 	}
 
@@ -253,6 +507,22 @@ func (s *NetService) TakeOfferSync(
 	return nil
 }
 
+// Readvertise re-advertises us in the DHT as a provider of our current offers,
+// for when our DHT records expired or we reconnected after being offline. The
+// advertising happens in the background.
+func (s *NetService) Readvertise(_ *http.Request, _ *interface{}, resp *rpctypes.ReadvertiseResponse) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+	if s.isObserver {
+		return errObserverMode
+	}
+
+	s.net.Advertise()
+	resp.NumOffers = len(s.xmrmaker.GetOffers())
+	return nil
+}
+
 // MakeOffer creates and advertises a new swap offer.
 func (s *NetService) MakeOffer(
 	_ *http.Request,
@@ -262,6 +532,9 @@ func (s *NetService) MakeOffer(
 	if s.isBootnode {
 		return errUnsupportedForBootnode
 	}
+	if s.isObserver {
+		return errObserverMode
+	}
 
 	offerResp, _, err := s.makeOffer(req)
 	if err != nil {
@@ -271,7 +544,109 @@ func (s *NetService) MakeOffer(
 	return nil
 }
 
+// ValidateOffer runs the same checks as MakeOffer and returns the offer's
+// amounts, without creating or advertising the offer.
+func (s *NetService) ValidateOffer(
+	_ *http.Request,
+	req *rpctypes.MakeOfferRequest,
+	resp *rpctypes.ValidateOfferResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+	if s.isObserver {
+		return errObserverMode
+	}
+
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		req.MinAmount,
+		req.MaxAmount,
+		req.ExchangeRate,
+		req.EthAsset,
+	)
+
+	summary, err := s.xmrmaker.ValidateOffer(
+		offer,
+		req.UseRelayer,
+		offerExpiresAt(req),
+		req.MinTakerReputation,
+		req.Repricing,
+	)
+	if err != nil {
+		return err
+	}
+
+	*resp = *summary
+	return nil
+}
+
+// GetOfferDefaults returns the amounts and exchange rate of the most recent
+// offer made for the given asset.
+func (s *NetService) GetOfferDefaults(
+	_ *http.Request,
+	req *rpctypes.GetOfferDefaultsRequest,
+	resp *rpctypes.GetOfferDefaultsResponse,
+) error {
+	if s.isBootnode {
+		return errUnsupportedForBootnode
+	}
+	if s.isObserver {
+		return errObserverMode
+	}
+
+	defaults, err := s.xmrmaker.GetOfferDefaults(req.EthAsset)
+	if err != nil {
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			return fmt.Errorf("no previous offer found for asset %s", req.EthAsset)
+		}
+		return err
+	}
+
+	resp.MinAmount = defaults.MinAmount
+	resp.MaxAmount = defaults.MaxAmount
+	resp.ExchangeRate = defaults.ExchangeRate
+	return nil
+}
+
+// makeOffer makes the requested offer. If the request has an idempotency key
+// that an offer was already made for, that offer is returned instead, along
+// with its extra info if it is still current, or nil if it is not.
 func (s *NetService) makeOffer(req *rpctypes.MakeOfferRequest) (*rpctypes.MakeOfferResponse, *types.OfferExtra, error) {
+	if req.IdempotencyKey == "" {
+		return s.makeNewOffer(req)
+	}
+
+	s.offerKeys.Lock()
+	defer s.offerKeys.Unlock()
+
+	offerID, err := s.offerKeys.lookup(req, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if offerID != nil {
+		return &rpctypes.MakeOfferResponse{
+			PeerID:  s.net.PeerID(),
+			OfferID: *offerID,
+		}, s.xmrmaker.OfferExtra(*offerID), nil
+	}
+
+	resp, offerExtra, err := s.makeNewOffer(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = s.offerKeys.add(req, resp.OfferID, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	return resp, offerExtra, nil
+}
+
+func (s *NetService) makeNewOffer(
+	req *rpctypes.MakeOfferRequest,
+) (*rpctypes.MakeOfferResponse, *types.OfferExtra, error) {
 	offer := types.NewOffer(
 		coins.ProvidesXMR,
 		req.MinAmount,
@@ -280,13 +655,32 @@ func (s *NetService) makeOffer(req *rpctypes.MakeOfferRequest) (*rpctypes.MakeOf
 		req.EthAsset,
 	)
 
-	offerExtra, err := s.xmrmaker.MakeOffer(offer, req.UseRelayer)
+	offerExtra, err := s.xmrmaker.MakeOffer(
+		offer,
+		req.UseRelayer,
+		offerExpiresAt(req),
+		req.MinTakerReputation,
+		req.Persistent,
+		req.Repricing,
+	)
 	if err != nil {
 		return nil, nil, err
 	}
+	s.tokens.addAsset(offer.EthAsset)
 
 	return &rpctypes.MakeOfferResponse{
 		PeerID:  s.net.PeerID(),
 		OfferID: offer.ID,
 	}, offerExtra, nil
 }
+
+// offerExpiresAt returns the time at which the requested offer expires, or nil
+// if it does not expire.
+func offerExpiresAt(req *rpctypes.MakeOfferRequest) *time.Time {
+	if req.ExpiresIn == 0 {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+	return &expiresAt
+}
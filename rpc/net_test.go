@@ -4,11 +4,16 @@
 package rpc
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
 
 	"github.com/stretchr/testify/require"
 )
@@ -41,6 +46,77 @@ func TestNet_Query(t *testing.T) {
 	require.Equal(t, 1, len(resp.Offers))
 }
 
+func TestNet_QueryRelayers(t *testing.T) {
+	legacy, expensive, cheap := peer.ID("legacy"), peer.ID("expensive"), peer.ID("cheap")
+	network := &mockNet{
+		discovered: []peer.ID{legacy, expensive, cheap},
+		relayerFees: map[peer.ID]*coins.WeiAmount{
+			expensive: coins.NewWeiAmount(big.NewInt(2e16)),
+			cheap:     coins.NewWeiAmount(big.NewInt(5e15)),
+		},
+	}
+	ns := NewNetService(network, new(mockXMRTaker), nil, new(mockSwapManager), false)
+
+	resp := new(rpctypes.QueryRelayersResponse)
+	err := ns.QueryRelayers(nil, new(rpctypes.QueryRelayersRequest), resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Relayers, 3)
+
+	require.Equal(t, cheap, resp.Relayers[0].PeerID)
+	require.Equal(t, "0.005", resp.Relayers[0].Fee.Text('f'))
+	require.Equal(t, expensive, resp.Relayers[1].PeerID)
+	require.Equal(t, "0.02", resp.Relayers[1].Fee.Text('f'))
+	require.Equal(t, legacy, resp.Relayers[2].PeerID)
+	require.Nil(t, resp.Relayers[2].Fee)
+}
+
+func TestNet_RelayerStatus(t *testing.T) {
+	relayerID := peer.ID("relayer")
+	network := &mockNet{
+		discovered: []peer.ID{relayerID},
+		relayerFees: map[peer.ID]*coins.WeiAmount{
+			relayerID: coins.NewWeiAmount(big.NewInt(5e15)),
+		},
+	}
+	ns := NewNetService(network, new(mockXMRTaker), nil, new(mockSwapManager), false)
+
+	resp := new(rpctypes.RelayerStatusResponse)
+	err := ns.RelayerStatus(nil, new(rpctypes.RelayerStatusRequest), resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Relayers, 1)
+	require.Equal(t, "0.005", resp.Relayers[0].Fee.Text('f'))
+	require.False(t, resp.IsRelayer)
+	require.Nil(t, resp.Fee)
+	require.Nil(t, resp.TotalFees)
+
+	network.relayerFee = coins.NewWeiAmount(coins.RelayerFeeWei)
+	resp = new(rpctypes.RelayerStatusResponse)
+	err = ns.RelayerStatus(nil, new(rpctypes.RelayerStatusRequest), resp)
+	require.NoError(t, err)
+	require.True(t, resp.IsRelayer)
+	require.Equal(t, "0.009", resp.Fee.Text('f'))
+	require.NotNil(t, resp.TotalFees)
+}
+
+func TestNet_QueryPeerAddr(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
+
+	req := &rpctypes.QueryPeerAddrRequest{
+		Multiaddr: "/ip4/127.0.0.1/tcp/9900/p2p/12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
+	}
+
+	resp := new(rpctypes.QueryPeerResponse)
+
+	err := ns.QueryPeerAddr(nil, req, resp)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(resp.Offers))
+
+	// the multiaddr must include the peer ID
+	req.Multiaddr = "/ip4/127.0.0.1/tcp/9900"
+	err = ns.QueryPeerAddr(nil, req, resp)
+	require.Error(t, err)
+}
+
 func TestNet_TakeOffer(t *testing.T) {
 	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
 
@@ -68,3 +144,99 @@ func TestNet_TakeOfferSync(t *testing.T) {
 	err := ns.TakeOfferSync(nil, req, resp)
 	require.NoError(t, err)
 }
+
+func TestNet_PeerStats(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager), false)
+
+	resp := new(rpctypes.PeerStatsResponse)
+	err := ns.PeerStats(nil, new(rpctypes.PeerStatsRequest), resp)
+	require.NoError(t, err)
+	require.Equal(t, []*rpctypes.PeerStats{
+		{PeerID: testPeerID, NumSucceeded: 3, NumRefunded: 1},
+	}, resp.Peers)
+
+	// peers without completed swaps have empty stats
+	otherPeerID, err := peer.Decode("12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5")
+	require.NoError(t, err)
+	err = ns.PeerStats(nil, &rpctypes.PeerStatsRequest{PeerID: otherPeerID}, resp)
+	require.NoError(t, err)
+	require.Equal(t, []*rpctypes.PeerStats{{PeerID: otherPeerID}}, resp.Peers)
+}
+
+func TestNet_MakeOffer_idempotencyKey(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), new(mockXMRMaker), new(mockSwapManager), false)
+
+	newRequest := func(key string) *rpctypes.MakeOfferRequest {
+		return &rpctypes.MakeOfferRequest{
+			MinAmount:      coins.StrToDecimal("0.1"),
+			MaxAmount:      coins.StrToDecimal("1"),
+			ExchangeRate:   coins.ToExchangeRate(coins.StrToDecimal("0.05")),
+			IdempotencyKey: key,
+		}
+	}
+
+	makeOffer := func(req *rpctypes.MakeOfferRequest) types.Hash {
+		resp := new(rpctypes.MakeOfferResponse)
+		require.NoError(t, ns.MakeOffer(nil, req, resp))
+		return resp.OfferID
+	}
+
+	// a retried request returns the offer made by the first request
+	offerID := makeOffer(newRequest("key1"))
+	require.Equal(t, offerID, makeOffer(newRequest("key1")))
+
+	// requests with other keys, or with no key, make new offers
+	otherOfferID := makeOffer(newRequest("key2"))
+	require.NotEqual(t, offerID, otherOfferID)
+	require.NotEqual(t, offerID, makeOffer(newRequest("")))
+	require.NotEqual(t, makeOffer(newRequest("")), makeOffer(newRequest("")))
+
+	// a key can't be reused for a different offer
+	req := newRequest("key1")
+	req.MaxAmount = coins.StrToDecimal("2")
+	err := ns.MakeOffer(nil, req, new(rpctypes.MakeOfferResponse))
+	require.ErrorIs(t, err, errIdempotencyKeyReused)
+}
+
+func TestOfferKeys_prune(t *testing.T) {
+	keys := newOfferKeys()
+	req := &rpctypes.MakeOfferRequest{IdempotencyKey: "key"}
+	now := time.Now()
+	require.NoError(t, keys.add(req, types.Hash{1}, now))
+
+	offerID, err := keys.lookup(req, now.Add(offerKeyTTL))
+	require.NoError(t, err)
+	require.Equal(t, &types.Hash{1}, offerID)
+
+	offerID, err = keys.lookup(req, now.Add(offerKeyTTL+time.Second))
+	require.NoError(t, err)
+	require.Nil(t, offerID)
+}
+
+func TestNet_Readvertise(t *testing.T) {
+	network := new(mockNet)
+	ns := NewNetService(network, new(mockXMRTaker), new(mockXMRMaker), new(mockSwapManager), false)
+
+	resp := new(rpctypes.ReadvertiseResponse)
+	require.NoError(t, ns.Readvertise(nil, nil, resp))
+	require.True(t, network.advertised)
+	require.Zero(t, resp.NumOffers)
+
+	ns = NewNetService(new(mockNet), nil, nil, nil, true)
+	err := ns.Readvertise(nil, nil, resp)
+	require.ErrorIs(t, err, errUnsupportedForBootnode)
+}
+
+func TestNet_observer(t *testing.T) {
+	ns := NewNetService(new(mockNet), nil, nil, nil, false)
+	ns.isObserver = true
+
+	err := ns.Readvertise(nil, nil, new(rpctypes.ReadvertiseResponse))
+	require.ErrorIs(t, err, errObserverMode)
+
+	err = ns.TakeOffer(nil, new(rpctypes.TakeOfferRequest), nil)
+	require.ErrorIs(t, err, errObserverMode)
+
+	err = ns.MakeOffer(nil, new(rpctypes.MakeOfferRequest), new(rpctypes.MakeOfferResponse))
+	require.ErrorIs(t, err, errObserverMode)
+}
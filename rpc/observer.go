@@ -0,0 +1,44 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+)
+
+// ObserverSwapService is the RPC service prefixed by swap_ when swapd runs in
+// observer mode. There is no wallet or swap backend, so it only serves the
+// suggested exchange rate.
+type ObserverSwapService struct {
+	ctx   context.Context
+	ec    extethclient.EthClient
+	rates *rateCache // nil to read the Chainlink price feeds on every request
+}
+
+// NewObserverSwapService ...
+func NewObserverSwapService(ctx context.Context, ec extethclient.EthClient) *ObserverSwapService {
+	return &ObserverSwapService{
+		ctx: ctx,
+		ec:  ec,
+	}
+}
+
+// SuggestedExchangeRate returns the current mainnet exchange rate, expressed as the XMR/ETH price.
+// If swapd caches the rate, the cached rate is returned until it expires, unless a refresh is forced.
+func (s *ObserverSwapService) SuggestedExchangeRate(
+	_ *http.Request,
+	req *SuggestedExchangeRateRequest,
+	resp *SuggestedExchangeRateResponse,
+) error {
+	rate, err := s.rates.get(s.ctx, s.ec.Raw(), req.ForceRefresh)
+	if err != nil {
+		return err
+	}
+
+	*resp = *rate
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// offerKeyTTL is how long the offer made for an idempotency key is remembered.
+const offerKeyTTL = 24 * time.Hour
+
+// keyedOffer is the offer that was made for an idempotency key.
+type keyedOffer struct {
+	offerID types.Hash
+	request []byte // the request without its key, to detect reuse of the key
+	madeAt  time.Time
+}
+
+// offerKeys remembers the offers made for recent make-offer idempotency keys,
+// so that a retried request returns the offer made by the first request
+// instead of making a duplicate offer. The keys are only kept in memory.
+type offerKeys struct {
+	// held while an offer is made for a key, so that concurrent requests with
+	// the same key make a single offer
+	sync.Mutex
+	offers map[string]*keyedOffer
+}
+
+func newOfferKeys() *offerKeys {
+	return &offerKeys{
+		offers: make(map[string]*keyedOffer),
+	}
+}
+
+// lookup returns the ID of the offer made for the request's idempotency key,
+// if one was made. It returns an error if the key was used for a request with
+// different parameters. The caller must hold the lock.
+func (k *offerKeys) lookup(req *rpctypes.MakeOfferRequest, now time.Time) (*types.Hash, error) {
+	k.prune(now)
+
+	offer, has := k.offers[req.IdempotencyKey]
+	if !has {
+		return nil, nil
+	}
+
+	request, err := requestWithoutKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(request, offer.request) {
+		return nil, errIdempotencyKeyReused
+	}
+
+	return &offer.offerID, nil
+}
+
+// add remembers the offer made for the request's idempotency key. The caller
+// must hold the lock.
+func (k *offerKeys) add(req *rpctypes.MakeOfferRequest, offerID types.Hash, now time.Time) error {
+	request, err := requestWithoutKey(req)
+	if err != nil {
+		return err
+	}
+
+	k.offers[req.IdempotencyKey] = &keyedOffer{
+		offerID: offerID,
+		request: request,
+		madeAt:  now,
+	}
+	return nil
+}
+
+// prune forgets the keys older than offerKeyTTL.
+func (k *offerKeys) prune(now time.Time) {
+	for key, offer := range k.offers {
+		if now.Sub(offer.madeAt) > offerKeyTTL {
+			delete(k.offers, key)
+		}
+	}
+}
+
+func requestWithoutKey(req *rpctypes.MakeOfferRequest) ([]byte, error) {
+	reqCopy := *req
+	reqCopy.IdempotencyKey = ""
+	return json.Marshal(&reqCopy)
+}
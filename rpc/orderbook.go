@@ -0,0 +1,114 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// OrderbookRequest ...
+type OrderbookRequest struct {
+	SearchTime uint64 `json:"searchTime"` // in seconds
+}
+
+// OrderbookEntry is an offer in the order book and the peer that made it.
+type OrderbookEntry struct {
+	PeerID peer.ID      `json:"peerID" validate:"required"`
+	Offer  *types.Offer `json:"offer" validate:"required"`
+}
+
+// OrderbookSummary summarises the offers of the order book for one ETH asset.
+// Makers only advertise offers that provide XMR, so every offer is an ask.
+type OrderbookSummary struct {
+	EthAsset  types.EthAsset  `json:"ethAsset"`
+	NumOffers int             `json:"numOffers"`
+	BestAsk   *OrderbookEntry `json:"bestAsk" validate:"required"`  // lowest exchange rate
+	WorstAsk  *OrderbookEntry `json:"worstAsk" validate:"required"` // highest exchange rate
+}
+
+// OrderbookResponse ...
+type OrderbookResponse struct {
+	NumPeers       int                 `json:"numPeers"`       // number of discovered peers
+	NumPeersFailed int                 `json:"numPeersFailed"` // peers that could not be queried
+	Offers         []*OrderbookEntry   `json:"offers" validate:"dive,required"`
+	Summaries      []*OrderbookSummary `json:"summaries" validate:"dive,required"`
+}
+
+// Orderbook discovers the peers that provide XMR, queries each of them for its
+// offers and returns the offers of all peers as one order book. Offers are
+// grouped by ETH asset, with ETH first, and sorted by exchange rate from the
+// lowest to the highest within each asset.
+func (s *SwapService) Orderbook(_ *http.Request, req *OrderbookRequest, resp *OrderbookResponse) error {
+	searchTime := time.Duration(req.SearchTime) * time.Second
+	if searchTime == 0 {
+		searchTime = defaultSearchTime
+	}
+
+	peerIDs, err := s.net.Discover(string(coins.ProvidesXMR), searchTime)
+	if err != nil {
+		return err
+	}
+
+	resp.NumPeers = len(peerIDs)
+	resp.Offers = []*OrderbookEntry{}
+	for _, p := range peerIDs {
+		msg, err := s.net.Query(p) //nolint:govet
+		if err != nil {
+			log.Debugf("Failed to query peer ID %s: %s", p, err)
+			resp.NumPeersFailed++
+			continue
+		}
+
+		for _, o := range msg.Offers {
+			resp.Offers = append(resp.Offers, &OrderbookEntry{PeerID: p, Offer: o})
+		}
+	}
+
+	sortOrderbook(resp.Offers)
+	resp.Summaries = summariseOrderbook(resp.Offers)
+	return nil
+}
+
+// sortOrderbook sorts the entries by ETH asset, with ETH first, and then by
+// exchange rate from the lowest to the highest. Entries with the same asset and
+// exchange rate keep the order in which they were received.
+func sortOrderbook(entries []*OrderbookEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Offer, entries[j].Offer
+		if a.EthAsset != b.EthAsset {
+			return bytes.Compare(a.EthAsset[:], b.EthAsset[:]) < 0
+		}
+		return a.ExchangeRate.Cmp(b.ExchangeRate) < 0
+	})
+}
+
+// summariseOrderbook returns the summary of each ETH asset in the sorted order
+// book, in the order of the assets in the book.
+func summariseOrderbook(entries []*OrderbookEntry) []*OrderbookSummary {
+	summaries := []*OrderbookSummary{}
+
+	var current *OrderbookSummary
+	for _, e := range entries {
+		if current == nil || current.EthAsset != e.Offer.EthAsset {
+			current = &OrderbookSummary{
+				EthAsset: e.Offer.EthAsset,
+				BestAsk:  e,
+			}
+			summaries = append(summaries, current)
+		}
+
+		current.NumOffers++
+		current.WorstAsk = e
+	}
+
+	return summaries
+}
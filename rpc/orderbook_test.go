@@ -0,0 +1,83 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+func TestSwap_Orderbook(t *testing.T) {
+	newOffer := func(rate string, asset types.EthAsset) *types.Offer {
+		return types.NewOffer(
+			coins.ProvidesXMR,
+			apd.New(1, 0),
+			apd.New(2, 0),
+			coins.StrToExchangeRate(rate),
+			asset,
+		)
+	}
+
+	token := types.EthAsset(ethcommon.HexToAddress("0x1"))
+	alice, bob, offline := peer.ID("alice"), peer.ID("bob"), peer.ID("offline")
+	aliceETH, aliceToken := newOffer("0.07", types.EthAssetETH), newOffer("150", token)
+	bobLow, bobHigh := newOffer("0.05", types.EthAssetETH), newOffer("0.09", types.EthAssetETH)
+
+	network := &mockNet{
+		discovered: []peer.ID{alice, bob, offline},
+		offers: map[peer.ID][]*types.Offer{
+			alice: {aliceToken, aliceETH},
+			bob:   {bobHigh, bobLow},
+		},
+	}
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		network,
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(OrderbookResponse)
+	require.NoError(t, ss.Orderbook(nil, new(OrderbookRequest), resp))
+	require.Equal(t, 3, resp.NumPeers)
+	require.Equal(t, 1, resp.NumPeersFailed)
+	require.Equal(t, []*OrderbookEntry{
+		{PeerID: bob, Offer: bobLow},
+		{PeerID: alice, Offer: aliceETH},
+		{PeerID: bob, Offer: bobHigh},
+		{PeerID: alice, Offer: aliceToken},
+	}, resp.Offers)
+
+	require.Len(t, resp.Summaries, 2)
+	require.Equal(t, &OrderbookSummary{
+		EthAsset:  types.EthAssetETH,
+		NumOffers: 3,
+		BestAsk:   resp.Offers[0],
+		WorstAsk:  resp.Offers[2],
+	}, resp.Summaries[0])
+	require.Equal(t, &OrderbookSummary{
+		EthAsset:  token,
+		NumOffers: 1,
+		BestAsk:   resp.Offers[3],
+		WorstAsk:  resp.Offers[3],
+	}, resp.Summaries[1])
+
+	// no peers gives an empty order book
+	network.discovered = nil
+	resp = new(OrderbookResponse)
+	require.NoError(t, ss.Orderbook(nil, new(OrderbookRequest), resp))
+	require.Empty(t, resp.Offers)
+	require.Empty(t, resp.Summaries)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+)
+
+// AllOrigins is the origin allowlist entry that allows requests from any origin.
+const AllOrigins = "*"
+
+// restrictOrigins returns a handler that rejects requests whose Origin header
+// is not one of the allowed origins with 403 Forbidden. The CORS headers alone
+// only stop browsers from reading the responses to cross-origin requests, not
+// from sending them, and are not used for websocket connections. Requests
+// without an Origin header, which browsers always send for cross-origin
+// requests, are not from a web page and are passed to next.
+func restrictOrigins(allowed []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && !originAllowed(allowed, origin) {
+			log.Debugf("rejected %s request to %s from origin %q", r.Method, r.URL.Path, origin)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed returns true if the origin is in the allowlist. Origins are
+// compared exactly, the same as the CORS handler does.
+func originAllowed(allowed []string, origin string) bool {
+	for _, a := range allowed {
+		if a == AllOrigins || a == origin {
+			return true
+		}
+	}
+	return false
+}
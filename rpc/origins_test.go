@@ -0,0 +1,38 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictOrigins(t *testing.T) {
+	handler := restrictOrigins(
+		[]string{"http://localhost:3000", "https://swap.example.org"},
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	for origin, expectedCode := range map[string]int{
+		"":                         http.StatusOK, // not from a browser
+		"http://localhost:3000":    http.StatusOK,
+		"https://swap.example.org": http.StatusOK,
+		"http://localhost:3001":    http.StatusForbidden,
+		"https://evil.example.org": http.StatusForbidden,
+		"null":                     http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, expectedCode, rec.Code, "origin %q", origin)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 )
 
@@ -18,6 +19,7 @@ type PersonalService struct {
 	ctx      context.Context
 	xmrmaker XMRMaker
 	pb       ProtocolBackend
+	tokens   *tokenCache // nil if token metadata is not cached
 }
 
 // NewPersonalService ...
@@ -31,24 +33,63 @@ func NewPersonalService(ctx context.Context, xmrmaker XMRMaker, pb ProtocolBacke
 
 // SetSwapTimeoutRequest ...
 type SetSwapTimeoutRequest struct {
-	Timeout uint64 `json:"timeout" validate:"required"` // timeout in seconds
+	// Timeout, if set, is the duration used for both T0Duration and
+	// T1Duration, unless they are set themselves.
+	Timeout uint64 `json:"timeout,omitempty"` // timeout in seconds
+	// T0Duration and T1Duration, if set, are the durations between the swap
+	// being initiated on-chain and t0, and between t0 and t1. A duration
+	// that is not set, either here or by Timeout, is left unchanged.
+	T0Duration uint64 `json:"t0Duration,omitempty"` // in seconds
+	T1Duration uint64 `json:"t1Duration,omitempty"` // in seconds
 }
 
-// SetSwapTimeout ...
+// SetSwapTimeout sets the durations of the swap timeouts of the swaps that we
+// initiate on-chain.
 func (s *PersonalService) SetSwapTimeout(_ *http.Request, req *SetSwapTimeoutRequest, _ *interface{}) error {
-	timeout := time.Second * time.Duration(req.Timeout)
-	s.pb.SetSwapTimeout(timeout)
+	if req.Timeout == 0 && req.T0Duration == 0 && req.T1Duration == 0 {
+		return errNoSwapTimeout
+	}
+
+	t0Duration, t1Duration := s.pb.SwapTimeout()
+	if req.Timeout != 0 {
+		t0Duration = time.Second * time.Duration(req.Timeout)
+		t1Duration = t0Duration
+	}
+	if req.T0Duration != 0 {
+		t0Duration = time.Second * time.Duration(req.T0Duration)
+	}
+	if req.T1Duration != 0 {
+		t1Duration = time.Second * time.Duration(req.T1Duration)
+	}
+
+	// makers reject swaps with durations outside of the bounds
+	minDuration, maxDuration := common.SwapTimeoutBoundsFromEnv(s.pb.Env())
+	for _, d := range []time.Duration{t0Duration, t1Duration} {
+		if d < minDuration || d > maxDuration {
+			return fmt.Errorf("%w: must be between %s and %s", errSwapTimeoutOutOfBounds, minDuration, maxDuration)
+		}
+	}
+
+	s.pb.SetSwapTimeout(t0Duration, t1Duration)
 	return nil
 }
 
 // GetSwapTimeoutResponse ...
 type GetSwapTimeoutResponse struct {
-	Timeout uint64 `json:"timeout"` // timeout in seconds
+	// Timeout is the same as T0Duration, for clients that only know of a
+	// single duration.
+	Timeout    uint64 `json:"timeout"`    // timeout in seconds
+	T0Duration uint64 `json:"t0Duration"` // in seconds
+	T1Duration uint64 `json:"t1Duration"` // in seconds
 }
 
-// GetSwapTimeout ...
+// GetSwapTimeout returns the durations of the swap timeouts of the swaps that
+// we initiate on-chain.
 func (s *PersonalService) GetSwapTimeout(_ *http.Request, _ *interface{}, resp *GetSwapTimeoutResponse) error {
-	resp.Timeout = uint64(s.pb.SwapTimeout().Seconds())
+	t0Duration, t1Duration := s.pb.SwapTimeout()
+	resp.Timeout = uint64(t0Duration.Seconds())
+	resp.T0Duration = uint64(t0Duration.Seconds())
+	resp.T1Duration = uint64(t1Duration.Seconds())
 	return nil
 }
 
@@ -63,13 +104,22 @@ func (s *PersonalService) SetGasPrice(_ *http.Request, req *SetGasPriceRequest,
 	return nil
 }
 
-// TokenInfo looks up the ERC20 token's metadata
+// TokenInfo looks up the ERC20 token's metadata. Cached metadata is returned
+// unless a refresh is requested, in which case the cached metadata is replaced.
 func (s *PersonalService) TokenInfo(
 	_ *http.Request,
 	req *rpctypes.TokenInfoRequest,
 	resp *rpctypes.TokenInfoResponse,
 ) error {
-	tokenInfo, err := s.pb.ETHClient().ERC20Info(s.ctx, req.TokenAddr)
+	var (
+		tokenInfo *coins.ERC20TokenInfo
+		err       error
+	)
+	if s.tokens != nil {
+		tokenInfo, err = s.tokens.tokenInfo(req.TokenAddr, req.Refresh)
+	} else {
+		tokenInfo, err = s.pb.ETHClient().ERC20Info(s.ctx, req.TokenAddr)
+	}
 	if err != nil {
 		return err
 	}
@@ -78,6 +128,36 @@ func (s *PersonalService) TokenInfo(
 	return nil
 }
 
+// MoneroSyncStatusResponse ...
+type MoneroSyncStatusResponse struct {
+	// WalletHeight is the height to which our Monero wallet is synced
+	WalletHeight uint64 `json:"walletHeight"`
+	// DaemonHeight is the height of the monerod node used by the wallet
+	DaemonHeight uint64 `json:"daemonHeight"`
+	// TargetHeight is the height of the chain that monerod is syncing to,
+	// which is DaemonHeight once monerod is synced
+	TargetHeight uint64 `json:"targetHeight"`
+	// IsSynced is true if both monerod and the wallet are fully synced
+	IsSynced bool `json:"isSynced"`
+}
+
+// MoneroSyncStatus returns how far our Monero wallet and the monerod node it
+// uses are synced.
+func (s *PersonalService) MoneroSyncStatus(_ *http.Request, _ *interface{}, resp *MoneroSyncStatusResponse) error {
+	status, err := s.pb.XMRClient().GetSyncStatus()
+	if err != nil {
+		return err
+	}
+
+	*resp = MoneroSyncStatusResponse{
+		WalletHeight: status.WalletHeight,
+		DaemonHeight: status.DaemonHeight,
+		TargetHeight: status.TargetHeight,
+		IsSynced:     status.IsSynced,
+	}
+	return nil
+}
+
 // Balances returns combined information of both the Monero and Ethereum account addresses
 // and balances.
 func (s *PersonalService) Balances(
@@ -103,6 +183,7 @@ func (s *PersonalService) Balances(
 			if err != nil {
 				return fmt.Errorf("unable to get balance for %s: %w", tokenAddr, err)
 			}
+			s.tokens.add(balance.TokenInfo)
 
 			tokenBalances = append(tokenBalances, balance)
 		}
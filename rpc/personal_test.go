@@ -0,0 +1,80 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/common"
+)
+
+func TestPersonalService_SetSwapTimeout(t *testing.T) {
+	pb := newMockProtocolBackend()
+	ps := NewPersonalService(context.Background(), nil, pb)
+
+	// a single timeout sets both durations
+	err := ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{Timeout: 60}, nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, pb.t0Duration)
+	require.Equal(t, time.Minute, pb.t1Duration)
+
+	// a duration that is not given is left unchanged
+	err = ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{T1Duration: 120}, nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, pb.t0Duration)
+	require.Equal(t, 2*time.Minute, pb.t1Duration)
+
+	err = ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{Timeout: 60, T0Duration: 30}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, pb.t0Duration)
+	require.Equal(t, time.Minute, pb.t1Duration)
+
+	resp := new(GetSwapTimeoutResponse)
+	require.NoError(t, ps.GetSwapTimeout(nil, nil, resp))
+	require.Equal(t, uint64(30), resp.Timeout)
+	require.Equal(t, uint64(30), resp.T0Duration)
+	require.Equal(t, uint64(60), resp.T1Duration)
+
+	err = ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{}, nil)
+	require.ErrorIs(t, err, errNoSwapTimeout)
+}
+
+func TestPersonalService_SetSwapTimeout_bounds(t *testing.T) {
+	pb := newMockProtocolBackend()
+	pb.env = common.Mainnet
+	ps := NewPersonalService(context.Background(), nil, pb)
+
+	// asymmetric durations are fine, as long as both are within the bounds
+	err := ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{T0Duration: 40 * 60, T1Duration: 2 * 60 * 60}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 40*time.Minute, pb.t0Duration)
+	require.Equal(t, 2*time.Hour, pb.t1Duration)
+
+	err = ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{T0Duration: 60}, nil)
+	require.ErrorIs(t, err, errSwapTimeoutOutOfBounds)
+
+	err = ps.SetSwapTimeout(nil, &SetSwapTimeoutRequest{T1Duration: 5 * 60 * 60}, nil)
+	require.ErrorIs(t, err, errSwapTimeoutOutOfBounds)
+
+	// rejected values leave the durations unchanged
+	require.Equal(t, 40*time.Minute, pb.t0Duration)
+	require.Equal(t, 2*time.Hour, pb.t1Duration)
+}
+
+func TestPersonalService_MoneroSyncStatus(t *testing.T) {
+	ps := NewPersonalService(context.Background(), nil, newMockProtocolBackend())
+
+	resp := new(MoneroSyncStatusResponse)
+	require.NoError(t, ps.MoneroSyncStatus(nil, nil, resp))
+	require.Equal(t, &MoneroSyncStatusResponse{
+		WalletHeight: 90,
+		DaemonHeight: 100,
+		TargetHeight: 120,
+		IsSynced:     false,
+	}, resp)
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+)
+
+// rateCache reads the suggested exchange rate from the configured price
+// providers, caching it for a TTL so that clients polling the rate don't query
+// the providers on every request. A nil *rateCache reads the Chainlink price
+// feeds on every request.
+type rateCache struct {
+	ttl       time.Duration
+	providers []pricefeed.Provider
+	maxAge    time.Duration
+	fetch     func(context.Context, []pricefeed.Provider, time.Duration) (*SuggestedExchangeRateResponse, error)
+
+	mu        sync.Mutex
+	rate      *SuggestedExchangeRateResponse
+	fetchedAt time.Time
+}
+
+// newRateCache returns a cache that keeps the suggested exchange rate for the
+// TTL, or doesn't keep it if the TTL is not positive. The rate comes from the
+// first of the providers, in order, with prices updated within maxAge. If
+// there are no providers, the Chainlink price feeds are read with the client
+// passed to get.
+func newRateCache(ttl time.Duration, providers []pricefeed.Provider, maxAge time.Duration) *rateCache {
+	return &rateCache{
+		ttl:       ttl,
+		providers: providers,
+		maxAge:    maxAge,
+		fetch:     suggestedExchangeRate,
+	}
+}
+
+// get returns the suggested exchange rate. The price providers are queried if
+// no rate is cached, the cached rate expired, or forceRefresh is set. A cached
+// rate keeps the update times of the prices at the time it was read.
+func (c *rateCache) get(
+	ctx context.Context,
+	ec *ethclient.Client,
+	forceRefresh bool,
+) (*SuggestedExchangeRateResponse, error) {
+	chainlink := []pricefeed.Provider{pricefeed.NewChainlinkProvider(ec)}
+	if c == nil {
+		return suggestedExchangeRate(ctx, chainlink, 0)
+	}
+
+	providers := c.providers
+	if len(providers) == 0 {
+		providers = chainlink
+	}
+
+	if c.ttl <= 0 {
+		return c.fetch(ctx, providers, c.maxAge)
+	}
+
+	// the lock is held while the providers are queried, so that concurrent
+	// requests for an expired rate only query them once
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !forceRefresh && c.rate != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.rate, nil
+	}
+
+	rate, err := c.fetch(ctx, providers, c.maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rate = rate
+	c.fetchedAt = time.Now()
+	return rate, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+)
+
+// newTestRateCache returns a rate cache whose fetches are counted, instead of
+// querying the price providers.
+func newTestRateCache(ttl time.Duration, updatedAt time.Time) (*rateCache, *int) {
+	numFetches := new(int)
+	c := newRateCache(ttl, nil, 0)
+	c.fetch = func(_ context.Context, _ []pricefeed.Provider, _ time.Duration) (*SuggestedExchangeRateResponse, error) {
+		*numFetches++
+		return &SuggestedExchangeRateResponse{
+			XMRUpdatedAt: updatedAt,
+			ExchangeRate: coins.ToExchangeRate(coins.StrToDecimal("0.075")),
+			Provider:     pricefeed.ChainlinkProviderName,
+		}, nil
+	}
+	return c, numFetches
+}
+
+func TestRateCache(t *testing.T) {
+	ctx := context.Background()
+	updatedAt := time.Now().Add(-time.Hour)
+	c, numFetches := newTestRateCache(time.Hour, updatedAt)
+
+	rate, err := c.get(ctx, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, *numFetches)
+	require.Equal(t, updatedAt, rate.XMRUpdatedAt)
+
+	// the cached rate keeps the update time of the price feed
+	rate, err = c.get(ctx, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, *numFetches)
+	require.Equal(t, updatedAt, rate.XMRUpdatedAt)
+
+	_, err = c.get(ctx, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, *numFetches)
+
+	// the rate is read again once it expires
+	c.fetchedAt = time.Now().Add(-2 * time.Hour)
+	_, err = c.get(ctx, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, *numFetches)
+}
+
+func TestRateCache_disabled(t *testing.T) {
+	c, numFetches := newTestRateCache(0, time.Now())
+
+	for i := 1; i <= 2; i++ {
+		_, err := c.get(context.Background(), nil, false)
+		require.NoError(t, err)
+		require.Equal(t, i, *numFetches)
+	}
+}
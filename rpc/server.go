@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
 	"time"
@@ -27,9 +28,14 @@ import (
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
 	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
+	"github.com/athanorlabs/atomic-swap/monero"
+	"github.com/athanorlabs/atomic-swap/pricefeed"
+	"github.com/athanorlabs/atomic-swap/protocol/backend"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/protocol/txsender"
+	"github.com/athanorlabs/atomic-swap/relayer"
 )
 
 const (
@@ -51,15 +57,42 @@ type Server struct {
 
 // Config ...
 type Config struct {
-	Ctx             context.Context
-	Address         string // "IP:port"
-	Net             Net
-	XMRTaker        XMRTaker
-	XMRMaker        XMRMaker
-	ProtocolBackend ProtocolBackend
-	RecoveryDB      RecoveryDB
-	Namespaces      map[string]struct{}
-	IsBootnodeOnly  bool
+	Ctx              context.Context
+	Address          string // "IP:port"
+	Net              Net
+	XMRTaker         XMRTaker
+	XMRMaker         XMRMaker
+	ProtocolBackend  ProtocolBackend
+	RecoveryDB       RecoveryDB
+	TokenDB          TokenDB      // optional, token metadata is not cached if unset
+	RateSampleDB     RateSampleDB // optional, swap_rateHistory fails if unset
+	Namespaces       map[string]struct{}
+	IsBootnodeOnly   bool
+	EventSocketPath  string // optional Unix socket path to emit swap events on
+	WsCompression    bool   // compress websocket messages for clients that negotiate it
+	MaxWsConnections uint32 // max concurrent websocket connections, 0 for no limit
+	EnableMetrics    bool   // serve Prometheus metrics on /metrics
+	AuthToken        string // optional token that all requests must present in their Authorization header
+
+	// AllowedOrigins are the origins of the web pages that can make requests,
+	// including websocket connections. If empty, all origins are allowed.
+	AllowedOrigins []string
+
+	// RateCacheTTL is how long the suggested exchange rate is cached. If zero,
+	// the price feeds are read on every request.
+	RateCacheTTL time.Duration
+
+	// PriceProviders are the sources of the suggested exchange rate, in the
+	// order they are tried. A provider whose prices were updated longer than
+	// MaxPriceAge ago is skipped, unless MaxPriceAge is zero. If there are no
+	// providers, the Chainlink price feeds are read.
+	PriceProviders []pricefeed.Provider
+	MaxPriceAge    time.Duration
+
+	// IsObserver is set when swapd runs without a wallet. Only the net
+	// namespace and the suggested exchange rate are served, using ETHClient.
+	IsObserver bool
+	ETHClient  extethclient.EthClient
 }
 
 // AllNamespaces returns a map with all RPC namespaces set for usage in the config.
@@ -76,10 +109,16 @@ func AllNamespaces() map[string]struct{} {
 // NewServer ...
 func NewServer(cfg *Config) (*Server, error) {
 	rpcServer := rpc.NewServer()
-	rpcServer.RegisterCodec(NewCodec(), "application/json")
+	codec := NewCodec()
+	codec.isObserver = cfg.IsObserver
+	rpcServer.RegisterCodec(codec, "application/json")
 
 	serverCtx, serverCancel := context.WithCancel(cfg.Ctx)
-	err := rpcServer.RegisterService(NewDaemonService(serverCancel, cfg.ProtocolBackend), "daemon")
+	wsConns := &wsConnCounter{max: cfg.MaxWsConnections}
+	daemonService := NewDaemonService(serverCancel, cfg.ProtocolBackend)
+	daemonService.wsConns = wsConns
+	daemonService.net = cfg.Net
+	err := rpcServer.RegisterService(daemonService, "daemon")
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +128,15 @@ func NewServer(cfg *Config) (*Server, error) {
 		swapManager = cfg.ProtocolBackend.SwapManager()
 	}
 
+	var tokens *tokenCache
+	if cfg.TokenDB != nil && cfg.ProtocolBackend != nil {
+		tokens = newTokenCache(serverCtx, cfg.TokenDB, cfg.ProtocolBackend)
+	}
+
+	rates := newRateCache(cfg.RateCacheTTL, cfg.PriceProviders, cfg.MaxPriceAge)
+
 	var netService *NetService
+	var personalService *PersonalService
 	for ns := range cfg.Namespaces {
 		switch ns {
 		case DaemonNamespace:
@@ -98,22 +145,33 @@ func NewServer(cfg *Config) (*Server, error) {
 			err = rpcServer.RegisterService(NewDatabaseService(cfg.RecoveryDB), DatabaseNamespace)
 		case NetNamespace:
 			netService = NewNetService(cfg.Net, cfg.XMRTaker, cfg.XMRMaker, swapManager, cfg.IsBootnodeOnly)
+			netService.tokens = tokens
+			netService.isObserver = cfg.IsObserver
 			err = rpcServer.RegisterService(netService, NetNamespace)
 		case PersonalName:
-			err = rpcServer.RegisterService(NewPersonalService(serverCtx, cfg.XMRMaker, cfg.ProtocolBackend), PersonalName)
+			personalService = NewPersonalService(serverCtx, cfg.XMRMaker, cfg.ProtocolBackend)
+			personalService.tokens = tokens
+			err = rpcServer.RegisterService(personalService, PersonalName)
 		case SwapNamespace:
-			err = rpcServer.RegisterService(
-				NewSwapService(
-					serverCtx,
-					swapManager,
-					cfg.XMRTaker,
-					cfg.XMRMaker,
-					cfg.Net,
-					cfg.ProtocolBackend,
-					cfg.RecoveryDB,
-				),
-				SwapNamespace,
+			if cfg.IsObserver {
+				observerService := NewObserverSwapService(serverCtx, cfg.ETHClient)
+				observerService.rates = rates
+				err = rpcServer.RegisterService(observerService, SwapNamespace)
+				continue
+			}
+			swapService := NewSwapService(
+				serverCtx,
+				swapManager,
+				cfg.XMRTaker,
+				cfg.XMRMaker,
+				cfg.Net,
+				cfg.ProtocolBackend,
+				cfg.RecoveryDB,
 			)
+			swapService.tokens = tokens
+			swapService.rateSamples = cfg.RateSampleDB
+			swapService.rates = rates
+			err = rpcServer.RegisterService(swapService, SwapNamespace)
 		default:
 			err = fmt.Errorf("unknown namespace %s", ns)
 		}
@@ -123,7 +181,16 @@ func NewServer(cfg *Config) (*Server, error) {
 		return nil, err
 	}
 
-	wsServer := newWsServer(serverCtx, swapManager, netService, cfg.ProtocolBackend, cfg.XMRTaker)
+	wsServer := newWsServer(serverCtx, swapManager, netService, personalService, cfg.ProtocolBackend,
+		cfg.XMRTaker, cfg.WsCompression, wsConns)
+	wsServer.isObserver = cfg.IsObserver
+
+	if cfg.EventSocketPath != "" && swapManager != nil {
+		if _, err = newEventSocket(serverCtx, cfg.EventSocketPath, swapManager); err != nil {
+			serverCancel()
+			return nil, fmt.Errorf("failed to create event socket: %w", err)
+		}
+	}
 
 	lc := net.ListenConfig{}
 	ln, err := lc.Listen(serverCtx, "tcp", cfg.Address)
@@ -136,13 +203,35 @@ func NewServer(cfg *Config) (*Server, error) {
 	r.Handle("/", rpcServer)
 	r.Handle("/ws", wsServer)
 
-	headersOk := handlers.AllowedHeaders([]string{"content-type", "username", "password"})
+	if cfg.EnableMetrics {
+		metricsHandler, err := newMetricsHandler(cfg) //nolint:govet
+		if err != nil {
+			serverCancel()
+			return nil, fmt.Errorf("failed to create metrics handler: %w", err)
+		}
+		r.Handle("/metrics", metricsHandler)
+	}
+
+	// CORS preflight requests are answered without credentials, so the token
+	// is only checked on the requests that pass through the CORS handler
+	var handler http.Handler = r
+	if cfg.AuthToken != "" {
+		handler = requireAuthToken(cfg.AuthToken, handler)
+	}
+
+	allowedOrigins := []string{AllOrigins}
+	if len(cfg.AllowedOrigins) > 0 {
+		allowedOrigins = cfg.AllowedOrigins
+		handler = restrictOrigins(allowedOrigins, handler)
+	}
+
+	headersOk := handlers.AllowedHeaders([]string{"content-type", "username", "password", "authorization"})
 	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
-	originsOk := handlers.AllowedOrigins([]string{"*"})
+	originsOk := handlers.AllowedOrigins(allowedOrigins)
 	server := &http.Server{
 		Addr:              ln.Addr().String(),
 		ReadHeaderTimeout: time.Second,
-		Handler:           handlers.CORS(headersOk, methodsOk, originsOk)(r),
+		Handler:           handlers.CORS(headersOk, methodsOk, originsOk)(handler),
 		BaseContext: func(listener net.Listener) context.Context {
 			return serverCtx
 		},
@@ -212,35 +301,65 @@ func (s *Server) Stop() error {
 type Protocol interface {
 	Provides() coins.ProvidesCoin
 	GetOngoingSwapState(types.Hash) common.SwapState
+	RetrySweep(offerID types.Hash) error
+	SetGasPriceOverride(offerID types.Hash, gasPrice *big.Int) error
 }
 
 // ProtocolBackend represents protocol/backend.Backend
 type ProtocolBackend interface {
 	Ctx() context.Context
 	Env() common.Environment
-	SetSwapTimeout(timeout time.Duration)
-	SwapTimeout() time.Duration
+	SetSwapTimeout(t0Duration time.Duration, t1Duration time.Duration)
+	SwapTimeout() (t0Duration time.Duration, t1Duration time.Duration)
 	SwapManager() swap.Manager
 	SwapCreatorAddr() ethcommon.Address
 	SetXMRDepositAddress(*mcrypto.Address, types.Hash)
 	ClearXMRDepositAddress(types.Hash)
 	ETHClient() extethclient.EthClient
+	XMRClient() monero.WalletClient
+	GasBalanceStatus() *backend.GasBalanceStatus
+	RelayLimiterStatus() *relayer.LimiterStatus
 }
 
 // XMRTaker ...
 type XMRTaker interface {
 	Protocol
-	InitiateProtocol(peerID peer.ID, providesAmount *apd.Decimal, offer *types.Offer) (common.SwapState, error)
+	InitiateProtocol(
+		peerID peer.ID,
+		providesAmount *apd.Decimal,
+		offer *types.Offer,
+		maxExchangeRate *coins.ExchangeRate,
+	) (common.SwapState, error)
 	ExternalSender(offerID types.Hash) (*txsender.ExternalSender, error)
 }
 
 // XMRMaker ...
 type XMRMaker interface {
 	Protocol
-	MakeOffer(offer *types.Offer, useRelayer bool) (*types.OfferExtra, error)
+	MakeOffer(
+		offer *types.Offer,
+		useRelayer bool,
+		expiresAt *time.Time,
+		minTakerRep *types.MinTakerReputation,
+		persistent bool,
+		repricing *types.Repricing,
+	) (*types.OfferExtra, error)
+	ValidateOffer(
+		offer *types.Offer,
+		useRelayer bool,
+		expiresAt *time.Time,
+		minTakerRep *types.MinTakerReputation,
+		repricing *types.Repricing,
+	) (*types.OfferSummary, error)
 	GetOffers() []*types.Offer
+	OfferExpiry(id types.Hash) *time.Time
+	OfferExtra(id types.Hash) *types.OfferExtra
 	ClearOffers([]types.Hash) error
+	ForceCompleteSwap(offerID types.Hash) error
 	GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error)
+	GetRejectedTakes() ([]*db.RejectedTake, error)
+	GetOfferDefaults(asset types.EthAsset) (*db.OfferDefaults, error)
+	AddOfferTakenListener(listener func(*types.OfferTakenEvent)) (remove func())
 }
 
 // SwapManager ...
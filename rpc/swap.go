@@ -5,19 +5,27 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"sort"
 	"time"
 
+	"github.com/ChainSafe/chaindb"
 	"github.com/cockroachdb/apd/v3"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/db"
 	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/ethereum/extethclient"
 	"github.com/athanorlabs/atomic-swap/pricefeed"
+	pcommon "github.com/athanorlabs/atomic-swap/protocol"
 	"github.com/athanorlabs/atomic-swap/protocol/swap"
 )
 
@@ -30,6 +38,14 @@ type SwapService struct {
 	net      Net
 	backend  ProtocolBackend
 	rdb      RecoveryDB
+	tokens   *tokenCache // nil if token metadata is not cached
+
+	// rateSamples is nil if the exchange rate history is not available
+	rateSamples RateSampleDB
+
+	rates *rateCache // nil to read the Chainlink price feeds on every request
+
+	completionTimes *completionTimes
 }
 
 // NewSwapService ...
@@ -50,12 +66,15 @@ func NewSwapService(
 		net:      net,
 		backend:  b,
 		rdb:      rdb,
+
+		completionTimes: newCompletionTimes(sm),
 	}
 }
 
 // PastSwap represents a past swap returned by swap_getPast.
 type PastSwap struct {
 	ID             types.Hash          `json:"id" validate:"required"`
+	PeerID         peer.ID             `json:"peerID" validate:"required"`
 	Provided       coins.ProvidesCoin  `json:"provided" validate:"required"`
 	EthAsset       types.EthAsset      `json:"ethAsset"`
 	ProvidedAmount *apd.Decimal        `json:"providedAmount" validate:"required"`
@@ -64,11 +83,53 @@ type PastSwap struct {
 	Status         types.Status        `json:"status" validate:"required"`
 	StartTime      time.Time           `json:"startTime" validate:"required"`
 	EndTime        *time.Time          `json:"endTime"`
+	// MarketExchangeRate and ProfitPercent are only set if the market exchange
+	// rate was available at the start of the swap.
+	MarketExchangeRate *coins.ExchangeRate `json:"marketExchangeRate,omitempty"`
+	ProfitPercent      *apd.Decimal        `json:"profitPercent,omitempty"`
+	// The transaction hashes are only set for the swap's transactions that
+	// were seen by this node.
+	NewSwapTxHash  *ethcommon.Hash `json:"newSwapTxHash,omitempty"`
+	SetReadyTxHash *ethcommon.Hash `json:"setReadyTxHash,omitempty"`
+	ClaimTxHash    *ethcommon.Hash `json:"claimTxHash,omitempty"`
+	RefundTxHash   *ethcommon.Hash `json:"refundTxHash,omitempty"`
+	// RelayerFee is only set if a relayer claimed the swap's ETH for us.
+	RelayerFee *apd.Decimal `json:"relayerFee,omitempty"`
 }
 
 // GetPastRequest ...
 type GetPastRequest struct {
 	OfferID *types.Hash `json:"offerID,omitempty"`
+	// The remaining fields filter the returned swaps when no OfferID is set.
+	// Since and Until are compared against the swap's start time.
+	Status *types.Status `json:"status,omitempty"`
+	Since  *time.Time    `json:"since,omitempty"`
+	Until  *time.Time    `json:"until,omitempty"`
+	// Offset and Limit page through the matching swaps, which are ordered from
+	// newest to oldest. A zero Limit returns all matching swaps.
+	Offset uint `json:"offset,omitempty"`
+	Limit  uint `json:"limit,omitempty"`
+}
+
+// filter returns the predicate selecting the past swaps that match the request,
+// or nil if the request has no filter parameters.
+func (req *GetPastRequest) filter() swap.PastSwapFilter {
+	if req.Status == nil && req.Since == nil && req.Until == nil {
+		return nil
+	}
+
+	return func(info *swap.Info) bool {
+		if req.Status != nil && info.Status != *req.Status {
+			return false
+		}
+		if req.Since != nil && info.StartTime.Before(*req.Since) {
+			return false
+		}
+		if req.Until != nil && info.StartTime.After(*req.Until) {
+			return false
+		}
+		return true
+	}
 }
 
 // GetPastResponse ...
@@ -77,25 +138,21 @@ type GetPastResponse struct {
 }
 
 // GetPast returns information about a past swap given its ID.
-// If no ID is provided, all past swaps are returned.
-// It sorts them in order from newest to oldest.
+// If no ID is provided, the requested page of past swaps matching the optional
+// status and start time filters is returned, ordered from newest to oldest.
 func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPastResponse) error {
 	var swaps []*swap.Info
 
+	if req.Since != nil && req.Until != nil && req.Until.Before(*req.Since) {
+		return errSinceAfterUntil
+	}
+
 	if req.OfferID == nil {
-		ids, err := s.sm.GetPastIDs()
+		var err error
+		swaps, err = s.sm.GetPastSwaps(req.filter(), req.Offset, req.Limit)
 		if err != nil {
 			return err
 		}
-
-		for _, id := range ids {
-			info, err := s.sm.GetPastSwap(id)
-			if err != nil {
-				return fmt.Errorf("failed to get past swap %s: %w", id, err)
-			}
-
-			swaps = append(swaps, info)
-		}
 	} else {
 		info, err := s.sm.GetPastSwap(*req.OfferID)
 		if err != nil {
@@ -107,23 +164,73 @@ func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPas
 
 	resp.Swaps = make([]*PastSwap, len(swaps))
 	for i, info := range swaps {
+		profitPercent, err := info.ProfitPercent()
+		if err != nil {
+			return fmt.Errorf("failed to calculate profit of swap %s: %w", info.OfferID, err)
+		}
+
 		resp.Swaps[i] = &PastSwap{
-			ID:             info.OfferID,
-			Provided:       info.Provides,
-			EthAsset:       info.EthAsset,
-			ProvidedAmount: info.ProvidedAmount,
-			ExpectedAmount: info.ExpectedAmount,
-			ExchangeRate:   info.ExchangeRate,
-			Status:         info.Status,
-			StartTime:      info.StartTime,
-			EndTime:        info.EndTime,
+			ID:                 info.OfferID,
+			PeerID:             info.PeerID,
+			Provided:           info.Provides,
+			EthAsset:           info.EthAsset,
+			ProvidedAmount:     info.ProvidedAmount,
+			ExpectedAmount:     info.ExpectedAmount,
+			ExchangeRate:       info.ExchangeRate,
+			Status:             info.Status,
+			StartTime:          info.StartTime,
+			EndTime:            info.EndTime,
+			MarketExchangeRate: info.MarketExchangeRate,
+			ProfitPercent:      profitPercent,
+			NewSwapTxHash:      info.NewSwapTxHash,
+			SetReadyTxHash:     info.SetReadyTxHash,
+			ClaimTxHash:        info.ClaimTxHash,
+			RefundTxHash:       info.RefundTxHash,
+			RelayerFee:         info.RelayerFee,
 		}
 	}
 
-	sort.Slice(resp.Swaps, func(i, j int) bool {
-		return resp.Swaps[j].StartTime.Before(resp.Swaps[i].StartTime)
-	})
+	return nil
+}
+
+// ExportRequest ...
+type ExportRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// ExportResponse is the full stored record of a past swap.
+type ExportResponse struct {
+	Swap *swap.Info `json:"swap" validate:"required"`
+	// ContractSwapInfo is only set if the swap's contract info is still in the
+	// recovery database.
+	ContractSwapInfo *db.EthereumSwapInfo `json:"contractSwapInfo,omitempty"`
+}
+
+// Export returns the full stored record of a past swap, for archiving. It
+// returns an error if the swap is still ongoing.
+func (s *SwapService) Export(_ *http.Request, req *ExportRequest, resp *ExportResponse) error {
+	if s.sm.HasOngoingSwap(req.OfferID) {
+		return errSwapOngoing
+	}
+
+	info, err := s.sm.GetPastSwap(req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	resp.Swap = info
+
+	if s.rdb == nil {
+		return nil
+	}
+
+	// the recovery info is usually deleted when a swap completes
+	contractSwapInfo, err := s.rdb.GetContractSwapInfo(req.OfferID)
+	if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+		return fmt.Errorf("failed to get contract swap info: %w", err)
+	}
 
+	resp.ContractSwapInfo = contractSwapInfo
 	return nil
 }
 
@@ -214,9 +321,14 @@ type GetStatusRequest struct {
 
 // GetStatusResponse ...
 type GetStatusResponse struct {
-	Status      types.Status `json:"status" validate:"required"`
-	Description string       `json:"info" validate:"required"`
-	StartTime   time.Time    `json:"startTime" validate:"required"`
+	Status                    types.Status  `json:"status" validate:"required"`
+	Description               string        `json:"info" validate:"required"`
+	StartTime                 time.Time     `json:"startTime" validate:"required"`
+	Timeout0                  *time.Time    `json:"timeout0,omitempty"`
+	Timeout1                  *time.Time    `json:"timeout1,omitempty"`
+	EstimatedTimeToCompletion time.Duration `json:"estimatedTimeToCompletion"`
+	ClaimMethod               string        `json:"claimMethod,omitempty"`
+	RelayerFee                *apd.Decimal  `json:"relayerFee,omitempty"`
 }
 
 // GetStatus returns the status of the ongoing swap, if there is one.
@@ -229,6 +341,26 @@ func (s *SwapService) GetStatus(_ *http.Request, req *GetStatusRequest, resp *Ge
 	resp.Status = info.Status
 	resp.Description = info.Status.Description()
 	resp.StartTime = info.StartTime
+	resp.Timeout0 = info.Timeout0
+	resp.Timeout1 = info.Timeout1
+	resp.ClaimMethod = info.ClaimMethod
+	resp.RelayerFee = info.RelayerFee
+
+	// the swap may have completed since we fetched it, in which case there is
+	// nothing left to estimate
+	if !info.Status.IsOngoing() {
+		return nil
+	}
+
+	resp.EstimatedTimeToCompletion, err = estimatedTimeToCompletion(
+		s.backend.Env(),
+		info.Status,
+		info.LastStatusUpdateTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to estimate time to completion for swap %s: %w", info.OfferID, err)
+	}
+
 	return nil
 }
 
@@ -236,12 +368,55 @@ func (s *SwapService) GetStatus(_ *http.Request, req *GetStatusRequest, resp *Ge
 type GetOffersResponse struct {
 	PeerID peer.ID        `json:"peerID" validate:"required"`
 	Offers []*types.Offer `json:"offers" validate:"dive,required"`
+	// Expiries maps the IDs of offers that expire to their expiry time
+	Expiries map[types.Hash]time.Time `json:"expiries,omitempty"`
 }
 
 // GetOffers returns our currently available offers.
 func (s *SwapService) GetOffers(_ *http.Request, _ *interface{}, resp *GetOffersResponse) error {
 	resp.PeerID = s.net.PeerID()
 	resp.Offers = s.xmrmaker.GetOffers()
+
+	for _, o := range resp.Offers {
+		expiresAt := s.xmrmaker.OfferExpiry(o.ID)
+		if expiresAt == nil {
+			continue
+		}
+		if resp.Expiries == nil {
+			resp.Expiries = make(map[types.Hash]time.Time)
+		}
+		resp.Expiries[o.ID] = *expiresAt
+	}
+
+	return nil
+}
+
+// ExportedOffer is one of our offers together with the settings it was made
+// with. It holds only the offer's parameters, so it can be used to re-make the
+// offer later.
+type ExportedOffer struct {
+	Offer *types.Offer      `json:"offer" validate:"required"`
+	Extra *types.OfferExtra `json:"extra" validate:"required"`
+}
+
+// ExportOffersResponse ...
+type ExportOffersResponse struct {
+	Offers []*ExportedOffer `json:"offers" validate:"dive,required"`
+}
+
+// ExportOffers returns our current offers along with their settings, such as
+// whether they use the relayer and when they expire.
+func (s *SwapService) ExportOffers(_ *http.Request, _ *interface{}, resp *ExportOffersResponse) error {
+	resp.Offers = []*ExportedOffer{}
+	for _, o := range s.xmrmaker.GetOffers() {
+		extra := s.xmrmaker.OfferExtra(o.ID)
+		if extra == nil {
+			// the offer was taken or cleared since it was listed
+			continue
+		}
+		resp.Offers = append(resp.Offers, &ExportedOffer{Offer: o, Extra: extra})
+	}
+
 	return nil
 }
 
@@ -260,52 +435,520 @@ func (s *SwapService) ClearOffers(_ *http.Request, req *ClearOffersRequest, _ *i
 	return nil
 }
 
+// GetRejectedTakesResponse ...
+type GetRejectedTakesResponse struct {
+	RejectedTakes []*db.RejectedTake `json:"rejectedTakes" validate:"dive,required"`
+}
+
+// GetRejectedTakes returns the take requests for our offers that were rejected
+// before a swap was created, ordered from newest to oldest.
+func (s *SwapService) GetRejectedTakes(_ *http.Request, _ *interface{}, resp *GetRejectedTakesResponse) error {
+	rejectedTakes, err := s.xmrmaker.GetRejectedTakes()
+	if err != nil {
+		return err
+	}
+
+	resp.RejectedTakes = rejectedTakes
+	return nil
+}
+
 // CancelRequest ...
 type CancelRequest struct {
 	OfferID types.Hash `json:"offerID" validate:"required"`
 }
 
+// CancelReason is a code describing the outcome of a swap_cancel request.
+type CancelReason string
+
+// CancelReason values
+const (
+	// CancelReasonAborted means the swap was exited before any funds were locked.
+	CancelReasonAborted CancelReason = "abortedBeforeLock"
+	// CancelReasonRefunded means the swap was exited and our locked funds were
+	// refunded.
+	CancelReasonRefunded CancelReason = "refunded"
+	// CancelReasonCompleted means the swap completed successfully before it
+	// could be exited.
+	CancelReasonCompleted CancelReason = "completed"
+	// CancelReasonPastPointOfNoReturn means the swap could not be exited, as it
+	// is past the point where exiting would forfeit our funds. The swap
+	// continues until we can claim or refund.
+	CancelReasonPastPointOfNoReturn CancelReason = "pastPointOfNoReturn"
+	// CancelReasonNoSuchSwap means there is no ongoing swap with the given ID.
+	CancelReasonNoSuchSwap CancelReason = "noSuchSwap"
+	// CancelReasonFailed means exiting the swap failed. It is only used by
+	// swap_cancelAll, swap_cancel returns the error instead.
+	CancelReasonFailed CancelReason = "failed"
+)
+
 // CancelResponse ...
 type CancelResponse struct {
-	Status types.Status `json:"status" validate:"required"`
+	// Status is the exit status of the swap, or its current status if it could
+	// not be exited. It is not set if there is no such swap.
+	Status  types.Status `json:"status,omitempty"`
+	Reason  CancelReason `json:"reason" validate:"required"`
+	Message string       `json:"message" validate:"required"`
 }
 
 // Cancel attempts to cancel the currently ongoing swap, if there is one.
 func (s *SwapService) Cancel(_ *http.Request, req *CancelRequest, resp *CancelResponse) error {
+	if !s.sm.HasOngoingSwap(req.OfferID) {
+		resp.Reason = CancelReasonNoSuchSwap
+		resp.Message = fmt.Sprintf("no ongoing swap with ID %s", req.OfferID)
+		return nil
+	}
+
 	info, err := s.sm.GetOngoingSwap(req.OfferID)
 	if err != nil {
 		return fmt.Errorf("failed to get ongoing swap: %w", err)
 	}
 
+	result, err := s.cancelOngoingSwap(&info)
+	if err != nil {
+		return err
+	}
+
+	*resp = *result
+	return nil
+}
+
+// CancelAllResponse ...
+type CancelAllResponse struct {
+	// Results maps the ID of each swap that was ongoing to the outcome of
+	// cancelling it.
+	Results map[types.Hash]*CancelResponse `json:"results" validate:"dive,required"`
+}
+
+// CancelAll attempts to cancel every ongoing swap, one after the other. Swaps
+// that can't be cancelled, because they are past the point of no return or
+// because exiting them failed, are reported in the results instead of failing
+// the whole request.
+func (s *SwapService) CancelAll(_ *http.Request, _ *interface{}, resp *CancelAllResponse) error {
+	swaps, err := s.sm.GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+
+	resp.Results = make(map[types.Hash]*CancelResponse, len(swaps))
+	for _, info := range swaps {
+		result, err := s.cancelOngoingSwap(info) //nolint:govet
+		if err != nil {
+			result = &CancelResponse{
+				Status:  info.Status,
+				Reason:  CancelReasonFailed,
+				Message: err.Error(),
+			}
+		}
+
+		resp.Results[info.OfferID] = result
+	}
+
+	return nil
+}
+
+// cancelOngoingSwap exits the given ongoing swap and describes the outcome.
+// It returns an error if the swap could not be exited for any reason other
+// than it being past the point of no return.
+func (s *SwapService) cancelOngoingSwap(info *swap.Info) (*CancelResponse, error) {
+	status, err := s.cancelSwap(info)
+	if err != nil {
+		if errors.Is(err, errNoSwapState) || info.Status.IsCancellable() {
+			return nil, err
+		}
+
+		// the swap state refused to exit, or failed to refund, as our funds
+		// are locked and can't be safely reclaimed yet
+		return &CancelResponse{
+			Status:  info.Status,
+			Reason:  CancelReasonPastPointOfNoReturn,
+			Message: fmt.Sprintf("cannot cancel swap at status %s: %s", info.Status, err),
+		}, nil
+	}
+
+	resp := &CancelResponse{Status: status}
+	switch status {
+	case types.CompletedAbort:
+		resp.Reason = CancelReasonAborted
+		resp.Message = "swap aborted before any funds were locked"
+	case types.CompletedRefund:
+		resp.Reason = CancelReasonRefunded
+		resp.Message = "swap exited and locked funds were refunded"
+	case types.CompletedSuccess:
+		resp.Reason = CancelReasonCompleted
+		resp.Message = "swap completed successfully before it could be cancelled"
+	default:
+		return nil, fmt.Errorf("unexpected exit status %s", status)
+	}
+
+	return resp, nil
+}
+
+// cancelSwap exits the given ongoing swap and returns its resulting status.
+func (s *SwapService) cancelSwap(info *swap.Info) (types.Status, error) {
 	var ss common.SwapState
 	switch info.Provides {
 	case coins.ProvidesETH:
-		ss = s.xmrtaker.GetOngoingSwapState(req.OfferID)
+		ss = s.xmrtaker.GetOngoingSwapState(info.OfferID)
 	case coins.ProvidesXMR:
-		ss = s.xmrmaker.GetOngoingSwapState(req.OfferID)
+		ss = s.xmrmaker.GetOngoingSwapState(info.OfferID)
 	}
 
 	if ss == nil {
-		return fmt.Errorf("failed to find swap state with ID %s", req.OfferID)
+		return 0, fmt.Errorf("%w: %s", errNoSwapState, info.OfferID)
 	}
 
 	// Exit() is safe to be called concurrently, as it puts an exit event
 	// into the swap state's eventCh, and events are handled sequentially.
-	if err = ss.Exit(); err != nil {
-		return err
+	if err := ss.Exit(); err != nil {
+		return 0, err
 	}
 
-	s.net.CloseProtocolStream(req.OfferID)
+	s.net.CloseProtocolStream(info.OfferID)
 
 	past, err := s.sm.GetPastSwap(info.OfferID)
+	if err != nil {
+		return 0, err
+	}
+
+	return past.Status, nil
+}
+
+// CancelByStatusRequest ...
+type CancelByStatusRequest struct {
+	Status types.Status `json:"status" validate:"required"`
+	// OlderThan, if set, only matches swaps whose status was last updated
+	// at least this many seconds ago.
+	OlderThan uint64 `json:"olderThan,omitempty"`
+}
+
+// CancelByStatusResult is the outcome of cancelling a single swap matched by
+// swap_cancelByStatus.
+type CancelByStatusResult struct {
+	OfferID types.Hash   `json:"offerID" validate:"required"`
+	Status  types.Status `json:"status" validate:"required"`
+	// Skipped is true if the swap matched but was not cancelled because it
+	// is past the point where it can be safely exited.
+	Skipped bool   `json:"skipped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CancelByStatusResponse ...
+type CancelByStatusResponse struct {
+	Results []*CancelByStatusResult `json:"results" validate:"dive,required"`
+}
+
+// CancelByStatus attempts to cancel all ongoing swaps with the given status
+// that have not changed status in at least req.OlderThan seconds. Swaps that cannot be safely
+// cancelled are reported as skipped.
+func (s *SwapService) CancelByStatus(
+	_ *http.Request,
+	req *CancelByStatusRequest,
+	resp *CancelByStatusResponse,
+) error {
+	if !req.Status.IsOngoing() {
+		return fmt.Errorf("status %s is not an ongoing swap status", req.Status)
+	}
+
+	swaps, err := s.sm.GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+
+	minAge := time.Second * time.Duration(req.OlderThan)
+	resp.Results = []*CancelByStatusResult{}
+	for _, info := range swaps {
+		if info.Status != req.Status || time.Since(info.LastStatusUpdateTime) < minAge {
+			continue
+		}
+
+		result := &CancelByStatusResult{
+			OfferID: info.OfferID,
+			Status:  info.Status,
+		}
+		resp.Results = append(resp.Results, result)
+
+		if !info.Status.IsCancellable() {
+			result.Skipped = true
+			continue
+		}
+
+		status, err := s.cancelSwap(info)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		result.Status = status
+	}
+
+	return nil
+}
+
+// ForceCompleteRequest ...
+type ForceCompleteRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// ForceComplete moves an ongoing swap that is already finished on-chain, but
+// that swapd failed to finalize, to the past swaps. It returns an error if
+// the swap is not finished on-chain. Only swaps where we provide XMR are
+// supported.
+func (s *SwapService) ForceComplete(_ *http.Request, req *ForceCompleteRequest, _ *interface{}) error {
+	info, err := s.sm.GetOngoingSwap(req.OfferID)
+	if err != nil {
+		return fmt.Errorf("failed to get ongoing swap: %w", err)
+	}
+
+	if info.Provides != coins.ProvidesXMR {
+		return errForceCompleteNotMaker
+	}
+
+	return s.xmrmaker.ForceCompleteSwap(req.OfferID)
+}
+
+// TokenListResponse ...
+type TokenListResponse struct {
+	Tokens []*coins.ERC20TokenInfo `json:"tokens" validate:"dive,required"`
+}
+
+// TokenList returns the cached metadata of all ERC20 tokens that swapd has seen
+// in offers, balance queries and token lookups.
+func (s *SwapService) TokenList(_ *http.Request, _ *interface{}, resp *TokenListResponse) error {
+	if s.tokens == nil {
+		return errTokenCacheDisabled
+	}
+
+	tokens, err := s.tokens.list()
+	if err != nil {
+		return err
+	}
+
+	resp.Tokens = tokens
+	return nil
+}
+
+// CompletionTimesResponse ...
+type CompletionTimesResponse struct {
+	Assets map[types.EthAsset]*AssetCompletionTimes `json:"assets" validate:"dive,required"`
+}
+
+// CompletionTimes returns the average and percentile times that our past
+// swaps took to complete, by asset and by final status.
+func (s *SwapService) CompletionTimes(_ *http.Request, _ *interface{}, resp *CompletionTimesResponse) error {
+	assets, err := s.completionTimes.stats()
+	if err != nil {
+		return err
+	}
+
+	resp.Assets = assets
+	return nil
+}
+
+// RetrySweepRequest ...
+type RetrySweepRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// RetrySweep retries sweeping the XMR of an ongoing swap, whose XMR was
+// claimed but not swept out of the swap wallet, to the primary wallet. The swap
+// is completed once its XMR was swept.
+func (s *SwapService) RetrySweep(_ *http.Request, req *RetrySweepRequest, _ *interface{}) error {
+	info, err := s.sm.GetOngoingSwap(req.OfferID)
+	if err != nil {
+		return fmt.Errorf("failed to get ongoing swap: %w", err)
+	}
+
+	if info.Provides == coins.ProvidesXMR {
+		return s.xmrmaker.RetrySweep(req.OfferID)
+	}
+
+	return s.xmrtaker.RetrySweep(req.OfferID)
+}
+
+// SweepSharedWalletRequest ...
+type SweepSharedWalletRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// SweepSharedWalletResponse ...
+type SweepSharedWalletResponse struct {
+	Address *mcrypto.Address `json:"address" validate:"required"`
+	Amount  *apd.Decimal     `json:"amount" validate:"required"` // in XMR, after fees
+	Fee     *apd.Decimal     `json:"fee" validate:"required"`    // in XMR
+	TxIDs   []string         `json:"txIDs" validate:"required"`
+}
+
+// SweepSharedWallet recreates the shared swap wallet of a past swap from both
+// parties' swap keys in the recovery database, and sweeps any XMR left in it
+// to our primary address. It returns an error if either party's keys are not
+// in the database.
+func (s *SwapService) SweepSharedWallet(
+	_ *http.Request,
+	req *SweepSharedWalletRequest,
+	resp *SweepSharedWalletResponse,
+) error {
+	if s.rdb == nil {
+		return errNoRecoveryDB
+	}
+
+	if s.sm.HasOngoingSwap(req.OfferID) {
+		return errSweepOngoingSwap
+	}
+
+	info, err := s.sm.GetPastSwap(req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	kpAB, err := s.sharedWalletKeys(req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	depositAddr := s.backend.XMRClient().PrimaryAddress()
+	transfers, err := pcommon.SweepSharedWallet(
+		s.ctx,
+		s.backend.Env(),
+		req.OfferID,
+		s.backend.XMRClient(),
+		kpAB,
+		info.MoneroStartHeight,
+		depositAddr,
+	)
+	if err != nil {
+		return err
+	}
+
+	var amount, fee uint64
+	resp.TxIDs = make([]string, 0, len(transfers))
+	for _, transfer := range transfers {
+		amount += transfer.Amount
+		fee += transfer.Fee
+		resp.TxIDs = append(resp.TxIDs, transfer.TxID)
+	}
+
+	resp.Address = depositAddr
+	resp.Amount = coins.NewPiconeroAmount(amount).AsMonero()
+	resp.Fee = coins.NewPiconeroAmount(fee).AsMonero()
+	log.Infof("swept %s XMR from the shared wallet of swap %s to %s", resp.Amount.Text('f'), req.OfferID, depositAddr)
+	return nil
+}
+
+// sharedWalletKeys returns the private keypair of the shared swap wallet of
+// the swap, from both parties' swap keys in the recovery database.
+func (s *SwapService) sharedWalletKeys(offerID types.Hash) (*mcrypto.PrivateKeyPair, error) {
+	sk, err := s.rdb.GetSwapPrivateKey(offerID)
+	if err != nil {
+		return nil, fmt.Errorf("our swap private key is not in the recovery database: %w", err)
+	}
+
+	vk, err := sk.View()
+	if err != nil {
+		return nil, err
+	}
+
+	counterpartySk, err := s.rdb.GetCounterpartySwapPrivateKey(offerID)
+	if err != nil {
+		return nil, fmt.Errorf("counterparty's swap private key is not in the recovery database: %w", err)
+	}
+
+	_, counterpartyVk, err := s.rdb.GetCounterpartySwapKeys(offerID)
+	if err != nil {
+		return nil, fmt.Errorf("counterparty's swap view key is not in the recovery database: %w", err)
+	}
+
+	return pcommon.GetClaimKeypair(sk, counterpartySk, vk, counterpartyVk), nil
+}
+
+// VerifyRequest ...
+type VerifyRequest struct {
+	OfferID types.Hash `json:"offerID" validate:"required"`
+}
+
+// VerifyResponse ...
+type VerifyResponse struct {
+	Status        types.Status `json:"status" validate:"required"`
+	ContractStage string       `json:"contractStage" validate:"required"`
+	// Mismatches describes each divergence between the stored state of the
+	// swap and its on-chain state. It is empty if they are consistent.
+	Mismatches []string `json:"mismatches"`
+}
+
+// Verify compares the stored state of an ongoing or past swap with the state of
+// its swap in the contract, reporting any divergence without acting on it. The
+// swap's contract info must still be in the recovery database.
+func (s *SwapService) Verify(_ *http.Request, req *VerifyRequest, resp *VerifyResponse) error {
+	if s.rdb == nil {
+		return errNoRecoveryDB
+	}
+
+	var info *swap.Info
+	if s.sm.HasOngoingSwap(req.OfferID) {
+		ongoing, err := s.sm.GetOngoingSwap(req.OfferID)
+		if err != nil {
+			return err
+		}
+		info = &ongoing
+	} else {
+		past, err := s.sm.GetPastSwap(req.OfferID)
+		if err != nil {
+			return err
+		}
+		info = past
+	}
+
+	ethSwapInfo, err := s.rdb.GetContractSwapInfo(req.OfferID)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return errNoContractSwapInfo
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get contract swap info: %w", err)
+	}
+
+	stage, mismatches, err := pcommon.VerifySwap(s.ctx, s.backend.ETHClient(), info, ethSwapInfo)
 	if err != nil {
 		return err
 	}
 
-	resp.Status = past.Status
+	resp.Status = info.Status
+	resp.ContractStage = contracts.StageToString(stage)
+	resp.Mismatches = mismatches
 	return nil
 }
 
+// SetGasPriceOverrideRequest ...
+type SetGasPriceOverrideRequest struct {
+	OfferID  types.Hash `json:"offerID" validate:"required"`
+	GasPrice uint64     `json:"gasPrice" validate:"required"` // in gwei
+}
+
+// SetGasPriceOverride sets the gas price of the next contract transaction of an
+// ongoing swap, bypassing the maximum gas price. This is an emergency tool for
+// getting a time-critical claim or refund mined during a gas price spike. The
+// gas price must be above the currently suggested gas price.
+func (s *SwapService) SetGasPriceOverride(_ *http.Request, req *SetGasPriceOverrideRequest, _ *interface{}) error {
+	info, err := s.sm.GetOngoingSwap(req.OfferID)
+	if err != nil {
+		return fmt.Errorf("failed to get ongoing swap: %w", err)
+	}
+
+	gasPrice := new(big.Int).Mul(new(big.Int).SetUint64(req.GasPrice), big.NewInt(1e9))
+	suggested, err := s.backend.ETHClient().SuggestGasPrice(s.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get suggested gas price: %w", err)
+	}
+
+	if gasPrice.Cmp(suggested) <= 0 {
+		return fmt.Errorf("%w: override %s wei, suggested %s wei", errGasPriceOverrideTooLow, gasPrice, suggested)
+	}
+
+	if info.Provides == coins.ProvidesXMR {
+		return s.xmrmaker.SetGasPriceOverride(req.OfferID, gasPrice)
+	}
+
+	return s.xmrtaker.SetGasPriceOverride(req.OfferID, gasPrice)
+}
+
 // ManualTransactionRequest is used to call swap_claim or swap_refund.
 type ManualTransactionRequest struct {
 	OfferID types.Hash `json:"offerID" validate:"required"`
@@ -398,6 +1041,180 @@ func (s *SwapService) Refund(_ *http.Request, req *ManualTransactionRequest, res
 	return nil
 }
 
+// EstimateTakeRequest ...
+type EstimateTakeRequest struct {
+	PeerID         peer.ID      `json:"peerID" validate:"required"`
+	OfferID        types.Hash   `json:"offerID" validate:"required"`
+	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"` // eth asset amount
+}
+
+// EstimateTakeResponse ...
+type EstimateTakeResponse struct {
+	EthAsset     types.EthAsset      `json:"ethAsset"`
+	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	// MinProvidesAmount and MaxProvidesAmount are the offer's minimum and
+	// maximum amounts converted to the eth asset.
+	MinProvidesAmount *apd.Decimal `json:"minProvidesAmount" validate:"required"`
+	MaxProvidesAmount *apd.Decimal `json:"maxProvidesAmount" validate:"required"`
+	// ProvidesAmount is the requested amount, clamped to the offer's range and
+	// rounded to the asset's decimals. Clamped is set if it was outside of the
+	// range, in which case taking the offer with the requested amount fails.
+	ProvidesAmount *apd.Decimal `json:"providesAmount" validate:"required"`
+	Clamped        bool         `json:"clamped"`
+	ReceivedAmount *apd.Decimal `json:"receivedAmount" validate:"required"` // in XMR
+}
+
+// EstimateTake returns the amount of XMR we would receive for taking the offer
+// with the given amount, without initiating a swap.
+func (s *SwapService) EstimateTake(_ *http.Request, req *EstimateTakeRequest, resp *EstimateTakeResponse) error {
+	offer, err := queryOffer(s.net, req.PeerID, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	estimate, err := estimateTake(s.ctx, s.backend.ETHClient(), offer, req.ProvidesAmount)
+	if err != nil {
+		return err
+	}
+
+	*resp = *estimate
+	return nil
+}
+
+// estimateTake converts the provided amount to XMR the same way as taking the
+// offer does, clamping the amount to the offer's range first.
+func estimateTake(
+	ctx context.Context,
+	ec extethclient.EthClient,
+	offer *types.Offer,
+	providesAmount *apd.Decimal,
+) (*EstimateTakeResponse, error) {
+	err := coins.ValidatePositive("providesAmount", coins.NumEtherDecimals, providesAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	minETH, err := offer.ExchangeRate.ToETH(offer.MinAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	maxETH, err := offer.ExchangeRate.ToETH(offer.MaxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := providesAmount
+	switch {
+	case amount.Cmp(minETH) < 0:
+		amount = minETH
+	case amount.Cmp(maxETH) > 0:
+		amount = maxETH
+	}
+
+	providedAmount, err := pcommon.GetEthAssetAmount(ctx, ec, amount, offer.EthAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	receivedAmount, err := offer.ExchangeRate.ToXMR(providedAmount.AsStandard())
+	if err != nil {
+		return nil, err
+	}
+
+	return &EstimateTakeResponse{
+		EthAsset:          offer.EthAsset,
+		ExchangeRate:      offer.ExchangeRate,
+		MinProvidesAmount: minETH,
+		MaxProvidesAmount: maxETH,
+		ProvidesAmount:    providedAmount.AsStandard(),
+		Clamped:           amount != providesAmount,
+		ReceivedAmount:    receivedAmount,
+	}, nil
+}
+
+// EstimateGasRequest ...
+type EstimateGasRequest struct {
+	// EthAsset is the asset that we would provide in the swap, ETH if unset.
+	EthAsset types.EthAsset `json:"ethAsset,omitempty"`
+}
+
+// GasCostEstimate is the gas used by a set of swap transactions and its cost
+// at the current gas price.
+type GasCostEstimate struct {
+	Gas  uint64       `json:"gas"`
+	Cost *apd.Decimal `json:"cost" validate:"required"` // in ETH
+}
+
+// EstimateGasResponse ...
+type EstimateGasResponse struct {
+	GasPrice *coins.WeiAmount `json:"gasPrice" validate:"required"`
+	// Success is our cost as the taker for a swap that completes, which is
+	// creating the swap and setting it ready, after approving the token if
+	// the asset is an ERC20 token.
+	Success *GasCostEstimate `json:"success" validate:"required"`
+	// Refund is our cost as the taker for a swap that we refund after setting
+	// it ready, which is the most that a refunded swap costs.
+	Refund *GasCostEstimate `json:"refund" validate:"required"`
+	// SelfClaim is the maker's cost of claiming the swap's funds without a
+	// relayer.
+	SelfClaim *GasCostEstimate `json:"selfClaim" validate:"required"`
+	// RelayedClaimFee is the fixed fee, in ETH, that is deducted from the
+	// swap's funds when the maker claims using a relayer. Relayers are not
+	// used for ERC20 swaps, so it is not set for them.
+	RelayedClaimFee *apd.Decimal `json:"relayedClaimFee,omitempty"`
+}
+
+// EstimateGas estimates the gas cost of each party's swap transactions with
+// the given asset at the current gas price. The estimates use the most gas
+// that we have seen each contract function use, so they are upper bounds.
+func (s *SwapService) EstimateGas(_ *http.Request, req *EstimateGasRequest, resp *EstimateGasResponse) error {
+	gasPrice, err := s.backend.ETHClient().SuggestGasPrice(s.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	*resp = *estimateSwapGas(req.EthAsset, gasPrice)
+	return nil
+}
+
+// estimateSwapGas returns the gas cost estimates of the swap transactions with
+// the given asset at the given gas price.
+func estimateSwapGas(asset types.EthAsset, gasPrice *big.Int) *EstimateGasResponse {
+	estimate := func(gas uint64) *GasCostEstimate {
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+		return &GasCostEstimate{
+			Gas:  gas,
+			Cost: coins.NewWeiAmount(cost).AsEther(),
+		}
+	}
+
+	resp := &EstimateGasResponse{
+		GasPrice: coins.NewWeiAmount(gasPrice),
+	}
+
+	if asset.IsToken() {
+		lockGas := uint64(contracts.MaxTokenApproveGas + contracts.MaxNewSwapTokenGas + contracts.MaxSetReadyGas)
+		resp.Success = estimate(lockGas)
+		resp.Refund = estimate(lockGas + contracts.MaxRefundTokenGas)
+		resp.SelfClaim = estimate(contracts.MaxClaimTokenGas)
+		return resp
+	}
+
+	lockGas := uint64(contracts.MaxNewSwapETHGas + contracts.MaxSetReadyGas)
+	resp.Success = estimate(lockGas)
+	resp.Refund = estimate(lockGas + contracts.MaxRefundETHGas)
+	resp.SelfClaim = estimate(contracts.MaxClaimETHGas)
+	resp.RelayedClaimFee = new(apd.Decimal).Set(coins.RelayerFeeETH)
+	return resp
+}
+
+// SuggestedExchangeRateRequest ...
+type SuggestedExchangeRateRequest struct {
+	// ForceRefresh reads the price feeds even if a cached rate has not expired.
+	ForceRefresh bool `json:"forceRefresh,omitempty"`
+}
+
 // SuggestedExchangeRateResponse ...
 type SuggestedExchangeRateResponse struct {
 	ETHUpdatedAt time.Time           `json:"ethUpdatedAt" validate:"required"`
@@ -405,34 +1222,94 @@ type SuggestedExchangeRateResponse struct {
 	XMRUpdatedAt time.Time           `json:"xmrUpdatedAt" validate:"required"`
 	XMRPrice     *apd.Decimal        `json:"xmrPrice" validate:"required"`
 	ExchangeRate *coins.ExchangeRate `json:"exchangeRate" validate:"required"`
+	Provider     string              `json:"provider"` // the price provider that the prices came from
 }
 
 // SuggestedExchangeRate returns the current mainnet exchange rate, expressed as the XMR/ETH price.
-func (s *SwapService) SuggestedExchangeRate(_ *http.Request, _ *interface{}, resp *SuggestedExchangeRateResponse) error { //nolint:lll
-	ec := s.backend.ETHClient().Raw()
-
-	xmrFeed, err := pricefeed.GetXMRUSDPrice(s.ctx, ec)
+// If swapd caches the rate, the cached rate is returned until it expires, unless a refresh is forced.
+func (s *SwapService) SuggestedExchangeRate(
+	_ *http.Request,
+	req *SuggestedExchangeRateRequest,
+	resp *SuggestedExchangeRateResponse,
+) error {
+	rate, err := s.rates.get(s.ctx, s.backend.ETHClient().Raw(), req.ForceRefresh)
 	if err != nil {
 		return err
 	}
 
-	ethFeed, err := pricefeed.GetETHUSDPrice(s.ctx, ec)
+	*resp = *rate
+	return nil
+}
+
+// suggestedExchangeRate returns the exchange rate from the first of the price
+// providers, in order, that returns prices updated within maxAge.
+func suggestedExchangeRate(
+	ctx context.Context,
+	providers []pricefeed.Provider,
+	maxAge time.Duration,
+) (*SuggestedExchangeRateResponse, error) {
+	rate, err := pricefeed.GetExchangeRate(ctx, providers, maxAge)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	exchangeRate, err := coins.CalcExchangeRate(xmrFeed.Price, ethFeed.Price)
-	if err != nil {
-		return err
+	return &SuggestedExchangeRateResponse{
+		XMRUpdatedAt: rate.XMRFeed.UpdatedAt,
+		XMRPrice:     rate.XMRFeed.Price,
+		ETHUpdatedAt: rate.ETHFeed.UpdatedAt,
+		ETHPrice:     rate.ETHFeed.Price,
+		ExchangeRate: rate.ExchangeRate,
+		Provider:     rate.Provider,
+	}, nil
+}
+
+// RateSampleDB is the database of the exchange rate samples taken by swapd.
+type RateSampleDB interface {
+	GetRateSamples(since time.Time, until time.Time) ([]*db.RateSample, error)
+}
+
+// RateHistoryRequest ...
+type RateHistoryRequest struct {
+	// Since and Until optionally limit the returned samples to those taken in
+	// the time range, inclusive.
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// RateHistoryResponse ...
+type RateHistoryResponse struct {
+	Samples []*db.RateSample `json:"samples" validate:"dive,required"`
+}
+
+// RateHistory returns the stored samples of the suggested exchange rate,
+// ordered from oldest to newest. Samples are only taken if swapd was started
+// with a rate sample interval.
+func (s *SwapService) RateHistory(_ *http.Request, req *RateHistoryRequest, resp *RateHistoryResponse) error {
+	if s.rateSamples == nil {
+		return errRateHistoryDisabled
 	}
 
-	resp.XMRUpdatedAt = xmrFeed.UpdatedAt
-	resp.XMRPrice = xmrFeed.Price
+	if req.Since != nil && req.Until != nil && req.Until.Before(*req.Since) {
+		return errSinceAfterUntil
+	}
 
-	resp.ETHUpdatedAt = ethFeed.UpdatedAt
-	resp.ETHPrice = ethFeed.Price
+	var since, until time.Time
+	if req.Since != nil {
+		since = *req.Since
+	}
+	if req.Until != nil {
+		until = *req.Until
+	}
 
-	resp.ExchangeRate = exchangeRate
+	samples, err := s.rateSamples.GetRateSamples(since, until)
+	if err != nil {
+		return err
+	}
+
+	if samples == nil {
+		samples = []*db.RateSample{}
+	}
+	resp.Samples = samples
 	return nil
 }
 
@@ -0,0 +1,497 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/db"
+	contracts "github.com/athanorlabs/atomic-swap/ethereum"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
+)
+
+func TestSwap_GetStatus(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(GetStatusResponse)
+	err := ss.GetStatus(nil, &GetStatusRequest{ID: testSwapID}, resp)
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedSuccess, resp.Status)
+	require.Equal(t, types.CompletedSuccess.Description(), resp.Description)
+
+	// completed swaps have no time left to completion
+	require.Zero(t, resp.EstimatedTimeToCompletion)
+}
+
+func TestEstimatedTimeToCompletion(t *testing.T) {
+	estimate, err := estimatedTimeToCompletion(common.Development, types.XMRLocked, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 14*time.Second, estimate)
+
+	_, err = estimatedTimeToCompletion(common.Development, types.CompletedSuccess, time.Now())
+	require.Error(t, err)
+}
+
+func TestSwap_Export(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(ExportResponse)
+	err := ss.Export(nil, &ExportRequest{OfferID: types.Hash{1}}, resp)
+	require.NoError(t, err)
+	require.NotNil(t, resp.Swap)
+	require.Nil(t, resp.ContractSwapInfo)
+}
+
+func TestSwap_Export_ongoing(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	err := ss.Export(nil, &ExportRequest{OfferID: testSwapID}, new(ExportResponse))
+	require.ErrorIs(t, err, errSwapOngoing)
+}
+
+func TestSwap_SweepSharedWallet_refused(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		new(mockRecoveryDB),
+	)
+
+	req := &SweepSharedWalletRequest{OfferID: testSwapID}
+	err := ss.SweepSharedWallet(nil, req, new(SweepSharedWalletResponse))
+	require.ErrorIs(t, err, errSweepOngoingSwap)
+
+	// the swap keys are not in the recovery database
+	req = &SweepSharedWalletRequest{OfferID: types.Hash{1}}
+	err = ss.SweepSharedWallet(nil, req, new(SweepSharedWalletResponse))
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+	require.ErrorContains(t, err, "our swap private key is not in the recovery database")
+}
+
+func TestGetPastRequest_filter(t *testing.T) {
+	require.Nil(t, new(GetPastRequest).filter())
+
+	now := time.Now()
+	since := now.Add(-time.Hour)
+	status := types.CompletedRefund
+	filter := (&GetPastRequest{Status: &status, Since: &since, Until: &now}).filter()
+
+	require.True(t, filter(&swap.Info{Status: types.CompletedRefund, StartTime: since}))
+	require.True(t, filter(&swap.Info{Status: types.CompletedRefund, StartTime: now}))
+	require.False(t, filter(&swap.Info{Status: types.CompletedSuccess, StartTime: since}))
+	require.False(t, filter(&swap.Info{Status: types.CompletedRefund, StartTime: since.Add(-time.Second)}))
+	require.False(t, filter(&swap.Info{Status: types.CompletedRefund, StartTime: now.Add(time.Second)}))
+}
+
+func TestSwap_GetPast_sinceAfterUntil(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	now := time.Now()
+	since := now.Add(time.Hour)
+	err := ss.GetPast(nil, &GetPastRequest{Since: &since, Until: &now}, new(GetPastResponse))
+	require.ErrorIs(t, err, errSinceAfterUntil)
+}
+
+func TestSwap_RateHistory(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	err := ss.RateHistory(nil, new(RateHistoryRequest), new(RateHistoryResponse))
+	require.ErrorIs(t, err, errRateHistoryDisabled)
+
+	sdb, err := db.NewDatabase(&chaindb.Config{
+		DataDir:  t.TempDir(),
+		InMemory: true,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sdb.Close()) }()
+	ss.rateSamples = sdb
+
+	resp := new(RateHistoryResponse)
+	require.NoError(t, ss.RateHistory(nil, new(RateHistoryRequest), resp))
+	require.NotNil(t, resp.Samples)
+	require.Empty(t, resp.Samples)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		err = sdb.PutRateSample(&db.RateSample{
+			Time:         now.Add(time.Duration(i-2) * time.Hour),
+			XMRPrice:     coins.StrToDecimal("150"),
+			ETHPrice:     coins.StrToDecimal("2000"),
+			ExchangeRate: coins.ToExchangeRate(coins.StrToDecimal("0.075")),
+		})
+		require.NoError(t, err)
+	}
+
+	since := now.Add(-90 * time.Minute)
+	resp = new(RateHistoryResponse)
+	require.NoError(t, ss.RateHistory(nil, &RateHistoryRequest{Since: &since}, resp))
+	require.Len(t, resp.Samples, 2)
+
+	until := now.Add(-3 * time.Hour)
+	err = ss.RateHistory(nil, &RateHistoryRequest{Since: &since, Until: &until}, new(RateHistoryResponse))
+	require.ErrorIs(t, err, errSinceAfterUntil)
+}
+
+// cancelTestSwapManager holds ongoing swaps with mixed statuses, reporting any
+// swap as aborted once it was exited.
+type cancelTestSwapManager struct {
+	mockSwapManager
+	ongoing []*swap.Info
+}
+
+func (m *cancelTestSwapManager) GetOngoingSwaps() ([]*swap.Info, error) {
+	return m.ongoing, nil
+}
+
+func (m *cancelTestSwapManager) HasOngoingSwap(id types.Hash) bool {
+	for _, info := range m.ongoing {
+		if info.OfferID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *cancelTestSwapManager) GetOngoingSwap(id types.Hash) (swap.Info, error) {
+	for _, info := range m.ongoing {
+		if info.OfferID == id {
+			return *info, nil
+		}
+	}
+	return swap.Info{}, errors.New("no such swap")
+}
+
+func (m *cancelTestSwapManager) GetPastSwap(id types.Hash) (*swap.Info, error) {
+	return &swap.Info{OfferID: id, Status: types.CompletedAbort}, nil
+}
+
+// cancelTestSwapState records whether it was exited, failing with exitErr.
+type cancelTestSwapState struct {
+	mockSwapState
+	exited  bool
+	exitErr error
+}
+
+func (s *cancelTestSwapState) Exit() error {
+	s.exited = true
+	return s.exitErr
+}
+
+type cancelTestXMRMaker struct {
+	mockXMRMaker
+	states map[types.Hash]*cancelTestSwapState
+}
+
+func (m *cancelTestXMRMaker) GetOngoingSwapState(id types.Hash) common.SwapState {
+	return m.states[id]
+}
+
+type cancelTestNet struct {
+	mockNet
+}
+
+func (*cancelTestNet) CloseProtocolStream(_ types.Hash) {}
+
+func TestSwap_CancelByStatus(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	newInfo := func(id byte, status types.Status, lastUpdate time.Time) *swap.Info {
+		return &swap.Info{
+			OfferID:              types.Hash{id},
+			Provides:             coins.ProvidesXMR,
+			Status:               status,
+			LastStatusUpdateTime: lastUpdate,
+		}
+	}
+
+	sm := &cancelTestSwapManager{
+		ongoing: []*swap.Info{
+			newInfo(1, types.ExpectingKeys, old), // cancelled
+			newInfo(2, types.ExpectingKeys, old), // fails to exit
+			newInfo(3, types.ExpectingKeys, now), // too recently updated
+			newInfo(4, types.KeysExchanged, old), // different status
+			newInfo(5, types.XMRLocked, old),     // not cancellable
+		},
+	}
+	exitErr := errors.New("exit failed")
+	maker := &cancelTestXMRMaker{states: map[types.Hash]*cancelTestSwapState{}}
+	for _, info := range sm.ongoing {
+		maker.states[info.OfferID] = new(cancelTestSwapState)
+	}
+	maker.states[types.Hash{2}].exitErr = exitErr
+
+	ss := NewSwapService(
+		context.Background(),
+		sm,
+		new(mockXMRTaker),
+		maker,
+		new(cancelTestNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(CancelByStatusResponse)
+	req := &CancelByStatusRequest{Status: types.ExpectingKeys, OlderThan: 60}
+	require.NoError(t, ss.CancelByStatus(nil, req, resp))
+	require.Equal(t, []*CancelByStatusResult{
+		{OfferID: types.Hash{1}, Status: types.CompletedAbort},
+		{OfferID: types.Hash{2}, Status: types.ExpectingKeys, Error: exitErr.Error()},
+	}, resp.Results)
+
+	// matching swaps that are no longer cancellable are skipped, not exited
+	resp = new(CancelByStatusResponse)
+	require.NoError(t, ss.CancelByStatus(nil, &CancelByStatusRequest{Status: types.XMRLocked}, resp))
+	require.Equal(t, []*CancelByStatusResult{
+		{OfferID: types.Hash{5}, Status: types.XMRLocked, Skipped: true},
+	}, resp.Results)
+
+	for id, state := range maker.states {
+		wantExited := id == types.Hash{1} || id == types.Hash{2}
+		require.Equal(t, wantExited, state.exited, "swap %s", id)
+	}
+
+	// only ongoing statuses can be cancelled
+	err := ss.CancelByStatus(nil, &CancelByStatusRequest{Status: types.CompletedSuccess}, resp)
+	require.ErrorContains(t, err, "is not an ongoing swap status")
+}
+
+func TestSwap_Cancel(t *testing.T) {
+	newInfo := func(id byte, status types.Status) *swap.Info {
+		return &swap.Info{
+			OfferID:  types.Hash{id},
+			Provides: coins.ProvidesXMR,
+			Status:   status,
+		}
+	}
+
+	sm := &cancelTestSwapManager{
+		ongoing: []*swap.Info{
+			newInfo(1, types.KeysExchanged), // aborted
+			newInfo(2, types.XMRLocked),     // refuses to exit
+		},
+	}
+	maker := &cancelTestXMRMaker{states: map[types.Hash]*cancelTestSwapState{
+		{1}: new(cancelTestSwapState),
+		{2}: {exitErr: errors.New("our XMR is locked")},
+	}}
+
+	ss := NewSwapService(
+		context.Background(),
+		sm,
+		new(mockXMRTaker),
+		maker,
+		new(cancelTestNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(CancelResponse)
+	require.NoError(t, ss.Cancel(nil, &CancelRequest{OfferID: types.Hash{1}}, resp))
+	require.Equal(t, types.CompletedAbort, resp.Status)
+	require.Equal(t, CancelReasonAborted, resp.Reason)
+
+	resp = new(CancelResponse)
+	require.NoError(t, ss.Cancel(nil, &CancelRequest{OfferID: types.Hash{2}}, resp))
+	require.Equal(t, types.XMRLocked, resp.Status)
+	require.Equal(t, CancelReasonPastPointOfNoReturn, resp.Reason)
+	require.Contains(t, resp.Message, "our XMR is locked")
+
+	resp = new(CancelResponse)
+	require.NoError(t, ss.Cancel(nil, &CancelRequest{OfferID: types.Hash{3}}, resp))
+	require.Equal(t, CancelReasonNoSuchSwap, resp.Reason)
+	require.Zero(t, resp.Status)
+}
+
+func TestSwap_CancelAll(t *testing.T) {
+	newInfo := func(id byte, status types.Status) *swap.Info {
+		return &swap.Info{
+			OfferID:  types.Hash{id},
+			Provides: coins.ProvidesXMR,
+			Status:   status,
+		}
+	}
+
+	sm := &cancelTestSwapManager{
+		ongoing: []*swap.Info{
+			newInfo(1, types.KeysExchanged), // aborted
+			newInfo(2, types.XMRLocked),     // refuses to exit
+			newInfo(3, types.ExpectingKeys), // fails to exit
+		},
+	}
+	exitErr := errors.New("exit failed")
+	maker := &cancelTestXMRMaker{states: map[types.Hash]*cancelTestSwapState{
+		{1}: new(cancelTestSwapState),
+		{2}: {exitErr: errors.New("our XMR is locked")},
+		{3}: {exitErr: exitErr},
+	}}
+
+	ss := NewSwapService(
+		context.Background(),
+		sm,
+		new(mockXMRTaker),
+		maker,
+		new(cancelTestNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	resp := new(CancelAllResponse)
+	require.NoError(t, ss.CancelAll(nil, nil, resp))
+	require.Len(t, resp.Results, 3)
+
+	require.Equal(t, types.CompletedAbort, resp.Results[types.Hash{1}].Status)
+	require.Equal(t, CancelReasonAborted, resp.Results[types.Hash{1}].Reason)
+
+	require.Equal(t, types.XMRLocked, resp.Results[types.Hash{2}].Status)
+	require.Equal(t, CancelReasonPastPointOfNoReturn, resp.Results[types.Hash{2}].Reason)
+
+	require.Equal(t, &CancelResponse{
+		Status:  types.ExpectingKeys,
+		Reason:  CancelReasonFailed,
+		Message: exitErr.Error(),
+	}, resp.Results[types.Hash{3}])
+
+	for id, state := range maker.states {
+		require.True(t, state.exited, "swap %s", id)
+	}
+}
+
+func TestSwap_EstimateGas(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	// the mock eth client suggests a gas price of 10 gwei
+	resp := new(EstimateGasResponse)
+	require.NoError(t, ss.EstimateGas(nil, new(EstimateGasRequest), resp))
+	require.Equal(t, "10000000000", resp.GasPrice.BigInt().String())
+	require.Equal(t, uint64(contracts.MaxNewSwapETHGas+contracts.MaxSetReadyGas), resp.Success.Gas)
+	require.Equal(t, "0.00082461", resp.Success.Cost.Text('f'))
+	require.Equal(t, resp.Success.Gas+contracts.MaxRefundETHGas, resp.Refund.Gas)
+	require.Equal(t, uint64(contracts.MaxClaimETHGas), resp.SelfClaim.Gas)
+	require.Equal(t, coins.RelayerFeeETH.Text('f'), resp.RelayedClaimFee.Text('f'))
+
+	tokenReq := &EstimateGasRequest{EthAsset: types.EthAsset(ethcommon.Address{0x1})}
+	resp = new(EstimateGasResponse)
+	require.NoError(t, ss.EstimateGas(nil, tokenReq, resp))
+	require.Equal(t,
+		uint64(contracts.MaxTokenApproveGas+contracts.MaxNewSwapTokenGas+contracts.MaxSetReadyGas),
+		resp.Success.Gas,
+	)
+	require.Equal(t, uint64(contracts.MaxClaimTokenGas), resp.SelfClaim.Gas)
+	require.Nil(t, resp.RelayedClaimFee)
+}
+
+func TestSwap_SetGasPriceOverride(t *testing.T) {
+	ss := NewSwapService(
+		context.Background(),
+		new(mockSwapManager),
+		new(mockXMRTaker),
+		new(mockXMRMaker),
+		new(mockNet),
+		newMockProtocolBackend(),
+		nil,
+	)
+
+	// the mock eth client suggests a gas price of 10 gwei
+	req := &SetGasPriceOverrideRequest{OfferID: testSwapID, GasPrice: 10}
+	err := ss.SetGasPriceOverride(nil, req, nil)
+	require.ErrorIs(t, err, errGasPriceOverrideTooLow)
+
+	req.GasPrice = 50
+	require.NoError(t, ss.SetGasPriceOverride(nil, req, nil))
+}
+
+func TestSwap_estimateTake(t *testing.T) {
+	offer := types.NewOffer(
+		coins.ProvidesXMR,
+		coins.StrToDecimal("1"),
+		coins.StrToDecimal("5"),
+		coins.StrToExchangeRate("0.1"),
+		types.EthAssetETH,
+	)
+	ec := newMockProtocolBackend().ETHClient()
+
+	for _, tc := range []struct {
+		providesAmount string
+		expectedAmount string
+		expectedXMR    string
+		clamped        bool
+	}{
+		{"0.25", "0.25", "2.5", false},
+		{"0.123456789", "0.123456789", "1.23456789", false},
+		{"0.05", "0.1", "1", true}, // under the offer's minimum of 0.1 ETH
+		{"0.75", "0.5", "5", true}, // over the offer's maximum of 0.5 ETH
+	} {
+		estimate, err := estimateTake(context.Background(), ec, offer, coins.StrToDecimal(tc.providesAmount))
+		require.NoError(t, err)
+		require.Equal(t, tc.expectedAmount, estimate.ProvidesAmount.Text('f'), tc.providesAmount)
+		require.Zero(t, estimate.ReceivedAmount.Cmp(coins.StrToDecimal(tc.expectedXMR)), tc.providesAmount)
+		require.Equal(t, tc.clamped, estimate.Clamped, tc.providesAmount)
+		require.Equal(t, "0.1", estimate.MinProvidesAmount.Text('f'))
+		require.Equal(t, "0.5", estimate.MaxProvidesAmount.Text('f'))
+	}
+
+	_, err := estimateTake(context.Background(), ec, offer, coins.StrToDecimal("-1"))
+	require.Error(t, err)
+}
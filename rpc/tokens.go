@@ -0,0 +1,110 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+// TokenDB is the database of the ERC20 token metadata cached by swapd.
+type TokenDB interface {
+	PutTokenInfo(env common.Environment, token *coins.ERC20TokenInfo) error
+	GetTokenInfo(env common.Environment, addr ethcommon.Address) (*coins.ERC20TokenInfo, error)
+	GetAllTokenInfo(env common.Environment) ([]*coins.ERC20TokenInfo, error)
+}
+
+// tokenCache looks up the metadata of ERC20 tokens, caching it in the database
+// so that the metadata of each token is only read from the chain once. Cached
+// metadata is only replaced when it is explicitly refreshed.
+type tokenCache struct {
+	ctx context.Context
+	db  TokenDB
+	pb  ProtocolBackend
+}
+
+func newTokenCache(ctx context.Context, db TokenDB, pb ProtocolBackend) *tokenCache {
+	return &tokenCache{
+		ctx: ctx,
+		db:  db,
+		pb:  pb,
+	}
+}
+
+// tokenInfo returns the metadata of the given token. The metadata is read from
+// the chain and cached if it is not cached yet, or if refresh is set.
+func (c *tokenCache) tokenInfo(addr ethcommon.Address, refresh bool) (*coins.ERC20TokenInfo, error) {
+	env := c.pb.Env()
+
+	if !refresh {
+		token, err := c.db.GetTokenInfo(env, addr)
+		if err == nil {
+			return token, nil
+		}
+
+		if !errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil, err
+		}
+	}
+
+	token, err := c.pb.ETHClient().ERC20Info(c.ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.db.PutTokenInfo(env, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// add caches the metadata of a token that was already read from the chain, if
+// the token is not cached yet. Failures are only logged, as the caller doesn't
+// depend on the cache. The cache is nil if token metadata is not cached.
+func (c *tokenCache) add(token *coins.ERC20TokenInfo) {
+	if c == nil {
+		return
+	}
+
+	env := c.pb.Env()
+
+	_, err := c.db.GetTokenInfo(env, token.Address)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		err = c.db.PutTokenInfo(env, token)
+	}
+
+	if err != nil {
+		log.Warnf("failed to cache metadata of token %s: %s", token.Address, err)
+	}
+}
+
+// addAsset caches the metadata of an offer's ETH asset, if the asset is a token
+// that is not cached yet. Failures are only logged, as the caller doesn't depend
+// on the cache. The cache is nil if token metadata is not cached.
+func (c *tokenCache) addAsset(asset types.EthAsset) {
+	if c == nil || asset.IsETH() {
+		return
+	}
+
+	if _, err := c.tokenInfo(asset.Address(), false); err != nil {
+		log.Warnf("failed to cache metadata of token %s: %s", asset, err)
+	}
+}
+
+// list returns the cached metadata of all tokens.
+func (c *tokenCache) list() ([]*coins.ERC20TokenInfo, error) {
+	return c.db.GetAllTokenInfo(c.pb.Env())
+}
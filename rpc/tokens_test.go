@@ -0,0 +1,78 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/types"
+)
+
+type mockTokenDB struct {
+	tokens map[ethcommon.Address]*coins.ERC20TokenInfo
+}
+
+func (m *mockTokenDB) PutTokenInfo(_ common.Environment, token *coins.ERC20TokenInfo) error {
+	m.tokens[token.Address] = token
+	return nil
+}
+
+func (m *mockTokenDB) GetTokenInfo(_ common.Environment, addr ethcommon.Address) (*coins.ERC20TokenInfo, error) {
+	token, has := m.tokens[addr]
+	if !has {
+		return nil, chaindb.ErrKeyNotFound
+	}
+	return token, nil
+}
+
+func (m *mockTokenDB) GetAllTokenInfo(_ common.Environment) ([]*coins.ERC20TokenInfo, error) {
+	var tokens []*coins.ERC20TokenInfo
+	for _, token := range m.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func TestTokenCache(t *testing.T) {
+	tokenDB := &mockTokenDB{tokens: make(map[ethcommon.Address]*coins.ERC20TokenInfo)}
+	c := newTokenCache(context.Background(), tokenDB, newMockProtocolBackend())
+
+	addr := ethcommon.Address{0x1}
+	token, err := c.tokenInfo(addr, false)
+	require.NoError(t, err)
+	require.Equal(t, "MOCK", token.Symbol)
+	require.Contains(t, tokenDB.tokens, addr)
+
+	// cached metadata is returned until it is refreshed
+	tokenDB.tokens[addr] = coins.NewERC20TokenInfo(addr, 18, "Cached Token", "CACHED")
+	token, err = c.tokenInfo(addr, false)
+	require.NoError(t, err)
+	require.Equal(t, "CACHED", token.Symbol)
+
+	token, err = c.tokenInfo(addr, true)
+	require.NoError(t, err)
+	require.Equal(t, "MOCK", token.Symbol)
+	require.Equal(t, "MOCK", tokenDB.tokens[addr].Symbol)
+
+	// adding doesn't replace cached metadata
+	c.add(coins.NewERC20TokenInfo(addr, 18, "Other Token", "OTHER"))
+	require.Equal(t, "MOCK", tokenDB.tokens[addr].Symbol)
+
+	c.addAsset(types.EthAssetETH)
+	c.addAsset(types.EthAsset(ethcommon.Address{0x2}))
+	tokens, err := c.list()
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+
+	// a nil cache ignores added tokens
+	var nilCache *tokenCache
+	nilCache.addAsset(types.EthAsset(ethcommon.Address{0x3}))
+}
@@ -4,45 +4,75 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common"
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: checkOriginFunc,
-}
+// wsStatusEventBufSize is the number of events that can be queued for a
+// swap_subscribeAllStatus or net_subscribeOffersTaken subscriber before it is
+// disconnected for not keeping up.
+const wsStatusEventBufSize = 32
+
+// wsBalancesUnlockPollInterval is how often the balances of a
+// personal_subscribeBalances subscriber are re-read while some of the XMR
+// balance is locked, as XMR unlocking is not tied to a swap update.
+const wsBalancesUnlockPollInterval = 30 * time.Second
+
+// wsCloseTimeout is how long we wait to send the close message to a websocket
+// connection that is rejected.
+const wsCloseTimeout = time.Second
 
 func checkOriginFunc(_ *http.Request) bool {
 	return true
 }
 
 type wsServer struct {
-	ctx     context.Context
-	sm      SwapManager
-	ns      *NetService
-	backend ProtocolBackend
-	taker   XMRTaker
+	ctx      context.Context
+	sm       SwapManager
+	ns       *NetService
+	ps       *PersonalService
+	backend  ProtocolBackend
+	taker    XMRTaker
+	upgrader websocket.Upgrader
+	conns    *wsConnCounter
+
+	// in observer mode, only the net_ requests are served
+	isObserver bool
 }
 
-func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend ProtocolBackend,
-	taker XMRTaker) *wsServer {
+// newWsServer creates the websocket server. If compression is true, messages
+// are compressed for clients that negotiate the permessage-deflate extension.
+// Connections past the maximum of conns are rejected.
+func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, ps *PersonalService,
+	backend ProtocolBackend, taker XMRTaker, compression bool, conns *wsConnCounter) *wsServer {
 	s := &wsServer{
 		ctx:     ctx,
 		sm:      sm,
 		ns:      ns,
+		ps:      ps,
 		backend: backend,
 		taker:   taker,
+		upgrader: websocket.Upgrader{
+			CheckOrigin:       checkOriginFunc,
+			EnableCompression: compression,
+		},
+		conns: conns,
 	}
 
 	return s
@@ -50,7 +80,18 @@ func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend Pr
 
 // ServeHTTP ...
 func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	// Each connection has at most one active subscription, as the request loop
+	// below is blocked while a subscription is being served, so limiting
+	// connections also limits subscriptions. The slot is taken before
+	// upgrading, so we don't set up a full connection only to reject it.
+	if !s.conns.tryAcquire() {
+		log.Warnf("rejecting websocket connection from %s, limit of %d reached", r.RemoteAddr, s.conns.max)
+		s.reject(w, r)
+		return
+	}
+	defer s.conns.release()
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Warnf("failed to update connection to websockets: %s", err)
 		return
@@ -58,11 +99,18 @@ func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	defer func() { _ = conn.Close() }()
 
+	// Messages are read in their own goroutine, so that we notice the client
+	// closing the connection while a subscription is being served. Otherwise,
+	// subscriptions that rarely write would keep the connection slot of a
+	// client that is long gone.
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	msgCh := readMessages(ctx, cancel, conn)
+
 	for {
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			log.Debugf("failed to read websockets message: %s", err)
-			break
+		message, ok := nextMessage(ctx, msgCh)
+		if !ok {
+			return
 		}
 
 		req := new(rpctypes.Request)
@@ -73,14 +121,78 @@ func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		log.Debugf("received message over websockets: %s", message)
-		err = s.handleRequest(conn, req)
+		err = s.handleRequest(ctx, conn, msgCh, req)
 		if err != nil {
 			_ = writeError(conn, err)
 		}
 	}
 }
 
-func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) error {
+// readMessages reads messages from the connection, passing them to the returned
+// channel. When reading fails, which includes the client closing the
+// connection, it calls cancel and closes the channel.
+func readMessages(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) <-chan []byte {
+	msgCh := make(chan []byte)
+
+	go func() {
+		defer close(msgCh)
+		defer cancel()
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				log.Debugf("failed to read websockets message: %s", err)
+				return
+			}
+
+			select {
+			case msgCh <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return msgCh
+}
+
+// nextMessage returns the next message read from the connection, or false if
+// the connection was closed or ctx is done.
+func nextMessage(ctx context.Context, msgCh <-chan []byte) ([]byte, bool) {
+	select {
+	case message, ok := <-msgCh:
+		return message, ok
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// reject upgrades a connection past the connection limit just to close it with
+// a reason, so that clients can tell why they were disconnected.
+func (s *wsServer) reject(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warnf("failed to update connection to websockets: %s", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, errTooManyWsConns.Error())
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsCloseTimeout))
+}
+
+// handleRequest serves a request until it completes or ctx is cancelled. The
+// messages of the connection that follow the request are read from msgCh.
+func (s *wsServer) handleRequest(
+	ctx context.Context,
+	conn *websocket.Conn,
+	msgCh <-chan []byte,
+	req *rpctypes.Request,
+) error {
+	if s.isObserver && req.Method != rpctypes.NetDiscover && req.Method != rpctypes.NetQueryPeer {
+		return errObserverMode
+	}
+
 	switch req.Method {
 	case rpctypes.SubscribeSigner:
 		params := new(rpctypes.SignerRequest)
@@ -88,7 +200,7 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
-		return s.handleSigner(s.ctx, conn, params.OfferID, params.EthAddress, params.XMRAddress)
+		return s.handleSigner(ctx, conn, msgCh, params.OfferID, params.EthAddress, params.XMRAddress)
 	case rpctypes.SubscribeNewPeer:
 		return errUnimplemented
 	case rpctypes.NetDiscover:
@@ -131,7 +243,22 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
-		return s.subscribeSwapStatus(s.ctx, conn, params.OfferID)
+		return s.subscribeSwapStatus(ctx, conn, params.OfferID)
+	case rpctypes.SubscribeAllStatus:
+		return s.subscribeAllSwapStatus(ctx, conn)
+	case rpctypes.SubscribeBalances:
+		if s.ps == nil {
+			return errNamespaceNotEnabled
+		}
+
+		params := new(rpctypes.BalancesRequest)
+		if len(req.Params) > 0 {
+			if err := vjson.UnmarshalStruct(req.Params, params); err != nil {
+				return fmt.Errorf("failed to unmarshal parameters: %w", err)
+			}
+		}
+
+		return s.subscribeBalances(ctx, conn, params)
 	case rpctypes.SubscribeTakeOffer:
 		if s.ns == nil {
 			return errNamespaceNotEnabled
@@ -142,12 +269,12 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
-		ch, err := s.ns.takeOffer(params.PeerID, params.OfferID, params.ProvidesAmount)
+		ch, err := s.ns.takeOffer(params.PeerID, params.OfferID, params.ProvidesAmount, params.MaxExchangeRate)
 		if err != nil {
 			return err
 		}
 
-		return s.subscribeTakeOffer(s.ctx, conn, ch)
+		return s.subscribeTakeOffer(ctx, conn, ch)
 	case rpctypes.SubscribeMakeOffer:
 		if s.ns == nil {
 			return errNamespaceNotEnabled
@@ -163,7 +290,21 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return err
 		}
 
-		return s.subscribeMakeOffer(s.ctx, conn, offerResp.OfferID, offerExtra)
+		if offerExtra == nil {
+			return errKeyedOfferGone
+		}
+
+		return s.subscribeMakeOffer(ctx, conn, offerResp.OfferID, offerExtra)
+	case rpctypes.SubscribeOffersTaken:
+		if s.ns == nil {
+			return errNamespaceNotEnabled
+		}
+
+		if s.ns.isBootnode {
+			return errUnsupportedForBootnode
+		}
+
+		return s.subscribeOffersTaken(ctx, conn, s.ns.xmrmaker)
 	default:
 		return errInvalidMethod
 	}
@@ -172,6 +313,7 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 func (s *wsServer) handleSigner(
 	ctx context.Context,
 	conn *websocket.Conn,
+	msgCh <-chan []byte,
 	offerID types.Hash,
 	ethAddress ethcommon.Address,
 	xmrAddr *mcrypto.Address,
@@ -202,7 +344,7 @@ func (s *wsServer) handleSigner(
 
 	for {
 		select {
-		// TODO: check if conn closes or swap exited (#165)
+		// TODO: check if swap exited (#165)
 		case <-time.After(timeout):
 			return fmt.Errorf("signer timed out")
 		case <-ctx.Done():
@@ -215,15 +357,25 @@ func (s *wsServer) handleSigner(
 				Data:    tx.Data,
 				Value:   tx.Value,
 			}
+			if tx.MaxGasPrice != nil {
+				resp.GasPrice = coins.NewWeiAmount(tx.GasPrice)
+				resp.MaxGasPrice = coins.NewWeiAmount(tx.MaxGasPrice)
+			}
+			if tx.MaxFeePerGas != nil {
+				resp.MaxFeePerGas = coins.NewWeiAmount(tx.MaxFeePerGas)
+			}
+			if tx.MaxPriorityFeePerGas != nil {
+				resp.MaxPriorityFeePerGas = coins.NewWeiAmount(tx.MaxPriorityFeePerGas)
+			}
 
 			err := conn.WriteJSON(resp)
 			if err != nil {
 				return err
 			}
 
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				return err
+			message, ok := nextMessage(ctx, msgCh)
+			if !ok {
+				return nil
 			}
 
 			params := new(rpctypes.SignerTxSigned)
@@ -337,6 +489,169 @@ func (s *wsServer) subscribeSwapStatus(ctx context.Context, conn *websocket.Conn
 	}
 }
 
+// subscribeAllSwapStatus writes the status of every ongoing swap to the connection,
+// followed by each status change of any swap until the connection or swapd is closed.
+// example: `{"jsonrpc":"2.0", "method":"swap_subscribeAllStatus", "params": {}, "id": 0}`
+func (s *wsServer) subscribeAllSwapStatus(ctx context.Context, conn *websocket.Conn) error {
+	eventCh := make(chan *rpctypes.SwapStatusEvent, wsStatusEventBufSize)
+	overflowCh := make(chan struct{})
+	var overflowOnce sync.Once
+
+	// The listener is called with the swap manager's lock held, so it must not
+	// block. Subscribers that fall behind are disconnected rather than silently
+	// missing a status change.
+	removeListener := s.sm.AddStatusListener(func(info *swap.Info) {
+		select {
+		case eventCh <- &rpctypes.SwapStatusEvent{OfferID: info.OfferID, Status: info.Status}:
+		default:
+			overflowOnce.Do(func() { close(overflowCh) })
+		}
+	})
+	defer removeListener()
+
+	// the listener is registered before reading the ongoing swaps, so no status
+	// change is missed; any duplicates are filtered out below
+	ongoing, err := s.sm.GetOngoingSwaps()
+	if err != nil {
+		return err
+	}
+
+	lastStatus := make(map[types.Hash]types.Status)
+	writeEvent := func(event *rpctypes.SwapStatusEvent) error {
+		// the swap manager notifies on every write of the swap, which does not
+		// always coincide with a status change
+		if last, has := lastStatus[event.OfferID]; has && last == event.Status {
+			return nil
+		}
+
+		if event.Status.IsOngoing() {
+			lastStatus[event.OfferID] = event.Status
+		} else {
+			delete(lastStatus, event.OfferID)
+		}
+
+		return writeResponse(conn, event)
+	}
+
+	for _, info := range ongoing {
+		if err = writeEvent(&rpctypes.SwapStatusEvent{OfferID: info.OfferID, Status: info.Status}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event := <-eventCh:
+			if err = writeEvent(event); err != nil {
+				return err
+			}
+		case <-overflowCh:
+			return errSubscriberTooSlow
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// subscribeOffersTaken writes an event to the connection each time a take of one
+// of our offers is accepted, until the connection or swapd is closed. Offers
+// made with `make --detached` are included.
+// example: `{"jsonrpc":"2.0", "method":"net_subscribeOffersTaken", "params": {}, "id": 0}`
+func (s *wsServer) subscribeOffersTaken(ctx context.Context, conn *websocket.Conn, maker XMRMaker) error {
+	eventCh := make(chan *types.OfferTakenEvent, wsStatusEventBufSize)
+	overflowCh := make(chan struct{})
+	var overflowOnce sync.Once
+
+	// The listener is called while the take is being handled, so it must not
+	// block. Subscribers that fall behind are disconnected rather than silently
+	// missing a take.
+	removeListener := maker.AddOfferTakenListener(func(event *types.OfferTakenEvent) {
+		select {
+		case eventCh <- event:
+		default:
+			overflowOnce.Do(func() { close(overflowCh) })
+		}
+	})
+	defer removeListener()
+
+	for {
+		select {
+		case event := <-eventCh:
+			if err := writeResponse(conn, event); err != nil {
+				return err
+			}
+		case <-overflowCh:
+			return errSubscriberTooSlow
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// subscribeBalances writes our balances to the connection, followed by our
+// balances each time they change, until the connection or swapd is closed. The
+// balances are re-read when a swap is updated, which covers claims, refunds
+// and swaps completing, and periodically while some XMR is locked, to report
+// it unlocking.
+// example: `{"jsonrpc":"2.0", "method":"personal_subscribeBalances", "params": {}, "id": 0}`
+func (s *wsServer) subscribeBalances(ctx context.Context, conn *websocket.Conn, req *rpctypes.BalancesRequest) error {
+	// The listener is called with the swap manager's lock held, so it must not
+	// block. Updates that arrive while the balances are being read are
+	// coalesced, as one read covers them all.
+	updateCh := make(chan struct{}, 1)
+	removeListener := s.sm.AddStatusListener(func(_ *swap.Info) {
+		select {
+		case updateCh <- struct{}{}:
+		default:
+		}
+	})
+	defer removeListener()
+
+	var last []byte
+	writeBalances := func() (*rpctypes.BalancesResponse, error) {
+		balances := new(rpctypes.BalancesResponse)
+		if err := s.ps.Balances(nil, req, balances); err != nil {
+			return nil, err
+		}
+
+		// swap updates do not always change our balances
+		bz, err := vjson.MarshalStruct(balances)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(bz, last) {
+			return balances, nil
+		}
+		last = bz
+
+		return balances, writeResponse(conn, balances)
+	}
+
+	balances, err := writeBalances()
+	if err != nil {
+		return err
+	}
+
+	unlockTicker := time.NewTicker(wsBalancesUnlockPollInterval)
+	defer unlockTicker.Stop()
+
+	for {
+		select {
+		case <-updateCh:
+		case <-unlockTicker.C:
+			if balances.BlocksToUnlock == 0 {
+				continue
+			}
+		case <-ctx.Done():
+			return nil
+		}
+
+		if balances, err = writeBalances(); err != nil {
+			return err
+		}
+	}
+}
+
 func (s *wsServer) writeSwapExitStatus(conn *websocket.Conn, id types.Hash) error {
 	info, err := s.sm.GetPastSwap(id)
 	if err != nil {
@@ -378,3 +693,33 @@ func writeError(conn *websocket.Conn, err error) error {
 
 	return conn.WriteJSON(resp)
 }
+
+// wsConnCounter counts the open websocket connections, limiting them to max if
+// max is non-zero.
+type wsConnCounter struct {
+	max   uint32
+	count atomic.Uint32
+}
+
+// tryAcquire increments the count, returning false without incrementing it if
+// the maximum has been reached.
+func (c *wsConnCounter) tryAcquire() bool {
+	for {
+		n := c.count.Load()
+		if c.max != 0 && n >= c.max {
+			return false
+		}
+		if c.count.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+func (c *wsConnCounter) release() {
+	c.count.Add(^uint32(0))
+}
+
+// current returns the number of open connections.
+func (c *wsConnCounter) current() uint32 {
+	return c.count.Load()
+}
@@ -5,17 +5,27 @@ package rpc
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/MarinX/monerorpc/wallet"
 	"github.com/cockroachdb/apd/v3"
+	"github.com/gorilla/websocket"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/athanorlabs/atomic-swap/coins"
+	"github.com/athanorlabs/atomic-swap/common"
+	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 	"github.com/athanorlabs/atomic-swap/common/types"
+	"github.com/athanorlabs/atomic-swap/common/vjson"
+	mcrypto "github.com/athanorlabs/atomic-swap/crypto/monero"
+	"github.com/athanorlabs/atomic-swap/protocol/swap"
 	"github.com/athanorlabs/atomic-swap/rpcclient/wsclient"
 )
 
@@ -26,17 +36,30 @@ var (
 )
 
 func newServer(t *testing.T) *Server {
+	return newServerWithBackend(t, newMockProtocolBackend())
+}
+
+func newServerWithBackend(t *testing.T, backend *mockProtocolBackend) *Server {
+	return newServerWithConfig(t, backend, nil)
+}
+
+// newServerWithConfig starts a server, calling setConfig (if not nil) to modify
+// the default test configuration first.
+func newServerWithConfig(t *testing.T, backend *mockProtocolBackend, setConfig func(cfg *Config)) *Server {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	cfg := &Config{
 		Ctx:             ctx,
 		Address:         "127.0.0.1:0", // OS assigned port
 		Net:             new(mockNet),
-		ProtocolBackend: newMockProtocolBackend(),
+		ProtocolBackend: backend,
 		XMRTaker:        new(mockXMRTaker),
 		XMRMaker:        new(mockXMRMaker),
 		Namespaces:      AllNamespaces(),
 	}
+	if setConfig != nil {
+		setConfig(cfg)
+	}
 
 	s, err := NewServer(cfg)
 	require.NoError(t, err)
@@ -78,6 +101,264 @@ func TestSubscribeSwapStatus(t *testing.T) {
 	}
 }
 
+func TestSubscribeAllSwapStatus(t *testing.T) {
+	backend := newMockProtocolBackend()
+	s := newServerWithBackend(t, backend)
+
+	c, err := wsclient.NewWsClient(s.ctx, s.WsURL())
+	require.NoError(t, err)
+	defer c.Close()
+
+	ch, err := c.SubscribeAllSwapStatus()
+	require.NoError(t, err)
+
+	// wait for the server to register its status listener
+	info := &swap.Info{OfferID: testSwapID, Status: types.ExpectingKeys}
+	require.Eventually(t, func() bool { return backend.sm.notify(info) }, testTimeout, 10*time.Millisecond)
+
+	// duplicate statuses are not sent to the subscriber
+	otherSwapID := types.Hash{100}
+	backend.sm.notify(&swap.Info{OfferID: testSwapID, Status: types.ExpectingKeys})
+	backend.sm.notify(&swap.Info{OfferID: otherSwapID, Status: types.KeysExchanged})
+	backend.sm.notify(&swap.Info{OfferID: testSwapID, Status: types.CompletedSuccess})
+
+	expected := []*rpctypes.SwapStatusEvent{
+		{OfferID: testSwapID, Status: types.ExpectingKeys},
+		{OfferID: otherSwapID, Status: types.KeysExchanged},
+		{OfferID: testSwapID, Status: types.CompletedSuccess},
+	}
+	for _, exp := range expected {
+		select {
+		case event := <-ch:
+			require.Equal(t, exp, event)
+		case <-time.After(testTimeout):
+			t.Fatal("test timed out")
+		}
+	}
+}
+
+func TestSubscribeAllSwapStatus_compression(t *testing.T) {
+	for _, clientCompression := range []bool{true, false} {
+		clientCompression := clientCompression
+		name := fmt.Sprintf("clientCompression=%t", clientCompression)
+		t.Run(name, func(t *testing.T) {
+			testSubscribeAllSwapStatusCompression(t, clientCompression)
+		})
+	}
+}
+
+func testSubscribeAllSwapStatusCompression(t *testing.T, clientCompression bool) {
+	backend := newMockProtocolBackend()
+	s := newServerWithConfig(t, backend, func(cfg *Config) { cfg.WsCompression = true })
+
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = clientCompression
+	conn, resp, err := dialer.DialContext(s.ctx, s.WsURL(), nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	defer func() { _ = conn.Close() }()
+
+	// compression is only used if the client offers it
+	extensions := resp.Header.Get("Sec-WebSocket-Extensions")
+	require.Equal(t, clientCompression, strings.Contains(extensions, "permessage-deflate"))
+
+	err = conn.WriteJSON(&rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeAllStatus,
+	})
+	require.NoError(t, err)
+
+	info := &swap.Info{OfferID: testSwapID, Status: types.ExpectingKeys}
+	require.Eventually(t, func() bool { return backend.sm.notify(info) }, testTimeout, 10*time.Millisecond)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(testTimeout)))
+	_, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	res := new(rpctypes.Response)
+	require.NoError(t, vjson.UnmarshalStruct(message, res))
+	require.Nil(t, res.Error)
+
+	event := new(rpctypes.SwapStatusEvent)
+	require.NoError(t, vjson.UnmarshalStruct(res.Result, event))
+	require.Equal(t, &rpctypes.SwapStatusEvent{OfferID: testSwapID, Status: types.ExpectingKeys}, event)
+}
+
+func TestSubscribeOffersTaken(t *testing.T) {
+	xmrmaker := new(mockXMRMaker)
+	s := newServerWithConfig(t, newMockProtocolBackend(), func(cfg *Config) { cfg.XMRMaker = xmrmaker })
+
+	c, err := wsclient.NewWsClient(s.ctx, s.WsURL())
+	require.NoError(t, err)
+	defer c.Close()
+
+	ch, err := c.SubscribeOffersTaken()
+	require.NoError(t, err)
+
+	event := &types.OfferTakenEvent{
+		OfferID:        testSwapID,
+		PeerID:         peer.ID("taker"),
+		EthAsset:       types.EthAssetETH,
+		ProvidedAmount: apd.New(5, -1),
+		XMRAmount:      apd.New(10, 0),
+		Time:           time.Now().Round(0),
+	}
+
+	// wait for the server to register its listener
+	require.Eventually(t, func() bool { return xmrmaker.notifyOfferTaken(event) }, testTimeout, 10*time.Millisecond)
+
+	select {
+	case received, ok := <-ch:
+		require.True(t, ok)
+		require.Equal(t, event.OfferID, received.OfferID)
+		require.Equal(t, event.PeerID, received.PeerID)
+		require.Equal(t, event.EthAsset, received.EthAsset)
+		require.Equal(t, event.ProvidedAmount.String(), received.ProvidedAmount.String())
+		require.Equal(t, event.XMRAmount.String(), received.XMRAmount.String())
+		require.True(t, event.Time.Equal(received.Time))
+	case <-time.After(testTimeout):
+		t.Fatal("test timed out")
+	}
+}
+
+// balancesXMRMaker is a mockXMRMaker with a Monero balance that can be changed
+// by the test.
+type balancesXMRMaker struct {
+	mockXMRMaker
+	address   *mcrypto.Address
+	piconeros atomic.Uint64
+}
+
+func (m *balancesXMRMaker) GetMoneroBalance() (*mcrypto.Address, *wallet.GetBalanceResponse, error) {
+	balance := m.piconeros.Load()
+	return m.address, &wallet.GetBalanceResponse{Balance: balance, UnlockedBalance: balance}, nil
+}
+
+func TestSubscribeBalances(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+	xmrmaker := &balancesXMRMaker{address: kp.PublicKeyPair().Address(common.Development)}
+	xmrmaker.piconeros.Store(1)
+
+	backend := newMockProtocolBackend()
+	s := newServerWithConfig(t, backend, func(cfg *Config) { cfg.XMRMaker = xmrmaker })
+
+	c, err := wsclient.NewWsClient(s.ctx, s.WsURL())
+	require.NoError(t, err)
+	defer c.Close()
+
+	ch, err := c.SubscribeBalances(nil)
+	require.NoError(t, err)
+
+	nextPiconeros := func() string {
+		select {
+		case balances := <-ch:
+			return balances.PiconeroBalance.String()
+		case <-time.After(testTimeout):
+			t.Fatal("test timed out")
+			return ""
+		}
+	}
+
+	// the current balances are sent first
+	require.Equal(t, "1", nextPiconeros())
+
+	// wait for the server to register its status listener
+	info := &swap.Info{OfferID: testSwapID, Status: types.CompletedSuccess}
+	require.Eventually(t, func() bool { return backend.sm.notify(info) }, testTimeout, 10*time.Millisecond)
+
+	xmrmaker.piconeros.Store(2)
+	backend.sm.notify(info)
+	require.Equal(t, "2", nextPiconeros())
+
+	// swap updates that do not change the balances are not sent
+	backend.sm.notify(info)
+	xmrmaker.piconeros.Store(3)
+	backend.sm.notify(info)
+	require.Equal(t, "3", nextPiconeros())
+}
+
+func TestWsServer_maxConnections(t *testing.T) {
+	const maxConns = 2
+	s := newServerWithConfig(t, newMockProtocolBackend(), func(cfg *Config) { cfg.MaxWsConnections = maxConns })
+
+	for i := 0; i < maxConns; i++ {
+		c, err := wsclient.NewWsClient(s.ctx, s.WsURL())
+		require.NoError(t, err)
+		defer c.Close()
+	}
+
+	// the connection past the limit is closed with a reason
+	conn, resp, err := websocket.DefaultDialer.DialContext(s.ctx, s.WsURL(), nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	defer func() { _ = conn.Close() }()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(testTimeout)))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	require.Equal(t, websocket.CloseTryAgainLater, closeErr.Code)
+	require.Equal(t, errTooManyWsConns.Error(), closeErr.Text)
+}
+
+func TestWsServer_subscriberDisconnects(t *testing.T) {
+	backend := newMockProtocolBackend()
+	s := newServerWithConfig(t, backend, func(cfg *Config) { cfg.MaxWsConnections = 1 })
+
+	c, err := wsclient.NewWsClient(s.ctx, s.WsURL())
+	require.NoError(t, err)
+
+	_, err = c.SubscribeAllSwapStatus()
+	require.NoError(t, err)
+
+	// wait for the server to register its status listener
+	info := &swap.Info{OfferID: testSwapID, Status: types.ExpectingKeys}
+	require.Eventually(t, func() bool { return backend.sm.notify(info) }, testTimeout, 10*time.Millisecond)
+
+	// the subscription ends when the idle subscriber disconnects, without
+	// waiting for a status change to write
+	c.Close()
+	require.Eventually(t, func() bool { return !backend.sm.notify(info) }, testTimeout, 10*time.Millisecond)
+
+	// which releases its connection slot
+	c, err = wsclient.NewWsClient(s.ctx, s.WsURL())
+	require.NoError(t, err)
+	defer c.Close()
+
+	ch, err := c.SubscribeAllSwapStatus()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return backend.sm.notify(info) }, testTimeout, 10*time.Millisecond)
+
+	select {
+	case event := <-ch:
+		require.Equal(t, &rpctypes.SwapStatusEvent{OfferID: testSwapID, Status: types.ExpectingKeys}, event)
+	case <-time.After(testTimeout):
+		t.Fatal("test timed out")
+	}
+}
+
+func TestDaemonService_Health_wsConnections(t *testing.T) {
+	conns := &wsConnCounter{max: 1}
+	require.True(t, conns.tryAcquire())
+	require.False(t, conns.tryAcquire())
+
+	ds := NewDaemonService(nil, newMockProtocolBackend())
+	ds.wsConns = conns
+	resp := new(HealthResponse)
+	require.NoError(t, ds.Health(nil, nil, resp))
+	require.Equal(t, &WsConnectionsHealth{Count: 1, Max: 1}, resp.WsConnections)
+	require.True(t, resp.IsHealthy)
+	require.True(t, resp.MoneroWalletReachable)
+	require.True(t, resp.EthereumSynced)
+	require.Zero(t, resp.NumOngoingSwaps)
+	require.Empty(t, resp.Errors)
+
+	// a released connection frees up space for a new one
+	conns.release()
+	require.True(t, conns.tryAcquire())
+}
+
 func TestSubscribeMakeOffer(t *testing.T) {
 	s := newServer(t)
 
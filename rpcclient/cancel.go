@@ -4,12 +4,14 @@
 package rpcclient
 
 import (
+	"time"
+
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/rpc"
 )
 
 // Cancel calls swap_cancel.
-func (c *Client) Cancel(offerID types.Hash) (types.Status, error) {
+func (c *Client) Cancel(offerID types.Hash) (*rpc.CancelResponse, error) {
 	const (
 		method = "swap_cancel"
 	)
@@ -20,8 +22,42 @@ func (c *Client) Cancel(offerID types.Hash) (types.Status, error) {
 	res := &rpc.CancelResponse{}
 
 	if err := c.Post(method, req, res); err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CancelAll calls swap_cancelAll.
+func (c *Client) CancelAll() (*rpc.CancelAllResponse, error) {
+	const (
+		method = "swap_cancelAll"
+	)
+
+	res := &rpc.CancelAllResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CancelByStatus calls swap_cancelByStatus.
+func (c *Client) CancelByStatus(status types.Status, olderThan time.Duration) (*rpc.CancelByStatusResponse, error) {
+	const (
+		method = "swap_cancelByStatus"
+	)
+
+	req := &rpc.CancelByStatusRequest{
+		Status:    status,
+		OlderThan: uint64(olderThan.Seconds()),
+	}
+	res := &rpc.CancelByStatusResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
 	}
 
-	return res.Status, nil
+	return res, nil
 }
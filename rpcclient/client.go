@@ -8,6 +8,7 @@ package rpcclient
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -16,6 +17,10 @@ import (
 	"github.com/gorilla/rpc/v2/json2"
 )
 
+// ErrUnauthorized is returned when swapd rejects a request because the client's
+// auth token is missing or wrong.
+var ErrUnauthorized = errors.New("unauthorized, check the auth token")
+
 var (
 	contentTypeJSON   = "application/json"
 	dialTimeout       = 60 * time.Second
@@ -35,20 +40,50 @@ var (
 
 // Client primarily exists to be a JSON-RPC client to swapd instances, but it can be used
 // to POST JSON-RPC requests to any JSON-RPC server. Its current use case assumes swapd is
-// running on the local host of a single use system. TLS and authentication are not
-// currently supported.
+// running on the local host of a single use system, or behind a TLS terminating reverse
+// proxy. If swapd requires an auth token, set it with SetAuthToken.
 type Client struct {
-	ctx      context.Context
-	endpoint string
+	ctx        context.Context
+	endpoint   string
+	httpClient *http.Client
+	authToken  string
 }
 
-// NewClient creates a new JSON-RPC client for the specified endpoint. The passed context
-// is used for the full lifetime of the client.
-func NewClient(ctx context.Context, endpoint string) *Client {
-	return &Client{
-		ctx:      ctx,
-		endpoint: endpoint,
+// NewClient creates a new JSON-RPC client for the specified endpoint. If caCertPath is
+// not empty, the PEM encoded certificates in the file are trusted when verifying an
+// https:// endpoint, in addition to the system's root certificates. An error is
+// returned if the file cannot be loaded. The passed context is used for the full
+// lifetime of the client.
+func NewClient(ctx context.Context, endpoint string, caCertPath string) (*Client, error) {
+	c := &Client{
+		ctx:        ctx,
+		endpoint:   endpoint,
+		httpClient: httpClient,
+	}
+
+	if caCertPath == "" {
+		return c, nil
+	}
+
+	tlsConf, err := NewTLSConfig(caCertPath)
+	if err != nil {
+		return nil, err
 	}
+
+	tlsTransport := transport.Clone()
+	tlsTransport.TLSClientConfig = tlsConf
+	c.httpClient = &http.Client{
+		Transport: tlsTransport,
+		Timeout:   httpClientTimeout,
+	}
+
+	return c, nil
+}
+
+// SetAuthToken sets the token that the client sends as a bearer token in the
+// Authorization header of its requests. An empty token sends no header.
+func (c *Client) SetAuthToken(token string) {
+	c.authToken = token
 }
 
 // Post makes a JSON-RPC call to the client's endpoint, serializing any passed request
@@ -66,18 +101,25 @@ func (c *Client) Post(method string, request any, response any) error {
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", contentTypeJSON)
+	if c.authToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
 
 	ctx, cancel := context.WithTimeout(c.ctx, callTimeout)
 	defer cancel()
 	httpReq = httpReq.WithContext(ctx)
 
-	httpResp, err := httpClient.Do(httpReq)
+	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("failed to post %q request: %w", method, err)
 	}
 
 	defer func() { _ = httpResp.Body.Close() }()
 
+	if httpResp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%q request rejected: %w", method, ErrUnauthorized)
+	}
+
 	if response == nil {
 		return nil
 	}
@@ -1,6 +1,9 @@
 package rpcclient
 
 import (
+	"math"
+	"time"
+
 	"github.com/athanorlabs/atomic-swap/rpc"
 )
 
@@ -15,6 +18,25 @@ func (c *Client) Shutdown() error {
 	return nil
 }
 
+// DrainAndShutdown stops swapd from starting new swaps and publishing offers,
+// waits up to the timeout for its ongoing swaps to complete, then shuts it
+// down. A zero timeout uses swapd's default. The timeout is rounded up to whole
+// seconds and must not be more than rpc.MaxDrainTimeout.
+func (c *Client) DrainAndShutdown(timeout time.Duration) (*rpc.ShutdownResponse, error) {
+	const (
+		method = "daemon_shutdown"
+	)
+	req := &rpc.ShutdownRequest{
+		Drain:   true,
+		Timeout: uint64(math.Ceil(timeout.Seconds())),
+	}
+	resp := &rpc.ShutdownResponse{}
+	if err := c.Post(method, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // Version returns version & misc info about swapd and its dependencies
 func (c *Client) Version() (*rpc.VersionResponse, error) {
 	const (
@@ -26,3 +48,53 @@ func (c *Client) Version() (*rpc.VersionResponse, error) {
 	}
 	return resp, nil
 }
+
+// Forwarder returns the trusted forwarder used by swapd's SwapCreator contract
+// and whether it passed verification
+func (c *Client) Forwarder() (*rpc.ForwarderResponse, error) {
+	const (
+		method = "daemon_forwarder"
+	)
+	resp := &rpc.ForwarderResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Health returns the health of swapd and its dependencies
+func (c *Client) Health() (*rpc.HealthResponse, error) {
+	const (
+		method = "daemon_health"
+	)
+	resp := &rpc.HealthResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EthSyncStatus returns how far the Ethereum node used by swapd is synced
+func (c *Client) EthSyncStatus() (*rpc.EthSyncStatusResponse, error) {
+	const (
+		method = "daemon_ethSyncStatus"
+	)
+	resp := &rpc.EthSyncStatusResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RelayerStats returns the totals of the claims that swapd relayed since it
+// started
+func (c *Client) RelayerStats() (*rpc.RelayerStatsResponse, error) {
+	const (
+		method = "daemon_relayerStats"
+	)
+	resp := &rpc.RelayerStatsResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
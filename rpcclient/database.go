@@ -45,3 +45,21 @@ func (c *Client) GetSwapSecret(offerID types.Hash) (*rpc.GetSwapSecretResponse,
 
 	return res, nil
 }
+
+// VerifyDLEqProof calls database_verifyDLEqProof.
+func (c *Client) VerifyDLEqProof(offerID types.Hash) (*rpc.VerifyDLEqProofResponse, error) {
+	const (
+		method = "database_verifyDLEqProof"
+	)
+
+	req := &rpc.VerifyDLEqProofRequest{
+		OfferID: offerID,
+	}
+
+	res := &rpc.VerifyDLEqProofResponse{}
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
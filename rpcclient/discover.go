@@ -47,3 +47,39 @@ func (c *Client) QueryAll(provides coins.ProvidesCoin, searchTime uint64) ([]*rp
 
 	return res.PeersWithOffers, nil
 }
+
+// QueryRelayers calls net_queryRelayers.
+func (c *Client) QueryRelayers(searchTime uint64) ([]*rpctypes.RelayerWithFee, error) {
+	const (
+		method = "net_queryRelayers"
+	)
+
+	req := &rpctypes.QueryRelayersRequest{
+		SearchTime: searchTime,
+	}
+	res := &rpctypes.QueryRelayersResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res.Relayers, nil
+}
+
+// RelayerStatus calls net_relayerStatus.
+func (c *Client) RelayerStatus(searchTime uint64) (*rpctypes.RelayerStatusResponse, error) {
+	const (
+		method = "net_relayerStatus"
+	)
+
+	req := &rpctypes.RelayerStatusRequest{
+		SearchTime: searchTime,
+	}
+	res := &rpctypes.RelayerStatusResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
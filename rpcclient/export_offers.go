@@ -0,0 +1,23 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"github.com/athanorlabs/atomic-swap/rpc"
+)
+
+// ExportOffers calls swap_exportOffers.
+func (c *Client) ExportOffers() (*rpc.ExportOffersResponse, error) {
+	const (
+		method = "swap_exportOffers"
+	)
+
+	resp := &rpc.ExportOffersResponse{}
+
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
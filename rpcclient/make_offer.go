@@ -18,18 +18,92 @@ func (c *Client) MakeOffer(
 	ethAsset types.EthAsset,
 	useRelayer bool,
 ) (*rpctypes.MakeOfferResponse, error) {
+	return c.MakeOfferRequest(&rpctypes.MakeOfferRequest{
+		MinAmount:    min,
+		MaxAmount:    max,
+		ExchangeRate: exchangeRate,
+		EthAsset:     ethAsset,
+		UseRelayer:   useRelayer,
+	})
+}
+
+// MakeOfferRequest calls net_makeOffer with the full request, so that optional
+// fields like the offer expiry can be set.
+func (c *Client) MakeOfferRequest(req *rpctypes.MakeOfferRequest) (*rpctypes.MakeOfferResponse, error) {
 	const (
 		method = "net_makeOffer"
 	)
 
-	req := &rpctypes.MakeOfferRequest{
+	res := &rpctypes.MakeOfferResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Readvertise calls net_readvertise.
+func (c *Client) Readvertise() (*rpctypes.ReadvertiseResponse, error) {
+	const (
+		method = "net_readvertise"
+	)
+
+	res := &rpctypes.ReadvertiseResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ValidateOffer calls net_validateOffer.
+func (c *Client) ValidateOffer(
+	min, max *apd.Decimal,
+	exchangeRate *coins.ExchangeRate,
+	ethAsset types.EthAsset,
+	useRelayer bool,
+) (*rpctypes.ValidateOfferResponse, error) {
+	const (
+		method = "net_validateOffer"
+	)
+
+	return c.ValidateOfferRequest(&rpctypes.MakeOfferRequest{
 		MinAmount:    min,
 		MaxAmount:    max,
 		ExchangeRate: exchangeRate,
 		EthAsset:     ethAsset,
 		UseRelayer:   useRelayer,
+	})
+}
+
+// ValidateOfferRequest calls net_validateOffer with the full request, so that
+// the optional fields are validated too.
+func (c *Client) ValidateOfferRequest(req *rpctypes.MakeOfferRequest) (*rpctypes.ValidateOfferResponse, error) {
+	const (
+		method = "net_validateOffer"
+	)
+
+	res := &rpctypes.ValidateOfferResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
 	}
-	res := &rpctypes.MakeOfferResponse{}
+
+	return res, nil
+}
+
+// GetOfferDefaults calls net_getOfferDefaults.
+func (c *Client) GetOfferDefaults(ethAsset types.EthAsset) (*rpctypes.GetOfferDefaultsResponse, error) {
+	const (
+		method = "net_getOfferDefaults"
+	)
+
+	req := &rpctypes.GetOfferDefaultsRequest{
+		EthAsset: ethAsset,
+	}
+	res := &rpctypes.GetOfferDefaultsResponse{}
 
 	if err := c.Post(method, req, res); err != nil {
 		return nil, err
@@ -4,6 +4,8 @@
 package rpcclient
 
 import (
+	"github.com/libp2p/go-libp2p/core/peer"
+
 	"github.com/athanorlabs/atomic-swap/common/rpctypes"
 )
 
@@ -21,3 +23,65 @@ func (c *Client) Peers() (*rpctypes.PeersResponse, error) {
 
 	return res, nil
 }
+
+// GetProtocolStreams calls net_getProtocolStreams to get the open swap protocol
+// streams of a swapd instance.
+func (c *Client) GetProtocolStreams() (*rpctypes.GetProtocolStreamsResponse, error) {
+	const (
+		method = "net_getProtocolStreams"
+	)
+
+	res := &rpctypes.GetProtocolStreamsResponse{}
+
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// PeerStats calls net_peerStats to get the outcomes of the completed swaps with
+// the given peer, or with all peers if peerID is empty.
+func (c *Client) PeerStats(peerID peer.ID) (*rpctypes.PeerStatsResponse, error) {
+	const (
+		method = "net_peerStats"
+	)
+
+	req := &rpctypes.PeerStatsRequest{
+		PeerID: peerID,
+	}
+
+	res := &rpctypes.PeerStatsResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// BlockPeer calls net_blockPeer to add the peer to the blocklist.
+func (c *Client) BlockPeer(peerID peer.ID) error {
+	const (
+		method = "net_blockPeer"
+	)
+
+	req := &rpctypes.BlockPeerRequest{
+		PeerID: peerID,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// UnblockPeer calls net_unblockPeer to remove the peer from the blocklist.
+func (c *Client) UnblockPeer(peerID peer.ID) error {
+	const (
+		method = "net_unblockPeer"
+	)
+
+	req := &rpctypes.BlockPeerRequest{
+		PeerID: peerID,
+	}
+
+	return c.Post(method, req, nil)
+}
@@ -11,14 +11,16 @@ import (
 	"github.com/athanorlabs/atomic-swap/rpc"
 )
 
-// SetSwapTimeout calls personal_setSwapTimeout.
-func (c *Client) SetSwapTimeout(timeoutSeconds uint64) error {
+// SetSwapTimeout calls personal_setSwapTimeout. A zero duration is left
+// unchanged.
+func (c *Client) SetSwapTimeout(t0Seconds uint64, t1Seconds uint64) error {
 	const (
 		method = "personal_setSwapTimeout"
 	)
 
 	req := &rpc.SetSwapTimeoutRequest{
-		Timeout: timeoutSeconds,
+		T0Duration: t0Seconds,
+		T1Duration: t1Seconds,
 	}
 
 	if err := c.Post(method, req, nil); err != nil {
@@ -72,3 +74,17 @@ func (c *Client) Balances(request *rpctypes.BalancesRequest) (*rpctypes.Balances
 
 	return balances, nil
 }
+
+// MoneroSyncStatus calls personal_moneroSyncStatus.
+func (c *Client) MoneroSyncStatus() (*rpc.MoneroSyncStatusResponse, error) {
+	const (
+		method = "personal_moneroSyncStatus"
+	)
+
+	resp := &rpc.MoneroSyncStatusResponse{}
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -26,3 +26,21 @@ func (c *Client) Query(who peer.ID) (*rpctypes.QueryPeerResponse, error) {
 
 	return res, nil
 }
+
+// QueryAddr calls net_queryPeerAddr.
+func (c *Client) QueryAddr(multiaddr string) (*rpctypes.QueryPeerResponse, error) {
+	const (
+		method = "net_queryPeerAddr"
+	)
+
+	req := &rpctypes.QueryPeerAddrRequest{
+		Multiaddr: multiaddr,
+	}
+	res := &rpctypes.QueryPeerResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
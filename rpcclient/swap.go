@@ -6,6 +6,10 @@ package rpcclient
 import (
 	"fmt"
 
+	"github.com/cockroachdb/apd/v3"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/athanorlabs/atomic-swap/coins"
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/rpc"
 )
@@ -30,15 +34,37 @@ func (c *Client) GetOngoingSwap(id *types.Hash) (*rpc.GetOngoingResponse, error)
 
 // GetPastSwap calls swap_getPast
 func (c *Client) GetPastSwap(id *types.Hash) (*rpc.GetPastResponse, error) {
+	return c.GetPastSwaps(&rpc.GetPastRequest{
+		OfferID: id,
+	})
+}
+
+// GetPastSwaps calls swap_getPast with the given request, which can filter the
+// returned swaps by status and start time.
+func (c *Client) GetPastSwaps(req *rpc.GetPastRequest) (*rpc.GetPastResponse, error) {
 	const (
 		method = "swap_getPast"
 	)
 
-	req := &rpc.GetPastRequest{
-		OfferID: id,
+	res := &rpc.GetPastResponse{}
+
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
 	}
 
-	res := &rpc.GetPastResponse{}
+	return res, nil
+}
+
+// Export calls swap_export
+func (c *Client) Export(id types.Hash) (*rpc.ExportResponse, error) {
+	const (
+		method = "swap_export"
+	)
+
+	req := &rpc.ExportRequest{
+		OfferID: id,
+	}
+	res := &rpc.ExportResponse{}
 
 	if err := c.Post(method, req, res); err != nil {
 		return nil, err
@@ -82,6 +108,96 @@ func (c *Client) ClearOffers(offerIDs []types.Hash) error {
 	return nil
 }
 
+// GetRejectedTakes calls swap_getRejectedTakes
+func (c *Client) GetRejectedTakes() (*rpc.GetRejectedTakesResponse, error) {
+	const (
+		method = "swap_getRejectedTakes"
+	)
+
+	res := &rpc.GetRejectedTakesResponse{}
+	if err := c.Post(method, nil, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ForceComplete calls swap_forceComplete
+func (c *Client) ForceComplete(offerID types.Hash) error {
+	const (
+		method = "swap_forceComplete"
+	)
+
+	req := &rpc.ForceCompleteRequest{
+		OfferID: offerID,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// RetrySweep calls swap_retrySweep
+func (c *Client) RetrySweep(offerID types.Hash) error {
+	const (
+		method = "swap_retrySweep"
+	)
+
+	req := &rpc.RetrySweepRequest{
+		OfferID: offerID,
+	}
+
+	return c.Post(method, req, nil)
+}
+
+// SweepSharedWallet calls swap_sweepSharedWallet
+func (c *Client) SweepSharedWallet(offerID types.Hash) (*rpc.SweepSharedWalletResponse, error) {
+	const (
+		method = "swap_sweepSharedWallet"
+	)
+
+	req := &rpc.SweepSharedWalletRequest{
+		OfferID: offerID,
+	}
+
+	res := &rpc.SweepSharedWalletResponse{}
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Verify calls swap_verify
+func (c *Client) Verify(offerID types.Hash) (*rpc.VerifyResponse, error) {
+	const (
+		method = "swap_verify"
+	)
+
+	req := &rpc.VerifyRequest{
+		OfferID: offerID,
+	}
+
+	res := &rpc.VerifyResponse{}
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetGasPriceOverride calls swap_setGasPriceOverride
+func (c *Client) SetGasPriceOverride(offerID types.Hash, gasPriceGwei uint64) error {
+	const (
+		method = "swap_setGasPriceOverride"
+	)
+
+	req := &rpc.SetGasPriceOverrideRequest{
+		OfferID:  offerID,
+		GasPrice: gasPriceGwei,
+	}
+
+	return c.Post(method, req, nil)
+}
+
 // Claim calls swap_claim
 func (c *Client) Claim(offerID types.Hash) (*rpc.ManualTransactionResponse, error) {
 	const (
@@ -121,15 +237,120 @@ func (c *Client) Refund(offerID types.Hash) (*rpc.ManualTransactionResponse, err
 }
 
 // SuggestedExchangeRate calls swap_suggestedExchangeRate
-func (c *Client) SuggestedExchangeRate() (*rpc.SuggestedExchangeRateResponse, error) {
+func (c *Client) SuggestedExchangeRate(forceRefresh bool) (*rpc.SuggestedExchangeRateResponse, error) {
 	const (
 		method = "swap_suggestedExchangeRate"
 	)
 
+	req := &rpc.SuggestedExchangeRateRequest{
+		ForceRefresh: forceRefresh,
+	}
+
 	res := &rpc.SuggestedExchangeRateResponse{}
-	if err := c.Post(method, nil, res); err != nil {
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RateHistory calls swap_rateHistory
+func (c *Client) RateHistory(req *rpc.RateHistoryRequest) (*rpc.RateHistoryResponse, error) {
+	const (
+		method = "swap_rateHistory"
+	)
+
+	res := &rpc.RateHistoryResponse{}
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Orderbook calls swap_orderbook
+func (c *Client) Orderbook(searchTime uint64) (*rpc.OrderbookResponse, error) {
+	const (
+		method = "swap_orderbook"
+	)
+
+	req := &rpc.OrderbookRequest{
+		SearchTime: searchTime,
+	}
+	res := &rpc.OrderbookResponse{}
+	if err := c.Post(method, req, res); err != nil {
 		return nil, err
 	}
 
 	return res, nil
 }
+
+// EstimateGas calls swap_estimateGas
+func (c *Client) EstimateGas(ethAsset types.EthAsset) (*rpc.EstimateGasResponse, error) {
+	const (
+		method = "swap_estimateGas"
+	)
+
+	req := &rpc.EstimateGasRequest{
+		EthAsset: ethAsset,
+	}
+
+	res := &rpc.EstimateGasResponse{}
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// EstimateTake calls swap_estimateTake
+func (c *Client) EstimateTake(
+	peerID peer.ID,
+	offerID types.Hash,
+	providesAmount *apd.Decimal,
+) (*rpc.EstimateTakeResponse, error) {
+	const (
+		method = "swap_estimateTake"
+	)
+
+	req := &rpc.EstimateTakeRequest{
+		PeerID:         peerID,
+		OfferID:        offerID,
+		ProvidesAmount: providesAmount,
+	}
+
+	res := &rpc.EstimateTakeResponse{}
+	if err := c.Post(method, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// TokenList calls swap_tokenList
+func (c *Client) TokenList() ([]*coins.ERC20TokenInfo, error) {
+	const (
+		method = "swap_tokenList"
+	)
+
+	resp := new(rpc.TokenListResponse)
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Tokens, nil
+}
+
+// CompletionTimes calls swap_completionTimes
+func (c *Client) CompletionTimes() (map[types.EthAsset]*rpc.AssetCompletionTimes, error) {
+	const (
+		method = "swap_completionTimes"
+	)
+
+	resp := new(rpc.CompletionTimesResponse)
+	if err := c.Post(method, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Assets, nil
+}
@@ -13,15 +13,19 @@ import (
 
 // TakeOffer calls net_takeOffer.
 func (c *Client) TakeOffer(peerID peer.ID, offerID types.Hash, providesAmount *apd.Decimal) error {
-	const (
-		method = "net_takeOffer"
-	)
-
-	req := &rpctypes.TakeOfferRequest{
+	return c.TakeOfferRequest(&rpctypes.TakeOfferRequest{
 		PeerID:         peerID,
 		OfferID:        offerID,
 		ProvidesAmount: providesAmount,
-	}
+	})
+}
+
+// TakeOfferRequest calls net_takeOffer with the full request, so that optional
+// fields like the maximum exchange rate can be set.
+func (c *Client) TakeOfferRequest(req *rpctypes.TakeOfferRequest) error {
+	const (
+		method = "net_takeOffer"
+	)
 
 	if err := c.Post(method, req, nil); err != nil {
 		return err
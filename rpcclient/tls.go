@@ -0,0 +1,42 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// NewTLSConfig returns a TLS client configuration. If caCertPath is not empty, the
+// PEM encoded certificates in the file are trusted in addition to the system's root
+// certificates, which allows connecting to a swapd proxy using a self-signed cert.
+func NewTLSConfig(caCertPath string) (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caCertPath == "" {
+		return conf, nil
+	}
+
+	pemData, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		certPool = x509.NewCertPool()
+	}
+
+	if !certPool.AppendCertsFromPEM(pemData) {
+		return nil, errors.New("no valid PEM certificates found in CA certificate file")
+	}
+
+	conf.RootCAs = certPool
+	return conf, nil
+}
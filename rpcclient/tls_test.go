@@ -0,0 +1,85 @@
+// Copyright 2023 The AthanorLabs/atomic-swap Authors
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package rpcclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCACert writes a self-signed PEM encoded CA certificate to a file
+// and returns the file's path along with the certificate.
+func writeTestCACert(t *testing.T) (string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "swapd test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	certPath := path.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	require.NoError(t, os.WriteFile(certPath, pemData, 0600))
+	return certPath, cert
+}
+
+func TestNewTLSConfig_noCACert(t *testing.T) {
+	conf, err := NewTLSConfig("")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), conf.MinVersion)
+	require.Nil(t, conf.RootCAs) // the system's root certificates are used
+}
+
+func TestNewTLSConfig_validCACert(t *testing.T) {
+	certPath, cert := writeTestCACert(t)
+
+	conf, err := NewTLSConfig(certPath)
+	require.NoError(t, err)
+	require.NotNil(t, conf.RootCAs)
+
+	_, err = cert.Verify(x509.VerifyOptions{Roots: conf.RootCAs})
+	require.NoError(t, err)
+}
+
+func TestNewTLSConfig_badPath(t *testing.T) {
+	_, err := NewTLSConfig(path.Join(t.TempDir(), "missing.pem"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.ErrorContains(t, err, "failed to read CA certificate")
+}
+
+func TestNewTLSConfig_badPEM(t *testing.T) {
+	certPath := path.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(certPath, []byte("not a certificate"), 0600))
+
+	_, err := NewTLSConfig(certPath)
+	require.ErrorContains(t, err, "no valid PEM certificates found")
+}
+
+func TestNewClient_badCACert(t *testing.T) {
+	_, err := NewClient(context.Background(), "https://127.0.0.1:1", path.Join(t.TempDir(), "missing.pem"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
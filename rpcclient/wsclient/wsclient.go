@@ -7,7 +7,10 @@ package wsclient
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 
 	"github.com/cockroachdb/apd/v3"
@@ -18,6 +21,7 @@ import (
 	"github.com/athanorlabs/atomic-swap/common/types"
 	"github.com/athanorlabs/atomic-swap/common/vjson"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/websocket"
 	logging "github.com/ipfs/go-log"
 )
@@ -30,10 +34,17 @@ type WsClient interface {
 	Discover(provides string, searchTime uint64) ([]peer.ID, error)
 	Query(who peer.ID) (*rpctypes.QueryPeerResponse, error)
 	SubscribeSwapStatus(id types.Hash) (<-chan types.Status, error)
+	SubscribeAllSwapStatus() (<-chan *rpctypes.SwapStatusEvent, error)
+	SubscribeOffersTaken() (<-chan *types.OfferTakenEvent, error)
+	SubscribeBalances(tokenAddrs []ethcommon.Address) (<-chan *rpctypes.BalancesResponse, error)
 	TakeOfferAndSubscribe(peerID peer.ID, offerID types.Hash, providesAmount *apd.Decimal) (
 		ch <-chan types.Status,
 		err error,
 	)
+	TakeOfferRequestAndSubscribe(params *rpctypes.TakeOfferRequest) (
+		ch <-chan types.Status,
+		err error,
+	)
 	MakeOfferAndSubscribe(
 		min *apd.Decimal,
 		max *apd.Decimal,
@@ -41,6 +52,11 @@ type WsClient interface {
 		ethAsset types.EthAsset,
 		useRelayer bool,
 	) (*rpctypes.MakeOfferResponse, <-chan types.Status, error)
+	MakeOfferRequestAndSubscribe(params *rpctypes.MakeOfferRequest) (
+		*rpctypes.MakeOfferResponse,
+		<-chan types.Status,
+		error,
+	)
 }
 
 type wsClient struct {
@@ -51,8 +67,44 @@ type wsClient struct {
 
 // NewWsClient ...
 func NewWsClient(ctx context.Context, endpoint string) (*wsClient, error) { ///nolint:revive
-	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	return NewAuthWsClient(ctx, endpoint, nil, "")
+}
+
+// NewTLSWsClient creates a websocket client for a wss:// endpoint, verifying the server
+// using the passed TLS configuration.
+func NewTLSWsClient(ctx context.Context, endpoint string, tlsConf *tls.Config) (*wsClient, error) { ///nolint:revive
+	return NewAuthWsClient(ctx, endpoint, tlsConf, "")
+}
+
+// NewAuthWsClient creates a websocket client that presents the auth token as a bearer
+// token when connecting, unless it's empty. If tlsConf is not nil, it's used to verify
+// the server of a wss:// endpoint.
+func NewAuthWsClient( ///nolint:revive
+	ctx context.Context,
+	endpoint string,
+	tlsConf *tls.Config,
+	authToken string,
+) (*wsClient, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = tlsConf
+
+	var header http.Header
+	if authToken != "" {
+		header = http.Header{"Authorization": []string{"Bearer " + authToken}}
+	}
+
+	return dial(ctx, &dialer, endpoint, header)
+}
+
+// dial connects to the endpoint, offering per-message deflate compression,
+// which is used if the server has it enabled.
+func dial(ctx context.Context, dialer *websocket.Dialer, endpoint string, header http.Header) (*wsClient, error) {
+	dialer.EnableCompression = true
+	conn, resp, err := dialer.DialContext(ctx, endpoint, header)
 	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, errors.New("failed to dial WS endpoint: unauthorized, check the auth token")
+		}
 		return nil, fmt.Errorf("failed to dial WS endpoint: %w", err)
 	}
 
@@ -243,17 +295,189 @@ func (c *wsClient) SubscribeSwapStatus(id types.Hash) (<-chan types.Status, erro
 	return respCh, nil
 }
 
+// SubscribeAllSwapStatus returns a channel that receives the status of every ongoing
+// swap, followed by each status change of any swap. The channel is closed when the
+// connection is closed or lost.
+func (c *wsClient) SubscribeAllSwapStatus() (<-chan *rpctypes.SwapStatusEvent, error) {
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeAllStatus,
+		ID:      0,
+	}
+
+	if err := c.writeJSON(req); err != nil {
+		return nil, err
+	}
+
+	respCh := make(chan *rpctypes.SwapStatusEvent)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Debugf("failed to read websockets message: %s", err)
+				return
+			}
+
+			resp := new(rpctypes.Response)
+			err = vjson.UnmarshalStruct(message, resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				return
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				return
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			event := new(rpctypes.SwapStatusEvent)
+			if err := vjson.UnmarshalStruct(resp.Result, event); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				return
+			}
+
+			respCh <- event
+		}
+	}()
+
+	return respCh, nil
+}
+
+// SubscribeOffersTaken returns a channel that receives an event each time a take
+// of one of our offers is accepted. The channel is closed when the connection is
+// closed or lost.
+func (c *wsClient) SubscribeOffersTaken() (<-chan *types.OfferTakenEvent, error) {
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeOffersTaken,
+		ID:      0,
+	}
+
+	if err := c.writeJSON(req); err != nil {
+		return nil, err
+	}
+
+	respCh := make(chan *types.OfferTakenEvent)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Debugf("failed to read websockets message: %s", err)
+				return
+			}
+
+			resp := new(rpctypes.Response)
+			err = vjson.UnmarshalStruct(message, resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				return
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				return
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			event := new(types.OfferTakenEvent)
+			if err := vjson.UnmarshalStruct(resp.Result, event); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				return
+			}
+
+			respCh <- event
+		}
+	}()
+
+	return respCh, nil
+}
+
+// SubscribeBalances returns a channel that receives our balances, followed by
+// our balances each time they change. The balances of the given tokens are
+// included. The channel is closed when the connection is closed or lost.
+func (c *wsClient) SubscribeBalances(tokenAddrs []ethcommon.Address) (<-chan *rpctypes.BalancesResponse, error) {
+	params := &rpctypes.BalancesRequest{
+		TokenAddrs: tokenAddrs,
+	}
+
+	bz, err := vjson.MarshalStruct(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  rpctypes.SubscribeBalances,
+		Params:  bz,
+		ID:      0,
+	}
+
+	if err = c.writeJSON(req); err != nil {
+		return nil, err
+	}
+
+	respCh := make(chan *rpctypes.BalancesResponse)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Debugf("failed to read websockets message: %s", err)
+				return
+			}
+
+			resp := new(rpctypes.Response)
+			err = vjson.UnmarshalStruct(message, resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				return
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				return
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			balances := new(rpctypes.BalancesResponse)
+			if err := vjson.UnmarshalStruct(resp.Result, balances); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				return
+			}
+
+			respCh <- balances
+		}
+	}()
+
+	return respCh, nil
+}
+
 func (c *wsClient) TakeOfferAndSubscribe(
 	peerID peer.ID,
 	offerID types.Hash,
 	providesAmount *apd.Decimal,
 ) (ch <-chan types.Status, err error) {
-	params := &rpctypes.TakeOfferRequest{
+	return c.TakeOfferRequestAndSubscribe(&rpctypes.TakeOfferRequest{
 		PeerID:         peerID,
 		OfferID:        offerID,
 		ProvidesAmount: providesAmount,
-	}
+	})
+}
 
+// TakeOfferRequestAndSubscribe is like TakeOfferAndSubscribe, but takes the full
+// request, so that optional fields like the maximum exchange rate can be set.
+func (c *wsClient) TakeOfferRequestAndSubscribe(
+	params *rpctypes.TakeOfferRequest,
+) (ch <-chan types.Status, err error) {
 	bz, err := vjson.MarshalStruct(params)
 	if err != nil {
 		return nil, err
@@ -330,14 +554,20 @@ func (c *wsClient) MakeOfferAndSubscribe(
 	ethAsset types.EthAsset,
 	useRelayer bool,
 ) (*rpctypes.MakeOfferResponse, <-chan types.Status, error) {
-	params := &rpctypes.MakeOfferRequest{
+	return c.MakeOfferRequestAndSubscribe(&rpctypes.MakeOfferRequest{
 		MinAmount:    min,
 		MaxAmount:    max,
 		ExchangeRate: exchangeRate,
 		EthAsset:     ethAsset,
 		UseRelayer:   useRelayer,
-	}
+	})
+}
 
+// MakeOfferRequestAndSubscribe is like MakeOfferAndSubscribe, but takes the full
+// request, so that optional fields like the offer expiry can be set.
+func (c *wsClient) MakeOfferRequestAndSubscribe(
+	params *rpctypes.MakeOfferRequest,
+) (*rpctypes.MakeOfferResponse, <-chan types.Status, error) {
 	bz, err := vjson.MarshalStruct(params)
 	if err != nil {
 		return nil, nil, err
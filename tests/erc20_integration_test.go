@@ -50,7 +50,8 @@ func deployTestERC20(t *testing.T) ethcommon.Address {
 	MineTransaction(t, ec.Raw(), erc20Tx)
 
 	// Query Charlie's Ethereum address
-	charlieCli := rpcclient.NewClient(ctx, defaultCharlieSwapdEndpoint)
+	charlieCli, err := rpcclient.NewClient(ctx, defaultCharlieSwapdEndpoint, "")
+	require.NoError(t, err)
 	balResp, err := charlieCli.Balances(nil)
 	require.NoError(t, err)
 	charlieAddr := balResp.EthAddress
@@ -67,7 +68,8 @@ func deployTestERC20(t *testing.T) ethcommon.Address {
 	}
 
 	// verify that the XMR Taker has exactly 1000 tokens
-	aliceCli := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	aliceCli, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(t, err)
 	balResp, err = aliceCli.Balances(tokenBalReq)
 	require.NoError(t, err)
 	require.Equal(t, "1000", balResp.TokenBalances[0].AsStandardString())
@@ -79,10 +79,12 @@ func (s *IntegrationTestSuite) SetupTest() {
 	}
 
 	// Reset XMR Maker and Taker between tests, so tests starts in a known state
-	ac := rpcclient.NewClient(context.Background(), defaultXMRTakerSwapdEndpoint)
-	err := ac.SetSwapTimeout(defaultSwapTimeout)
+	ac, err := rpcclient.NewClient(context.Background(), defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
+	err = ac.SetSwapTimeout(defaultSwapTimeout, defaultSwapTimeout)
+	require.NoError(s.T(), err)
+	bc, err := rpcclient.NewClient(context.Background(), defaultXMRMakerSwapdEndpoint, "")
 	require.NoError(s.T(), err)
-	bc := rpcclient.NewClient(context.Background(), defaultXMRMakerSwapdEndpoint)
 	err = bc.ClearOffers(nil)
 	require.NoError(s.T(), err)
 }
@@ -94,7 +96,9 @@ func mineMinXMRMakerBalance(t *testing.T, minBalance *coins.PiconeroAmount) {
 	daemonCli := monerorpc.New(monero.MonerodRegtestEndpoint, nil).Daemon
 	ctx := context.Background()
 	for {
-		balances, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint).Balances(nil)
+		c, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+		require.NoError(t, err)
+		balances, err := c.Balances(nil)
 		require.NoError(t, err)
 		if balances.PiconeroUnlockedBalance.Cmp(minBalance) >= 0 {
 			break
@@ -121,14 +125,16 @@ func (s *IntegrationTestSuite) newSwapdWSClient(ctx context.Context, endpoint st
 
 func (s *IntegrationTestSuite) TestXMRTaker_Discover() {
 	ctx := context.Background()
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	_, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate, types.EthAssetETH, false)
 	require.NoError(s.T(), err)
 
 	// Give offer advertisement time to propagate
 	require.NoError(s.T(), common.SleepWithContext(ctx, time.Second))
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	peerIDs, err := ac.Discover(string(coins.ProvidesXMR), defaultDiscoverTimeout)
 	require.NoError(s.T(), err)
 	require.Equal(s.T(), 1, len(peerIDs))
@@ -136,7 +142,8 @@ func (s *IntegrationTestSuite) TestXMRTaker_Discover() {
 
 func (s *IntegrationTestSuite) TestXMRMaker_Discover() {
 	ctx := context.Background()
-	c := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	c, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	peerIDs, err := c.Discover(string(coins.ProvidesETH), defaultDiscoverTimeout)
 	require.NoError(s.T(), err)
 	require.Equal(s.T(), 0, len(peerIDs))
@@ -148,13 +155,15 @@ func (s *IntegrationTestSuite) TestXMRTaker_Query() {
 
 func (s *IntegrationTestSuite) testXMRTakerQuery(asset types.EthAsset) {
 	ctx := context.Background()
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	offerResp, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate, asset, false)
 	require.NoError(s.T(), err)
 
 	require.NoError(s.T(), common.SleepWithContext(ctx, time.Second)) // Give offer advertisement time to propagate
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	peerIDs, err := ac.Discover(string(coins.ProvidesXMR), defaultDiscoverTimeout)
 	require.NoError(s.T(), err)
 	require.Equal(s.T(), 1, len(peerIDs))
@@ -192,7 +201,8 @@ func (s *IntegrationTestSuite) testSuccessOneSwap(asset types.EthAsset, useRelay
 		exchangeRate, asset, useRelayer)
 	require.NoError(s.T(), err)
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	beforeResp, err := bc.GetOffers()
 	require.NoError(s.T(), err)
 
@@ -225,7 +235,8 @@ func (s *IntegrationTestSuite) testSuccessOneSwap(asset types.EthAsset, useRelay
 		}
 	}()
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	awsc := s.newSwapdWSClient(ctx, defaultXMRTakerSwapdWSEndpoint)
 
 	// Give offer advertisement time to propagate
@@ -286,7 +297,8 @@ func (s *IntegrationTestSuite) testRefundXMRTakerCancels(asset types.EthAsset) {
 		exchangeRate, asset, false)
 	require.NoError(s.T(), err)
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	beforeResp, err := bc.GetOffers()
 	require.NoError(s.T(), err)
 
@@ -321,10 +333,11 @@ func (s *IntegrationTestSuite) testRefundXMRTakerCancels(asset types.EthAsset) {
 		}
 	}()
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	awsc := s.newSwapdWSClient(ctx, defaultXMRTakerSwapdWSEndpoint)
 
-	err = ac.SetSwapTimeout(swapTimeout)
+	err = ac.SetSwapTimeout(swapTimeout, swapTimeout)
 	require.NoError(s.T(), err)
 
 	// Give offer advertisement time to propagate
@@ -348,7 +361,7 @@ func (s *IntegrationTestSuite) testRefundXMRTakerCancels(asset types.EthAsset) {
 			}
 
 			s.T().Log("> XMRTaker cancelling swap!")
-			exitStatus, err := ac.Cancel(offerResp.OfferID) //nolint:govet
+			cancelResp, err := ac.Cancel(offerResp.OfferID) //nolint:govet
 			if err != nil {
 				s.T().Log("XMRTaker got error", err)
 				if !strings.Contains(err.Error(), "revert it's the counterparty's turn, unable to refund") {
@@ -357,6 +370,7 @@ func (s *IntegrationTestSuite) testRefundXMRTakerCancels(asset types.EthAsset) {
 				return
 			}
 
+			exitStatus := cancelResp.Status
 			switch exitStatus {
 			case types.CompletedRefund:
 				// the desired outcome, do nothing
@@ -416,7 +430,8 @@ func (s *IntegrationTestSuite) testRefundXMRMakerCancels(
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	bwsc := s.newSwapdWSClient(ctx, defaultXMRMakerSwapdWSEndpoint)
 
 	offerResp, statusCh, err := bwsc.MakeOfferAndSubscribe(xmrmakerProvideAmount, xmrmakerProvideAmount,
@@ -443,12 +458,13 @@ func (s *IntegrationTestSuite) testRefundXMRMakerCancels(
 				}
 
 				s.T().Log("> XMRMaker cancelled swap!")
-				exitStatus, err := bc.Cancel(offerResp.OfferID) //nolint:govet
+				cancelResp, err := bc.Cancel(offerResp.OfferID) //nolint:govet
 				if err != nil {
 					errCh <- err
 					return
 				}
 
+				exitStatus := cancelResp.Status
 				if exitStatus != expectedExitStatus {
 					errCh <- fmt.Errorf("did not get expected exit status for XMRMaker: got %s, expected %s", exitStatus, expectedExitStatus) //nolint:lll
 					return
@@ -463,10 +479,11 @@ func (s *IntegrationTestSuite) testRefundXMRMakerCancels(
 		}
 	}()
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	awsc := s.newSwapdWSClient(ctx, defaultXMRTakerSwapdWSEndpoint)
 
-	err = ac.SetSwapTimeout(swapTimeout)
+	err = ac.SetSwapTimeout(swapTimeout, swapTimeout)
 	require.NoError(s.T(), err)
 
 	// Give offer advertisement time to propagate
@@ -534,7 +551,8 @@ func (s *IntegrationTestSuite) testAbortXMRTakerCancels(asset types.EthAsset) {
 		exchangeRate, asset, false)
 	require.NoError(s.T(), err)
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	beforeResp, err := bc.GetOffers()
 	require.NoError(s.T(), err)
 
@@ -566,7 +584,8 @@ func (s *IntegrationTestSuite) testAbortXMRTakerCancels(asset types.EthAsset) {
 		}
 	}()
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	awsc := s.newSwapdWSClient(ctx, defaultXMRTakerSwapdWSEndpoint)
 
 	// Bob making an offer above only queues the DHT advertisement for the XMR
@@ -593,12 +612,13 @@ func (s *IntegrationTestSuite) testAbortXMRTakerCancels(asset types.EthAsset) {
 			}
 
 			s.T().Log("> XMRTaker cancelled swap!")
-			exitStatus, err := ac.Cancel(offerResp.OfferID) //nolint:govet
+			cancelResp, err := ac.Cancel(offerResp.OfferID) //nolint:govet
 			if err != nil {
 				errCh <- err
 				return
 			}
 
+			exitStatus := cancelResp.Status
 			if exitStatus != types.CompletedAbort {
 				errCh <- fmt.Errorf("did not refund exit: exit status was %s", exitStatus)
 			}
@@ -636,14 +656,16 @@ func (s *IntegrationTestSuite) testAbortXMRMakerCancels(asset types.EthAsset) {
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
 
-	bcli := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bcli, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	bwsc := s.newSwapdWSClient(ctx, defaultXMRMakerSwapdWSEndpoint)
 
 	offerResp, statusCh, err := bwsc.MakeOfferAndSubscribe(xmrmakerProvideAmount, xmrmakerProvideAmount,
 		exchangeRate, asset, false)
 	require.NoError(s.T(), err)
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	beforeResp, err := bc.GetOffers()
 	require.NoError(s.T(), err)
 
@@ -660,11 +682,12 @@ func (s *IntegrationTestSuite) testAbortXMRMakerCancels(asset types.EthAsset) {
 			case status := <-statusCh:
 				s.T().Log("> XMRMaker got status:", status)
 				s.T().Log("> XMRMaker cancelling swap!")
-				exitStatus, err := bcli.Cancel(offerResp.OfferID) //nolint:govet
+				cancelResp, err := bcli.Cancel(offerResp.OfferID) //nolint:govet
 				if err != nil {
 					errCh <- err
 					return
 				}
+				exitStatus := cancelResp.Status
 				if exitStatus != types.CompletedAbort {
 					errCh <- fmt.Errorf("did not abort successfully: exit status was %s", exitStatus)
 					return
@@ -678,7 +701,8 @@ func (s *IntegrationTestSuite) testAbortXMRMakerCancels(asset types.EthAsset) {
 		}
 	}()
 
-	c := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	c, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	wsc := s.newSwapdWSClient(ctx, defaultXMRTakerSwapdWSEndpoint)
 
 	// Give offer advertisement time to propagate
@@ -738,14 +762,16 @@ func (s *IntegrationTestSuite) testErrorShouldOnlyTakeOfferOnce(asset types.EthA
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	offerResp, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate, asset, false)
 	require.NoError(s.T(), err)
 
 	// Give offer advertisement time to propagate
 	require.NoError(s.T(), common.SleepWithContext(ctx, time.Second))
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	peerIDs, err := ac.Discover(string(coins.ProvidesXMR), defaultDiscoverTimeout)
 	require.NoError(s.T(), err)
 	require.Equal(s.T(), 1, len(peerIDs))
@@ -843,8 +869,9 @@ func (s *IntegrationTestSuite) testSuccessConcurrentSwaps(asset types.EthAsset)
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
 
-	ac := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint)
-	err := ac.SetSwapTimeout(swapTimeout)
+	ac, err := rpcclient.NewClient(ctx, defaultXMRTakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
+	err = ac.SetSwapTimeout(swapTimeout, swapTimeout)
 	require.NoError(s.T(), err)
 
 	type makerTest struct {
@@ -872,7 +899,8 @@ func (s *IntegrationTestSuite) testSuccessConcurrentSwaps(asset types.EthAsset)
 		}
 	}
 
-	bc := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	bc, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 	beforeResp, err := bc.GetOffers()
 	require.NoError(s.T(), err)
 
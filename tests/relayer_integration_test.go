@@ -23,7 +23,8 @@ func (s *IntegrationTestSuite) TestERC20_Success_ClaimRelayer() {
 
 func (s *IntegrationTestSuite) TestXMRMaker_DiscoverRelayer() {
 	ctx := context.Background()
-	c := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint)
+	c, err := rpcclient.NewClient(ctx, defaultXMRMakerSwapdEndpoint, "")
+	require.NoError(s.T(), err)
 
 	// see https://github.com/AthanorLabs/go-relayer/blob/master/net/host.go#L20
 	peerIDs, err := c.Discover("relayer", defaultDiscoverTimeout)